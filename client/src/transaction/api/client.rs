@@ -2,6 +2,7 @@
 use grpcio::{CallOption, Channel, Client, ClientSStreamReceiver, ClientUnaryReceiver, Result};
 use serde::{Deserialize, Serialize};
 use serde_bytes::ByteBuf;
+use serde_repr::{Deserialize_repr, Serialize_repr};
 
 use oasis_core_runtime::{
     common::{crypto::hash::Hash, runtime::RuntimeId},
@@ -98,6 +99,83 @@ pub struct Query {
     pub conditions: Vec<QueryCondition>,
     /// The maximum number of results to return.
     pub limit: u64,
+    /// An optional boolean filter expression over transaction tags.
+    ///
+    /// It is combined with the conditions using an AND query.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub filter: Option<QueryFilter>,
+    /// The order in which results are returned.
+    #[serde(default)]
+    pub order: QueryOrder,
+    /// An optional pagination cursor. Only results that come after the
+    /// cursor in the requested order are returned.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub cursor: Option<QueryCursor>,
+}
+
+/// The order in which query results are returned.
+#[derive(Clone, Copy, Debug, PartialEq, Eq, Serialize_repr, Deserialize_repr)]
+#[repr(u8)]
+pub enum QueryOrder {
+    /// Ascending round and transaction index.
+    Ascending = 0,
+    /// Descending round and transaction index.
+    Descending = 1,
+}
+
+impl Default for QueryOrder {
+    fn default() -> Self {
+        QueryOrder::Ascending
+    }
+}
+
+/// A position in the query results.
+#[derive(Clone, Debug, Serialize, Deserialize)]
+pub struct QueryCursor {
+    /// The round of the transaction.
+    pub round: u64,
+    /// The index of the transaction within the block.
+    pub index: u32,
+}
+
+/// A boolean filter expression over transaction tags.
+///
+/// Exactly one of the fields must be set.
+#[derive(Clone, Debug, Default, Serialize, Deserialize)]
+pub struct QueryFilter {
+    /// Matches transactions that match all of the given filters.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub and: Option<Vec<QueryFilter>>,
+    /// Matches transactions that match any of the given filters.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub or: Option<Vec<QueryFilter>>,
+    /// Matches transactions that do not match the given filter.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub not: Option<Box<QueryFilter>>,
+    /// Matches transactions with a matching tag.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub tag: Option<TagMatch>,
+}
+
+/// Matches transactions with the given tag key and a value that matches
+/// exactly one of the exact, prefix or range conditions.
+#[derive(Clone, Debug, Default, Serialize, Deserialize)]
+pub struct TagMatch {
+    /// The tag key that should be matched.
+    #[serde(with = "serde_bytes")]
+    pub key: Vec<u8>,
+    /// Matches tags with exactly the given value.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub value: Option<ByteBuf>,
+    /// Matches tags with values starting with the given prefix.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub prefix: Option<ByteBuf>,
+    /// Matches tags with values lexicographically greater or equal to the given value.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub min: Option<ByteBuf>,
+    /// Matches tags with values lexicographically less or equal to the given value.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub max: Option<ByteBuf>,
 }
 
 #[derive(Clone, Debug, Serialize, Deserialize)]
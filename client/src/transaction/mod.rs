@@ -8,6 +8,8 @@ pub mod snapshot;
 
 // Re-exports.
 pub use self::{
-    api::client::{Query, QueryCondition, ROUND_LATEST},
+    api::client::{
+        Query, QueryCondition, QueryCursor, QueryFilter, QueryOrder, TagMatch, ROUND_LATEST,
+    },
     client::TxnClient,
 };
@@ -0,0 +1,236 @@
+package badger
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/dgraph-io/badger/v2/options"
+	"github.com/dgraph-io/badger/v2/pb"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+)
+
+const (
+	// EncryptionKeySize is the size of a database encryption key in bytes.
+	EncryptionKeySize = 32
+
+	// EncryptionKeySourceNone disables database encryption.
+	EncryptionKeySourceNone = ""
+	// EncryptionKeySourceIdentity derives the database encryption key from the node identity.
+	EncryptionKeySourceIdentity = "identity"
+	// EncryptionKeySourceFile loads the database encryption key from a file.
+	EncryptionKeySourceFile = "file"
+
+	// encryptionIndexCacheSize is the size of the index cache used for encrypted databases as
+	// otherwise badger needs to decrypt table indices on every access.
+	encryptionIndexCacheSize = 64 * 1024 * 1024
+
+	// rotateNewDirSuffix is the suffix of the directory holding the re-encrypted database while
+	// the encryption key is being rotated.
+	rotateNewDirSuffix = ".rotate-new"
+	// rotateOldDirSuffix is the suffix of the directory holding the old database while the
+	// re-encrypted database is being moved in its place.
+	rotateOldDirSuffix = ".rotate-old"
+	// rotateMaxPendingWrites is the maximum number of pending writes while copying a database.
+	rotateMaxPendingWrites = 256
+
+	// badgerBitDelete is the entry metadata bit used by badger to mark deleted entries.
+	badgerBitDelete = 1 << 0
+)
+
+// encryptionKeyContext is the signature context used for deriving database encryption keys.
+var encryptionKeyContext = signature.NewContext("oasis-core/badger: database encryption key")
+
+// DeriveEncryptionKey derives a database encryption key from the given signer.
+//
+// The signer must produce deterministic signatures (e.g., Ed25519) as the key is derived from
+// a signature over a fixed message.
+func DeriveEncryptionKey(signer signature.Signer) ([]byte, error) {
+	sig, err := signer.ContextSign(encryptionKeyContext, []byte(signer.Public().String()))
+	if err != nil {
+		return nil, fmt.Errorf("badger: failed to derive encryption key: %w", err)
+	}
+	h := hash.NewFromBytes(sig)
+	return h[:], nil
+}
+
+// LoadEncryptionKey loads a hex-encoded database encryption key from the given file.
+func LoadEncryptionKey(fn string) ([]byte, error) {
+	raw, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, fmt.Errorf("badger: failed to read encryption key: %w", err)
+	}
+	key, err := hex.DecodeString(string(bytes.TrimSpace(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("badger: malformed encryption key: %w", err)
+	}
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("badger: malformed encryption key: invalid size %d", len(key))
+	}
+	return key, nil
+}
+
+// NewEncryptionKey returns the database encryption key for the given key source. In case the
+// key source is EncryptionKeySourceNone, nil is returned.
+func NewEncryptionKey(source, fn string, signer signature.Signer) ([]byte, error) {
+	switch source {
+	case EncryptionKeySourceNone:
+		return nil, nil
+	case EncryptionKeySourceIdentity:
+		if signer == nil {
+			return nil, errors.New("badger: identity key source requires a signer")
+		}
+		return DeriveEncryptionKey(signer)
+	case EncryptionKeySourceFile:
+		return LoadEncryptionKey(fn)
+	default:
+		return nil, fmt.Errorf("badger: unsupported encryption key source: '%s'", source)
+	}
+}
+
+// WithEncryption configures the given options so that the database is encrypted at rest with
+// the given key. In case the key is nil, the options are returned unchanged.
+func WithEncryption(opts badger.Options, key []byte) badger.Options {
+	if key == nil {
+		return opts
+	}
+
+	opts = opts.WithEncryptionKey(key)
+	if opts.IndexCacheSize == 0 {
+		opts = opts.WithIndexCacheSize(encryptionIndexCacheSize)
+	}
+	return opts
+}
+
+// RotateEncryptionKey re-encrypts all data of the (closed) database in the given directory with
+// a new key.
+//
+// As badger encrypts data with data keys that are only wrapped by the database encryption key,
+// re-wrapping the key registry is not enough to stop a leaked key (or data key) from being used
+// to decrypt existing data. Instead, all entries (including older versions and deletion markers)
+// are streamed into a new database encrypted with the new key which then replaces the old one.
+//
+// Either key may be nil in case the database is (or should be) unencrypted.
+func RotateEncryptionKey(dir string, oldKey, newKey []byte) error {
+	logger := logging.GetLogger("common/badger").With("path", dir)
+
+	newDir := dir + rotateNewDirSuffix
+	oldDir := dir + rotateOldDirSuffix
+	for _, d := range []string{newDir, oldDir} {
+		if err := os.RemoveAll(d); err != nil {
+			return fmt.Errorf("badger: failed to remove stale directory: %w", err)
+		}
+	}
+
+	if err := copyDatabase(logger, dir, newDir, oldKey, newKey); err != nil {
+		_ = os.RemoveAll(newDir)
+		return err
+	}
+
+	// Swap the re-encrypted database in place of the old one.
+	if err := os.Rename(dir, oldDir); err != nil {
+		_ = os.RemoveAll(newDir)
+		return fmt.Errorf("badger: failed to move old database: %w", err)
+	}
+	if err := os.Rename(newDir, dir); err != nil {
+		return fmt.Errorf("badger: failed to move re-encrypted database (old database kept in '%s'): %w", oldDir, err)
+	}
+	if err := os.RemoveAll(oldDir); err != nil {
+		return fmt.Errorf("badger: failed to remove old database: %w", err)
+	}
+	return nil
+}
+
+// copyDatabase copies all entries of the database in srcDir into a new database in dstDir,
+// decrypting them with srcKey and encrypting them with dstKey.
+func copyDatabase(logger *logging.Logger, srcDir, dstDir string, srcKey, dstKey []byte) error {
+	if _, err := os.Stat(filepath.Join(srcDir, badger.ManifestFilename)); err != nil {
+		return fmt.Errorf("badger: failed to find database manifest: %w", err)
+	}
+
+	// Databases are opened in managed mode so that entry versions are preserved regardless of
+	// whether the database is used in managed mode or not.
+	srcOpts := badger.DefaultOptions(srcDir).WithLogger(NewLogAdapter(logger))
+	src, err := badger.OpenManaged(WithEncryption(srcOpts, srcKey))
+	if err != nil {
+		return fmt.Errorf("badger: failed to open database: %w", err)
+	}
+	defer src.Close()
+
+	dstOpts := badger.DefaultOptions(dstDir).
+		WithLogger(NewLogAdapter(logger)).
+		WithSyncWrites(true).
+		WithCompression(options.Snappy)
+	dst, err := badger.OpenManaged(WithEncryption(dstOpts, dstKey))
+	if err != nil {
+		return fmt.Errorf("badger: failed to create database: %w", err)
+	}
+
+	ldr := dst.NewKVLoader(rotateMaxPendingWrites)
+	stream := src.NewStreamAt(math.MaxUint64)
+	stream.LogPrefix = "badger: copying database"
+	stream.KeyToList = copyKeyToList
+	stream.Send = func(list *pb.KVList) error {
+		for _, kv := range list.Kv {
+			if err := ldr.Set(kv); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err = stream.Orchestrate(context.Background()); err == nil {
+		err = ldr.Finish()
+	}
+	if err != nil {
+		_ = dst.Close()
+		return fmt.Errorf("badger: failed to copy database: %w", err)
+	}
+	if err = dst.Close(); err != nil {
+		return fmt.Errorf("badger: failed to close database: %w", err)
+	}
+	return nil
+}
+
+// copyKeyToList returns all versions of the given key, including deletion markers. Unlike the
+// list used by badger's backups, versions older than a deletion marker are retained as they may
+// still be read by transactions at older timestamps.
+func copyKeyToList(key []byte, itr *badger.Iterator) (*pb.KVList, error) {
+	list := &pb.KVList{}
+	for ; itr.Valid(); itr.Next() {
+		item := itr.Item()
+		if !bytes.Equal(item.Key(), key) {
+			break
+		}
+
+		kv := &pb.KV{
+			Key:       item.KeyCopy(nil),
+			UserMeta:  []byte{item.UserMeta()},
+			Version:   item.Version(),
+			ExpiresAt: item.ExpiresAt(),
+		}
+		if item.IsDeletedOrExpired() {
+			kv.Meta = []byte{badgerBitDelete}
+		} else {
+			var err error
+			if kv.Value, err = item.ValueCopy(nil); err != nil {
+				return nil, err
+			}
+		}
+		list.Kv = append(list.Kv, kv)
+
+		if item.DiscardEarlierVersions() {
+			break
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,163 @@
+package badger
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/require"
+
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+)
+
+func TestEncryptionKey(t *testing.T) {
+	require := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "oasis-badger-encryption-test")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(tmpDir)
+
+	// Disabled encryption.
+	key, err := NewEncryptionKey(EncryptionKeySourceNone, "", nil)
+	require.NoError(err, "NewEncryptionKey(none)")
+	require.Nil(key, "no key should be returned when encryption is disabled")
+
+	// Identity-derived keys should be deterministic.
+	signer := memorySigner.NewTestSigner("badger encryption test")
+	key, err = NewEncryptionKey(EncryptionKeySourceIdentity, "", signer)
+	require.NoError(err, "NewEncryptionKey(identity)")
+	require.Len(key, EncryptionKeySize, "derived key should have the correct size")
+	key2, err := DeriveEncryptionKey(signer)
+	require.NoError(err, "DeriveEncryptionKey")
+	require.EqualValues(key, key2, "derived key should be deterministic")
+	_, err = NewEncryptionKey(EncryptionKeySourceIdentity, "", nil)
+	require.Error(err, "identity key source should require a signer")
+
+	// File-based keys.
+	fn := filepath.Join(tmpDir, "key")
+	err = ioutil.WriteFile(fn, []byte(hex.EncodeToString(key)+"\n"), 0o600)
+	require.NoError(err, "WriteFile")
+	key2, err = NewEncryptionKey(EncryptionKeySourceFile, fn, nil)
+	require.NoError(err, "NewEncryptionKey(file)")
+	require.EqualValues(key, key2, "file key should be loaded correctly")
+
+	err = ioutil.WriteFile(fn, []byte("deadbeef"), 0o600)
+	require.NoError(err, "WriteFile")
+	_, err = NewEncryptionKey(EncryptionKeySourceFile, fn, nil)
+	require.Error(err, "short file key should be rejected")
+
+	_, err = NewEncryptionKey("invalid", "", nil)
+	require.Error(err, "unsupported key source should be rejected")
+}
+
+func TestRotateEncryptionKey(t *testing.T) {
+	require := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "oasis-badger-encryption-test")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(tmpDir)
+
+	oldKey, err := DeriveEncryptionKey(memorySigner.NewTestSigner("badger encryption test old"))
+	require.NoError(err, "DeriveEncryptionKey")
+	newKey, err := DeriveEncryptionKey(memorySigner.NewTestSigner("badger encryption test new"))
+	require.NoError(err, "DeriveEncryptionKey")
+
+	openDB := func(key []byte) (*badger.DB, error) {
+		opts := badger.DefaultOptions(tmpDir).WithLogger(nil)
+		return badger.Open(WithEncryption(opts, key))
+	}
+
+	db, err := openDB(oldKey)
+	require.NoError(err, "Open")
+	err = db.Update(func(tx *badger.Txn) error {
+		return tx.Set([]byte("key"), []byte("value"))
+	})
+	require.NoError(err, "Update")
+	require.NoError(db.Close(), "Close")
+
+	_, err = openDB(newKey)
+	require.Error(err, "opening with the wrong key should fail")
+
+	err = RotateEncryptionKey(tmpDir, oldKey, newKey)
+	require.NoError(err, "RotateEncryptionKey")
+
+	_, err = openDB(oldKey)
+	require.Error(err, "opening with the old key should fail after rotation")
+
+	db, err = openDB(newKey)
+	require.NoError(err, "Open with new key")
+	defer db.Close()
+	err = db.View(func(tx *badger.Txn) error {
+		item, txErr := tx.Get([]byte("key"))
+		if txErr != nil {
+			return txErr
+		}
+		value, txErr := item.ValueCopy(nil)
+		require.EqualValues([]byte("value"), value, "value should be readable after rotation")
+		return txErr
+	})
+	require.NoError(err, "View")
+}
+
+func TestRotateEncryptionKeyVersions(t *testing.T) {
+	require := require.New(t)
+
+	tmpDir, err := ioutil.TempDir("", "oasis-badger-encryption-test")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(tmpDir)
+
+	oldKey, err := DeriveEncryptionKey(memorySigner.NewTestSigner("badger encryption test old"))
+	require.NoError(err, "DeriveEncryptionKey")
+
+	openDB := func(key []byte) (*badger.DB, error) {
+		opts := badger.DefaultOptions(tmpDir).WithLogger(nil)
+		return badger.OpenManaged(WithEncryption(opts, key))
+	}
+
+	// Write multiple versions of a key, including a deletion.
+	db, err := openDB(oldKey)
+	require.NoError(err, "OpenManaged")
+	for ts, value := range [][]byte{nil, []byte("v1"), []byte("v2"), nil} {
+		if ts == 0 {
+			continue
+		}
+		tx := db.NewTransactionAt(uint64(ts), true)
+		if value != nil {
+			err = tx.Set([]byte("key"), value)
+		} else {
+			err = tx.Delete([]byte("key"))
+		}
+		require.NoError(err, "Set/Delete")
+		require.NoError(tx.CommitAt(uint64(ts), nil), "CommitAt")
+	}
+	require.NoError(db.Close(), "Close")
+
+	// Rotate to an unencrypted database.
+	err = RotateEncryptionKey(tmpDir, oldKey, nil)
+	require.NoError(err, "RotateEncryptionKey")
+	for _, suffix := range []string{rotateNewDirSuffix, rotateOldDirSuffix} {
+		_, err = os.Stat(tmpDir + suffix)
+		require.True(os.IsNotExist(err), "temporary directories should be removed")
+	}
+
+	db, err = openDB(nil)
+	require.NoError(err, "OpenManaged without a key")
+	defer db.Close()
+
+	for ts, expected := range [][]byte{nil, []byte("v1"), []byte("v2"), nil} {
+		tx := db.NewTransactionAt(uint64(ts), false)
+		item, txErr := tx.Get([]byte("key"))
+		if expected == nil {
+			require.Equal(badger.ErrKeyNotFound, txErr, "key should not exist at version %d", ts)
+		} else {
+			require.NoError(txErr, "Get at version %d", ts)
+			value, txErr := item.ValueCopy(nil)
+			require.NoError(txErr, "ValueCopy")
+			require.EqualValues(expected, value, "value at version %d should be preserved", ts)
+		}
+		tx.Discard()
+	}
+}
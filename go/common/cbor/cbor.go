@@ -106,12 +106,18 @@ func MustUnmarshal(data []byte, dst interface{}) {
 	}
 }
 
+// Encoder is a streaming CBOR encoder.
+type Encoder = cbor.Encoder
+
+// Decoder is a streaming CBOR decoder.
+type Decoder = cbor.Decoder
+
 // NewEncoder creates a new CBOR encoder.
-func NewEncoder(w io.Writer) *cbor.Encoder {
+func NewEncoder(w io.Writer) *Encoder {
 	return encMode.NewEncoder(w)
 }
 
 // NewDecoder creates a new CBOR decoder.
-func NewDecoder(r io.Reader) *cbor.Decoder {
+func NewDecoder(r io.Reader) *Decoder {
 	return decMode.NewDecoder(r)
 }
@@ -6,8 +6,26 @@ import (
 	"context"
 
 	"github.com/opentracing/opentracing-go"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+)
+
+const (
+	// TagRuntimeID is the span tag containing the runtime identifier.
+	TagRuntimeID = "runtime_id"
+	// TagRound is the span tag containing the runtime round.
+	TagRound = "round"
 )
 
+// RoundTags returns the span tags used to correlate spans belonging to the
+// given runtime round.
+func RoundTags(runtimeID common.Namespace, round uint64) opentracing.Tags {
+	return opentracing.Tags{
+		TagRuntimeID: runtimeID.String(),
+		TagRound:     round,
+	}
+}
+
 // StartSpanWithContext creates a new span and returns a context containing it.
 // In contrast to opentracing.StartSpanFromContext(), this function does not
 // take existing span from `ctx` as a ChildOfRef.
@@ -78,6 +78,9 @@ type ApplicationConfig struct { // nolint: maligned
 	// ReadOnlyStorage forces read-only access for the state storage.
 	ReadOnlyStorage bool
 
+	// StorageEncryptionKey is the optional key used to encrypt the state storage at rest.
+	StorageEncryptionKey []byte
+
 	// InitialHeight is the height of the initial block.
 	InitialHeight uint64
 }
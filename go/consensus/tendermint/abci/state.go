@@ -453,6 +453,7 @@ func InitStateStorage(ctx context.Context, cfg *ApplicationConfig) (storage.Loca
 		NoFsync:          true, // This is safe as Tendermint will replay on crash.
 		MemoryOnly:       cfg.MemoryOnlyStorage,
 		ReadOnly:         cfg.ReadOnlyStorage,
+		EncryptionKey:    cfg.StorageEncryptionKey,
 	})
 	if err != nil {
 		return nil, nil, nil, err
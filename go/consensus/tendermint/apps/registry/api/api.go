@@ -20,4 +20,12 @@ var (
 	// MessageRuntimeResumed is the message kind for suspended runtime resumptions. The message is
 	// the runtime descriptor of the runtime that has been resumed.
 	MessageRuntimeResumed = messageKind(2)
+
+	// MessageRuntimeSuspended is the message kind for runtimes explicitly suspended by their
+	// owners. The message is the runtime descriptor of the runtime that has been suspended.
+	MessageRuntimeSuspended = messageKind(3)
+
+	// MessageRuntimeDecommissioned is the message kind for runtime decommissions. The message is
+	// the runtime descriptor of the runtime that has been decommissioned.
+	MessageRuntimeDecommissioned = messageKind(4)
 )
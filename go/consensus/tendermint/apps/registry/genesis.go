@@ -7,13 +7,16 @@ import (
 
 	"github.com/tendermint/tendermint/abci/types"
 
+	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
 	abciAPI "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
 	registryState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/registry/state"
+	stakingState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/staking/state"
 	genesis "github.com/oasisprotocol/oasis-core/go/genesis/api"
 	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
 )
 
 func (app *registryApplication) InitChain(ctx *abciAPI.Context, request types.RequestInitChain, doc *genesis.Document) error {
@@ -121,6 +124,30 @@ func (app *registryApplication) InitChain(ctx *abciAPI.Context, request types.Re
 		}
 	}
 
+	for id, status := range st.RuntimeStatuses {
+		if status == nil {
+			return fmt.Errorf("registry: genesis runtime status %s is nil", id)
+		}
+		rt, err := state.AnyRuntime(ctx, id)
+		if err != nil {
+			return fmt.Errorf("registry: genesis runtime status for unknown runtime %s: %w", id, err)
+		}
+		if err = state.SetRuntimeStatus(ctx, id, status); err != nil {
+			ctx.Logger().Error("InitChain: failed to set runtime status",
+				"err", err,
+			)
+			return fmt.Errorf("registry: genesis runtime status set failure: %w", err)
+		}
+
+		// Decommissioned runtimes do not hold a stake claim.
+		if status.Decommissioned && !st.Parameters.DebugBypassStake {
+			acctAddr := staking.NewAddress(rt.EntityID)
+			if err = stakingState.RemoveStakeClaim(ctx, acctAddr, registry.StakeClaimForRuntime(id)); err != nil {
+				return fmt.Errorf("registry: failed to remove decommissioned runtime stake claim: %w", err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -169,6 +196,26 @@ func (rq *registryQuerier) Genesis(ctx context.Context) (*registry.Genesis, erro
 		nodeStatuses[n.ID] = status
 	}
 
+	// Only keep non-default runtime statuses.
+	runtimes, err := rq.state.AllRuntimes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var runtimeStatuses map[common.Namespace]*registry.RuntimeStatus
+	for _, rt := range runtimes {
+		var status *registry.RuntimeStatus
+		if status, err = rq.state.RuntimeStatus(ctx, rt.ID); err != nil {
+			return nil, err
+		}
+		if !status.Paused && !status.Decommissioned {
+			continue
+		}
+		if runtimeStatuses == nil {
+			runtimeStatuses = make(map[common.Namespace]*registry.RuntimeStatus)
+		}
+		runtimeStatuses[rt.ID] = status
+	}
+
 	params, err := rq.state.ConsensusParameters(ctx)
 	if err != nil {
 		return nil, err
@@ -181,6 +228,7 @@ func (rq *registryQuerier) Genesis(ctx context.Context) (*registry.Genesis, erro
 		SuspendedRuntimes: suspendedRuntimes,
 		Nodes:             validatorNodes,
 		NodeStatuses:      nodeStatuses,
+		RuntimeStatuses:   runtimeStatuses,
 	}
 	return &gen, nil
 }
@@ -101,6 +101,27 @@ func (app *registryApplication) ExecuteTx(ctx *api.Context, tx *transaction.Tran
 		}
 
 		return app.registerRuntime(ctx, state, &sigRt)
+	case registry.MethodPauseRuntime:
+		var pause registry.PauseRuntime
+		if err := cbor.Unmarshal(tx.Body, &pause); err != nil {
+			return err
+		}
+
+		return app.pauseRuntime(ctx, state, &pause)
+	case registry.MethodResumeRuntime:
+		var resume registry.ResumeRuntime
+		if err := cbor.Unmarshal(tx.Body, &resume); err != nil {
+			return err
+		}
+
+		return app.resumeRuntime(ctx, state, &resume)
+	case registry.MethodDecommissionRuntime:
+		var decommission registry.DecommissionRuntime
+		if err := cbor.Unmarshal(tx.Body, &decommission); err != nil {
+			return err
+		}
+
+		return app.decommissionRuntime(ctx, state, &decommission)
 	default:
 		return registry.ErrInvalidArgument
 	}
@@ -152,10 +173,6 @@ func (app *registryApplication) onRegistryEpochChanged(ctx *api.Context, registr
 	// otherwise the nodes could not be resolved.
 	var expiredNodes []*node.Node
 	for _, node := range nodes {
-		if !node.IsExpired(uint64(registryEpoch)) {
-			continue
-		}
-
 		// Fetch node status to check whether we have already processed the
 		// node expiration (this is required so that we don't emit expiration
 		// events every epoch).
@@ -165,13 +182,23 @@ func (app *registryApplication) onRegistryEpochChanged(ctx *api.Context, registr
 			return fmt.Errorf("registry: onRegistryEpochChanged: couldn't get node status: %w", err)
 		}
 
-		if !status.ExpirationProcessed {
+		// Remove any runtime exclusions that have ended.
+		updated := status.PruneExcludedRuntimes(registryEpoch)
+
+		expired := node.IsExpired(uint64(registryEpoch))
+		if expired && !status.ExpirationProcessed {
 			expiredNodes = append(expiredNodes, node)
 			status.ExpirationProcessed = true
+			updated = true
+		}
+		if updated {
 			if err = state.SetNodeStatus(ctx, node.ID, status); err != nil {
 				return fmt.Errorf("registry: onRegistryEpochChanged: couldn't set node status: %w", err)
 			}
 		}
+		if !expired {
+			continue
+		}
 
 		// If node has been expired for the debonding interval, finally remove it.
 		if math.MaxUint64-node.Expiration < uint64(debondingInterval) {
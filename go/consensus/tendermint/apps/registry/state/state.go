@@ -69,6 +69,10 @@ var (
 	//
 	// Value is empty.
 	signedRuntimeByEntityKeyFmt = keyformat.New(0x19, keyformat.H(&signature.PublicKey{}), keyformat.H(&common.Namespace{}))
+	// runtimeStatusKeyFmt is the key format used for runtime lifecycle statuses.
+	//
+	// Value is CBOR-serialized runtime status.
+	runtimeStatusKeyFmt = keyformat.New(0x1a, keyformat.H(&common.Namespace{}))
 )
 
 // ImmutableState is the immutable registry state wrapper.
@@ -443,6 +447,27 @@ func (s *ImmutableState) NodeStatus(ctx context.Context, id signature.PublicKey)
 	return &status, nil
 }
 
+// RuntimeStatus returns the lifecycle status of a registered runtime.
+func (s *ImmutableState) RuntimeStatus(ctx context.Context, id common.Namespace) (*registry.RuntimeStatus, error) {
+	if _, err := s.AnyRuntime(ctx, id); err != nil {
+		return nil, err
+	}
+
+	value, err := s.is.Get(ctx, runtimeStatusKeyFmt.Encode(&id))
+	if err != nil {
+		return nil, abciAPI.UnavailableStateError(err)
+	}
+	if value == nil {
+		return &registry.RuntimeStatus{}, nil
+	}
+
+	var status registry.RuntimeStatus
+	if err := cbor.Unmarshal(value, &status); err != nil {
+		return nil, abciAPI.UnavailableStateError(err)
+	}
+	return &status, nil
+}
+
 // HasEntityNodes checks whether an entity has any registered nodes.
 func (s *ImmutableState) HasEntityNodes(ctx context.Context, id signature.PublicKey) (bool, error) {
 	it := s.is.NewIterator(ctx)
@@ -688,6 +713,12 @@ func (s *MutableState) SetNodeStatus(ctx context.Context, id signature.PublicKey
 	return abciAPI.UnavailableStateError(err)
 }
 
+// SetRuntimeStatus sets a lifecycle status for a registered runtime.
+func (s *MutableState) SetRuntimeStatus(ctx context.Context, id common.Namespace, status *registry.RuntimeStatus) error {
+	err := s.ms.Insert(ctx, runtimeStatusKeyFmt.Encode(&id), cbor.Marshal(status))
+	return abciAPI.UnavailableStateError(err)
+}
+
 // SetConsensusParameters sets registry consensus parameters.
 func (s *MutableState) SetConsensusParameters(ctx context.Context, params *registry.ConsensusParameters) error {
 	err := s.ms.Insert(ctx, parametersKeyFmt.Encode(), cbor.Marshal(params))
@@ -3,6 +3,7 @@ package registry
 import (
 	"fmt"
 
+	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/entity"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
@@ -383,6 +384,20 @@ func (app *registryApplication) registerNode( // nolint: gocyclo
 	// If a runtime was previously suspended and this node now paid maintenance
 	// fees for it, resume the runtime.
 	for _, rt := range paidRuntimes {
+		// Runtimes explicitly paused or decommissioned by their owners are never resumed
+		// automatically.
+		var rtStatus *registry.RuntimeStatus
+		if rtStatus, err = state.RuntimeStatus(ctx, rt.ID); err != nil {
+			ctx.Logger().Error("RegisterNode: failed to fetch runtime status",
+				"err", err,
+				"runtime_id", rt.ID,
+			)
+			return fmt.Errorf("failed to fetch runtime status: %w", err)
+		}
+		if rtStatus.Paused || rtStatus.Decommissioned {
+			continue
+		}
+
 		// Only resume a runtime if the entity has enough stake to avoid having the runtime be
 		// suspended again on the next epoch transition.
 		if !params.DebugBypassStake {
@@ -569,6 +584,14 @@ func (app *registryApplication) registerRuntime( // nolint: gocyclo
 	}
 	// If there is an existing runtime, verify update.
 	if existingRt != nil {
+		var status *registry.RuntimeStatus
+		if status, err = state.RuntimeStatus(ctx, rt.ID); err != nil {
+			return fmt.Errorf("failed to fetch runtime status: %w", err)
+		}
+		if status.Decommissioned {
+			return registry.ErrRuntimeDecommissioned
+		}
+
 		err = registry.VerifyRuntimeUpdate(ctx.Logger(), existingRt, rt)
 		if err != nil {
 			return err
@@ -627,3 +650,228 @@ func (app *registryApplication) registerRuntime( // nolint: gocyclo
 
 	return nil
 }
+
+// getOwnedRuntime fetches the (active or suspended) runtime targeted by a runtime lifecycle
+// transaction together with its lifecycle status and makes sure that the transaction was signed
+// by the runtime owner.
+func (app *registryApplication) getOwnedRuntime(
+	ctx *api.Context,
+	state *registryState.MutableState,
+	id common.Namespace,
+) (rt *registry.Runtime, suspended bool, status *registry.RuntimeStatus, err error) {
+	rt, err = state.Runtime(ctx, id)
+	switch err {
+	case nil:
+	case registry.ErrNoSuchRuntime:
+		if rt, err = state.SuspendedRuntime(ctx, id); err != nil {
+			return
+		}
+		suspended = true
+	default:
+		return
+	}
+
+	// Make sure that the request was signed by the owning entity.
+	if !ctx.TxSigner().Equal(rt.EntityID) {
+		err = registry.ErrBadEntityForRuntime
+		return
+	}
+
+	status, err = state.RuntimeStatus(ctx, id)
+	return
+}
+
+func (app *registryApplication) pauseRuntime(
+	ctx *api.Context,
+	state *registryState.MutableState,
+	pause *registry.PauseRuntime,
+) error {
+	if ctx.IsCheckOnly() {
+		return nil
+	}
+
+	// Charge gas for this transaction.
+	params, err := state.ConsensusParameters(ctx)
+	if err != nil {
+		ctx.Logger().Error("PauseRuntime: failed to fetch consensus parameters",
+			"err", err,
+		)
+		return err
+	}
+	if err = ctx.Gas().UseGas(1, registry.GasOpPauseRuntime, params.GasCosts); err != nil {
+		return err
+	}
+
+	rt, suspended, status, err := app.getOwnedRuntime(ctx, state, pause.RuntimeID)
+	if err != nil {
+		return err
+	}
+	if status.Decommissioned {
+		return registry.ErrRuntimeDecommissioned
+	}
+
+	// Pausing an already suspended runtime only prevents it from being resumed automatically.
+	if !suspended {
+		if err = state.SuspendRuntime(ctx, rt.ID); err != nil {
+			return fmt.Errorf("failed to suspend runtime: %w", err)
+		}
+
+		// Notify other interested applications about the suspended runtime.
+		if err = app.md.Publish(ctx, registryApi.MessageRuntimeSuspended, rt); err != nil {
+			ctx.Logger().Error("PauseRuntime: failed to dispatch runtime suspension message",
+				"err", err,
+			)
+			return err
+		}
+	}
+
+	status.Paused = true
+	if err = state.SetRuntimeStatus(ctx, rt.ID, status); err != nil {
+		return fmt.Errorf("failed to set runtime status: %w", err)
+	}
+
+	ctx.Logger().Debug("PauseRuntime: paused",
+		"runtime_id", rt.ID,
+	)
+
+	return nil
+}
+
+func (app *registryApplication) resumeRuntime(
+	ctx *api.Context,
+	state *registryState.MutableState,
+	resume *registry.ResumeRuntime,
+) error {
+	if ctx.IsCheckOnly() {
+		return nil
+	}
+
+	// Charge gas for this transaction.
+	params, err := state.ConsensusParameters(ctx)
+	if err != nil {
+		ctx.Logger().Error("ResumeRuntime: failed to fetch consensus parameters",
+			"err", err,
+		)
+		return err
+	}
+	if err = ctx.Gas().UseGas(1, registry.GasOpResumeRuntime, params.GasCosts); err != nil {
+		return err
+	}
+
+	rt, suspended, status, err := app.getOwnedRuntime(ctx, state, resume.RuntimeID)
+	if err != nil {
+		return err
+	}
+	if status.Decommissioned {
+		return registry.ErrRuntimeDecommissioned
+	}
+	if !suspended {
+		return registry.ErrRuntimeNotSuspended
+	}
+
+	// Only resume a runtime if the entity has enough stake to avoid having the runtime be
+	// suspended again on the next epoch transition.
+	if !params.DebugBypassStake {
+		acctAddr := staking.NewAddress(rt.EntityID)
+		if err = stakingState.CheckStakeClaims(ctx, acctAddr); err != nil {
+			ctx.Logger().Error("ResumeRuntime: insufficient stake",
+				"err", err,
+				"entity", rt.EntityID,
+				"account", acctAddr,
+			)
+			return err
+		}
+	}
+
+	if err = state.ResumeRuntime(ctx, rt.ID); err != nil {
+		return fmt.Errorf("failed to resume runtime: %w", err)
+	}
+
+	status.Paused = false
+	if err = state.SetRuntimeStatus(ctx, rt.ID, status); err != nil {
+		return fmt.Errorf("failed to set runtime status: %w", err)
+	}
+
+	// Notify other interested applications about the resumed runtime.
+	if err = app.md.Publish(ctx, registryApi.MessageRuntimeResumed, rt); err != nil {
+		ctx.Logger().Error("ResumeRuntime: failed to dispatch runtime resumption message",
+			"err", err,
+		)
+		return err
+	}
+
+	ctx.Logger().Debug("ResumeRuntime: resumed",
+		"runtime_id", rt.ID,
+	)
+
+	ctx.EmitEvent(api.NewEventBuilder(app.Name()).Attribute(KeyRuntimeRegistered, cbor.Marshal(rt)))
+
+	return nil
+}
+
+func (app *registryApplication) decommissionRuntime(
+	ctx *api.Context,
+	state *registryState.MutableState,
+	decommission *registry.DecommissionRuntime,
+) error {
+	if ctx.IsCheckOnly() {
+		return nil
+	}
+
+	// Charge gas for this transaction.
+	params, err := state.ConsensusParameters(ctx)
+	if err != nil {
+		ctx.Logger().Error("DecommissionRuntime: failed to fetch consensus parameters",
+			"err", err,
+		)
+		return err
+	}
+	if err = ctx.Gas().UseGas(1, registry.GasOpDecommissionRuntime, params.GasCosts); err != nil {
+		return err
+	}
+
+	rt, suspended, status, err := app.getOwnedRuntime(ctx, state, decommission.RuntimeID)
+	if err != nil {
+		return err
+	}
+	if status.Decommissioned {
+		return registry.ErrRuntimeDecommissioned
+	}
+
+	if !suspended {
+		if err = state.SuspendRuntime(ctx, rt.ID); err != nil {
+			return fmt.Errorf("failed to suspend runtime: %w", err)
+		}
+	}
+
+	status.Decommissioned = true
+	if err = state.SetRuntimeStatus(ctx, rt.ID, status); err != nil {
+		return fmt.Errorf("failed to set runtime status: %w", err)
+	}
+
+	// The runtime can no longer be used, so release its stake claim.
+	if !params.DebugBypassStake {
+		acctAddr := staking.NewAddress(rt.EntityID)
+		if err = stakingState.RemoveStakeClaim(ctx, acctAddr, registry.StakeClaimForRuntime(rt.ID)); err != nil {
+			ctx.Logger().Error("DecommissionRuntime: failed to remove runtime stake claim",
+				"err", err,
+				"runtime_id", rt.ID,
+			)
+			return fmt.Errorf("failed to remove runtime stake claim: %w", err)
+		}
+	}
+
+	// Notify other interested applications about the decommissioned runtime.
+	if err = app.md.Publish(ctx, registryApi.MessageRuntimeDecommissioned, rt); err != nil {
+		ctx.Logger().Error("DecommissionRuntime: failed to dispatch runtime decommission message",
+			"err", err,
+		)
+		return err
+	}
+
+	ctx.Logger().Debug("DecommissionRuntime: decommissioned",
+		"runtime_id", rt.ID,
+	)
+
+	return nil
+}
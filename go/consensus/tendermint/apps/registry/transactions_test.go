@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -446,3 +447,81 @@ func TestRegisterNode(t *testing.T) {
 		})
 	}
 }
+
+func TestRuntimeLifecycle(t *testing.T) {
+	require := requirePkg.New(t)
+
+	now := time.Unix(1580461674, 0)
+	cfg := abciAPI.MockApplicationStateConfig{}
+	appState := abciAPI.NewMockApplicationState(&cfg)
+	ctx := appState.NewContext(abciAPI.ContextDeliverTx, now)
+	defer ctx.Close()
+
+	var md abciAPI.NoopMessageDispatcher
+	app := registryApplication{appState, &md}
+	state := registryState.NewMutableState(ctx.State())
+
+	err := state.SetConsensusParameters(ctx, &registry.ConsensusParameters{
+		DebugBypassStake: true,
+	})
+	require.NoError(err, "registry.SetConsensusParameters")
+
+	entitySigner := memorySigner.NewTestSigner("consensus/tendermint/apps/registry: entity signer: RuntimeLifecycle")
+	otherSigner := memorySigner.NewTestSigner("consensus/tendermint/apps/registry: other signer: RuntimeLifecycle")
+	rt := registry.Runtime{
+		Versioned: cbor.NewVersioned(registry.LatestRuntimeDescriptorVersion),
+		ID:        common.NewTestNamespaceFromSeed([]byte("consensus/tendermint/apps/registry: runtime: RuntimeLifecycle"), 0),
+		EntityID:  entitySigner.Public(),
+		Kind:      registry.KindCompute,
+	}
+	sigRt, err := registry.SignRuntime(entitySigner, registry.RegisterRuntimeSignatureContext, &rt)
+	require.NoError(err, "SignRuntime")
+	err = state.SetRuntime(ctx, &rt, sigRt, false)
+	require.NoError(err, "SetRuntime")
+
+	// Only the runtime owner should be able to manage the runtime lifecycle.
+	ctx.SetTxSigner(otherSigner.Public())
+	err = app.pauseRuntime(ctx, state, &registry.PauseRuntime{RuntimeID: rt.ID})
+	require.True(errors.Is(err, registry.ErrBadEntityForRuntime), "pause by non-owner should fail")
+
+	ctx.SetTxSigner(entitySigner.Public())
+
+	// Resuming an active runtime should fail.
+	err = app.resumeRuntime(ctx, state, &registry.ResumeRuntime{RuntimeID: rt.ID})
+	require.True(errors.Is(err, registry.ErrRuntimeNotSuspended), "resume of active runtime should fail")
+
+	// Pause the runtime.
+	err = app.pauseRuntime(ctx, state, &registry.PauseRuntime{RuntimeID: rt.ID})
+	require.NoError(err, "pauseRuntime")
+	_, err = state.Runtime(ctx, rt.ID)
+	require.True(errors.Is(err, registry.ErrNoSuchRuntime), "paused runtime should not be active")
+	status, err := state.RuntimeStatus(ctx, rt.ID)
+	require.NoError(err, "RuntimeStatus")
+	require.True(status.Paused, "runtime should be paused")
+
+	// Resume the runtime.
+	err = app.resumeRuntime(ctx, state, &registry.ResumeRuntime{RuntimeID: rt.ID})
+	require.NoError(err, "resumeRuntime")
+	_, err = state.Runtime(ctx, rt.ID)
+	require.NoError(err, "resumed runtime should be active")
+	status, err = state.RuntimeStatus(ctx, rt.ID)
+	require.NoError(err, "RuntimeStatus")
+	require.False(status.Paused, "runtime should no longer be paused")
+
+	// Decommission the runtime.
+	err = app.decommissionRuntime(ctx, state, &registry.DecommissionRuntime{RuntimeID: rt.ID})
+	require.NoError(err, "decommissionRuntime")
+	_, err = state.SuspendedRuntime(ctx, rt.ID)
+	require.NoError(err, "decommissioned runtime should be suspended")
+	status, err = state.RuntimeStatus(ctx, rt.ID)
+	require.NoError(err, "RuntimeStatus")
+	require.True(status.Decommissioned, "runtime should be decommissioned")
+
+	// A decommissioned runtime can no longer be resumed, paused or decommissioned again.
+	err = app.resumeRuntime(ctx, state, &registry.ResumeRuntime{RuntimeID: rt.ID})
+	require.True(errors.Is(err, registry.ErrRuntimeDecommissioned), "resume of decommissioned runtime should fail")
+	err = app.pauseRuntime(ctx, state, &registry.PauseRuntime{RuntimeID: rt.ID})
+	require.True(errors.Is(err, registry.ErrRuntimeDecommissioned), "pause of decommissioned runtime should fail")
+	err = app.decommissionRuntime(ctx, state, &registry.DecommissionRuntime{RuntimeID: rt.ID})
+	require.True(errors.Is(err, registry.ErrRuntimeDecommissioned), "repeated decommission should fail")
+}
@@ -44,12 +44,21 @@ var (
 	// merge discrepancy detected events (value is a CBOR serialized
 	// ValueExecutionDiscrepancyDetected).
 	KeyExecutionDiscrepancyDetected = []byte("execution-discrepancy")
+	// KeyExecutorMisbehavior is an ABCI event attribute key for executor
+	// misbehavior events (value is a CBOR serialized ValueExecutorMisbehavior).
+	KeyExecutorMisbehavior = []byte("executor-misbehavior")
+	// KeySuspensionNotice is an ABCI event attribute key for runtime
+	// suspension notice events (value is a CBOR serialized ValueSuspensionNotice).
+	KeySuspensionNotice = []byte("suspension-notice")
 	// KeyFinalized is an ABCI event attribute key for finalized blocks
 	// (value is a CBOR serialized ValueFinalized).
 	KeyFinalized = []byte("finalized")
 	// KeyMessage is an ABCI event attribute key for message result events
 	// (value is a CBOR serialized ValueMessage).
 	KeyMessage = []byte("message")
+	// KeyInMessageDelivered is an ABCI event attribute key for runtime message delivery receipt
+	// events (value is a CBOR serialized ValueInMessageDelivered).
+	KeyInMessageDelivered = []byte("in-message-delivered")
 )
 
 // QueryForRuntime returns a query for filtering transactions processed by the roothash application
@@ -83,8 +92,26 @@ type ValueExecutionDiscrepancyDetected struct {
 	Event roothash.ExecutionDiscrepancyDetectedEvent `json:"event"`
 }
 
+// ValueExecutorMisbehavior is the value component of a KeyExecutorMisbehavior.
+type ValueExecutorMisbehavior struct {
+	ID    common.Namespace                  `json:"id"`
+	Event roothash.ExecutorMisbehaviorEvent `json:"event"`
+}
+
+// ValueSuspensionNotice is the value component of a KeySuspensionNotice.
+type ValueSuspensionNotice struct {
+	ID    common.Namespace               `json:"id"`
+	Event roothash.SuspensionNoticeEvent `json:"event"`
+}
+
 // ValueMessage is the value component of a KeyMessage.
 type ValueMessage struct {
 	ID    common.Namespace      `json:"id"`
 	Event roothash.MessageEvent `json:"event"`
 }
+
+// ValueInMessageDelivered is the value component of a KeyInMessageDelivered.
+type ValueInMessageDelivered struct {
+	ID    common.Namespace                 `json:"id"`
+	Event roothash.InMessageDeliveredEvent `json:"event"`
+}
@@ -64,6 +64,9 @@ func (rq *rootHashQuerier) Genesis(ctx context.Context) (*roothashAPI.Genesis, e
 				Round:           rt.CurrentBlock.Header.Round,
 			},
 		}
+		if rtState.IncomingMessages, err = rq.state.IncomingMessageQueue(ctx, rt.Runtime.ID, 0); err != nil {
+			return nil, fmt.Errorf("failed to fetch incoming message queue: %w", err)
+		}
 
 		rtStates[rt.Runtime.ID] = &rtState
 	}
@@ -0,0 +1,144 @@
+package roothash
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	tmapi "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	registryState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/registry/state"
+	epochtime "github.com/oasisprotocol/oasis-core/go/epochtime/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+)
+
+// updateLivenessStatistics updates the liveness statistics of the primary executor workers based
+// on the commitments in the executor pool. It must be called when the round ends, before the
+// commitments are reset.
+//
+// A primary worker without a commitment only missed the round in case the round timed out, as
+// otherwise the round ended before the worker's commitment was due.
+func (app *rootHashApplication) updateLivenessStatistics(
+	ctx *tmapi.Context,
+	rtState *roothash.RuntimeState,
+	timedOut bool,
+) error {
+	pool := rtState.ExecutorPool
+	if pool == nil || pool.Committee == nil {
+		return nil
+	}
+	if rtState.LivenessStatistics == nil {
+		rtState.LivenessStatistics = roothash.NewLivenessStatistics()
+	}
+
+	seen := make(map[signature.PublicKey]bool)
+	for _, member := range pool.Committee.Members {
+		if member.Role != scheduler.RoleWorker || seen[member.PublicKey] {
+			continue
+		}
+		seen[member.PublicKey] = true
+
+		ns := rtState.LivenessStatistics.Node(member.PublicKey)
+		ns.RoundsElected++
+		switch _, ok := pool.ExecuteCommitments[member.PublicKey]; {
+		case ok:
+			ns.RoundsCommitted++
+		case timedOut:
+			ns.RoundsMissed++
+		default:
+			continue
+		}
+
+		if err := app.checkLivenessPenalty(ctx, rtState, member.PublicKey, ns); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordProposerTimeout records a proposer timeout for the transaction scheduler of the current
+// round in the liveness statistics.
+func (app *rootHashApplication) recordProposerTimeout(
+	ctx *tmapi.Context,
+	rtState *roothash.RuntimeState,
+) error {
+	pool := rtState.ExecutorPool
+	scheduler, err := commitment.GetTransactionScheduler(pool.Committee, pool.Round)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction scheduler: %w", err)
+	}
+	if rtState.LivenessStatistics == nil {
+		rtState.LivenessStatistics = roothash.NewLivenessStatistics()
+	}
+
+	ns := rtState.LivenessStatistics.Node(scheduler.PublicKey)
+	ns.ProposerTimeouts++
+
+	return app.checkLivenessPenalty(ctx, rtState, scheduler.PublicKey, ns)
+}
+
+// checkLivenessPenalty penalizes the given node as configured by the runtime in case it has
+// exceeded the maximum number of allowed liveness failures in the current epoch.
+func (app *rootHashApplication) checkLivenessPenalty(
+	ctx *tmapi.Context,
+	rtState *roothash.RuntimeState,
+	nodeID signature.PublicKey,
+	ns *roothash.NodeLivenessStatistics,
+) error {
+	params := rtState.Runtime.Executor
+	if params.MaxLivenessFailures == 0 || ns.Penalized || ns.Failures() <= params.MaxLivenessFailures {
+		return nil
+	}
+	// Only penalize each node once per epoch.
+	ns.Penalized = true
+
+	regState := registryState.NewMutableState(ctx.State())
+	status, err := regState.NodeStatus(ctx, nodeID)
+	if err != nil {
+		ctx.Logger().Warn("failed to get unresponsive node status",
+			"err", err,
+			"node_id", nodeID,
+		)
+		return nil
+	}
+
+	epoch, err := ctx.AppState().GetEpoch(context.Background(), ctx.BlockHeight()+1)
+	if err != nil {
+		return err
+	}
+
+	switch params.LivenessPenalty {
+	case registry.LivenessPenaltyFreeze:
+		// Do not shorten an existing freeze.
+		if status.IsFrozen() {
+			return nil
+		}
+		status.FreezeEndTime = epoch + 1
+	default:
+		// Exclude the node from the election for the next epoch.
+		if status.ExcludedRuntimes == nil {
+			status.ExcludedRuntimes = make(map[common.Namespace]epochtime.EpochTime)
+		}
+		status.ExcludedRuntimes[rtState.Runtime.ID] = epoch + 2
+	}
+
+	if err = regState.SetNodeStatus(ctx, nodeID, status); err != nil {
+		return fmt.Errorf("failed to set node status: %w", err)
+	}
+
+	ctx.Logger().Warn("penalized executor node for liveness failures",
+		"runtime_id", rtState.Runtime.ID,
+		"node_id", nodeID,
+		"penalty", params.LivenessPenalty,
+		"rounds_missed", ns.RoundsMissed,
+		"proposer_timeouts", ns.ProposerTimeouts,
+		logging.LogEvent, roothash.LogEventExecutorLivenessPenalty,
+	)
+
+	return nil
+}
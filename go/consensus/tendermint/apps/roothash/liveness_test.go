@@ -0,0 +1,133 @@
+package roothash
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+	abciAPI "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	registryState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/registry/state"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+)
+
+func TestLivenessStatistics(t *testing.T) {
+	require := require.New(t)
+	var err error
+
+	now := time.Unix(1580461674, 0)
+	appState := abciAPI.NewMockApplicationState(&abciAPI.MockApplicationStateConfig{
+		CurrentEpoch: 10,
+	})
+	ctx := appState.NewContext(abciAPI.ContextDeliverTx, now)
+	defer ctx.Close()
+
+	var md testMsgDispatcher
+	app := rootHashApplication{appState, &md}
+
+	regState := registryState.NewMutableState(ctx.State())
+
+	runtime := registry.Runtime{
+		ID:   common.NewTestNamespaceFromSeed([]byte("roothash liveness test ns"), 0),
+		Kind: registry.KindCompute,
+		Executor: registry.ExecutorParameters{
+			MaxLivenessFailures: 1,
+			LivenessPenalty:     registry.LivenessPenaltyExclude,
+		},
+	}
+
+	// Generate two primary workers and a backup worker.
+	var (
+		nodeIDs []signature.PublicKey
+		members []*scheduler.CommitteeNode
+	)
+	roles := []scheduler.Role{scheduler.RoleWorker, scheduler.RoleWorker, scheduler.RoleBackupWorker}
+	for _, role := range roles {
+		var nodeSigner signature.Signer
+		nodeSigner, err = memorySigner.NewSigner(rand.Reader)
+		require.NoError(err, "NewSigner")
+		err = regState.SetNodeStatus(ctx, nodeSigner.Public(), &registry.NodeStatus{})
+		require.NoError(err, "SetNodeStatus")
+
+		nodeIDs = append(nodeIDs, nodeSigner.Public())
+		members = append(members, &scheduler.CommitteeNode{Role: role, PublicKey: nodeSigner.Public()})
+	}
+
+	// Only the first worker commits.
+	rtState := &roothash.RuntimeState{
+		Runtime: &runtime,
+		ExecutorPool: &commitment.Pool{
+			Runtime: &runtime,
+			Committee: &scheduler.Committee{
+				RuntimeID: runtime.ID,
+				Kind:      scheduler.KindComputeExecutor,
+				Members:   members,
+			},
+			ExecuteCommitments: map[signature.PublicKey]commitment.OpenExecutorCommitment{
+				nodeIDs[0]: {},
+			},
+		},
+	}
+
+	err = app.updateLivenessStatistics(ctx, rtState, true)
+	require.NoError(err, "updateLivenessStatistics")
+	stats := rtState.LivenessStatistics
+	require.Len(stats.Nodes, 2, "only primary workers should be tracked")
+	require.EqualValues(&roothash.NodeLivenessStatistics{RoundsElected: 1, RoundsCommitted: 1}, stats.Nodes[nodeIDs[0]])
+	require.EqualValues(&roothash.NodeLivenessStatistics{RoundsElected: 1, RoundsMissed: 1}, stats.Nodes[nodeIDs[1]])
+
+	// Rounds ending before the timeout should not count as missed.
+	err = app.updateLivenessStatistics(ctx, rtState, false)
+	require.NoError(err, "updateLivenessStatistics")
+	require.EqualValues(&roothash.NodeLivenessStatistics{RoundsElected: 2, RoundsCommitted: 2}, stats.Nodes[nodeIDs[0]])
+	require.EqualValues(&roothash.NodeLivenessStatistics{RoundsElected: 2, RoundsMissed: 1}, stats.Nodes[nodeIDs[1]])
+
+	// A single failure is tolerated.
+	status, err := regState.NodeStatus(ctx, nodeIDs[1])
+	require.NoError(err, "NodeStatus")
+	require.False(status.IsExcluded(runtime.ID, 11), "node should not be excluded yet")
+
+	// A proposer timeout should push the transaction scheduler over the limit.
+	txnScheduler, err := commitment.GetTransactionScheduler(rtState.ExecutorPool.Committee, rtState.ExecutorPool.Round)
+	require.NoError(err, "GetTransactionScheduler")
+	err = app.recordProposerTimeout(ctx, rtState)
+	require.NoError(err, "recordProposerTimeout")
+	require.EqualValues(1, stats.Nodes[txnScheduler.PublicKey].ProposerTimeouts)
+
+	// Another missed round should get the second worker penalized.
+	err = app.updateLivenessStatistics(ctx, rtState, true)
+	require.NoError(err, "updateLivenessStatistics")
+	require.EqualValues(2, stats.Nodes[nodeIDs[1]].RoundsMissed)
+	require.True(stats.Nodes[nodeIDs[1]].Penalized, "node should be penalized")
+	require.False(stats.Nodes[nodeIDs[0]].Penalized, "committing node should not be penalized")
+
+	status, err = regState.NodeStatus(ctx, nodeIDs[1])
+	require.NoError(err, "NodeStatus")
+	require.True(status.IsExcluded(runtime.ID, 11), "node should be excluded from the next election")
+	require.False(status.IsExcluded(runtime.ID, 12), "node should only be excluded for one epoch")
+	require.False(status.IsFrozen(), "node should not be frozen")
+
+	// Exclusions should be removed once they end.
+	require.False(status.PruneExcludedRuntimes(11), "exclusion should not end early")
+	require.True(status.PruneExcludedRuntimes(12), "exclusion should be removed")
+	require.Nil(status.ExcludedRuntimes)
+
+	// Switch to the freeze penalty.
+	runtime.Executor.LivenessPenalty = registry.LivenessPenaltyFreeze
+	rtState.LivenessStatistics = roothash.NewLivenessStatistics()
+	for i := 0; i < 2; i++ {
+		err = app.updateLivenessStatistics(ctx, rtState, true)
+		require.NoError(err, "updateLivenessStatistics")
+	}
+	status, err = regState.NodeStatus(ctx, nodeIDs[1])
+	require.NoError(err, "NodeStatus")
+	require.True(status.IsFrozen(), "node should be frozen")
+	require.EqualValues(11, status.FreezeEndTime)
+}
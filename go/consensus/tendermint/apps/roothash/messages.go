@@ -1,11 +1,16 @@
 package roothash
 
 import (
+	"fmt"
+
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/errors"
 	tmapi "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
 	roothashApi "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/roothash/api"
+	roothashState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/roothash/state"
+	stakingState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/staking/state"
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/message"
 	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
 )
@@ -39,6 +44,8 @@ func (app *rootHashApplication) processRuntimeMessages(
 		switch {
 		case msg.Staking != nil:
 			err = app.md.Publish(ctx, roothashApi.RuntimeMessageStaking, msg.Staking)
+		case msg.Runtime != nil:
+			err = app.queueRuntimeMessage(ctx, rtState, uint32(i), msg.Runtime)
 		default:
 			// Unsupported message.
 			err = roothash.ErrInvalidArgument
@@ -73,3 +80,151 @@ func (app *rootHashApplication) processRuntimeMessages(
 	}
 	return nil
 }
+
+// queueRuntimeMessage queues a runtime message into the incoming message queue of its destination
+// runtime after charging the message fee to the sending runtime's account.
+func (app *rootHashApplication) queueRuntimeMessage(
+	ctx *tmapi.Context,
+	rtState *roothash.RuntimeState,
+	index uint32,
+	msg *message.RuntimeMessage,
+) error {
+	if msg.To.Equal(&rtState.Runtime.ID) {
+		return roothash.ErrInvalidArgument
+	}
+
+	state := roothashState.NewMutableState(ctx.State())
+	dstState, err := state.RuntimeState(ctx, msg.To)
+	if err != nil {
+		return err
+	}
+	if dstState.Decommissioned {
+		return roothash.ErrRuntimeDecommissioned
+	}
+
+	params := &dstState.Runtime.Executor
+	if params.MaxInMessages == 0 {
+		return roothash.ErrInMessagesNotAccepted
+	}
+	if uint32(len(msg.Data)) > params.MaxInMessageSize {
+		return roothash.ErrInMessageTooBig
+	}
+	if msg.Fee.Cmp(&params.MinInMessageFee) < 0 {
+		return roothash.ErrInMessageFeeTooLow
+	}
+	meta, err := state.IncomingMessageQueueMeta(ctx, msg.To)
+	if err != nil {
+		return err
+	}
+	if meta.Size >= params.InMessageQueueSize {
+		return roothash.ErrInMessageQueueFull
+	}
+
+	// Pay the message fee to the destination runtime.
+	stakeState := stakingState.NewMutableState(ctx.State())
+	if err = stakeState.Transfer(ctx, ctx.CallerAddress(), staking.NewRuntimeAddress(msg.To), &msg.Fee); err != nil {
+		return err
+	}
+
+	inMsg := &message.IncomingMessage{
+		ID:          meta.NextSequenceNumber,
+		From:        rtState.Runtime.ID,
+		SourceRound: rtState.CurrentBlock.Header.Round + 1,
+		SourceIndex: index,
+		Fee:         msg.Fee,
+		Data:        msg.Data,
+	}
+	if err = state.SetIncomingMessageInQueue(ctx, msg.To, inMsg); err != nil {
+		return fmt.Errorf("failed to queue incoming message: %w", err)
+	}
+	meta.Size++
+	meta.NextSequenceNumber++
+	if err = state.SetIncomingMessageQueueMeta(ctx, msg.To, meta); err != nil {
+		return fmt.Errorf("failed to set incoming message queue metadata: %w", err)
+	}
+
+	ctx.Logger().Debug("queued runtime message",
+		"runtime_id", rtState.Runtime.ID,
+		"to", msg.To,
+		"id", inMsg.ID,
+	)
+
+	return nil
+}
+
+// checkIncomingMessages checks that the incoming messages processed by the runtime, as indicated
+// in the compute results header, match the head of its incoming message queue and returns the
+// processed messages.
+//
+// In case the header does not match the queue, an error wrapping roothash.ErrInvalidArgument is
+// returned.
+func (app *rootHashApplication) checkIncomingMessages(
+	ctx *tmapi.Context,
+	rtState *roothash.RuntimeState,
+	hdr *commitment.ComputeResultsHeader,
+) ([]*message.IncomingMessage, error) {
+	if hdr.InMessagesCount == 0 {
+		return nil, nil
+	}
+	if hdr.InMessagesCount > rtState.Runtime.Executor.MaxInMessages {
+		return nil, fmt.Errorf("%w: too many incoming messages", roothash.ErrInvalidArgument)
+	}
+
+	state := roothashState.NewMutableState(ctx.State())
+	msgs, err := state.IncomingMessageQueue(ctx, rtState.Runtime.ID, hdr.InMessagesCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incoming message queue: %w", err)
+	}
+	if uint32(len(msgs)) != hdr.InMessagesCount {
+		return nil, fmt.Errorf("%w: not enough queued incoming messages", roothash.ErrInvalidArgument)
+	}
+	if h := message.InMessagesHash(msgs); !h.Equal(hdr.InMessagesHash) {
+		return nil, fmt.Errorf("%w: incoming messages hash mismatch", roothash.ErrInvalidArgument)
+	}
+	return msgs, nil
+}
+
+// dequeueIncomingMessages removes the given processed messages from the runtime's incoming
+// message queue and emits delivery receipts for the sending runtimes.
+func (app *rootHashApplication) dequeueIncomingMessages(
+	ctx *tmapi.Context,
+	rtState *roothash.RuntimeState,
+	round uint64,
+	msgs []*message.IncomingMessage,
+) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	state := roothashState.NewMutableState(ctx.State())
+	meta, err := state.IncomingMessageQueueMeta(ctx, rtState.Runtime.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get incoming message queue metadata: %w", err)
+	}
+	for _, msg := range msgs {
+		if err = state.RemoveIncomingMessageFromQueue(ctx, rtState.Runtime.ID, msg.ID); err != nil {
+			return fmt.Errorf("failed to remove incoming message: %w", err)
+		}
+
+		evV := ValueInMessageDelivered{
+			ID: msg.From,
+			Event: roothash.InMessageDeliveredEvent{
+				To:          rtState.Runtime.ID,
+				ID:          msg.ID,
+				Round:       round,
+				SourceRound: msg.SourceRound,
+				SourceIndex: msg.SourceIndex,
+			},
+		}
+		ctx.EmitEvent(
+			tmapi.NewEventBuilder(app.Name()).
+				Attribute(KeyInMessageDelivered, cbor.Marshal(evV)).
+				Attribute(KeyRuntimeID, ValueRuntimeID(evV.ID)),
+		)
+	}
+	meta.Size -= uint32(len(msgs))
+	if err = state.SetIncomingMessageQueueMeta(ctx, rtState.Runtime.ID, meta); err != nil {
+		return fmt.Errorf("failed to set incoming message queue metadata: %w", err)
+	}
+	return nil
+}
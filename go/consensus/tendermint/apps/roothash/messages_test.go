@@ -0,0 +1,215 @@
+package roothash
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	cmnErrors "github.com/oasisprotocol/oasis-core/go/common/errors"
+	abciAPI "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	roothashState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/roothash/state"
+	stakingState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/staking/state"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/message"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+func TestRuntimeMessages(t *testing.T) {
+	require := require.New(t)
+	var err error
+
+	now := time.Unix(1580461674, 0)
+	appState := abciAPI.NewMockApplicationState(&abciAPI.MockApplicationStateConfig{})
+	ctx := appState.NewContext(abciAPI.ContextDeliverTx, now)
+	defer ctx.Close()
+
+	var md testMsgDispatcher
+	app := rootHashApplication{appState, &md}
+
+	state := roothashState.NewMutableState(ctx.State())
+	stakeState := stakingState.NewMutableState(ctx.State())
+
+	// Set up a sending runtime and a receiving runtime.
+	newRuntime := func(seed string, executor registry.ExecutorParameters) *roothash.RuntimeState {
+		rt := &registry.Runtime{
+			ID:       common.NewTestNamespaceFromSeed([]byte(seed), 0),
+			Kind:     registry.KindCompute,
+			Executor: executor,
+		}
+		rtState := &roothash.RuntimeState{
+			Runtime:      rt,
+			GenesisBlock: block.NewGenesisBlock(rt.ID, 0),
+			CurrentBlock: block.NewGenesisBlock(rt.ID, 0),
+		}
+		err = state.SetRuntimeState(ctx, rtState)
+		require.NoError(err, "SetRuntimeState")
+		return rtState
+	}
+	src := newRuntime("roothash messages test src ns", registry.ExecutorParameters{})
+	dst := newRuntime("roothash messages test dst ns", registry.ExecutorParameters{
+		MaxInMessages:      2,
+		MaxInMessageSize:   8,
+		InMessageQueueSize: 3,
+		MinInMessageFee:    mustQuantity(t, 5),
+	})
+	closed := newRuntime("roothash messages test closed ns", registry.ExecutorParameters{})
+
+	srcAddr := staking.NewRuntimeAddress(src.Runtime.ID)
+	srcAcct := &staking.Account{}
+	srcAcct.General.Balance = mustQuantity(t, 17)
+	err = stakeState.SetAccount(ctx, srcAddr, srcAcct)
+	require.NoError(err, "SetAccount")
+
+	newMsg := func(to common.Namespace, fee uint64, data string) message.Message {
+		return message.Message{Runtime: &message.RuntimeMessage{
+			To:   to,
+			Fee:  mustQuantity(t, fee),
+			Data: []byte(data),
+		}}
+	}
+	msgs := []message.Message{
+		newMsg(dst.Runtime.ID, 5, "first"),
+		newMsg(dst.Runtime.ID, 1, "cheap"),
+		newMsg(dst.Runtime.ID, 5, "too big payload"),
+		newMsg(src.Runtime.ID, 5, "self"),
+		newMsg(closed.Runtime.ID, 5, "closed"),
+		newMsg(common.NewTestNamespaceFromSeed([]byte("roothash messages test missing ns"), 0), 5, "missing"),
+		newMsg(dst.Runtime.ID, 6, "second"),
+		newMsg(dst.Runtime.ID, 100, "broke"),
+		newMsg(dst.Runtime.ID, 5, "third"),
+	}
+	err = app.processRuntimeMessages(ctx, src, msgs)
+	require.NoError(err, "processRuntimeMessages")
+
+	// Check message results.
+	var results []roothash.MessageEvent
+	for _, ev := range ctx.GetEvents() {
+		for _, pair := range ev.GetAttributes() {
+			if !bytes.Equal(pair.GetKey(), KeyMessage) {
+				continue
+			}
+			var value ValueMessage
+			err = cbor.Unmarshal(pair.GetValue(), &value)
+			require.NoError(err, "cbor.Unmarshal")
+			results = append(results, value.Event)
+		}
+	}
+	require.Len(results, len(msgs), "there should be a result for each message")
+	for i, expected := range []error{
+		nil,
+		roothash.ErrInMessageFeeTooLow,
+		roothash.ErrInMessageTooBig,
+		roothash.ErrInvalidArgument,
+		roothash.ErrInMessagesNotAccepted,
+		roothash.ErrInvalidRuntime,
+		nil,
+		staking.ErrInsufficientBalance,
+		nil,
+	} {
+		if expected == nil {
+			require.True(results[i].IsSuccess(), "message %d should be queued", i)
+			continue
+		}
+		module, code := cmnErrors.Code(expected)
+		require.Equal(module, results[i].Module, "message %d should be rejected with the expected error", i)
+		require.Equal(code, results[i].Code, "message %d should be rejected with the expected error", i)
+	}
+
+	// Message fees should be paid to the destination runtime.
+	srcAcct, err = stakeState.Account(ctx, srcAddr)
+	require.NoError(err, "Account")
+	require.EqualValues(mustQuantity(t, 1), srcAcct.General.Balance, "fees should be charged to the sender")
+	dstAcct, err := stakeState.Account(ctx, staking.NewRuntimeAddress(dst.Runtime.ID))
+	require.NoError(err, "Account")
+	require.EqualValues(mustQuantity(t, 16), dstAcct.General.Balance, "fees should be paid to the destination")
+
+	// Messages should be queued in order.
+	queue, err := state.IncomingMessageQueue(ctx, dst.Runtime.ID, 0)
+	require.NoError(err, "IncomingMessageQueue")
+	require.Len(queue, 3, "accepted messages should be queued")
+	for i, data := range []string{"first", "second", "third"} {
+		require.EqualValues(i, queue[i].ID, "messages should be assigned consecutive sequence numbers")
+		require.EqualValues(src.Runtime.ID, queue[i].From)
+		require.EqualValues(1, queue[i].SourceRound)
+		require.EqualValues(data, string(queue[i].Data))
+	}
+	require.EqualValues(6, queue[1].SourceIndex)
+	require.EqualValues(8, queue[2].SourceIndex)
+
+	// The queue should now be full.
+	srcAcct.General.Balance = mustQuantity(t, 100)
+	err = stakeState.SetAccount(ctx, srcAddr, srcAcct)
+	require.NoError(err, "SetAccount")
+	srcCtx := ctx.WithCallerAddress(srcAddr)
+	err = app.queueRuntimeMessage(srcCtx, src, 0, newMsg(dst.Runtime.ID, 5, "full").Runtime)
+	srcCtx.Close()
+	require.True(errors.Is(err, roothash.ErrInMessageQueueFull), "messages should be rejected when the queue is full")
+
+	// Processed incoming messages must match the queue.
+	inMsgsHash := func(msgs []*message.IncomingMessage) *hash.Hash {
+		h := message.InMessagesHash(msgs)
+		return &h
+	}
+	for _, tc := range []struct {
+		name  string
+		hdr   commitment.ComputeResultsHeader
+		valid bool
+	}{
+		{"NoMessages", commitment.ComputeResultsHeader{}, true},
+		{"Valid", commitment.ComputeResultsHeader{InMessagesHash: inMsgsHash(queue[:2]), InMessagesCount: 2}, true},
+		{"TooMany", commitment.ComputeResultsHeader{InMessagesHash: inMsgsHash(queue), InMessagesCount: 3}, false},
+		{"WrongHash", commitment.ComputeResultsHeader{InMessagesHash: inMsgsHash(queue[1:2]), InMessagesCount: 1}, false},
+	} {
+		processed, cErr := app.checkIncomingMessages(ctx, dst, &tc.hdr)
+		if !tc.valid {
+			require.True(errors.Is(cErr, roothash.ErrInvalidArgument), tc.name)
+			continue
+		}
+		require.NoError(cErr, tc.name)
+		require.Len(processed, int(tc.hdr.InMessagesCount), tc.name)
+		for i, msg := range processed {
+			require.EqualValues(queue[i], msg, tc.name)
+		}
+	}
+
+	// Dequeue processed messages and check delivery receipts.
+	err = app.dequeueIncomingMessages(ctx, dst, 1, queue[:2])
+	require.NoError(err, "dequeueIncomingMessages")
+
+	var receipts []ValueInMessageDelivered
+	for _, ev := range ctx.GetEvents() {
+		for _, pair := range ev.GetAttributes() {
+			if !bytes.Equal(pair.GetKey(), KeyInMessageDelivered) {
+				continue
+			}
+			var value ValueInMessageDelivered
+			err = cbor.Unmarshal(pair.GetValue(), &value)
+			require.NoError(err, "cbor.Unmarshal")
+			receipts = append(receipts, value)
+		}
+	}
+	require.Len(receipts, 2, "a receipt should be emitted for each delivered message")
+	for i, receipt := range receipts {
+		require.EqualValues(src.Runtime.ID, receipt.ID, "receipts should be emitted for the sender")
+		require.EqualValues(dst.Runtime.ID, receipt.Event.To)
+		require.EqualValues(queue[i].ID, receipt.Event.ID)
+		require.EqualValues(1, receipt.Event.Round)
+		require.EqualValues(queue[i].SourceIndex, receipt.Event.SourceIndex)
+	}
+
+	remaining, err := state.IncomingMessageQueue(ctx, dst.Runtime.ID, 0)
+	require.NoError(err, "IncomingMessageQueue")
+	require.EqualValues(queue[2:], remaining, "delivered messages should be removed from the queue")
+	meta, err := state.IncomingMessageQueueMeta(ctx, dst.Runtime.ID)
+	require.NoError(err, "IncomingMessageQueueMeta")
+	require.EqualValues(&roothash.IncomingMessageQueueMeta{Size: 1, NextSequenceNumber: 3}, meta)
+}
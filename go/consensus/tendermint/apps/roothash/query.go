@@ -8,6 +8,7 @@ import (
 	roothashState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/roothash/state"
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/message"
 )
 
 // Query is the roothash query interface.
@@ -15,6 +16,9 @@ type Query interface {
 	LatestBlock(context.Context, common.Namespace) (*block.Block, error)
 	GenesisBlock(context.Context, common.Namespace) (*block.Block, error)
 	RuntimeState(context.Context, common.Namespace) (*roothash.RuntimeState, error)
+	LivenessStatistics(context.Context, common.Namespace) (*roothash.LivenessStatistics, error)
+	IncomingMessageQueueMeta(context.Context, common.Namespace) (*roothash.IncomingMessageQueueMeta, error)
+	IncomingMessageQueue(context.Context, common.Namespace, uint32) ([]*message.IncomingMessage, error)
 	Genesis(context.Context) (*roothash.Genesis, error)
 }
 
@@ -56,6 +60,33 @@ func (rq *rootHashQuerier) RuntimeState(ctx context.Context, id common.Namespace
 	return rq.state.RuntimeState(ctx, id)
 }
 
+func (rq *rootHashQuerier) LivenessStatistics(ctx context.Context, id common.Namespace) (*roothash.LivenessStatistics, error) {
+	runtime, err := rq.state.RuntimeState(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if runtime.LivenessStatistics == nil {
+		return roothash.NewLivenessStatistics(), nil
+	}
+	return runtime.LivenessStatistics, nil
+}
+
+func (rq *rootHashQuerier) IncomingMessageQueueMeta(ctx context.Context, id common.Namespace) (*roothash.IncomingMessageQueueMeta, error) {
+	// Make sure the runtime exists.
+	if _, err := rq.state.RuntimeState(ctx, id); err != nil {
+		return nil, err
+	}
+	return rq.state.IncomingMessageQueueMeta(ctx, id)
+}
+
+func (rq *rootHashQuerier) IncomingMessageQueue(ctx context.Context, id common.Namespace, limit uint32) ([]*message.IncomingMessage, error) {
+	// Make sure the runtime exists.
+	if _, err := rq.state.RuntimeState(ctx, id); err != nil {
+		return nil, err
+	}
+	return rq.state.IncomingMessageQueue(ctx, id, limit)
+}
+
 func (app *rootHashApplication) QueryFactory() interface{} {
 	return &QueryFactory{app.state}
 }
@@ -2,6 +2,7 @@
 package roothash
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/tendermint/tendermint/abci/types"
@@ -24,6 +25,7 @@ import (
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/message"
 	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
 	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
 )
@@ -63,6 +65,8 @@ func (app *rootHashApplication) OnRegister(state tmapi.ApplicationState, md tmap
 	md.Subscribe(registryApi.MessageNewRuntimeRegistered, app)
 	md.Subscribe(registryApi.MessageRuntimeUpdated, app)
 	md.Subscribe(registryApi.MessageRuntimeResumed, app)
+	md.Subscribe(registryApi.MessageRuntimeSuspended, app)
+	md.Subscribe(registryApi.MessageRuntimeDecommissioned, app)
 	md.Subscribe(roothashApi.RuntimeMessageNoop, app)
 }
 
@@ -142,10 +146,27 @@ func (app *rootHashApplication) onCommitteeChanged(ctx *tmapi.Context, epoch epo
 				sufficientStake = false
 			}
 		}
-		if (empty || !sufficientStake) && !params.DebugDoNotSuspendRuntimes {
+		switch {
+		case params.DebugDoNotSuspendRuntimes:
+		case empty:
 			if err = app.suspendUnpaidRuntime(ctx, rtState, regState); err != nil {
 				return err
 			}
+		case !sufficientStake:
+			// Give the runtime owner a grace period to top up the stake before suspending.
+			if rtState.SuspensionDeadline == 0 {
+				rtState.SuspensionDeadline = epoch + params.SuspensionGracePeriod
+				if params.SuspensionGracePeriod > 0 {
+					app.emitSuspensionNotice(ctx, rtState)
+				}
+			}
+			if epoch >= rtState.SuspensionDeadline {
+				if err = app.suspendUnpaidRuntime(ctx, rtState, regState); err != nil {
+					return err
+				}
+			}
+		default:
+			rtState.SuspensionDeadline = 0
 		}
 
 		// If the committee has actually changed, force a new round.
@@ -169,6 +190,9 @@ func (app *rootHashApplication) onCommitteeChanged(ctx *tmapi.Context, epoch epo
 			// Set the executor pool.
 			rtState.ExecutorPool = executorPool
 			rtState.ExecutorPool.Round = rtState.CurrentBlock.Header.Round
+
+			// Reset liveness statistics for the new committee.
+			rtState.LivenessStatistics = roothash.NewLivenessStatistics()
 		}
 
 		// Update the runtime descriptor to the latest per-epoch value.
@@ -195,17 +219,45 @@ func (app *rootHashApplication) suspendUnpaidRuntime(
 		return err
 	}
 
-	rtState.Suspended = true
-	rtState.ExecutorPool = nil
+	return app.suspendRuntime(ctx, rtState)
+}
 
-	// Emity an empty block signalling that the runtime was suspended.
+// suspendRuntime marks the runtime as suspended and emits an empty block signalling that the
+// runtime was suspended. The caller is responsible for suspending the runtime in the registry.
+func (app *rootHashApplication) suspendRuntime(ctx *tmapi.Context, rtState *roothash.RuntimeState) error {
+	// Emit an empty block signalling that the runtime was suspended. This also clears any
+	// scheduled round timeout.
 	if err := app.emitEmptyBlock(ctx, rtState, block.Suspended); err != nil {
 		return fmt.Errorf("failed to emit empty block: %w", err)
 	}
 
+	rtState.Suspended = true
+	rtState.SuspensionDeadline = 0
+	rtState.ExecutorPool = nil
+	rtState.LivenessStatistics = nil
+
 	return nil
 }
 
+func (app *rootHashApplication) emitSuspensionNotice(ctx *tmapi.Context, rtState *roothash.RuntimeState) {
+	ctx.Logger().Warn("insufficient stake for runtime operation, runtime will be suspended",
+		"runtime_id", rtState.Runtime.ID,
+		"deadline", rtState.SuspensionDeadline,
+	)
+
+	tagV := ValueSuspensionNotice{
+		ID: rtState.Runtime.ID,
+		Event: roothash.SuspensionNoticeEvent{
+			Deadline: rtState.SuspensionDeadline,
+		},
+	}
+	ctx.EmitEvent(
+		tmapi.NewEventBuilder(app.Name()).
+			Attribute(KeySuspensionNotice, cbor.Marshal(tagV)).
+			Attribute(KeyRuntimeID, ValueRuntimeID(rtState.Runtime.ID)),
+	)
+}
+
 func (app *rootHashApplication) prepareNewCommittees(
 	ctx *tmapi.Context,
 	epoch epochtime.EpochTime,
@@ -296,6 +348,12 @@ func (app *rootHashApplication) ExecuteMessage(ctx *tmapi.Context, kind, msg int
 	case registryApi.MessageRuntimeResumed:
 		// A previously suspended runtime has been resumed.
 		return nil
+	case registryApi.MessageRuntimeSuspended:
+		// A runtime has been explicitly suspended by its owner.
+		return app.onRuntimeSuspended(ctx, msg.(*registry.Runtime), false)
+	case registryApi.MessageRuntimeDecommissioned:
+		// A runtime has been permanently decommissioned by its owner.
+		return app.onRuntimeSuspended(ctx, msg.(*registry.Runtime), true)
 	case roothashApi.RuntimeMessageNoop:
 		// Noop message always succeeds.
 		return nil
@@ -304,6 +362,38 @@ func (app *rootHashApplication) ExecuteMessage(ctx *tmapi.Context, kind, msg int
 	}
 }
 
+func (app *rootHashApplication) onRuntimeSuspended(ctx *tmapi.Context, rt *registry.Runtime, decommissioned bool) error {
+	state := roothashState.NewMutableState(ctx.State())
+
+	rtState, err := state.RuntimeState(ctx, rt.ID)
+	switch err {
+	case nil:
+	case roothash.ErrInvalidRuntime:
+		// Non-compute runtimes have no roothash state.
+		return nil
+	default:
+		return fmt.Errorf("failed to fetch runtime state: %w", err)
+	}
+
+	ctx.Logger().Debug("runtime suspended by owner",
+		"runtime_id", rt.ID,
+		"decommissioned", decommissioned,
+	)
+
+	if !rtState.Suspended {
+		if err = app.suspendRuntime(ctx, rtState); err != nil {
+			return err
+		}
+	}
+	// The state root of a decommissioned runtime is frozen at the current block.
+	rtState.Decommissioned = rtState.Decommissioned || decommissioned
+
+	if err = state.SetRuntimeState(ctx, rtState); err != nil {
+		return fmt.Errorf("failed to set runtime state: %w", err)
+	}
+	return nil
+}
+
 func (app *rootHashApplication) verifyRuntimeUpdate(ctx *tmapi.Context, rt *registry.Runtime) error {
 	state := roothashState.NewMutableState(ctx.State())
 
@@ -392,6 +482,22 @@ func (app *rootHashApplication) onNewRuntime(ctx *tmapi.Context, runtime *regist
 						Attribute(KeyRuntimeID, ValueRuntimeID(evV.ID)),
 				)
 			}
+
+			// Restore the incoming message queue.
+			if n := len(genesisRts.IncomingMessages); n > 0 {
+				for _, msg := range genesisRts.IncomingMessages {
+					if err = state.SetIncomingMessageInQueue(ctx, runtime.ID, msg); err != nil {
+						return fmt.Errorf("failed to restore incoming message: %w", err)
+					}
+				}
+				err = state.SetIncomingMessageQueueMeta(ctx, runtime.ID, &roothash.IncomingMessageQueueMeta{
+					Size:               uint32(n),
+					NextSequenceNumber: genesisRts.IncomingMessages[n-1].ID + 1,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to restore incoming message queue metadata: %w", err)
+				}
+			}
 		}
 	}
 
@@ -486,6 +592,34 @@ func (app *rootHashApplication) tryFinalizeExecutorCommits(
 	round := rtState.CurrentBlock.Header.Round + 1
 
 	commit, err := rtState.ExecutorPool.TryFinalize(ctx.BlockHeight(), runtime.Executor.RoundTimeout, forced, true)
+	// Without a timeout, the round can only proceed once all primary workers have committed, so
+	// primary workers can only have missed the round in case it timed out or is in the discrepancy
+	// resolution state (which it entered either due to a timeout or with all primary commitments).
+	primaryTimedOut := forced || rtState.ExecutorPool.Discrepancy
+
+	// In case the discrepancy has been resolved by the backup workers, handle any nodes that
+	// committed results disagreeing with the majority.
+	if rtState.ExecutorPool.Discrepancy && (err == nil || err == commitment.ErrBadProposerCommitment) {
+		if merr := app.processExecutorMisbehavior(ctx, rtState, round); merr != nil {
+			// Failing to process misbehavior should not prevent the round from being finalized.
+			ctx.Logger().Error("failed to process executor misbehavior",
+				"err", merr,
+				"round", round,
+			)
+		}
+	}
+
+	// Make sure that the processed incoming messages match the incoming message queue, otherwise
+	// the round fails.
+	var inMsgs []*message.IncomingMessage
+	if err == nil {
+		body := commit.ToDDResult().(*commitment.ComputeBody)
+		inMsgs, err = app.checkIncomingMessages(ctx, rtState, &body.Header)
+		if err != nil && !errors.Is(err, roothash.ErrInvalidArgument) {
+			return err
+		}
+	}
+
 	switch err {
 	case nil:
 		// Round has been finalized.
@@ -501,12 +635,22 @@ func (app *rootHashApplication) tryFinalizeExecutorCommits(
 			return fmt.Errorf("failed to process runtime messages: %w", err)
 		}
 
+		// Dequeue any processed incoming messages.
+		if err = app.dequeueIncomingMessages(ctx, rtState, round, inMsgs); err != nil {
+			return fmt.Errorf("failed to dequeue incoming messages: %w", err)
+		}
+
 		// Generate the final block.
 		blk := block.NewEmptyBlock(rtState.CurrentBlock, uint64(ctx.Now().Unix()), block.Normal)
 		blk.Header.IORoot = *hdr.IORoot
 		blk.Header.StateRoot = *hdr.StateRoot
 		blk.Header.MessagesHash = *hdr.MessagesHash
 
+		// Update executor liveness statistics before the commitments are reset.
+		if err = app.updateLivenessStatistics(ctx, rtState, primaryTimedOut); err != nil {
+			return fmt.Errorf("failed to update liveness statistics: %w", err)
+		}
+
 		// Timeout will be cleared by caller.
 		rtState.ExecutorPool.ResetCommitments(blk.Header.Round)
 
@@ -563,6 +707,9 @@ func (app *rootHashApplication) tryFinalizeExecutorCommits(
 		logging.LogEvent, roothash.LogEventRoundFailed,
 	)
 
+	if err := app.updateLivenessStatistics(ctx, rtState, primaryTimedOut); err != nil {
+		return fmt.Errorf("failed to update liveness statistics: %w", err)
+	}
 	if err := app.emitEmptyBlock(ctx, rtState, block.RoundFailed); err != nil {
 		return fmt.Errorf("failed to emit empty block: %w", err)
 	}
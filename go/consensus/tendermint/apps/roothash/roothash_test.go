@@ -0,0 +1,68 @@
+package roothash
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	abciAPI "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	registryApi "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/registry/api"
+	roothashState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/roothash/state"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+)
+
+func TestRuntimeSuspension(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Unix(1580461674, 0)
+	appState := abciAPI.NewMockApplicationState(&abciAPI.MockApplicationStateConfig{})
+	ctx := appState.NewContext(abciAPI.ContextDeliverTx, now)
+	defer ctx.Close()
+
+	var md testMsgDispatcher
+	app := rootHashApplication{appState, &md}
+	state := roothashState.NewMutableState(ctx.State())
+
+	runtime := &registry.Runtime{
+		ID:   common.NewTestNamespaceFromSeed([]byte("roothash suspension test ns"), 0),
+		Kind: registry.KindCompute,
+	}
+	genesisBlock := block.NewGenesisBlock(runtime.ID, 0)
+	err := state.SetRuntimeState(ctx, &roothash.RuntimeState{
+		Runtime:      runtime,
+		CurrentBlock: genesisBlock,
+		GenesisBlock: genesisBlock,
+	})
+	require.NoError(err, "SetRuntimeState")
+
+	// Messages for runtimes without roothash state should be ignored.
+	otherRuntime := &registry.Runtime{
+		ID: common.NewTestNamespaceFromSeed([]byte("roothash suspension test ns"), 1),
+	}
+	err = app.ExecuteMessage(ctx, registryApi.MessageRuntimeSuspended, otherRuntime)
+	require.NoError(err, "ExecuteMessage(MessageRuntimeSuspended) for unknown runtime")
+
+	// Suspending a runtime should emit a suspended block.
+	err = app.ExecuteMessage(ctx, registryApi.MessageRuntimeSuspended, runtime)
+	require.NoError(err, "ExecuteMessage(MessageRuntimeSuspended)")
+	rtState, err := state.RuntimeState(ctx, runtime.ID)
+	require.NoError(err, "RuntimeState")
+	require.True(rtState.Suspended, "runtime should be suspended")
+	require.False(rtState.Decommissioned, "runtime should not be decommissioned")
+	require.EqualValues(block.Suspended, rtState.CurrentBlock.Header.HeaderType, "suspended block should be emitted")
+	require.EqualValues(1, rtState.CurrentBlock.Header.Round, "suspended block should be emitted")
+
+	// Decommissioning a suspended runtime should freeze its state without emitting another block.
+	err = app.ExecuteMessage(ctx, registryApi.MessageRuntimeDecommissioned, runtime)
+	require.NoError(err, "ExecuteMessage(MessageRuntimeDecommissioned)")
+	rtState, err = state.RuntimeState(ctx, runtime.ID)
+	require.NoError(err, "RuntimeState")
+	require.True(rtState.Suspended, "runtime should be suspended")
+	require.True(rtState.Decommissioned, "runtime should be decommissioned")
+	require.EqualValues(1, rtState.CurrentBlock.Header.Round, "no further blocks should be emitted")
+	require.Equal(genesisBlock.Header.StateRoot, rtState.CurrentBlock.Header.StateRoot, "state root should be frozen")
+}
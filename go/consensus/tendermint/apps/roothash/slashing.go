@@ -0,0 +1,204 @@
+package roothash
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	tmapi "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	registryState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/registry/state"
+	stakingState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/staking/state"
+	epochtime "github.com/oasisprotocol/oasis-core/go/epochtime/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// processExecutorMisbehavior handles executor nodes that committed results disagreeing with the
+// discrepancy resolution majority. It emits misbehavior events, slashes the misbehaving nodes and
+// rewards the nodes that agreed with the majority from the runtime's escrow as configured by the
+// runtime.
+//
+// The executor pool must be in the discrepancy resolution state.
+func (app *rootHashApplication) processExecutorMisbehavior(
+	ctx *tmapi.Context,
+	rtState *roothash.RuntimeState,
+	round uint64,
+) error {
+	agreeing, disagreeing, err := rtState.ExecutorPool.ClassifyCommitments()
+	if err != nil {
+		// No majority, so there is nothing to attribute the discrepancy to.
+		return nil
+	}
+	if len(disagreeing) == 0 {
+		return nil
+	}
+
+	runtime := rtState.Runtime
+	for _, id := range disagreeing {
+		ctx.Logger().Warn("executor node committed incorrect results",
+			"round", round,
+			"node_id", id,
+			logging.LogEvent, roothash.LogEventExecutorMisbehavior,
+		)
+
+		tagV := ValueExecutorMisbehavior{
+			ID: runtime.ID,
+			Event: roothash.ExecutorMisbehaviorEvent{
+				Round:  round,
+				NodeID: id,
+			},
+		}
+		ctx.EmitEvent(
+			tmapi.NewEventBuilder(app.Name()).
+				Attribute(KeyExecutorMisbehavior, cbor.Marshal(tagV)).
+				Attribute(KeyRuntimeID, ValueRuntimeID(runtime.ID)),
+		)
+	}
+
+	penalty, ok := runtime.Staking.Slashing[staking.SlashRuntimeIncorrectResults]
+	if !ok {
+		return nil
+	}
+
+	// Make sure that nodes are either slashed and rewarded or there are no changes at all.
+	sc := ctx.StartCheckpoint()
+	defer sc.Close()
+
+	var slashedAny bool
+	for _, id := range disagreeing {
+		var slashed *quantity.Quantity
+		if slashed, err = onRuntimeIncorrectResults(ctx, runtime, id, &penalty); err != nil {
+			return err
+		}
+		slashedAny = slashedAny || !slashed.IsZero()
+	}
+
+	// Only reward nodes in case misbehavior was actually punished so that the same discrepancy
+	// is not rewarded multiple times.
+	if !runtime.Staking.RewardIncorrectResults.IsZero() && slashedAny {
+		if err = rewardRuntimeCorrectResults(ctx, runtime, agreeing); err != nil {
+			return err
+		}
+	}
+	sc.Commit()
+
+	return nil
+}
+
+// onRuntimeIncorrectResults slashes and freezes the given node for committing incorrect results
+// and returns the slashed amount.
+func onRuntimeIncorrectResults(
+	ctx *tmapi.Context,
+	runtime *registry.Runtime,
+	nodeID signature.PublicKey,
+	penalty *staking.Slash,
+) (*quantity.Quantity, error) {
+	regState := registryState.NewMutableState(ctx.State())
+	stakeState := stakingState.NewMutableState(ctx.State())
+
+	node, err := regState.Node(ctx, nodeID)
+	if err != nil {
+		ctx.Logger().Warn("failed to get misbehaving node",
+			"err", err,
+			"node_id", nodeID,
+		)
+		return quantity.NewQuantity(), nil
+	}
+
+	nodeStatus, err := regState.NodeStatus(ctx, node.ID)
+	if err != nil {
+		ctx.Logger().Warn("failed to get misbehaving node status",
+			"err", err,
+			"node_id", node.ID,
+		)
+		return quantity.NewQuantity(), nil
+	}
+
+	// Do not slash a frozen node.
+	if nodeStatus.IsFrozen() {
+		ctx.Logger().Debug("not slashing frozen node",
+			"node_id", node.ID,
+			"entity_id", node.EntityID,
+			"freeze_end_time", nodeStatus.FreezeEndTime,
+		)
+		return quantity.NewQuantity(), nil
+	}
+
+	// Freeze node to prevent it being slashed again. This also prevents the node from being
+	// scheduled in the next epoch.
+	if penalty.FreezeInterval > 0 {
+		var epoch epochtime.EpochTime
+		epoch, err = ctx.AppState().GetEpoch(context.Background(), ctx.BlockHeight()+1)
+		if err != nil {
+			return nil, err
+		}
+
+		// Check for overflow.
+		if math.MaxUint64-penalty.FreezeInterval < epoch {
+			nodeStatus.FreezeEndTime = registry.FreezeForever
+		} else {
+			nodeStatus.FreezeEndTime = epoch + penalty.FreezeInterval
+		}
+	}
+
+	entityAddr := staking.NewAddress(node.EntityID)
+	slashed, err := stakeState.SlashEscrow(ctx, entityAddr, &penalty.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to slash node entity: %w", err)
+	}
+
+	if err = regState.SetNodeStatus(ctx, node.ID, nodeStatus); err != nil {
+		return nil, fmt.Errorf("failed to set node status: %w", err)
+	}
+
+	ctx.Logger().Warn("slashed executor node for incorrect results",
+		"runtime_id", runtime.ID,
+		"node_id", node.ID,
+		"entity_id", node.EntityID,
+		"slashed", slashed,
+	)
+
+	return slashed, nil
+}
+
+// rewardRuntimeCorrectResults rewards the entities of the given nodes from the runtime's escrow
+// for committing correct results in a round where incorrect results were detected.
+func rewardRuntimeCorrectResults(
+	ctx *tmapi.Context,
+	runtime *registry.Runtime,
+	nodeIDs []signature.PublicKey,
+) error {
+	regState := registryState.NewMutableState(ctx.State())
+	stakeState := stakingState.NewMutableState(ctx.State())
+
+	runtimeAddr := staking.NewRuntimeAddress(runtime.ID)
+	for _, id := range nodeIDs {
+		node, err := regState.Node(ctx, id)
+		if err != nil {
+			ctx.Logger().Warn("failed to get rewarded node",
+				"err", err,
+				"node_id", id,
+			)
+			continue
+		}
+
+		entityAddr := staking.NewAddress(node.EntityID)
+		transferred, err := stakeState.TransferEscrow(ctx, runtimeAddr, entityAddr, &runtime.Staking.RewardIncorrectResults)
+		if err != nil {
+			return fmt.Errorf("failed to reward node entity: %w", err)
+		}
+		if !transferred {
+			ctx.Logger().Warn("runtime escrow depleted, not rewarding executor nodes",
+				"runtime_id", runtime.ID,
+			)
+			return nil
+		}
+	}
+
+	return nil
+}
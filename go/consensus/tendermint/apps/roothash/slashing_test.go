@@ -0,0 +1,179 @@
+package roothash
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	abciAPI "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
+	registryState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/registry/state"
+	stakingState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/staking/state"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+func mustQuantity(t *testing.T, n uint64) quantity.Quantity {
+	var q quantity.Quantity
+	require.NoError(t, q.FromUint64(n), "FromUint64")
+	return q
+}
+
+func TestExecutorMisbehavior(t *testing.T) {
+	require := require.New(t)
+	var err error
+
+	now := time.Unix(1580461674, 0)
+	appState := abciAPI.NewMockApplicationState(&abciAPI.MockApplicationStateConfig{
+		CurrentEpoch: 10,
+	})
+	ctx := appState.NewContext(abciAPI.ContextDeliverTx, now)
+	defer ctx.Close()
+
+	var md testMsgDispatcher
+	app := rootHashApplication{appState, &md}
+
+	regState := registryState.NewMutableState(ctx.State())
+	stakeState := stakingState.NewMutableState(ctx.State())
+
+	runtime := registry.Runtime{
+		ID:   common.NewTestNamespaceFromSeed([]byte("roothash slashing test ns"), 0),
+		Kind: registry.KindCompute,
+		Staking: registry.RuntimeStakingParameters{
+			Slashing: map[staking.SlashReason]staking.Slash{
+				staking.SlashRuntimeIncorrectResults: {
+					Amount:         mustQuantity(t, 40),
+					FreezeInterval: 5,
+				},
+			},
+			RewardIncorrectResults: mustQuantity(t, 25),
+		},
+	}
+
+	err = stakeState.SetCommonPool(ctx, quantity.NewQuantity())
+	require.NoError(err, "SetCommonPool")
+	runtimeAddr := staking.NewRuntimeAddress(runtime.ID)
+	runtimeAcct := &staking.Account{}
+	runtimeAcct.Escrow.Active.Balance = mustQuantity(t, 40)
+	err = stakeState.SetAccount(ctx, runtimeAddr, runtimeAcct)
+	require.NoError(err, "SetAccount")
+
+	// Generate three nodes, each belonging to a different entity. The first node is the worker
+	// committing correct results, the second node is the worker committing incorrect results and
+	// the third node is the backup worker resolving the discrepancy.
+	var (
+		nodeIDs     []signature.PublicKey
+		entityAddrs []staking.Address
+		members     []*scheduler.CommitteeNode
+	)
+	roles := []scheduler.Role{scheduler.RoleWorker, scheduler.RoleWorker, scheduler.RoleBackupWorker}
+	for _, role := range roles {
+		var nodeSigner, entitySigner signature.Signer
+		nodeSigner, err = memorySigner.NewSigner(rand.Reader)
+		require.NoError(err, "NewSigner")
+		entitySigner, err = memorySigner.NewSigner(rand.Reader)
+		require.NoError(err, "NewSigner")
+
+		n := &node.Node{
+			Versioned: cbor.NewVersioned(node.LatestNodeDescriptorVersion),
+			ID:        nodeSigner.Public(),
+			EntityID:  entitySigner.Public(),
+		}
+		var sigNode *node.MultiSignedNode
+		sigNode, err = node.MultiSignNode([]signature.Signer{nodeSigner}, registry.RegisterNodeSignatureContext, n)
+		require.NoError(err, "MultiSignNode")
+		err = regState.SetNode(ctx, nil, n, sigNode)
+		require.NoError(err, "SetNode")
+		err = regState.SetNodeStatus(ctx, n.ID, &registry.NodeStatus{})
+		require.NoError(err, "SetNodeStatus")
+
+		entityAddr := staking.NewAddress(entitySigner.Public())
+		entityAcct := &staking.Account{}
+		entityAcct.Escrow.Active.Balance = mustQuantity(t, 100)
+		err = stakeState.SetAccount(ctx, entityAddr, entityAcct)
+		require.NoError(err, "SetAccount")
+
+		nodeIDs = append(nodeIDs, n.ID)
+		entityAddrs = append(entityAddrs, entityAddr)
+		members = append(members, &scheduler.CommitteeNode{Role: role, PublicKey: n.ID})
+	}
+
+	// Prepare the executor pool in discrepancy resolution state.
+	var goodRoot, badRoot hash.Hash
+	goodRoot.FromBytes([]byte("good state root"))
+	badRoot.FromBytes([]byte("bad state root"))
+	openCommit := func(stateRoot hash.Hash) commitment.OpenExecutorCommitment {
+		return commitment.OpenExecutorCommitment{
+			Body: &commitment.ComputeBody{
+				Header: commitment.ComputeResultsHeader{
+					Round:     1,
+					StateRoot: &stateRoot,
+				},
+			},
+		}
+	}
+	rtState := &roothash.RuntimeState{
+		Runtime: &runtime,
+		ExecutorPool: &commitment.Pool{
+			Runtime: &runtime,
+			Committee: &scheduler.Committee{
+				RuntimeID: runtime.ID,
+				Kind:      scheduler.KindComputeExecutor,
+				Members:   members,
+			},
+			Round: 0,
+			ExecuteCommitments: map[signature.PublicKey]commitment.OpenExecutorCommitment{
+				nodeIDs[0]: openCommit(goodRoot),
+				nodeIDs[1]: openCommit(badRoot),
+				nodeIDs[2]: openCommit(goodRoot),
+			},
+			Discrepancy: true,
+		},
+	}
+
+	err = app.processExecutorMisbehavior(ctx, rtState, 1)
+	require.NoError(err, "processExecutorMisbehavior")
+
+	// The misbehaving node's entity should be slashed and the node frozen.
+	acct, err := stakeState.Account(ctx, entityAddrs[1])
+	require.NoError(err, "Account")
+	require.Equal(mustQuantity(t, 60), acct.Escrow.Active.Balance, "misbehaving entity should be slashed")
+	status, err := regState.NodeStatus(ctx, nodeIDs[1])
+	require.NoError(err, "NodeStatus")
+	require.EqualValues(15, status.FreezeEndTime, "misbehaving node should be frozen")
+	commonPool, err := stakeState.CommonPool(ctx)
+	require.NoError(err, "CommonPool")
+	require.Equal(mustQuantity(t, 40), *commonPool, "slashed amount should go to the common pool")
+
+	// Honest nodes' entities should be rewarded from the runtime's escrow until it is depleted.
+	acct, err = stakeState.Account(ctx, entityAddrs[0])
+	require.NoError(err, "Account")
+	require.Equal(mustQuantity(t, 125), acct.Escrow.Active.Balance, "honest entity should be rewarded")
+	acct, err = stakeState.Account(ctx, entityAddrs[2])
+	require.NoError(err, "Account")
+	require.Equal(mustQuantity(t, 115), acct.Escrow.Active.Balance, "honest entity should get remaining runtime escrow")
+	acct, err = stakeState.Account(ctx, runtimeAddr)
+	require.NoError(err, "Account")
+	require.True(acct.Escrow.Active.Balance.IsZero(), "runtime escrow should be depleted")
+
+	// Frozen nodes should not be slashed again.
+	err = app.processExecutorMisbehavior(ctx, rtState, 1)
+	require.NoError(err, "processExecutorMisbehavior")
+	acct, err = stakeState.Account(ctx, entityAddrs[1])
+	require.NoError(err, "Account")
+	require.Equal(mustQuantity(t, 60), acct.Escrow.Active.Balance, "frozen node should not be slashed again")
+	acct, err = stakeState.Account(ctx, entityAddrs[0])
+	require.NoError(err, "Account")
+	require.Equal(mustQuantity(t, 125), acct.Escrow.Active.Balance, "nothing slashed, so no rewards")
+}
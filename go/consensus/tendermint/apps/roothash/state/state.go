@@ -9,6 +9,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common/keyformat"
 	"github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/message"
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
 )
 
@@ -25,13 +26,37 @@ var (
 	//
 	// The format is (height, runtimeID). Value is runtimeID.
 	roundTimeoutQueueKeyFmt = keyformat.New(0x22, int64(0), keyformat.H(&common.Namespace{}))
+	// inMsgQueueMetaKeyFmt is the key format used for incoming message queue metadata.
+	//
+	// Value is CBOR-serialized roothash.IncomingMessageQueueMeta.
+	inMsgQueueMetaKeyFmt = keyformat.New(0x23, keyformat.H(&common.Namespace{}))
+	// inMsgQueueKeyFmt is the key format used for the incoming message queue.
+	//
+	// The format is (runtimeID, sequence number). Value is CBOR-serialized
+	// message.IncomingMessage.
+	inMsgQueueKeyFmt = keyformat.New(0x24, keyformat.H(&common.Namespace{}), uint64(0))
 )
 
+// RuntimeStateKey returns the consensus state key under which the given runtime's roothash state
+// is stored.
+func RuntimeStateKey(id common.Namespace) []byte {
+	return runtimeKeyFmt.Encode(&id)
+}
+
 // ImmutableState is the immutable roothash state wrapper.
 type ImmutableState struct {
 	is *api.ImmutableState
 }
 
+// NewImmutableStateFromTree creates a new immutable roothash state wrapper backed by the given
+// consensus state tree.
+//
+// This can be used to read roothash state from a tree backed by a remote read syncer which
+// verifies all reads against a trusted consensus state root.
+func NewImmutableStateFromTree(tree mkvs.ImmutableKeyValueTree) *ImmutableState {
+	return &ImmutableState{&api.ImmutableState{ImmutableKeyValueTree: tree}}
+}
+
 func NewImmutableState(ctx context.Context, state api.ApplicationQueryState, version int64) (*ImmutableState, error) {
 	is, err := api.NewImmutableState(ctx, state, version)
 	if err != nil {
@@ -144,6 +169,53 @@ func (s *ImmutableState) ConsensusParameters(ctx context.Context) (*roothash.Con
 	return &params, nil
 }
 
+// IncomingMessageQueueMeta returns the incoming message queue metadata of the given runtime.
+func (s *ImmutableState) IncomingMessageQueueMeta(ctx context.Context, id common.Namespace) (*roothash.IncomingMessageQueueMeta, error) {
+	raw, err := s.is.Get(ctx, inMsgQueueMetaKeyFmt.Encode(&id))
+	if err != nil {
+		return nil, api.UnavailableStateError(err)
+	}
+	var meta roothash.IncomingMessageQueueMeta
+	if raw == nil {
+		return &meta, nil
+	}
+	if err = cbor.Unmarshal(raw, &meta); err != nil {
+		return nil, api.UnavailableStateError(err)
+	}
+	return &meta, nil
+}
+
+// IncomingMessageQueue returns the queued incoming messages of the given runtime in delivery
+// order. In case the limit is non-zero, at most that many messages are returned.
+func (s *ImmutableState) IncomingMessageQueue(ctx context.Context, id common.Namespace, limit uint32) ([]*message.IncomingMessage, error) {
+	meta, err := s.IncomingMessageQueueMeta(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	size := meta.Size
+	if limit > 0 && limit < size {
+		size = limit
+	}
+
+	msgs := make([]*message.IncomingMessage, 0, size)
+	for seq := meta.FirstSequenceNumber(); uint32(len(msgs)) < size; seq++ {
+		raw, err := s.is.Get(ctx, inMsgQueueKeyFmt.Encode(&id, seq))
+		if err != nil {
+			return nil, api.UnavailableStateError(err)
+		}
+		if raw == nil {
+			return nil, api.UnavailableStateError(fmt.Errorf("missing incoming message %d", seq))
+		}
+
+		var msg message.IncomingMessage
+		if err = cbor.Unmarshal(raw, &msg); err != nil {
+			return nil, api.UnavailableStateError(err)
+		}
+		msgs = append(msgs, &msg)
+	}
+	return msgs, nil
+}
+
 // MutableState is the mutable roothash state wrapper.
 type MutableState struct {
 	*ImmutableState
@@ -184,3 +256,27 @@ func (s *MutableState) ClearRoundTimeout(ctx context.Context, runtimeID common.N
 	err := s.ms.Remove(ctx, roundTimeoutQueueKeyFmt.Encode(height, &runtimeID))
 	return api.UnavailableStateError(err)
 }
+
+// SetIncomingMessageQueueMeta sets the incoming message queue metadata of the given runtime.
+func (s *MutableState) SetIncomingMessageQueueMeta(ctx context.Context, id common.Namespace, meta *roothash.IncomingMessageQueueMeta) error {
+	err := s.ms.Insert(ctx, inMsgQueueMetaKeyFmt.Encode(&id), cbor.Marshal(meta))
+	return api.UnavailableStateError(err)
+}
+
+// SetIncomingMessageInQueue stores the given incoming message in the incoming message queue of
+// the given runtime under the message's sequence number.
+//
+// The caller is responsible for updating the queue metadata.
+func (s *MutableState) SetIncomingMessageInQueue(ctx context.Context, id common.Namespace, msg *message.IncomingMessage) error {
+	err := s.ms.Insert(ctx, inMsgQueueKeyFmt.Encode(&id, msg.ID), cbor.Marshal(msg))
+	return api.UnavailableStateError(err)
+}
+
+// RemoveIncomingMessageFromQueue removes the incoming message with the given sequence number
+// from the incoming message queue of the given runtime.
+//
+// The caller is responsible for updating the queue metadata.
+func (s *MutableState) RemoveIncomingMessageFromQueue(ctx context.Context, id common.Namespace, seq uint64) error {
+	err := s.ms.Remove(ctx, inMsgQueueKeyFmt.Encode(&id, seq))
+	return api.UnavailableStateError(err)
+}
@@ -12,6 +12,7 @@ import (
 	registryState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/registry/state"
 	roothashState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/roothash/state"
 	schedulerState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/scheduler/state"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
@@ -26,6 +27,11 @@ type roothashSignatureVerifier struct {
 	runtimeID common.Namespace
 	scheduler *schedulerState.MutableState
 	registry  *registryState.MutableState
+
+	// runtime is the runtime descriptor.
+	runtime *registry.Runtime
+	// roundStartHeight is the consensus height at which the current round started.
+	roundStartHeight int64
 }
 
 // VerifyCommitteeSignatures verifies that the given signatures come from
@@ -71,12 +77,19 @@ func (sv *roothashSignatureVerifier) VerifyTxnSchedulerSignature(sig signature.S
 	if committee == nil {
 		return roothash.ErrInvalidRuntime
 	}
-	scheduler, err := commitment.GetTransactionScheduler(committee, round)
-	if err != nil {
+	priority, err := commitment.GetProposerPriority(sv.runtime, committee, round, sig.PublicKey)
+	switch err {
+	case nil:
+	case commitment.ErrNotProposer:
+		return fmt.Errorf("roothash: signature is not from a valid transaction scheduler")
+	default:
 		return fmt.Errorf("roothash: error getting transaction scheduler: %w", err)
 	}
-	if !scheduler.PublicKey.Equal(sig.PublicKey) {
-		return fmt.Errorf("roothash: signature is not from a valid transaction scheduler")
+
+	// Backup proposers may only propose once their timeout has elapsed.
+	height := sv.ctx.BlockHeight() + 1 // Current height is ctx.BlockHeight() + 1
+	if priority > 0 && height < sv.roundStartHeight+int64(priority)*sv.runtime.TxnScheduler.BackupProposerTimeout {
+		return fmt.Errorf("roothash: signature is from a backup proposer before its timeout")
 	}
 	return nil
 }
@@ -93,6 +106,9 @@ func (app *rootHashApplication) getRuntimeState(
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("roothash: failed to fetch runtime state: %w", err)
 	}
+	if rtState.Decommissioned {
+		return nil, nil, nil, roothash.ErrRuntimeDecommissioned
+	}
 	if rtState.Suspended {
 		return nil, nil, nil, roothash.ErrRuntimeSuspended
 	}
@@ -106,6 +122,9 @@ func (app *rootHashApplication) getRuntimeState(
 		runtimeID: id,
 		scheduler: schedulerState.NewMutableState(ctx.State()),
 		registry:  registryState.NewMutableState(ctx.State()),
+
+		runtime:          rtState.Runtime,
+		roundStartHeight: rtState.CurrentBlockHeight,
 	}
 
 	// Create node lookup.
@@ -168,6 +187,9 @@ func (app *rootHashApplication) executorProposerTimeout(
 		"err", err,
 		logging.LogEvent, roothash.LogEventRoundFailed,
 	)
+	if err = app.recordProposerTimeout(ctx, rtState); err != nil {
+		return fmt.Errorf("failed to record proposer timeout: %w", err)
+	}
 	if err = app.emitEmptyBlock(ctx, rtState, block.RoundFailed); err != nil {
 		return fmt.Errorf("failed to emit empty block: %w", err)
 	}
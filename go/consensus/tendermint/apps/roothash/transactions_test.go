@@ -205,3 +205,83 @@ func TestMessagesGasEstimation(t *testing.T) {
 	require.NoError(err, "ExecutorCommit")
 	require.EqualValues(5000, ctx.Gas().GasUsed(), "gas amount should be correct")
 }
+
+func TestVerifyTxnSchedulerSignature(t *testing.T) {
+	genesisTestHelpers.SetTestChainContext()
+
+	now := time.Unix(1580461674, 0)
+
+	// Generate keys for the workers, in round zero the first one is the transaction scheduler
+	// while the others are the backup proposers in order.
+	var sks []signature.Signer
+	for i := 0; i < 4; i++ {
+		sk, err := memorySigner.NewSigner(rand.Reader)
+		require.NoError(t, err, "NewSigner")
+		sks = append(sks, sk)
+	}
+
+	runtime := registry.Runtime{
+		TxnScheduler: registry.TxnSchedulerParameters{
+			BackupProposers:       2,
+			BackupProposerTimeout: 5,
+		},
+	}
+	executorCommittee := scheduler.Committee{
+		RuntimeID: runtime.ID,
+		Kind:      scheduler.KindComputeExecutor,
+	}
+	for _, sk := range sks {
+		executorCommittee.Members = append(executorCommittee.Members, &scheduler.CommitteeNode{
+			Role:      scheduler.RoleWorker,
+			PublicKey: sk.Public(),
+		})
+	}
+
+	const roundStartHeight = 10
+	for _, tc := range []struct {
+		name     string
+		proposer int
+		height   int64
+		valid    bool
+	}{
+		{"Scheduler", 0, roundStartHeight + 1, true},
+		{"FirstBackupEarly", 1, roundStartHeight + 4, false},
+		{"FirstBackup", 1, roundStartHeight + 5, true},
+		{"SecondBackupEarly", 2, roundStartHeight + 9, false},
+		{"SecondBackup", 2, roundStartHeight + 10, true},
+		{"NotProposer", 3, roundStartHeight + 100, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+
+			// The current height is the last committed block height plus one.
+			appState := abciAPI.NewMockApplicationState(&abciAPI.MockApplicationStateConfig{
+				BlockHeight: tc.height - 1,
+			})
+			ctx := appState.NewContext(abciAPI.ContextDeliverTx, now)
+			defer ctx.Close()
+
+			schedulerState := schedulerState.NewMutableState(ctx.State())
+			err := schedulerState.PutCommittee(ctx, &executorCommittee)
+			require.NoError(err, "PutCommittee")
+
+			sv := &roothashSignatureVerifier{
+				ctx:              ctx,
+				runtimeID:        runtime.ID,
+				scheduler:        schedulerState,
+				registry:         registryState.NewMutableState(ctx.State()),
+				runtime:          &runtime,
+				roundStartHeight: roundStartHeight,
+			}
+
+			sig := signature.Signature{PublicKey: sks[tc.proposer].Public()}
+			err = sv.VerifyTxnSchedulerSignature(sig, 0)
+			switch tc.valid {
+			case true:
+				require.NoError(err, "VerifyTxnSchedulerSignature")
+			case false:
+				require.Error(err, "VerifyTxnSchedulerSignature")
+			}
+		})
+	}
+}
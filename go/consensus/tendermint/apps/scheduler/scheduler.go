@@ -12,6 +12,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/drbg"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/mathrand"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
@@ -39,6 +40,7 @@ var (
 	RNGContextStorage    = []byte("EkS-ABCI-Storage")
 	RNGContextValidators = []byte("EkS-ABCI-Validators")
 	RNGContextEntities   = []byte("EkS-ABCI-Entities")
+	RNGContextProposers  = []byte("EkS-ABCI-Proposers")
 )
 
 type schedulerApplication struct {
@@ -379,6 +381,7 @@ func (app *schedulerApplication) electCommittee(
 		return fmt.Errorf("tendermint/scheduler: invalid committee type: %v", kind)
 	}
 
+	regState := registryState.NewMutableState(ctx.State())
 	for _, n := range nodes {
 		// Check if an entity has enough stake.
 		entAddr := staking.NewAddress(n.EntityID)
@@ -387,6 +390,17 @@ func (app *schedulerApplication) electCommittee(
 				continue
 			}
 		}
+		// Nodes penalized for liveness failures are excluded from the runtime's executor
+		// committee elections.
+		if kind == scheduler.KindComputeExecutor {
+			var status *registry.NodeStatus
+			if status, err = regState.NodeStatus(ctx, n.ID); err != nil {
+				return fmt.Errorf("tendermint/scheduler: couldn't get node status: %w", err)
+			}
+			if status.IsExcluded(rt.ID, epoch) {
+				continue
+			}
+		}
 		if isSuitableFn(ctx, n, rt) {
 			nodeList = append(nodeList, n)
 			if entitiesEligibleForReward != nil {
@@ -428,7 +442,10 @@ func (app *schedulerApplication) electCommittee(
 		return err
 	}
 
-	var members []*scheduler.CommitteeNode
+	var (
+		members        []*scheduler.CommitteeNode
+		memberEntities []signature.PublicKey
+	)
 	for i := 0; i < len(idxs); i++ {
 		role := scheduler.RoleWorker
 		if i >= workerSize {
@@ -438,6 +455,7 @@ func (app *schedulerApplication) electCommittee(
 			Role:      role,
 			PublicKey: nodeList[idxs[i]].ID,
 		})
+		memberEntities = append(memberEntities, nodeList[idxs[i]].EntityID)
 		if len(members) >= wantedNodes {
 			break
 		}
@@ -457,18 +475,67 @@ func (app *schedulerApplication) electCommittee(
 		return nil
 	}
 
-	err = schedulerState.NewMutableState(ctx.State()).PutCommittee(ctx, &scheduler.Committee{
+	committee := &scheduler.Committee{
 		Kind:      kind,
 		RuntimeID: rt.ID,
 		Members:   members,
 		ValidFor:  epoch,
-	})
+	}
+	if kind == scheduler.KindComputeExecutor {
+		if err = prepareProposerRotation(ctx, committee, memberEntities, workerSize, rt, beacon, stakeAcc); err != nil {
+			return err
+		}
+	}
+
+	err = schedulerState.NewMutableState(ctx.State()).PutCommittee(ctx, committee)
 	if err != nil {
 		return fmt.Errorf("failed to save committee: %w", err)
 	}
 	return nil
 }
 
+// prepareProposerRotation prepares the executor committee for the transaction scheduler rotation
+// strategy configured for the runtime. The first workerSize committee members are the workers.
+func prepareProposerRotation(
+	ctx *api.Context,
+	committee *scheduler.Committee,
+	entities []signature.PublicKey,
+	workerSize int,
+	rt *registry.Runtime,
+	beacon []byte,
+	stakeAcc *stakingState.StakeAccumulatorCache,
+) error {
+	switch rt.TxnScheduler.ProposerRotation {
+	case registry.ProposerRotationStake:
+		if stakeAcc == nil {
+			// In simplified no-stake deployments, keep the election order.
+			ctx.Logger().Warn("stake is not tracked, using round robin proposer rotation",
+				"runtime_id", rt.ID,
+			)
+			return nil
+		}
+
+		// Stable-sort the workers by descending escrow balance of their entities, the election
+		// order breaks ties.
+		workers := committee.Members[:workerSize]
+		balances := make(map[signature.PublicKey]*quantity.Quantity)
+		for i, w := range workers {
+			bal, err := stakeAcc.GetEscrowBalance(staking.NewAddress(entities[i]))
+			if err != nil {
+				return fmt.Errorf("failed to fetch escrow balance: %w", err)
+			}
+			balances[w.PublicKey] = bal
+		}
+		sort.SliceStable(workers, func(i, j int) bool {
+			return balances[workers[i].PublicKey].Cmp(balances[workers[j].PublicKey]) == 1
+		})
+	case registry.ProposerRotationRandom:
+		seed := hash.NewFromBytes(beacon, rt.ID[:], RNGContextProposers)
+		committee.ProposerSeed = seed[:]
+	}
+	return nil
+}
+
 // Operates on consensus connection.
 func (app *schedulerApplication) electAllCommittees(
 	ctx *api.Context,
@@ -631,7 +631,7 @@ func slashPool(dst *quantity.Quantity, p *staking.SharePool, amount, total *quan
 
 // SlashEscrow slashes the escrow balance and the escrow-but-undergoing-debonding
 // balance of the account, transferring it to the global common pool, returning
-// true iff the amount actually slashed is > 0.
+// the amount actually slashed.
 //
 // WARNING: This is an internal routine to be used to implement staking policy,
 // and MUST NOT be exposed outside of backend implementations.
@@ -639,47 +639,47 @@ func (s *MutableState) SlashEscrow(
 	ctx *abciAPI.Context,
 	fromAddr staking.Address,
 	amount *quantity.Quantity,
-) (bool, error) {
+) (*quantity.Quantity, error) {
 	commonPool, err := s.CommonPool(ctx)
 	if err != nil {
-		return false, fmt.Errorf("tendermint/staking: failed to query common pool for slash: %w", err)
+		return nil, fmt.Errorf("tendermint/staking: failed to query common pool for slash: %w", err)
 	}
 
 	from, err := s.Account(ctx, fromAddr)
 	if err != nil {
-		return false, fmt.Errorf("tendermint/staking: failed to query account %s: %w", fromAddr, err)
+		return nil, fmt.Errorf("tendermint/staking: failed to query account %s: %w", fromAddr, err)
 	}
 
 	// Compute the amount we need to slash each pool. The amount is split
 	// between the pools based on relative total balance.
 	total := from.Escrow.Active.Balance.Clone()
 	if err = total.Add(&from.Escrow.Debonding.Balance); err != nil {
-		return false, fmt.Errorf("tendermint/staking: compute total balance: %w", err)
+		return nil, fmt.Errorf("tendermint/staking: compute total balance: %w", err)
 	}
 
 	var slashed quantity.Quantity
 	if err = slashPool(&slashed, &from.Escrow.Active, amount, total); err != nil {
-		return false, fmt.Errorf("tendermint/staking: failed slashing active escrow: %w", err)
+		return nil, fmt.Errorf("tendermint/staking: failed slashing active escrow: %w", err)
 	}
 	if err = slashPool(&slashed, &from.Escrow.Debonding, amount, total); err != nil {
-		return false, fmt.Errorf("tendermint/staking: failed slashing debonding escrow: %w", err)
+		return nil, fmt.Errorf("tendermint/staking: failed slashing debonding escrow: %w", err)
 	}
 
 	if slashed.IsZero() {
-		return false, nil
+		return &slashed, nil
 	}
 
 	totalSlashed := slashed.Clone()
 
 	if err = quantity.Move(commonPool, &slashed, totalSlashed); err != nil {
-		return false, fmt.Errorf("tendermint/staking: failed moving stake to common pool: %w", err)
+		return nil, fmt.Errorf("tendermint/staking: failed moving stake to common pool: %w", err)
 	}
 
 	if err = s.SetCommonPool(ctx, commonPool); err != nil {
-		return false, fmt.Errorf("tendermint/staking: failed to set common pool: %w", err)
+		return nil, fmt.Errorf("tendermint/staking: failed to set common pool: %w", err)
 	}
 	if err = s.SetAccount(ctx, fromAddr, from); err != nil {
-		return false, fmt.Errorf("tendermint/staking: failed to set account. %w", err)
+		return nil, fmt.Errorf("tendermint/staking: failed to set account. %w", err)
 	}
 
 	if !ctx.IsCheckOnly() {
@@ -690,12 +690,13 @@ func (s *MutableState) SlashEscrow(
 		ctx.EmitEvent(api.NewEventBuilder(AppName).Attribute(KeyTakeEscrow, ev))
 	}
 
-	return true, nil
+	return totalSlashed, nil
 }
 
 // TransferFromCommon transfers up to the amount from the global common pool
-// to the general balance of the account, returning true iff the
-// amount transferred is > 0.
+// to the general balance of the account (or to its active escrow balance
+// without issuing any shares in case escrow is true), returning true iff
+// the amount transferred is > 0.
 //
 // WARNING: This is an internal routine to be used to implement incentivization
 // policy, and MUST NOT be exposed outside of backend implementations.
@@ -703,6 +704,7 @@ func (s *MutableState) TransferFromCommon(
 	ctx *abciAPI.Context,
 	toAddr staking.Address,
 	amount *quantity.Quantity,
+	escrow bool,
 ) (bool, error) {
 	commonPool, err := s.CommonPool(ctx)
 	if err != nil {
@@ -713,7 +715,11 @@ func (s *MutableState) TransferFromCommon(
 	if err != nil {
 		return false, fmt.Errorf("tendermint/staking: failed to query account %s: %w", toAddr, err)
 	}
-	transferred, err := quantity.MoveUpTo(&to.General.Balance, commonPool, amount)
+	dst := &to.General.Balance
+	if escrow {
+		dst = &to.Escrow.Active.Balance
+	}
+	transferred, err := quantity.MoveUpTo(dst, commonPool, amount)
 	if err != nil {
 		return false, fmt.Errorf("tendermint/staking: failed to transfer from common pool: %w", err)
 	}
@@ -728,12 +734,129 @@ func (s *MutableState) TransferFromCommon(
 		}
 
 		if !ctx.IsCheckOnly() {
-			ev := cbor.Marshal(&staking.TransferEvent{
-				From:   staking.CommonPoolAddress,
-				To:     toAddr,
+			if escrow {
+				ev := cbor.Marshal(&staking.AddEscrowEvent{
+					Owner:  staking.CommonPoolAddress,
+					Escrow: toAddr,
+					Amount: *transferred,
+				})
+				ctx.EmitEvent(api.NewEventBuilder(AppName).Attribute(KeyAddEscrow, ev))
+			} else {
+				ev := cbor.Marshal(&staking.TransferEvent{
+					From:   staking.CommonPoolAddress,
+					To:     toAddr,
+					Amount: *transferred,
+				})
+				ctx.EmitEvent(api.NewEventBuilder(AppName).Attribute(KeyTransfer, ev))
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// Transfer transfers the amount from the general balance of the source account to the general
+// balance of the destination account.
+//
+// WARNING: This is an internal routine to be used to implement fees charged by other
+// applications, and MUST NOT be exposed outside of backend implementations.
+func (s *MutableState) Transfer(
+	ctx *abciAPI.Context,
+	fromAddr staking.Address,
+	toAddr staking.Address,
+	amount *quantity.Quantity,
+) error {
+	if fromAddr.Equal(toAddr) {
+		return staking.ErrInvalidArgument
+	}
+
+	from, err := s.Account(ctx, fromAddr)
+	if err != nil {
+		return fmt.Errorf("tendermint/staking: failed to query account %s: %w", fromAddr, err)
+	}
+	if from.General.Balance.Cmp(amount) < 0 {
+		return staking.ErrInsufficientBalance
+	}
+	to, err := s.Account(ctx, toAddr)
+	if err != nil {
+		return fmt.Errorf("tendermint/staking: failed to query account %s: %w", toAddr, err)
+	}
+	if err = quantity.Move(&to.General.Balance, &from.General.Balance, amount); err != nil {
+		return fmt.Errorf("tendermint/staking: failed to transfer: %w", err)
+	}
+
+	if err = s.SetAccount(ctx, fromAddr, from); err != nil {
+		return fmt.Errorf("tendermint/staking: failed to set account %s: %w", fromAddr, err)
+	}
+	if err = s.SetAccount(ctx, toAddr, to); err != nil {
+		return fmt.Errorf("tendermint/staking: failed to set account %s: %w", toAddr, err)
+	}
+
+	if !ctx.IsCheckOnly() && !amount.IsZero() {
+		ev := cbor.Marshal(&staking.TransferEvent{
+			From:   fromAddr,
+			To:     toAddr,
+			Amount: *amount,
+		})
+		ctx.EmitEvent(api.NewEventBuilder(AppName).Attribute(KeyTransfer, ev))
+	}
+
+	return nil
+}
+
+// TransferEscrow transfers up to the amount from the active escrow balance of the source account
+// to the active escrow balance of the destination account without issuing or burning any shares
+// (thus taking the amount from all delegators of the source account and rewarding all delegators
+// of the destination account), returning true iff the amount transferred is > 0.
+//
+// WARNING: This is an internal routine to be used to implement incentivization
+// policy, and MUST NOT be exposed outside of backend implementations.
+func (s *MutableState) TransferEscrow(
+	ctx *abciAPI.Context,
+	fromAddr staking.Address,
+	toAddr staking.Address,
+	amount *quantity.Quantity,
+) (bool, error) {
+	if fromAddr.Equal(toAddr) {
+		return false, staking.ErrInvalidArgument
+	}
+
+	from, err := s.Account(ctx, fromAddr)
+	if err != nil {
+		return false, fmt.Errorf("tendermint/staking: failed to query account %s: %w", fromAddr, err)
+	}
+	to, err := s.Account(ctx, toAddr)
+	if err != nil {
+		return false, fmt.Errorf("tendermint/staking: failed to query account %s: %w", toAddr, err)
+	}
+
+	transferred, err := quantity.MoveUpTo(&to.Escrow.Active.Balance, &from.Escrow.Active.Balance, amount)
+	if err != nil {
+		return false, fmt.Errorf("tendermint/staking: failed to transfer escrow: %w", err)
+	}
+
+	ret := !transferred.IsZero()
+	if ret {
+		if err = s.SetAccount(ctx, fromAddr, from); err != nil {
+			return false, fmt.Errorf("tendermint/staking: failed to set account %s: %w", fromAddr, err)
+		}
+		if err = s.SetAccount(ctx, toAddr, to); err != nil {
+			return false, fmt.Errorf("tendermint/staking: failed to set account %s: %w", toAddr, err)
+		}
+
+		if !ctx.IsCheckOnly() {
+			takeEv := cbor.Marshal(&staking.TakeEscrowEvent{
+				Owner:  fromAddr,
+				Amount: *transferred,
+			})
+			ctx.EmitEvent(api.NewEventBuilder(AppName).Attribute(KeyTakeEscrow, takeEv))
+
+			addEv := cbor.Marshal(&staking.AddEscrowEvent{
+				Owner:  fromAddr,
+				Escrow: toAddr,
 				Amount: *transferred,
 			})
-			ctx.EmitEvent(api.NewEventBuilder(AppName).Attribute(KeyTransfer, ev))
+			ctx.EmitEvent(api.NewEventBuilder(AppName).Attribute(KeyAddEscrow, addEv))
 		}
 	}
 
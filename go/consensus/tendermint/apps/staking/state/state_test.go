@@ -251,9 +251,9 @@ func TestRewardAndSlash(t *testing.T) {
 	require.NoError(err, "Account")
 	require.Equal(mustInitQuantity(t, 300), escrowAccount.Escrow.Active.Balance, "reward late epoch - escrow active escrow")
 
-	slashedNonzero, err := s.SlashEscrow(ctx, escrowAddr, mustInitQuantityP(t, 40))
+	slashed, err := s.SlashEscrow(ctx, escrowAddr, mustInitQuantityP(t, 40))
 	require.NoError(err, "slash escrow")
-	require.True(slashedNonzero, "slashed nonzero")
+	require.Equal(mustInitQuantityP(t, 40), slashed, "slashed amount")
 
 	// Loss of 40 base units.
 	delegatorAccount, err = s.Account(ctx, delegatorAddr)
@@ -277,6 +277,33 @@ func TestRewardAndSlash(t *testing.T) {
 	commonPool, err = s.CommonPool(ctx)
 	require.NoError(err, "load common pool")
 	require.Equal(mustInitQuantityP(t, 9827), commonPool, "reward attenuated - common pool")
+
+	// Transfer between active escrow balances without issuing or burning shares.
+	transferred, err := s.TransferEscrow(ctx, escrowAddr, delegatorAddr, mustInitQuantityP(t, 17))
+	require.NoError(err, "transfer escrow")
+	require.True(transferred, "transferred nonzero")
+
+	escrowAccount, err = s.Account(ctx, escrowAddr)
+	require.NoError(err, "Account")
+	require.Equal(mustInitQuantity(t, 266), escrowAccount.Escrow.Active.Balance, "transfer escrow - escrow active escrow")
+	delegatorAccount, err = s.Account(ctx, delegatorAddr)
+	require.NoError(err, "Account")
+	require.Equal(mustInitQuantity(t, 17), delegatorAccount.Escrow.Active.Balance, "transfer escrow - delegator active escrow")
+	require.True(delegatorAccount.Escrow.Active.TotalShares.IsZero(), "transfer escrow - delegator total shares")
+	del, err = s.Delegation(ctx, delegatorAddr, escrowAddr)
+	require.NoError(err, "Delegation")
+	require.Equal(mustInitQuantity(t, 100), del.Shares, "transfer escrow - delegation shares")
+
+	// Transfers are limited by the available active escrow balance.
+	transferred, err = s.TransferEscrow(ctx, escrowAddr, delegatorAddr, mustInitQuantityP(t, 1000))
+	require.NoError(err, "transfer escrow")
+	require.True(transferred, "transferred nonzero")
+	escrowAccount, err = s.Account(ctx, escrowAddr)
+	require.NoError(err, "Account")
+	require.True(escrowAccount.Escrow.Active.Balance.IsZero(), "transfer escrow - escrow active escrow depleted")
+	transferred, err = s.TransferEscrow(ctx, escrowAddr, delegatorAddr, mustInitQuantityP(t, 1))
+	require.NoError(err, "transfer escrow")
+	require.False(transferred, "transferred zero")
 }
 
 func TestEpochSigning(t *testing.T) {
@@ -29,17 +29,18 @@ const (
 var (
 	baseLogger = logging.GetLogger("tendermint/db/badger")
 
-	// DBProvider is a DBProvider to be used when initializing
-	// a tendermint node.
-	DBProvider node.DBProvider = badgerDBProvider
-
 	dbVersionStart = []byte{dbVersion}
 	dbVersionEnd   = []byte{dbVersion + 1}
 )
 
-func badgerDBProvider(ctx *node.DBContext) (dbm.DB, error) {
-	// BadgerDB can handle dealing with the directory for us.
-	return New(filepath.Join(ctx.Config.DBDir(), ctx.ID), false)
+// NewDBProvider returns a DBProvider to be used when initializing a tendermint node.
+//
+// In case an encryption key is given, all databases will be encrypted at rest.
+func NewDBProvider(encryptionKey []byte) node.DBProvider {
+	return func(ctx *node.DBContext) (dbm.DB, error) {
+		// BadgerDB can handle dealing with the directory for us.
+		return New(filepath.Join(ctx.Config.DBDir(), ctx.ID), false, encryptionKey)
+	}
 }
 
 type badgerDBImpl struct {
@@ -54,9 +55,12 @@ type badgerDBImpl struct {
 // New constructs a new tendermint DB, backed by a Badger database at
 // the provided path.
 //
+// In case an encryption key is given, the database will be encrypted
+// at rest.
+//
 // Note: This should only be used by tendermint, all other places
 // that need a K/V store should favor using BadgerDB directly.
-func New(fn string, noSuffix bool) (dbm.DB, error) {
+func New(fn string, noSuffix bool, encryptionKey []byte) (dbm.DB, error) {
 	if !noSuffix && !strings.HasSuffix(fn, dbSuffix) {
 		fn = fn + dbSuffix
 	}
@@ -71,6 +75,7 @@ func New(fn string, noSuffix bool) (dbm.DB, error) {
 	opts = opts.WithTruncate(true)
 	opts = opts.WithCompression(options.Snappy)
 	opts = opts.WithBlockCacheSize(64 * 1024 * 1024)
+	opts = cmnBadger.WithEncryption(opts, encryptionKey)
 
 	db, err := badger.Open(opts)
 	if err != nil {
@@ -18,7 +18,7 @@ func TestBadgerTendermintDB(t *testing.T) {
 	defer os.RemoveAll(tmpDir)
 
 	// Create the database.
-	db, err := New(filepath.Join(tmpDir, "test"), false)
+	db, err := New(filepath.Join(tmpDir, "test"), false, nil)
 	require.NoError(t, err, "New")
 	defer db.Close()
 
@@ -25,24 +25,28 @@ func GetBackendName() string {
 }
 
 // GetProvider returns the currently configured Tendermint DBProvider.
-func GetProvider() (node.DBProvider, error) {
+//
+// In case an encryption key is given, all databases will be encrypted at rest.
+func GetProvider(encryptionKey []byte) (node.DBProvider, error) {
 	backend := viper.GetString(cfgBackend)
 
 	switch strings.ToLower(backend) {
 	case badger.BackendName:
-		return badger.DBProvider, nil
+		return badger.NewDBProvider(encryptionKey), nil
 	default:
 		return nil, fmt.Errorf("tendermint/db: unsupported backend: '%v'", backend)
 	}
 }
 
 // New constructs a new tendermint DB with the configured backend.
-func New(fn string, noSuffix bool) (dbm.DB, error) {
+//
+// In case an encryption key is given, the database will be encrypted at rest.
+func New(fn string, noSuffix bool, encryptionKey []byte) (dbm.DB, error) {
 	backend := viper.GetString(cfgBackend)
 
 	switch strings.ToLower(backend) {
 	case badger.BackendName:
-		return badger.New(fn, noSuffix)
+		return badger.New(fn, noSuffix, encryptionKey)
 	default:
 		return nil, fmt.Errorf("tendermint/db: unsupported backend: '%v'", backend)
 	}
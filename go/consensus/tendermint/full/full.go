@@ -31,6 +31,7 @@ import (
 	tmdb "github.com/tendermint/tm-db"
 
 	beaconAPI "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	cmnBadger "github.com/oasisprotocol/oasis-core/go/common/badger"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
@@ -1101,6 +1102,17 @@ func (t *fullService) lazyInit() error {
 	}
 	pruneCfg.NumKept = viper.GetUint64(CfgABCIPruneNumKept)
 
+	// Derive the (optional) database encryption key used for both the ABCI state
+	// storage and the Tendermint databases.
+	encryptionKey, err := cmnBadger.NewEncryptionKey(
+		cmflags.DBEncryptionKeySource(),
+		cmflags.DBEncryptionKeyFile(),
+		t.identity.NodeSigner,
+	)
+	if err != nil {
+		return err
+	}
+
 	appConfig := &abci.ApplicationConfig{
 		DataDir:                   filepath.Join(t.dataDir, tmcommon.StateDir),
 		StorageBackend:            db.GetBackendName(),
@@ -1111,6 +1123,7 @@ func (t *fullService) lazyInit() error {
 		DisableCheckpointer:       viper.GetBool(CfgCheckpointerDisabled),
 		CheckpointerCheckInterval: viper.GetDuration(CfgCheckpointerCheckInterval),
 		InitialHeight:             uint64(t.genesis.Height),
+		StorageEncryptionKey:      encryptionKey,
 	}
 	t.mux, err = abci.NewApplicationServer(t.ctx, t.upgrader, appConfig)
 	if err != nil {
@@ -1212,7 +1225,7 @@ func (t *fullService) lazyInit() error {
 		return tmGenDoc, nil
 	}
 
-	dbProvider, err := db.GetProvider()
+	dbProvider, err := db.GetProvider(encryptionKey)
 	if err != nil {
 		t.Logger.Error("failed to obtain database provider",
 			"err", err,
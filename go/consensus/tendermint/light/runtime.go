@@ -0,0 +1,126 @@
+package light
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	roothashState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/roothash/state"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
+)
+
+// proofReadSyncer is a read syncer that serves a single pre-fetched proof. Any proofs served are
+// verified by the tree against its (trusted) root.
+type proofReadSyncer struct {
+	proof *syncer.Proof
+}
+
+// Implements syncer.ReadSyncer.
+func (rs *proofReadSyncer) SyncGet(ctx context.Context, request *syncer.GetRequest) (*syncer.ProofResponse, error) {
+	if rs.proof == nil {
+		return nil, fmt.Errorf("light: proof is incomplete")
+	}
+	proof := rs.proof
+	rs.proof = nil
+
+	return &syncer.ProofResponse{Proof: *proof}, nil
+}
+
+// Implements syncer.ReadSyncer.
+func (rs *proofReadSyncer) SyncGetPrefixes(ctx context.Context, request *syncer.GetPrefixesRequest) (*syncer.ProofResponse, error) {
+	return nil, syncer.ErrUnsupported
+}
+
+// Implements syncer.ReadSyncer.
+func (rs *proofReadSyncer) SyncIterate(ctx context.Context, request *syncer.IterateRequest) (*syncer.ProofResponse, error) {
+	return nil, syncer.ErrUnsupported
+}
+
+// VerifyRuntimeState verifies the given runtime state proof against the app hash of a verified
+// consensus light block and returns the verified runtime state.
+func VerifyRuntimeState(
+	ctx context.Context,
+	client Client,
+	runtimeID common.Namespace,
+	proof *roothash.RuntimeStateProof,
+) (*roothash.RuntimeState, error) {
+	// The consensus state at a given height is committed to by the app hash of the next block.
+	lb, err := client.GetVerifiedLightBlock(ctx, proof.Height+1)
+	if err != nil {
+		return nil, fmt.Errorf("light: failed to get verified light block: %w", err)
+	}
+	var stateRoot hash.Hash
+	if err = stateRoot.UnmarshalBinary(lb.AppHash); err != nil {
+		return nil, fmt.Errorf("light: malformed app hash: %w", err)
+	}
+
+	tree := mkvs.NewWithRoot(&proofReadSyncer{proof: &proof.Proof}, nil, node.Root{
+		Version: uint64(proof.Height),
+		Hash:    stateRoot,
+	})
+	defer tree.Close()
+
+	rtState, err := roothashState.NewImmutableStateFromTree(tree).RuntimeState(ctx, runtimeID)
+	if err != nil {
+		return nil, fmt.Errorf("light: failed to verify runtime state: %w", err)
+	}
+	return rtState, nil
+}
+
+// VerifyRuntimeBlock verifies that the given runtime block is included in the consensus state
+// proven by the given runtime state proof.
+//
+// As multiple runtime blocks may be finalized at the same consensus height, the given headers must
+// link the block to the latest runtime block in the proven state. Each header must be for the round
+// following the previous one and must commit to the hash of the previous header. In case the block
+// itself is the latest runtime block, no headers are required.
+func VerifyRuntimeBlock(
+	ctx context.Context,
+	client Client,
+	blk *block.Block,
+	headers []block.Header,
+	proof *roothash.RuntimeStateProof,
+) error {
+	rtState, err := VerifyRuntimeState(ctx, client, blk.Header.Namespace, proof)
+	if err != nil {
+		return err
+	}
+	if rtState.CurrentBlock == nil {
+		return fmt.Errorf("light: runtime state has no current block")
+	}
+
+	latest := &blk.Header
+	for i := range headers {
+		hdr := &headers[i]
+		prevHash := latest.EncodedHash()
+		switch {
+		case hdr.Round != latest.Round+1:
+			return fmt.Errorf("light: unexpected header round (expected: %d got: %d)", latest.Round+1, hdr.Round)
+		case !hdr.PreviousHash.Equal(&prevHash):
+			return fmt.Errorf("light: header for round %d does not link to the previous header", hdr.Round)
+		}
+		latest = hdr
+	}
+
+	provenHash, latestHash := rtState.CurrentBlock.Header.EncodedHash(), latest.EncodedHash()
+	if !provenHash.Equal(&latestHash) {
+		return fmt.Errorf("light: runtime block mismatch (expected: %s got: %s)", provenHash, latestHash)
+	}
+	return nil
+}
+
+// NewRuntimeStateTree creates a new runtime state tree for the given verified runtime block. All
+// reads from the tree are fetched using the given read syncer and verified against the state root
+// of the runtime block.
+func NewRuntimeStateTree(rs syncer.ReadSyncer, blk *block.Block) mkvs.Tree {
+	return mkvs.NewWithRoot(rs, nil, node.Root{
+		Namespace: blk.Header.Namespace,
+		Version:   blk.Header.Round,
+		Hash:      blk.Header.StateRoot,
+	})
+}
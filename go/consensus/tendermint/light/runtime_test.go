@@ -0,0 +1,114 @@
+package light
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	roothashState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/roothash/state"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
+)
+
+// testClient is a light client that returns a light block with a fixed app hash.
+type testClient struct {
+	Client
+
+	height  int64
+	appHash hash.Hash
+}
+
+func (tc *testClient) GetVerifiedLightBlock(ctx context.Context, height int64) (*tmtypes.LightBlock, error) {
+	lb := &tmtypes.LightBlock{
+		SignedHeader: &tmtypes.SignedHeader{
+			Header: &tmtypes.Header{Height: height},
+		},
+	}
+	if height == tc.height {
+		lb.AppHash = tc.appHash[:]
+	}
+	return lb, nil
+}
+
+func TestVerifyRuntimeBlock(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	runtimeID := common.NewTestNamespaceFromSeed([]byte("light runtime test ns"), 0)
+	var stateRoot hash.Hash
+	stateRoot.FromBytes([]byte("runtime state root"))
+	blk := block.NewGenesisBlock(runtimeID, 0)
+	blk.Header.Round = 42
+	blk.Header.StateRoot = stateRoot
+	// Multiple blocks may be finalized at the same consensus height.
+	earlierBlk := block.NewEmptyBlock(blk, 0, block.RoundFailed)
+	earlierBlk.Header.Round = 40
+	midBlk := block.NewEmptyBlock(earlierBlk, 0, block.Normal)
+	blk.Header.PreviousHash = midBlk.Header.EncodedHash()
+
+	// Prepare consensus state containing the runtime state.
+	tree := mkvs.New(nil, nil)
+	defer tree.Close()
+	err := roothashState.NewMutableState(tree).SetRuntimeState(ctx, &roothash.RuntimeState{
+		Runtime:      &registry.Runtime{ID: runtimeID},
+		CurrentBlock: blk,
+	})
+	require.NoError(err, "SetRuntimeState")
+	_, consensusRoot, err := tree.Commit(ctx, common.Namespace{}, 10)
+	require.NoError(err, "Commit")
+
+	rsp, err := tree.SyncGet(ctx, &syncer.GetRequest{
+		Tree: syncer.TreeID{
+			Root:     node.Root{Version: 10, Hash: consensusRoot},
+			Position: consensusRoot,
+		},
+		Key: roothashState.RuntimeStateKey(runtimeID),
+	})
+	require.NoError(err, "SyncGet")
+	proof := &roothash.RuntimeStateProof{Height: 10, Proof: rsp.Proof}
+
+	client := &testClient{height: 11, appHash: consensusRoot}
+
+	rtState, err := VerifyRuntimeState(ctx, client, runtimeID, proof)
+	require.NoError(err, "VerifyRuntimeState")
+	require.EqualValues(blk, rtState.CurrentBlock, "verified runtime state should contain the block")
+
+	err = VerifyRuntimeBlock(ctx, client, blk, nil, proof)
+	require.NoError(err, "VerifyRuntimeBlock")
+
+	// A different block should not verify.
+	otherBlk := block.NewEmptyBlock(blk, 0, block.Normal)
+	err = VerifyRuntimeBlock(ctx, client, otherBlk, nil, proof)
+	require.Error(err, "VerifyRuntimeBlock should fail for a different block")
+
+	// Earlier blocks should verify when linked to the latest block.
+	err = VerifyRuntimeBlock(ctx, client, earlierBlk, []block.Header{midBlk.Header, blk.Header}, proof)
+	require.NoError(err, "VerifyRuntimeBlock with headers")
+	err = VerifyRuntimeBlock(ctx, client, midBlk, []block.Header{blk.Header}, proof)
+	require.NoError(err, "VerifyRuntimeBlock with headers")
+	err = VerifyRuntimeBlock(ctx, client, earlierBlk, nil, proof)
+	require.Error(err, "VerifyRuntimeBlock should fail without headers")
+	err = VerifyRuntimeBlock(ctx, client, earlierBlk, []block.Header{blk.Header}, proof)
+	require.Error(err, "VerifyRuntimeBlock should fail with missing headers")
+	otherMidBlk := block.NewEmptyBlock(earlierBlk, 1, block.Normal)
+	err = VerifyRuntimeBlock(ctx, client, earlierBlk, []block.Header{otherMidBlk.Header, blk.Header}, proof)
+	require.Error(err, "VerifyRuntimeBlock should fail with unlinked headers")
+
+	// A proof against a different app hash should not verify.
+	var otherRoot hash.Hash
+	otherRoot.FromBytes([]byte("other consensus root"))
+	_, err = VerifyRuntimeState(ctx, &testClient{height: 11, appHash: otherRoot}, runtimeID, proof)
+	require.Error(err, "VerifyRuntimeState should fail for a different app hash")
+
+	// A proof for a different height should not verify.
+	_, err = VerifyRuntimeState(ctx, client, runtimeID, &roothash.RuntimeStateProof{Height: 11, Proof: rsp.Proof})
+	require.Error(err, "VerifyRuntimeState should fail for a different height")
+}
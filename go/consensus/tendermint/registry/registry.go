@@ -142,6 +142,15 @@ func (sc *serviceClient) GetRuntime(ctx context.Context, query *api.NamespaceQue
 	return q.Runtime(ctx, query.ID)
 }
 
+func (sc *serviceClient) GetRuntimeStatus(ctx context.Context, query *api.NamespaceQuery) (*api.RuntimeStatus, error) {
+	q, err := sc.querier.QueryAt(ctx, query.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.RuntimeStatus(ctx, query.ID)
+}
+
 func (sc *serviceClient) WatchRuntimes(ctx context.Context) (<-chan *api.Runtime, pubsub.ClosableSubscription, error) {
 	typedCh := make(chan *api.Runtime)
 	sub := sc.runtimeNotifier.Subscribe()
@@ -4,6 +4,7 @@ package roothash
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"sync"
@@ -21,11 +22,15 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
 	tmapi "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/api"
 	app "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/roothash"
+	roothashState "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/apps/roothash/state"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/message"
 	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
 )
 
 const crashPointBlockBeforeIndex = "roothash.before_index"
@@ -119,6 +124,174 @@ func (sc *serviceClient) getLatestBlockAt(ctx context.Context, runtimeID common.
 	return q.LatestBlock(ctx, runtimeID)
 }
 
+func (sc *serviceClient) GetBlockByRound(ctx context.Context, runtimeID common.Namespace, round uint64) (*api.AnnotatedBlock, error) {
+	earliestHeight, genesisHeight, err := sc.getRetainedHeights(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latestBlk, err := sc.backend.GetBlock(ctx, consensus.HeightLatest)
+	if err != nil {
+		return nil, fmt.Errorf("roothash: failed to get latest consensus block: %w", err)
+	}
+
+	// Rounds are finalized in increasing order, so find the first height at which the latest
+	// block's round is at least the requested round.
+	var found *api.AnnotatedBlock
+	lo, hi := earliestHeight, latestBlk.Height
+	for lo <= hi {
+		mid := lo + (hi-lo)/2
+		blk, err := sc.getLatestBlockOrNil(ctx, runtimeID, mid)
+		if err != nil {
+			return nil, err
+		}
+		if blk != nil && blk.Header.Round >= round {
+			found = &api.AnnotatedBlock{Height: mid, Block: blk}
+			hi = mid - 1
+		} else {
+			lo = mid + 1
+		}
+	}
+	switch {
+	case found == nil:
+		return nil, api.ErrNotFound
+	case found.Block.Header.Round != round:
+		return nil, fmt.Errorf("%w: round %d superseded by round %d at height %d",
+			api.ErrRoundSuperseded,
+			round,
+			found.Block.Header.Round,
+			found.Height,
+		)
+	}
+	// In case the block is already present at the earliest retained height, it may have been
+	// finalized at a height that has since been pruned.
+	if found.Height == earliestHeight && earliestHeight != genesisHeight {
+		return nil, api.ErrNotFound
+	}
+	return found, nil
+}
+
+func (sc *serviceClient) GetRoundsInRange(ctx context.Context, runtimeID common.Namespace, startHeight, endHeight int64) ([]*api.AnnotatedBlock, error) {
+	if endHeight == consensus.HeightLatest {
+		latestBlk, err := sc.backend.GetBlock(ctx, consensus.HeightLatest)
+		if err != nil {
+			return nil, fmt.Errorf("roothash: failed to get latest consensus block: %w", err)
+		}
+		endHeight = latestBlk.Height
+	}
+	if startHeight <= 0 || startHeight > endHeight {
+		return nil, api.ErrInvalidArgument
+	}
+	if endHeight-startHeight+1 > api.MaxRoundsInRangeHeights {
+		return nil, api.ErrHeightRangeTooLarge
+	}
+
+	earliestHeight, genesisHeight, err := sc.getRetainedHeights(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Blocks finalized at the start height are those that differ from the block at the
+	// preceding height, so state at that height is required unless we start at genesis.
+	var prevBlk *block.Block
+	switch {
+	case startHeight == genesisHeight:
+	case startHeight > earliestHeight:
+		if prevBlk, err = sc.getLatestBlockOrNil(ctx, runtimeID, startHeight-1); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: height %d has been pruned", api.ErrNotFound, startHeight-1)
+	}
+	lastBlk, err := sc.getLatestBlockOrNil(ctx, runtimeID, endHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*api.AnnotatedBlock
+	if err = sc.findRounds(ctx, runtimeID, startHeight, endHeight, prevBlk, lastBlk, &blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// findRounds appends the blocks finalized in the given (inclusive) height range to blocks, given
+// the latest runtime block at the height preceding the range and at the end of the range.
+//
+// Since only heights at which the latest block changes need to be examined, the range is bisected
+// until such heights are found. As only the latest block at each height is available, an error is
+// returned in case multiple rounds were finalized at any such height.
+func (sc *serviceClient) findRounds(
+	ctx context.Context,
+	runtimeID common.Namespace,
+	startHeight int64,
+	endHeight int64,
+	prevBlk *block.Block,
+	lastBlk *block.Block,
+	blocks *[]*api.AnnotatedBlock,
+) error {
+	switch {
+	case prevBlk == nil && lastBlk == nil:
+		return nil
+	case prevBlk != nil && lastBlk != nil && prevBlk.Header.Round == lastBlk.Header.Round:
+		return nil
+	case startHeight == endHeight:
+		// Rounds are consecutive so any skipped rounds have been superseded at this height.
+		if prevBlk != nil && lastBlk.Header.Round != prevBlk.Header.Round+1 {
+			return fmt.Errorf("%w: rounds %d to %d superseded by round %d at height %d",
+				api.ErrRoundSuperseded,
+				prevBlk.Header.Round+1,
+				lastBlk.Header.Round-1,
+				lastBlk.Header.Round,
+				endHeight,
+			)
+		}
+		*blocks = append(*blocks, &api.AnnotatedBlock{Height: endHeight, Block: lastBlk})
+		return nil
+	default:
+	}
+
+	midHeight := startHeight + (endHeight-startHeight)/2
+	midBlk, err := sc.getLatestBlockOrNil(ctx, runtimeID, midHeight)
+	if err != nil {
+		return err
+	}
+	if err = sc.findRounds(ctx, runtimeID, startHeight, midHeight, prevBlk, midBlk, blocks); err != nil {
+		return err
+	}
+	return sc.findRounds(ctx, runtimeID, midHeight+1, endHeight, midBlk, lastBlk, blocks)
+}
+
+// getLatestBlockOrNil returns the latest block of the given runtime at the given height or nil in
+// case the runtime did not exist at the given height.
+func (sc *serviceClient) getLatestBlockOrNil(ctx context.Context, runtimeID common.Namespace, height int64) (*block.Block, error) {
+	blk, err := sc.getLatestBlockAt(ctx, runtimeID, height)
+	switch {
+	case err == nil:
+		return blk, nil
+	case errors.Is(err, api.ErrInvalidRuntime):
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
+
+// getRetainedHeights returns the earliest consensus height for which state is available together
+// with the initial consensus height.
+func (sc *serviceClient) getRetainedHeights(ctx context.Context) (int64, int64, error) {
+	lastRetainedHeight, err := sc.backend.GetLastRetainedVersion(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("roothash: failed to get last retained height: %w", err)
+	}
+	genesisDoc, err := sc.backend.GetGenesisDocument(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("roothash: failed to get genesis document: %w", err)
+	}
+	if lastRetainedHeight < genesisDoc.Height {
+		lastRetainedHeight = genesisDoc.Height
+	}
+	return lastRetainedHeight, genesisDoc.Height, nil
+}
+
 func (sc *serviceClient) GetRuntimeState(ctx context.Context, runtimeID common.Namespace, height int64) (*api.RuntimeState, error) {
 	q, err := sc.querier.QueryAt(ctx, height)
 	if err != nil {
@@ -128,6 +301,64 @@ func (sc *serviceClient) GetRuntimeState(ctx context.Context, runtimeID common.N
 	return q.RuntimeState(ctx, runtimeID)
 }
 
+func (sc *serviceClient) GetRuntimeStateProof(ctx context.Context, runtimeID common.Namespace, height int64) (*api.RuntimeStateProof, error) {
+	// The consensus state at a given height is committed to by the next block so fetch that.
+	var blk *consensus.Block
+	var err error
+	switch height {
+	case consensus.HeightLatest:
+		blk, err = sc.backend.GetBlock(ctx, consensus.HeightLatest)
+	default:
+		blk, err = sc.backend.GetBlock(ctx, height+1)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("roothash: failed to get consensus block: %w", err)
+	}
+
+	rsp, err := sc.backend.State().SyncGet(ctx, &syncer.GetRequest{
+		Tree: syncer.TreeID{
+			Root:     blk.StateRoot,
+			Position: blk.StateRoot.Hash,
+		},
+		Key: roothashState.RuntimeStateKey(runtimeID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("roothash: failed to generate runtime state proof: %w", err)
+	}
+
+	return &api.RuntimeStateProof{
+		Height: int64(blk.StateRoot.Version),
+		Proof:  rsp.Proof,
+	}, nil
+}
+
+func (sc *serviceClient) GetLivenessStatistics(ctx context.Context, runtimeID common.Namespace, height int64) (*api.LivenessStatistics, error) {
+	q, err := sc.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.LivenessStatistics(ctx, runtimeID)
+}
+
+func (sc *serviceClient) GetIncomingMessageQueueMeta(ctx context.Context, runtimeID common.Namespace, height int64) (*api.IncomingMessageQueueMeta, error) {
+	q, err := sc.querier.QueryAt(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.IncomingMessageQueueMeta(ctx, runtimeID)
+}
+
+func (sc *serviceClient) GetIncomingMessageQueue(ctx context.Context, request *api.InMessageQueueRequest) ([]*message.IncomingMessage, error) {
+	q, err := sc.querier.QueryAt(ctx, request.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.IncomingMessageQueue(ctx, request.RuntimeID, request.Limit)
+}
+
 func (sc *serviceClient) WatchBlocks(id common.Namespace) (<-chan *api.AnnotatedBlock, *pubsub.Subscription, error) {
 	notifiers := sc.getRuntimeNotifiers(id)
 
@@ -199,6 +430,97 @@ func (sc *serviceClient) WatchEvents(id common.Namespace) (<-chan *api.Event, *p
 	return ch, sub, nil
 }
 
+func (sc *serviceClient) WatchRuntimeEvents(
+	ctx context.Context,
+	runtimeID common.Namespace,
+	fromHeight int64,
+) (<-chan *api.Event, pubsub.ClosableSubscription, error) {
+	if fromHeight < 0 {
+		return nil, nil, api.ErrInvalidArgument
+	}
+	if fromHeight != consensus.HeightLatest {
+		earliestHeight, _, err := sc.getRetainedHeights(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if fromHeight < earliestHeight {
+			return nil, nil, fmt.Errorf("%w: height %d has been pruned", api.ErrNotFound, fromHeight)
+		}
+	}
+
+	ctx, sub := pubsub.NewContextSubscription(ctx)
+
+	// Subscribe to consensus blocks before determining the latest height so that no heights are
+	// missed between replaying past events and streaming new ones.
+	blkCh, blkSub, err := sc.backend.WatchBlocks(ctx)
+	if err != nil {
+		sub.Close()
+		return nil, nil, fmt.Errorf("roothash: failed to watch consensus blocks: %w", err)
+	}
+	latestBlk, err := sc.backend.GetBlock(ctx, consensus.HeightLatest)
+	if err != nil {
+		blkSub.Close()
+		sub.Close()
+		return nil, nil, fmt.Errorf("roothash: failed to get latest consensus block: %w", err)
+	}
+	nextHeight := fromHeight
+	if nextHeight == consensus.HeightLatest {
+		nextHeight = latestBlk.Height + 1
+	}
+
+	ch := make(chan *api.Event)
+	go func() {
+		defer close(ch)
+		defer blkSub.Close()
+
+		emitUntil := func(height int64) bool {
+			for ; nextHeight <= height; nextHeight++ {
+				evs, err := sc.GetEvents(ctx, nextHeight)
+				if err != nil {
+					sc.logger.Error("failed to get runtime events",
+						"err", err,
+						"runtime_id", runtimeID,
+						"height", nextHeight,
+					)
+					return false
+				}
+
+				for _, ev := range evs {
+					if !ev.RuntimeID.Equal(&runtimeID) {
+						continue
+					}
+
+					select {
+					case ch <- ev:
+					case <-ctx.Done():
+						return false
+					}
+				}
+			}
+			return true
+		}
+
+		if !emitUntil(latestBlk.Height) {
+			return
+		}
+		for {
+			select {
+			case blk, ok := <-blkCh:
+				if !ok {
+					return
+				}
+				if !emitUntil(blk.Height) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, sub, nil
+}
+
 func (sc *serviceClient) TrackRuntime(ctx context.Context, history api.BlockHistory) error {
 	return sc.trackRuntime(ctx, history.RuntimeID(), history)
 }
@@ -676,6 +998,26 @@ func EventsFromTendermint(
 
 				ev := &api.Event{RuntimeID: value.ID, Height: height, TxHash: txHash, ExecutionDiscrepancyDetected: &value.Event}
 				events = append(events, ev)
+			case bytes.Equal(key, app.KeyExecutorMisbehavior):
+				// An executor node has misbehaved.
+				var value app.ValueExecutorMisbehavior
+				if err := cbor.Unmarshal(val, &value); err != nil {
+					errs = multierror.Append(errs, fmt.Errorf("roothash: corrupt ValueExecutorMisbehavior event: %w", err))
+					continue
+				}
+
+				ev := &api.Event{RuntimeID: value.ID, Height: height, TxHash: txHash, ExecutorMisbehavior: &value.Event}
+				events = append(events, ev)
+			case bytes.Equal(key, app.KeySuspensionNotice):
+				// A runtime will be suspended after the grace period.
+				var value app.ValueSuspensionNotice
+				if err := cbor.Unmarshal(val, &value); err != nil {
+					errs = multierror.Append(errs, fmt.Errorf("roothash: corrupt ValueSuspensionNotice event: %w", err))
+					continue
+				}
+
+				ev := &api.Event{RuntimeID: value.ID, Height: height, TxHash: txHash, SuspensionNotice: &value.Event}
+				events = append(events, ev)
 			case bytes.Equal(key, app.KeyExecutorCommitted):
 				// An executor commit has been processed.
 				var value app.ValueExecutorCommitted
@@ -696,6 +1038,16 @@ func EventsFromTendermint(
 
 				ev := &api.Event{RuntimeID: value.ID, Height: height, TxHash: txHash, Message: &value.Event}
 				events = append(events, ev)
+			case bytes.Equal(key, app.KeyInMessageDelivered):
+				// Runtime message has been delivered to the destination runtime.
+				var value app.ValueInMessageDelivered
+				if err := cbor.Unmarshal(val, &value); err != nil {
+					errs = multierror.Append(errs, fmt.Errorf("roothash: corrupt in message delivered event: %w", err))
+					continue
+				}
+
+				ev := &api.Event{RuntimeID: value.ID, Height: height, TxHash: txHash, InMessageDelivered: &value.Event}
+				events = append(events, ev)
 			case bytes.Equal(key, app.KeyRuntimeID):
 				// Runtime ID attribute (Base64-encoded to allow queries).
 			default:
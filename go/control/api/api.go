@@ -14,6 +14,7 @@ import (
 	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
 	epochtime "github.com/oasisprotocol/oasis-core/go/epochtime/api"
 	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
 	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
 	upgrade "github.com/oasisprotocol/oasis-core/go/upgrade/api"
 	commonWorker "github.com/oasisprotocol/oasis-core/go/worker/common/api"
@@ -121,6 +122,11 @@ type RuntimeStatus struct {
 	Committee *commonWorker.Status `json:"committee"`
 	// Storage contains the storage worker status in case this node is a storage node.
 	Storage *storageWorker.Status `json:"storage"`
+
+	// Resources contains the resource usage of the runtime in case this node is hosting it.
+	Resources *host.ResourceUsage `json:"resources"`
+	// Crashes contains the crash status of the runtime in case this node is hosting it.
+	Crashes *host.CrashStatus `json:"crashes"`
 }
 
 // ControlledNode is an internal interface that the controlled oasis-node must provide.
@@ -14,6 +14,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
+	cmnBadger "github.com/oasisprotocol/oasis-core/go/common/badger"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/entity"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
@@ -283,6 +284,25 @@ func ExportEntity(signerBackend, entityDir string) error {
 	return err
 }
 
+// LoadDBEncryptionKey loads the database encryption key for the given key source.
+//
+// In case the key is derived from the node identity, the node signer is loaded
+// from the given data directory.
+func LoadDBEncryptionKey(dataDir, source, keyFile string) ([]byte, error) {
+	var signer signature.Signer
+	if source == cmnBadger.EncryptionKeySourceIdentity {
+		factory, err := cmdSigner.NewFactory(cmdSigner.Backend(), dataDir, signature.SignerNode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create signer factory for %s: %w", cmdSigner.Backend(), err)
+		}
+		if signer, err = factory.Load(signature.SignerNode); err != nil {
+			return nil, fmt.Errorf("failed to load node signer: %w", err)
+		}
+	}
+
+	return cmnBadger.NewEncryptionKey(source, keyFile, signer)
+}
+
 // GetUserConfirmation displays the prompt, and scans the input for
 // the user's confirmation, until the user either explicitly confirms
 // or rejects the prompt.
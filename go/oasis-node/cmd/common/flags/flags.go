@@ -29,6 +29,13 @@ const (
 	// yes.
 	CfgAssumeYes      = "assume_yes"
 	cfgAssumeYesShort = "y"
+
+	// CfgDBEncryptionKeySource is the flag used to specify the source of the key used for
+	// encrypting node databases at rest.
+	CfgDBEncryptionKeySource = "db.encryption.key_source"
+	// CfgDBEncryptionKeyFile is the flag used to specify the file containing the hex-encoded
+	// key used for encrypting node databases at rest.
+	CfgDBEncryptionKeyFile = "db.encryption.key_file"
 )
 
 var (
@@ -52,6 +59,9 @@ var (
 
 	// AssumeYesFlag has the assume yes flag.
 	AssumeYesFlag = flag.NewFlagSet("", flag.ContinueOnError)
+
+	// DBEncryptionFlags has the database encryption flags.
+	DBEncryptionFlags = flag.NewFlagSet("", flag.ContinueOnError)
 )
 
 // Verbose returns true iff the verbose flag is set.
@@ -95,6 +105,16 @@ func AssumeYes() bool {
 	return viper.GetBool(CfgAssumeYes)
 }
 
+// DBEncryptionKeySource returns the configured database encryption key source.
+func DBEncryptionKeySource() string {
+	return viper.GetString(CfgDBEncryptionKeySource)
+}
+
+// DBEncryptionKeyFile returns the configured database encryption key file.
+func DBEncryptionKeyFile() string {
+	return viper.GetString(CfgDBEncryptionKeyFile)
+}
+
 func init() {
 	VerboseFlags.BoolP(cfgVerbose, "v", false, "verbose output")
 
@@ -114,6 +134,9 @@ func init() {
 
 	AssumeYesFlag.BoolP(CfgAssumeYes, cfgAssumeYesShort, false, "automatically assume yes for all questions")
 
+	DBEncryptionFlags.String(CfgDBEncryptionKeySource, "", "database encryption key source (identity, file; default disabled)")
+	DBEncryptionFlags.String(CfgDBEncryptionKeyFile, "", "path to the hex-encoded database encryption key (for the file key source)")
+
 	for _, v := range []*flag.FlagSet{
 		VerboseFlags,
 		ForceFlags,
@@ -123,6 +146,7 @@ func init() {
 		DebugDontBlameOasisFlag,
 		DryRunFlag,
 		AssumeYesFlag,
+		DBEncryptionFlags,
 	} {
 		_ = viper.BindPFlags(v)
 	}
@@ -93,6 +93,18 @@ func doDumpDB(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	encryptionKey, err := cmdCommon.LoadDBEncryptionKey(
+		dataDir,
+		flags.DBEncryptionKeySource(),
+		flags.DBEncryptionKeyFile(),
+	)
+	if err != nil {
+		logger.Error("failed to load database encryption key",
+			"err", err,
+		)
+		return
+	}
+
 	// Initialize the ABCI state storage for access.
 	//
 	// Note: While it would be great to always use read-only DB access,
@@ -104,11 +116,12 @@ func doDumpDB(cmd *cobra.Command, args []string) {
 	ldb, _, stateRoot, err := abci.InitStateStorage(
 		ctx,
 		&abci.ApplicationConfig{
-			DataDir:             filepath.Join(dataDir, tendermintCommon.StateDir),
-			StorageBackend:      storageDB.BackendNameBadgerDB, // No other backend for now.
-			MemoryOnlyStorage:   false,
-			ReadOnlyStorage:     viper.GetBool(cfgDumpReadOnlyDB),
-			DisableCheckpointer: true,
+			DataDir:              filepath.Join(dataDir, tendermintCommon.StateDir),
+			StorageBackend:       storageDB.BackendNameBadgerDB, // No other backend for now.
+			MemoryOnlyStorage:    false,
+			ReadOnlyStorage:      viper.GetBool(cfgDumpReadOnlyDB),
+			DisableCheckpointer:  true,
+			StorageEncryptionKey: encryptionKey,
 		},
 	)
 	if err != nil {
@@ -383,6 +396,7 @@ func (qs *dumpQueryState) LastRetainedVersion() (int64, error) {
 // Register registers the dumpdb sub-commands.
 func Register(parentCmd *cobra.Command) {
 	dumpDBCmd.Flags().AddFlagSet(flags.GenesisFileFlags)
+	dumpDBCmd.Flags().AddFlagSet(flags.DBEncryptionFlags)
 	dumpDBCmd.Flags().AddFlagSet(dumpDBFlags)
 	parentCmd.AddCommand(dumpDBCmd)
 }
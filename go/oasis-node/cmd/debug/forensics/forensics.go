@@ -0,0 +1,280 @@
+// Package forensics implements the executor forensics debug sub-commands.
+package forensics
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+	tlsCert "github.com/oasisprotocol/oasis-core/go/common/crypto/tls"
+	cmnGrpc "github.com/oasisprotocol/oasis-core/go/common/grpc"
+	"github.com/oasisprotocol/oasis-core/go/common/identity"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
+	cmdFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
+	cmdGrpc "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/grpc"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/api"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/forensics"
+)
+
+const (
+	cfgFetchNodes     = "forensics.nodes"
+	cfgFetchOutputDir = "forensics.output_dir"
+	cfgFetchTLSCert   = "forensics.tls.cert"
+	cfgFetchTLSKey    = "forensics.tls.key"
+)
+
+var (
+	forensicsCmd = &cobra.Command{
+		Use:   "forensics",
+		Short: "executor discrepancy forensics utilities",
+	}
+
+	forensicsFetchCmd = &cobra.Command{
+		Use:   "fetch runtime-id (hex) round",
+		Short: "fetch forensics bundles for a round from executor nodes",
+		Args:  cobra.ExactArgs(2),
+		Run:   doFetch,
+	}
+
+	forensicsDiffCmd = &cobra.Command{
+		Use:   "diff bundle-file bundle-file...",
+		Short: "compare forensics bundles of different executor nodes",
+		Long: "Compare the inputs and outputs recorded in the given forensics bundles. " +
+			"All bundles are compared against the first one.",
+		Args: cobra.MinimumNArgs(2),
+		Run:  doDiff,
+	}
+
+	forensicsFetchFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+	logger = logging.GetLogger("cmd/debug/forensics")
+)
+
+// loadClientCertificate loads (or generates) the client TLS certificate used to authenticate
+// with the external gRPC endpoint of remote executor nodes.
+func loadClientCertificate() (*tls.Certificate, error) {
+	certPath, keyPath := viper.GetString(cfgFetchTLSCert), viper.GetString(cfgFetchTLSKey)
+	if certPath == "" || keyPath == "" {
+		return nil, fmt.Errorf("client TLS certificate and key paths must be configured")
+	}
+	cert, err := tlsCert.LoadOrGenerate(certPath, keyPath, identity.CommonName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client TLS certificate: %w", err)
+	}
+
+	// The public key needs to be allowed by the remote nodes (worker.executor.forensics.allowed_clients).
+	logger.Info("using client TLS certificate",
+		"pub_key", memorySigner.NewFromRuntime(cert.PrivateKey.(ed25519.PrivateKey)).Public(),
+	)
+
+	return cert, nil
+}
+
+// dial establishes a connection with the internal gRPC endpoint of the node at the given address.
+func dial(addr string) (*grpc.ClientConn, error) {
+	if _, err := os.Stat(addr); err == nil {
+		addr = "unix:" + addr
+	}
+	return cmnGrpc.Dial(addr, grpc.WithInsecure())
+}
+
+// dialExternal establishes a connection with the external gRPC endpoint of the node at the given
+// address of the form pubkey@host:port, authenticating with the given client certificate.
+func dialExternal(addr string, cert *tls.Certificate) (*grpc.ClientConn, error) {
+	var tlsAddr node.TLSAddress
+	if err := tlsAddr.UnmarshalText([]byte(addr)); err != nil {
+		return nil, fmt.Errorf("malformed address: %w", err)
+	}
+	creds, err := cmnGrpc.NewClientCreds(&cmnGrpc.ClientOptions{
+		CommonName:    identity.CommonName,
+		ServerPubKeys: map[signature.PublicKey]bool{tlsAddr.PubKey: true},
+		Certificates:  []tls.Certificate{*cert},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cmnGrpc.Dial(tlsAddr.Address.String(), grpc.WithTransportCredentials(creds))
+}
+
+// loadBundle loads a CBOR-serialized forensics bundle from the given file.
+func loadBundle(fn string) (*api.ForensicsBundle, error) {
+	raw, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	var bundle api.ForensicsBundle
+	if err = cbor.Unmarshal(raw, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to decode bundle: %w", err)
+	}
+	return &bundle, nil
+}
+
+func doFetch(cmd *cobra.Command, args []string) {
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	var runtimeID common.Namespace
+	if err := runtimeID.UnmarshalHex(args[0]); err != nil {
+		logger.Error("malformed runtime id",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+	round, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		logger.Error("malformed round",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
+	nodes := viper.GetStringSlice(cfgFetchNodes)
+	if len(nodes) == 0 {
+		addr, _ := cmd.Flags().GetString(cmdGrpc.CfgAddress)
+		nodes = []string{addr}
+	}
+
+	// Only load the client certificate once and only if needed.
+	var (
+		cert    *tls.Certificate
+		certErr error
+	)
+	getCert := func() (*tls.Certificate, error) {
+		if cert == nil && certErr == nil {
+			cert, certErr = loadClientCertificate()
+		}
+		return cert, certErr
+	}
+
+	var failed bool
+	for _, addr := range nodes {
+		fn, err := fetchBundle(addr, getCert, runtimeID, round)
+		if err != nil {
+			logger.Error("failed to fetch forensics bundle",
+				"err", err,
+				"address", addr,
+			)
+			failed = true
+			continue
+		}
+		fmt.Println(fn)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func fetchBundle(addr string, getCert func() (*tls.Certificate, error), runtimeID common.Namespace, round uint64) (string, error) {
+	var (
+		conn *grpc.ClientConn
+		err  error
+	)
+	if strings.Contains(addr, "@") {
+		var cert *tls.Certificate
+		if cert, err = getCert(); err != nil {
+			return "", err
+		}
+		conn, err = dialExternal(addr, cert)
+	} else {
+		conn, err = dial(addr)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to establish connection with node: %w", err)
+	}
+	defer conn.Close()
+
+	client := api.NewExecutorWorkerClient(conn)
+	bundle, err := client.GetForensicsBundle(context.Background(), &api.GetForensicsBundleRequest{
+		RuntimeID: runtimeID,
+		Round:     round,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	fn := filepath.Join(
+		viper.GetString(cfgFetchOutputDir),
+		fmt.Sprintf("forensics-%s-%d-%s.cbor", runtimeID, round, hex.EncodeToString(bundle.NodeID[:])),
+	)
+	if err = ioutil.WriteFile(fn, cbor.Marshal(bundle), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return fn, nil
+}
+
+func doDiff(cmd *cobra.Command, args []string) {
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	bundles := make([]*api.ForensicsBundle, 0, len(args))
+	for _, fn := range args {
+		bundle, err := loadBundle(fn)
+		if err != nil {
+			logger.Error("failed to load forensics bundle",
+				"err", err,
+				"fn", fn,
+			)
+			os.Exit(1)
+		}
+		bundles = append(bundles, bundle)
+	}
+
+	var differs bool
+	for i, bundle := range bundles[1:] {
+		diffs := forensics.Diff(bundles[0], bundle)
+		if len(diffs) == 0 {
+			continue
+		}
+		differs = true
+
+		fmt.Printf("%s (node %s) vs %s (node %s):\n", args[0], bundles[0].NodeID, args[i+1], bundle.NodeID)
+		for _, d := range diffs {
+			fmt.Printf("  %s\n", d)
+		}
+	}
+	if differs {
+		os.Exit(1)
+	}
+	fmt.Println("no differences")
+}
+
+// Register registers the forensics sub-command and all of its children.
+func Register(parentCmd *cobra.Command) {
+	forensicsCmd.PersistentFlags().AddFlagSet(cmdGrpc.ClientFlags)
+	forensicsFetchCmd.Flags().AddFlagSet(forensicsFetchFlags)
+	forensicsReplayCmd.Flags().AddFlagSet(forensicsReplayFlags)
+	forensicsReplayCmd.Flags().AddFlagSet(cmdFlags.DebugDontBlameOasisFlag)
+
+	forensicsCmd.AddCommand(forensicsFetchCmd)
+	forensicsCmd.AddCommand(forensicsDiffCmd)
+	forensicsCmd.AddCommand(forensicsReplayCmd)
+	parentCmd.AddCommand(forensicsCmd)
+}
+
+func init() {
+	forensicsFetchFlags.StringSlice(cfgFetchNodes, nil, "gRPC addresses of the executor nodes to fetch bundles from, either internal endpoints or external endpoints of the form PubKey@ip:port (default: --address)")
+	forensicsFetchFlags.String(cfgFetchOutputDir, ".", "directory to write the fetched bundles to")
+	forensicsFetchFlags.String(cfgFetchTLSCert, "", "path to the client TLS certificate used for external endpoints (generated if missing)")
+	forensicsFetchFlags.String(cfgFetchTLSKey, "", "path to the client TLS private key used for external endpoints (generated if missing)")
+	_ = viper.BindPFlags(forensicsFetchFlags)
+}
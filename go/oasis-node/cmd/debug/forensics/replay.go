@@ -0,0 +1,290 @@
+package forensics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	consensusAPI "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
+	cmdFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
+	cmdGrpc "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/grpc"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	hostMock "github.com/oasisprotocol/oasis-core/go/runtime/host/mock"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	hostSandbox "github.com/oasisprotocol/oasis-core/go/runtime/host/sandbox"
+	storageAPI "github.com/oasisprotocol/oasis-core/go/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/api"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/forensics"
+)
+
+const (
+	cfgReplayRuntimeBinary = "forensics.runtime.binary"
+	cfgReplayProvisioner   = "forensics.runtime.provisioner"
+	cfgReplaySandboxBinary = "forensics.runtime.sandbox_binary"
+	cfgReplayTimeout       = "forensics.runtime.timeout"
+
+	provisionerMock       = "mock"
+	provisionerUnconfined = "unconfined"
+	provisionerSandboxed  = "sandboxed"
+)
+
+var (
+	forensicsReplayCmd = &cobra.Command{
+		Use:   "replay bundle-file",
+		Short: "replay a forensics bundle in a local runtime and compare the results",
+		Long: "Re-execute the batch recorded in the given forensics bundle in a locally provisioned " +
+			"runtime against the parent state root and compare the results with the recorded " +
+			"outputs. Runtime and consensus state are read from the node given by --address.",
+		Args: cobra.ExactArgs(1),
+		Run:  doReplay,
+	}
+
+	forensicsReplayFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+	errMethodNotSupported   = errors.New("method not supported")
+	errEndpointNotSupported = errors.New("endpoint not supported")
+)
+
+// replayHandler is a runtime host handler serving storage requests of a replayed batch from a
+// remote node.
+type replayHandler struct {
+	sync.Mutex
+
+	storage        syncer.ReadSyncer
+	consensus      syncer.ReadSyncer
+	consensusLight consensusAPI.LightClientBackend
+	localStorage   map[string][]byte
+}
+
+func (h *replayHandler) Handle(ctx context.Context, body *protocol.Body) (*protocol.Body, error) {
+	// Storage.
+	if body.HostStorageSyncRequest != nil {
+		rq := body.HostStorageSyncRequest
+
+		var rs syncer.ReadSyncer
+		switch rq.Endpoint {
+		case protocol.HostStorageEndpointRuntime:
+			rs = h.storage
+		case protocol.HostStorageEndpointConsensus:
+			rs = h.consensus
+		default:
+			return nil, errEndpointNotSupported
+		}
+
+		var rsp *storageAPI.ProofResponse
+		var err error
+		switch {
+		case rq.SyncGet != nil:
+			rsp, err = rs.SyncGet(ctx, rq.SyncGet)
+		case rq.SyncGetPrefixes != nil:
+			rsp, err = rs.SyncGetPrefixes(ctx, rq.SyncGetPrefixes)
+		case rq.SyncIterate != nil:
+			rsp, err = rs.SyncIterate(ctx, rq.SyncIterate)
+		default:
+			return nil, errMethodNotSupported
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &protocol.Body{HostStorageSyncResponse: &protocol.HostStorageSyncResponse{ProofResponse: rsp}}, nil
+	}
+	// Local storage, kept in memory as the replay should not affect the node.
+	if body.HostLocalStorageGetRequest != nil {
+		h.Lock()
+		defer h.Unlock()
+		return &protocol.Body{HostLocalStorageGetResponse: &protocol.HostLocalStorageGetResponse{
+			Value: h.localStorage[string(body.HostLocalStorageGetRequest.Key)],
+		}}, nil
+	}
+	if body.HostLocalStorageSetRequest != nil {
+		h.Lock()
+		defer h.Unlock()
+		h.localStorage[string(body.HostLocalStorageSetRequest.Key)] = body.HostLocalStorageSetRequest.Value
+		return &protocol.Body{HostLocalStorageSetResponse: &protocol.Empty{}}, nil
+	}
+	// Consensus light client.
+	if body.HostFetchConsensusBlockRequest != nil {
+		lb, err := h.consensusLight.GetLightBlock(ctx, int64(body.HostFetchConsensusBlockRequest.Height))
+		if err != nil {
+			return nil, err
+		}
+		return &protocol.Body{HostFetchConsensusBlockResponse: &protocol.HostFetchConsensusBlockResponse{
+			Block: *lb,
+		}}, nil
+	}
+
+	// Key manager RPC is not available during replay.
+	return nil, errMethodNotSupported
+}
+
+func newProvisioner(hostInfo *protocol.HostInfo) (host.Provisioner, error) {
+	switch p := viper.GetString(cfgReplayProvisioner); p {
+	case provisionerMock:
+		if !cmdFlags.DebugDontBlameOasis() {
+			return nil, fmt.Errorf("mock provisioner requires use of unsafe debug flags")
+		}
+		return hostMock.New(), nil
+	case provisionerUnconfined:
+		if !cmdFlags.DebugDontBlameOasis() {
+			return nil, fmt.Errorf("unconfined provisioner requires use of unsafe debug flags")
+		}
+		return hostSandbox.New(hostSandbox.Config{
+			HostInfo:          hostInfo,
+			InsecureNoSandbox: true,
+		})
+	case provisionerSandboxed:
+		sandboxBinary := viper.GetString(cfgReplaySandboxBinary)
+		if _, err := os.Stat(sandboxBinary); err != nil {
+			return nil, fmt.Errorf("failed to stat sandbox binary: %w", err)
+		}
+		return hostSandbox.New(hostSandbox.Config{
+			HostInfo:          hostInfo,
+			SandboxBinaryPath: sandboxBinary,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported runtime provisioner: %s", p)
+	}
+}
+
+// replay re-executes the batch of the given bundle in a locally provisioned runtime and returns
+// a bundle with the replayed outputs.
+func replay(ctx context.Context, bundle *api.ForensicsBundle) (*api.ForensicsBundle, error) {
+	conn, err := dial(viper.GetString(cmdGrpc.CfgAddress))
+	if err != nil {
+		return nil, fmt.Errorf("failed to establish connection with node: %w", err)
+	}
+	defer conn.Close()
+
+	cs, err := consensusAPI.NewConsensusClient(conn).GetStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consensus layer status: %w", err)
+	}
+	provisioner, err := newProvisioner(&protocol.HostInfo{
+		ConsensusBackend:         cs.Backend,
+		ConsensusProtocolVersion: cs.Version.ToU64(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	consensusLight := consensusAPI.NewConsensusLightClient(conn)
+	rt, err := provisioner.NewRuntime(ctx, host.Config{
+		RuntimeID: bundle.RuntimeID,
+		Path:      viper.GetString(cfgReplayRuntimeBinary),
+		MessageHandler: &replayHandler{
+			storage:        storageAPI.NewStorageClient(conn),
+			consensus:      consensusLight.State(),
+			consensusLight: consensusLight,
+			localStorage:   make(map[string][]byte),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision runtime: %w", err)
+	}
+	evCh, sub, err := rt.WatchEvents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch runtime events: %w", err)
+	}
+	defer sub.Close()
+	if err = rt.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start runtime: %w", err)
+	}
+	defer rt.Stop()
+
+	// Wait for the runtime to start.
+	select {
+	case ev := <-evCh:
+		if ev.Started == nil {
+			if ev.FailedToStart != nil {
+				return nil, fmt.Errorf("failed to start runtime: %w", ev.FailedToStart.Error)
+			}
+			return nil, fmt.Errorf("unexpected runtime event: %+v", ev)
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	rq := bundle.Request
+	rsp, err := rt.Call(ctx, &protocol.Body{RuntimeExecuteTxBatchRequest: &rq})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute batch: %w", err)
+	}
+	if rsp.RuntimeExecuteTxBatchResponse == nil {
+		return nil, fmt.Errorf("malformed response from runtime")
+	}
+	computed := rsp.RuntimeExecuteTxBatchResponse.Batch
+
+	replayed := *bundle
+	replayed.Body.Header = computed.Header
+	replayed.Body.Failure = commitment.FailureNone
+	replayed.IOWriteLog = computed.IOWriteLog
+	replayed.StateWriteLog = computed.StateWriteLog
+	return &replayed, nil
+}
+
+func doReplay(cmd *cobra.Command, args []string) {
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	if viper.GetString(cfgReplayRuntimeBinary) == "" && viper.GetString(cfgReplayProvisioner) != provisionerMock {
+		logger.Error("runtime binary must be set")
+		os.Exit(1)
+	}
+	bundle, err := loadBundle(args[0])
+	if err != nil {
+		logger.Error("failed to load forensics bundle",
+			"err", err,
+			"fn", args[0],
+		)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration(cfgReplayTimeout))
+	defer cancel()
+
+	logger.Info("replaying batch",
+		"runtime_id", bundle.RuntimeID,
+		"round", bundle.Round,
+		"node_id", bundle.NodeID,
+		"batch_size", len(bundle.Request.Inputs),
+	)
+
+	replayed, err := replay(ctx, bundle)
+	if err != nil {
+		logger.Error("failed to replay batch",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
+	diffs := forensics.Diff(bundle, replayed)
+	if len(diffs) == 0 {
+		fmt.Println("replayed results match the recorded results")
+		return
+	}
+	fmt.Printf("replayed results differ from the recorded results (node %s):\n", bundle.NodeID)
+	for _, d := range diffs {
+		fmt.Printf("  %s\n", d)
+	}
+	os.Exit(1)
+}
+
+func init() {
+	forensicsReplayFlags.String(cfgReplayRuntimeBinary, "", "path to the runtime binary to replay the batch with")
+	forensicsReplayFlags.String(cfgReplayProvisioner, provisionerSandboxed, "runtime provisioner to use (sandboxed, unconfined or mock)")
+	forensicsReplayFlags.String(cfgReplaySandboxBinary, "/usr/bin/bwrap", "path to the sandbox binary (bubblewrap)")
+	forensicsReplayFlags.Duration(cfgReplayTimeout, 5*time.Minute, "timeout for provisioning the runtime and replaying the batch")
+	_ = viper.BindPFlags(forensicsReplayFlags)
+}
@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
+	cmdFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/runtime/history"
+	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
+	storageAPI "github.com/oasisprotocol/oasis-core/go/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/archive"
+	"github.com/oasisprotocol/oasis-core/go/worker/storage"
+	"github.com/oasisprotocol/oasis-core/go/worker/storage/committee"
+)
+
+const (
+	cfgArchiveFile       = "storage.archive.file"
+	cfgArchiveStartRound = "storage.archive.start_round"
+	cfgArchiveEndRound   = "storage.archive.end_round"
+)
+
+var (
+	storageArchiveCmd = &cobra.Command{
+		Use:   "archive",
+		Short: "write log archive utilities",
+	}
+
+	storageArchiveExportCmd = &cobra.Command{
+		Use:   "export runtime-id (hex)",
+		Short: "export write logs for a range of rounds into an archive",
+		Args:  validateArchiveArgs,
+		Run:   doArchiveExport,
+	}
+
+	storageArchiveImportCmd = &cobra.Command{
+		Use:   "import runtime-id (hex)",
+		Short: "rebuild the node database from a write log archive",
+		Args:  validateArchiveArgs,
+		Run:   doArchiveImport,
+	}
+
+	storageArchiveFlags       = flag.NewFlagSet("", flag.ContinueOnError)
+	storageArchiveExportFlags = flag.NewFlagSet("", flag.ContinueOnError)
+)
+
+func validateArchiveArgs(cmd *cobra.Command, args []string) error {
+	nrFn := cobra.ExactArgs(1)
+	if err := nrFn(cmd, args); err != nil {
+		return err
+	}
+	if err := ValidateRuntimeIDStr(args[0]); err != nil {
+		return fmt.Errorf("malformed runtime id '%v': %w", args[0], err)
+	}
+	return nil
+}
+
+// archiveInit performs common initialization for the archive commands and returns the runtime
+// identifier and runtime state directory.
+func archiveInit(args []string) (common.Namespace, string, bool) {
+	var id common.Namespace
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	dataDir := cmdCommon.DataDir()
+	if dataDir == "" {
+		logger.Error("data directory must be set")
+		return id, "", false
+	}
+	if viper.GetString(cfgArchiveFile) == "" {
+		logger.Error("archive file must be set")
+		return id, "", false
+	}
+	if err := id.UnmarshalHex(args[0]); err != nil {
+		logger.Error("failed to decode runtime id",
+			"err", err,
+		)
+		return id, "", false
+	}
+
+	return id, filepath.Join(dataDir, runtimeRegistry.RuntimesDir, id.String()), true
+}
+
+func doArchiveExport(cmd *cobra.Command, args []string) {
+	var ok bool
+	defer func() {
+		if !ok {
+			os.Exit(1)
+		}
+	}()
+
+	id, dataDir, initOk := archiveInit(args)
+	if !initOk {
+		return
+	}
+	ctx := context.Background()
+
+	storageBackend, err := newDirectStorageBackend(dataDir, id)
+	if err != nil {
+		logger.Error("failed to construct storage backend",
+			"err", err,
+		)
+		return
+	}
+	<-storageBackend.Initialized()
+	defer storageBackend.Cleanup()
+
+	blockHistory, err := history.New(dataDir, id, nil)
+	if err != nil {
+		logger.Error("failed to open runtime history",
+			"err", err,
+		)
+		return
+	}
+	defer blockHistory.Close()
+
+	startRound := viper.GetUint64(cfgArchiveStartRound)
+	endRound := viper.GetUint64(cfgArchiveEndRound)
+	if endRound == committee.RoundLatest {
+		var latestBlock *block.Block
+		if latestBlock, err = blockHistory.GetLatestBlock(ctx); err != nil {
+			logger.Error("failed to get latest block",
+				"err", err,
+			)
+			return
+		}
+		endRound = latestBlock.Header.Round
+	}
+
+	// Unless we start at genesis, the archive needs to reference the parent block.
+	startBlock, err := blockHistory.GetBlock(ctx, startRound)
+	if err != nil {
+		logger.Error("failed to get start block",
+			"err", err,
+			"round", startRound,
+		)
+		return
+	}
+	var parent *block.Header
+	if !startBlock.Header.PreviousHash.IsEmpty() {
+		var parentBlock *block.Block
+		if parentBlock, err = blockHistory.GetBlock(ctx, startRound-1); err != nil {
+			logger.Error("failed to get parent block",
+				"err", err,
+				"round", startRound-1,
+			)
+			return
+		}
+		parent = &parentBlock.Header
+	}
+
+	fn := viper.GetString(cfgArchiveFile)
+	f, err := os.Create(fn)
+	if err != nil {
+		logger.Error("failed to create archive file",
+			"err", err,
+			"fn", fn,
+		)
+		return
+	}
+	defer f.Close()
+
+	bw := bufio.NewWriter(f)
+	w, err := archive.NewWriter(bw, id, parent)
+	if err != nil {
+		logger.Error("failed to create archive writer",
+			"err", err,
+		)
+		return
+	}
+
+	logger.Info("exporting write logs",
+		"runtime_id", id,
+		"start_round", startRound,
+		"end_round", endRound,
+	)
+
+	if err = archive.Export(ctx, w, storageBackend, blockHistory.GetBlock, startRound, endRound); err != nil {
+		logger.Error("failed to export write logs",
+			"err", err,
+		)
+		return
+	}
+	if err = w.Close(); err != nil {
+		logger.Error("failed to close archive",
+			"err", err,
+		)
+		return
+	}
+	if err = bw.Flush(); err != nil {
+		logger.Error("failed to flush archive file",
+			"err", err,
+		)
+		return
+	}
+
+	ok = true
+}
+
+func doArchiveImport(cmd *cobra.Command, args []string) {
+	var ok bool
+	defer func() {
+		if !ok {
+			os.Exit(1)
+		}
+	}()
+
+	id, dataDir, initOk := archiveInit(args)
+	if !initOk {
+		return
+	}
+	if err := common.Mkdir(dataDir); err != nil {
+		logger.Error("failed to create runtime state directory",
+			"err", err,
+		)
+		return
+	}
+
+	storageBackend, err := newDirectStorageBackend(dataDir, id)
+	if err != nil {
+		logger.Error("failed to construct storage backend",
+			"err", err,
+		)
+		return
+	}
+	<-storageBackend.Initialized()
+	defer storageBackend.Cleanup()
+
+	localBackend, isLocal := storageBackend.(storageAPI.LocalBackend)
+	if !isLocal {
+		logger.Error("storage backend is not a local backend")
+		return
+	}
+
+	fn := viper.GetString(cfgArchiveFile)
+	f, err := os.Open(fn)
+	if err != nil {
+		logger.Error("failed to open archive file",
+			"err", err,
+			"fn", fn,
+		)
+		return
+	}
+	defer f.Close()
+
+	r, err := archive.NewReader(bufio.NewReader(f))
+	if err != nil {
+		logger.Error("failed to read archive",
+			"err", err,
+		)
+		return
+	}
+	if !r.Header().Namespace.Equal(&id) {
+		logger.Error("archive is for a different runtime",
+			"runtime_id", id,
+			"archive_runtime_id", r.Header().Namespace,
+		)
+		return
+	}
+
+	lastBlock, err := archive.Import(context.Background(), r, localBackend.NodeDB())
+	if err != nil {
+		logger.Error("failed to import write logs",
+			"err", err,
+		)
+		return
+	}
+
+	logger.Info("write logs imported",
+		"runtime_id", id,
+		"last_round", lastBlock.Round,
+		"state_root", lastBlock.StateRoot,
+	)
+
+	ok = true
+}
+
+func registerArchiveCmds(parentCmd *cobra.Command) {
+	for _, cmd := range []*cobra.Command{
+		storageArchiveExportCmd,
+		storageArchiveImportCmd,
+	} {
+		cmd.Flags().AddFlagSet(storage.Flags)
+		cmd.Flags().AddFlagSet(cmdFlags.DBEncryptionFlags)
+		cmd.Flags().AddFlagSet(storageArchiveFlags)
+		storageArchiveCmd.AddCommand(cmd)
+	}
+	storageArchiveExportCmd.Flags().AddFlagSet(storageArchiveExportFlags)
+
+	parentCmd.AddCommand(storageArchiveCmd)
+}
+
+func init() {
+	storageArchiveFlags.String(cfgArchiveFile, "", "path to the write log archive file")
+	_ = viper.BindPFlags(storageArchiveFlags)
+
+	storageArchiveExportFlags.Uint64(cfgArchiveStartRound, 0, "first round to export")
+	storageArchiveExportFlags.Uint64(cfgArchiveEndRound, committee.RoundLatest, "last round to export; default latest")
+	_ = viper.BindPFlags(storageArchiveExportFlags)
+}
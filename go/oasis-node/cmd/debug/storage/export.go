@@ -16,6 +16,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common"
 	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
 	cmdConsensus "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/consensus"
+	cmdFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
 	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
 	storageAPI "github.com/oasisprotocol/oasis-core/go/storage/api"
@@ -158,12 +159,22 @@ func exportIterator(fn string, root *storageAPI.Root, it mkvs.Iterator) error {
 func newDirectStorageBackend(dataDir string, namespace common.Namespace) (storageAPI.Backend, error) {
 	// The right thing to do will be to use storage.New, but the backend config
 	// assumes that identity is valid, and we don't have one.
+	encryptionKey, err := cmdCommon.LoadDBEncryptionKey(
+		cmdCommon.DataDir(),
+		cmdFlags.DBEncryptionKeySource(),
+		cmdFlags.DBEncryptionKeyFile(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to load database encryption key: %w", err)
+	}
+
 	cfg := &storageAPI.Config{
 		Backend:           strings.ToLower(viper.GetString(storage.CfgBackend)),
 		DB:                dataDir,
 		ApplyLockLRUSlots: uint64(viper.GetInt(storage.CfgLRUSlots)),
 		Namespace:         namespace,
 		MaxCacheSize:      int64(viper.GetSizeInBytes(storage.CfgMaxCacheSize)),
+		EncryptionKey:     encryptionKey,
 	}
 
 	b := strings.ToLower(viper.GetString(storage.CfgBackend))
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
+	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
+	storageAPI "github.com/oasisprotocol/oasis-core/go/storage/api"
+	nodedb "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
+)
+
+const (
+	cfgGCDryRun  = "storage.gc.dry_run"
+	cfgGCCompact = "storage.gc.compact"
+)
+
+var (
+	storageGCCmd = &cobra.Command{
+		Use:   "gc runtime-id (hex)...",
+		Short: "remove unreachable nodes from the (offline) node database and compact it",
+		Args: func(cmd *cobra.Command, args []string) error {
+			nrFn := cobra.MinimumNArgs(1)
+			if err := nrFn(cmd, args); err != nil {
+				return err
+			}
+			for _, arg := range args {
+				if err := ValidateRuntimeIDStr(arg); err != nil {
+					return fmt.Errorf("malformed runtime id '%v': %w", arg, err)
+				}
+			}
+
+			return nil
+		},
+		Run: doGC,
+	}
+
+	storageGCFlags = flag.NewFlagSet("", flag.ContinueOnError)
+)
+
+func doGC(cmd *cobra.Command, args []string) {
+	var ok bool
+	defer func() {
+		if !ok {
+			os.Exit(1)
+		}
+	}()
+
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	dataDir := cmdCommon.DataDir()
+	if dataDir == "" {
+		logger.Error("data directory must be set")
+		return
+	}
+
+	for _, arg := range args {
+		var id common.Namespace
+		if err := id.UnmarshalHex(arg); err != nil {
+			logger.Error("failed to decode runtime id",
+				"err", err,
+			)
+			return
+		}
+
+		if err := gcRuntime(dataDir, id); err != nil {
+			return
+		}
+	}
+
+	ok = true
+}
+
+func gcRuntime(dataDir string, id common.Namespace) error {
+	dataDir = filepath.Join(dataDir, runtimeRegistry.RuntimesDir, id.String())
+
+	storageBackend, err := newDirectStorageBackend(dataDir, id)
+	if err != nil {
+		logger.Error("failed to construct storage backend",
+			"err", err,
+		)
+		return err
+	}
+
+	<-storageBackend.Initialized()
+	defer storageBackend.Cleanup()
+
+	localBackend, ok := storageBackend.(storageAPI.LocalBackend)
+	if !ok {
+		logger.Error("storage backend is not a local backend")
+		return fmt.Errorf("storage backend is not a local backend")
+	}
+
+	logger.Info("collecting garbage",
+		"runtime_id", id,
+	)
+
+	res, err := localBackend.NodeDB().CollectGarbage(context.Background(), &nodedb.GCOptions{
+		DryRun:  viper.GetBool(cfgGCDryRun),
+		Compact: viper.GetBool(cfgGCCompact),
+	})
+	if err != nil {
+		logger.Error("failed to collect garbage",
+			"err", err,
+			"runtime_id", id,
+		)
+		return err
+	}
+
+	formatted, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		logger.Error("failed to format garbage collection result",
+			"err", err,
+		)
+		return err
+	}
+	fmt.Printf("%s: %s\n", id, formatted)
+
+	return nil
+}
+
+func init() {
+	storageGCFlags.Bool(cfgGCDryRun, false, "only report unreachable nodes without removing them")
+	storageGCFlags.Bool(cfgGCCompact, true, "compact the database after removing unreachable nodes")
+	_ = viper.BindPFlags(storageGCFlags)
+}
@@ -280,15 +280,22 @@ func Register(parentCmd *cobra.Command) {
 	storageForceFinalizeCmd.PersistentFlags().AddFlagSet(cmdFlags.DebugDontBlameOasisFlag)
 
 	storageExportCmd.Flags().AddFlagSet(storage.Flags)
+	storageExportCmd.Flags().AddFlagSet(cmdFlags.DBEncryptionFlags)
 	storageExportCmd.Flags().AddFlagSet(cmdFlags.GenesisFileFlags)
 	storageExportCmd.Flags().AddFlagSet(cmdFlags.DebugDontBlameOasisFlag)
 	storageExportCmd.Flags().AddFlagSet(storageExportFlags)
 
 	storageBenchmarkCmd.Flags().AddFlagSet(storageBenchmarkFlags)
 
+	storageGCCmd.Flags().AddFlagSet(storage.Flags)
+	storageGCCmd.Flags().AddFlagSet(cmdFlags.DBEncryptionFlags)
+	storageGCCmd.Flags().AddFlagSet(storageGCFlags)
+
 	storageCmd.AddCommand(storageCheckRootsCmd)
 	storageCmd.AddCommand(storageForceFinalizeCmd)
 	storageCmd.AddCommand(storageExportCmd)
 	storageCmd.AddCommand(storageBenchmarkCmd)
+	storageCmd.AddCommand(storageGCCmd)
+	registerArchiveCmds(storageCmd)
 	parentCmd.AddCommand(storageCmd)
 }
@@ -79,6 +79,7 @@ const (
 	cfgRoothashDebugDoNotSuspendRuntimes = "roothash.debug.do_not_suspend_runtimes"
 	cfgRoothashDebugBypassStake          = "roothash.debug.bypass_stake" // nolint: gosec
 	cfgRoothashMaxRuntimeMessages        = "roothash.max_runtime_messages"
+	cfgRoothashSuspensionGracePeriod     = "roothash.suspension_grace_period"
 
 	// Staking config flags.
 	CfgStakingTokenSymbol        = "staking.token_symbol"
@@ -432,6 +433,7 @@ func AppendRootHashState(doc *genesis.Document, exports []string, l *logging.Log
 			DebugDoNotSuspendRuntimes: viper.GetBool(cfgRoothashDebugDoNotSuspendRuntimes),
 			DebugBypassStake:          viper.GetBool(cfgRoothashDebugBypassStake),
 			MaxRuntimeMessages:        viper.GetUint32(cfgRoothashMaxRuntimeMessages),
+			SuspensionGracePeriod:     epochtime.EpochTime(viper.GetUint64(cfgRoothashSuspensionGracePeriod)),
 			// TODO: Make these configurable.
 			GasCosts: roothash.DefaultGasCosts,
 		},
@@ -723,6 +725,7 @@ func init() {
 	initGenesisFlags.Bool(cfgRoothashDebugDoNotSuspendRuntimes, false, "do not suspend runtimes (UNSAFE)")
 	initGenesisFlags.Bool(cfgRoothashDebugBypassStake, false, "bypass all roothash stake checks and operations (UNSAFE)")
 	initGenesisFlags.Uint32(cfgRoothashMaxRuntimeMessages, 128, "maximum number of runtime messages submitted in a round")
+	initGenesisFlags.Uint64(cfgRoothashSuspensionGracePeriod, 0, "number of epochs before a runtime with insufficient owner stake is suspended")
 	_ = initGenesisFlags.MarkHidden(cfgRoothashDebugDoNotSuspendRuntimes)
 	_ = initGenesisFlags.MarkHidden(cfgRoothashDebugBypassStake)
 
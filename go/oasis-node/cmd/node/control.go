@@ -8,6 +8,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common/identity"
 	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
 	control "github.com/oasisprotocol/oasis-core/go/control/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
 	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
 	"github.com/oasisprotocol/oasis-core/go/worker/registration"
 )
@@ -144,7 +145,42 @@ func (n *Node) GetRuntimeStatus(ctx context.Context) (map[common.Namespace]contr
 			}
 		}
 
+		// Fetch hosted runtime resource usage.
+		if hrt := n.getHostedRuntime(rt.ID()); hrt != nil {
+			if reporter, ok := hrt.(host.ResourceUsageReporter); ok {
+				status.Resources, err = reporter.GetResourceUsage()
+				if err != nil {
+					n.logger.Error("failed to fetch runtime resource usage",
+						"err", err,
+						"runtime_id", rt.ID(),
+					)
+				}
+			}
+			if reporter, ok := hrt.(host.CrashStatusReporter); ok {
+				status.Crashes, err = reporter.GetCrashStatus()
+				if err != nil {
+					n.logger.Error("failed to fetch runtime crash status",
+						"err", err,
+						"runtime_id", rt.ID(),
+					)
+				}
+			}
+		}
+
 		runtimes[rt.ID()] = status
 	}
 	return runtimes, nil
 }
+
+// getHostedRuntime returns the runtime hosted by this node (if any).
+func (n *Node) getHostedRuntime(id common.Namespace) host.Runtime {
+	if n.ExecutorWorker != nil && n.ExecutorWorker.Enabled() {
+		if hrt := n.ExecutorWorker.GetHostedRuntime(id); hrt != nil {
+			return hrt
+		}
+	}
+	if n.KeymanagerWorker != nil && n.KeymanagerWorker.Enabled() && n.KeymanagerWorker.GetRuntime().ID() == id {
+		return n.KeymanagerWorker.GetHostedRuntime()
+	}
+	return nil
+}
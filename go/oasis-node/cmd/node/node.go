@@ -43,6 +43,7 @@ import (
 	cmdSigner "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/signer"
 	"github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/tracing"
 	registryAPI "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothashAPI "github.com/oasisprotocol/oasis-core/go/roothash/api"
 	runtimeClient "github.com/oasisprotocol/oasis-core/go/runtime/client"
 	runtimeClientAPI "github.com/oasisprotocol/oasis-core/go/runtime/client/api"
 	enclaverpc "github.com/oasisprotocol/oasis-core/go/runtime/enclaverpc/api"
@@ -52,6 +53,7 @@ import (
 	sentryAPI "github.com/oasisprotocol/oasis-core/go/sentry/api"
 	stakingAPI "github.com/oasisprotocol/oasis-core/go/staking/api"
 	storageAPI "github.com/oasisprotocol/oasis-core/go/storage/api"
+	storageClient "github.com/oasisprotocol/oasis-core/go/storage/client"
 	"github.com/oasisprotocol/oasis-core/go/upgrade"
 	upgradeAPI "github.com/oasisprotocol/oasis-core/go/upgrade/api"
 	workerCommon "github.com/oasisprotocol/oasis-core/go/worker/common"
@@ -200,6 +202,7 @@ func (n *Node) startRuntimeServices() error {
 	registryAPI.RegisterService(grpcSrv, n.Consensus.Registry())
 	stakingAPI.RegisterService(grpcSrv, n.Consensus.Staking())
 	keymanagerAPI.RegisterService(grpcSrv, n.Consensus.KeyManager())
+	roothashAPI.RegisterService(grpcSrv, n.Consensus.RootHash())
 
 	// Register dump genesis halt hook.
 	n.Consensus.RegisterHaltHook(func(ctx context.Context, blockHeight int64, epoch epochtime.EpochTime) {
@@ -242,6 +245,7 @@ func (n *Node) startRuntimeServices() error {
 		n.Consensus,
 		n.RuntimeRegistry,
 		n.P2P,
+		n.ExecutorWorker,
 	)
 	if err != nil {
 		return err
@@ -382,6 +386,7 @@ func (n *Node) initRuntimeWorkers() error {
 
 	// Initialize the executor worker.
 	n.ExecutorWorker, err = executor.New(
+		n.grpcInternal,
 		dataDir,
 		n.CommonWorker,
 		n.RegistrationWorker,
@@ -750,13 +755,20 @@ func Register(parentCmd *cobra.Command) {
 	unsafeResetCmd.Flags().AddFlagSet(flags.DryRunFlag)
 	unsafeResetCmd.Flags().AddFlagSet(unsafeResetFlags)
 
+	rotateDBKeyCmd.Flags().AddFlagSet(flags.DryRunFlag)
+	rotateDBKeyCmd.Flags().AddFlagSet(flags.DBEncryptionFlags)
+	rotateDBKeyCmd.Flags().AddFlagSet(cmdSigner.Flags)
+	rotateDBKeyCmd.Flags().AddFlagSet(rotateDBKeyFlags)
+
 	parentCmd.AddCommand(unsafeResetCmd)
+	parentCmd.AddCommand(rotateDBKeyCmd)
 }
 
 func init() {
 	Flags.AddFlagSet(flags.DebugTestEntityFlags)
 	Flags.AddFlagSet(flags.ConsensusValidatorFlag)
 	Flags.AddFlagSet(flags.GenesisFileFlags)
+	Flags.AddFlagSet(flags.DBEncryptionFlags)
 
 	// Backend initialization flags.
 	for _, v := range []*flag.FlagSet{
@@ -766,6 +778,7 @@ func init() {
 		cmdSigner.Flags,
 		pprof.Flags,
 		storage.Flags,
+		storageClient.Flags,
 		tendermint.Flags,
 		seed.Flags,
 		ias.Flags,
@@ -0,0 +1,128 @@
+package node
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	cmnBadger "github.com/oasisprotocol/oasis-core/go/common/badger"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	tendermintCommon "github.com/oasisprotocol/oasis-core/go/consensus/tendermint/common"
+	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
+	cmdFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
+)
+
+const (
+	// CfgRotateNewKeySource is the source of the new database encryption key
+	// for the rotate-db-key sub-command.
+	CfgRotateNewKeySource = "rotate.new_key_source"
+
+	// CfgRotateNewKeyFile is the file containing the new hex-encoded database
+	// encryption key for the rotate-db-key sub-command.
+	CfgRotateNewKeyFile = "rotate.new_key_file"
+)
+
+var (
+	rotateDBKeyFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+	rotateDBKeyCmd = &cobra.Command{
+		Use:   "rotate-db-key",
+		Short: "rotate the database encryption key (node must be stopped)",
+		Run:   doRotateDBKey,
+	}
+
+	encryptedDatabaseGlobs = []string{
+		runtimeMkvsDatabaseGlob,
+		filepath.Join(tendermintCommon.StateDir, "abci-state", "mkvs_storage.*.db"),
+		filepath.Join(tendermintCommon.StateDir, "data", "*.badger.db"),
+	}
+
+	rotateLogger = logging.GetLogger("cmd/rotate-db-key")
+)
+
+func doRotateDBKey(cmd *cobra.Command, args []string) {
+	var ok bool
+	defer func() {
+		if !ok {
+			os.Exit(1)
+		}
+	}()
+
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	dataDir := cmdCommon.DataDir()
+	if dataDir == "" {
+		rotateLogger.Error("data directory must be set")
+		return
+	}
+
+	oldKey, err := cmdCommon.LoadDBEncryptionKey(dataDir, cmdFlags.DBEncryptionKeySource(), cmdFlags.DBEncryptionKeyFile())
+	if err != nil {
+		rotateLogger.Error("failed to load current database encryption key",
+			"err", err,
+		)
+		return
+	}
+	newKey, err := cmdCommon.LoadDBEncryptionKey(dataDir, viper.GetString(CfgRotateNewKeySource), viper.GetString(CfgRotateNewKeyFile))
+	if err != nil {
+		rotateLogger.Error("failed to load new database encryption key",
+			"err", err,
+		)
+		return
+	}
+
+	isDryRun := cmdFlags.DryRun()
+	if isDryRun {
+		rotateLogger.Info("dry run, no modifications will be made to files")
+	}
+
+	// Enumerate the databases to rotate.
+	var dbPaths []string
+	for _, v := range encryptedDatabaseGlobs {
+		glob := filepath.Join(dataDir, v)
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			rotateLogger.Error("failed to glob database location",
+				"err", err,
+				"glob", glob,
+			)
+			return
+		}
+		dbPaths = append(dbPaths, matches...)
+	}
+
+	// Re-encrypt the databases. Each database is copied into a new database
+	// encrypted with the new key, so enough free space is required to hold
+	// a copy of the largest database.
+	for _, v := range dbPaths {
+		rotateLogger.Info("rotating database encryption key",
+			"path", v,
+		)
+
+		if isDryRun {
+			continue
+		}
+		if err = cmnBadger.RotateEncryptionKey(v, oldKey, newKey); err != nil {
+			rotateLogger.Error("failed to rotate database encryption key",
+				"err", err,
+				"path", v,
+			)
+			return
+		}
+	}
+
+	rotateLogger.Info("database encryption key rotation complete")
+
+	ok = true
+}
+
+func init() {
+	rotateDBKeyFlags.String(CfgRotateNewKeySource, "", "new database encryption key source (identity, file; default disabled)")
+	rotateDBKeyFlags.String(CfgRotateNewKeyFile, "", "path to the new hex-encoded database encryption key (for the file key source)")
+	_ = viper.BindPFlags(rotateDBKeyFlags)
+}
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -25,6 +26,8 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common/sgx"
 	"github.com/oasisprotocol/oasis-core/go/common/version"
 	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	epochtime "github.com/oasisprotocol/oasis-core/go/epochtime/api"
 	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
 	cmdConsensus "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/consensus"
 	cmdFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
@@ -47,12 +50,20 @@ const (
 	CfgVersion        = "runtime.version"
 	CfgVersionEnclave = "runtime.version.enclave"
 
+	cfgVersionBundleHash = "runtime.version.bundle_hash"
+
 	// Executor committee flags.
-	CfgExecutorGroupSize         = "runtime.executor.group_size"
-	CfgExecutorGroupBackupSize   = "runtime.executor.group_backup_size"
-	CfgExecutorAllowedStragglers = "runtime.executor.allowed_stragglers"
-	CfgExecutorRoundTimeout      = "runtime.executor.round_timeout"
-	CfgExecutorMaxMessages       = "runtime.executor.max_messages"
+	CfgExecutorGroupSize           = "runtime.executor.group_size"
+	CfgExecutorGroupBackupSize     = "runtime.executor.group_backup_size"
+	CfgExecutorAllowedStragglers   = "runtime.executor.allowed_stragglers"
+	CfgExecutorRoundTimeout        = "runtime.executor.round_timeout"
+	CfgExecutorMaxMessages         = "runtime.executor.max_messages"
+	CfgExecutorMaxLivenessFailures = "runtime.executor.max_liveness_failures"
+	CfgExecutorLivenessPenalty     = "runtime.executor.liveness_penalty"
+	CfgExecutorMaxInMessages       = "runtime.executor.max_in_messages"
+	CfgExecutorMaxInMessageSize    = "runtime.executor.max_in_message_size"
+	CfgExecutorInMessageQueueSize  = "runtime.executor.in_message_queue_size"
+	CfgExecutorMinInMessageFee     = "runtime.executor.min_in_message_fee"
 
 	// Storage committee flags.
 	CfgStorageGroupSize               = "runtime.storage.group_size"
@@ -64,11 +75,14 @@ const (
 	CfgStorageCheckpointChunkSize     = "runtime.storage.checkpoint_chunk_size"
 
 	// Transaction scheduler flags.
-	CfgTxnSchedulerAlgorithm         = "runtime.txn_scheduler.algorithm"
-	CfgTxnSchedulerBatchFlushTimeout = "runtime.txn_scheduler.flush_timeout"
-	CfgTxnSchedulerMaxBatchSize      = "runtime.txn_scheduler.max_batch_size"
-	CfgTxnSchedulerMaxBatchSizeBytes = "runtime.txn_scheduler.max_batch_size_bytes"
-	CfgTxnSchedulerProposerTimeout   = "runtime.txn_scheduler.proposer_timeout"
+	CfgTxnSchedulerAlgorithm             = "runtime.txn_scheduler.algorithm"
+	CfgTxnSchedulerBatchFlushTimeout     = "runtime.txn_scheduler.flush_timeout"
+	CfgTxnSchedulerMaxBatchSize          = "runtime.txn_scheduler.max_batch_size"
+	CfgTxnSchedulerMaxBatchSizeBytes     = "runtime.txn_scheduler.max_batch_size_bytes"
+	CfgTxnSchedulerProposerTimeout       = "runtime.txn_scheduler.proposer_timeout"
+	CfgTxnSchedulerProposerRotation      = "runtime.txn_scheduler.proposer_rotation"
+	CfgTxnSchedulerBackupProposers       = "runtime.txn_scheduler.backup_proposers"
+	CfgTxnSchedulerBackupProposerTimeout = "runtime.txn_scheduler.backup_proposer_timeout"
 
 	// Admission policy flags.
 	CfgAdmissionPolicy                 = "runtime.admission_policy"
@@ -78,6 +92,13 @@ const (
 
 	// Staking parameters flags.
 	CfgStakingThreshold = "runtime.staking.threshold"
+	// CfgStakingSlashingAmount configures the per-reason slashing amounts.
+	CfgStakingSlashingAmount = "runtime.staking.slashing.amount"
+	// CfgStakingSlashingFreezeInterval configures the per-reason slashing freeze intervals.
+	CfgStakingSlashingFreezeInterval = "runtime.staking.slashing.freeze_interval"
+	// CfgStakingRewardIncorrectResults configures the reward for committing correct results in
+	// rounds where incorrect results were detected.
+	CfgStakingRewardIncorrectResults = "runtime.staking.reward_incorrect_results"
 
 	// List runtimes flags.
 	CfgIncludeSuspended = "include_suspended"
@@ -90,6 +111,8 @@ var (
 	runtimeFlags     = flag.NewFlagSet("", flag.ContinueOnError)
 	runtimeListFlags = flag.NewFlagSet("", flag.ContinueOnError)
 	registerFlags    = flag.NewFlagSet("", flag.ContinueOnError)
+	runtimeIDFlags   = flag.NewFlagSet("", flag.ContinueOnError)
+	lifecycleFlags   = flag.NewFlagSet("", flag.ContinueOnError)
 
 	runtimeCmd = &cobra.Command{
 		Use:   "runtime",
@@ -108,6 +131,24 @@ var (
 		Run:   doGenRegister,
 	}
 
+	pauseCmd = &cobra.Command{
+		Use:   "gen_pause",
+		Short: "generate a pause runtime transaction",
+		Run:   doGenPause,
+	}
+
+	resumeCmd = &cobra.Command{
+		Use:   "gen_resume",
+		Short: "generate a resume runtime transaction",
+		Run:   doGenResume,
+	}
+
+	decommissionCmd = &cobra.Command{
+		Use:   "gen_decommission",
+		Short: "generate a decommission runtime transaction",
+		Run:   doGenDecommission,
+	}
+
 	listCmd = &cobra.Command{
 		Use:   "list",
 		Short: "list registered runtimes",
@@ -197,6 +238,46 @@ func doGenRegister(cmd *cobra.Command, args []string) {
 	cmdConsensus.SignAndSaveTx(context.Background(), tx, nil)
 }
 
+func doGenLifecycleTx(newTx func(nonce uint64, fee *transaction.Fee, id common.Namespace) *transaction.Transaction) {
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	cmdConsensus.InitGenesis()
+	cmdConsensus.AssertTxFileOK()
+
+	var id common.Namespace
+	if err := id.UnmarshalHex(viper.GetString(CfgID)); err != nil {
+		logger.Error("failed to parse runtime ID",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
+	nonce, fee := cmdConsensus.GetTxNonceAndFee()
+	tx := newTx(nonce, fee, id)
+
+	cmdConsensus.SignAndSaveTx(context.Background(), tx, nil)
+}
+
+func doGenPause(cmd *cobra.Command, args []string) {
+	doGenLifecycleTx(func(nonce uint64, fee *transaction.Fee, id common.Namespace) *transaction.Transaction {
+		return registry.NewPauseRuntimeTx(nonce, fee, &registry.PauseRuntime{RuntimeID: id})
+	})
+}
+
+func doGenResume(cmd *cobra.Command, args []string) {
+	doGenLifecycleTx(func(nonce uint64, fee *transaction.Fee, id common.Namespace) *transaction.Transaction {
+		return registry.NewResumeRuntimeTx(nonce, fee, &registry.ResumeRuntime{RuntimeID: id})
+	})
+}
+
+func doGenDecommission(cmd *cobra.Command, args []string) {
+	doGenLifecycleTx(func(nonce uint64, fee *transaction.Fee, id common.Namespace) *transaction.Transaction {
+		return registry.NewDecommissionRuntimeTx(nonce, fee, &registry.DecommissionRuntime{RuntimeID: id})
+	})
+}
+
 func doList(cmd *cobra.Command, args []string) {
 	if err := cmdCommon.Init(); err != nil {
 		cmdCommon.EarlyLogAndExit(err)
@@ -268,6 +349,16 @@ func runtimeFromFlags() (*registry.Runtime, signature.Signer, error) { // nolint
 		)
 		return nil, nil, fmt.Errorf("invalid runtime kind")
 	}
+
+	var livenessPenalty registry.LivenessPenalty
+	s = viper.GetString(CfgExecutorLivenessPenalty)
+	if err = livenessPenalty.FromString(s); err != nil {
+		logger.Error("invalid liveness penalty",
+			CfgExecutorLivenessPenalty, s,
+		)
+		return nil, nil, fmt.Errorf("invalid liveness penalty")
+	}
+
 	switch kind {
 	case registry.KindCompute:
 		if viper.GetString(CfgKeyManager) != "" {
@@ -364,18 +455,26 @@ func runtimeFromFlags() (*registry.Runtime, signature.Signer, error) { // nolint
 		},
 		KeyManager: kmID,
 		Executor: registry.ExecutorParameters{
-			GroupSize:         viper.GetUint64(CfgExecutorGroupSize),
-			GroupBackupSize:   viper.GetUint64(CfgExecutorGroupBackupSize),
-			AllowedStragglers: viper.GetUint64(CfgExecutorAllowedStragglers),
-			RoundTimeout:      viper.GetInt64(CfgExecutorRoundTimeout),
-			MaxMessages:       viper.GetUint32(CfgExecutorMaxMessages),
+			GroupSize:           viper.GetUint64(CfgExecutorGroupSize),
+			GroupBackupSize:     viper.GetUint64(CfgExecutorGroupBackupSize),
+			AllowedStragglers:   viper.GetUint64(CfgExecutorAllowedStragglers),
+			RoundTimeout:        viper.GetInt64(CfgExecutorRoundTimeout),
+			MaxMessages:         viper.GetUint32(CfgExecutorMaxMessages),
+			MaxLivenessFailures: viper.GetUint64(CfgExecutorMaxLivenessFailures),
+			LivenessPenalty:     livenessPenalty,
+			MaxInMessages:       viper.GetUint32(CfgExecutorMaxInMessages),
+			MaxInMessageSize:    uint32(viper.GetSizeInBytes(CfgExecutorMaxInMessageSize)),
+			InMessageQueueSize:  viper.GetUint32(CfgExecutorInMessageQueueSize),
 		},
 		TxnScheduler: registry.TxnSchedulerParameters{
-			Algorithm:         viper.GetString(CfgTxnSchedulerAlgorithm),
-			BatchFlushTimeout: viper.GetDuration(CfgTxnSchedulerBatchFlushTimeout),
-			MaxBatchSize:      viper.GetUint64(CfgTxnSchedulerMaxBatchSize),
-			MaxBatchSizeBytes: uint64(viper.GetSizeInBytes(CfgTxnSchedulerMaxBatchSizeBytes)),
-			ProposerTimeout:   viper.GetInt64(CfgTxnSchedulerProposerTimeout),
+			Algorithm:             viper.GetString(CfgTxnSchedulerAlgorithm),
+			BatchFlushTimeout:     viper.GetDuration(CfgTxnSchedulerBatchFlushTimeout),
+			MaxBatchSize:          viper.GetUint64(CfgTxnSchedulerMaxBatchSize),
+			MaxBatchSizeBytes:     uint64(viper.GetSizeInBytes(CfgTxnSchedulerMaxBatchSizeBytes)),
+			ProposerTimeout:       viper.GetInt64(CfgTxnSchedulerProposerTimeout),
+			ProposerRotation:      viper.GetString(CfgTxnSchedulerProposerRotation),
+			BackupProposers:       viper.GetUint64(CfgTxnSchedulerBackupProposers),
+			BackupProposerTimeout: viper.GetInt64(CfgTxnSchedulerBackupProposerTimeout),
 		},
 		Storage: registry.StorageParameters{
 			GroupSize:               viper.GetUint64(CfgStorageGroupSize),
@@ -387,6 +486,21 @@ func runtimeFromFlags() (*registry.Runtime, signature.Signer, error) { // nolint
 			CheckpointChunkSize:     uint64(viper.GetSizeInBytes(CfgStorageCheckpointChunkSize)),
 		},
 	}
+	if fee := viper.GetString(CfgExecutorMinInMessageFee); fee != "" {
+		if err = rt.Executor.MinInMessageFee.UnmarshalText([]byte(fee)); err != nil {
+			return nil, nil, fmt.Errorf("bad minimum incoming message fee (%s): %w", fee, err)
+		}
+	}
+	if bundleHash := viper.GetString(cfgVersionBundleHash); bundleHash != "" {
+		var h hash.Hash
+		if err = h.UnmarshalHex(bundleHash); err != nil {
+			logger.Error("failed to parse runtime bundle hash",
+				"err", err,
+			)
+			return nil, nil, err
+		}
+		rt.Version.BundleHash = &h
+	}
 	if teeHardware == node.TEEHardwareIntelSGX {
 		var vi registry.VersionInfoIntelSGX
 		for _, v := range viper.GetStringSlice(CfgVersionEnclave) {
@@ -449,6 +563,37 @@ func runtimeFromFlags() (*registry.Runtime, signature.Signer, error) { // nolint
 			rt.Staking.Thresholds[kind] = value
 		}
 	}
+	if amounts := viper.GetStringMapString(CfgStakingSlashingAmount); len(amounts) > 0 {
+		freezeIntervals := viper.GetStringMapString(CfgStakingSlashingFreezeInterval)
+		rt.Staking.Slashing = make(map[staking.SlashReason]staking.Slash)
+		for reasonRaw, amountRaw := range amounts {
+			var (
+				reason staking.SlashReason
+				slash  staking.Slash
+			)
+
+			if err = reason.UnmarshalText([]byte(reasonRaw)); err != nil {
+				return nil, nil, fmt.Errorf("staking: bad slash reason (%s): %w", reasonRaw, err)
+			}
+			if err = slash.Amount.UnmarshalText([]byte(amountRaw)); err != nil {
+				return nil, nil, fmt.Errorf("staking: bad slash amount (%s): %w", amountRaw, err)
+			}
+			if intervalRaw, ok := freezeIntervals[reasonRaw]; ok {
+				var interval uint64
+				if interval, err = strconv.ParseUint(intervalRaw, 10, 64); err != nil {
+					return nil, nil, fmt.Errorf("staking: bad slash freeze interval (%s): %w", intervalRaw, err)
+				}
+				slash.FreezeInterval = epochtime.EpochTime(interval)
+			}
+
+			rt.Staking.Slashing[reason] = slash
+		}
+	}
+	if reward := viper.GetString(CfgStakingRewardIncorrectResults); reward != "" {
+		if err = rt.Staking.RewardIncorrectResults.UnmarshalText([]byte(reward)); err != nil {
+			return nil, nil, fmt.Errorf("staking: bad incorrect results reward (%s): %w", reward, err)
+		}
+	}
 
 	// Validate descriptor.
 	if err = rt.ValidateBasic(true); err != nil {
@@ -483,6 +628,9 @@ func Register(parentCmd *cobra.Command) {
 	for _, v := range []*cobra.Command{
 		initGenesisCmd,
 		registerCmd,
+		pauseCmd,
+		resumeCmd,
+		decommissionCmd,
 		listCmd,
 	} {
 		runtimeCmd.AddCommand(v)
@@ -495,6 +643,14 @@ func Register(parentCmd *cobra.Command) {
 		v.Flags().AddFlagSet(cmdFlags.DebugTestEntityFlags)
 	}
 
+	for _, v := range []*cobra.Command{
+		pauseCmd,
+		resumeCmd,
+		decommissionCmd,
+	} {
+		v.Flags().AddFlagSet(lifecycleFlags)
+	}
+
 	initGenesisCmd.Flags().AddFlagSet(runtimeFlags)
 	initGenesisCmd.Flags().AddFlagSet(outputFlags)
 
@@ -513,7 +669,10 @@ func init() {
 	outputFlags.String(cfgOutput, runtimeGenesisFilename, "File name of the document to be written under datadir")
 	_ = viper.BindPFlags(outputFlags)
 
-	runtimeFlags.String(CfgID, "", "Runtime ID")
+	runtimeIDFlags.String(CfgID, "", "Runtime ID")
+	_ = viper.BindPFlags(runtimeIDFlags)
+	runtimeFlags.AddFlagSet(runtimeIDFlags)
+
 	runtimeFlags.String(CfgTEEHardware, "invalid", "Type of TEE hardware.  Supported values are \"invalid\" and \"intel-sgx\"")
 	runtimeFlags.String(CfgGenesisState, "", "Runtime state at genesis")
 	runtimeFlags.Uint64(CfgGenesisRound, 0, "Runtime round at genesis")
@@ -521,6 +680,7 @@ func init() {
 	runtimeFlags.String(CfgKind, "compute", "Kind of runtime.  Supported values are \"compute\" and \"keymanager\"")
 	runtimeFlags.String(CfgVersion, "", "Runtime version. Value is 64-bit hex e.g. 0x0000000100020003 for 1.2.3")
 	runtimeFlags.StringSlice(CfgVersionEnclave, nil, "Runtime TEE enclave version(s)")
+	runtimeFlags.String(cfgVersionBundleHash, "", "Runtime bundle hash (hex) enabling runtime upgrades without a node restart")
 
 	// Init Executor committee flags.
 	runtimeFlags.Uint64(CfgExecutorGroupSize, 1, "Number of workers in the runtime executor group/committee")
@@ -528,6 +688,12 @@ func init() {
 	runtimeFlags.Uint64(CfgExecutorAllowedStragglers, 0, "Number of stragglers allowed per round in the runtime executor group")
 	runtimeFlags.Int64(CfgExecutorRoundTimeout, 5, "Executor committee round timeout for this runtime (in consensus blocks)")
 	runtimeFlags.Uint32(CfgExecutorMaxMessages, 32, "Maximum number of runtime messages that can be emitted in a round")
+	runtimeFlags.Uint64(CfgExecutorMaxLivenessFailures, 0, "Number of missed rounds per epoch after which an executor node is penalized (0 disables)")
+	runtimeFlags.String(CfgExecutorLivenessPenalty, "exclude", "Penalty for executor nodes exceeding the liveness failure limit (exclude, freeze)")
+	runtimeFlags.Uint32(CfgExecutorMaxInMessages, 0, "Maximum number of incoming runtime messages that can be delivered in a round (0 disables incoming messages)")
+	runtimeFlags.String(CfgExecutorMaxInMessageSize, "0", "Maximum size (in bytes) of an incoming runtime message payload")
+	runtimeFlags.Uint32(CfgExecutorInMessageQueueSize, 0, "Maximum number of queued incoming runtime messages")
+	runtimeFlags.String(CfgExecutorMinInMessageFee, "", "Minimum fee that must be paid for sending an incoming runtime message")
 
 	// Init Transaction scheduler flags.
 	runtimeFlags.String(CfgTxnSchedulerAlgorithm, registry.TxnSchedulerSimple, "Transaction scheduling algorithm")
@@ -535,6 +701,9 @@ func init() {
 	runtimeFlags.Uint64(CfgTxnSchedulerMaxBatchSize, 1000, "Maximum size of a batch of runtime requests")
 	runtimeFlags.String(CfgTxnSchedulerMaxBatchSizeBytes, "16mb", "Maximum size (in bytes) of a batch of runtime requests")
 	runtimeFlags.Int64(CfgTxnSchedulerProposerTimeout, 5, "Timeout (in consensus blocks) before a round can be timeouted due to proposer not proposing")
+	runtimeFlags.String(CfgTxnSchedulerProposerRotation, registry.ProposerRotationRoundRobin, "Transaction scheduler rotation strategy (round_robin, stake, random)")
+	runtimeFlags.Uint64(CfgTxnSchedulerBackupProposers, 0, "Number of backup proposers taking over in case the transaction scheduler does not propose")
+	runtimeFlags.Int64(CfgTxnSchedulerBackupProposerTimeout, 0, "Timeout (in consensus blocks) after which each successive backup proposer may propose")
 
 	// Init Storage committee flags.
 	runtimeFlags.Uint64(CfgStorageGroupSize, 1, "Number of storage nodes for the runtime")
@@ -551,6 +720,9 @@ func init() {
 
 	// Init Staking flags.
 	runtimeFlags.StringToString(CfgStakingThreshold, nil, "Additional staking threshold for this runtime (<kind>=<value>)")
+	runtimeFlags.StringToString(CfgStakingSlashingAmount, nil, "Slashing amount for misbehavior while serving this runtime (<reason>=<amount>)")
+	runtimeFlags.StringToString(CfgStakingSlashingFreezeInterval, nil, "Slashing freeze interval for misbehavior while serving this runtime (<reason>=<epochs>)")
+	runtimeFlags.String(CfgStakingRewardIncorrectResults, "", "Reward paid from the runtime's escrow for correct results in rounds with incorrect results")
 
 	_ = viper.BindPFlags(runtimeFlags)
 	runtimeFlags.AddFlagSet(cmdSigner.Flags)
@@ -560,6 +732,13 @@ func init() {
 	registerFlags.AddFlagSet(cmdConsensus.TxFlags)
 	registerFlags.AddFlagSet(cmdFlags.AssumeYesFlag)
 
+	lifecycleFlags.AddFlagSet(runtimeIDFlags)
+	lifecycleFlags.AddFlagSet(cmdSigner.Flags)
+	lifecycleFlags.AddFlagSet(cmdSigner.CLIFlags)
+	lifecycleFlags.AddFlagSet(cmdFlags.DebugTestEntityFlags)
+	lifecycleFlags.AddFlagSet(cmdConsensus.TxFlags)
+	lifecycleFlags.AddFlagSet(cmdFlags.AssumeYesFlag)
+
 	// List Runtimes flags.
 	runtimeListFlags.Bool(CfgIncludeSuspended, false, "Use to include suspended runtimes")
 	_ = viper.BindPFlags(runtimeListFlags)
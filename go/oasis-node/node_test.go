@@ -73,6 +73,7 @@ var (
 		{workerCommon.CfgClientPort, workerClientPort},
 		{storageWorker.CfgWorkerEnabled, true},
 		{executor.CfgScheduleCheckTxEnabled, false},
+		{executor.CfgForensicsEnabled, true},
 		{tendermintCommon.CfgCoreListenAddress, "tcp://0.0.0.0:27565"},
 		{tendermintFull.CfgSupplementarySanityEnabled, true},
 		{tendermintFull.CfgSupplementarySanityInterval, 1},
@@ -231,6 +232,10 @@ func TestNode(t *testing.T) {
 		// Runtime client tests also need a functional runtime.
 		{"RuntimeClient", testRuntimeClient},
 
+		// Executor forensics generates additional runtime blocks, so it runs
+		// after the runtime client tests which expect specific rounds.
+		{"ExecutorWorkerForensics", testExecutorWorkerForensics},
+
 		// Staking requires a registered node that is a validator.
 		{"Staking", testStaking},
 		{"StakingClient", testStakingClient},
@@ -441,6 +446,16 @@ func testExecutorWorker(t *testing.T, node *testNode) {
 	)
 }
 
+func testExecutorWorkerForensics(t *testing.T, node *testNode) {
+	executorWorkerTests.ForensicsTests(
+		t,
+		node.ExecutorWorker,
+		node.runtimeID,
+		node.executorCommitteeNode,
+		node.Consensus.RootHash(),
+	)
+}
+
 func testStorageWorker(t *testing.T, node *testNode) {
 	storageWorkerTests.WorkerImplementationTests(t, node.StorageWorker)
 }
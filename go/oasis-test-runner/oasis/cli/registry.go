@@ -59,6 +59,11 @@ func (r *RegistryHelpers) runRegistryRuntimeSubcommand(
 			"--"+cmdRegRt.CfgExecutorAllowedStragglers, strconv.FormatUint(runtime.Executor.AllowedStragglers, 10),
 			"--"+cmdRegRt.CfgExecutorRoundTimeout, strconv.FormatInt(runtime.Executor.RoundTimeout, 10),
 			"--"+cmdRegRt.CfgExecutorMaxMessages, strconv.FormatUint(uint64(runtime.Executor.MaxMessages), 10),
+			"--"+cmdRegRt.CfgExecutorMaxLivenessFailures, strconv.FormatUint(runtime.Executor.MaxLivenessFailures, 10),
+			"--"+cmdRegRt.CfgExecutorLivenessPenalty, runtime.Executor.LivenessPenalty.String(),
+			"--"+cmdRegRt.CfgExecutorMaxInMessages, strconv.FormatUint(uint64(runtime.Executor.MaxInMessages), 10),
+			"--"+cmdRegRt.CfgExecutorMaxInMessageSize, strconv.FormatUint(uint64(runtime.Executor.MaxInMessageSize), 10),
+			"--"+cmdRegRt.CfgExecutorInMessageQueueSize, strconv.FormatUint(uint64(runtime.Executor.InMessageQueueSize), 10),
 			"--"+cmdRegRt.CfgStorageGroupSize, strconv.FormatUint(runtime.Storage.GroupSize, 10),
 			"--"+cmdRegRt.CfgStorageMinWriteReplication, strconv.FormatUint(runtime.Storage.MinWriteReplication, 10),
 			"--"+cmdRegRt.CfgStorageMaxApplyWriteLogEntries, strconv.FormatUint(runtime.Storage.MaxApplyWriteLogEntries, 10),
@@ -71,6 +76,9 @@ func (r *RegistryHelpers) runRegistryRuntimeSubcommand(
 			"--"+cmdRegRt.CfgTxnSchedulerMaxBatchSize, strconv.FormatUint(runtime.TxnScheduler.MaxBatchSize, 10),
 			"--"+cmdRegRt.CfgTxnSchedulerMaxBatchSizeBytes, strconv.FormatUint(runtime.TxnScheduler.MaxBatchSizeBytes, 10),
 			"--"+cmdRegRt.CfgTxnSchedulerProposerTimeout, strconv.FormatInt(runtime.TxnScheduler.ProposerTimeout, 10),
+			"--"+cmdRegRt.CfgTxnSchedulerProposerRotation, runtime.TxnScheduler.ProposerRotation,
+			"--"+cmdRegRt.CfgTxnSchedulerBackupProposers, strconv.FormatUint(runtime.TxnScheduler.BackupProposers, 10),
+			"--"+cmdRegRt.CfgTxnSchedulerBackupProposerTimeout, strconv.FormatInt(runtime.TxnScheduler.BackupProposerTimeout, 10),
 		)
 	}
 	if runtime.KeyManager != nil {
@@ -103,6 +111,26 @@ func (r *RegistryHelpers) runRegistryRuntimeSubcommand(
 		)
 	}
 
+	for reason, slash := range runtime.Staking.Slashing {
+		reasonRaw, _ := reason.MarshalText()
+		amountRaw, _ := slash.Amount.MarshalText()
+
+		args = append(args,
+			"--"+cmdRegRt.CfgStakingSlashingAmount, fmt.Sprintf("%s=%s", string(reasonRaw), string(amountRaw)),
+			"--"+cmdRegRt.CfgStakingSlashingFreezeInterval, fmt.Sprintf("%s=%d", string(reasonRaw), slash.FreezeInterval),
+		)
+	}
+	if !runtime.Executor.MinInMessageFee.IsZero() {
+		args = append(args,
+			"--"+cmdRegRt.CfgExecutorMinInMessageFee, runtime.Executor.MinInMessageFee.String(),
+		)
+	}
+	if !runtime.Staking.RewardIncorrectResults.IsZero() {
+		args = append(args,
+			"--"+cmdRegRt.CfgStakingRewardIncorrectResults, runtime.Staking.RewardIncorrectResults.String(),
+		)
+	}
+
 	if out, err := r.runSubCommandWithOutput("registry-runtime-"+cmd, args); err != nil {
 		return fmt.Errorf("failed to run 'registry runtime %s': error: %w output: %s", cmd, err, out.String())
 	}
@@ -614,10 +614,12 @@ func (sc *registryCLIImpl) testRuntime(ctx context.Context, childEnv *env.Env, c
 		EntityID:  testEntity.ID,
 		Kind:      registry.KindCompute,
 		Executor: registry.ExecutorParameters{
-			GroupSize:         1,
-			GroupBackupSize:   2,
-			AllowedStragglers: 1,
-			RoundTimeout:      5,
+			GroupSize:           1,
+			GroupBackupSize:     2,
+			AllowedStragglers:   1,
+			RoundTimeout:        5,
+			MaxLivenessFailures: 3,
+			LivenessPenalty:     registry.LivenessPenaltyFreeze,
 		},
 		TxnScheduler: registry.TxnSchedulerParameters{
 			Algorithm:         registry.TxnSchedulerSimple,
@@ -644,6 +646,13 @@ func (sc *registryCLIImpl) testRuntime(ctx context.Context, childEnv *env.Env, c
 				staking.KindNodeCompute: q,
 				staking.KindNodeStorage: q,
 			},
+			Slashing: map[staking.SlashReason]staking.Slash{
+				staking.SlashRuntimeIncorrectResults: {
+					Amount:         q,
+					FreezeInterval: 2,
+				},
+			},
+			RewardIncorrectResults: q,
 		},
 	}
 	// Runtime ID 0x0 is for simple-keyvalue, 0xf... is for the keymanager. Let's use 0x1.
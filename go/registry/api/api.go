@@ -131,6 +131,12 @@ var (
 	// has runtimes.
 	ErrEntityHasRuntimes = errors.New(ModuleName, 19, "registry: entity still has runtimes")
 
+	// ErrRuntimeDecommissioned is the error returned when a runtime has been decommissioned.
+	ErrRuntimeDecommissioned = errors.New(ModuleName, 20, "registry: runtime is decommissioned")
+
+	// ErrRuntimeNotSuspended is the error returned when resuming a runtime that is not suspended.
+	ErrRuntimeNotSuspended = errors.New(ModuleName, 21, "registry: runtime is not suspended")
+
 	// MethodRegisterEntity is the method name for entity registrations.
 	MethodRegisterEntity = transaction.NewMethodName(ModuleName, "RegisterEntity", entity.SignedEntity{})
 	// MethodDeregisterEntity is the method name for entity deregistrations.
@@ -141,6 +147,12 @@ var (
 	MethodUnfreezeNode = transaction.NewMethodName(ModuleName, "UnfreezeNode", UnfreezeNode{})
 	// MethodRegisterRuntime is the method name for registering runtimes.
 	MethodRegisterRuntime = transaction.NewMethodName(ModuleName, "RegisterRuntime", SignedRuntime{})
+	// MethodPauseRuntime is the method name for pausing runtimes.
+	MethodPauseRuntime = transaction.NewMethodName(ModuleName, "PauseRuntime", PauseRuntime{})
+	// MethodResumeRuntime is the method name for resuming suspended runtimes.
+	MethodResumeRuntime = transaction.NewMethodName(ModuleName, "ResumeRuntime", ResumeRuntime{})
+	// MethodDecommissionRuntime is the method name for decommissioning runtimes.
+	MethodDecommissionRuntime = transaction.NewMethodName(ModuleName, "DecommissionRuntime", DecommissionRuntime{})
 
 	// Methods is the list of all methods supported by the registry backend.
 	Methods = []transaction.MethodName{
@@ -149,6 +161,9 @@ var (
 		MethodRegisterNode,
 		MethodUnfreezeNode,
 		MethodRegisterRuntime,
+		MethodPauseRuntime,
+		MethodResumeRuntime,
+		MethodDecommissionRuntime,
 	}
 
 	// RuntimesRequiredRoles are the Node roles that require runtimes.
@@ -217,6 +232,9 @@ type Backend interface {
 	// GetRuntime gets a runtime by ID.
 	GetRuntime(context.Context, *NamespaceQuery) (*Runtime, error)
 
+	// GetRuntimeStatus returns a runtime's lifecycle status.
+	GetRuntimeStatus(context.Context, *NamespaceQuery) (*RuntimeStatus, error)
+
 	// GetRuntimes returns the registered Runtimes at the specified
 	// block height.
 	GetRuntimes(context.Context, *GetRuntimesQuery) ([]*Runtime, error)
@@ -286,6 +304,21 @@ func NewRegisterRuntimeTx(nonce uint64, fee *transaction.Fee, sigRt *SignedRunti
 	return transaction.NewTransaction(nonce, fee, MethodRegisterRuntime, sigRt)
 }
 
+// NewPauseRuntimeTx creates a new pause runtime transaction.
+func NewPauseRuntimeTx(nonce uint64, fee *transaction.Fee, pause *PauseRuntime) *transaction.Transaction {
+	return transaction.NewTransaction(nonce, fee, MethodPauseRuntime, pause)
+}
+
+// NewResumeRuntimeTx creates a new resume runtime transaction.
+func NewResumeRuntimeTx(nonce uint64, fee *transaction.Fee, resume *ResumeRuntime) *transaction.Transaction {
+	return transaction.NewTransaction(nonce, fee, MethodResumeRuntime, resume)
+}
+
+// NewDecommissionRuntimeTx creates a new decommission runtime transaction.
+func NewDecommissionRuntimeTx(nonce uint64, fee *transaction.Fee, decommission *DecommissionRuntime) *transaction.Transaction {
+	return transaction.NewTransaction(nonce, fee, MethodDecommissionRuntime, decommission)
+}
+
 // EntityEvent is the event that is returned via WatchEntities to signify
 // entity registration changes and updates.
 type EntityEvent struct {
@@ -1193,6 +1226,9 @@ type Genesis struct {
 
 	// NodeStatuses is a set of node statuses.
 	NodeStatuses map[signature.PublicKey]*NodeStatus `json:"node_statuses,omitempty"`
+
+	// RuntimeStatuses is a set of runtime lifecycle statuses.
+	RuntimeStatuses map[common.Namespace]*RuntimeStatus `json:"runtime_statuses,omitempty"`
 }
 
 // ConsensusParameters are the registry consensus parameters.
@@ -1240,6 +1276,12 @@ const (
 	GasOpUnfreezeNode transaction.Op = "unfreeze_node"
 	// GasOpRegisterRuntime is the gas operation identifier for runtime registration.
 	GasOpRegisterRuntime transaction.Op = "register_runtime"
+	// GasOpPauseRuntime is the gas operation identifier for pausing runtimes.
+	GasOpPauseRuntime transaction.Op = "pause_runtime"
+	// GasOpResumeRuntime is the gas operation identifier for resuming runtimes.
+	GasOpResumeRuntime transaction.Op = "resume_runtime"
+	// GasOpDecommissionRuntime is the gas operation identifier for decommissioning runtimes.
+	GasOpDecommissionRuntime transaction.Op = "decommission_runtime"
 	// GasOpRuntimeEpochMaintenance is the gas operation identifier for per-epoch
 	// runtime maintenance costs.
 	GasOpRuntimeEpochMaintenance transaction.Op = "runtime_epoch_maintenance"
@@ -1257,6 +1299,9 @@ var DefaultGasCosts = transaction.Costs{
 	GasOpRegisterNode:            1000,
 	GasOpUnfreezeNode:            1000,
 	GasOpRegisterRuntime:         1000,
+	GasOpPauseRuntime:            1000,
+	GasOpResumeRuntime:           1000,
+	GasOpDecommissionRuntime:     1000,
 	GasOpRuntimeEpochMaintenance: 1000,
 	GasOpUpdateKeyManager:        1000,
 }
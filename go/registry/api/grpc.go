@@ -29,6 +29,8 @@ var (
 	methodGetNodes = serviceName.NewMethod("GetNodes", int64(0))
 	// methodGetRuntime is the GetRuntime method.
 	methodGetRuntime = serviceName.NewMethod("GetRuntime", NamespaceQuery{})
+	// methodGetRuntimeStatus is the GetRuntimeStatus method.
+	methodGetRuntimeStatus = serviceName.NewMethod("GetRuntimeStatus", NamespaceQuery{})
 	// methodGetRuntimes is the GetRuntimes method.
 	methodGetRuntimes = serviceName.NewMethod("GetRuntimes", int64(0))
 	// methodStateToGenesis is the StateToGenesis method.
@@ -78,6 +80,10 @@ var (
 				MethodName: methodGetRuntime.ShortName(),
 				Handler:    handlerGetRuntime,
 			},
+			{
+				MethodName: methodGetRuntimeStatus.ShortName(),
+				Handler:    handlerGetRuntimeStatus,
+			},
 			{
 				MethodName: methodGetRuntimes.ShortName(),
 				Handler:    handlerGetRuntimes,
@@ -277,6 +283,29 @@ func handlerGetRuntime( // nolint: golint
 	return interceptor(ctx, &query, info, handler)
 }
 
+func handlerGetRuntimeStatus( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var query NamespaceQuery
+	if err := dec(&query); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Backend).GetRuntimeStatus(ctx, &query)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetRuntimeStatus.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Backend).GetRuntimeStatus(ctx, req.(*NamespaceQuery))
+	}
+	return interceptor(ctx, &query, info, handler)
+}
+
 func handlerGetRuntimes( // nolint: golint
 	srv interface{},
 	ctx context.Context,
@@ -628,6 +657,14 @@ func (c *registryClient) GetRuntime(ctx context.Context, query *NamespaceQuery)
 	return &rsp, nil
 }
 
+func (c *registryClient) GetRuntimeStatus(ctx context.Context, query *NamespaceQuery) (*RuntimeStatus, error) {
+	var rsp RuntimeStatus
+	if err := c.conn.Invoke(ctx, methodGetRuntimeStatus.FullName(), query, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
 func (c *registryClient) GetRuntimes(ctx context.Context, query *GetRuntimesQuery) ([]*Runtime, error) {
 	var rsp []*Runtime
 	if err := c.conn.Invoke(ctx, methodGetRuntimes.FullName(), query, &rsp); err != nil {
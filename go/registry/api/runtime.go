@@ -27,6 +27,10 @@ var (
 	// kind is malformed or unknown.
 	ErrUnsupportedRuntimeKind = errors.New("runtime: unsupported runtime kind")
 
+	// ErrUnsupportedLivenessPenalty is the error returned when the parsed liveness
+	// penalty is malformed or unknown.
+	ErrUnsupportedLivenessPenalty = errors.New("runtime: unsupported liveness penalty")
+
 	_ prettyprint.PrettyPrinter = (*SignedRuntime)(nil)
 )
 
@@ -49,6 +53,30 @@ const (
 
 	// TxnSchedulerSimple is the name of the simple batching algorithm.
 	TxnSchedulerSimple = "simple"
+
+	// TxnSchedulerPriority is the name of the batching algorithm which orders transactions by
+	// the priority reported by the runtime when checking transactions.
+	TxnSchedulerPriority = "priority"
+
+	// TxnSchedulerParallel is the name of the batching algorithm which schedules transactions in
+	// FIFO order, the same as the simple algorithm. The only difference is that executors execute
+	// batches in non-conflicting sub-batches based on the read/write sets declared by the runtime.
+	TxnSchedulerParallel = "parallel"
+
+	// ProposerRotationRoundRobin is the name of the proposer rotation strategy which rotates the
+	// transaction scheduler among the executor committee workers in committee order.
+	ProposerRotationRoundRobin = "round_robin"
+
+	// ProposerRotationStake is the name of the proposer rotation strategy which rotates the
+	// transaction scheduler among the executor committee workers ordered by descending stake of
+	// their entities.
+	ProposerRotationStake = "stake"
+
+	// ProposerRotationRandom is the name of the proposer rotation strategy which selects the
+	// transaction scheduler for each round pseudo-randomly, based on a seed derived from the random
+	// beacon at the time of the committee election. Unlike a VRF-based selection, this requires no
+	// per-round proofs but makes the rotation public once the committee is elected.
+	ProposerRotationRandom = "random"
 )
 
 // String returns a string representation of a runtime kind.
@@ -79,6 +107,48 @@ func (k *RuntimeKind) FromString(str string) error {
 	return nil
 }
 
+// LivenessPenalty is the penalty applied to executor nodes that fail to commit in too many rounds.
+type LivenessPenalty uint8
+
+const (
+	// LivenessPenaltyExclude excludes the node from the next executor committee election for the
+	// runtime.
+	LivenessPenaltyExclude LivenessPenalty = 0
+
+	// LivenessPenaltyFreeze freezes the node, preventing it from being scheduled until it is
+	// explicitly unfrozen.
+	LivenessPenaltyFreeze LivenessPenalty = 1
+
+	livenessPenaltyExclude = "exclude"
+	livenessPenaltyFreeze  = "freeze"
+)
+
+// String returns a string representation of a liveness penalty.
+func (p LivenessPenalty) String() string {
+	switch p {
+	case LivenessPenaltyExclude:
+		return livenessPenaltyExclude
+	case LivenessPenaltyFreeze:
+		return livenessPenaltyFreeze
+	default:
+		return "[unsupported liveness penalty]"
+	}
+}
+
+// FromString deserializes a string into a LivenessPenalty.
+func (p *LivenessPenalty) FromString(str string) error {
+	switch strings.ToLower(str) {
+	case livenessPenaltyExclude:
+		*p = LivenessPenaltyExclude
+	case livenessPenaltyFreeze:
+		*p = LivenessPenaltyFreeze
+	default:
+		return ErrUnsupportedLivenessPenalty
+	}
+
+	return nil
+}
+
 // ExecutorParameters are parameters for the executor committee.
 type ExecutorParameters struct {
 	// GroupSize is the size of the committee.
@@ -96,6 +166,29 @@ type ExecutorParameters struct {
 	// MaxMessages is the maximum number of messages that can be emitted by the runtime in a
 	// single round.
 	MaxMessages uint32 `json:"max_messages"`
+
+	// MaxLivenessFailures is the number of rounds within an epoch in which a node may fail to
+	// commit (or fail to propose as the transaction scheduler) before it is penalized. Zero
+	// disables liveness penalties.
+	MaxLivenessFailures uint64 `json:"max_liveness_failures,omitempty"`
+
+	// LivenessPenalty is the penalty applied to nodes exceeding MaxLivenessFailures.
+	LivenessPenalty LivenessPenalty `json:"liveness_penalty,omitempty"`
+
+	// MaxInMessages is the maximum number of incoming messages from other runtimes that can be
+	// delivered to the runtime in a single round. Zero means that the runtime does not accept
+	// incoming messages.
+	MaxInMessages uint32 `json:"max_in_messages,omitempty"`
+
+	// MaxInMessageSize is the maximum size (in bytes) of the payload of an incoming message.
+	MaxInMessageSize uint32 `json:"max_in_message_size,omitempty"`
+
+	// InMessageQueueSize is the maximum number of incoming messages that can be queued for
+	// delivery to the runtime.
+	InMessageQueueSize uint32 `json:"in_message_queue_size,omitempty"`
+
+	// MinInMessageFee is the minimum fee that must be paid by the sender of an incoming message.
+	MinInMessageFee quantity.Quantity `json:"min_in_message_fee,omitempty"`
 }
 
 // ValidateBasic performs basic executor parameter validity checks.
@@ -110,6 +203,19 @@ func (e *ExecutorParameters) ValidateBasic() error {
 	if e.RoundTimeout < 5 {
 		return fmt.Errorf("round timeout too small")
 	}
+	switch e.LivenessPenalty {
+	case LivenessPenaltyExclude, LivenessPenaltyFreeze:
+	default:
+		return fmt.Errorf("unsupported liveness penalty: %d", e.LivenessPenalty)
+	}
+	if e.MaxInMessages > 0 {
+		if e.MaxInMessageSize == 0 {
+			return fmt.Errorf("max incoming message size too small")
+		}
+		if e.InMessageQueueSize < e.MaxInMessages {
+			return fmt.Errorf("incoming message queue size too small")
+		}
+	}
 	return nil
 }
 
@@ -118,8 +224,7 @@ type TxnSchedulerParameters struct {
 	// Algorithm is the transaction scheduling algorithm.
 	Algorithm string `json:"algorithm"`
 
-	// BatchFlushTimeout denotes, if using the "simple" algorithm, how long to
-	// wait for a scheduled batch.
+	// BatchFlushTimeout denotes how long to wait for a scheduled batch.
 	BatchFlushTimeout time.Duration `json:"batch_flush_timeout"`
 
 	// MaxBatchSize denotes what is the max size of a scheduled batch.
@@ -131,12 +236,26 @@ type TxnSchedulerParameters struct {
 	// ProposerTimeout denotes the timeout (in consensus blocks) for scheduler
 	// to propose a batch.
 	ProposerTimeout int64 `json:"propose_batch_timeout"`
+
+	// ProposerRotation is the transaction scheduler (proposer) rotation strategy. An empty value
+	// means round robin.
+	ProposerRotation string `json:"proposer_rotation,omitempty"`
+
+	// BackupProposers is the number of backup proposers that take over in order in case the
+	// transaction scheduler fails to propose a batch in time.
+	BackupProposers uint64 `json:"backup_proposers,omitempty"`
+
+	// BackupProposerTimeout is the timeout (in consensus blocks) after which each successive
+	// backup proposer is allowed to propose a batch.
+	BackupProposerTimeout int64 `json:"backup_proposer_timeout,omitempty"`
 }
 
 // ValidateBasic performs basic transaction scheduler parameter validity checks.
 func (t *TxnSchedulerParameters) ValidateBasic() error {
 	// Ensure txnscheduler parameters have sensible values.
-	if t.Algorithm != TxnSchedulerSimple {
+	switch t.Algorithm {
+	case TxnSchedulerSimple, TxnSchedulerPriority, TxnSchedulerParallel:
+	default:
 		return fmt.Errorf("invalid transaction scheduler algorithm")
 	}
 	if t.BatchFlushTimeout < 50*time.Millisecond {
@@ -151,6 +270,20 @@ func (t *TxnSchedulerParameters) ValidateBasic() error {
 	if t.ProposerTimeout < 5 {
 		return fmt.Errorf("transaction scheduler proposer timeout parameter too small")
 	}
+	switch t.ProposerRotation {
+	case "", ProposerRotationRoundRobin, ProposerRotationStake, ProposerRotationRandom:
+	default:
+		return fmt.Errorf("invalid transaction scheduler proposer rotation")
+	}
+	if t.BackupProposers > 0 {
+		if t.BackupProposerTimeout < 1 {
+			return fmt.Errorf("transaction scheduler backup proposer timeout parameter too small")
+		}
+		// All backup proposers should get a chance before the round can be timed out.
+		if t.BackupProposers > uint64((t.ProposerTimeout-1)/t.BackupProposerTimeout) {
+			return fmt.Errorf("transaction scheduler backup proposer timeout parameter too large")
+		}
+	}
 
 	return nil
 }
@@ -239,6 +372,17 @@ type RuntimeStakingParameters struct {
 	// In case a node is registered for multiple runtimes, it will need to satisfy the maximum
 	// threshold of all the runtimes.
 	Thresholds map[staking.ThresholdKind]quantity.Quantity `json:"thresholds,omitempty"`
+
+	// Slashing are the per-runtime misbehavior slashing parameters. May be left unspecified in
+	// which case nodes are not slashed for misbehaving while serving the runtime.
+	//
+	// Currently only staking.SlashRuntimeIncorrectResults is supported.
+	Slashing map[staking.SlashReason]staking.Slash `json:"slashing,omitempty"`
+
+	// RewardIncorrectResults is the amount transferred from the runtime's escrow account to the
+	// escrow account of each entity whose executor node committed results agreeing with the
+	// discrepancy resolution majority in a round where a node was slashed for incorrect results.
+	RewardIncorrectResults quantity.Quantity `json:"reward_incorrect_results,omitempty"`
 }
 
 // ValidateBasic performs basic descriptor validity checks.
@@ -261,6 +405,31 @@ func (s *RuntimeStakingParameters) ValidateBasic(runtimeKind RuntimeKind) error
 			return fmt.Errorf("invalid threshold of kind %s specified", kind)
 		}
 	}
+
+	for reason, slash := range s.Slashing {
+		switch reason {
+		case staking.SlashRuntimeIncorrectResults:
+			if runtimeKind != KindCompute {
+				return fmt.Errorf("unsupported slash reason for runtime: %s", reason)
+			}
+		default:
+			return fmt.Errorf("unsupported slash reason for runtime: %s", reason)
+		}
+
+		if !slash.Amount.IsValid() {
+			return fmt.Errorf("invalid slash amount for reason %s specified", reason)
+		}
+	}
+
+	if !s.RewardIncorrectResults.IsValid() {
+		return fmt.Errorf("invalid incorrect results reward specified")
+	}
+	if !s.RewardIncorrectResults.IsZero() && runtimeKind != KindCompute {
+		return fmt.Errorf("incorrect results reward is only supported for compute runtimes")
+	}
+	if _, ok := s.Slashing[staking.SlashRuntimeIncorrectResults]; !s.RewardIncorrectResults.IsZero() && !ok {
+		return fmt.Errorf("incorrect results reward requires slashing for incorrect results")
+	}
 	return nil
 }
 
@@ -356,6 +525,9 @@ func (r *Runtime) ValidateBasic(strictVersion bool) error {
 		if err := r.TxnScheduler.ValidateBasic(); err != nil {
 			return fmt.Errorf("bad txn scheduler parameters: %w", err)
 		}
+		if r.TxnScheduler.BackupProposers >= r.Executor.GroupSize {
+			return fmt.Errorf("bad txn scheduler parameters: too many backup proposers")
+		}
 		if err := r.Storage.ValidateBasic(); err != nil {
 			return fmt.Errorf("bad storage parameters: %w", err)
 		}
@@ -444,6 +616,10 @@ type VersionInfo struct {
 	// TEE is the enclave version information, in an enclave provider specific
 	// format if any.
 	TEE []byte `json:"tee,omitempty"`
+
+	// BundleHash is the optional hash of the runtime bundle for this version.
+	// Nodes only perform runtime upgrades without a restart in case it is set.
+	BundleHash *hash.Hash `json:"bundle_hash,omitempty"`
 }
 
 // VersionInfoIntelSGX is the SGX TEE version information.
@@ -46,6 +46,19 @@ func (g *Genesis) SanityCheck(
 		return err
 	}
 
+	// Check runtime statuses.
+	for id, status := range g.RuntimeStatuses {
+		if status == nil {
+			return fmt.Errorf("registry: sanity check failed: runtime status for '%s' is nil", id)
+		}
+		if !status.Paused && !status.Decommissioned {
+			continue
+		}
+		if _, err = runtimesLookup.SuspendedRuntime(context.Background(), id); err != nil {
+			return fmt.Errorf("registry: sanity check failed: paused or decommissioned runtime '%s' is not suspended", id)
+		}
+	}
+
 	// Check nodes.
 	nodeLookup, err := SanityCheckNodes(logger, &g.Parameters, g.Nodes, seenEntities, runtimesLookup, true, baseEpoch)
 	if err != nil {
@@ -64,10 +77,16 @@ func (g *Genesis) SanityCheck(
 	if err != nil {
 		return fmt.Errorf("registry: sanity check failed: could not obtain all runtimes from runtimesLookup: %w", err)
 	}
+	var stakedRuntimes []*Runtime
 	for _, rt := range runtimes {
 		if publicKeyBlacklist[rt.EntityID] {
 			return fmt.Errorf("registry: sanity check failed: runtime '%s' owned by blacklisted entity: '%s'", rt.ID, rt.EntityID)
 		}
+		// Decommissioned runtimes do not require any stake.
+		if status := g.RuntimeStatuses[rt.ID]; status != nil && status.Decommissioned {
+			continue
+		}
+		stakedRuntimes = append(stakedRuntimes, rt)
 	}
 	for k := range publicKeyBlacklist {
 		if node, _ := nodeLookup.NodeBySubKey(context.Background(), k); node != nil {
@@ -81,7 +100,7 @@ func (g *Genesis) SanityCheck(
 			return fmt.Errorf("registry: sanity check failed: could not obtain node list from nodeLookup: %w", err)
 		}
 		// Check stake.
-		return SanityCheckStake(entities, stakeLedger, nodes, runtimes, stakeThresholds, true)
+		return SanityCheckStake(entities, stakeLedger, nodes, stakedRuntimes, stakeThresholds, true)
 	}
 
 	return nil
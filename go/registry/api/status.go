@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	epochtime "github.com/oasisprotocol/oasis-core/go/epochtime/api"
 )
@@ -22,6 +23,9 @@ type NodeStatus struct {
 	// After the specified epoch passes, this flag needs to be explicitly
 	// cleared (set to zero) in order for the node to become unfrozen.
 	FreezeEndTime epochtime.EpochTime `json:"freeze_end_time"`
+	// ExcludedRuntimes maps runtimes to the epoch until which the node is excluded from the
+	// runtime's executor committee elections.
+	ExcludedRuntimes map[common.Namespace]epochtime.EpochTime `json:"excluded_runtimes,omitempty"`
 }
 
 // IsFrozen returns true if the node is currently frozen (prevented
@@ -30,6 +34,29 @@ func (ns NodeStatus) IsFrozen() bool {
 	return ns.FreezeEndTime > 0
 }
 
+// IsExcluded returns true if the node is excluded from executor committee
+// elections for the given runtime at the given epoch.
+func (ns NodeStatus) IsExcluded(runtimeID common.Namespace, epoch epochtime.EpochTime) bool {
+	return epoch < ns.ExcludedRuntimes[runtimeID]
+}
+
+// PruneExcludedRuntimes removes exclusions from executor committee
+// elections that have ended by the given epoch and returns true iff any
+// exclusions were removed.
+func (ns *NodeStatus) PruneExcludedRuntimes(epoch epochtime.EpochTime) bool {
+	var pruned bool
+	for runtimeID, until := range ns.ExcludedRuntimes {
+		if epoch >= until {
+			delete(ns.ExcludedRuntimes, runtimeID)
+			pruned = true
+		}
+	}
+	if len(ns.ExcludedRuntimes) == 0 {
+		ns.ExcludedRuntimes = nil
+	}
+	return pruned
+}
+
 // Unfreeze makes the node unfrozen.
 func (ns *NodeStatus) Unfreeze() {
 	ns.FreezeEndTime = 0
@@ -39,3 +66,33 @@ func (ns *NodeStatus) Unfreeze() {
 type UnfreezeNode struct {
 	NodeID signature.PublicKey `json:"node_id"`
 }
+
+// RuntimeStatus is the lifecycle status of a runtime.
+type RuntimeStatus struct {
+	// Paused is a flag specifying whether the runtime has been explicitly paused by its owner.
+	//
+	// A paused runtime is suspended and is not automatically resumed when nodes pay maintenance
+	// fees for it. It needs to be explicitly resumed by its owner.
+	Paused bool `json:"paused,omitempty"`
+	// Decommissioned is a flag specifying whether the runtime has been permanently
+	// decommissioned by its owner.
+	//
+	// A decommissioned runtime is suspended forever, its state root is frozen and its descriptor
+	// can no longer be updated.
+	Decommissioned bool `json:"decommissioned,omitempty"`
+}
+
+// PauseRuntime is a request to suspend a runtime until it is explicitly resumed.
+type PauseRuntime struct {
+	RuntimeID common.Namespace `json:"runtime_id"`
+}
+
+// ResumeRuntime is a request to resume a suspended runtime.
+type ResumeRuntime struct {
+	RuntimeID common.Namespace `json:"runtime_id"`
+}
+
+// DecommissionRuntime is a request to permanently decommission a runtime.
+type DecommissionRuntime struct {
+	RuntimeID common.Namespace `json:"runtime_id"`
+}
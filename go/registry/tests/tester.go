@@ -636,6 +636,59 @@ func testRegistryRuntime(t *testing.T, backend api.Backend, consensus consensusA
 			false,
 			false,
 		},
+		// Runtime using custom slashing parameters.
+		{
+			"StakingSlashing",
+			func(rt *api.Runtime) {
+				var q quantity.Quantity
+				_ = q.FromUint64(1000)
+
+				rt.Staking = api.RuntimeStakingParameters{
+					Slashing: map[staking.SlashReason]staking.Slash{
+						staking.SlashRuntimeIncorrectResults: {Amount: q},
+					},
+					RewardIncorrectResults: q,
+				}
+			},
+			false,
+			true,
+		},
+		// Runtime using invalid custom slashing parameters.
+		{
+			"StakingSlashingInvalid",
+			func(rt *api.Runtime) {
+				var q quantity.Quantity
+				_ = q.FromUint64(1000)
+
+				rt.Staking = api.RuntimeStakingParameters{
+					Slashing: map[staking.SlashReason]staking.Slash{
+						staking.SlashDoubleSigning: {Amount: q},
+					},
+				}
+			},
+			false,
+			false,
+		},
+		// Runtime with liveness penalties.
+		{
+			"LivenessPenalty",
+			func(rt *api.Runtime) {
+				rt.Executor.MaxLivenessFailures = 2
+				rt.Executor.LivenessPenalty = api.LivenessPenaltyFreeze
+			},
+			false,
+			true,
+		},
+		// Runtime with an invalid liveness penalty.
+		{
+			"LivenessPenaltyInvalid",
+			func(rt *api.Runtime) {
+				rt.Executor.MaxLivenessFailures = 2
+				rt.Executor.LivenessPenalty = 42
+			},
+			false,
+			false,
+		},
 		// Hardware Invalid Key manager runtime.
 		{
 			"HardwareInvalidKeyManager",
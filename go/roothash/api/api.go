@@ -8,14 +8,18 @@ import (
 
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/errors"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
 	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+	epochtime "github.com/oasisprotocol/oasis-core/go/epochtime/api"
 	"github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
 	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/message"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
 )
 
 const (
@@ -25,6 +29,12 @@ const (
 	// LogEventExecutionDiscrepancyDetected is a log event value that signals
 	// an execution discrepancy has been detected.
 	LogEventExecutionDiscrepancyDetected = "roothash/execution_discrepancy_detected"
+	// LogEventExecutorMisbehavior is a log event value that signals an executor node has
+	// committed results disagreeing with the discrepancy resolution majority.
+	LogEventExecutorMisbehavior = "roothash/executor_misbehavior"
+	// LogEventExecutorLivenessPenalty is a log event value that signals an executor node has been
+	// penalized for failing to commit in too many rounds.
+	LogEventExecutorLivenessPenalty = "roothash/executor_liveness_penalty"
 	// LogEventTimerFired is a log event value that signals a timer has fired.
 	LogEventTimerFired = "roothash/timer_fired"
 	// LogEventRoundFailed is a log event value that signals a round has failed.
@@ -34,6 +44,10 @@ const (
 	// LogEventHistoryReindexing is a log event value that signals a roothash runtime reindexing
 	// was run.
 	LogEventHistoryReindexing = "roothash/history_reindexing"
+
+	// MaxRoundsInRangeHeights is the maximum number of consensus heights that can be queried in a
+	// single GetRoundsInRange request.
+	MaxRoundsInRangeHeights = 10000
 )
 
 var (
@@ -59,6 +73,33 @@ var (
 	// larger than the MaxRuntimeMessages specified in consensus parameters.
 	ErrMaxMessagesTooBig = errors.New(ModuleName, 7, "roothash: max runtime messages is too big")
 
+	// ErrHeightRangeTooLarge is the error returned when the requested consensus height range is
+	// larger than MaxRoundsInRangeHeights.
+	ErrHeightRangeTooLarge = errors.New(ModuleName, 8, "roothash: height range too large")
+
+	// ErrRuntimeDecommissioned is the error returned when the passed runtime is decommissioned.
+	ErrRuntimeDecommissioned = errors.New(ModuleName, 9, "roothash: runtime is decommissioned")
+
+	// ErrInMessagesNotAccepted is the error returned when the destination runtime of a runtime
+	// message does not accept incoming messages.
+	ErrInMessagesNotAccepted = errors.New(ModuleName, 10, "roothash: runtime does not accept incoming messages")
+
+	// ErrInMessageTooBig is the error returned when the payload of a runtime message is larger
+	// than allowed by the destination runtime.
+	ErrInMessageTooBig = errors.New(ModuleName, 11, "roothash: incoming message too big")
+
+	// ErrInMessageFeeTooLow is the error returned when the fee paid for a runtime message is lower
+	// than required by the destination runtime.
+	ErrInMessageFeeTooLow = errors.New(ModuleName, 12, "roothash: incoming message fee too low")
+
+	// ErrInMessageQueueFull is the error returned when the incoming message queue of the
+	// destination runtime is full.
+	ErrInMessageQueueFull = errors.New(ModuleName, 13, "roothash: incoming message queue is full")
+
+	// ErrRoundSuperseded is the error returned when a block is not available because it was
+	// superseded by another block within the same consensus block.
+	ErrRoundSuperseded = errors.New(ModuleName, 14, "roothash: round superseded within the same consensus block")
+
 	// MethodExecutorCommit is the method name for executor commit submission.
 	MethodExecutorCommit = transaction.NewMethodName(ModuleName, "ExecutorCommit", ExecutorCommit{})
 
@@ -72,8 +113,8 @@ var (
 	}
 )
 
-// Backend is a root hash implementation.
-type Backend interface {
+// ClientBackend is a limited root hash backend interface, exposed to remote clients over gRPC.
+type ClientBackend interface {
 	// GetGenesisBlock returns the genesis block.
 	GetGenesisBlock(ctx context.Context, runtimeID common.Namespace, height int64) (*block.Block, error)
 
@@ -83,9 +124,62 @@ type Backend interface {
 	// the latest state from the storage backend.
 	GetLatestBlock(ctx context.Context, runtimeID common.Namespace, height int64) (*block.Block, error)
 
+	// GetBlockByRound returns the block finalized in the given round together with the consensus
+	// height at which it was finalized.
+	//
+	// The block is looked up in the consensus state so it is only available in case the consensus
+	// state at the height at which it was finalized has not been pruned. Blocks that were
+	// superseded by another block within the same consensus block are not available and
+	// ErrRoundSuperseded is returned instead.
+	GetBlockByRound(ctx context.Context, runtimeID common.Namespace, round uint64) (*AnnotatedBlock, error)
+
+	// GetRoundsInRange returns the blocks finalized in the given (inclusive) range of consensus
+	// heights, ordered by round.
+	//
+	// The same limitations as for GetBlockByRound apply. Rounds are never skipped, so in case any
+	// block in the range was superseded within the same consensus block, ErrRoundSuperseded is
+	// returned.
+	GetRoundsInRange(ctx context.Context, runtimeID common.Namespace, startHeight, endHeight int64) ([]*AnnotatedBlock, error)
+
 	// GetRuntimeState returns the given runtime's state.
 	GetRuntimeState(ctx context.Context, runtimeID common.Namespace, height int64) (*RuntimeState, error)
 
+	// GetRuntimeStateProof returns a Merkle proof of the given runtime's state against the
+	// consensus state root at the given height.
+	//
+	// The consensus state root is committed to by the app hash of the consensus block at the
+	// next height, so the proof can be verified using a verified consensus light block.
+	GetRuntimeStateProof(ctx context.Context, runtimeID common.Namespace, height int64) (*RuntimeStateProof, error)
+
+	// GetLivenessStatistics returns the liveness statistics of the given runtime's executor
+	// committee members for the current epoch.
+	GetLivenessStatistics(ctx context.Context, runtimeID common.Namespace, height int64) (*LivenessStatistics, error)
+
+	// GetIncomingMessageQueueMeta returns the given runtime's incoming message queue metadata.
+	GetIncomingMessageQueueMeta(ctx context.Context, runtimeID common.Namespace, height int64) (*IncomingMessageQueueMeta, error)
+
+	// GetIncomingMessageQueue returns the given runtime's queued incoming messages in delivery
+	// order. In case the limit is non-zero, at most that many messages are returned.
+	GetIncomingMessageQueue(ctx context.Context, request *InMessageQueueRequest) ([]*message.IncomingMessage, error)
+
+	// WatchRuntimeEvents returns a stream of protocol events for the given runtime, including
+	// block finalization events.
+	//
+	// All events emitted since the given consensus height are replayed first, followed by events
+	// as they are emitted. In case the height is HeightLatest, only new events are streamed.
+	WatchRuntimeEvents(ctx context.Context, runtimeID common.Namespace, fromHeight int64) (<-chan *Event, pubsub.ClosableSubscription, error)
+
+	// StateToGenesis returns the genesis state at specified block height.
+	StateToGenesis(ctx context.Context, height int64) (*Genesis, error)
+
+	// GetEvents returns the events at specified block height.
+	GetEvents(ctx context.Context, height int64) ([]*Event, error)
+}
+
+// Backend is a root hash implementation.
+type Backend interface {
+	ClientBackend
+
 	// WatchBlocks returns a channel that produces a stream of
 	// annotated blocks.
 	//
@@ -100,16 +194,42 @@ type Backend interface {
 	// TrackRuntime adds a runtime the history of which should be tracked.
 	TrackRuntime(ctx context.Context, history BlockHistory) error
 
-	// StateToGenesis returns the genesis state at specified block height.
-	StateToGenesis(ctx context.Context, height int64) (*Genesis, error)
-
-	// GetEvents returns the events at specified block height.
-	GetEvents(ctx context.Context, height int64) ([]*Event, error)
-
 	// Cleanup cleans up the roothash backend.
 	Cleanup()
 }
 
+// RuntimeRequest is a request for a runtime's information at a given consensus height.
+type RuntimeRequest struct {
+	RuntimeID common.Namespace `json:"runtime_id"`
+	Height    int64            `json:"height"`
+}
+
+// BlockByRoundRequest is a GetBlockByRound request.
+type BlockByRoundRequest struct {
+	RuntimeID common.Namespace `json:"runtime_id"`
+	Round     uint64           `json:"round"`
+}
+
+// RoundsInRangeRequest is a GetRoundsInRange request.
+type RoundsInRangeRequest struct {
+	RuntimeID   common.Namespace `json:"runtime_id"`
+	StartHeight int64            `json:"start_height"`
+	EndHeight   int64            `json:"end_height"`
+}
+
+// InMessageQueueRequest is a GetIncomingMessageQueue request.
+type InMessageQueueRequest struct {
+	RuntimeID common.Namespace `json:"runtime_id"`
+	Height    int64            `json:"height"`
+	Limit     uint32           `json:"limit,omitempty"`
+}
+
+// WatchRuntimeEventsRequest is a WatchRuntimeEvents request.
+type WatchRuntimeEventsRequest struct {
+	RuntimeID  common.Namespace `json:"runtime_id"`
+	FromHeight int64            `json:"from_height"`
+}
+
 // ExecutorCommit is the argument set for the ExecutorCommit method.
 type ExecutorCommit struct {
 	ID      common.Namespace                `json:"id"`
@@ -143,6 +263,14 @@ type RuntimeState struct {
 	Runtime   *registry.Runtime `json:"runtime"`
 	Suspended bool              `json:"suspended,omitempty"`
 
+	// Decommissioned is true iff the runtime has been permanently decommissioned. The state root
+	// of a decommissioned runtime is frozen at its last block.
+	Decommissioned bool `json:"decommissioned,omitempty"`
+
+	// SuspensionDeadline is the epoch at which the runtime will be suspended unless its owner
+	// tops up its stake. Zero means that no suspension is pending.
+	SuspensionDeadline epochtime.EpochTime `json:"suspension_deadline,omitempty"`
+
 	GenesisBlock *block.Block `json:"genesis_block"`
 
 	CurrentBlock       *block.Block `json:"current_block"`
@@ -155,6 +283,33 @@ type RuntimeState struct {
 	LastNormalHeight int64 `json:"last_normal_height"`
 
 	ExecutorPool *commitment.Pool `json:"executor_pool"`
+
+	// LivenessStatistics are the liveness statistics of the current executor committee members.
+	LivenessStatistics *LivenessStatistics `json:"liveness_stats,omitempty"`
+}
+
+// IncomingMessageQueueMeta is the incoming message queue metadata of a runtime.
+type IncomingMessageQueueMeta struct {
+	// Size is the number of messages currently in the queue.
+	Size uint32 `json:"size,omitempty"`
+
+	// NextSequenceNumber is the sequence number that will be assigned to the next queued message.
+	NextSequenceNumber uint64 `json:"next_sequence_number,omitempty"`
+}
+
+// FirstSequenceNumber returns the sequence number of the first message in the queue.
+func (m *IncomingMessageQueueMeta) FirstSequenceNumber() uint64 {
+	return m.NextSequenceNumber - uint64(m.Size)
+}
+
+// RuntimeStateProof is a Merkle proof of a runtime's state in the consensus layer state.
+type RuntimeStateProof struct {
+	// Height is the consensus height of the state the proof is for.
+	Height int64 `json:"height"`
+
+	// Proof is the Merkle proof of the runtime state entry against the consensus state root at
+	// the given height.
+	Proof syncer.Proof `json:"proof"`
 }
 
 // AnnotatedBlock is an annotated roothash block.
@@ -179,6 +334,22 @@ type ExecutionDiscrepancyDetectedEvent struct {
 	Timeout bool `json:"timeout"`
 }
 
+// ExecutorMisbehaviorEvent is an executor misbehavior event, emitted for each executor node that
+// committed results disagreeing with the discrepancy resolution majority.
+type ExecutorMisbehaviorEvent struct {
+	// Round is the round in which the misbehavior was detected.
+	Round uint64 `json:"round"`
+	// NodeID is the identifier of the misbehaving node.
+	NodeID signature.PublicKey `json:"node_id"`
+}
+
+// SuspensionNoticeEvent is a suspension notice event, emitted when a runtime's owner no longer
+// has enough stake and the runtime will be suspended after the grace period.
+type SuspensionNoticeEvent struct {
+	// Deadline is the epoch at which the runtime will be suspended.
+	Deadline epochtime.EpochTime `json:"deadline"`
+}
+
 // FinalizedEvent is a finalized event.
 type FinalizedEvent struct {
 	Round uint64 `json:"round"`
@@ -196,6 +367,22 @@ func (me *MessageEvent) IsSuccess() bool {
 	return me.Code == errors.CodeNoError
 }
 
+// InMessageDeliveredEvent is a delivery receipt for a runtime message, emitted for the sending
+// runtime once the message has been delivered to the destination runtime in a finalized round.
+type InMessageDeliveredEvent struct {
+	// To is the identifier of the destination runtime.
+	To common.Namespace `json:"to"`
+	// ID is the sequence number of the message in the destination runtime's queue.
+	ID uint64 `json:"id"`
+	// Round is the destination runtime round in which the message was delivered.
+	Round uint64 `json:"round"`
+
+	// SourceRound is the round of the sending runtime in which the message was emitted.
+	SourceRound uint64 `json:"source_round"`
+	// SourceIndex is the index of the message among the messages emitted in SourceRound.
+	SourceIndex uint32 `json:"source_index"`
+}
+
 // Event is a roothash event.
 type Event struct {
 	Height int64     `json:"height,omitempty"`
@@ -205,8 +392,11 @@ type Event struct {
 
 	ExecutorCommitted            *ExecutorCommittedEvent            `json:"executor_committed,omitempty"`
 	ExecutionDiscrepancyDetected *ExecutionDiscrepancyDetectedEvent `json:"execution_discrepancy,omitempty"`
+	ExecutorMisbehavior          *ExecutorMisbehaviorEvent          `json:"executor_misbehavior,omitempty"`
+	SuspensionNotice             *SuspensionNoticeEvent             `json:"suspension_notice,omitempty"`
 	Finalized                    *FinalizedEvent                    `json:"finalized,omitempty"`
 	Message                      *MessageEvent                      `json:"message,omitempty"`
+	InMessageDelivered           *InMessageDeliveredEvent           `json:"in_message_delivered,omitempty"`
 }
 
 // MetricsMonitorable is the interface exposed by backends capable of
@@ -225,6 +415,9 @@ type GenesisRuntimeState struct {
 
 	// MessageResults are the message results emitted at the last processed round.
 	MessageResults []*MessageEvent `json:"message_results,omitempty"`
+
+	// IncomingMessages are the incoming messages queued for delivery to the runtime.
+	IncomingMessages []*message.IncomingMessage `json:"incoming_messages,omitempty"`
 }
 
 // Genesis is the roothash genesis state.
@@ -252,6 +445,10 @@ type ConsensusParameters struct {
 	// MaxRuntimeMessages is the maximum number of allowed messages that can be emitted by a runtime
 	// in a single round.
 	MaxRuntimeMessages uint32 `json:"max_runtime_messages"`
+
+	// SuspensionGracePeriod is the number of epochs a runtime is allowed to keep operating after
+	// its owner no longer has enough stake, before being suspended.
+	SuspensionGracePeriod epochtime.EpochTime `json:"suspension_grace_period,omitempty"`
 }
 
 const (
@@ -294,6 +491,13 @@ func (g *Genesis) SanityCheck() error {
 		if err := rtg.SanityCheck(true); err != nil {
 			return err
 		}
+
+		// Check that queued incoming messages have consecutive sequence numbers.
+		for i, msg := range rtg.IncomingMessages {
+			if i > 0 && msg.ID != rtg.IncomingMessages[i-1].ID+1 {
+				return fmt.Errorf("roothash: sanity check failed: incoming message sequence numbers are not consecutive")
+			}
+		}
 	}
 	return nil
 }
@@ -304,5 +508,8 @@ func VerifyRuntimeParameters(logger *logging.Logger, rt *registry.Runtime, param
 	if rt.Executor.MaxMessages > params.MaxRuntimeMessages {
 		return ErrMaxMessagesTooBig
 	}
+	if rt.Executor.MaxInMessages > params.MaxRuntimeMessages {
+		return ErrMaxMessagesTooBig
+	}
 	return nil
 }
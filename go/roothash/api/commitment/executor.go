@@ -42,6 +42,11 @@ type ComputeResultsHeader struct {
 	IORoot       *hash.Hash `json:"io_root,omitempty"`
 	StateRoot    *hash.Hash `json:"state_root,omitempty"`
 	MessagesHash *hash.Hash `json:"messages_hash,omitempty"`
+
+	// InMessagesHash is the hash of processed incoming messages.
+	InMessagesHash *hash.Hash `json:"in_msgs_hash,omitempty"`
+	// InMessagesCount is the number of processed incoming messages.
+	InMessagesCount uint32 `json:"in_msgs_count,omitempty"`
 }
 
 // IsParentOf returns true iff the header is the parent of a child header.
@@ -94,6 +99,8 @@ func (m *ComputeBody) SetFailure(failure ExecutorCommitmentFailure) {
 	m.Header.IORoot = nil
 	m.Header.StateRoot = nil
 	m.Header.MessagesHash = nil
+	m.Header.InMessagesHash = nil
+	m.Header.InMessagesCount = 0
 	m.StorageSignatures = nil
 	m.RakSig = nil
 	m.Messages = nil
@@ -145,6 +152,9 @@ func (m *ComputeBody) ValidateBasic() error {
 		if header.MessagesHash == nil {
 			return fmt.Errorf("missing messages hash")
 		}
+		if header.InMessagesCount > 0 && header.InMessagesHash == nil {
+			return fmt.Errorf("missing incoming messages hash")
+		}
 
 		// Validate any included runtime messages.
 		for i, msg := range m.Messages {
@@ -168,6 +178,9 @@ func (m *ComputeBody) ValidateBasic() error {
 		if header.MessagesHash != nil {
 			return fmt.Errorf("failure indicating commitment includes MessagesHash")
 		}
+		if header.InMessagesHash != nil || header.InMessagesCount > 0 {
+			return fmt.Errorf("failure indicating commitment includes incoming messages")
+		}
 		// In case of failure indicating commitment make sure RAK signature is empty.
 		if m.RakSig != nil {
 			return fmt.Errorf("failure indicating body includes RAK signature")
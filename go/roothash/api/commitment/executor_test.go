@@ -90,6 +90,23 @@ func TestValidateBasic(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"Bad InMessagesHash",
+			func(b ComputeBody) ComputeBody {
+				b.Header.InMessagesCount = 1
+				return b
+			},
+			true,
+		},
+		{
+			"Ok InMessagesHash",
+			func(b ComputeBody) ComputeBody {
+				b.Header.InMessagesHash = &emptyRoot
+				b.Header.InMessagesCount = 1
+				return b
+			},
+			false,
+		},
 		{
 			"Bad runtime messages",
 			func(b ComputeBody) ComputeBody {
@@ -164,6 +181,16 @@ func TestValidateBasic(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"Bad Failure (existing InMessagesHash)",
+			func(b ComputeBody) ComputeBody {
+				b.SetFailure(FailureStorageUnavailable)
+				b.Header.InMessagesHash = &emptyRoot
+				b.Header.InMessagesCount = 1
+				return b
+			},
+			true,
+		},
 		{
 			"Ok Failure",
 			func(b ComputeBody) ComputeBody {
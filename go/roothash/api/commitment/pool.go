@@ -40,6 +40,7 @@ var (
 	ErrInvalidRound           = errors.New(moduleName, 18, "roothash/commitment: invalid round")
 	ErrNoProposerCommitment   = errors.New(moduleName, 19, "roothash/commitment: no proposer commitment")
 	ErrBadProposerCommitment  = errors.New(moduleName, 20, "roothash/commitment: bad proposer commitment")
+	ErrNotProposer            = errors.New(moduleName, 21, "roothash/commitment: node is not a proposer")
 )
 
 const (
@@ -64,7 +65,7 @@ type SignatureVerifier interface {
 	VerifyCommitteeSignatures(kind scheduler.CommitteeKind, sigs []signature.Signature) error
 
 	// VerifyTxnSchedulerSignature verifies that the given signatures come from
-	// the transaction scheduler at provided round.
+	// the transaction scheduler or one of the backup proposers at provided round.
 	VerifyTxnSchedulerSignature(sig signature.Signature, round uint64) error
 }
 
@@ -159,6 +160,39 @@ func (p *Pool) isScheduler(id signature.PublicKey) bool {
 	return scheduler.PublicKey.Equal(id)
 }
 
+// getProposers limits the given transaction schedulers ordered by priority to the transaction
+// scheduler and the backup proposers configured for the runtime.
+func (p *Pool) getProposers(schedulers []*scheduler.CommitteeNode) []*scheduler.CommitteeNode {
+	if n := p.Runtime.TxnScheduler.BackupProposers + 1; uint64(len(schedulers)) > n {
+		return schedulers[:n]
+	}
+	return schedulers
+}
+
+// isBatchProposer checks if the given node is the transaction scheduler or a backup proposer in
+// the current round and the given commitment body is not for a batch proposed by another one.
+func (p *Pool) isBatchProposer(id signature.PublicKey, body *ComputeBody) bool {
+	if p.Committee == nil || p.Runtime == nil {
+		return false
+	}
+	schedulers, err := GetTransactionSchedulers(p.Committee, p.Round)
+	if err != nil {
+		return false
+	}
+
+	var isProposer bool
+	for _, proposer := range p.getProposers(schedulers) {
+		switch {
+		case proposer.PublicKey.Equal(id):
+			isProposer = true
+		case proposer.PublicKey.Equal(body.TxnSchedSig.PublicKey):
+			// Batch proposed by another proposer.
+			return false
+		}
+	}
+	return isProposer
+}
+
 // ResetCommitments resets the commitments in the pool, clears the discrepancy flag and the next
 // timeout height.
 func (p *Pool) ResetCommitments(round uint64) {
@@ -324,9 +358,9 @@ func (p *Pool) addOpenExecutorCommitment(
 		}
 
 		// Check emitted runtime messages.
-		switch p.isScheduler(id) {
+		switch p.isBatchProposer(id, body) {
 		case true:
-			// The transaction scheduler can include messages.
+			// The (backup) proposer of the batch can include messages.
 			if uint32(len(body.Messages)) > p.Runtime.Executor.MaxMessages {
 				logger.Debug("executor commitment from scheduler has too many messages",
 					"node_id", id,
@@ -406,12 +440,14 @@ func (p *Pool) CheckEnoughCommitments(didTimeout bool) error {
 	case scheduler.KindComputeExecutor:
 		// We can only allow stragglers in case the transaction scheduler has submitted
 		// their commitment as that commitment may contain roothash messages.
-		proposer, err := GetTransactionScheduler(p.Committee, p.Round)
-		if err != nil {
-			return ErrNoCommittee
+		_, err := p.getProposerCommitment()
+		switch err {
+		case nil:
+			hasProposer = true
+		case ErrNoProposerCommitment:
+		default:
+			return err
 		}
-
-		_, hasProposer = p.ExecuteCommitments[proposer.PublicKey]
 	default:
 		panic("roothash/commitment: unknown committee kind while checking commitments: " + p.Committee.Kind.String())
 	}
@@ -501,24 +537,49 @@ func (p *Pool) CheckProposerTimeout(
 	return nil
 }
 
-func (p *Pool) getProposerCommitment() (OpenCommitment, error) {
-	var proposerCommit OpenCommitment
+// getProposerCommitments returns the commitments submitted by the transaction scheduler and the
+// backup proposers for their own batches, ordered by proposer priority.
+func (p *Pool) getProposerCommitments() ([]OpenCommitment, error) {
+	var proposerCommits []OpenCommitment
 	switch p.Committee.Kind {
 	case scheduler.KindComputeExecutor:
-		proposer, err := GetTransactionScheduler(p.Committee, p.Round)
+		proposers, err := GetTransactionSchedulers(p.Committee, p.Round)
 		if err != nil {
 			return nil, ErrNoCommittee
 		}
 
-		var ok bool
-		if proposerCommit, ok = p.ExecuteCommitments[proposer.PublicKey]; !ok {
+		for _, proposer := range p.getProposers(proposers) {
+			com, ok := p.ExecuteCommitments[proposer.PublicKey]
+			if !ok || !p.isBatchProposer(proposer.PublicKey, com.Body) {
+				continue
+			}
+			proposerCommits = append(proposerCommits, com)
+		}
+		if len(proposerCommits) == 0 {
 			// No proposer commitment, we cannot proceed.
 			return nil, ErrNoProposerCommitment
 		}
 	default:
 		panic("roothash/commitment: unknown committee kind while checking commitments: " + p.Committee.Kind.String())
 	}
-	return proposerCommit, nil
+	return proposerCommits, nil
+}
+
+// getProposerCommitment returns the proposer commitment. In case multiple proposers proposed a
+// batch, the commitment of the one with the highest priority is used.
+func (p *Pool) getProposerCommitment() (OpenCommitment, error) {
+	proposerCommits, err := p.getProposerCommitments()
+	if err != nil {
+		return nil, err
+	}
+	return proposerCommits[0], nil
+}
+
+// isSameBatch checks if the given executor commitments are for the same proposed batch.
+func isSameBatch(a, b OpenCommitment) bool {
+	return a.(OpenExecutorCommitment).Body.TxnSchedSig.PublicKey.Equal(
+		b.(OpenExecutorCommitment).Body.TxnSchedSig.PublicKey,
+	)
 }
 
 // DetectDiscrepancy performs discrepancy detection on the current commitments in
@@ -567,15 +628,8 @@ func (p *Pool) DetectDiscrepancy() (OpenCommitment, error) {
 	return proposerCommit, nil
 }
 
-// ResolveDiscrepancy performs discrepancy resolution on the current commitments
-// in the pool.
-//
-// The caller must verify that there are enough commitments in the pool.
-func (p *Pool) ResolveDiscrepancy() (OpenCommitment, error) {
-	if p.Committee == nil {
-		return nil, ErrNoCommittee
-	}
-
+// resolveMajority determines the commitment submitted by the majority of the backup workers.
+func (p *Pool) resolveMajority() (OpenCommitment, error) {
 	type voteEnt struct {
 		commit OpenCommitment
 		tally  uint64
@@ -629,18 +683,76 @@ func (p *Pool) ResolveDiscrepancy() (OpenCommitment, error) {
 	if majorityCommit == nil {
 		return nil, ErrInsufficientVotes
 	}
+	return majorityCommit, nil
+}
+
+// ResolveDiscrepancy performs discrepancy resolution on the current commitments
+// in the pool.
+//
+// The caller must verify that there are enough commitments in the pool.
+func (p *Pool) ResolveDiscrepancy() (OpenCommitment, error) {
+	if p.Committee == nil {
+		return nil, ErrNoCommittee
+	}
+
+	majorityCommit, err := p.resolveMajority()
+	if err != nil {
+		return nil, err
+	}
 
 	// Make sure that the majority commitment is the same as the proposer commitment. We must return
-	// the proposer commitment as that one contains additional data.
-	proposerCommit, err := p.getProposerCommitment()
+	// the proposer commitment as that one contains additional data. In case multiple proposers
+	// proposed a batch, the backup workers decide which one is used.
+	proposerCommits, err := p.getProposerCommitments()
 	if err != nil {
 		return nil, err
 	}
-	if !proposerCommit.MostlyEqual(majorityCommit) {
-		return nil, ErrBadProposerCommitment
+	for _, proposerCommit := range proposerCommits {
+		if proposerCommit.MostlyEqual(majorityCommit) {
+			return proposerCommit, nil
+		}
 	}
 
-	return proposerCommit, nil
+	return nil, ErrBadProposerCommitment
+}
+
+// ClassifyCommitments classifies the committee members that submitted commitments for the current
+// round based on whether their results agree with the commitment submitted by the majority of the
+// backup workers. Members whose commitments indicate failure or are for a different batch than the
+// majority commitment (e.g., one proposed by a backup proposer) are not classified.
+//
+// The members are returned in committee order. In case the backup workers have not reached a
+// majority, an error is returned.
+func (p *Pool) ClassifyCommitments() (agreeing, disagreeing []signature.PublicKey, err error) {
+	if p.Committee == nil {
+		return nil, nil, ErrNoCommittee
+	}
+
+	majorityCommit, err := p.resolveMajority()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seen := make(map[signature.PublicKey]bool)
+	for _, n := range p.Committee.Members {
+		if seen[n.PublicKey] {
+			continue
+		}
+		seen[n.PublicKey] = true
+
+		c, ok := p.getCommitment(n.PublicKey)
+		if !ok || c.IsIndicatingFailure() || !isSameBatch(majorityCommit, c) {
+			continue
+		}
+
+		switch majorityCommit.MostlyEqual(c) {
+		case true:
+			agreeing = append(agreeing, n.PublicKey)
+		case false:
+			disagreeing = append(disagreeing, n.PublicKey)
+		}
+	}
+	return agreeing, disagreeing, nil
 }
 
 // TryFinalize attempts to finalize the commitments by performing discrepancy
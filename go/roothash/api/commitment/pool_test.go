@@ -540,6 +540,12 @@ func TestPoolTwoCommitments(t *testing.T) {
 		require.Equal(t, true, pool.Discrepancy)
 		header := dc.ToDDResult().(*ComputeBody).Header
 		require.EqualValues(t, &correctBody.Header, &header, "DR should return the same header")
+
+		// The worker that committed a different result should be classified as disagreeing.
+		agreeing, disagreeing, err := pool.ClassifyCommitments()
+		require.NoError(t, err, "ClassifyCommitments")
+		require.EqualValues(t, []signature.PublicKey{sk1.Public(), sk3.Public()}, agreeing)
+		require.EqualValues(t, []signature.PublicKey{sk2.Public()}, disagreeing)
 	})
 
 	t.Run("DiscrepancyResolutionFailureVotes", func(t *testing.T) {
@@ -550,6 +556,10 @@ func TestPoolTwoCommitments(t *testing.T) {
 		require.Nil(t, dc, "ResolveDiscrepancy")
 		require.Error(t, err, "ResolveDiscrepancy")
 		require.Equal(t, ErrInsufficientVotes, err)
+
+		// Commitments cannot be classified without a majority.
+		_, _, err = pool.ClassifyCommitments()
+		require.Equal(t, ErrInsufficientVotes, err, "ClassifyCommitments")
 	})
 
 	t.Run("DiscrepancyResolutionFailureNotProposer", func(t *testing.T) {
@@ -572,6 +582,12 @@ func TestPoolTwoCommitments(t *testing.T) {
 		require.Nil(t, dc, "ResolveDiscrepancy")
 		require.Error(t, err, "ResolveDiscrepancy")
 		require.Equal(t, ErrBadProposerCommitment, err)
+
+		// The proposer should be classified as disagreeing.
+		agreeing, disagreeing, err := pool.ClassifyCommitments()
+		require.NoError(t, err, "ClassifyCommitments")
+		require.EqualValues(t, []signature.PublicKey{sk2.Public(), sk3.Public()}, agreeing)
+		require.EqualValues(t, []signature.PublicKey{sk1.Public()}, disagreeing)
 	})
 }
 
@@ -953,6 +969,169 @@ func TestExecutorTimeoutRequest(t *testing.T) {
 	})
 }
 
+func TestPoolBackupProposer(t *testing.T) {
+	genesisTestHelpers.SetTestChainContext()
+
+	rtTemplate := &registry.Runtime{
+		Kind:        registry.KindCompute,
+		TEEHardware: node.TEEHardwareInvalid,
+		Storage: registry.StorageParameters{
+			GroupSize:           1,
+			MinWriteReplication: 1,
+		},
+		Executor: registry.ExecutorParameters{
+			GroupSize:       2,
+			GroupBackupSize: 1,
+		},
+		TxnScheduler: registry.TxnSchedulerParameters{
+			BackupProposers: 1,
+		},
+	}
+	rt, sks, committee, nl := generateMockCommittee(t, rtTemplate)
+	sk1 := sks[0]
+	sk2 := sks[1]
+	sk3 := sks[2]
+
+	now := int64(1)
+	roundTimeout := int64(10)
+
+	t.Run("BackupBatch", func(t *testing.T) {
+		require := require.New(t)
+
+		// Create a pool. In round zero the first worker is the transaction scheduler and the
+		// second worker is the backup proposer.
+		pool := Pool{
+			Runtime:   rt,
+			Committee: committee,
+			Round:     0,
+		}
+
+		// Generate commitments for a batch proposed by the backup proposer.
+		childBlk, _, body := generateComputeBody(t, pool.Round)
+		body.TxnSchedSig = generateProposerSignature(t, sk2, childBlk, &body)
+
+		commit1, err := SignExecutorCommitment(sk1, &body)
+		require.NoError(err, "SignExecutorCommitment")
+		commit2, err := SignExecutorCommitment(sk2, &body)
+		require.NoError(err, "SignExecutorCommitment")
+
+		err = pool.AddExecutorCommitment(context.Background(), childBlk, nopSV, nl, commit1, nil)
+		require.NoError(err, "AddExecutorCommitment")
+
+		// The transaction scheduler executing the backup batch is not a proposer commitment.
+		_, err = pool.getProposerCommitment()
+		require.Equal(ErrNoProposerCommitment, err, "getProposerCommitment")
+
+		err = pool.AddExecutorCommitment(context.Background(), childBlk, nopSV, nl, commit2, nil)
+		require.NoError(err, "AddExecutorCommitment")
+
+		dc, err := pool.TryFinalize(now, roundTimeout, false, true)
+		require.NoError(err, "TryFinalize")
+		require.True(sk2.Public().Equal(dc.(OpenExecutorCommitment).Signature.PublicKey), "backup proposer commitment should be used")
+		header := dc.ToDDResult().(*ComputeBody).Header
+		require.EqualValues(&body.Header, &header, "DD should return the same header")
+	})
+
+	t.Run("CompetingBatches", func(t *testing.T) {
+		require := require.New(t)
+
+		pool := Pool{
+			Runtime:   rt,
+			Committee: committee,
+			Round:     0,
+		}
+
+		// The transaction scheduler and the backup proposer both propose and execute a batch.
+		childBlk, parentBlk, body := generateComputeBody(t, pool.Round)
+		schedulerBody := body
+		schedulerBody.TxnSchedSig = generateProposerSignature(t, sk1, childBlk, &schedulerBody)
+
+		backupBody := body
+		otherRoot := hash.NewFromBytes([]byte("backup batch"))
+		backupBody.Header.IORoot = &otherRoot
+		backupBody.StorageSignatures = []signature.Signature{generateStorageReceiptSignature(t, parentBlk, &backupBody)}
+		backupBody.TxnSchedSig = generateProposerSignature(t, sk2, childBlk, &backupBody)
+
+		commit1, err := SignExecutorCommitment(sk1, &schedulerBody)
+		require.NoError(err, "SignExecutorCommitment")
+		commit2, err := SignExecutorCommitment(sk2, &backupBody)
+		require.NoError(err, "SignExecutorCommitment")
+		commit3, err := SignExecutorCommitment(sk3, &backupBody)
+		require.NoError(err, "SignExecutorCommitment")
+
+		for _, commit := range []*ExecutorCommitment{commit1, commit2} {
+			err = pool.AddExecutorCommitment(context.Background(), childBlk, nopSV, nl, commit, nil)
+			require.NoError(err, "AddExecutorCommitment")
+		}
+
+		// The primary workers executed different batches.
+		_, err = pool.TryFinalize(now, roundTimeout, false, true)
+		require.Equal(ErrDiscrepancyDetected, err, "TryFinalize")
+
+		// The backup worker executes the backup batch which should be used.
+		err = pool.AddExecutorCommitment(context.Background(), childBlk, nopSV, nl, commit3, nil)
+		require.NoError(err, "AddExecutorCommitment")
+
+		dc, err := pool.TryFinalize(now, roundTimeout, false, true)
+		require.NoError(err, "TryFinalize")
+		require.True(sk2.Public().Equal(dc.(OpenExecutorCommitment).Signature.PublicKey), "backup proposer commitment should be used")
+		header := dc.ToDDResult().(*ComputeBody).Header
+		require.EqualValues(&backupBody.Header, &header, "DR should return the backup batch header")
+
+		// The transaction scheduler executed another batch so it should not be classified.
+		agreeing, disagreeing, err := pool.ClassifyCommitments()
+		require.NoError(err, "ClassifyCommitments")
+		require.EqualValues([]signature.PublicKey{sk2.Public(), sk3.Public()}, agreeing, "backup batch workers should agree")
+		require.Empty(disagreeing, "no workers should disagree")
+	})
+}
+
+func TestTransactionSchedulers(t *testing.T) {
+	require := require.New(t)
+
+	rt, sks, committee, _ := generateMockCommittee(t, nil)
+	sk1 := sks[0]
+	sk2 := sks[1]
+
+	// Default rotation is round-robin over the workers.
+	for round, expected := range []signature.Signer{sk1, sk2, sk1} {
+		txnScheduler, err := GetTransactionScheduler(committee, uint64(round))
+		require.NoError(err, "GetTransactionScheduler")
+		require.True(txnScheduler.PublicKey.Equal(expected.Public()), "transaction scheduler should rotate")
+	}
+
+	proposers, err := GetTransactionSchedulers(committee, 1)
+	require.NoError(err, "GetTransactionSchedulers")
+	require.Len(proposers, 2, "all workers should be proposers")
+	require.True(proposers[0].PublicKey.Equal(sk2.Public()), "first proposer should be the scheduler")
+	require.True(proposers[1].PublicKey.Equal(sk1.Public()), "second proposer should be the next worker")
+
+	// Without backup proposers only the transaction scheduler has a priority.
+	priority, err := GetProposerPriority(rt, committee, 0, sk1.Public())
+	require.NoError(err, "GetProposerPriority")
+	require.EqualValues(0, priority, "transaction scheduler should have priority zero")
+	_, err = GetProposerPriority(rt, committee, 0, sk2.Public())
+	require.Equal(ErrNotProposer, err, "GetProposerPriority should fail for non-proposers")
+
+	// With a backup proposer the next worker may also propose.
+	backupRt := *rt
+	backupRt.TxnScheduler.BackupProposers = 1
+	priority, err = GetProposerPriority(&backupRt, committee, 0, sk2.Public())
+	require.NoError(err, "GetProposerPriority")
+	require.EqualValues(1, priority, "backup proposer should have priority one")
+
+	// A proposer seed makes the order deterministic for a given seed and round.
+	seeded := *committee
+	seeded.ProposerSeed = []byte("proposer seed")
+	for round := uint64(0); round < 10; round++ {
+		a, err := GetTransactionSchedulers(&seeded, round)
+		require.NoError(err, "GetTransactionSchedulers")
+		b, err := GetTransactionSchedulers(&seeded, round)
+		require.NoError(err, "GetTransactionSchedulers")
+		require.EqualValues(a, b, "seeded order should be deterministic")
+	}
+}
+
 func generateMockCommittee(t *testing.T, rtTemplate *registry.Runtime) (
 	rt *registry.Runtime,
 	sks []signature.Signer,
@@ -1063,6 +1242,13 @@ func generateStorageReceiptSignature(t *testing.T, blk *block.Block, body *Compu
 }
 
 func generateTxnSchedulerSignature(t *testing.T, childBlk *block.Block, body *ComputeBody) signature.Signature {
+	sk, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(t, err, "NewSigner")
+
+	return generateProposerSignature(t, sk, childBlk, body)
+}
+
+func generateProposerSignature(t *testing.T, sk signature.Signer, childBlk *block.Block, body *ComputeBody) signature.Signature {
 	body.InputRoot = hash.Hash{}
 	body.InputStorageSigs = []signature.Signature{}
 	dispatch := &ProposedBatch{
@@ -1070,8 +1256,6 @@ func generateTxnSchedulerSignature(t *testing.T, childBlk *block.Block, body *Co
 		StorageSignatures: body.InputStorageSigs,
 		Header:            childBlk.Header,
 	}
-	sk, err := memorySigner.NewSigner(rand.Reader)
-	require.NoError(t, err, "NewSigner")
 	signedDispatch, err := SignProposedBatch(sk, dispatch)
 	require.NoError(t, err, "SignProposedBatch")
 
@@ -1,10 +1,12 @@
 package commitment
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
 )
@@ -57,11 +59,59 @@ func SignProposedBatch(signer signature.Signer, tsbd *ProposedBatch) (*SignedPro
 // GetTransactionScheduler returns the transaction scheduler of the provided
 // committee based on the provided round.
 func GetTransactionScheduler(committee *scheduler.Committee, round uint64) (*scheduler.CommitteeNode, error) {
+	proposers, err := GetTransactionSchedulers(committee, round)
+	if err != nil {
+		return nil, err
+	}
+	return proposers[0], nil
+}
+
+// GetTransactionSchedulers returns all workers of the provided committee ordered by their
+// transaction scheduler priority in the provided round. The first worker is the transaction
+// scheduler while the following workers are the backup proposers in order.
+func GetTransactionSchedulers(committee *scheduler.Committee, round uint64) ([]*scheduler.CommitteeNode, error) {
 	workers := committee.Workers()
 	numNodes := uint64(len(workers))
 	if numNodes == 0 {
-		return nil, fmt.Errorf("GetTransactionScheduler: no workers in commmittee")
+		return nil, fmt.Errorf("GetTransactionSchedulers: no workers in commmittee")
 	}
+
 	schedulerIdx := round % numNodes
-	return workers[schedulerIdx], nil
+	if len(committee.ProposerSeed) > 0 {
+		var rawRound [8]byte
+		binary.BigEndian.PutUint64(rawRound[:], round)
+		h := hash.NewFromBytes(committee.ProposerSeed, rawRound[:])
+		schedulerIdx = binary.BigEndian.Uint64(h[:8]) % numNodes
+	}
+
+	proposers := make([]*scheduler.CommitteeNode, 0, numNodes)
+	for i := uint64(0); i < numNodes; i++ {
+		proposers = append(proposers, workers[(schedulerIdx+i)%numNodes])
+	}
+	return proposers, nil
+}
+
+// GetProposerPriority returns the transaction scheduler priority of the given node in the provided
+// round. Priority zero is the transaction scheduler while higher priorities are the backup
+// proposers configured for the runtime.
+func GetProposerPriority(
+	runtime *registry.Runtime,
+	committee *scheduler.Committee,
+	round uint64,
+	id signature.PublicKey,
+) (uint64, error) {
+	proposers, err := GetTransactionSchedulers(committee, round)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, proposer := range proposers {
+		if uint64(i) > runtime.TxnScheduler.BackupProposers {
+			break
+		}
+		if proposer.PublicKey.Equal(id) {
+			return uint64(i), nil
+		}
+	}
+	return 0, ErrNotProposer
 }
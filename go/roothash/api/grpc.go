@@ -0,0 +1,534 @@
+package api
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	cmnGrpc "github.com/oasisprotocol/oasis-core/go/common/grpc"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/message"
+)
+
+var (
+	// serviceName is the gRPC service name.
+	serviceName = cmnGrpc.NewServiceName("RootHash")
+
+	// methodGetGenesisBlock is the GetGenesisBlock method.
+	methodGetGenesisBlock = serviceName.NewMethod("GetGenesisBlock", RuntimeRequest{})
+	// methodGetLatestBlock is the GetLatestBlock method.
+	methodGetLatestBlock = serviceName.NewMethod("GetLatestBlock", RuntimeRequest{})
+	// methodGetBlockByRound is the GetBlockByRound method.
+	methodGetBlockByRound = serviceName.NewMethod("GetBlockByRound", BlockByRoundRequest{})
+	// methodGetRoundsInRange is the GetRoundsInRange method.
+	methodGetRoundsInRange = serviceName.NewMethod("GetRoundsInRange", RoundsInRangeRequest{})
+	// methodGetRuntimeState is the GetRuntimeState method.
+	methodGetRuntimeState = serviceName.NewMethod("GetRuntimeState", RuntimeRequest{})
+	// methodGetRuntimeStateProof is the GetRuntimeStateProof method.
+	methodGetRuntimeStateProof = serviceName.NewMethod("GetRuntimeStateProof", RuntimeRequest{})
+	// methodGetLivenessStatistics is the GetLivenessStatistics method.
+	methodGetLivenessStatistics = serviceName.NewMethod("GetLivenessStatistics", RuntimeRequest{})
+	// methodGetIncomingMessageQueueMeta is the GetIncomingMessageQueueMeta method.
+	methodGetIncomingMessageQueueMeta = serviceName.NewMethod("GetIncomingMessageQueueMeta", RuntimeRequest{})
+	// methodGetIncomingMessageQueue is the GetIncomingMessageQueue method.
+	methodGetIncomingMessageQueue = serviceName.NewMethod("GetIncomingMessageQueue", InMessageQueueRequest{})
+	// methodStateToGenesis is the StateToGenesis method.
+	methodStateToGenesis = serviceName.NewMethod("StateToGenesis", int64(0))
+	// methodGetEvents is the GetEvents method.
+	methodGetEvents = serviceName.NewMethod("GetEvents", int64(0))
+
+	// methodWatchRuntimeEvents is the WatchRuntimeEvents method.
+	methodWatchRuntimeEvents = serviceName.NewMethod("WatchRuntimeEvents", WatchRuntimeEventsRequest{})
+
+	// serviceDesc is the gRPC service descriptor.
+	serviceDesc = grpc.ServiceDesc{
+		ServiceName: string(serviceName),
+		HandlerType: (*ClientBackend)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: methodGetGenesisBlock.ShortName(),
+				Handler:    handlerGetGenesisBlock,
+			},
+			{
+				MethodName: methodGetLatestBlock.ShortName(),
+				Handler:    handlerGetLatestBlock,
+			},
+			{
+				MethodName: methodGetBlockByRound.ShortName(),
+				Handler:    handlerGetBlockByRound,
+			},
+			{
+				MethodName: methodGetRoundsInRange.ShortName(),
+				Handler:    handlerGetRoundsInRange,
+			},
+			{
+				MethodName: methodGetRuntimeState.ShortName(),
+				Handler:    handlerGetRuntimeState,
+			},
+			{
+				MethodName: methodGetRuntimeStateProof.ShortName(),
+				Handler:    handlerGetRuntimeStateProof,
+			},
+			{
+				MethodName: methodGetLivenessStatistics.ShortName(),
+				Handler:    handlerGetLivenessStatistics,
+			},
+			{
+				MethodName: methodGetIncomingMessageQueueMeta.ShortName(),
+				Handler:    handlerGetIncomingMessageQueueMeta,
+			},
+			{
+				MethodName: methodGetIncomingMessageQueue.ShortName(),
+				Handler:    handlerGetIncomingMessageQueue,
+			},
+			{
+				MethodName: methodStateToGenesis.ShortName(),
+				Handler:    handlerStateToGenesis,
+			},
+			{
+				MethodName: methodGetEvents.ShortName(),
+				Handler:    handlerGetEvents,
+			},
+		},
+		Streams: []grpc.StreamDesc{
+			{
+				StreamName:    methodWatchRuntimeEvents.ShortName(),
+				Handler:       handlerWatchRuntimeEvents,
+				ServerStreams: true,
+			},
+		},
+	}
+)
+
+func handlerGetGenesisBlock( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var req RuntimeRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientBackend).GetGenesisBlock(ctx, req.RuntimeID, req.Height)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetGenesisBlock.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		r := req.(*RuntimeRequest)
+		return srv.(ClientBackend).GetGenesisBlock(ctx, r.RuntimeID, r.Height)
+	}
+	return interceptor(ctx, &req, info, handler)
+}
+
+func handlerGetLatestBlock( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var req RuntimeRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientBackend).GetLatestBlock(ctx, req.RuntimeID, req.Height)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetLatestBlock.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		r := req.(*RuntimeRequest)
+		return srv.(ClientBackend).GetLatestBlock(ctx, r.RuntimeID, r.Height)
+	}
+	return interceptor(ctx, &req, info, handler)
+}
+
+func handlerGetBlockByRound( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var req BlockByRoundRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientBackend).GetBlockByRound(ctx, req.RuntimeID, req.Round)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetBlockByRound.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		r := req.(*BlockByRoundRequest)
+		return srv.(ClientBackend).GetBlockByRound(ctx, r.RuntimeID, r.Round)
+	}
+	return interceptor(ctx, &req, info, handler)
+}
+
+func handlerGetRoundsInRange( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var req RoundsInRangeRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientBackend).GetRoundsInRange(ctx, req.RuntimeID, req.StartHeight, req.EndHeight)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetRoundsInRange.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		r := req.(*RoundsInRangeRequest)
+		return srv.(ClientBackend).GetRoundsInRange(ctx, r.RuntimeID, r.StartHeight, r.EndHeight)
+	}
+	return interceptor(ctx, &req, info, handler)
+}
+
+func handlerGetRuntimeState( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var req RuntimeRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientBackend).GetRuntimeState(ctx, req.RuntimeID, req.Height)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetRuntimeState.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		r := req.(*RuntimeRequest)
+		return srv.(ClientBackend).GetRuntimeState(ctx, r.RuntimeID, r.Height)
+	}
+	return interceptor(ctx, &req, info, handler)
+}
+
+func handlerGetRuntimeStateProof( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var req RuntimeRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientBackend).GetRuntimeStateProof(ctx, req.RuntimeID, req.Height)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetRuntimeStateProof.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		r := req.(*RuntimeRequest)
+		return srv.(ClientBackend).GetRuntimeStateProof(ctx, r.RuntimeID, r.Height)
+	}
+	return interceptor(ctx, &req, info, handler)
+}
+
+func handlerGetLivenessStatistics( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var req RuntimeRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientBackend).GetLivenessStatistics(ctx, req.RuntimeID, req.Height)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetLivenessStatistics.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		r := req.(*RuntimeRequest)
+		return srv.(ClientBackend).GetLivenessStatistics(ctx, r.RuntimeID, r.Height)
+	}
+	return interceptor(ctx, &req, info, handler)
+}
+
+func handlerGetIncomingMessageQueueMeta( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var req RuntimeRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientBackend).GetIncomingMessageQueueMeta(ctx, req.RuntimeID, req.Height)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetIncomingMessageQueueMeta.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		r := req.(*RuntimeRequest)
+		return srv.(ClientBackend).GetIncomingMessageQueueMeta(ctx, r.RuntimeID, r.Height)
+	}
+	return interceptor(ctx, &req, info, handler)
+}
+
+func handlerGetIncomingMessageQueue( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var req InMessageQueueRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientBackend).GetIncomingMessageQueue(ctx, &req)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetIncomingMessageQueue.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientBackend).GetIncomingMessageQueue(ctx, req.(*InMessageQueueRequest))
+	}
+	return interceptor(ctx, &req, info, handler)
+}
+
+func handlerStateToGenesis( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var height int64
+	if err := dec(&height); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientBackend).StateToGenesis(ctx, height)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodStateToGenesis.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientBackend).StateToGenesis(ctx, req.(int64))
+	}
+	return interceptor(ctx, height, info, handler)
+}
+
+func handlerGetEvents( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var height int64
+	if err := dec(&height); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClientBackend).GetEvents(ctx, height)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetEvents.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClientBackend).GetEvents(ctx, req.(int64))
+	}
+	return interceptor(ctx, height, info, handler)
+}
+
+func handlerWatchRuntimeEvents(srv interface{}, stream grpc.ServerStream) error {
+	var req WatchRuntimeEventsRequest
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	ch, sub, err := srv.(ClientBackend).WatchRuntimeEvents(ctx, req.RuntimeID, req.FromHeight)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return nil
+			}
+
+			if err := stream.SendMsg(ev); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// RegisterService registers a new roothash service with the given gRPC server.
+func RegisterService(server *grpc.Server, service ClientBackend) {
+	server.RegisterService(&serviceDesc, service)
+}
+
+type roothashClient struct {
+	conn *grpc.ClientConn
+}
+
+func (c *roothashClient) GetGenesisBlock(ctx context.Context, runtimeID common.Namespace, height int64) (*block.Block, error) {
+	var rsp block.Block
+	if err := c.conn.Invoke(ctx, methodGetGenesisBlock.FullName(), &RuntimeRequest{RuntimeID: runtimeID, Height: height}, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func (c *roothashClient) GetLatestBlock(ctx context.Context, runtimeID common.Namespace, height int64) (*block.Block, error) {
+	var rsp block.Block
+	if err := c.conn.Invoke(ctx, methodGetLatestBlock.FullName(), &RuntimeRequest{RuntimeID: runtimeID, Height: height}, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func (c *roothashClient) GetBlockByRound(ctx context.Context, runtimeID common.Namespace, round uint64) (*AnnotatedBlock, error) {
+	var rsp AnnotatedBlock
+	if err := c.conn.Invoke(ctx, methodGetBlockByRound.FullName(), &BlockByRoundRequest{RuntimeID: runtimeID, Round: round}, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func (c *roothashClient) GetRoundsInRange(ctx context.Context, runtimeID common.Namespace, startHeight, endHeight int64) ([]*AnnotatedBlock, error) {
+	var rsp []*AnnotatedBlock
+	if err := c.conn.Invoke(ctx, methodGetRoundsInRange.FullName(), &RoundsInRangeRequest{RuntimeID: runtimeID, StartHeight: startHeight, EndHeight: endHeight}, &rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+func (c *roothashClient) GetRuntimeState(ctx context.Context, runtimeID common.Namespace, height int64) (*RuntimeState, error) {
+	var rsp RuntimeState
+	if err := c.conn.Invoke(ctx, methodGetRuntimeState.FullName(), &RuntimeRequest{RuntimeID: runtimeID, Height: height}, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func (c *roothashClient) GetRuntimeStateProof(ctx context.Context, runtimeID common.Namespace, height int64) (*RuntimeStateProof, error) {
+	var rsp RuntimeStateProof
+	if err := c.conn.Invoke(ctx, methodGetRuntimeStateProof.FullName(), &RuntimeRequest{RuntimeID: runtimeID, Height: height}, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func (c *roothashClient) GetLivenessStatistics(ctx context.Context, runtimeID common.Namespace, height int64) (*LivenessStatistics, error) {
+	var rsp LivenessStatistics
+	if err := c.conn.Invoke(ctx, methodGetLivenessStatistics.FullName(), &RuntimeRequest{RuntimeID: runtimeID, Height: height}, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func (c *roothashClient) GetIncomingMessageQueueMeta(ctx context.Context, runtimeID common.Namespace, height int64) (*IncomingMessageQueueMeta, error) {
+	var rsp IncomingMessageQueueMeta
+	if err := c.conn.Invoke(ctx, methodGetIncomingMessageQueueMeta.FullName(), &RuntimeRequest{RuntimeID: runtimeID, Height: height}, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func (c *roothashClient) GetIncomingMessageQueue(ctx context.Context, request *InMessageQueueRequest) ([]*message.IncomingMessage, error) {
+	var rsp []*message.IncomingMessage
+	if err := c.conn.Invoke(ctx, methodGetIncomingMessageQueue.FullName(), request, &rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+func (c *roothashClient) StateToGenesis(ctx context.Context, height int64) (*Genesis, error) {
+	var rsp Genesis
+	if err := c.conn.Invoke(ctx, methodStateToGenesis.FullName(), height, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+func (c *roothashClient) GetEvents(ctx context.Context, height int64) ([]*Event, error) {
+	var rsp []*Event
+	if err := c.conn.Invoke(ctx, methodGetEvents.FullName(), height, &rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+func (c *roothashClient) WatchRuntimeEvents(
+	ctx context.Context,
+	runtimeID common.Namespace,
+	fromHeight int64,
+) (<-chan *Event, pubsub.ClosableSubscription, error) {
+	ctx, sub := pubsub.NewContextSubscription(ctx)
+
+	stream, err := c.conn.NewStream(ctx, &serviceDesc.Streams[0], methodWatchRuntimeEvents.FullName())
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = stream.SendMsg(&WatchRuntimeEventsRequest{RuntimeID: runtimeID, FromHeight: fromHeight}); err != nil {
+		return nil, nil, err
+	}
+	if err = stream.CloseSend(); err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan *Event)
+	go func() {
+		defer close(ch)
+
+		for {
+			var ev Event
+			if serr := stream.RecvMsg(&ev); serr != nil {
+				return
+			}
+
+			select {
+			case ch <- &ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, sub, nil
+}
+
+// NewRootHashClient creates a new gRPC roothash client service.
+func NewRootHashClient(c *grpc.ClientConn) ClientBackend {
+	return &roothashClient{c}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+)
+
+// NodeLivenessStatistics are per-node liveness statistics for an executor committee member.
+type NodeLivenessStatistics struct {
+	// RoundsElected is the number of rounds in which the node was a primary executor worker.
+	RoundsElected uint64 `json:"rounds_elected"`
+	// RoundsCommitted is the number of rounds in which the node submitted a commitment.
+	RoundsCommitted uint64 `json:"rounds_committed"`
+	// RoundsMissed is the number of rounds in which the node failed to submit a commitment.
+	RoundsMissed uint64 `json:"rounds_missed"`
+	// ProposerTimeouts is the number of rounds that failed due to the node not proposing a batch
+	// while being the transaction scheduler.
+	ProposerTimeouts uint64 `json:"proposer_timeouts"`
+
+	// Penalized is a flag specifying whether the node has already been penalized for liveness
+	// failures during the current epoch.
+	Penalized bool `json:"penalized,omitempty"`
+}
+
+// Failures returns the total number of liveness failures of the node.
+func (s *NodeLivenessStatistics) Failures() uint64 {
+	return s.RoundsMissed + s.ProposerTimeouts
+}
+
+// LivenessStatistics are liveness statistics of the executor committee members of a runtime.
+//
+// The statistics are reset each time a new executor committee is elected.
+type LivenessStatistics struct {
+	// Nodes are the per-node liveness statistics.
+	Nodes map[signature.PublicKey]*NodeLivenessStatistics `json:"nodes"`
+}
+
+// Node returns the liveness statistics for the given node, creating an empty entry if none
+// exists yet.
+func (s *LivenessStatistics) Node(id signature.PublicKey) *NodeLivenessStatistics {
+	if s.Nodes == nil {
+		s.Nodes = make(map[signature.PublicKey]*NodeLivenessStatistics)
+	}
+	ns := s.Nodes[id]
+	if ns == nil {
+		ns = new(NodeLivenessStatistics)
+		s.Nodes[id] = ns
+	}
+	return ns
+}
+
+// NewLivenessStatistics creates new empty liveness statistics.
+func NewLivenessStatistics() *LivenessStatistics {
+	return &LivenessStatistics{
+		Nodes: make(map[signature.PublicKey]*NodeLivenessStatistics),
+	}
+}
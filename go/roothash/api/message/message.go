@@ -4,21 +4,28 @@ package message
 import (
 	"fmt"
 
+	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
 	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
 )
 
 // Message is a message that can be sent by a runtime.
 type Message struct {
 	Staking *StakingMessage `json:"staking,omitempty"`
+	Runtime *RuntimeMessage `json:"runtime,omitempty"`
 }
 
 // ValidateBasic performs basic validation of the runtime message.
 func (m *Message) ValidateBasic() error {
 	switch {
+	case m.Staking != nil && m.Runtime != nil:
+		return fmt.Errorf("runtime message has multiple fields set")
 	case m.Staking != nil:
 		return m.Staking.ValidateBasic()
+	case m.Runtime != nil:
+		return m.Runtime.ValidateBasic()
 	default:
 		return fmt.Errorf("runtime message has no fields set")
 	}
@@ -57,3 +64,55 @@ func (sm *StakingMessage) ValidateBasic() error {
 		return fmt.Errorf("staking runtime message has no fields set")
 	}
 }
+
+// RuntimeMessage is a runtime message that allows a runtime to send a message to another runtime.
+//
+// The message is queued into the incoming message queue of the destination runtime and delivered
+// in one of its subsequent rounds.
+type RuntimeMessage struct {
+	cbor.Versioned
+
+	// To is the identifier of the destination runtime.
+	To common.Namespace `json:"to"`
+	// Fee is the fee paid by the sending runtime's account for delivering the message. It is
+	// transferred to the destination runtime's account.
+	Fee quantity.Quantity `json:"fee"`
+	// Data is the opaque message payload.
+	Data []byte `json:"data,omitempty"`
+}
+
+// ValidateBasic performs basic validation of the runtime message.
+func (rm *RuntimeMessage) ValidateBasic() error {
+	// Size limits and fees are checked against the destination runtime's parameters.
+	return nil
+}
+
+// IncomingMessage is a message sent by a runtime that has been queued for delivery to the
+// destination runtime.
+type IncomingMessage struct {
+	// ID is the sequence number of the message in the destination runtime's incoming message
+	// queue. Messages are delivered in sequence number order.
+	ID uint64 `json:"id"`
+
+	// From is the identifier of the sending runtime.
+	From common.Namespace `json:"from"`
+	// SourceRound is the round of the sending runtime in which the message was emitted.
+	SourceRound uint64 `json:"source_round"`
+	// SourceIndex is the index of the message among the messages emitted in SourceRound.
+	SourceIndex uint32 `json:"source_index"`
+
+	// Fee is the fee paid for delivering the message.
+	Fee quantity.Quantity `json:"fee"`
+	// Data is the opaque message payload.
+	Data []byte `json:"data,omitempty"`
+}
+
+// InMessagesHash returns a hash of provided incoming runtime messages.
+func InMessagesHash(msgs []*IncomingMessage) (h hash.Hash) {
+	if len(msgs) == 0 {
+		// Special case if there are no messages.
+		h.Empty()
+		return
+	}
+	return hash.NewFrom(msgs)
+}
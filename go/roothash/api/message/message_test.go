@@ -20,6 +20,7 @@ func TestMessageHash(t *testing.T) {
 		{[]Message{}, "c672b8d1ef56ed28ab87c3622c5114069bdd3ad7b8f9737498d0c01ecef0967a"},
 		{[]Message{{Staking: &StakingMessage{Transfer: &staking.Transfer{}}}}, "a6b91f974b34a9192efd12025659a768520d2f04e1dae9839677456412cdb2be"},
 		{[]Message{{Staking: &StakingMessage{Withdraw: &staking.Withdraw{}}}}, "069b0fda76d804e3fd65d4bbd875c646f15798fb573ac613100df67f5ba4c3fd"},
+		{[]Message{{Runtime: &RuntimeMessage{}}}, "4601050e644623a423a293c491106515058b429af7339253f77ed8cfadd1c508"},
 	} {
 		var h hash.Hash
 		err := h.UnmarshalHex(tc.expectedHash)
@@ -41,6 +42,8 @@ func TestMessageValidateBasic(t *testing.T) {
 		{"StakingNoFieldsSet", Message{Staking: &StakingMessage{}}, false},
 		{"StakingMultipleFieldsSet", Message{Staking: &StakingMessage{Transfer: &staking.Transfer{}, Withdraw: &staking.Withdraw{}}}, false},
 		{"ValidStaking", Message{Staking: &StakingMessage{Transfer: &staking.Transfer{}}}, true},
+		{"ValidRuntime", Message{Runtime: &RuntimeMessage{}}, true},
+		{"MultipleFieldsSet", Message{Staking: &StakingMessage{Transfer: &staking.Transfer{}}, Runtime: &RuntimeMessage{}}, false},
 	} {
 		err := tc.msg.ValidateBasic()
 		if tc.valid {
@@ -50,3 +53,22 @@ func TestMessageValidateBasic(t *testing.T) {
 		}
 	}
 }
+
+func TestInMessagesHash(t *testing.T) {
+	require := require.New(t)
+
+	for _, tc := range []struct {
+		msgs         []*IncomingMessage
+		expectedHash string
+	}{
+		{nil, "c672b8d1ef56ed28ab87c3622c5114069bdd3ad7b8f9737498d0c01ecef0967a"},
+		{[]*IncomingMessage{}, "c672b8d1ef56ed28ab87c3622c5114069bdd3ad7b8f9737498d0c01ecef0967a"},
+		{[]*IncomingMessage{{ID: 1, SourceRound: 2, Data: []byte("data")}}, "255ee601bae97dc1665af3dcb4775a32775b9c0f41da417f04489d1b2e6dc0c9"},
+	} {
+		var h hash.Hash
+		err := h.UnmarshalHex(tc.expectedHash)
+		require.NoError(err, "UnmarshalHex")
+
+		require.Equal(h.String(), InMessagesHash(tc.msgs).String(), "InMessagesHash must return the expected hash")
+	}
+}
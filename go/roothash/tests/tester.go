@@ -3,6 +3,7 @@ package tests
 
 import (
 	"context"
+	"errors"
 	"io/ioutil"
 	"os"
 	"strconv"
@@ -25,6 +26,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
 	storageAPI "github.com/oasisprotocol/oasis-core/go/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
 	"github.com/oasisprotocol/oasis-core/go/worker/storage"
 )
 
@@ -99,6 +101,14 @@ func RootHashImplementationTests(t *testing.T, backend api.Backend, consensus co
 		testSuccessfulRound(t, backend, consensus, identity, rtStates)
 	})
 
+	t.Run("RuntimeStateProof", func(t *testing.T) {
+		testRuntimeStateProof(t, backend, consensus, rtStates)
+	})
+
+	t.Run("RoundHistory", func(t *testing.T) {
+		testRoundHistory(t, backend, rtStates)
+	})
+
 	t.Run("RoundTimeout", func(t *testing.T) {
 		testRoundTimeout(t, backend, consensus, identity, rtStates)
 	})
@@ -228,6 +238,85 @@ func (s *runtimeState) testEpochTransitionBlock(t *testing.T, consensus consensu
 	}
 }
 
+func testRuntimeStateProof(t *testing.T, backend api.Backend, consensus consensusAPI.Backend, states []*runtimeState) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	for _, state := range states {
+		proof, err := backend.GetRuntimeStateProof(ctx, state.rt.Runtime.ID, consensusAPI.HeightLatest)
+		require.NoError(err, "GetRuntimeStateProof")
+
+		// The proof should verify against the state root committed to by the next block.
+		blk, err := consensus.GetBlock(ctx, proof.Height+1)
+		require.NoError(err, "GetBlock")
+		require.EqualValues(proof.Height, blk.StateRoot.Version, "proof should be for the committed state")
+
+		var pv syncer.ProofVerifier
+		_, err = pv.VerifyProof(ctx, blk.StateRoot.Hash, &proof.Proof)
+		require.NoError(err, "VerifyProof")
+	}
+}
+
+func testRoundHistory(t *testing.T, backend api.Backend, states []*runtimeState) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	for _, state := range states {
+		id := state.rt.Runtime.ID
+		latestBlk, err := backend.GetLatestBlock(ctx, id, consensusAPI.HeightLatest)
+		require.NoError(err, "GetLatestBlock")
+
+		annBlk, err := backend.GetBlockByRound(ctx, id, latestBlk.Header.Round)
+		require.NoError(err, "GetBlockByRound")
+		require.EqualValues(latestBlk, annBlk.Block, "GetBlockByRound should return the latest block")
+
+		// The block should have been finalized at the returned height.
+		blk, err := backend.GetLatestBlock(ctx, id, annBlk.Height)
+		require.NoError(err, "GetLatestBlock")
+		require.EqualValues(latestBlk, blk, "block should be the latest block at the returned height")
+		blk, err = backend.GetLatestBlock(ctx, id, annBlk.Height-1)
+		if err == nil {
+			require.True(blk.Header.Round < latestBlk.Header.Round, "block should not be the latest block at the preceding height")
+		}
+
+		_, err = backend.GetBlockByRound(ctx, id, latestBlk.Header.Round+1)
+		require.Error(err, "GetBlockByRound should fail for a future round")
+		require.True(errors.Is(err, api.ErrNotFound), "GetBlockByRound should fail with ErrNotFound")
+
+		blks, err := backend.GetRoundsInRange(ctx, id, annBlk.Height, consensusAPI.HeightLatest)
+		require.NoError(err, "GetRoundsInRange")
+		require.NotEmpty(blks, "GetRoundsInRange should return finalized blocks")
+		require.EqualValues(annBlk, blks[0], "GetRoundsInRange should start with the block finalized at start height")
+		for i := 1; i < len(blks); i++ {
+			require.EqualValues(blks[i-1].Block.Header.Round+1, blks[i].Block.Header.Round, "rounds should be consecutive")
+			require.True(blks[i].Height > blks[i-1].Height, "heights should be increasing")
+		}
+
+		// Past events should be replayed.
+		ch, sub, err := backend.WatchRuntimeEvents(ctx, id, annBlk.Height)
+		require.NoError(err, "WatchRuntimeEvents")
+		func() {
+			defer sub.Close()
+
+			for {
+				select {
+				case ev := <-ch:
+					require.EqualValues(id, ev.RuntimeID, "event should be for the watched runtime")
+					require.True(ev.Height >= annBlk.Height, "event should not be older than the requested height")
+					if ev.Finalized == nil || ev.Finalized.Round < latestBlk.Header.Round {
+						continue
+					}
+					require.EqualValues(annBlk.Height, ev.Height, "finalized event should be at the block height")
+					require.EqualValues(latestBlk.Header.Round, ev.Finalized.Round, "finalized event should be for the block round")
+					return
+				case <-time.After(recvTimeout):
+					t.Fatalf("failed to receive replayed finalized event")
+				}
+			}
+		}()
+	}
+}
+
 func testSuccessfulRound(t *testing.T, backend api.Backend, consensus consensusAPI.Backend, identity *identity.Identity, states []*runtimeState) {
 	for _, state := range states {
 		state.testSuccessfulRound(t, backend, consensus, identity)
@@ -416,6 +505,15 @@ func (s *runtimeState) testSuccessfulRound(t *testing.T, backend api.Backend, co
 				}
 			}
 
+			// All committing nodes should have their commitments recorded.
+			stats, err := backend.GetLivenessStatistics(ctx, header.Namespace, blk.Height)
+			require.NoError(err, "GetLivenessStatistics")
+			for _, ec := range executorCommits {
+				ns := stats.Nodes[ec.Signature.PublicKey]
+				require.NotNil(ns, "liveness statistics should exist for committing node")
+				require.True(ns.RoundsCommitted > 0, "liveness statistics should record the commitment")
+			}
+
 			// Nothing more to do after the block was received.
 			return
 		case <-time.After(recvTimeout):
@@ -2,9 +2,11 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"math"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/errors"
 	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
@@ -31,8 +33,24 @@ var (
 	// ErrNotSynced is an error return if transaction is submitted before node has finished
 	// initial syncing.
 	ErrNotSynced = errors.New(ModuleName, 4, "client: not finished initial sync")
+	// ErrNotHosted is an error returned when a query is made for a runtime which is not
+	// hosted by the node.
+	ErrNotHosted = errors.New(ModuleName, 5, "client: runtime not hosted")
+	// ErrQueryFailed is an error returned when the runtime fails to execute a query.
+	ErrQueryFailed = errors.New(ModuleName, 6, "client: query failed")
+	// ErrInvalidQuery is an error returned when an indexer query is malformed.
+	ErrInvalidQuery = errors.New(ModuleName, 7, "client: invalid query")
+	// ErrRuntimeSuspended is an error returned when a transaction is submitted to a runtime that
+	// is suspended.
+	ErrRuntimeSuspended = errors.New(ModuleName, 8, "client: runtime is suspended")
+	// ErrRuntimeDecommissioned is an error returned when a transaction is submitted to a runtime
+	// that has been decommissioned.
+	ErrRuntimeDecommissioned = errors.New(ModuleName, 9, "client: runtime is decommissioned")
 )
 
+// maxQueryFilterDepth is the maximum nesting depth of query filters.
+const maxQueryFilterDepth = 16
+
 // RuntimeClient is the runtime client interface.
 type RuntimeClient interface {
 	enclaverpc.Transport
@@ -49,6 +67,12 @@ type RuntimeClient interface {
 	// GetBlockByHash fetches the given runtime block by its block hash.
 	GetBlockByHash(ctx context.Context, request *GetBlockByHashRequest) (*block.Block, error)
 
+	// GetBlockWithProof fetches the given runtime block together with a proof of its inclusion
+	// in the consensus layer state.
+	//
+	// The proof can be verified by thin clients against a verified consensus light block.
+	GetBlockWithProof(ctx context.Context, request *GetBlockRequest) (*BlockWithProof, error)
+
 	// GetTx fetches the given runtime transaction.
 	GetTx(ctx context.Context, request *GetTxRequest) (*TxResult, error)
 
@@ -65,6 +89,12 @@ type RuntimeClient interface {
 	// QueryTxs queries the indexer for specific runtime transactions.
 	QueryTxs(ctx context.Context, request *QueryTxsRequest) ([]*TxResult, error)
 
+	// Query makes a read-only query against the runtime state at the given finalized round
+	// without submitting a transaction.
+	//
+	// The runtime must be hosted by the node handling the query.
+	Query(ctx context.Context, request *QueryRequest) (*QueryResponse, error)
+
 	// WatchBlocks subscribes to blocks for a specific runtimes.
 	WatchBlocks(ctx context.Context, runtimeID common.Namespace) (<-chan *roothash.AnnotatedBlock, pubsub.ClosableSubscription, error)
 
@@ -93,6 +123,18 @@ type GetBlockByHashRequest struct {
 	BlockHash hash.Hash        `json:"block_hash"`
 }
 
+// BlockWithProof is a runtime block together with a proof of its inclusion in the consensus
+// layer state.
+type BlockWithProof struct {
+	// Block is the runtime block.
+	Block *block.Block `json:"block"`
+	// Headers are the headers of the runtime blocks following the block up to and including the
+	// latest runtime block in the proven runtime state, linked by their previous hashes.
+	Headers []block.Header `json:"headers,omitempty"`
+	// Proof is the proof of the runtime state containing the latest runtime block.
+	Proof *roothash.RuntimeStateProof `json:"proof"`
+}
+
 // TxResult is the transaction query result.
 type TxResult struct {
 	Block  *block.Block `json:"block"`
@@ -158,6 +200,153 @@ type Query struct {
 	//
 	// A zero value means that the `maxQueryLimit` limit is used.
 	Limit uint64 `json:"limit"`
+
+	// Filter is an optional boolean filter expression over transaction tags.
+	//
+	// It is combined with the conditions using an AND query.
+	Filter *QueryFilter `json:"filter,omitempty"`
+
+	// Order is the order in which results are returned.
+	Order QueryOrder `json:"order,omitempty"`
+
+	// Cursor is an optional pagination cursor. In case it is set, only results that come after
+	// the cursor in the requested order are returned.
+	//
+	// To fetch the next page of results, set it to the position of the last returned result.
+	Cursor *QueryCursor `json:"cursor,omitempty"`
+}
+
+// ValidateBasic performs basic query validity checks.
+func (q *Query) ValidateBasic() error {
+	switch q.Order {
+	case OrderAscending, OrderDescending:
+	default:
+		return fmt.Errorf("%w: unknown order: %d", ErrInvalidQuery, q.Order)
+	}
+	if q.Filter != nil {
+		if err := q.Filter.validate(0); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidQuery, err)
+		}
+	}
+	return nil
+}
+
+// QueryOrder is the order in which query results are returned.
+type QueryOrder uint8
+
+const (
+	// OrderAscending orders results by ascending round and transaction index.
+	OrderAscending QueryOrder = 0
+	// OrderDescending orders results by descending round and transaction index.
+	OrderDescending QueryOrder = 1
+)
+
+// QueryCursor is a position in the query results.
+type QueryCursor struct {
+	// Round is the round of the transaction.
+	Round uint64 `json:"round"`
+	// Index is the index of the transaction within the block.
+	Index uint32 `json:"index"`
+}
+
+// QueryFilter is a boolean filter expression over transaction tags.
+//
+// Exactly one of the fields must be set.
+type QueryFilter struct {
+	// And matches transactions that match all of the given filters.
+	And []*QueryFilter `json:"and,omitempty"`
+	// Or matches transactions that match any of the given filters.
+	Or []*QueryFilter `json:"or,omitempty"`
+	// Not matches transactions that do not match the given filter.
+	Not *QueryFilter `json:"not,omitempty"`
+	// Tag matches transactions with a matching tag.
+	Tag *TagMatch `json:"tag,omitempty"`
+}
+
+func (f *QueryFilter) validate(depth int) error {
+	if depth >= maxQueryFilterDepth {
+		return fmt.Errorf("filter nested too deeply")
+	}
+
+	var (
+		n        int
+		children []*QueryFilter
+	)
+	if f.And != nil {
+		n++
+		children = f.And
+	}
+	if f.Or != nil {
+		n++
+		children = f.Or
+	}
+	if f.Not != nil {
+		n++
+		children = []*QueryFilter{f.Not}
+	}
+	if f.Tag != nil {
+		n++
+		if err := f.Tag.validate(); err != nil {
+			return err
+		}
+	}
+	if n != 1 {
+		return fmt.Errorf("filter must have exactly one of and/or/not/tag set")
+	}
+	if (f.And != nil || f.Or != nil) && len(children) == 0 {
+		return fmt.Errorf("and/or filter must have at least one child")
+	}
+	for _, child := range children {
+		if child == nil {
+			return fmt.Errorf("nil filter")
+		}
+		if err := child.validate(depth + 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TagMatch matches transactions with the given tag key and a value that matches exactly one of
+// the exact, prefix or range conditions.
+type TagMatch struct {
+	// Key is the tag key that should be matched.
+	Key []byte `json:"key"`
+
+	// Value matches tags with exactly the given value.
+	Value []byte `json:"value,omitempty"`
+	// Prefix matches tags with values starting with the given prefix.
+	Prefix []byte `json:"prefix,omitempty"`
+	// Min matches tags with values lexicographically greater or equal to the given value.
+	Min []byte `json:"min,omitempty"`
+	// Max matches tags with values lexicographically less or equal to the given value.
+	Max []byte `json:"max,omitempty"`
+}
+
+// IsRange returns true iff the tag match is a range match.
+func (m *TagMatch) IsRange() bool {
+	return m.Min != nil || m.Max != nil
+}
+
+func (m *TagMatch) validate() error {
+	if len(m.Key) == 0 {
+		return fmt.Errorf("tag match must have a key")
+	}
+
+	var n int
+	if m.Value != nil {
+		n++
+	}
+	if m.Prefix != nil {
+		n++
+	}
+	if m.IsRange() {
+		n++
+	}
+	if n != 1 {
+		return fmt.Errorf("tag match must have exactly one of value/prefix/range set")
+	}
+	return nil
 }
 
 // QueryTxsRequest is a QueryTxs request.
@@ -166,6 +355,19 @@ type QueryTxsRequest struct {
 	Query     Query            `json:"query"`
 }
 
+// QueryRequest is a Query request.
+type QueryRequest struct {
+	RuntimeID common.Namespace `json:"runtime_id"`
+	Round     uint64           `json:"round"`
+	Method    string           `json:"method"`
+	Args      cbor.RawMessage  `json:"args"`
+}
+
+// QueryResponse is a response to the runtime query.
+type QueryResponse struct {
+	Data cbor.RawMessage `json:"data"`
+}
+
 // WaitBlockIndexedRequest is a WaitBlockIndexed request.
 type WaitBlockIndexedRequest struct {
 	RuntimeID common.Namespace `json:"runtime_id"`
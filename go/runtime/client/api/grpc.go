@@ -28,6 +28,8 @@ var (
 	methodGetBlock = serviceName.NewMethod("GetBlock", GetBlockRequest{})
 	// methodGetBlockByHash is the GetBlockByHash method.
 	methodGetBlockByHash = serviceName.NewMethod("GetBlockByHash", GetBlockByHashRequest{})
+	// methodGetBlockWithProof is the GetBlockWithProof method.
+	methodGetBlockWithProof = serviceName.NewMethod("GetBlockWithProof", GetBlockRequest{})
 	// methodGetTx is the GetTx method.
 	methodGetTx = serviceName.NewMethod("GetTx", GetTxRequest{})
 	// methodGetTxByBlockHash is the GetTxByBlockHash method.
@@ -38,6 +40,8 @@ var (
 	methodQueryTx = serviceName.NewMethod("QueryTx", QueryTxRequest{})
 	// methodQueryTxs is the QueryTxs method.
 	methodQueryTxs = serviceName.NewMethod("QueryTxs", QueryTxsRequest{})
+	// methodQuery is the Query method.
+	methodQuery = serviceName.NewMethod("Query", QueryRequest{})
 	// methodWaitBlockIndexed is the WaitBlockIndexed method.
 	methodWaitBlockIndexed = serviceName.NewMethod("WaitBlockIndexed", WaitBlockIndexedRequest{})
 
@@ -65,6 +69,10 @@ var (
 				MethodName: methodGetBlockByHash.ShortName(),
 				Handler:    handlerGetBlockByHash,
 			},
+			{
+				MethodName: methodGetBlockWithProof.ShortName(),
+				Handler:    handlerGetBlockWithProof,
+			},
 			{
 				MethodName: methodGetTx.ShortName(),
 				Handler:    handlerGetTx,
@@ -85,6 +93,10 @@ var (
 				MethodName: methodQueryTxs.ShortName(),
 				Handler:    handlerQueryTxs,
 			},
+			{
+				MethodName: methodQuery.ShortName(),
+				Handler:    handlerQuery,
+			},
 			{
 				MethodName: methodWaitBlockIndexed.ShortName(),
 				Handler:    handlerWaitBlockIndexed,
@@ -225,6 +237,31 @@ func handlerGetBlockByHash( // nolint: golint
 	return interceptor(ctx, &rq, info, handler)
 }
 
+func handlerGetBlockWithProof( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var rq GetBlockRequest
+	if err := dec(&rq); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		rsp, err := srv.(RuntimeClient).GetBlockWithProof(ctx, &rq)
+		return rsp, errorWrapNotFound(err)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodGetBlockWithProof.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		rsp, err := srv.(RuntimeClient).GetBlockWithProof(ctx, req.(*GetBlockRequest))
+		return rsp, errorWrapNotFound(err)
+	}
+	return interceptor(ctx, &rq, info, handler)
+}
+
 func handlerGetTx( // nolint: golint
 	srv interface{},
 	ctx context.Context,
@@ -346,6 +383,31 @@ func handlerQueryTxs( // nolint: golint
 	return interceptor(ctx, &rq, info, handler)
 }
 
+func handlerQuery( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	var rq QueryRequest
+	if err := dec(&rq); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		rsp, err := srv.(RuntimeClient).Query(ctx, &rq)
+		return rsp, errorWrapNotFound(err)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: methodQuery.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		rsp, err := srv.(RuntimeClient).Query(ctx, req.(*QueryRequest))
+		return rsp, errorWrapNotFound(err)
+	}
+	return interceptor(ctx, &rq, info, handler)
+}
+
 func handlerWaitBlockIndexed( // nolint: golint
 	srv interface{},
 	ctx context.Context,
@@ -441,6 +503,14 @@ func (c *runtimeClient) GetBlockByHash(ctx context.Context, request *GetBlockByH
 	return &rsp, nil
 }
 
+func (c *runtimeClient) GetBlockWithProof(ctx context.Context, request *GetBlockRequest) (*BlockWithProof, error) {
+	var rsp BlockWithProof
+	if err := c.conn.Invoke(ctx, methodGetBlockWithProof.FullName(), request, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
 func (c *runtimeClient) GetTx(ctx context.Context, request *GetTxRequest) (*TxResult, error) {
 	var rsp TxResult
 	if err := c.conn.Invoke(ctx, methodGetTx.FullName(), request, &rsp); err != nil {
@@ -481,6 +551,14 @@ func (c *runtimeClient) QueryTxs(ctx context.Context, request *QueryTxsRequest)
 	return rsp, nil
 }
 
+func (c *runtimeClient) Query(ctx context.Context, request *QueryRequest) (*QueryResponse, error) {
+	var rsp QueryResponse
+	if err := c.conn.Invoke(ctx, methodQuery.FullName(), request, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
 func (c *runtimeClient) WaitBlockIndexed(ctx context.Context, request *WaitBlockIndexedRequest) error {
 	return c.conn.Invoke(ctx, methodWaitBlockIndexed.FullName(), request, nil)
 }
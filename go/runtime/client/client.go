@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
@@ -21,6 +22,8 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	"github.com/oasisprotocol/oasis-core/go/runtime/client/api"
 	enclaverpc "github.com/oasisprotocol/oasis-core/go/runtime/enclaverpc/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
 	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
 	"github.com/oasisprotocol/oasis-core/go/runtime/tagindexer"
 	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
@@ -45,12 +48,21 @@ var (
 	Flags = flag.NewFlagSet("", flag.ContinueOnError)
 )
 
+// HostedRuntimeProvider provides access to runtimes hosted by the node.
+type HostedRuntimeProvider interface {
+	// GetHostedRuntime returns the hosted runtime for the given runtime or nil in case the
+	// runtime is not hosted by the node.
+	GetHostedRuntime(runtimeID common.Namespace) host.Runtime
+}
+
 type clientCommon struct {
 	storage         storage.Backend
 	consensus       consensus.Backend
 	runtimeRegistry runtimeRegistry.Registry
 	// p2p may be nil.
 	p2p *p2p.P2P
+	// hostedRuntimes may be nil.
+	hostedRuntimes HostedRuntimeProvider
 
 	ctx context.Context
 }
@@ -91,6 +103,11 @@ func (c *runtimeClient) SubmitTx(ctx context.Context, request *api.SubmitTxReque
 		return nil, api.ErrNotSynced
 	}
 
+	// Fail early in case the runtime is not accepting transactions.
+	if err := c.checkRuntimeActive(ctx, request.RuntimeID); err != nil {
+		return nil, err
+	}
+
 	var watcher *blockWatcher
 	var ok bool
 	var err error
@@ -165,6 +182,22 @@ func (c *runtimeClient) SubmitTx(ctx context.Context, request *api.SubmitTxReque
 	}
 }
 
+func (c *runtimeClient) checkRuntimeActive(ctx context.Context, runtimeID common.Namespace) error {
+	rtState, err := c.common.consensus.RootHash().GetRuntimeState(ctx, runtimeID, consensus.HeightLatest)
+	if err != nil {
+		return fmt.Errorf("client: failed to get runtime state: %w", err)
+	}
+
+	switch {
+	case rtState.Decommissioned:
+		return api.ErrRuntimeDecommissioned
+	case rtState.Suspended:
+		return api.ErrRuntimeSuspended
+	default:
+		return nil
+	}
+}
+
 // Implements api.RuntimeClient.
 func (c *runtimeClient) WatchBlocks(ctx context.Context, runtimeID common.Namespace) (<-chan *roothash.AnnotatedBlock, pubsub.ClosableSubscription, error) {
 	return c.common.consensus.RootHash().WatchBlocks(runtimeID)
@@ -190,6 +223,62 @@ func (c *runtimeClient) GetBlock(ctx context.Context, request *api.GetBlockReque
 	}
 }
 
+// Implements api.RuntimeClient.
+func (c *runtimeClient) GetBlockWithProof(ctx context.Context, request *api.GetBlockRequest) (*api.BlockWithProof, error) {
+	switch request.Round {
+	case api.RoundLatest:
+		// Prove whatever is the latest runtime block at the latest provable consensus height.
+		proof, err := c.common.consensus.RootHash().GetRuntimeStateProof(ctx, request.RuntimeID, consensus.HeightLatest)
+		if err != nil {
+			return nil, err
+		}
+		blk, err := c.common.consensus.RootHash().GetLatestBlock(ctx, request.RuntimeID, proof.Height)
+		if err != nil {
+			return nil, err
+		}
+		return &api.BlockWithProof{Block: blk, Proof: proof}, nil
+	default:
+		rt, err := c.common.runtimeRegistry.GetRuntime(request.RuntimeID)
+		if err != nil {
+			return nil, err
+		}
+		annBlk, err := rt.History().GetAnnotatedBlock(ctx, request.Round)
+		if err != nil {
+			return nil, err
+		}
+		// The proof is for the consensus state at the height at which the block was finalized,
+		// but later runtime blocks may have been finalized at the same height.
+		proof, err := c.common.consensus.RootHash().GetRuntimeStateProof(ctx, request.RuntimeID, annBlk.Height)
+		if err != nil {
+			return nil, err
+		}
+		latestBlk, err := c.common.consensus.RootHash().GetLatestBlock(ctx, request.RuntimeID, annBlk.Height)
+		if err != nil {
+			return nil, err
+		}
+		if latestBlk.Header.Round < annBlk.Block.Header.Round {
+			return nil, fmt.Errorf("runtime/client: latest block at height %d precedes round %d",
+				annBlk.Height,
+				annBlk.Block.Header.Round,
+			)
+		}
+
+		// Link the block to the latest block via the headers of all following blocks.
+		var headers []block.Header
+		for round := annBlk.Block.Header.Round + 1; round < latestBlk.Header.Round; round++ {
+			var blk *block.Block
+			if blk, err = rt.History().GetBlock(ctx, round); err != nil {
+				return nil, fmt.Errorf("runtime/client: failed to get block for round %d: %w", round, err)
+			}
+			headers = append(headers, blk.Header)
+		}
+		if latestBlk.Header.Round > annBlk.Block.Header.Round {
+			headers = append(headers, latestBlk.Header)
+		}
+		return &api.BlockWithProof{Block: annBlk.Block, Headers: headers, Proof: proof}, nil
+	}
+}
+
 func (c *runtimeClient) getTxnTree(blk *block.Block) *transaction.Tree {
 	ioRoot := storage.Root{
 		Namespace: blk.Header.Namespace,
@@ -353,7 +442,16 @@ func (c *runtimeClient) QueryTxs(ctx context.Context, request *api.QueryTxsReque
 	}
 
 	output := []*api.TxResult{}
-	for round, txResults := range results {
+	for len(results) > 0 {
+		// Process all consecutive results from the same round together to preserve the order.
+		round := results[0].Round
+		n := 1
+		for n < len(results) && results[n].Round == round {
+			n++
+		}
+		txResults := results[:n]
+		results = results[n:]
+
 		// Fetch block for the given round.
 		var blk *block.Block
 		blk, err = c.GetBlock(ctx, &api.GetBlockRequest{RuntimeID: request.RuntimeID, Round: round})
@@ -392,6 +490,56 @@ func (c *runtimeClient) QueryTxs(ctx context.Context, request *api.QueryTxsReque
 	return output, nil
 }
 
+// Implements api.RuntimeClient.
+func (c *runtimeClient) Query(ctx context.Context, request *api.QueryRequest) (*api.QueryResponse, error) {
+	var hrt host.Runtime
+	if c.common.hostedRuntimes != nil {
+		hrt = c.common.hostedRuntimes.GetHostedRuntime(request.RuntimeID)
+	}
+	if hrt == nil {
+		return nil, api.ErrNotHosted
+	}
+
+	blk, err := c.GetBlock(ctx, &api.GetBlockRequest{RuntimeID: request.RuntimeID, Round: request.Round})
+	if err != nil {
+		return nil, err
+	}
+	consensusBlk, err := c.common.consensus.GetLightBlock(ctx, consensus.HeightLatest)
+	if err != nil {
+		return nil, fmt.Errorf("client: failed to get consensus light block: %w", err)
+	}
+	consensusStateBlk, err := c.common.consensus.GetBlock(ctx, consensusBlk.Height)
+	if err != nil {
+		return nil, fmt.Errorf("client: failed to get consensus block: %w", err)
+	}
+
+	rsp, err := hrt.Call(ctx, &protocol.Body{
+		RuntimeQueryRequest: &protocol.RuntimeQueryRequest{
+			ConsensusBlock: *consensusBlk,
+			ConsensusState: protocol.ConsensusState{ConsensusStateRoot: consensusStateBlk.StateRoot},
+			Method:         request.Method,
+			Args:           cbor.FixSliceForSerde(request.Args),
+			Block:          *blk,
+		},
+	})
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("%w: %s", api.ErrQueryFailed, err)
+	case rsp.RuntimeQueryResponse == nil:
+		return nil, fmt.Errorf("%w: malformed runtime response", api.ErrQueryFailed)
+	}
+
+	var output transaction.TxnOutput
+	if err = cbor.Unmarshal(rsp.RuntimeQueryResponse.Data, &output); err != nil {
+		return nil, fmt.Errorf("%w: malformed query output: %s", api.ErrQueryFailed, err)
+	}
+	if output.Error != nil {
+		return nil, fmt.Errorf("%w: %s", api.ErrQueryFailed, *output.Error)
+	}
+
+	return &api.QueryResponse{Data: output.Success}, nil
+}
+
 // Implements api.RuntimeClient.
 func (c *runtimeClient) WaitBlockIndexed(ctx context.Context, request *api.WaitBlockIndexedRequest) error {
 	tagIndexer, err := c.tagIndexer(request.RuntimeID)
@@ -456,6 +604,7 @@ func New(
 	consensus consensus.Backend,
 	runtimeRegistry runtimeRegistry.Registry,
 	p2p *p2p.P2P,
+	hostedRuntimes HostedRuntimeProvider,
 ) (api.RuntimeClient, error) {
 	maxTransactionAge := viper.GetInt64(CfgMaxTransactionAge)
 	if maxTransactionAge < minMaxTransactionAge && !cmdFlags.DebugDontBlameOasis() {
@@ -469,6 +618,7 @@ func New(
 			runtimeRegistry: runtimeRegistry,
 			ctx:             ctx,
 			p2p:             p2p,
+			hostedRuntimes:  hostedRuntimes,
 		},
 		watchers:          make(map[common.Namespace]*blockWatcher),
 		kmClients:         make(map[common.Namespace]*keymanager.Client),
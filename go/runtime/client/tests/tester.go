@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/runtime/client/api"
 )
@@ -38,6 +39,12 @@ func ClientImplementationTests(
 		defer cancelFunc()
 		testQuery(ctx, t, runtimeID, client, testInput)
 	})
+
+	t.Run("RuntimeQuery", func(t *testing.T) {
+		ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+		defer cancelFunc()
+		testRuntimeQuery(ctx, t, runtimeID, client)
+	})
 }
 
 func testSubmitTransaction(
@@ -98,6 +105,26 @@ func testQuery(
 	_, err = c.GetBlock(ctx, &api.GetBlockRequest{RuntimeID: runtimeID, Round: expectedLatestRound + 1})
 	require.Error(t, err, "GetBlock")
 
+	// Fetch blocks with inclusion proofs.
+	blkProof, err := c.GetBlockWithProof(ctx, &api.GetBlockRequest{RuntimeID: runtimeID, Round: 1})
+	require.NoError(t, err, "GetBlockWithProof")
+	require.EqualValues(t, 1, blkProof.Block.Header.Round)
+	require.True(t, blkProof.Proof.Height > 0, "GetBlockWithProof should return a valid proof height")
+	require.NotEmpty(t, blkProof.Proof.Proof.Entries, "GetBlockWithProof should return a proof")
+	prevHash := blkProof.Block.Header.EncodedHash()
+	for _, hdr := range blkProof.Headers {
+		require.EqualValues(t, prevHash, hdr.PreviousHash, "GetBlockWithProof should return linked headers")
+		prevHash = hdr.EncodedHash()
+	}
+
+	blkProof, err = c.GetBlockWithProof(ctx, &api.GetBlockRequest{RuntimeID: runtimeID, Round: api.RoundLatest})
+	require.NoError(t, err, "GetBlockWithProof(RoundLatest)")
+	// The latest provable block may lag behind the latest block.
+	blk2, err := c.GetBlock(ctx, &api.GetBlockRequest{RuntimeID: runtimeID, Round: blkProof.Block.Header.Round})
+	require.NoError(t, err, "GetBlock")
+	require.EqualValues(t, blk2, blkProof.Block, "GetBlockWithProof(RoundLatest) should return a valid block")
+	require.NotEmpty(t, blkProof.Proof.Proof.Entries, "GetBlockWithProof should return a proof")
+
 	err = c.WaitBlockIndexed(ctx, &api.WaitBlockIndexedRequest{RuntimeID: runtimeID, Round: expectedLatestRound})
 	require.NoError(t, err, "WaitBlockIndexed")
 
@@ -167,8 +194,65 @@ func testQuery(
 	require.True(t, strings.HasPrefix(string(results[0].Input), "hello world"))
 	require.True(t, strings.HasPrefix(string(results[0].Output), "hello world"))
 
+	// Test paginated transaction queries with boolean filters in descending order.
+	query = api.Query{
+		Filter: &api.QueryFilter{Or: []*api.QueryFilter{
+			{Tag: &api.TagMatch{Key: []byte("txn_foo"), Value: []byte("txn_bar")}},
+			{Tag: &api.TagMatch{Key: []byte("txn_foo"), Prefix: []byte("nonexistent")}},
+		}},
+		Order: api.OrderDescending,
+	}
+	all, err := c.QueryTxs(ctx, &api.QueryTxsRequest{RuntimeID: runtimeID, Query: query})
+	require.NoError(t, err, "QueryTxs")
+	require.NotEmpty(t, all, "QueryTxs should return results")
+	for i := 1; i < len(all); i++ {
+		require.True(t, all[i-1].Block.Header.Round >= all[i].Block.Header.Round, "results should be in descending order")
+	}
+
+	query.Limit = 1
+	var pages []*api.TxResult
+	for i := 0; i <= len(all); i++ {
+		results, err = c.QueryTxs(ctx, &api.QueryTxsRequest{RuntimeID: runtimeID, Query: query})
+		require.NoError(t, err, "QueryTxs")
+		if len(results) == 0 {
+			break
+		}
+		require.Len(t, results, 1, "QueryTxs should respect the limit")
+		pages = append(pages, results...)
+		query.Cursor = &api.QueryCursor{Round: results[0].Block.Header.Round, Index: results[0].Index}
+	}
+	require.EqualValues(t, all, pages, "paginated QueryTxs should return all results")
+
 	// Query genesis block again.
 	genBlk2, err := c.GetGenesisBlock(ctx, runtimeID)
 	require.NoError(t, err, "GetGenesisBlock2")
 	require.EqualValues(t, genBlk, genBlk2, "GetGenesisBlock should match previous GetGenesisBlock")
 }
+
+func testRuntimeQuery(
+	ctx context.Context,
+	t *testing.T,
+	runtimeID common.Namespace,
+	c api.RuntimeClient,
+) {
+	// The mock runtime echoes the query arguments.
+	args := cbor.Marshal("octopus")
+	rsp, err := c.Query(ctx, &api.QueryRequest{
+		RuntimeID: runtimeID,
+		Round:     api.RoundLatest,
+		Method:    "echo",
+		Args:      args,
+	})
+	require.NoError(t, err, "Query")
+	require.EqualValues(t, args, rsp.Data, "Query should return the runtime response")
+
+	// Querying an unknown runtime should fail.
+	var unknownID common.Namespace
+	_, err = c.Query(ctx, &api.QueryRequest{
+		RuntimeID: unknownID,
+		Round:     api.RoundLatest,
+		Method:    "echo",
+		Args:      args,
+	})
+	require.Error(t, err, "Query for an unknown runtime")
+}
@@ -180,6 +180,20 @@ func (w *blockWatcher) watch() {
 			}
 			w.toBeChecked = failedBlocks
 
+			// If the runtime has been suspended, pending transactions will not get processed.
+			if blk.Block.Header.HeaderType == block.Suspended {
+				for key, watch := range w.watched {
+					res := &watchResult{
+						err: api.ErrRuntimeSuspended,
+					}
+					// Ignore errors, the watch is getting deleted anyway.
+					_ = watch.send(res, 0)
+					close(watch.respCh)
+					delete(w.watched, key)
+				}
+				continue
+			}
+
 			// If this is an epoch transition block, update latest known group
 			// version and resend all transactions.
 			if blk.Block.Header.HeaderType != block.EpochTransition {
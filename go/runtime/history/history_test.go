@@ -91,6 +91,11 @@ func TestHistory(t *testing.T) {
 	require.NoError(err, "GetBlock")
 	require.Equal(&putBlk, gotBlk, "GetBlock should return the correct block")
 
+	gotAnnBlk, err := history.GetAnnotatedBlock(context.Background(), 10)
+	require.NoError(err, "GetAnnotatedBlock")
+	require.Equal(&putBlk, gotAnnBlk.Block, "GetAnnotatedBlock should return the correct block")
+	require.EqualValues(50, gotAnnBlk.Height, "GetAnnotatedBlock should return the correct height")
+
 	gotLatestBlk, err := history.GetLatestBlock(context.Background())
 	require.NoError(err, "GetLatestBlock")
 	require.Equal(&putBlk, gotLatestBlk, "GetLatestBlock should return the correct block")
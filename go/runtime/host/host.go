@@ -3,6 +3,7 @@ package host
 
 import (
 	"context"
+	"time"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
@@ -26,6 +27,35 @@ type Config struct {
 
 	// MessageHandler is the message handler for the Runtime Host Protocol messages.
 	MessageHandler protocol.Handler
+
+	// Limits are the resource limits for the provisioned runtime. Provisioners that do not support
+	// resource limits ignore this field.
+	Limits ResourceLimits
+}
+
+// ResourceLimits are the resource limits for a provisioned runtime. Zero values mean that the
+// given resource is not limited.
+type ResourceLimits struct {
+	// MemoryBytes is the maximum amount of memory (in bytes) that can be used.
+	MemoryBytes uint64
+
+	// CPUs is the maximum number of CPUs that can be used (e.g., 1.5 means one and a half CPUs).
+	CPUs float64
+
+	// Pids is the maximum number of processes that can be created.
+	Pids uint64
+}
+
+// ResourceUsage is the resource usage of a provisioned runtime.
+type ResourceUsage struct {
+	// CPUTime is the CPU time consumed by the current runtime instance.
+	CPUTime time.Duration `json:"cpu_time"`
+
+	// RSSBytes is the resident set size (in bytes) of the current runtime instance.
+	RSSBytes uint64 `json:"rss_bytes"`
+
+	// Restarts is the number of times the runtime has been restarted.
+	Restarts uint64 `json:"restarts"`
 }
 
 // Provisioner is the runtime provisioner interface.
@@ -60,6 +90,38 @@ type Runtime interface {
 	Stop()
 }
 
+// ResourceUsageReporter is the interface implemented by provisioned runtimes that support
+// resource accounting.
+type ResourceUsageReporter interface {
+	// GetResourceUsage returns the current resource usage of the runtime.
+	GetResourceUsage() (*ResourceUsage, error)
+}
+
+// CrashStatus is the crash status of a provisioned runtime.
+type CrashStatus struct {
+	// Crashes is the total number of times the runtime has crashed or failed to start.
+	Crashes uint64 `json:"crashes"`
+
+	// CrashLoop is true iff the runtime has been detected as crash looping and is not being
+	// restarted until the crash loop cooldown period expires.
+	CrashLoop bool `json:"crash_loop"`
+
+	// LastCrashTime is the time of the last crash.
+	LastCrashTime time.Time `json:"last_crash_time,omitempty"`
+
+	// LastExitStatus is the exit status of the last crash.
+	LastExitStatus string `json:"last_exit_status,omitempty"`
+
+	// LastStderr contains the last lines written to standard error before the last crash.
+	LastStderr []string `json:"last_stderr,omitempty"`
+}
+
+// CrashStatusReporter is the interface implemented by provisioned runtimes that track crashes.
+type CrashStatusReporter interface {
+	// GetCrashStatus returns the current crash status of the runtime.
+	GetCrashStatus() (*CrashStatus, error)
+}
+
 // RuntimeEventEmitter is the interface for emitting events for a provisioned runtime.
 type RuntimeEventEmitter interface {
 	// EmitEvent allows the caller to emit a runtime event.
@@ -72,6 +134,7 @@ type Event struct {
 	FailedToStart *FailedToStartEvent
 	Stopped       *StoppedEvent
 	Updated       *UpdatedEvent
+	CrashLoop     *CrashLoopEvent
 }
 
 // StartedEvent is a runtime started event.
@@ -94,6 +157,16 @@ type FailedToStartEvent struct {
 type StoppedEvent struct {
 }
 
+// CrashLoopEvent is a runtime crash loop detected event. After this event the runtime is not
+// restarted until the crash loop cooldown period expires.
+type CrashLoopEvent struct {
+	// Crashes is the number of crashes within the crash loop detection window.
+	Crashes int
+
+	// LastExitStatus is the exit status of the last crash.
+	LastExitStatus string
+}
+
 // UpdatedEvent is a runtime metadata updated event.
 type UpdatedEvent struct {
 	// CapabilityTEE is the updated runtime's CapabilityTEE. It may be nil in case the runtime is
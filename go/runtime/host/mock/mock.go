@@ -6,9 +6,11 @@ import (
 	"fmt"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/message"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
 	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
@@ -75,19 +77,35 @@ func (r *runtime) Call(ctx context.Context, body *protocol.Body) (*protocol.Body
 		stateRoot.Empty()
 		msgsHash.Empty()
 
+		// Process all incoming messages.
+		var inMsgsHash *hash.Hash
+		if len(rq.IncomingMessages) > 0 {
+			h := message.InMessagesHash(rq.IncomingMessages)
+			inMsgsHash = &h
+		}
+
 		return &protocol.Body{RuntimeExecuteTxBatchResponse: &protocol.RuntimeExecuteTxBatchResponse{
 			Batch: protocol.ComputedBatch{
 				Header: commitment.ComputeResultsHeader{
-					Round:        rq.Block.Header.Round + 1,
-					PreviousHash: rq.Block.Header.EncodedHash(),
-					IORoot:       &ioRoot,
-					StateRoot:    &stateRoot,
-					MessagesHash: &msgsHash,
+					Round:           rq.Block.Header.Round + 1,
+					PreviousHash:    rq.Block.Header.EncodedHash(),
+					IORoot:          &ioRoot,
+					StateRoot:       &stateRoot,
+					MessagesHash:    &msgsHash,
+					InMessagesHash:  inMsgsHash,
+					InMessagesCount: uint32(len(rq.IncomingMessages)),
 				},
 				IOWriteLog: ioWriteLog,
 			},
 			// No RakSig in mock response.
 		}}, nil
+	case body.RuntimeQueryRequest != nil:
+		rq := body.RuntimeQueryRequest
+
+		// Echo the query arguments back.
+		return &protocol.Body{RuntimeQueryResponse: &protocol.RuntimeQueryResponse{
+			Data: cbor.Marshal(&transaction.TxnOutput{Success: rq.Args}),
+		}}, nil
 	default:
 		return nil, fmt.Errorf("(mock) method not supported")
 	}
@@ -0,0 +1,299 @@
+// Package multi implements support for a runtime host aggregating multiple
+// versions of the same runtime, of which a single one is active at any time.
+package multi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+)
+
+var (
+	// ErrNoActiveVersion is the error returned when there is no active runtime version.
+	ErrNoActiveVersion = errors.New("runtime/host/multi: no active runtime version")
+	// ErrVersionExists is the error returned when adding an already existing runtime version.
+	ErrVersionExists = errors.New("runtime/host/multi: runtime version already exists")
+	// ErrNoSuchVersion is the error returned when a runtime version does not exist.
+	ErrNoSuchVersion = errors.New("runtime/host/multi: no such runtime version")
+	// ErrActiveVersion is the error returned when removing the active runtime version.
+	ErrActiveVersion = errors.New("runtime/host/multi: cannot remove active runtime version")
+
+	_ host.Runtime               = (*Aggregate)(nil)
+	_ host.ResourceUsageReporter = (*Aggregate)(nil)
+	_ host.CrashStatusReporter   = (*Aggregate)(nil)
+)
+
+type aggregatedHost struct {
+	host    host.Runtime
+	version version.Version
+
+	// started is the last started event emitted by the host (if any).
+	started *host.StartedEvent
+
+	stopCh chan struct{}
+}
+
+// Aggregate is a host.Runtime that aggregates multiple versions of the same runtime and forwards
+// all requests to the currently active version.
+type Aggregate struct {
+	l sync.RWMutex
+
+	id common.Namespace
+
+	hosts   map[version.Version]*aggregatedHost
+	active  *aggregatedHost
+	running bool
+
+	notifier *pubsub.Broker
+
+	logger *logging.Logger
+}
+
+// ID implements host.Runtime.
+func (agg *Aggregate) ID() common.Namespace {
+	return agg.id
+}
+
+// Call implements host.Runtime.
+func (agg *Aggregate) Call(ctx context.Context, body *protocol.Body) (*protocol.Body, error) {
+	agg.l.RLock()
+	active := agg.active
+	agg.l.RUnlock()
+
+	if active == nil {
+		return nil, ErrNoActiveVersion
+	}
+	return active.host.Call(ctx, body)
+}
+
+// WatchEvents implements host.Runtime.
+func (agg *Aggregate) WatchEvents(ctx context.Context) (<-chan *host.Event, pubsub.ClosableSubscription, error) {
+	typedCh := make(chan *host.Event)
+	sub := agg.notifier.Subscribe()
+	sub.Unwrap(typedCh)
+
+	return typedCh, sub, nil
+}
+
+// Start implements host.Runtime.
+//
+// Only the active version is started, other versions must be started explicitly.
+func (agg *Aggregate) Start() error {
+	agg.l.Lock()
+	defer agg.l.Unlock()
+
+	if agg.active == nil {
+		return ErrNoActiveVersion
+	}
+	if agg.running {
+		return nil
+	}
+	if err := agg.active.host.Start(); err != nil {
+		return err
+	}
+	agg.running = true
+	return nil
+}
+
+// Abort implements host.Runtime.
+func (agg *Aggregate) Abort(ctx context.Context, force bool) error {
+	agg.l.RLock()
+	active := agg.active
+	agg.l.RUnlock()
+
+	if active == nil {
+		return ErrNoActiveVersion
+	}
+	return active.host.Abort(ctx, force)
+}
+
+// Stop implements host.Runtime.
+//
+// All aggregated versions are stopped.
+func (agg *Aggregate) Stop() {
+	agg.l.Lock()
+	defer agg.l.Unlock()
+
+	for v, ah := range agg.hosts {
+		close(ah.stopCh)
+		ah.host.Stop()
+		delete(agg.hosts, v)
+	}
+	agg.active = nil
+	agg.running = false
+}
+
+// GetResourceUsage implements host.ResourceUsageReporter.
+//
+// The resource usage of the active version is returned.
+func (agg *Aggregate) GetResourceUsage() (*host.ResourceUsage, error) {
+	agg.l.RLock()
+	active := agg.active
+	agg.l.RUnlock()
+
+	if active == nil {
+		return nil, ErrNoActiveVersion
+	}
+	reporter, ok := active.host.(host.ResourceUsageReporter)
+	if !ok {
+		return nil, fmt.Errorf("runtime/host/multi: resource accounting not supported")
+	}
+	return reporter.GetResourceUsage()
+}
+
+// GetCrashStatus implements host.CrashStatusReporter.
+//
+// The crash status of the active version is returned.
+func (agg *Aggregate) GetCrashStatus() (*host.CrashStatus, error) {
+	agg.l.RLock()
+	active := agg.active
+	agg.l.RUnlock()
+
+	if active == nil {
+		return nil, ErrNoActiveVersion
+	}
+	reporter, ok := active.host.(host.CrashStatusReporter)
+	if !ok {
+		return nil, fmt.Errorf("runtime/host/multi: crash tracking not supported")
+	}
+	return reporter.GetCrashStatus()
+}
+
+// Version returns the currently active runtime version.
+func (agg *Aggregate) Version() (version.Version, error) {
+	agg.l.RLock()
+	defer agg.l.RUnlock()
+
+	if agg.active == nil {
+		return version.Version{}, ErrNoActiveVersion
+	}
+	return agg.active.version, nil
+}
+
+// HasVersion returns true iff the given runtime version has been added.
+func (agg *Aggregate) HasVersion(v version.Version) bool {
+	agg.l.RLock()
+	defer agg.l.RUnlock()
+
+	_, ok := agg.hosts[v]
+	return ok
+}
+
+// AddVersion adds a new runtime version. In case there is no active version yet, the added
+// version becomes active.
+//
+// The caller is responsible for starting the runtime unless it becomes active before Start is
+// called on the aggregate.
+func (agg *Aggregate) AddVersion(v version.Version, rt host.Runtime) error {
+	agg.l.Lock()
+	defer agg.l.Unlock()
+
+	if _, ok := agg.hosts[v]; ok {
+		return ErrVersionExists
+	}
+
+	ch, sub, err := rt.WatchEvents(context.Background())
+	if err != nil {
+		return fmt.Errorf("runtime/host/multi: failed to watch runtime events: %w", err)
+	}
+
+	ah := &aggregatedHost{
+		host:    rt,
+		version: v,
+		stopCh:  make(chan struct{}),
+	}
+	agg.hosts[v] = ah
+	if agg.active == nil {
+		agg.active = ah
+	}
+
+	go agg.forwardEvents(ah, ch, sub)
+
+	return nil
+}
+
+// SetVersion makes the given runtime version active.
+//
+// In case the runtime version has already emitted a started event, the event is re-emitted so
+// that subscribers learn about the new version.
+func (agg *Aggregate) SetVersion(v version.Version) error {
+	agg.l.Lock()
+	defer agg.l.Unlock()
+
+	ah, ok := agg.hosts[v]
+	if !ok {
+		return ErrNoSuchVersion
+	}
+	if agg.active == ah {
+		return nil
+	}
+
+	agg.logger.Info("switching active runtime version",
+		"version", v,
+	)
+
+	agg.active = ah
+	if ah.started != nil {
+		agg.notifier.Broadcast(&host.Event{Started: ah.started})
+	}
+	return nil
+}
+
+// RemoveVersion stops and removes the given runtime version.
+func (agg *Aggregate) RemoveVersion(v version.Version) error {
+	agg.l.Lock()
+	defer agg.l.Unlock()
+
+	ah, ok := agg.hosts[v]
+	if !ok {
+		return ErrNoSuchVersion
+	}
+	if agg.active == ah {
+		return ErrActiveVersion
+	}
+
+	close(ah.stopCh)
+	ah.host.Stop()
+	delete(agg.hosts, v)
+
+	return nil
+}
+
+func (agg *Aggregate) forwardEvents(ah *aggregatedHost, ch <-chan *host.Event, sub pubsub.ClosableSubscription) {
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ah.stopCh:
+			return
+		case ev := <-ch:
+			agg.l.Lock()
+			if ev.Started != nil {
+				ah.started = ev.Started
+			}
+			// Only forward events of the active version.
+			if agg.active == ah {
+				agg.notifier.Broadcast(ev)
+			}
+			agg.l.Unlock()
+		}
+	}
+}
+
+// New creates a new runtime host aggregating multiple versions of the same runtime.
+func New(id common.Namespace) *Aggregate {
+	return &Aggregate{
+		id:       id,
+		hosts:    make(map[version.Version]*aggregatedHost),
+		notifier: pubsub.NewBroker(false),
+		logger:   logging.GetLogger("runtime/host/multi").With("runtime_id", id),
+	}
+}
@@ -0,0 +1,90 @@
+package multi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/mock"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+)
+
+const recvTimeout = 1 * time.Second
+
+func TestAggregate(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	runtimeID := common.NewTestNamespaceFromSeed([]byte("multi test ns"), 0)
+	cfg := host.Config{RuntimeID: runtimeID}
+	v1 := version.Version{Major: 1}
+	v2 := version.Version{Major: 2}
+
+	agg := New(runtimeID)
+	require.Equal(runtimeID, agg.ID(), "ID")
+
+	_, err := agg.Version()
+	require.Equal(ErrNoActiveVersion, err, "Version should fail without versions")
+	require.Equal(ErrNoActiveVersion, agg.Start(), "Start should fail without versions")
+
+	evCh, sub, err := agg.WatchEvents(ctx)
+	require.NoError(err, "WatchEvents")
+	defer sub.Close()
+
+	rt1, err := mock.New().NewRuntime(ctx, cfg)
+	require.NoError(err, "NewRuntime")
+	rt2, err := mock.New().NewRuntime(ctx, cfg)
+	require.NoError(err, "NewRuntime")
+
+	require.NoError(agg.AddVersion(v1, rt1), "AddVersion")
+	require.Equal(ErrVersionExists, agg.AddVersion(v1, rt1), "AddVersion should fail for existing version")
+	require.NoError(agg.AddVersion(v2, rt2), "AddVersion")
+	require.True(agg.HasVersion(v2), "HasVersion")
+
+	// The first added version should be active.
+	active, err := agg.Version()
+	require.NoError(err, "Version")
+	require.Equal(v1, active)
+
+	require.NoError(agg.Start(), "Start")
+	select {
+	case ev := <-evCh:
+		require.NotNil(ev.Started, "should receive started event of active version")
+	case <-time.After(recvTimeout):
+		t.Fatalf("failed to receive started event")
+	}
+
+	// Events of inactive versions should not be forwarded.
+	require.NoError(rt2.Start(), "Start")
+	select {
+	case ev := <-evCh:
+		t.Fatalf("received unexpected event: %+v", ev)
+	case <-time.After(recvTimeout):
+	}
+
+	// Switching should re-emit the started event of the new active version.
+	require.Equal(ErrNoSuchVersion, agg.SetVersion(version.Version{Major: 3}), "SetVersion should fail for unknown version")
+	require.NoError(agg.SetVersion(v2), "SetVersion")
+	select {
+	case ev := <-evCh:
+		require.NotNil(ev.Started, "should receive started event of new active version")
+	case <-time.After(recvTimeout):
+		t.Fatalf("failed to receive started event")
+	}
+
+	_, err = agg.Call(ctx, &protocol.Body{RuntimeQueryRequest: &protocol.RuntimeQueryRequest{}})
+	require.NoError(err, "Call")
+
+	require.Equal(ErrActiveVersion, agg.RemoveVersion(v2), "RemoveVersion should fail for active version")
+	require.NoError(agg.RemoveVersion(v1), "RemoveVersion")
+	require.False(agg.HasVersion(v1), "HasVersion")
+
+	agg.Stop()
+	_, err = agg.Call(ctx, &protocol.Body{RuntimeQueryRequest: &protocol.RuntimeQueryRequest{}})
+	require.Equal(ErrNoActiveVersion, err, "Call should fail after Stop")
+}
@@ -78,20 +78,28 @@ type Body struct {
 	RuntimeCheckTxBatchResponse           *RuntimeCheckTxBatchResponse           `json:",omitempty"`
 	RuntimeExecuteTxBatchRequest          *RuntimeExecuteTxBatchRequest          `json:",omitempty"`
 	RuntimeExecuteTxBatchResponse         *RuntimeExecuteTxBatchResponse         `json:",omitempty"`
+	RuntimeExecuteTxSubBatchRequest       *RuntimeExecuteTxSubBatchRequest       `json:",omitempty"`
+	RuntimeExecuteTxSubBatchResponse      *RuntimeExecuteTxSubBatchResponse      `json:",omitempty"`
+	RuntimeMergeTxBatchRequest            *RuntimeMergeTxBatchRequest            `json:",omitempty"`
+	RuntimeMergeTxBatchResponse           *RuntimeMergeTxBatchResponse           `json:",omitempty"`
+	RuntimeQueryRequest                   *RuntimeQueryRequest                   `json:",omitempty"`
+	RuntimeQueryResponse                  *RuntimeQueryResponse                  `json:",omitempty"`
 	RuntimeAbortRequest                   *Empty                                 `json:",omitempty"`
 	RuntimeAbortResponse                  *Empty                                 `json:",omitempty"`
 	RuntimeKeyManagerPolicyUpdateRequest  *RuntimeKeyManagerPolicyUpdateRequest  `json:",omitempty"`
 	RuntimeKeyManagerPolicyUpdateResponse *Empty                                 `json:",omitempty"`
 
 	// Host interface.
-	HostRPCCallRequest          *HostRPCCallRequest          `json:",omitempty"`
-	HostRPCCallResponse         *HostRPCCallResponse         `json:",omitempty"`
-	HostStorageSyncRequest      *HostStorageSyncRequest      `json:",omitempty"`
-	HostStorageSyncResponse     *HostStorageSyncResponse     `json:",omitempty"`
-	HostLocalStorageGetRequest  *HostLocalStorageGetRequest  `json:",omitempty"`
-	HostLocalStorageGetResponse *HostLocalStorageGetResponse `json:",omitempty"`
-	HostLocalStorageSetRequest  *HostLocalStorageSetRequest  `json:",omitempty"`
-	HostLocalStorageSetResponse *Empty                       `json:",omitempty"`
+	HostRPCCallRequest              *HostRPCCallRequest              `json:",omitempty"`
+	HostRPCCallResponse             *HostRPCCallResponse             `json:",omitempty"`
+	HostStorageSyncRequest          *HostStorageSyncRequest          `json:",omitempty"`
+	HostStorageSyncResponse         *HostStorageSyncResponse         `json:",omitempty"`
+	HostLocalStorageGetRequest      *HostLocalStorageGetRequest      `json:",omitempty"`
+	HostLocalStorageGetResponse     *HostLocalStorageGetResponse     `json:",omitempty"`
+	HostLocalStorageSetRequest      *HostLocalStorageSetRequest      `json:",omitempty"`
+	HostLocalStorageSetResponse     *Empty                           `json:",omitempty"`
+	HostFetchConsensusBlockRequest  *HostFetchConsensusBlockRequest  `json:",omitempty"`
+	HostFetchConsensusBlockResponse *HostFetchConsensusBlockResponse `json:",omitempty"`
 }
 
 // Type returns the message type by determining the name of the first non-nil member.
@@ -179,11 +187,19 @@ type RuntimeLocalRPCCallResponse struct {
 	Response []byte `json:"response"`
 }
 
+// ConsensusState is the consensus layer state a runtime request is based on.
+type ConsensusState struct {
+	// ConsensusStateRoot is the consensus layer state root committed in the header of the
+	// request's consensus block. It can be used to verifiably read consensus layer state.
+	ConsensusStateRoot storage.Root `json:"consensus_state_root"`
+}
+
 // RuntimeCheckTxBatchRequest is a worker check tx batch request message body.
 type RuntimeCheckTxBatchRequest struct {
 	// ConsensusBlock is the consensus light block at the last finalized round
 	// height (e.g., corresponding to .Block.Header.Round).
 	ConsensusBlock consensus.LightBlock `json:"consensus_block"`
+	ConsensusState
 
 	// Batch of runtime inputs to check.
 	Inputs transaction.RawBatch `json:"inputs"`
@@ -222,11 +238,16 @@ type RuntimeExecuteTxBatchRequest struct {
 	// ConsensusBlock is the consensus light block at the last finalized round
 	// height (e.g., corresponding to .Block.Header.Round).
 	ConsensusBlock consensus.LightBlock `json:"consensus_block"`
+	ConsensusState
 
 	// MessageResults are the results of executing messages emitted by the
 	// runtime in the previous round.
 	MessageResults []*roothash.MessageEvent `json:"message_results,omitempty"`
 
+	// IncomingMessages are the messages sent to the runtime by other runtimes that should be
+	// processed in this round, in delivery order.
+	IncomingMessages []*message.IncomingMessage `json:"incoming_messages,omitempty"`
+
 	// IORoot is the I/O root containing the inputs (transactions) that
 	// the compute node should use. It must match what is passed in "inputs".
 	IORoot hash.Hash `json:"io_root"`
@@ -239,6 +260,117 @@ type RuntimeExecuteTxBatchRequest struct {
 // RuntimeExecuteTxBatchResponse is a worker execute tx batch response message body.
 type RuntimeExecuteTxBatchResponse struct {
 	Batch ComputedBatch `json:"batch"`
+
+	// FinalizationIndependent is true iff the results do not depend on the timestamp of the block
+	// or on the consensus block and state the batch has been executed against. These are only
+	// known once the block has been finalized.
+	FinalizationIndependent bool `json:"finalization_independent,omitempty"`
+}
+
+// SubBatchHeader is the header of the results of executing a sub-batch.
+type SubBatchHeader struct {
+	// PreviousHash is the hash of the block the sub-batch was executed on.
+	PreviousHash hash.Hash `json:"previous_hash"`
+	// InputsHash is the hash of the executed inputs.
+	InputsHash hash.Hash `json:"inputs_hash"`
+	// ReadSet is the union of the read sets declared by the executed transactions.
+	ReadSet transaction.CoarsenedSet `json:"read_set"`
+	// WriteSet is the union of the write sets declared by the executed transactions.
+	WriteSet transaction.CoarsenedSet `json:"write_set"`
+	// IOWriteLogHash is the hash of the I/O write log.
+	IOWriteLogHash hash.Hash `json:"io_write_log_hash"`
+	// StateWriteLogHash is the hash of the state write log.
+	StateWriteLogHash hash.Hash `json:"state_write_log_hash"`
+}
+
+// ExecutedSubBatch is an executed sub-batch.
+type ExecutedSubBatch struct {
+	// Header is the sub-batch results header.
+	Header SubBatchHeader `json:"header"`
+	// Log of the output artifacts added to the I/O tree.
+	IOWriteLog storage.WriteLog `json:"io_write_log"`
+	// Batch of storage write operations. The operations are not committed.
+	StateWriteLog storage.WriteLog `json:"state_write_log"`
+	// If this runtime uses a TEE, then this is the signature of Header with
+	// the RAK of the runtime instance that executed the sub-batch.
+	RakSig signature.RawSignature `json:"rak_sig"`
+}
+
+// String returns a string representation of an executed sub-batch.
+func (b *ExecutedSubBatch) String() string {
+	return "<ExecutedSubBatch>"
+}
+
+// MergeSubBatch is an executed sub-batch that should be merged.
+type MergeSubBatch struct {
+	// Indices are the indices of the sub-batch inputs within the batch, in batch order.
+	Indices []uint32 `json:"indices"`
+	// Batch is the executed sub-batch.
+	Batch ExecutedSubBatch `json:"batch"`
+	// RAK is the RAK of the runtime instance that executed the sub-batch, in case the runtime
+	// uses a TEE.
+	RAK *signature.PublicKey `json:"rak,omitempty"`
+	// AVR is the attestation of the RAK, in case the runtime uses a TEE.
+	AVR *ias.AVRBundle `json:"avr,omitempty"`
+}
+
+// RuntimeExecuteTxSubBatchRequest is a worker execute tx sub-batch request message body.
+//
+// The sub-batch is executed without committing any state changes, so that the results of
+// non-conflicting sub-batches can be merged via RuntimeMergeTxBatchRequest.
+type RuntimeExecuteTxSubBatchRequest struct {
+	// ConsensusBlock is the consensus light block at the last finalized round
+	// height (e.g., corresponding to .Block.Header.Round).
+	ConsensusBlock consensus.LightBlock `json:"consensus_block"`
+	ConsensusState
+
+	// Sub-batch of inputs (transactions).
+	Inputs transaction.RawBatch `json:"inputs"`
+	// Block on which the sub-batch computation should be based.
+	Block block.Block `json:"block"`
+}
+
+// RuntimeExecuteTxSubBatchResponse is a worker execute tx sub-batch response message body.
+type RuntimeExecuteTxSubBatchResponse struct {
+	Batch ExecutedSubBatch `json:"batch"`
+}
+
+// RuntimeMergeTxBatchRequest is a worker merge tx batch request message body.
+type RuntimeMergeTxBatchRequest struct {
+	// IORoot is the I/O root containing the inputs (transactions). It must
+	// match what is passed in "inputs".
+	IORoot hash.Hash `json:"io_root"`
+	// Batch of inputs (transactions).
+	Inputs transaction.RawBatch `json:"inputs"`
+	// Block on which the batch computation should be based.
+	Block block.Block `json:"block"`
+	// SubBatches are the executed sub-batches covering the whole batch.
+	SubBatches []MergeSubBatch `json:"sub_batches"`
+}
+
+// RuntimeMergeTxBatchResponse is a worker merge tx batch response message body.
+type RuntimeMergeTxBatchResponse struct {
+	Batch ComputedBatch `json:"batch"`
+}
+
+// RuntimeQueryRequest is a runtime query request message body.
+type RuntimeQueryRequest struct {
+	// ConsensusBlock is the consensus light block at the latest height known to the host.
+	ConsensusBlock consensus.LightBlock `json:"consensus_block"`
+	ConsensusState
+
+	// Method is the name of the queried runtime method.
+	Method string `json:"method"`
+	// Args are the CBOR-encoded method arguments.
+	Args []byte `json:"args"`
+	// Block is the finalized block against whose state the query should be executed.
+	Block block.Block `json:"block"`
+}
+
+// RuntimeQueryResponse is a runtime query response message body.
+type RuntimeQueryResponse struct {
+	// Data is the CBOR-encoded query output (a transaction.TxnOutput).
+	Data []byte `json:"data"`
 }
 
 // RuntimeKeyManagerPolicyUpdateRequest is a runtime key manager policy request
@@ -298,3 +430,13 @@ type HostLocalStorageSetRequest struct {
 	Key   []byte `json:"key"`
 	Value []byte `json:"value"`
 }
+
+// HostFetchConsensusBlockRequest is a request to host to fetch the given consensus light block.
+type HostFetchConsensusBlockRequest struct {
+	Height uint64 `json:"height"`
+}
+
+// HostFetchConsensusBlockResponse is a response from host fetching the given consensus light block.
+type HostFetchConsensusBlockResponse struct {
+	Block consensus.LightBlock `json:"block"`
+}
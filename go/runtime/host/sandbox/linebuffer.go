@@ -0,0 +1,74 @@
+package sandbox
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxLineLength is the maximum length of a captured line. Longer lines are split.
+const maxLineLength = 4096
+
+// lineBuffer is an io.Writer that keeps the last written lines.
+type lineBuffer struct {
+	sync.Mutex
+
+	maxLines int
+	lines    []string
+	partial  []byte
+}
+
+// Write implements io.Writer.
+func (b *lineBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.partial = append(b.partial, p...)
+	for {
+		idx := bytes.IndexByte(b.partial, '\n')
+		if idx < 0 {
+			break
+		}
+		b.addLine(string(b.partial[:idx]))
+		b.partial = b.partial[idx+1:]
+	}
+	for len(b.partial) > maxLineLength {
+		b.addLine(string(b.partial[:maxLineLength]))
+		b.partial = b.partial[maxLineLength:]
+	}
+	return len(p), nil
+}
+
+func (b *lineBuffer) addLine(line string) {
+	b.lines = append(b.lines, line)
+	if len(b.lines) > b.maxLines {
+		b.lines = append([]string{}, b.lines[len(b.lines)-b.maxLines:]...)
+	}
+}
+
+// Lines returns the last written lines, including any incomplete last line.
+func (b *lineBuffer) Lines() []string {
+	b.Lock()
+	defer b.Unlock()
+
+	lines := append([]string{}, b.lines...)
+	if len(b.partial) > 0 {
+		lines = append(lines, string(b.partial))
+	}
+	if len(lines) > b.maxLines {
+		lines = lines[len(lines)-b.maxLines:]
+	}
+	return lines
+}
+
+// Reset discards all captured lines.
+func (b *lineBuffer) Reset() {
+	b.Lock()
+	defer b.Unlock()
+
+	b.lines = nil
+	b.partial = nil
+}
+
+func newLineBuffer(maxLines int) *lineBuffer {
+	return &lineBuffer{maxLines: maxLines}
+}
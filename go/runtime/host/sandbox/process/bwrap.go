@@ -174,6 +174,17 @@ func NewBubbleWrap(cfg Config) (Process, error) {
 		return nil, err
 	}
 
+	// Enforce resource limits. This must happen before the sandbox is configured as the sandbox
+	// only spawns the entrypoint binary afterwards, ensuring that all limits are inherited.
+	if !cfg.Limits.IsEmpty() {
+		var cg *cgroup
+		if cg, err = applyLimits(n.GetPID(), cfg.Limits); err != nil {
+			n.Kill()
+			return nil, fmt.Errorf("sandbox: failed to enforce resource limits: %w", err)
+		}
+		n.(*naked).setCgroup(cg)
+	}
+
 	// Send configuration arguments.
 	for _, arg := range fdArgs {
 		if _, err = fdArgsPipe.Write([]byte(arg + "\x00")); err != nil {
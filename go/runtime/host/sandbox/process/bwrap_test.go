@@ -6,4 +6,7 @@ func TestBubbleWrapSandbox(t *testing.T) {
 	t.Run("BindData", func(t *testing.T) {
 		testBindData(t, NewBubbleWrap, "/usr/bin/bwrap")
 	})
+	t.Run("Usage", func(t *testing.T) {
+		testUsage(t, NewBubbleWrap, "/usr/bin/bwrap")
+	})
 }
@@ -0,0 +1,253 @@
+// +build linux
+
+package process
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/procfs"
+)
+
+const (
+	cgroupRoot = "/sys/fs/cgroup"
+
+	// cgroupCPUPeriod is the CPU bandwidth enforcement period (in microseconds).
+	cgroupCPUPeriod = 100000
+
+	cgroupRemoveRetries = 50
+	cgroupRemoveBackoff = 10 * time.Millisecond
+
+	// nodeCgroupName is the name of the leaf control group the node process is moved into.
+	nodeCgroupName = "oasis-node"
+
+	// clockTicks is the number of clock ticks per second (getconf CLK_TCK).
+	clockTicks = 100
+)
+
+// cgroup is a cgroup v2 control group used for enforcing resource limits and for resource
+// accounting of a sandboxed process.
+type cgroup struct {
+	path string
+}
+
+func (cg *cgroup) writeFile(name, value string) error {
+	return ioutil.WriteFile(filepath.Join(cg.path, name), []byte(value), 0o600)
+}
+
+func (cg *cgroup) readStat(name string) (map[string]uint64, error) {
+	f, err := os.Open(filepath.Join(cg.path, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stat := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		stat[fields[0]] = value
+	}
+	return stat, scanner.Err()
+}
+
+func (cg *cgroup) usage() (*Usage, error) {
+	cpuStat, err := cg.readStat("cpu.stat")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cgroup CPU statistics: %w", err)
+	}
+	memStat, err := cg.readStat("memory.stat")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cgroup memory statistics: %w", err)
+	}
+
+	return &Usage{
+		CPUTime:  time.Duration(cpuStat["usage_usec"]) * time.Microsecond,
+		RSSBytes: memStat["anon"] + memStat["file_mapped"],
+	}, nil
+}
+
+func (cg *cgroup) remove() {
+	// Make sure that no processes remain in the control group (supported since Linux 5.14).
+	_ = cg.writeFile("cgroup.kill", "1")
+
+	// Processes may take a while to exit after being killed.
+	for i := 0; i < cgroupRemoveRetries; i++ {
+		if err := os.Remove(cg.path); err == nil || os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(cgroupRemoveBackoff)
+	}
+}
+
+// ownCgroup returns the path of the cgroup v2 control group of the current process.
+func ownCgroup() (string, error) {
+	data, err := ioutil.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "0::") {
+			return filepath.Join(cgroupRoot, strings.TrimPrefix(line, "0::")), nil
+		}
+	}
+	return "", fmt.Errorf("not running in a cgroup v2 hierarchy")
+}
+
+var (
+	cgroupParentOnce sync.Once
+	cgroupParent     string
+	cgroupParentErr  error
+)
+
+// setupCgroupParent prepares the control group of the current process to be the parent of the
+// runtime control groups and returns its path.
+//
+// Controllers can only be enabled for child control groups of a control group without processes,
+// so the node process is first moved into a leaf control group. The control group of the node
+// therefore needs to be delegated to the user running the node and must not contain any other
+// processes.
+func setupCgroupParent() (string, error) {
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		return "", fmt.Errorf("cgroup v2 not available: %w", err)
+	}
+	parentPath, err := ownCgroup()
+	if err != nil {
+		return "", err
+	}
+
+	leaf := &cgroup{path: filepath.Join(parentPath, nodeCgroupName)}
+	if err = os.Mkdir(leaf.path, 0o755); err != nil && !os.IsExist(err) {
+		return "", fmt.Errorf("failed to create node cgroup: %w", err)
+	}
+	if err = leaf.writeFile("cgroup.procs", strconv.Itoa(os.Getpid())); err != nil {
+		return "", fmt.Errorf("failed to move node into its own cgroup: %w", err)
+	}
+
+	// Enable the required controllers for child control groups.
+	parent := &cgroup{path: parentPath}
+	if err = parent.writeFile("cgroup.subtree_control", "+cpu +memory +pids"); err != nil {
+		return "", fmt.Errorf("failed to enable cgroup controllers (cgroup %s must be delegated and must not contain other processes): %w",
+			parentPath,
+			err,
+		)
+	}
+	return parentPath, nil
+}
+
+// newCgroup creates a new cgroup v2 control group enforcing the given limits and moves the given
+// process into it.
+//
+// The control group is created as a sibling of the leaf control group of the node process, see
+// setupCgroupParent for the requirements.
+func newCgroup(pid int, limits Limits) (*cgroup, error) {
+	cgroupParentOnce.Do(func() {
+		cgroupParent, cgroupParentErr = setupCgroupParent()
+	})
+	if cgroupParentErr != nil {
+		return nil, cgroupParentErr
+	}
+
+	cg := &cgroup{path: filepath.Join(cgroupParent, fmt.Sprintf("oasis-runtime-%d", pid))}
+	if err := os.Mkdir(cg.path, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cgroup: %w", err)
+	}
+	if err := cg.configure(pid, limits); err != nil {
+		cg.remove()
+		return nil, err
+	}
+	return cg, nil
+}
+
+func (cg *cgroup) configure(pid int, limits Limits) error {
+	if limits.MemoryBytes > 0 {
+		if err := cg.writeFile("memory.max", strconv.FormatUint(limits.MemoryBytes, 10)); err != nil {
+			return fmt.Errorf("failed to set cgroup memory limit: %w", err)
+		}
+	}
+	if limits.CPUs > 0 {
+		quota := uint64(limits.CPUs * cgroupCPUPeriod)
+		if err := cg.writeFile("cpu.max", fmt.Sprintf("%d %d", quota, cgroupCPUPeriod)); err != nil {
+			return fmt.Errorf("failed to set cgroup CPU limit: %w", err)
+		}
+	}
+	if limits.Pids > 0 {
+		if err := cg.writeFile("pids.max", strconv.FormatUint(limits.Pids, 10)); err != nil {
+			return fmt.Errorf("failed to set cgroup process limit: %w", err)
+		}
+	}
+	if err := cg.writeFile("cgroup.procs", strconv.Itoa(pid)); err != nil {
+		return fmt.Errorf("failed to move process into cgroup: %w", err)
+	}
+	return nil
+}
+
+// applyLimits enforces the given limits on the given process via a new cgroup v2 control group.
+//
+// Resource limits are no substitute as they cannot limit CPU bandwidth, limiting the address
+// space breaks SGX enclaves and the process limit would apply to all processes of the user
+// running the node. So in case cgroup v2 is not available an error is returned instead of
+// silently not enforcing some of the limits.
+//
+// The process must not yet have spawned any children for the limits to apply to them.
+func applyLimits(pid int, limits Limits) (*cgroup, error) {
+	cg, err := newCgroup(pid, limits)
+	if err != nil {
+		return nil, fmt.Errorf("resource limits require cgroup v2: %w", err)
+	}
+	return cg, nil
+}
+
+// processTreeUsage returns the resource usage of the given process and all of its descendants
+// as reported by procfs.
+func processTreeUsage(pid int) (*Usage, error) {
+	procs, err := procfs.AllProcs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	children := make(map[int][]int)
+	stats := make(map[int]procfs.ProcStat)
+	for _, proc := range procs {
+		stat, serr := proc.Stat()
+		if serr != nil {
+			// Process may have exited in the meantime.
+			continue
+		}
+		stats[proc.PID] = stat
+		children[stat.PPID] = append(children[stat.PPID], proc.PID)
+	}
+	if _, ok := stats[pid]; !ok {
+		return nil, fmt.Errorf("process %d not found", pid)
+	}
+
+	var (
+		usage Usage
+		ticks uint
+	)
+	pending := []int{pid}
+	for len(pending) > 0 {
+		p := pending[0]
+		pending = append(pending[1:], children[p]...)
+
+		stat := stats[p]
+		ticks += stat.UTime + stat.STime
+		usage.RSSBytes += uint64(stat.ResidentMemory())
+	}
+	usage.CPUTime = time.Duration(ticks) * time.Second / clockTicks
+	return &usage, nil
+}
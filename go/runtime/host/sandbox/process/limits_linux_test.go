@@ -0,0 +1,79 @@
+// +build linux
+
+package process
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// startLimited starts the given shell script which waits for a line on its standard input before
+// proceeding, so that limits are applied before it spawns any children.
+func startLimited(t *testing.T, script string, limits Limits) (*exec.Cmd, *cgroup, *bytes.Buffer, error) {
+	require := require.New(t)
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("/bin/sh", "-c", "read x; "+script)
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	require.NoError(err, "StdinPipe")
+	require.NoError(cmd.Start(), "Start")
+
+	cg, err := applyLimits(cmd.Process.Pid, limits)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, nil, nil, err
+	}
+	t.Cleanup(cg.remove)
+
+	_, err = stdin.Write([]byte("\n"))
+	require.NoError(err, "Write")
+	require.NoError(stdin.Close(), "Close")
+	return cmd, cg, &stderr, nil
+}
+
+func TestLimits(t *testing.T) {
+	if _, err := setupCgroupParent(); err != nil {
+		// Limits must not be silently ignored when they cannot be enforced.
+		_, _, _, err = startLimited(t, "true", Limits{Pids: 16})
+		require.Error(t, err, "applyLimits should fail without cgroup v2")
+		t.Skipf("cgroup v2 not usable: %s", err)
+	}
+
+	t.Run("Pids", func(t *testing.T) {
+		require := require.New(t)
+
+		cmd, _, stderr, err := startLimited(t, "for i in 1 2 3 4; do sleep 1 & done; wait", Limits{Pids: 2})
+		require.NoError(err, "applyLimits")
+		err = cmd.Wait()
+		require.True(err != nil || stderr.Len() > 0, "spawning processes over the limit should fail")
+	})
+
+	t.Run("Memory", func(t *testing.T) {
+		require := require.New(t)
+
+		cmd, _, _, err := startLimited(t, "head -c 256M /dev/zero | tail > /dev/null", Limits{MemoryBytes: 16 << 20})
+		require.NoError(err, "applyLimits")
+		err = cmd.Wait()
+		require.Error(err, "exceeding the memory limit should kill the process")
+	})
+
+	t.Run("CPU", func(t *testing.T) {
+		require := require.New(t)
+
+		cmd, cg, _, err := startLimited(t, "true", Limits{CPUs: 0.5})
+		require.NoError(err, "applyLimits")
+		require.NoError(cmd.Wait(), "Wait")
+
+		data, err := ioutil.ReadFile(filepath.Join(cg.path, "cpu.max"))
+		require.NoError(err, "ReadFile")
+		require.EqualValues("50000 100000", strings.TrimSpace(string(data)), "CPU bandwidth should be limited")
+	})
+}
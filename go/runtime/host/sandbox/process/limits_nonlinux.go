@@ -0,0 +1,24 @@
+// +build !linux
+
+package process
+
+import (
+	"errors"
+)
+
+type cgroup struct{}
+
+func (cg *cgroup) usage() (*Usage, error) {
+	return nil, errors.New("cgroups only implemented for Linux")
+}
+
+func (cg *cgroup) remove() {
+}
+
+func applyLimits(pid int, limits Limits) (*cgroup, error) {
+	return nil, errors.New("applyLimits only implemented for Linux")
+}
+
+func processTreeUsage(pid int) (*Usage, error) {
+	return nil, errors.New("processTreeUsage only implemented for Linux")
+}
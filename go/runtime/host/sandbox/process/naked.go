@@ -15,6 +15,9 @@ type naked struct {
 
 	cmd *exec.Cmd
 
+	// cgroup is the control group enforcing resource limits (if any).
+	cgroup *cgroup
+
 	err    error
 	waitCh chan struct{}
 }
@@ -48,6 +51,24 @@ func (n *naked) Kill() {
 	_ = syscall.Kill(-n.cmd.Process.Pid, syscall.SIGKILL)
 }
 
+// Implements Process.
+func (n *naked) Usage() (*Usage, error) {
+	n.Lock()
+	cg := n.cgroup
+	n.Unlock()
+
+	if cg != nil {
+		return cg.usage()
+	}
+	return processTreeUsage(n.GetPID())
+}
+
+func (n *naked) setCgroup(cg *cgroup) {
+	n.Lock()
+	n.cgroup = cg
+	n.Unlock()
+}
+
 func (n *naked) wait() error {
 	err := n.cmd.Wait()
 	if err != nil {
@@ -121,8 +142,14 @@ func NewNaked(cfg Config) (Process, error) {
 
 		n.Lock()
 		n.err = err
+		cg := n.cgroup
 		n.Unlock()
 
+		// Remove the control group once the process has terminated.
+		if cg != nil {
+			cg.remove()
+		}
+
 		close(n.waitCh)
 	}()
 
@@ -15,6 +15,9 @@ func TestNakedSandbox(t *testing.T) {
 	t.Run("BindData", func(t *testing.T) {
 		testBindData(t, NewNaked, "")
 	})
+	t.Run("Usage", func(t *testing.T) {
+		testUsage(t, NewNaked, "")
+	})
 }
 
 func testBindData(t *testing.T, factory func(Config) (Process, error), sandboxBinary string) {
@@ -47,3 +50,19 @@ func testBindData(t *testing.T, factory func(Config) (Process, error), sandboxBi
 	// Make sure output was correct.
 	require.EqualValues("hello world", stdout.Bytes())
 }
+
+func testUsage(t *testing.T, factory func(Config) (Process, error), sandboxBinary string) {
+	require := require.New(t)
+
+	p, err := factory(Config{
+		Path:              "/bin/sleep",
+		Args:              []string{"10"},
+		SandboxBinaryPath: sandboxBinary,
+	})
+	require.NoError(err, "factory")
+	defer p.Kill()
+
+	usage, err := p.Usage()
+	require.NoError(err, "Usage")
+	require.NotZero(usage.RSSBytes, "process should use some memory")
+}
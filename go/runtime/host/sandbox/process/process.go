@@ -4,6 +4,7 @@ package process
 import (
 	"io"
 	"os"
+	"time"
 )
 
 // Config contains the sandbox configuration.
@@ -42,9 +43,39 @@ type Config struct {
 	// SandboxBinaryPath is the path to the sandbox support binary.
 	SandboxBinaryPath string
 
+	// Limits are the resource limits that should be enforced for the sandbox.
+	Limits Limits
+
 	extraFiles []*os.File
 }
 
+// Limits are the resource limits enforced for a sandboxed process. Zero values mean that the
+// given resource is not limited.
+type Limits struct {
+	// MemoryBytes is the maximum amount of memory (in bytes) that can be used.
+	MemoryBytes uint64
+
+	// CPUs is the maximum number of CPUs that can be used (e.g., 1.5 means one and a half CPUs).
+	CPUs float64
+
+	// Pids is the maximum number of processes that can be created.
+	Pids uint64
+}
+
+// IsEmpty returns true iff no resource limits are configured.
+func (l Limits) IsEmpty() bool {
+	return l.MemoryBytes == 0 && l.CPUs == 0 && l.Pids == 0
+}
+
+// Usage is the resource usage of a sandboxed process (including all of its descendants).
+type Usage struct {
+	// CPUTime is the total CPU time consumed.
+	CPUTime time.Duration
+
+	// RSSBytes is the resident set size (in bytes).
+	RSSBytes uint64
+}
+
 // Process is a sandboxed process.
 type Process interface {
 	// GetPID returns the process identifier of the sandbox running the given process.
@@ -60,4 +91,7 @@ type Process interface {
 
 	// Kill causes the sandboxed process to exit immediately.
 	Kill()
+
+	// Usage returns the current resource usage of the process.
+	Usage() (*Usage, error)
 }
@@ -4,6 +4,7 @@ package sandbox
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
@@ -31,6 +33,52 @@ const (
 	bindHostSocketPath = "/host.sock"
 
 	ctrlChannelBufferSize = 16
+
+	// stderrCaptureLines is the number of last standard error lines captured for crash reports.
+	stderrCaptureLines = 32
+
+	// usageUpdateInterval is the interval at which resource usage metrics are updated.
+	usageUpdateInterval = 10 * time.Second
+)
+
+var (
+	runtimeCPUTime = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "oasis_runtime_cpu_time_seconds",
+			Help: "CPU time consumed by the current runtime instance (seconds).",
+		},
+		[]string{"runtime"},
+	)
+	runtimeRSS = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "oasis_runtime_rss_bytes",
+			Help: "Resident set size of the current runtime instance (bytes).",
+		},
+		[]string{"runtime"},
+	)
+	runtimeRestarts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_runtime_restarts",
+			Help: "Number of runtime restarts.",
+		},
+		[]string{"runtime"},
+	)
+	runtimeCrashes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_runtime_crashes",
+			Help: "Number of runtime crashes (including failures to start).",
+		},
+		[]string{"runtime"},
+	)
+
+	sandboxCollectors = []prometheus.Collector{
+		runtimeCPUTime,
+		runtimeRSS,
+		runtimeRestarts,
+		runtimeCrashes,
+	}
+
+	metricsOnce sync.Once
 )
 
 // Config contains the sandbox provisioner configuration options.
@@ -55,6 +103,44 @@ type Config struct {
 
 	// InsecureNoSandbox disables the sandbox and runs the runtime binary directly.
 	InsecureNoSandbox bool
+
+	// RestartPolicy is the policy for restarting runtimes that have crashed or failed to start.
+	// In case it is not specified, DefaultRestartPolicy is used.
+	RestartPolicy *RestartPolicy
+}
+
+// RestartPolicy is the policy for restarting runtimes that have crashed or failed to start.
+type RestartPolicy struct {
+	// InitialBackoff is the delay before restarting a runtime after its first crash. The delay is
+	// doubled for each subsequent crash within the crash loop detection window.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the maximum delay before restarting a crashed runtime.
+	MaxBackoff time.Duration
+
+	// CrashLoopThreshold is the number of crashes within the crash loop detection window after
+	// which the runtime is considered to be crash looping.
+	CrashLoopThreshold int
+
+	// CrashLoopWindow is the crash loop detection window.
+	CrashLoopWindow time.Duration
+
+	// CrashLoopCooldown is the delay before restarting a crash looping runtime.
+	CrashLoopCooldown time.Duration
+
+	// MinUptime is the time the runtime must stay up after being started before its crash history
+	// is reset.
+	MinUptime time.Duration
+}
+
+// DefaultRestartPolicy is the default restart policy.
+var DefaultRestartPolicy = RestartPolicy{
+	InitialBackoff:     1 * time.Second,
+	MaxBackoff:         1 * time.Minute,
+	CrashLoopThreshold: 5,
+	CrashLoopWindow:    10 * time.Minute,
+	CrashLoopCooldown:  10 * time.Minute,
+	MinUptime:          1 * time.Minute,
 }
 
 type provisioner struct {
@@ -70,6 +156,7 @@ func (p *provisioner) NewRuntime(ctx context.Context, cfg host.Config) (host.Run
 		quitCh:   make(chan struct{}),
 		ctrlCh:   make(chan interface{}, ctrlChannelBufferSize),
 		notifier: pubsub.NewBroker(false),
+		stderr:   newLineBuffer(stderrCaptureLines),
 		logger:   p.cfg.Logger.With("runtime_id", cfg.RuntimeID),
 	}
 	return r, nil
@@ -97,6 +184,19 @@ type sandboxedRuntime struct {
 	conn     protocol.Connection
 	notifier *pubsub.Broker
 
+	// launched is the number of times the runtime process has been successfully started.
+	launched uint64
+
+	// stderr captures the last lines written to standard error by the runtime process.
+	stderr *lineBuffer
+	// startedAt is the time the runtime process was last started successfully. It is reset when
+	// the runtime crashes.
+	startedAt time.Time
+	// recentCrashes are the times of crashes within the crash loop detection window since the
+	// runtime last stayed up for at least the minimum uptime.
+	recentCrashes []time.Time
+	crashStatus   host.CrashStatus
+
 	logger *logging.Logger
 }
 
@@ -176,6 +276,65 @@ func (r *sandboxedRuntime) Stop() {
 	close(r.stopCh)
 }
 
+// Implements host.ResourceUsageReporter.
+func (r *sandboxedRuntime) GetResourceUsage() (*host.ResourceUsage, error) {
+	r.RLock()
+	p := r.process
+	launched := r.launched
+	r.RUnlock()
+
+	var usage host.ResourceUsage
+	if launched > 0 {
+		usage.Restarts = launched - 1
+	}
+	if p == nil {
+		return &usage, nil
+	}
+
+	pu, err := p.Usage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get process resource usage: %w", err)
+	}
+	usage.CPUTime = pu.CPUTime
+	usage.RSSBytes = pu.RSSBytes
+	return &usage, nil
+}
+
+// Implements host.CrashStatusReporter.
+func (r *sandboxedRuntime) GetCrashStatus() (*host.CrashStatus, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	status := r.crashStatus
+	status.LastStderr = append([]string{}, r.crashStatus.LastStderr...)
+	return &status, nil
+}
+
+// captureStderr configures the process to additionally write its standard error to the stderr
+// capture buffer.
+func (r *sandboxedRuntime) captureStderr(cfg *process.Config) {
+	stderr := cfg.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	r.stderr.Reset()
+	cfg.Stderr = io.MultiWriter(stderr, r.stderr)
+}
+
+func (r *sandboxedRuntime) updateUsageMetrics() {
+	usage, err := r.GetResourceUsage()
+	if err != nil {
+		r.logger.Debug("failed to get runtime resource usage",
+			"err", err,
+		)
+		return
+	}
+
+	labels := prometheus.Labels{"runtime": r.rtCfg.RuntimeID.String()}
+	runtimeCPUTime.With(labels).Set(usage.CPUTime.Seconds())
+	runtimeRSS.With(labels).Set(float64(usage.RSSBytes))
+}
+
 // Implements host.EmitEvent.
 func (r *sandboxedRuntime) EmitEvent(ev *host.Event) {
 	r.notifier.Broadcast(ev)
@@ -221,6 +380,10 @@ func (r *sandboxedRuntime) startProcess() (err error) {
 		if cErr != nil {
 			return fmt.Errorf("failed to configure process: %w", cErr)
 		}
+		if r.rtCfg.Limits != (host.ResourceLimits{}) {
+			r.logger.Warn("resource limits are not enforced for UNSANDBOXED runtimes")
+		}
+		r.captureStderr(&cfg)
 
 		p, err = process.NewNaked(cfg)
 		if err != nil {
@@ -237,6 +400,12 @@ func (r *sandboxedRuntime) startProcess() (err error) {
 			cfg.BindRW = make(map[string]string)
 		}
 		cfg.BindRW[hostSocket] = bindHostSocketPath
+		cfg.Limits = process.Limits{
+			MemoryBytes: r.rtCfg.Limits.MemoryBytes,
+			CPUs:        r.rtCfg.Limits.CPUs,
+			Pids:        r.rtCfg.Limits.Pids,
+		}
+		r.captureStderr(&cfg)
 
 		p, err = process.NewBubbleWrap(cfg)
 		if err != nil {
@@ -286,6 +455,9 @@ func (r *sandboxedRuntime) startProcess() (err error) {
 			"err", p.Error(),
 		)
 
+		if perr := p.Error(); perr != nil {
+			return fmt.Errorf("terminated while waiting for runtime to connect: %w", perr)
+		}
 		return fmt.Errorf("terminated while waiting for runtime to connect")
 	}
 
@@ -333,8 +505,21 @@ func (r *sandboxedRuntime) startProcess() (err error) {
 	}
 
 	ok = true
+	r.Lock()
 	r.process = p
 	r.conn = pc
+	r.launched++
+	restarted := r.launched > 1
+	// The crash history is only reset once the runtime has stayed up for the minimum uptime (see
+	// handleCrash) as otherwise a runtime crashing shortly after each start would never be
+	// detected as crash looping.
+	r.startedAt = time.Now()
+	r.crashStatus.CrashLoop = false
+	r.Unlock()
+
+	if restarted {
+		runtimeRestarts.With(prometheus.Labels{"runtime": r.rtCfg.RuntimeID.String()}).Inc()
+	}
 
 	// Notify subscribers that a runtime has been started.
 	r.notifier.Broadcast(&host.Event{Started: ev})
@@ -385,29 +570,97 @@ func (r *sandboxedRuntime) handleAbortRequest(rq *abortRequest) error {
 	return nil
 }
 
+// handleCrash records a runtime crash (or failure to start) and returns the delay after which
+// the runtime should be restarted based on the configured restart policy.
+func (r *sandboxedRuntime) handleCrash(err error) time.Duration {
+	policy := r.cfg.RestartPolicy
+	now := time.Now()
+
+	r.Lock()
+	// Forget earlier crashes in case the runtime stayed up long enough since it was last started.
+	if !r.startedAt.IsZero() && now.Sub(r.startedAt) >= policy.MinUptime {
+		r.recentCrashes = nil
+	}
+	r.startedAt = time.Time{}
+
+	// Only consider crashes within the crash loop detection window.
+	recent := r.recentCrashes[:0]
+	for _, t := range r.recentCrashes {
+		if now.Sub(t) < policy.CrashLoopWindow {
+			recent = append(recent, t)
+		}
+	}
+	r.recentCrashes = append(recent, now)
+	crashes := len(r.recentCrashes)
+
+	r.crashStatus.Crashes++
+	r.crashStatus.LastCrashTime = now
+	r.crashStatus.LastExitStatus = err.Error()
+	r.crashStatus.LastStderr = r.stderr.Lines()
+	r.crashStatus.CrashLoop = crashes >= policy.CrashLoopThreshold
+	crashLoop := r.crashStatus.CrashLoop
+	r.Unlock()
+
+	runtimeCrashes.With(prometheus.Labels{"runtime": r.rtCfg.RuntimeID.String()}).Inc()
+
+	if crashLoop {
+		r.logger.Error("runtime is crash looping, suspending restarts",
+			"crashes", crashes,
+			"window", policy.CrashLoopWindow,
+			"cooldown", policy.CrashLoopCooldown,
+		)
+
+		// Notify subscribers that the runtime is crash looping.
+		r.notifier.Broadcast(&host.Event{
+			CrashLoop: &host.CrashLoopEvent{
+				Crashes:        crashes,
+				LastExitStatus: err.Error(),
+			},
+		})
+		return policy.CrashLoopCooldown
+	}
+
+	delay := policy.InitialBackoff
+	for i := 1; i < crashes && delay < policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+	return delay
+}
+
 func (r *sandboxedRuntime) manager() {
-	// Initialize a ticker channel for restarting the process. Initialize it with a closed channel
-	// so that the first time, the process will be restarted immediately.
-	var ticker *backoff.Ticker
-	var tickerCh <-chan time.Time
-	ch := make(chan time.Time)
-	tickerCh = ch
-	close(ch)
+	// Initialize a channel for restarting the process. Initialize it with a closed channel so that
+	// the first time, the process will be started immediately.
+	var restartTimer *time.Timer
+	immediateCh := make(chan time.Time)
+	close(immediateCh)
+	restartCh := (<-chan time.Time)(immediateCh)
+
+	scheduleRestart := func(delay time.Duration) {
+		r.logger.Info("scheduling runtime restart",
+			"delay", delay,
+		)
+
+		restartTimer = time.NewTimer(delay)
+		restartCh = restartTimer.C
+	}
 
 	defer func() {
 		r.logger.Warn("terminating runtime")
 
-		if ticker != nil {
-			ticker.Stop()
-			ticker = nil
+		if restartTimer != nil {
+			restartTimer.Stop()
+			restartTimer = nil
 		}
 		if r.process != nil {
 			r.conn.Close()
 			r.process.Kill()
 			<-r.process.Wait()
-			r.process = nil
 
 			r.Lock()
+			r.process = nil
 			r.conn = nil
 			r.Unlock()
 
@@ -418,6 +671,9 @@ func (r *sandboxedRuntime) manager() {
 		close(r.quitCh)
 	}()
 
+	usageTicker := time.NewTicker(usageUpdateInterval)
+	defer usageTicker.Stop()
+
 	var attempt int
 	for {
 		// Make sure to restart the process if terminated.
@@ -426,7 +682,7 @@ func (r *sandboxedRuntime) manager() {
 			case <-r.stopCh:
 				r.logger.Warn("termination requested")
 				return
-			case <-tickerCh:
+			case <-restartCh:
 				attempt++
 				r.logger.Info("starting runtime",
 					"attempt", attempt,
@@ -444,18 +700,14 @@ func (r *sandboxedRuntime) manager() {
 						},
 					})
 
-					if ticker == nil {
-						ticker = backoff.NewTicker(backoff.NewExponentialBackOff())
-						tickerCh = ticker.C
-					}
+					scheduleRestart(r.handleCrash(err))
 					continue
 				}
 
-				// Runtime started successfully.
-				if ticker != nil {
-					ticker.Stop()
-					ticker = nil
-				}
+				// Runtime started successfully. Any subsequent restarts that are not caused by
+				// crashes (e.g., due to abort requests) should happen immediately.
+				restartTimer = nil
+				restartCh = immediateCh
 				attempt = 0
 			}
 		}
@@ -474,13 +726,19 @@ func (r *sandboxedRuntime) manager() {
 				)
 				continue
 			}
+		case <-usageTicker.C:
+			r.updateUsageMetrics()
 		case <-r.stopCh:
 			r.logger.Warn("termination requested")
 			return
 		case <-r.process.Wait():
 			// Process has terminated.
+			err := r.process.Error()
+			if err == nil {
+				err = fmt.Errorf("process exited")
+			}
 			r.logger.Error("runtime process has terminated unexpectedly",
-				"err", r.process.Error(),
+				"err", err,
 			)
 
 			r.Lock()
@@ -491,6 +749,8 @@ func (r *sandboxedRuntime) manager() {
 
 			// Notify subscribers that the runtime has stopped.
 			r.notifier.Broadcast(&host.Event{Stopped: &host.StoppedEvent{}})
+
+			scheduleRestart(r.handleCrash(err))
 			continue
 		}
 	}
@@ -532,5 +792,16 @@ func New(cfg Config) (host.Provisioner, error) {
 	if cfg.Logger == nil {
 		cfg.Logger = logging.GetLogger("runtime/host/sandbox")
 	}
+
+	// Use a default RestartPolicy if none was provided.
+	if cfg.RestartPolicy == nil {
+		policy := DefaultRestartPolicy
+		cfg.RestartPolicy = &policy
+	}
+
+	metricsOnce.Do(func() {
+		prometheus.MustRegister(sandboxCollectors...)
+	})
+
 	return &provisioner{cfg: cfg}, nil
 }
@@ -1,11 +1,19 @@
 package sandbox
 
 import (
+	"context"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/sandbox/process"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host/tests"
 )
 
@@ -42,3 +50,120 @@ func TestProvisionerSandbox(t *testing.T) {
 		}, nil)
 	})
 }
+
+func TestCrashLoop(t *testing.T) {
+	require := require.New(t)
+
+	p, err := New(Config{
+		GetSandboxConfig: func(cfg host.Config, socketPath, runtimeDir string) (process.Config, error) {
+			return process.Config{
+				Path:   "/bin/sh",
+				Args:   []string{"-c", "echo first line >&2; echo last line >&2; exit 3"},
+				Stderr: ioutil.Discard,
+			}, nil
+		},
+		HostInfo:          &protocol.HostInfo{},
+		InsecureNoSandbox: true,
+		RestartPolicy: &RestartPolicy{
+			InitialBackoff:     10 * time.Millisecond,
+			MaxBackoff:         50 * time.Millisecond,
+			CrashLoopThreshold: 3,
+			CrashLoopWindow:    time.Minute,
+			CrashLoopCooldown:  time.Hour,
+		},
+	})
+	require.NoError(err, "New")
+
+	rt, err := p.NewRuntime(context.Background(), host.Config{
+		RuntimeID: common.NewTestNamespaceFromSeed([]byte("sandbox crash loop test"), 0),
+	})
+	require.NoError(err, "NewRuntime")
+
+	evCh, sub, err := rt.WatchEvents(context.Background())
+	require.NoError(err, "WatchEvents")
+	defer sub.Close()
+
+	require.NoError(rt.Start(), "Start")
+	defer rt.Stop()
+
+	var failures int
+	for {
+		select {
+		case ev := <-evCh:
+			if ev.FailedToStart != nil {
+				failures++
+				continue
+			}
+			require.NotNil(ev.CrashLoop, "unexpected event: %+v", ev)
+			require.Equal(3, ev.CrashLoop.Crashes)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("failed to receive crash loop event")
+		}
+		break
+	}
+	require.Equal(3, failures, "runtime should fail to start before crash loop is detected")
+
+	status, err := rt.(host.CrashStatusReporter).GetCrashStatus()
+	require.NoError(err, "GetCrashStatus")
+	require.True(status.CrashLoop, "runtime should be crash looping")
+	require.EqualValues(3, status.Crashes)
+	require.Contains(status.LastExitStatus, "exit status 3")
+	require.Equal([]string{"first line", "last line"}, status.LastStderr)
+}
+
+func TestCrashLoopAfterStart(t *testing.T) {
+	require := require.New(t)
+
+	p, err := New(Config{
+		HostInfo:          &protocol.HostInfo{},
+		InsecureNoSandbox: true,
+		RestartPolicy: &RestartPolicy{
+			InitialBackoff:     10 * time.Millisecond,
+			MaxBackoff:         time.Second,
+			CrashLoopThreshold: 3,
+			CrashLoopWindow:    time.Minute,
+			CrashLoopCooldown:  time.Hour,
+			MinUptime:          time.Minute,
+		},
+	})
+	require.NoError(err, "New")
+
+	rt, err := p.NewRuntime(context.Background(), host.Config{
+		RuntimeID: common.NewTestNamespaceFromSeed([]byte("sandbox crash after start test"), 0),
+	})
+	require.NoError(err, "NewRuntime")
+	r := rt.(*sandboxedRuntime)
+	crashErr := fmt.Errorf("exit status 3")
+
+	// A runtime that crashes shortly after each successful start should have its backoff grow
+	// and should eventually be considered crash looping.
+	for i, expected := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond} {
+		r.startedAt = time.Now()
+		require.Equal(expected, r.handleCrash(crashErr), "backoff after crash %d", i+1)
+	}
+	r.startedAt = time.Now()
+	require.Equal(time.Hour, r.handleCrash(crashErr), "runtime should be crash looping")
+	status, err := r.GetCrashStatus()
+	require.NoError(err, "GetCrashStatus")
+	require.True(status.CrashLoop, "runtime should be crash looping")
+
+	// A runtime that stayed up for the minimum uptime should have its crash history reset.
+	r.startedAt = time.Now().Add(-2 * time.Minute)
+	require.Equal(10*time.Millisecond, r.handleCrash(crashErr), "crash history should be reset")
+	status, err = r.GetCrashStatus()
+	require.NoError(err, "GetCrashStatus")
+	require.False(status.CrashLoop, "runtime should not be crash looping")
+	require.EqualValues(4, status.Crashes)
+}
+
+func TestLineBuffer(t *testing.T) {
+	require := require.New(t)
+
+	b := newLineBuffer(2)
+	_, _ = b.Write([]byte("one\ntw"))
+	_, _ = b.Write([]byte("o\nthree\nfou"))
+	require.Equal([]string{"three", "fou"}, b.Lines())
+
+	b.Reset()
+	require.Empty(b.Lines())
+}
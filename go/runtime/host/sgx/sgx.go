@@ -65,6 +65,10 @@ type Config struct {
 
 	// InsecureNoSandbox disables the sandbox and runs the loader directly.
 	InsecureNoSandbox bool
+
+	// RestartPolicy is the policy for restarting runtimes that have crashed or failed to start.
+	// In case it is not specified, the sandbox default is used.
+	RestartPolicy *sandbox.RestartPolicy
 }
 
 // RuntimeExtra is the extra configuration for SGX runtimes.
@@ -376,6 +380,7 @@ func New(cfg Config) (host.Provisioner, error) {
 		HostInitializer:   s.hostInitializer,
 		InsecureNoSandbox: cfg.InsecureNoSandbox,
 		Logger:            s.logger,
+		RestartPolicy:     cfg.RestartPolicy,
 	})
 	if err != nil {
 		return nil, err
@@ -65,6 +65,8 @@ func newConfig() (*RuntimeConfig, error) {
 		cfg.TagIndexer = tagindexer.NewNopBackend()
 	case tagindexer.BleveBackendName:
 		cfg.TagIndexer = tagindexer.NewBleveBackend()
+	case tagindexer.SQLiteBackendName:
+		cfg.TagIndexer = tagindexer.NewSQLiteBackend()
 	default:
 		return nil, fmt.Errorf("runtime/registry: unknown tag indexer backend: %s", tagIndexer)
 	}
@@ -79,7 +81,7 @@ func init() {
 	Flags.Duration(CfgHistoryPrunerInterval, 2*time.Minute, "History pruning interval")
 	Flags.Uint64(CfgHistoryPrunerKeepLastNum, 600, "Keep last history pruner: number of last rounds to keep")
 
-	Flags.String(CfgTagIndexerBackend, "", "Runtime tag indexer backend (disabled by default)")
+	Flags.String(CfgTagIndexerBackend, "", "Runtime tag indexer backend (bleve, sqlite; disabled by default)")
 
 	_ = viper.BindPFlags(Flags)
 }
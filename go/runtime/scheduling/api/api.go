@@ -4,6 +4,7 @@ package api
 import (
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 )
 
 // Scheduler defines an algorithm for scheduling incoming transactions.
@@ -14,6 +15,10 @@ type Scheduler interface {
 	// QueueTx queues a transaction for scheduling.
 	QueueTx(tx []byte) error
 
+	// QueueCheckedTx queues a transaction that has been checked by the runtime
+	// for scheduling. The check result may be used to prioritize the transaction.
+	QueueCheckedTx(tx []byte, result *transaction.TxnCheckResult) error
+
 	// AppendTxBatch appends a transaction batch for scheduling.
 	//
 	// Note: the AppendTxBatch is not required to be atomic. Semantics depend
@@ -26,6 +31,9 @@ type Scheduler interface {
 	// GetBatch returns a batch of scheduled transactions (if any is available).
 	GetBatch(force bool) [][]byte
 
+	// GetTransactions returns all queued transactions in scheduling order.
+	GetTransactions() [][]byte
+
 	// UnscheduledSize returns number of unscheduled items.
 	UnscheduledSize() uint64
 
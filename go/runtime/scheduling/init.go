@@ -7,13 +7,16 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/api"
 	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple"
 	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/orderedmap"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/priorityqueue"
 )
 
 // New creates a new scheduler.
 func New(maxTxPoolSize uint64, params registry.TxnSchedulerParameters) (api.Scheduler, error) {
 	switch params.Algorithm {
-	case simple.Name:
+	case simple.Name, simple.NameParallel:
 		return simple.New(orderedmap.Name, maxTxPoolSize, params)
+	case simple.NamePriority:
+		return simple.New(priorityqueue.Name, maxTxPoolSize, params)
 	default:
 		return nil, fmt.Errorf("invalid transaction scheduler algorithm: %s", params.Algorithm)
 	}
@@ -0,0 +1,131 @@
+// Package parallel implements grouping of transactions into non-conflicting
+// sub-batches based on their declared read/write sets.
+//
+// The results of sub-batches are merged by the runtime, which verifies that
+// the sub-batches do not conflict, so the merged results are the same as the
+// results of executing the whole batch sequentially.
+package parallel
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+)
+
+// IsDeclared returns true iff the given read/write set has been declared by
+// the runtime.
+func IsDeclared(rwSet *transaction.ReadWriteSet) bool {
+	return rwSet != nil && rwSet.Granularity > 0
+}
+
+// overlaps checks whether any key in a overlaps with any key in b. Two keys
+// overlap if one is a prefix of the other.
+func overlaps(a, b transaction.CoarsenedSet) bool {
+	for _, ka := range a {
+		for _, kb := range b {
+			if bytes.HasPrefix(ka, kb) || bytes.HasPrefix(kb, ka) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// conflicts checks whether two transactions with the given read/write sets
+// conflict, i.e. whether one writes something the other reads or writes.
+func conflicts(a, b *transaction.ReadWriteSet) bool {
+	return overlaps(a.WriteSet, b.WriteSet) ||
+		overlaps(a.WriteSet, b.ReadSet) ||
+		overlaps(a.ReadSet, b.WriteSet)
+}
+
+// Partition groups transactions with the given declared read/write sets into
+// at most maxSubBatches sub-batches such that transactions in different
+// sub-batches do not conflict.
+//
+// Each sub-batch is returned as a list of indices into the batch, in batch
+// order. Sub-batches are ordered by their first transaction. In case any of
+// the transactions has no declared read/write set, a single sub-batch
+// containing the whole batch is returned.
+//
+// How a batch is partitioned only affects how it is executed locally, as the
+// merged results do not depend on the partitioning.
+func Partition(rwSets []*transaction.ReadWriteSet, maxSubBatches int) [][]int {
+	if len(rwSets) == 0 {
+		return nil
+	}
+	if maxSubBatches < 1 {
+		maxSubBatches = 1
+	}
+
+	whole := func() [][]int {
+		batch := make([]int, len(rwSets))
+		for i := range batch {
+			batch[i] = i
+		}
+		return [][]int{batch}
+	}
+	for _, rwSet := range rwSets {
+		if !IsDeclared(rwSet) {
+			return whole()
+		}
+	}
+	if maxSubBatches == 1 {
+		return whole()
+	}
+
+	// Group conflicting transactions together.
+	parent := make([]int, len(rwSets))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range rwSets {
+		for j := i + 1; j < len(rwSets); j++ {
+			if !conflicts(rwSets[i], rwSets[j]) {
+				continue
+			}
+			ri, rj := find(i), find(j)
+			switch {
+			case ri < rj:
+				parent[rj] = ri
+			case rj < ri:
+				parent[ri] = rj
+			}
+		}
+	}
+
+	// Collect groups, ordered by their first transaction.
+	groupIdx := make(map[int]int)
+	var groups [][]int
+	for i := range rwSets {
+		root := find(i)
+		idx, ok := groupIdx[root]
+		if !ok {
+			idx = len(groups)
+			groupIdx[root] = idx
+			groups = append(groups, nil)
+		}
+		groups[idx] = append(groups[idx], i)
+	}
+	if len(groups) <= maxSubBatches {
+		return groups
+	}
+
+	// Assign groups to sub-batches in a round-robin fashion.
+	subBatches := make([][]int, maxSubBatches)
+	for i, group := range groups {
+		subBatches[i%maxSubBatches] = append(subBatches[i%maxSubBatches], group...)
+	}
+	for _, sb := range subBatches {
+		sort.Ints(sb)
+	}
+	return subBatches
+}
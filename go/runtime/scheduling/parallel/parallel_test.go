@@ -0,0 +1,43 @@
+package parallel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+)
+
+func rwSet(reads, writes []string) *transaction.ReadWriteSet {
+	rw := &transaction.ReadWriteSet{Granularity: 1}
+	for _, k := range reads {
+		rw.ReadSet = append(rw.ReadSet, []byte(k))
+	}
+	for _, k := range writes {
+		rw.WriteSet = append(rw.WriteSet, []byte(k))
+	}
+	return rw
+}
+
+func TestPartition(t *testing.T) {
+	require := require.New(t)
+
+	rwSets := []*transaction.ReadWriteSet{
+		rwSet([]string{"a"}, []string{"a"}),
+		rwSet([]string{"b"}, []string{"b"}),
+		rwSet([]string{"ax"}, []string{"c"}),
+		rwSet([]string{"d"}, []string{"d"}),
+	}
+
+	// Transactions 0 and 2 conflict as 2 reads a key under the prefix written by 0.
+	require.EqualValues([][]int{{0, 2}, {1}, {3}}, Partition(rwSets, 4))
+	// Groups are assigned to sub-batches in a round-robin fashion.
+	require.EqualValues([][]int{{0, 2, 3}, {1}}, Partition(rwSets, 2))
+	require.EqualValues([][]int{{0, 1, 2, 3}}, Partition(rwSets, 1))
+
+	// Undeclared read/write sets should prevent partitioning.
+	rwSets[1] = nil
+	require.EqualValues([][]int{{0, 1, 2, 3}}, Partition(rwSets, 4))
+
+	require.Nil(Partition(nil, 4))
+}
@@ -0,0 +1,136 @@
+package persistent
+
+import (
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+)
+
+type asyncOp struct {
+	add    bool
+	remove bool
+	clear  bool
+
+	txs   [][]byte
+	round uint64
+}
+
+type asyncStore struct {
+	sync.Mutex
+
+	logger *logging.Logger
+	inner  Store
+
+	pending  []*asyncOp
+	notifyCh chan struct{}
+	closeCh  chan struct{}
+	doneCh   chan struct{}
+	flushMu  sync.Mutex
+}
+
+func (s *asyncStore) enqueue(op *asyncOp) {
+	s.Lock()
+	s.pending = append(s.pending, op)
+	s.Unlock()
+
+	select {
+	case s.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *asyncStore) Add(txs [][]byte, round uint64) error {
+	s.enqueue(&asyncOp{add: true, txs: txs, round: round})
+	return nil
+}
+
+func (s *asyncStore) Remove(txs [][]byte) error {
+	s.enqueue(&asyncOp{remove: true, txs: txs})
+	return nil
+}
+
+func (s *asyncStore) Clear() error {
+	s.enqueue(&asyncOp{clear: true})
+	return nil
+}
+
+func (s *asyncStore) Load() ([]*QueuedTx, error) {
+	// Make sure all previous modifications are visible.
+	s.flush()
+	return s.inner.Load()
+}
+
+func (s *asyncStore) Close() {
+	close(s.closeCh)
+	<-s.doneCh
+	s.inner.Close()
+}
+
+// flush applies all pending modifications in order. Consecutive additions are written together.
+func (s *asyncStore) flush() {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.Lock()
+	ops := s.pending
+	s.pending = nil
+	s.Unlock()
+
+	for len(ops) > 0 {
+		op := ops[0]
+		ops = ops[1:]
+
+		var err error
+		switch {
+		case op.add:
+			txs := op.txs
+			for len(ops) > 0 && ops[0].add && ops[0].round == op.round {
+				txs = append(txs, ops[0].txs...)
+				ops = ops[1:]
+			}
+			err = s.inner.Add(txs, op.round)
+		case op.remove:
+			err = s.inner.Remove(op.txs)
+		case op.clear:
+			err = s.inner.Clear()
+		}
+		if err != nil {
+			s.logger.Warn("failed to update persistent transaction pool",
+				"err", err,
+			)
+		}
+	}
+}
+
+func (s *asyncStore) worker() {
+	defer close(s.doneCh)
+
+	for {
+		select {
+		case <-s.notifyCh:
+			s.flush()
+		case <-s.closeCh:
+			s.flush()
+			return
+		}
+	}
+}
+
+// NewAsync wraps the given store so that modifications are applied by a background worker.
+//
+// Modifications are applied in order and consecutive additions are written together, so callers
+// never wait for the underlying store and queuing many transactions does not need a synced write
+// per transaction. Errors are logged. Loading first applies any pending modifications and closing
+// the store waits for them to be applied.
+func NewAsync(store Store) Store {
+	s := &asyncStore{
+		logger:   logging.GetLogger("runtime/scheduling/persistent/async"),
+		inner:    store,
+		notifyCh: make(chan struct{}, 1),
+		closeCh:  make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	go s.worker()
+
+	return s
+}
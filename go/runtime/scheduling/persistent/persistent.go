@@ -0,0 +1,219 @@
+// Package persistent implements a persistent store of queued runtime
+// transactions, used to restore the transaction pool across node restarts.
+package persistent
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/dgraph-io/badger/v2/options"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	cmnBadger "github.com/oasisprotocol/oasis-core/go/common/badger"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/keyformat"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+)
+
+// DBFile is the filename of the persistent transaction pool database.
+const DBFile = "txpool.badger.db"
+
+const dbVersion = 1
+
+var (
+	// metadataKeyFmt is the metadata key format.
+	//
+	// Value is CBOR-serialized dbMetadata.
+	metadataKeyFmt = keyformat.New(0x01)
+	// txKeyFmt is the queued transaction key format.
+	//
+	// Value is CBOR-serialized QueuedTx.
+	txKeyFmt = keyformat.New(0x02, &hash.Hash{})
+
+	_ Store = (*store)(nil)
+)
+
+type dbMetadata struct {
+	// RuntimeID is the runtime ID this database is for.
+	RuntimeID common.Namespace `json:"runtime_id"`
+	// Version is the database schema version.
+	Version uint64 `json:"version"`
+}
+
+// QueuedTx is a persisted queued transaction.
+type QueuedTx struct {
+	// Tx is the raw transaction.
+	Tx []byte `json:"tx"`
+	// Round is the latest runtime round at the time the transaction was queued.
+	Round uint64 `json:"round"`
+}
+
+// Store is a persistent store of queued transactions.
+type Store interface {
+	// Add persists the given queued transactions.
+	Add(txs [][]byte, round uint64) error
+
+	// Remove removes the given transactions.
+	Remove(txs [][]byte) error
+
+	// Clear removes all transactions.
+	Clear() error
+
+	// Load returns all persisted transactions.
+	Load() ([]*QueuedTx, error)
+
+	// Close closes the store.
+	Close()
+}
+
+type store struct {
+	logger *logging.Logger
+
+	db *badger.DB
+	gc *cmnBadger.GCWorker
+}
+
+func (s *store) Add(txs [][]byte, round uint64) error {
+	wb := s.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for _, tx := range txs {
+		txHash := hash.NewFromBytes(tx)
+		qtx := QueuedTx{
+			Tx:    tx,
+			Round: round,
+		}
+		if err := wb.Set(txKeyFmt.Encode(&txHash), cbor.Marshal(qtx)); err != nil {
+			return fmt.Errorf("runtime/scheduling/persistent: failed to add transaction: %w", err)
+		}
+	}
+	if err := wb.Flush(); err != nil {
+		return fmt.Errorf("runtime/scheduling/persistent: failed to add transactions: %w", err)
+	}
+	return nil
+}
+
+func (s *store) Remove(txs [][]byte) error {
+	wb := s.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for _, tx := range txs {
+		txHash := hash.NewFromBytes(tx)
+		if err := wb.Delete(txKeyFmt.Encode(&txHash)); err != nil {
+			return fmt.Errorf("runtime/scheduling/persistent: failed to remove transaction: %w", err)
+		}
+	}
+	if err := wb.Flush(); err != nil {
+		return fmt.Errorf("runtime/scheduling/persistent: failed to remove transactions: %w", err)
+	}
+	return nil
+}
+
+func (s *store) Clear() error {
+	if err := s.db.DropPrefix(txKeyFmt.Encode()); err != nil {
+		return fmt.Errorf("runtime/scheduling/persistent: failed to clear transactions: %w", err)
+	}
+	return nil
+}
+
+func (s *store) Load() ([]*QueuedTx, error) {
+	var txs []*QueuedTx
+	err := s.db.View(func(tx *badger.Txn) error {
+		it := tx.NewIterator(badger.IteratorOptions{Prefix: txKeyFmt.Encode()})
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			var qtx QueuedTx
+			if err := it.Item().Value(func(val []byte) error {
+				return cbor.Unmarshal(val, &qtx)
+			}); err != nil {
+				return err
+			}
+			txs = append(txs, &qtx)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("runtime/scheduling/persistent: failed to load transactions: %w", err)
+	}
+	return txs, nil
+}
+
+func (s *store) Close() {
+	s.gc.Close()
+	if err := s.db.Close(); err != nil {
+		s.logger.Error("failed to close transaction pool database",
+			"err", err,
+		)
+	}
+}
+
+func (s *store) ensureMetadata(runtimeID common.Namespace) error {
+	return s.db.Update(func(tx *badger.Txn) error {
+		item, err := tx.Get(metadataKeyFmt.Encode())
+		switch err {
+		case nil:
+		case badger.ErrKeyNotFound:
+			// Create new metadata section.
+			meta := dbMetadata{
+				RuntimeID: runtimeID,
+				Version:   dbVersion,
+			}
+			return tx.Set(metadataKeyFmt.Encode(), cbor.Marshal(meta))
+		default:
+			return err
+		}
+
+		var meta dbMetadata
+		if err = item.Value(func(val []byte) error {
+			return cbor.Unmarshal(val, &meta)
+		}); err != nil {
+			return err
+		}
+
+		// Verify metadata section.
+		if meta.Version != dbVersion {
+			return fmt.Errorf("runtime/scheduling/persistent: unsupported database version (expected: %d got: %d)",
+				dbVersion,
+				meta.Version,
+			)
+		}
+		if !meta.RuntimeID.Equal(&runtimeID) {
+			return fmt.Errorf("runtime/scheduling/persistent: database for different runtime (expected: %s got: %s)",
+				runtimeID,
+				meta.RuntimeID,
+			)
+		}
+		return nil
+	})
+}
+
+// New creates a new persistent transaction store.
+func New(dataDir string, runtimeID common.Namespace) (Store, error) {
+	s := &store{
+		logger: logging.GetLogger("runtime/scheduling/persistent").With("runtime_id", runtimeID),
+	}
+
+	opts := badger.DefaultOptions(filepath.Join(dataDir, DBFile))
+	opts = opts.WithLogger(cmnBadger.NewLogAdapter(s.logger))
+	opts = opts.WithSyncWrites(true)
+	// Allow value log truncation if required (this is needed to recover the
+	// value log file which can get corrupted in crashes).
+	opts = opts.WithTruncate(true)
+	opts = opts.WithCompression(options.None)
+
+	var err error
+	if s.db, err = badger.Open(opts); err != nil {
+		return nil, fmt.Errorf("runtime/scheduling/persistent: failed to open database: %w", err)
+	}
+	s.gc = cmnBadger.NewGCWorker(s.logger, s.db)
+
+	if err = s.ensureMetadata(runtimeID); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
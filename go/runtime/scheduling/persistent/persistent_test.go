@@ -0,0 +1,152 @@
+package persistent
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+)
+
+func TestStore(t *testing.T) {
+	require := require.New(t)
+
+	// Create a new random temporary directory under /tmp.
+	dataDir, err := ioutil.TempDir("", "oasis-runtime-txpool-test_")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dataDir)
+
+	runtimeID := common.NewTestNamespaceFromSeed([]byte("txpool test ns 1"), 0)
+	runtimeID2 := common.NewTestNamespaceFromSeed([]byte("txpool test ns 2"), 0)
+
+	store, err := New(dataDir, runtimeID)
+	require.NoError(err, "New")
+
+	txs, err := store.Load()
+	require.NoError(err, "Load")
+	require.Empty(txs, "empty store should not contain transactions")
+
+	err = store.Add([][]byte{[]byte("tx 1"), []byte("tx 2")}, 10)
+	require.NoError(err, "Add")
+	err = store.Add([][]byte{[]byte("tx 3")}, 11)
+	require.NoError(err, "Add")
+	err = store.Remove([][]byte{[]byte("tx 2"), []byte("unknown tx")})
+	require.NoError(err, "Remove")
+
+	// Transactions should survive reopening the store.
+	store.Close()
+	store, err = New(dataDir, runtimeID)
+	require.NoError(err, "New")
+
+	txs, err = store.Load()
+	require.NoError(err, "Load")
+	require.Len(txs, 2, "Load should return all persisted transactions")
+	rounds := make(map[string]uint64)
+	for _, qtx := range txs {
+		rounds[string(qtx.Tx)] = qtx.Round
+	}
+	require.EqualValues(map[string]uint64{"tx 1": 10, "tx 3": 11}, rounds)
+
+	err = store.Clear()
+	require.NoError(err, "Clear")
+	txs, err = store.Load()
+	require.NoError(err, "Load")
+	require.Empty(txs, "Clear should remove all transactions")
+	store.Close()
+
+	// Opening the store for a different runtime should fail.
+	_, err = New(dataDir, runtimeID2)
+	require.Error(err, "New should fail for a different runtime")
+}
+
+type blockingStore struct {
+	sync.Mutex
+
+	addCh chan struct{}
+	ops   []string
+	txs   map[string]uint64
+}
+
+func (s *blockingStore) Add(txs [][]byte, round uint64) error {
+	<-s.addCh
+
+	s.Lock()
+	defer s.Unlock()
+	s.ops = append(s.ops, fmt.Sprintf("add %d", len(txs)))
+	for _, tx := range txs {
+		s.txs[string(tx)] = round
+	}
+	return nil
+}
+
+func (s *blockingStore) Remove(txs [][]byte) error {
+	s.Lock()
+	defer s.Unlock()
+	s.ops = append(s.ops, fmt.Sprintf("remove %d", len(txs)))
+	for _, tx := range txs {
+		delete(s.txs, string(tx))
+	}
+	return nil
+}
+
+func (s *blockingStore) Clear() error {
+	s.Lock()
+	defer s.Unlock()
+	s.ops = append(s.ops, "clear")
+	s.txs = make(map[string]uint64)
+	return nil
+}
+
+func (s *blockingStore) Load() ([]*QueuedTx, error) {
+	s.Lock()
+	defer s.Unlock()
+	var txs []*QueuedTx
+	for tx, round := range s.txs {
+		txs = append(txs, &QueuedTx{Tx: []byte(tx), Round: round})
+	}
+	return txs, nil
+}
+
+func (s *blockingStore) Close() {
+}
+
+func TestAsyncStore(t *testing.T) {
+	require := require.New(t)
+
+	inner := &blockingStore{
+		addCh: make(chan struct{}),
+		txs:   make(map[string]uint64),
+	}
+	store := NewAsync(inner)
+
+	// Adding should not wait for the underlying store.
+	err := store.Add([][]byte{[]byte("tx 1")}, 10)
+	require.NoError(err, "Add")
+	// Wait for the worker to start writing the first transaction.
+	inner.addCh <- struct{}{}
+	for _, tx := range []string{"tx 2", "tx 3", "tx 4"} {
+		err = store.Add([][]byte{[]byte(tx)}, 10)
+		require.NoError(err, "Add")
+	}
+	err = store.Remove([][]byte{[]byte("tx 3")})
+	require.NoError(err, "Remove")
+
+	// Consecutive additions should be written together and all modifications should be applied
+	// in order before loading.
+	inner.addCh <- struct{}{}
+	txs, err := store.Load()
+	require.NoError(err, "Load")
+	require.Len(txs, 3, "Load should return all persisted transactions")
+	require.EqualValues([]string{"add 1", "add 3", "remove 1"}, inner.ops)
+
+	// Pending modifications should be applied on close.
+	err = store.Clear()
+	require.NoError(err, "Clear")
+	store.Close()
+	require.EqualValues("clear", inner.ops[len(inner.ops)-1])
+	require.Empty(inner.txs, "Clear should remove all transactions")
+}
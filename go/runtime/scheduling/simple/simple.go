@@ -10,22 +10,38 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/api"
 	txpool "github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/api"
 	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/orderedmap"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/priorityqueue"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 )
 
 const (
 	// Name of the scheduler.
 	Name = registry.TxnSchedulerSimple
+
+	// NamePriority is the name of the scheduler variant which orders transactions
+	// by the priority reported by the runtime.
+	NamePriority = registry.TxnSchedulerPriority
+
+	// NameParallel is the name of the scheduler variant used by runtimes whose
+	// batches are executed in non-conflicting sub-batches. Scheduling itself is
+	// the same FIFO scheduling as with the simple variant.
+	NameParallel = registry.TxnSchedulerParallel
 )
 
 type scheduler struct {
 	logger *logging.Logger
 
+	algorithm     string
 	txPool        txpool.TxPool
 	maxTxPoolSize uint64
 }
 
 func (s *scheduler) QueueTx(tx []byte) error {
-	switch err := s.txPool.Add(tx); err {
+	return s.QueueCheckedTx(tx, nil)
+}
+
+func (s *scheduler) QueueCheckedTx(tx []byte, result *transaction.TxnCheckResult) error {
+	switch err := s.txPool.AddChecked(tx, result); err {
 	case nil:
 		return nil
 	case txpool.ErrCallAlreadyExists:
@@ -60,6 +76,10 @@ func (s *scheduler) GetBatch(force bool) [][]byte {
 	return s.txPool.GetBatch(force)
 }
 
+func (s *scheduler) GetTransactions() [][]byte {
+	return s.txPool.GetTransactions()
+}
+
 func (s *scheduler) UnscheduledSize() uint64 {
 	return s.txPool.Size()
 }
@@ -73,7 +93,7 @@ func (s *scheduler) Clear() {
 }
 
 func (s *scheduler) UpdateParameters(params registry.TxnSchedulerParameters) error {
-	if params.Algorithm != Name {
+	if params.Algorithm != s.algorithm {
 		return fmt.Errorf("unexpected transaction scheduling algorithm: %s", params.Algorithm)
 	}
 	if err := s.txPool.UpdateConfig(txpool.Config{
@@ -87,12 +107,14 @@ func (s *scheduler) UpdateParameters(params registry.TxnSchedulerParameters) err
 }
 
 func (s *scheduler) Name() string {
-	return Name
+	return s.algorithm
 }
 
 // New creates a new simple scheduler.
 func New(txPoolImpl string, maxTxPoolSize uint64, params registry.TxnSchedulerParameters) (api.Scheduler, error) {
-	if params.Algorithm != Name {
+	switch params.Algorithm {
+	case Name, NamePriority, NameParallel:
+	default:
 		return nil, fmt.Errorf("unexpected transaction scheduling algorithm: %s", params.Algorithm)
 	}
 
@@ -105,11 +127,14 @@ func New(txPoolImpl string, maxTxPoolSize uint64, params registry.TxnSchedulerPa
 	switch txPoolImpl {
 	case orderedmap.Name:
 		pool = orderedmap.New(poolCfg)
+	case priorityqueue.Name:
+		pool = priorityqueue.New(poolCfg)
 	default:
 		return nil, fmt.Errorf("invalid transaction pool: %s", txPoolImpl)
 	}
 
 	scheduler := &scheduler{
+		algorithm:     params.Algorithm,
 		maxTxPoolSize: maxTxPoolSize,
 		txPool:        pool,
 		logger:        logging.GetLogger("runtime/scheduling").With("scheduler", "simple"),
@@ -7,6 +7,7 @@ import (
 
 	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
 	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/orderedmap"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/priorityqueue"
 	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/tests"
 )
 
@@ -22,6 +23,18 @@ func TestSimpleScheduler(t *testing.T) {
 	tests.SchedulerImplementationTests(t, algo)
 }
 
+func TestPriorityScheduler(t *testing.T) {
+	params := registry.TxnSchedulerParameters{
+		Algorithm:         NamePriority,
+		MaxBatchSize:      10,
+		MaxBatchSizeBytes: 16 * 1024 * 1024,
+	}
+	algo, err := New(priorityqueue.Name, 100, params)
+	require.NoError(t, err, "New()")
+
+	tests.SchedulerImplementationTests(t, algo)
+}
+
 func BenchmarkSimpleSchedulerOrderedMap(b *testing.B) {
 	params := registry.TxnSchedulerParameters{
 		Algorithm:         Name,
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 	p2pError "github.com/oasisprotocol/oasis-core/go/worker/common/p2p/error"
 )
 
@@ -12,6 +13,9 @@ var (
 	ErrCallAlreadyExists = fmt.Errorf("call already exists in pool")
 	ErrFull              = fmt.Errorf("pool is full")
 	ErrCallTooLarge      = p2pError.Permanent(fmt.Errorf("call too large"))
+
+	ErrSenderLimit            = fmt.Errorf("too many calls from sender in pool")
+	ErrReplacementUnderpriced = fmt.Errorf("replacement call underpriced")
 )
 
 // Config is a transaction pool configuration.
@@ -29,12 +33,21 @@ type TxPool interface {
 	// Add adds a single transaction into the transaction pool.
 	Add(tx []byte) error
 
+	// AddChecked adds a single checked transaction into the transaction pool.
+	//
+	// The check result reported by the runtime may be used by the implementation
+	// to order the transactions. A nil check result is the same as calling Add.
+	AddChecked(tx []byte, result *transaction.TxnCheckResult) error
+
 	// AddBatch adds a transaction batch into the transaction pool.
 	AddBatch(batch [][]byte) error
 
 	// GetBatch gets a transaction batch from the transaction pool.
 	GetBatch(force bool) [][]byte
 
+	// GetTransactions returns all transactions in the transaction pool in scheduling order.
+	GetTransactions() [][]byte
+
 	// RemoveBatch removes a batch from the transaction pool.
 	RemoveBatch(batch [][]byte) error
 
@@ -10,6 +10,7 @@ import (
 
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 )
 
 // Name is the name of the tx pool implementation.
@@ -62,6 +63,12 @@ func (q *orderedMap) Add(tx []byte) error {
 	return nil
 }
 
+// Implements api.TxPool.
+func (q *orderedMap) AddChecked(tx []byte, result *transaction.TxnCheckResult) error {
+	// Check results are ignored as the transactions are kept in FIFO order.
+	return q.Add(tx)
+}
+
 // Implements api.TxPool.
 func (q *orderedMap) AddBatch(batch [][]byte) error {
 	// Compute all hashes before taking the lock.
@@ -137,6 +144,18 @@ func (q *orderedMap) GetBatch(force bool) [][]byte {
 	return batch
 }
 
+// Implements api.TxPool.
+func (q *orderedMap) GetTransactions() [][]byte {
+	q.Lock()
+	defer q.Unlock()
+
+	txs := make([][]byte, 0, q.queue.Len())
+	for current := q.queue.Back(); current != nil; current = current.Prev() {
+		txs = append(txs, current.Value.(*pair).Value)
+	}
+	return txs
+}
+
 // Implements api.TxPool.
 func (q *orderedMap) RemoveBatch(batch [][]byte) error {
 	q.Lock()
@@ -0,0 +1,434 @@
+// Package priorityqueue implements a tx pool ordered by transaction priority.
+package priorityqueue
+
+import (
+	"container/heap"
+	"container/list"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+)
+
+// Name is the name of the tx pool implementation.
+const Name = "priority-queue"
+
+const (
+	// DefaultMaxTxsPerSender is the maximum number of transactions from a single sender that
+	// can be queued at the same time.
+	DefaultMaxTxsPerSender = 64
+
+	// DefaultTxTTL is the time after which a queued transaction is evicted from the pool.
+	DefaultTxTTL = 10 * time.Minute
+)
+
+var _ api.TxPool = (*priorityQueue)(nil)
+
+type item struct {
+	key       hash.Hash
+	value     []byte
+	priority  uint64
+	sender    string
+	senderSeq uint64
+
+	// seq is the insertion sequence number used to keep FIFO order among
+	// transactions with the same priority.
+	seq     uint64
+	addedAt time.Time
+
+	scheduleIndex int
+	evictIndex    int
+	expiry        *list.Element
+}
+
+// scheduleHeap orders items by descending priority, oldest first.
+type scheduleHeap []*item
+
+func (h scheduleHeap) Len() int {
+	return len(h)
+}
+
+func (h scheduleHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h scheduleHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].scheduleIndex = i
+	h[j].scheduleIndex = j
+}
+
+func (h *scheduleHeap) Push(x interface{}) {
+	it := x.(*item)
+	it.scheduleIndex = len(*h)
+	*h = append(*h, it)
+}
+
+func (h *scheduleHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return it
+}
+
+// evictHeap orders items by ascending priority, newest first.
+type evictHeap []*item
+
+func (h evictHeap) Len() int {
+	return len(h)
+}
+
+func (h evictHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority < h[j].priority
+	}
+	return h[i].seq > h[j].seq
+}
+
+func (h evictHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].evictIndex = i
+	h[j].evictIndex = j
+}
+
+func (h *evictHeap) Push(x interface{}) {
+	it := x.(*item)
+	it.evictIndex = len(*h)
+	*h = append(*h, it)
+}
+
+func (h *evictHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return it
+}
+
+type priorityQueue struct {
+	sync.Mutex
+
+	transactions   map[hash.Hash]*item
+	senders        map[string]map[uint64]*item
+	schedule       scheduleHeap
+	evict          evictHeap
+	expiry         *list.List
+	queueSizeBytes uint64
+	nextSeq        uint64
+
+	maxTxPoolSize     uint64
+	maxBatchSize      uint64
+	maxBatchSizeBytes uint64
+	maxTxsPerSender   uint64
+	txTTL             time.Duration
+
+	now func() time.Time
+}
+
+// Implements api.TxPool.
+func (q *priorityQueue) Name() string {
+	return Name
+}
+
+// Implements api.TxPool.
+func (q *priorityQueue) Add(tx []byte) error {
+	return q.AddChecked(tx, nil)
+}
+
+// Implements api.TxPool.
+func (q *priorityQueue) AddChecked(tx []byte, result *transaction.TxnCheckResult) error {
+	txHash := hash.NewFromBytes(tx)
+
+	q.Lock()
+	defer q.Unlock()
+
+	q.expireLocked()
+
+	return q.addTxLocked(tx, txHash, result)
+}
+
+// Implements api.TxPool.
+func (q *priorityQueue) AddBatch(batch [][]byte) error {
+	// Compute all hashes before taking the lock.
+	var txHashes []hash.Hash
+	for _, tx := range batch {
+		txHash := hash.NewFromBytes(tx)
+		txHashes = append(txHashes, txHash)
+	}
+
+	q.Lock()
+	defer q.Unlock()
+
+	q.expireLocked()
+
+	var errs error
+	for i, tx := range batch {
+		switch err := q.addTxLocked(tx, txHashes[i], nil); err {
+		case nil:
+		case api.ErrFull:
+			errs = multierror.Append(errs, fmt.Errorf("failed inserting tx: %d, error: %w", i, err))
+			return errs
+		default:
+			errs = multierror.Append(errs, fmt.Errorf("failed inserting tx: %d, error: %w", i, err))
+		}
+	}
+
+	return errs
+}
+
+// Implements api.TxPool.
+func (q *priorityQueue) GetBatch(force bool) [][]byte {
+	q.Lock()
+	defer q.Unlock()
+
+	q.expireLocked()
+
+	// Check if a batch is ready.
+	queueSize := uint64(len(q.transactions))
+	if queueSize < q.maxBatchSize && q.queueSizeBytes < q.maxBatchSizeBytes && !force {
+		return nil
+	}
+
+	var batch [][]byte
+	var batchSizeBytes uint64
+	var popped []*item
+	for q.schedule.Len() > 0 {
+		// Check if the batch already has enough transactions.
+		if uint64(len(batch)) >= q.maxBatchSize {
+			break
+		}
+
+		it := heap.Pop(&q.schedule).(*item)
+		popped = append(popped, it)
+
+		txSize := uint64(len(it.value))
+		// Check if the call does fit into the batch.
+		// XXX: potentially there could still be smaller transactions that would
+		// fit, which this will miss.
+		if batchSizeBytes+txSize > q.maxBatchSizeBytes {
+			break
+		}
+
+		batch = append(batch, it.value)
+		batchSizeBytes += txSize
+	}
+
+	// Transactions remain in the pool until they are removed.
+	for _, it := range popped {
+		heap.Push(&q.schedule, it)
+	}
+
+	return batch
+}
+
+// Implements api.TxPool.
+func (q *priorityQueue) GetTransactions() [][]byte {
+	q.Lock()
+	defer q.Unlock()
+
+	q.expireLocked()
+
+	schedule := make(scheduleHeap, len(q.schedule))
+	copy(schedule, q.schedule)
+	sort.Slice(schedule, func(i, j int) bool {
+		return schedule.Less(i, j)
+	})
+
+	txs := make([][]byte, 0, len(schedule))
+	for _, it := range schedule {
+		txs = append(txs, it.value)
+	}
+	return txs
+}
+
+// Implements api.TxPool.
+func (q *priorityQueue) RemoveBatch(batch [][]byte) error {
+	q.Lock()
+	defer q.Unlock()
+
+	for _, tx := range batch {
+		txHash := hash.NewFromBytes(tx)
+		if it, ok := q.transactions[txHash]; ok {
+			q.removeTxLocked(it)
+		}
+	}
+
+	return nil
+}
+
+// Implements api.TxPool.
+func (q *priorityQueue) IsQueued(txHash hash.Hash) bool {
+	q.Lock()
+	defer q.Unlock()
+
+	_, ok := q.transactions[txHash]
+	return ok
+}
+
+// Implements api.TxPool.
+func (q *priorityQueue) Size() uint64 {
+	q.Lock()
+	defer q.Unlock()
+
+	return uint64(len(q.transactions))
+}
+
+// Implements api.TxPool.
+func (q *priorityQueue) UpdateConfig(cfg api.Config) error {
+	q.Lock()
+	defer q.Unlock()
+	q.maxBatchSize = cfg.MaxBatchSize
+	q.maxBatchSizeBytes = cfg.MaxBatchSizeBytes
+	q.maxTxPoolSize = cfg.MaxPoolSize
+
+	// Remove any transactions that are bigger than the updated `maxBatchSizeBytes`.
+	for _, it := range q.transactions {
+		if uint64(len(it.value)) > cfg.MaxBatchSizeBytes {
+			q.removeTxLocked(it)
+		}
+	}
+	// Evict the lowest priority transactions if the pool is over capacity.
+	for uint64(len(q.transactions)) > cfg.MaxPoolSize {
+		q.removeTxLocked(q.evict[0])
+	}
+
+	return nil
+}
+
+// Implements api.TxPool.
+func (q *priorityQueue) IsQueue() bool {
+	return false
+}
+
+// Implements api.TxPool.
+func (q *priorityQueue) Clear() {
+	q.Lock()
+	defer q.Unlock()
+
+	q.transactions = make(map[hash.Hash]*item)
+	q.senders = make(map[string]map[uint64]*item)
+	q.schedule = nil
+	q.evict = nil
+	q.expiry = list.New()
+	q.queueSizeBytes = 0
+}
+
+// NOTE: Assumes lock is held.
+func (q *priorityQueue) expireLocked() {
+	now := q.now()
+	for {
+		front := q.expiry.Front()
+		if front == nil {
+			return
+		}
+		it := front.Value.(*item)
+		if now.Sub(it.addedAt) < q.txTTL {
+			return
+		}
+		q.removeTxLocked(it)
+	}
+}
+
+// NOTE: Assumes lock is held.
+func (q *priorityQueue) addTxLocked(tx []byte, txHash hash.Hash, result *transaction.TxnCheckResult) error {
+	if uint64(len(tx)) > q.maxBatchSizeBytes {
+		return api.ErrCallTooLarge
+	}
+	if _, ok := q.transactions[txHash]; ok {
+		return api.ErrCallAlreadyExists
+	}
+
+	it := &item{
+		key:     txHash,
+		value:   tx,
+		seq:     q.nextSeq,
+		addedAt: q.now(),
+	}
+	if result != nil {
+		it.priority = result.Priority
+		it.sender = string(result.Sender)
+		it.senderSeq = result.SenderSeq
+	}
+
+	// Handle per-sender limits and replacement of transactions with the same sequence number.
+	if it.sender != "" {
+		senderTxs := q.senders[it.sender]
+		if existing, ok := senderTxs[it.senderSeq]; ok {
+			if it.priority <= existing.priority {
+				return api.ErrReplacementUnderpriced
+			}
+			q.removeTxLocked(existing)
+		} else if uint64(len(senderTxs)) >= q.maxTxsPerSender {
+			return api.ErrSenderLimit
+		}
+	}
+
+	// Check if there is room in the queue, evicting a lower priority transaction if needed.
+	if uint64(len(q.transactions)) >= q.maxTxPoolSize {
+		if q.evict.Len() == 0 || q.evict[0].priority >= it.priority {
+			return api.ErrFull
+		}
+		q.removeTxLocked(q.evict[0])
+	}
+
+	q.nextSeq++
+	q.transactions[txHash] = it
+	heap.Push(&q.schedule, it)
+	heap.Push(&q.evict, it)
+	it.expiry = q.expiry.PushBack(it)
+	q.queueSizeBytes += uint64(len(tx))
+	if it.sender != "" {
+		senderTxs := q.senders[it.sender]
+		if senderTxs == nil {
+			senderTxs = make(map[uint64]*item)
+			q.senders[it.sender] = senderTxs
+		}
+		senderTxs[it.senderSeq] = it
+	}
+
+	return nil
+}
+
+// NOTE: Assumes lock is held.
+func (q *priorityQueue) removeTxLocked(it *item) {
+	heap.Remove(&q.schedule, it.scheduleIndex)
+	heap.Remove(&q.evict, it.evictIndex)
+	q.expiry.Remove(it.expiry)
+	delete(q.transactions, it.key)
+	q.queueSizeBytes -= uint64(len(it.value))
+
+	if it.sender != "" {
+		senderTxs := q.senders[it.sender]
+		delete(senderTxs, it.senderSeq)
+		if len(senderTxs) == 0 {
+			delete(q.senders, it.sender)
+		}
+	}
+}
+
+// New returns a new priority queue.
+func New(cfg api.Config) api.TxPool {
+	return &priorityQueue{
+		transactions:      make(map[hash.Hash]*item),
+		senders:           make(map[string]map[uint64]*item),
+		expiry:            list.New(),
+		maxTxPoolSize:     cfg.MaxPoolSize,
+		maxBatchSize:      cfg.MaxBatchSize,
+		maxBatchSizeBytes: cfg.MaxBatchSizeBytes,
+		maxTxsPerSender:   DefaultMaxTxsPerSender,
+		txTTL:             DefaultTxTTL,
+		now:               time.Now,
+	}
+}
@@ -0,0 +1,90 @@
+package priorityqueue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	tests "github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/simple/txpool/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	queue := New(api.Config{
+		MaxPoolSize:       10,
+		MaxBatchSize:      10,
+		MaxBatchSizeBytes: 10,
+	})
+	tests.TxPoolImplementationTests(t, queue)
+}
+
+func TestPriorityQueueScheduling(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Unix(1580461674, 0)
+	pool := New(api.Config{
+		MaxPoolSize:       4,
+		MaxBatchSize:      10,
+		MaxBatchSizeBytes: 100,
+	})
+	pool.(*priorityQueue).now = func() time.Time { return now }
+
+	check := func(priority uint64, sender string, seq uint64) *transaction.TxnCheckResult {
+		return &transaction.TxnCheckResult{
+			Priority:  priority,
+			Sender:    []byte(sender),
+			SenderSeq: seq,
+		}
+	}
+
+	// Higher priority transactions should be scheduled first, FIFO otherwise.
+	require.NoError(pool.AddChecked([]byte("low"), check(1, "", 0)), "AddChecked")
+	require.NoError(pool.AddChecked([]byte("high"), check(10, "", 0)), "AddChecked")
+	require.NoError(pool.AddChecked([]byte("low 2"), check(1, "", 0)), "AddChecked")
+	require.EqualValues([][]byte{[]byte("high"), []byte("low"), []byte("low 2")}, pool.GetBatch(true))
+
+	// A full pool should evict lower priority transactions.
+	require.NoError(pool.AddChecked([]byte("medium"), check(5, "", 0)), "AddChecked")
+	require.Equal(api.ErrFull, pool.AddChecked([]byte("spam"), check(1, "", 0)), "AddChecked should fail when full")
+	require.NoError(pool.AddChecked([]byte("important"), check(20, "", 0)), "AddChecked")
+	require.EqualValues(4, pool.Size(), "Size")
+	require.EqualValues([][]byte{[]byte("important"), []byte("high"), []byte("medium"), []byte("low")}, pool.GetBatch(true))
+
+	// Transactions with the same sender and sequence number should be replaced.
+	pool.Clear()
+	require.NoError(pool.AddChecked([]byte("tx 1"), check(1, "alice", 1)), "AddChecked")
+	require.Equal(api.ErrReplacementUnderpriced, pool.AddChecked([]byte("tx 1 again"), check(1, "alice", 1)))
+	require.NoError(pool.AddChecked([]byte("tx 1 bump"), check(2, "alice", 1)), "AddChecked")
+	require.EqualValues([][]byte{[]byte("tx 1 bump")}, pool.GetBatch(true))
+
+	// Per-sender limits should be enforced.
+	pool.Clear()
+	require.NoError(pool.UpdateConfig(api.Config{
+		MaxPoolSize:       1000,
+		MaxBatchSize:      10,
+		MaxBatchSizeBytes: 100,
+	}), "UpdateConfig")
+	for i := uint64(0); i < DefaultMaxTxsPerSender; i++ {
+		require.NoError(pool.AddChecked([]byte{byte(i)}, check(1, "bob", i)), "AddChecked")
+	}
+	require.Equal(api.ErrSenderLimit, pool.AddChecked([]byte("one too many"), check(1, "bob", DefaultMaxTxsPerSender)))
+	require.NoError(pool.AddChecked([]byte("other sender"), check(1, "carol", 0)), "AddChecked")
+
+	// Expired transactions should be evicted.
+	now = now.Add(DefaultTxTTL / 2)
+	require.NoError(pool.AddChecked([]byte("fresh"), check(1, "carol", 1)), "AddChecked")
+	now = now.Add(DefaultTxTTL / 2)
+	require.EqualValues([][]byte{[]byte("fresh")}, pool.GetBatch(true))
+	require.EqualValues(1, pool.Size(), "Size")
+}
+
+func BenchmarkPriorityQueue(b *testing.B) {
+	queue := New(api.Config{
+		MaxPoolSize:       10,
+		MaxBatchSize:      10,
+		MaxBatchSizeBytes: 10,
+	})
+	tests.TxPoolImplementationBenchmarks(b, queue)
+}
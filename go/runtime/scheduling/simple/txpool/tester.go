@@ -36,6 +36,10 @@ func TxPoolImplementationTests(
 		testRemoveBatch(t, pool)
 	})
 
+	t.Run("TestGetTransactions", func(t *testing.T) {
+		testGetTransactions(t, pool)
+	})
+
 	t.Run("TestUpdateConfig", func(t *testing.T) {
 		testUpdateConfig(t, pool)
 	})
@@ -192,6 +196,41 @@ func testRemoveBatch(t *testing.T, pool api.TxPool) {
 	require.EqualValues(t, 2, pool.Size(), "Size")
 }
 
+func testGetTransactions(t *testing.T, pool api.TxPool) {
+	pool.Clear()
+
+	err := pool.UpdateConfig(api.Config{
+		MaxPoolSize:       51,
+		MaxBatchSize:      2,
+		MaxBatchSizeBytes: 100,
+	})
+	require.NoError(t, err, "UpdateConfig")
+
+	require.Empty(t, pool.GetTransactions(), "GetTransactions on empty pool")
+
+	txs := [][]byte{
+		[]byte("hello world"),
+		[]byte("one"),
+		[]byte("two"),
+		[]byte("three"),
+	}
+	err = pool.AddBatch(txs)
+	require.NoError(t, err, "AddBatch")
+
+	queued := pool.GetTransactions()
+	require.Len(t, queued, len(txs), "GetTransactions should return all transactions")
+	require.ElementsMatch(t, txs, queued, "GetTransactions should return all transactions")
+	require.EqualValues(t, pool.GetBatch(true), queued[:2], "GetTransactions should be in scheduling order")
+	if pool.IsQueue() {
+		require.EqualValues(t, txs, queued, "GetTransactions should be in FIFO order")
+	}
+	require.EqualValues(t, 4, pool.Size(), "GetTransactions should not remove transactions")
+
+	err = pool.RemoveBatch(txs[:2])
+	require.NoError(t, err, "RemoveBatch")
+	require.ElementsMatch(t, txs[2:], pool.GetTransactions(), "GetTransactions after RemoveBatch")
+}
+
 func testUpdateConfig(t *testing.T, pool api.TxPool) {
 	pool.Clear()
 
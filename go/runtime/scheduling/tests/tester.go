@@ -46,6 +46,7 @@ func testScheduleTransactions(t *testing.T, scheduler api.Scheduler) {
 	batch = scheduler.GetBatch(true)
 	require.EqualValues(t, transaction.RawBatch{testTx}, batch, "transaction should be returned")
 	require.True(t, scheduler.IsQueued(txBytes), "IsQueued(tx)")
+	require.EqualValues(t, [][]byte{testTx}, scheduler.GetTransactions(), "GetTransactions")
 
 	// Test RemoveTxBatch.
 	err = scheduler.RemoveTxBatch(batch)
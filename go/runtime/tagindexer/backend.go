@@ -26,17 +26,16 @@ var (
 
 // Result is a query result.
 type Result struct {
+	// Round is the round of the block containing the matched transaction.
+	Round uint64
 	// TxHash is the hash of the matched transaction.
 	TxHash hash.Hash
 	// TxIndex is the index of the matched transaction within the block.
 	TxIndex uint32
 }
 
-// Results are query results.
-//
-// Map key is the round number and value is a list of transaction hashes
-// that match the query.
-type Results map[uint64][]Result
+// Results are query results in the order requested by the query.
+type Results []Result
 
 // BackendFactory is the tag indexer backend factory interface.
 type BackendFactory func(dataDir string, runtimeID common.Namespace) (Backend, error)
@@ -46,7 +45,7 @@ type QueryableBackend interface {
 	// QueryBlock queries the block tag index.
 	QueryBlock(ctx context.Context, blockHash hash.Hash) (uint64, error)
 
-	// QueryTxn queries the transaction tag index.
+	// QueryTxn queries the transaction tag index and returns the earliest matching transaction.
 	QueryTxn(ctx context.Context, key, value []byte) (uint64, hash.Hash, uint32, error)
 
 	// QueryTxnByIndex queries the transaction tag index for a specific transaction hash
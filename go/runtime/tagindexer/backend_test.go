@@ -2,8 +2,10 @@ package tagindexer
 
 import (
 	"context"
+	"errors"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -129,11 +131,9 @@ func testOperations(t *testing.T, backend Backend) {
 	}
 	results, err := backend.QueryTxns(ctx, query)
 	require.NoError(t, err, "QueryTxns")
-	require.Len(t, results, 1)
-	require.Contains(t, results, uint64(42))
-	require.Len(t, results[42], 2)
-	require.Contains(t, results[42], Result{TxHash: tx1Hash, TxIndex: 0})
-	require.Contains(t, results[42], Result{TxHash: tx2Hash, TxIndex: 1})
+	require.Len(t, results, 2)
+	require.Contains(t, results, Result{Round: 42, TxHash: tx1Hash, TxIndex: 0})
+	require.Contains(t, results, Result{Round: 42, TxHash: tx2Hash, TxIndex: 1})
 
 	query = api.Query{
 		Conditions: []api.QueryCondition{
@@ -143,11 +143,117 @@ func testOperations(t *testing.T, backend Backend) {
 	results, err = backend.QueryTxns(ctx, query)
 	require.NoError(t, err, "QueryTxns")
 	require.NoError(t, err, "QueryTxns")
-	require.Len(t, results, 1)
-	require.Contains(t, results, uint64(42))
-	require.Len(t, results[42], 2)
-	require.Contains(t, results[42], Result{TxHash: tx1Hash, TxIndex: 0})
-	require.Contains(t, results[42], Result{TxHash: tx2Hash, TxIndex: 1})
+	require.Len(t, results, 2)
+	require.Contains(t, results, Result{Round: 42, TxHash: tx1Hash, TxIndex: 0})
+	require.Contains(t, results, Result{Round: 42, TxHash: tx2Hash, TxIndex: 1})
+
+	// QueryTxn should return the earliest match.
+	round, txnHash, txnIndex, err = backend.QueryTxn(ctx, []byte("hello"), []byte("world"))
+	require.NoError(t, err, "QueryTxn")
+	require.EqualValues(t, 42, round)
+	require.EqualValues(t, tx1Hash, txnHash)
+	require.EqualValues(t, 0, txnIndex)
+
+	testQueries(t, backend, []Result{
+		{Round: 42, TxHash: tx1Hash, TxIndex: 0},
+		{Round: 42, TxHash: tx2Hash, TxIndex: 1},
+		{Round: 43, TxHash: tx3Hash, TxIndex: 0},
+	})
+}
+
+func testQueries(t *testing.T, backend Backend, txs []Result) {
+	ctx := context.Background()
+
+	tagFilter := func(key, value string) *api.QueryFilter {
+		return &api.QueryFilter{Tag: &api.TagMatch{Key: []byte(key), Value: []byte(value)}}
+	}
+	anyFilter := &api.QueryFilter{Or: []*api.QueryFilter{
+		tagFilter("hello", "world"),
+		tagFilter("foo", "bar"),
+	}}
+
+	// Boolean combinations and ordering.
+	results, err := backend.QueryTxns(ctx, api.Query{Filter: anyFilter})
+	require.NoError(t, err, "QueryTxns")
+	require.EqualValues(t, Results{txs[0], txs[1], txs[2]}, results, "results should be in ascending order")
+
+	results, err = backend.QueryTxns(ctx, api.Query{Filter: anyFilter, Order: api.OrderDescending})
+	require.NoError(t, err, "QueryTxns")
+	require.EqualValues(t, Results{txs[2], txs[1], txs[0]}, results, "results should be in descending order")
+
+	results, err = backend.QueryTxns(ctx, api.Query{Filter: &api.QueryFilter{And: []*api.QueryFilter{
+		tagFilter("hello", "world"),
+		{Not: tagFilter("hello2", "world")},
+	}}})
+	require.NoError(t, err, "QueryTxns")
+	require.EqualValues(t, Results{txs[0]}, results)
+
+	results, err = backend.QueryTxns(ctx, api.Query{Filter: &api.QueryFilter{Not: tagFilter("foo", "bar")}})
+	require.NoError(t, err, "QueryTxns")
+	require.EqualValues(t, Results{txs[0], txs[1]}, results)
+
+	// Prefix and range matching.
+	results, err = backend.QueryTxns(ctx, api.Query{Filter: &api.QueryFilter{
+		Tag: &api.TagMatch{Key: []byte("hello"), Prefix: []byte("wor")},
+	}})
+	require.NoError(t, err, "QueryTxns")
+	require.EqualValues(t, Results{txs[0], txs[1]}, results)
+
+	results, err = backend.QueryTxns(ctx, api.Query{Filter: &api.QueryFilter{
+		Tag: &api.TagMatch{Key: []byte("foo"), Min: []byte("b"), Max: []byte("bz")},
+	}})
+	require.NoError(t, err, "QueryTxns")
+	require.EqualValues(t, Results{txs[2]}, results)
+
+	results, err = backend.QueryTxns(ctx, api.Query{Filter: &api.QueryFilter{
+		Tag: &api.TagMatch{Key: []byte("foo"), Min: []byte("c")},
+	}})
+	require.NoError(t, err, "QueryTxns")
+	require.Empty(t, results)
+
+	// Range matching over rounds.
+	results, err = backend.QueryTxns(ctx, api.Query{Filter: anyFilter, RoundMin: 43})
+	require.NoError(t, err, "QueryTxns")
+	require.EqualValues(t, Results{txs[2]}, results)
+
+	// Cursor-based pagination.
+	for _, order := range []api.QueryOrder{api.OrderAscending, api.OrderDescending} {
+		var (
+			cursor *api.QueryCursor
+			pages  Results
+		)
+		for {
+			results, err = backend.QueryTxns(ctx, api.Query{
+				Filter: anyFilter,
+				Order:  order,
+				Limit:  2,
+				Cursor: cursor,
+			})
+			require.NoError(t, err, "QueryTxns")
+			if len(results) == 0 {
+				break
+			}
+			require.True(t, len(results) <= 2, "page should respect limit")
+			pages = append(pages, results...)
+
+			last := results[len(results)-1]
+			cursor = &api.QueryCursor{Round: last.Round, Index: last.TxIndex}
+		}
+
+		expected := Results{txs[0], txs[1], txs[2]}
+		if order == api.OrderDescending {
+			expected = Results{txs[2], txs[1], txs[0]}
+		}
+		require.EqualValues(t, expected, pages, "paginated results should match all results")
+	}
+
+	// Invalid queries.
+	_, err = backend.QueryTxns(ctx, api.Query{Filter: &api.QueryFilter{}})
+	require.True(t, errors.Is(err, api.ErrInvalidQuery), "QueryTxns should fail for invalid filters")
+	_, err = backend.QueryTxns(ctx, api.Query{Filter: &api.QueryFilter{
+		Tag: &api.TagMatch{Key: []byte("foo"), Value: []byte("bar"), Prefix: []byte("b")},
+	}})
+	require.True(t, errors.Is(err, api.ErrInvalidQuery), "QueryTxns should fail for invalid tag matches")
 }
 
 func testLoadIndex(t *testing.T, backend Backend) {
@@ -164,6 +270,27 @@ func testLoadIndex(t *testing.T, backend Backend) {
 	require.EqualValues(t, 42, round)
 }
 
+func testPrune(t *testing.T, backend Backend) {
+	ctx := context.Background()
+
+	var blockHash2 hash.Hash
+	blockHash2.FromBytes([]byte("this is a fake block hash 2"))
+
+	err := backend.Prune(ctx, 43)
+	require.NoError(t, err, "Prune")
+
+	_, err = backend.QueryBlock(ctx, blockHash2)
+	require.Equal(t, api.ErrNotFound, err, "QueryBlock must return a not found error after pruning")
+	_, err = backend.QueryTxnByIndex(ctx, 43, 0)
+	require.Equal(t, api.ErrNotFound, err, "QueryTxnByIndex must return a not found error after pruning")
+	_, _, _, err = backend.QueryTxn(ctx, []byte("foo"), []byte("bar"))
+	require.Equal(t, api.ErrNotFound, err, "QueryTxn must return a not found error after pruning")
+
+	// Other rounds should not be affected.
+	_, err = backend.QueryTxnByIndex(ctx, 42, 0)
+	require.NoError(t, err, "QueryTxnByIndex")
+}
+
 func testBackend(t *testing.T, factory BackendFactory) {
 	// Create a new random temporary directory under /tmp.
 	dataDir, err := ioutil.TempDir("", "oasis-client-indexer-test_")
@@ -188,8 +315,73 @@ func testBackend(t *testing.T, factory BackendFactory) {
 
 		testLoadIndex(t, backend)
 	})
+	t.Run("Prune", func(t *testing.T) {
+		var backend Backend
+		backend, err = factory(dataDir, id)
+		require.NoError(t, err, "New")
+		defer backend.Close()
+
+		testPrune(t, backend)
+	})
 }
 
 func TestBleveBackend(t *testing.T) {
 	testBackend(t, NewBleveBackend())
 }
+
+func TestSQLiteBackend(t *testing.T) {
+	testBackend(t, NewSQLiteBackend())
+
+	t.Run("QueryPlan", func(t *testing.T) {
+		require := require.New(t)
+
+		dataDir, err := ioutil.TempDir("", "oasis-runtime-tagindexer-test_")
+		require.NoError(err, "TempDir")
+		defer os.RemoveAll(dataDir)
+
+		backend, err := newSQLiteBackend(dataDir, common.Namespace{})
+		require.NoError(err, "newSQLiteBackend")
+		defer backend.Close()
+		db := backend.(*sqliteBackend).db
+
+		// Tag matches should be looked up in the tag index instead of checked for every transaction.
+		for _, filter := range []*api.QueryFilter{
+			{Tag: &api.TagMatch{Key: []byte("foo"), Value: []byte("bar")}},
+			{Tag: &api.TagMatch{Key: []byte("foo"), Prefix: []byte("b")}},
+			{Or: []*api.QueryFilter{
+				{Tag: &api.TagMatch{Key: []byte("foo"), Min: []byte("b")}},
+				{Tag: &api.TagMatch{Key: []byte("hello"), Max: []byte("w")}},
+			}},
+		} {
+			q := newTxnsQuery(&api.Query{Filter: filter, RoundMin: 10})
+			rows, err := db.Query("EXPLAIN QUERY PLAN "+q.expr.String(), q.args...)
+			require.NoError(err, "EXPLAIN QUERY PLAN")
+
+			var plan []string
+			for rows.Next() {
+				var (
+					id, parent, unused int
+					detail             string
+				)
+				require.NoError(rows.Scan(&id, &parent, &unused, &detail), "Scan")
+				plan = append(plan, detail)
+			}
+			require.NoError(rows.Err(), "rows.Err")
+			rows.Close()
+
+			planText := strings.Join(plan, "\n")
+			require.Contains(planText, "INDEX tags_key_value", "tags should be looked up by key and value")
+			require.NotContains(planText, "SCAN TABLE transactions", "transactions should not be scanned")
+			require.NotContains(planText, "SCAN t", "transactions should not be scanned")
+		}
+	})
+}
+
+func TestPrefixEnd(t *testing.T) {
+	require := require.New(t)
+
+	require.EqualValues([]byte("ac"), prefixEnd([]byte("ab")))
+	require.EqualValues([]byte("b"), prefixEnd([]byte("a\xff\xff")))
+	require.Nil(prefixEnd([]byte("\xff")))
+	require.Nil(prefixEnd(nil))
+}
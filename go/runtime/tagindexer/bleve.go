@@ -110,13 +110,96 @@ func queryByRound(round uint64) bleveQuery.Query {
 	return query
 }
 
+// tagField returns the name of the document field for the given tag key.
+func tagField(key []byte) string {
+	return fmt.Sprintf("%s.%s", fieldTags, string(key))
+}
+
 // queryByTag returns a query matching documents with the given tags.
 func queryByTag(key, value []byte) bleveQuery.Query {
 	query := bleve.NewTermQuery(string(value))
-	query.SetField(fmt.Sprintf("%s.%s", fieldTags, string(key)))
+	query.SetField(tagField(key))
 	return query
 }
 
+// queryByTagMatch returns a query matching documents with tags matching the given tag match.
+func queryByTagMatch(m *api.TagMatch) bleveQuery.Query {
+	switch {
+	case m.Value != nil:
+		return queryByTag(m.Key, m.Value)
+	case m.Prefix != nil:
+		query := bleve.NewPrefixQuery(string(m.Prefix))
+		query.SetField(tagField(m.Key))
+		return query
+	default:
+		inclusive := true
+		query := bleve.NewTermRangeInclusiveQuery(string(m.Min), string(m.Max), &inclusive, &inclusive)
+		query.SetField(tagField(m.Key))
+		return query
+	}
+}
+
+// queryByFilter returns a query matching transaction documents matching the given filter.
+func queryByFilter(f *api.QueryFilter) bleveQuery.Query {
+	switch {
+	case f.And != nil:
+		var qs []bleveQuery.Query
+		for _, child := range f.And {
+			qs = append(qs, queryByFilter(child))
+		}
+		return bleve.NewConjunctionQuery(qs...)
+	case f.Or != nil:
+		var qs []bleveQuery.Query
+		for _, child := range f.Or {
+			qs = append(qs, queryByFilter(child))
+		}
+		return bleve.NewDisjunctionQuery(qs...)
+	case f.Not != nil:
+		// A negation needs a positive clause to select documents from.
+		query := bleve.NewBooleanQuery()
+		query.AddMust(queryByKindTx)
+		query.AddMustNot(queryByFilter(f.Not))
+		return query
+	default:
+		return queryByTagMatch(f.Tag)
+	}
+}
+
+// queryByCursor returns a query matching transaction documents that come after the given cursor
+// in the given order.
+func queryByCursor(cursor *api.QueryCursor, order api.QueryOrder) bleveQuery.Query {
+	round := float64(cursor.Round)
+	index := float64(cursor.Index)
+	exclusive := false
+
+	var qRound, qIndex *bleveQuery.NumericRangeQuery
+	switch order {
+	case api.OrderDescending:
+		qRound = bleve.NewNumericRangeInclusiveQuery(nil, &round, nil, &exclusive)
+		qIndex = bleve.NewNumericRangeInclusiveQuery(nil, &index, nil, &exclusive)
+	default:
+		qRound = bleve.NewNumericRangeInclusiveQuery(&round, nil, &exclusive, nil)
+		qIndex = bleve.NewNumericRangeInclusiveQuery(&index, nil, &exclusive, nil)
+	}
+	qRound.SetField(fieldRound)
+	qIndex.SetField(fieldTxIndex)
+
+	return bleve.NewDisjunctionQuery(
+		qRound,
+		bleve.NewConjunctionQuery(queryByRound(cursor.Round), qIndex),
+	)
+}
+
+// sortOrder returns the bleve sort order for the given query order.
+func sortOrder(order api.QueryOrder) []string {
+	switch order {
+	case api.OrderDescending:
+		return []string{"-" + fieldRound, "-" + fieldTxIndex}
+	default:
+		return []string{fieldRound, fieldTxIndex}
+	}
+}
+
 func (b *bleveBackend) Index(
 	ctx context.Context,
 	round uint64,
@@ -208,6 +291,7 @@ func (b *bleveBackend) QueryTxn(ctx context.Context, key, value []byte) (uint64,
 	q := bleve.NewConjunctionQuery(queryByKindTx, queryByTag(key, value))
 	rq := bleve.NewSearchRequest(q)
 	rq.Size = 1
+	rq.SortBy(sortOrder(api.OrderAscending))
 
 	result, err := b.index.SearchInContext(ctx, rq)
 	if err != nil {
@@ -256,6 +340,10 @@ func (b *bleveBackend) QueryTxnByIndex(ctx context.Context, round uint64, index
 }
 
 func (b *bleveBackend) QueryTxns(ctx context.Context, query api.Query) (Results, error) {
+	if err := query.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
 	qs := []bleveQuery.Query{queryByKindTx}
 
 	// Filter by round.
@@ -294,8 +382,19 @@ func (b *bleveBackend) QueryTxns(ctx context.Context, query api.Query) (Results,
 		}
 	}
 
+	// Filter by boolean tag filter.
+	if query.Filter != nil {
+		qs = append(qs, queryByFilter(query.Filter))
+	}
+
+	// Filter by pagination cursor.
+	if query.Cursor != nil {
+		qs = append(qs, queryByCursor(query.Cursor, query.Order))
+	}
+
 	q := bleve.NewConjunctionQuery(qs...)
 	rq := bleve.NewSearchRequest(q)
+	rq.SortBy(sortOrder(query.Order))
 	if query.Limit > 0 {
 		rq.Size = int(query.Limit)
 	}
@@ -308,7 +407,7 @@ func (b *bleveBackend) QueryTxns(ctx context.Context, query api.Query) (Results,
 		return nil, err
 	}
 
-	results := make(Results)
+	results := make(Results, 0, len(result.Hits))
 	for _, hit := range result.Hits {
 		var decRound uint64
 		var decTxHash hash.Hash
@@ -317,7 +416,7 @@ func (b *bleveBackend) QueryTxns(ctx context.Context, query api.Query) (Results,
 			return nil, ErrCorrupted
 		}
 
-		results[decRound] = append(results[decRound], Result{TxHash: decTxHash, TxIndex: decTxIndex})
+		results = append(results, Result{Round: decRound, TxHash: decTxHash, TxIndex: decTxIndex})
 	}
 
 	return results, nil
@@ -0,0 +1,536 @@
+package tagindexer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	// Register the SQLite database driver.
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	"github.com/oasisprotocol/oasis-core/go/runtime/client/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+)
+
+const (
+	// SQLiteBackendName is the name of the SQLite backend.
+	SQLiteBackendName = "sqlite"
+
+	sqliteIndexFile = "tag-index.sqlite.db"
+)
+
+// sqliteSchema is the schema of the SQLite index database.
+//
+// The schema is considered a stable interface so that the database can be attached to and queried
+// by external tools. Rounds are stored as (signed) 64-bit integers, all hashes, transaction inputs
+// and outputs and tag keys and values are stored as raw bytes.
+const sqliteSchema = `
+-- Index metadata.
+CREATE TABLE IF NOT EXISTS metadata (
+	-- Metadata key (e.g., 'runtime_id').
+	key TEXT PRIMARY KEY,
+	-- Metadata value.
+	value BLOB NOT NULL
+);
+
+-- Indexed blocks, one row per round.
+CREATE TABLE IF NOT EXISTS blocks (
+	-- Runtime block round.
+	round INTEGER PRIMARY KEY,
+	-- Hash of the runtime block header.
+	block_hash BLOB NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS blocks_block_hash ON blocks (block_hash);
+
+-- Indexed transactions, one row per transaction in a block.
+CREATE TABLE IF NOT EXISTS transactions (
+	-- Runtime block round.
+	round INTEGER NOT NULL REFERENCES blocks (round) ON DELETE CASCADE,
+	-- Index of the transaction within the block.
+	tx_index INTEGER NOT NULL,
+	-- Hash of the transaction (input).
+	tx_hash BLOB NOT NULL,
+	-- Transaction input.
+	input BLOB NOT NULL,
+	-- Transaction output.
+	output BLOB NOT NULL,
+	PRIMARY KEY (round, tx_index)
+);
+CREATE INDEX IF NOT EXISTS transactions_tx_hash ON transactions (tx_hash);
+
+-- Indexed transaction tags, one row per tag emitted by a transaction.
+CREATE TABLE IF NOT EXISTS tags (
+	-- Runtime block round.
+	round INTEGER NOT NULL,
+	-- Index of the emitting transaction within the block.
+	tx_index INTEGER NOT NULL,
+	-- Tag key.
+	key BLOB NOT NULL,
+	-- Tag value.
+	value BLOB NOT NULL,
+	FOREIGN KEY (round, tx_index) REFERENCES transactions (round, tx_index) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS tags_key_value ON tags (key, value);
+CREATE INDEX IF NOT EXISTS tags_transaction ON tags (round, tx_index);
+`
+
+// sqliteMetaRuntimeID is the metadata key under which the runtime identifier is stored.
+const sqliteMetaRuntimeID = "runtime_id"
+
+var _ Backend = (*sqliteBackend)(nil)
+
+type sqliteBackend struct {
+	logger *logging.Logger
+
+	db *sql.DB
+
+	blockIndexedNotifier *pubsub.Broker
+}
+
+// sqlQuery is a partial SQL query expression together with its arguments.
+type sqlQuery struct {
+	expr strings.Builder
+	args []interface{}
+}
+
+func (q *sqlQuery) add(expr string, args ...interface{}) {
+	q.expr.WriteString(expr)
+	q.args = append(q.args, args...)
+}
+
+// roundRange restricts matched transactions to the given (inclusive) round range. Zero bounds
+// are not enforced.
+type roundRange struct {
+	min, max uint64
+}
+
+func (q *sqlQuery) addRoundRange(column string, r *roundRange) {
+	if r.min > 0 {
+		q.add(fmt.Sprintf(" AND %s >= ?", column), int64(r.min))
+	}
+	if r.max > 0 {
+		q.add(fmt.Sprintf(" AND %s <= ?", column), int64(r.max))
+	}
+}
+
+// addTagMatch adds a select statement returning the round and index of transactions with tags
+// matching the given tag match.
+//
+// Tag values are only compared against bounds so that the lookup is driven by the tags_key_value
+// index.
+func (q *sqlQuery) addTagMatch(m *api.TagMatch, r *roundRange) {
+	q.add("SELECT DISTINCT round, tx_index FROM tags WHERE key = ?", m.Key)
+	switch {
+	case m.Value != nil:
+		q.add(" AND value = ?", m.Value)
+	case m.Prefix != nil:
+		q.add(" AND value >= ?", m.Prefix)
+		if end := prefixEnd(m.Prefix); end != nil {
+			q.add(" AND value < ?", end)
+		}
+	default:
+		if m.Min != nil {
+			q.add(" AND value >= ?", m.Min)
+		}
+		if m.Max != nil {
+			q.add(" AND value <= ?", m.Max)
+		}
+	}
+	q.addRoundRange("round", r)
+}
+
+// prefixEnd returns the smallest value that is greater than all values with the given prefix or
+// nil in case there is no such value.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// addFilter adds a select statement returning the round and index of transactions matching the
+// given filter.
+func (q *sqlQuery) addFilter(f *api.QueryFilter, r *roundRange) {
+	switch {
+	case f.And != nil:
+		q.addCompound(" INTERSECT ", f.And, r)
+	case f.Or != nil:
+		q.addCompound(" UNION ", f.Or, r)
+	case f.Not != nil:
+		q.add("SELECT round, tx_index FROM transactions WHERE 1")
+		q.addRoundRange("round", r)
+		q.addCompound(" EXCEPT ", []*api.QueryFilter{f.Not}, r)
+	default:
+		q.addTagMatch(f.Tag, r)
+	}
+}
+
+// addCompound adds the select statements of the given filters combined with the given compound
+// operator. In case the operator is EXCEPT, the statements are appended to an existing one.
+func (q *sqlQuery) addCompound(op string, fs []*api.QueryFilter, r *roundRange) {
+	for i, child := range fs {
+		if i > 0 || op == " EXCEPT " {
+			q.add(op)
+		}
+		// Compound operators have the same precedence, so each operand is a separate subquery.
+		q.add("SELECT round, tx_index FROM (")
+		q.addFilter(child, r)
+		q.add(")")
+	}
+}
+
+func (b *sqliteBackend) Index(
+	ctx context.Context,
+	round uint64,
+	blockHash hash.Hash,
+	txs []*transaction.Transaction,
+	tags transaction.Tags,
+) error {
+	// The only reason why a list of transactions needs to be passed is to
+	// derive the transaction indices.
+	txIndices := make(map[hash.Hash]uint32)
+	for idx, tx := range txs {
+		txIndices[tx.Hash()] = uint32(idx)
+	}
+
+	dbTx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback() // nolint: errcheck
+
+	// Replace any existing entries for the same round.
+	if _, err = dbTx.ExecContext(ctx, "DELETE FROM blocks WHERE round = ?", int64(round)); err != nil {
+		return fmt.Errorf("tagindexer: failed to remove existing block: %w", err)
+	}
+	if _, err = dbTx.ExecContext(ctx,
+		"INSERT INTO blocks (round, block_hash) VALUES (?, ?)",
+		int64(round), blockHash[:],
+	); err != nil {
+		return fmt.Errorf("tagindexer: failed to index block: %w", err)
+	}
+
+	for idx, tx := range txs {
+		txHash := tx.Hash()
+		output := tx.Output
+		if output == nil {
+			output = []byte{}
+		}
+		if _, err = dbTx.ExecContext(ctx,
+			"INSERT INTO transactions (round, tx_index, tx_hash, input, output) VALUES (?, ?, ?, ?, ?)",
+			int64(round), idx, txHash[:], tx.Input, output,
+		); err != nil {
+			return fmt.Errorf("tagindexer: failed to index transaction: %w", err)
+		}
+	}
+
+	for _, tag := range tags {
+		txIndex, ok := txIndices[tag.TxHash]
+		if !ok {
+			// Tags can only be indexed for known transactions.
+			continue
+		}
+		if _, err = dbTx.ExecContext(ctx,
+			"INSERT INTO tags (round, tx_index, key, value) VALUES (?, ?, ?, ?)",
+			int64(round), txIndex, tag.Key, tag.Value,
+		); err != nil {
+			return fmt.Errorf("tagindexer: failed to index tag: %w", err)
+		}
+	}
+
+	if err = dbTx.Commit(); err != nil {
+		return err
+	}
+
+	b.blockIndexedNotifier.Broadcast(round)
+
+	return nil
+}
+
+func (b *sqliteBackend) QueryBlock(ctx context.Context, blockHash hash.Hash) (uint64, error) {
+	var round int64
+	err := b.db.QueryRowContext(ctx, "SELECT round FROM blocks WHERE block_hash = ?", blockHash[:]).Scan(&round)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, api.ErrNotFound
+	case err != nil:
+		return 0, err
+	}
+
+	return uint64(round), nil
+}
+
+func (b *sqliteBackend) QueryTxn(ctx context.Context, key, value []byte) (uint64, hash.Hash, uint32, error) {
+	var q sqlQuery
+	q.add("SELECT t.round, t.tx_hash, t.tx_index FROM tags g"+
+		" JOIN transactions t ON t.round = g.round AND t.tx_index = g.tx_index"+
+		" WHERE g.key = ? AND g.value = ?",
+		key, value,
+	)
+	q.add(" ORDER BY g.round, g.tx_index LIMIT 1")
+
+	results, err := b.queryResults(ctx, &q)
+	if err != nil {
+		return 0, hash.Hash{}, 0, err
+	}
+	if len(results) == 0 {
+		return 0, hash.Hash{}, 0, api.ErrNotFound
+	}
+
+	return results[0].Round, results[0].TxHash, results[0].TxIndex, nil
+}
+
+func (b *sqliteBackend) QueryTxnByIndex(ctx context.Context, round uint64, index uint32) (hash.Hash, error) {
+	var rawTxHash []byte
+	err := b.db.QueryRowContext(ctx,
+		"SELECT tx_hash FROM transactions WHERE round = ? AND tx_index = ?",
+		int64(round), index,
+	).Scan(&rawTxHash)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return hash.Hash{}, api.ErrNotFound
+	case err != nil:
+		return hash.Hash{}, err
+	}
+
+	var txHash hash.Hash
+	if err = txHash.UnmarshalBinary(rawTxHash); err != nil {
+		return hash.Hash{}, ErrCorrupted
+	}
+
+	return txHash, nil
+}
+
+// QueryTxns queries the transaction tag index of a given runtime with a complex query and returns
+// multiple results.
+//
+// Note that, unlike the bleve backend, all transactions are indexed (not only the ones that
+// emitted tags) so queries without tag conditions also match transactions without any tags.
+func (b *sqliteBackend) QueryTxns(ctx context.Context, query api.Query) (Results, error) {
+	if err := query.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
+	return b.queryResults(ctx, newTxnsQuery(&query))
+}
+
+// newTxnsQuery builds the SQL query for the given (validated) transaction query.
+func newTxnsQuery(query *api.Query) *sqlQuery {
+	// Combine key/value tag conditions with the boolean tag filter.
+	var filters []*api.QueryFilter
+	for _, cond := range query.Conditions {
+		if len(cond.Values) == 0 {
+			// No values (strange, but ok).
+			continue
+		}
+
+		var values []*api.QueryFilter
+		for _, v := range cond.Values {
+			values = append(values, &api.QueryFilter{Tag: &api.TagMatch{Key: cond.Key, Value: v}})
+		}
+		filters = append(filters, &api.QueryFilter{Or: values})
+	}
+	if query.Filter != nil {
+		filters = append(filters, query.Filter)
+	}
+
+	// Matching transactions are first determined from the tags and only then joined with the
+	// transactions table.
+	r := &roundRange{min: query.RoundMin, max: query.RoundMax}
+	var q sqlQuery
+	switch len(filters) {
+	case 0:
+		q.add("SELECT t.round, t.tx_hash, t.tx_index FROM transactions t WHERE 1")
+	default:
+		q.add("SELECT t.round, t.tx_hash, t.tx_index FROM (")
+		q.addFilter(&api.QueryFilter{And: filters}, r)
+		q.add(") m JOIN transactions t ON t.round = m.round AND t.tx_index = m.tx_index WHERE 1")
+	}
+
+	// Filter by round.
+	q.addRoundRange("t.round", r)
+
+	// Filter by pagination cursor.
+	cmp, order := ">", "ASC"
+	if query.Order == api.OrderDescending {
+		cmp, order = "<", "DESC"
+	}
+	if query.Cursor != nil {
+		round := int64(query.Cursor.Round)
+		q.add(
+			fmt.Sprintf(" AND (t.round %s ? OR (t.round = ? AND t.tx_index %s ?))", cmp, cmp),
+			round, round, query.Cursor.Index,
+		)
+	}
+
+	limit := int(query.Limit)
+	if limit == 0 || limit > maxQueryLimit {
+		limit = maxQueryLimit
+	}
+	q.add(fmt.Sprintf(" ORDER BY t.round %s, t.tx_index %s LIMIT ?", order, order), limit)
+
+	return &q
+}
+
+// queryResults executes a query returning round, transaction hash and transaction index columns.
+func (b *sqliteBackend) queryResults(ctx context.Context, q *sqlQuery) (Results, error) {
+	rows, err := b.db.QueryContext(ctx, q.expr.String(), q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := Results{}
+	for rows.Next() {
+		var (
+			round     int64
+			rawTxHash []byte
+			txIndex   uint32
+		)
+		if err = rows.Scan(&round, &rawTxHash, &txIndex); err != nil {
+			return nil, err
+		}
+
+		var txHash hash.Hash
+		if err = txHash.UnmarshalBinary(rawTxHash); err != nil {
+			return nil, ErrCorrupted
+		}
+
+		results = append(results, Result{Round: uint64(round), TxHash: txHash, TxIndex: txIndex})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (b *sqliteBackend) WaitBlockIndexed(ctx context.Context, round uint64) error {
+	sub := b.blockIndexedNotifier.Subscribe()
+	defer sub.Close()
+
+	ch := make(chan uint64)
+	sub.Unwrap(ch)
+
+	var exists bool
+	err := b.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM blocks WHERE round >= ?)",
+		int64(round),
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r := <-ch:
+			if r >= round {
+				return nil
+			}
+		}
+	}
+}
+
+func (b *sqliteBackend) Prune(ctx context.Context, round uint64) error {
+	// Transactions and tags are removed together with the block.
+	result, err := b.db.ExecContext(ctx, "DELETE FROM blocks WHERE round = ?", int64(round))
+	if err != nil {
+		return err
+	}
+
+	count, _ := result.RowsAffected()
+	b.logger.Debug("pruning items from index",
+		"round", round,
+		"item_count", count,
+	)
+
+	return nil
+}
+
+func (b *sqliteBackend) Close() {
+	if err := b.db.Close(); err != nil {
+		b.logger.Error("failed to close index",
+			"err", err,
+		)
+	}
+	b.db = nil
+}
+
+// checkRuntimeID stores the runtime identifier in a new index or makes sure that it matches the
+// one stored in an existing index.
+func (b *sqliteBackend) checkRuntimeID(runtimeID common.Namespace) error {
+	var rawID []byte
+	err := b.db.QueryRow("SELECT value FROM metadata WHERE key = ?", sqliteMetaRuntimeID).Scan(&rawID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = b.db.Exec("INSERT INTO metadata (key, value) VALUES (?, ?)", sqliteMetaRuntimeID, runtimeID[:])
+		return err
+	case err != nil:
+		return err
+	}
+
+	var id common.Namespace
+	if err = id.UnmarshalBinary(rawID); err != nil {
+		return ErrCorrupted
+	}
+	if !id.Equal(&runtimeID) {
+		return fmt.Errorf("tagindexer: index was created for a different runtime (expected: %s got: %s)",
+			runtimeID,
+			id,
+		)
+	}
+	return nil
+}
+
+func newSQLiteBackend(dataDir string, runtimeID common.Namespace) (Backend, error) {
+	b := &sqliteBackend{
+		logger:               logging.GetLogger("runtime/history/tagindexer/sqlite").With("runtime_id", runtimeID),
+		blockIndexedNotifier: pubsub.NewBroker(true),
+	}
+
+	path := filepath.Join(dataDir, sqliteIndexFile)
+	db, err := sql.Open("sqlite3", "file:"+path+"?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite only supports a single writer so avoid lock contention between connections.
+	db.SetMaxOpenConns(1)
+	b.db = db
+
+	if _, err = db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("tagindexer: failed to initialize schema: %w", err)
+	}
+	if err = b.checkRuntimeID(runtimeID); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	b.logger.Info("initialized tag indexer backend")
+
+	return b, nil
+}
+
+// NewSQLiteBackend creates a new SQLite indexer backend factory.
+//
+// The index is stored in an embedded SQLite database which can also be opened by external tools
+// for analytics. See sqliteSchema for a description of the schema.
+func NewSQLiteBackend() BackendFactory {
+	return newSQLiteBackend
+}
@@ -24,4 +24,13 @@ type TxnOutput struct {
 type TxnCheckResult struct {
 	// PredictedReadWriteSet is the predicted read/write set.
 	PredictedReadWriteSet ReadWriteSet `json:"predicted_rw_set"`
+
+	// Priority is the transaction priority. Transactions with higher priority are scheduled
+	// first by schedulers that support priorities.
+	Priority uint64 `json:"priority,omitempty"`
+	// Sender is an opaque runtime-specific identifier of the transaction sender.
+	Sender []byte `json:"sender,omitempty"`
+	// SenderSeq is the sender-specific sequence number of the transaction. A transaction
+	// with the same sender and sequence number as a queued transaction replaces it.
+	SenderSeq uint64 `json:"sender_seq,omitempty"`
 }
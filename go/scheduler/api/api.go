@@ -129,6 +129,10 @@ type Committee struct {
 
 	// ValidFor is the epoch for which the committee is valid.
 	ValidFor epochtime.EpochTime `json:"valid_for"`
+
+	// ProposerSeed is the seed used to select the transaction scheduler for each round. It is
+	// only set for executor committees of runtimes using random proposer rotation.
+	ProposerSeed []byte `json:"proposer_seed,omitempty"`
 }
 
 // Workers returns committee nodes with Worker role.
@@ -14,8 +14,14 @@ const (
 	// SlashDoubleSigning is slashing due to double signing.
 	SlashDoubleSigning SlashReason = 0
 
+	// SlashRuntimeIncorrectResults is slashing due to a runtime executor node submitting results
+	// that disagree with the discrepancy resolution majority.
+	SlashRuntimeIncorrectResults SlashReason = 0x80
+
 	// SlashDoubleSigningName is the string representation of SlashDoubleSigning.
 	SlashDoubleSigningName = "double-signing"
+	// SlashRuntimeIncorrectResultsName is the string representation of SlashRuntimeIncorrectResults.
+	SlashRuntimeIncorrectResultsName = "runtime-incorrect-results"
 )
 
 // String returns a string representation of a SlashReason.
@@ -23,6 +29,8 @@ func (s SlashReason) String() string {
 	switch s {
 	case SlashDoubleSigning:
 		return SlashDoubleSigningName
+	case SlashRuntimeIncorrectResults:
+		return SlashRuntimeIncorrectResultsName
 	default:
 		return "[unknown slash reason]"
 	}
@@ -33,6 +41,8 @@ func (s SlashReason) MarshalText() ([]byte, error) {
 	switch s {
 	case SlashDoubleSigning:
 		return []byte(SlashDoubleSigningName), nil
+	case SlashRuntimeIncorrectResults:
+		return []byte(SlashRuntimeIncorrectResultsName), nil
 	default:
 		return nil, fmt.Errorf("invalid slash reason: %d", s)
 	}
@@ -43,6 +53,8 @@ func (s *SlashReason) UnmarshalText(text []byte) error {
 	switch string(text) {
 	case SlashDoubleSigningName:
 		*s = SlashDoubleSigning
+	case SlashRuntimeIncorrectResultsName:
+		*s = SlashRuntimeIncorrectResults
 	default:
 		return fmt.Errorf("invalid slash reason: %s", string(text))
 	}
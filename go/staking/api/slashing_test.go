@@ -12,6 +12,7 @@ func TestSlashReason(t *testing.T) {
 	// Test valid SlashReasons.
 	for _, k := range []SlashReason{
 		SlashDoubleSigning,
+		SlashRuntimeIncorrectResults,
 	} {
 		enc, err := k.MarshalText()
 		require.NoError(err, "MarshalText")
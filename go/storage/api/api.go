@@ -107,6 +107,9 @@ type Config struct { // nolint: maligned
 
 	// ReadOnly will make the storage read-only.
 	ReadOnly bool
+
+	// EncryptionKey is the optional key used to encrypt the database at rest.
+	EncryptionKey []byte
 }
 
 // ToNodeDB converts from a Config to a node DB Config.
@@ -119,6 +122,7 @@ func (cfg *Config) ToNodeDB() *nodedb.Config {
 		MemoryOnly:       cfg.MemoryOnly,
 		ReadOnly:         cfg.ReadOnly,
 		DiscardWriteLogs: cfg.DiscardWriteLogs,
+		EncryptionKey:    cfg.EncryptionKey,
 	}
 }
 
@@ -327,4 +331,15 @@ type ClientBackend interface {
 	// This method will error in case the storage-client is not configured to
 	// track a specific committee.
 	EnsureCommitteeVersion(ctx context.Context, version int64) error
+
+	// PrefetchHints returns up to limit key prefixes that were most frequently requested via
+	// SyncGetPrefixes for the given runtime.
+	//
+	// In case the read cache is disabled, no hints are returned.
+	PrefetchHints(ns common.Namespace, limit int) [][]byte
+
+	// Prefetch populates the read cache with nodes for keys under the given prefixes.
+	//
+	// This method will error in case the read cache is disabled.
+	Prefetch(ctx context.Context, root Root, prefixes [][]byte, limit uint16) error
 }
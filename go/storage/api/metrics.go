@@ -7,6 +7,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
 	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/checkpoint"
 )
@@ -78,6 +79,20 @@ func (w *metricsWrapper) EnsureCommitteeVersion(ctx context.Context, version int
 	return ErrUnsupported
 }
 
+func (w *metricsWrapper) PrefetchHints(ns common.Namespace, limit int) [][]byte {
+	if clientBackend, ok := w.Backend.(ClientBackend); ok {
+		return clientBackend.PrefetchHints(ns, limit)
+	}
+	return nil
+}
+
+func (w *metricsWrapper) Prefetch(ctx context.Context, root Root, prefixes [][]byte, limit uint16) error {
+	if clientBackend, ok := w.Backend.(ClientBackend); ok {
+		return clientBackend.Prefetch(ctx, root, prefixes, limit)
+	}
+	return ErrUnsupported
+}
+
 func (w *metricsWrapper) Apply(ctx context.Context, request *ApplyRequest) ([]*Receipt, error) {
 	start := time.Now()
 	receipts, err := w.Backend.Apply(ctx, request)
@@ -0,0 +1,332 @@
+// Package archive implements a streaming archival format for runtime storage write logs.
+//
+// An archive consists of a header followed by a sequence of chunks, one for each runtime round.
+// Each chunk contains the round's block header together with the write logs that transform the
+// previous round's storage roots into the roots referenced by the block header. Every chunk
+// commits to the hash of the preceding chunk (or the archive header in case of the first chunk)
+// so that corruption, truncation in the middle of the stream and reordering are detected while
+// the archive is being read. The archive ends with a trailer committing to the number of chunks
+// and the hash of the last chunk so that truncation at a chunk boundary is detected as well.
+//
+// An archive either starts at the runtime's genesis block, in which case the first chunk contains
+// the complete genesis state, or it references a parent block whose state must already be
+// present in the node database the archive is imported into (e.g., restored from a checkpoint or
+// imported from a preceding archive).
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/writelog"
+)
+
+// ModuleName is the module name.
+const ModuleName = "storage/archive"
+
+// FormatVersion is the current archive format version.
+const FormatVersion uint16 = 1
+
+var (
+	// ErrCorrupted is the error returned when the archive is corrupted.
+	ErrCorrupted = errors.New(ModuleName, 1, "archive: corrupted archive")
+	// ErrUnsupportedVersion is the error returned when the archive format version is not
+	// supported.
+	ErrUnsupportedVersion = errors.New(ModuleName, 2, "archive: unsupported format version")
+	// ErrBadChain is the error returned when the chunks in the archive do not form a valid chain.
+	ErrBadChain = errors.New(ModuleName, 3, "archive: chunks do not form a valid chain")
+	// ErrMissingParentState is the error returned when the state of the archive's parent block
+	// is not available in the node database the archive is imported into.
+	ErrMissingParentState = errors.New(ModuleName, 4, "archive: parent state not available")
+
+	// magic is the magic value at the start of each archive.
+	magic = []byte("OASIS-WLA")
+)
+
+// Header is the archive header.
+type Header struct {
+	// Version is the archive format version.
+	Version uint16 `json:"version"`
+
+	// Namespace is the namespace of the runtime the archive is for.
+	Namespace common.Namespace `json:"namespace"`
+
+	// Parent is the header of the block preceding the first chunk. It is nil in case the
+	// archive starts at the genesis block.
+	Parent *block.Header `json:"parent,omitempty"`
+}
+
+// record is a single record in the archive following the header.
+type record struct {
+	Chunk   *Chunk   `json:"chunk,omitempty"`
+	Trailer *Trailer `json:"trailer,omitempty"`
+}
+
+// Trailer is the terminal archive record.
+type Trailer struct {
+	// Chunks is the number of chunks in the archive.
+	Chunks uint64 `json:"chunks"`
+
+	// LastHash is the hash of the last chunk (or the archive header in case the archive does
+	// not contain any chunks).
+	LastHash hash.Hash `json:"last_hash"`
+}
+
+// Chunk is an archive chunk containing the write logs for a single round.
+type Chunk struct {
+	// PreviousHash is the hash of the previous chunk (or the archive header in case of the
+	// first chunk).
+	PreviousHash hash.Hash `json:"previous_hash"`
+
+	// Block is the block header for the round.
+	Block block.Header `json:"block"`
+
+	// IOWriteLog is the write log that transforms an empty root into the block's I/O root.
+	IOWriteLog writelog.WriteLog `json:"io_write_log"`
+
+	// StateWriteLog is the write log that transforms the previous round's state root into
+	// the block's state root. For the genesis block it transforms an empty root.
+	StateWriteLog writelog.WriteLog `json:"state_write_log"`
+}
+
+// Hash returns the hash of the chunk.
+func (c *Chunk) Hash() hash.Hash {
+	return hash.NewFrom(c)
+}
+
+// chainState tracks the last chunk in the chain.
+type chainState struct {
+	namespace common.Namespace
+
+	lastHash  hash.Hash
+	lastBlock *block.Header
+	chunks    uint64
+}
+
+// verifyNext verifies that the given block header correctly follows the last chunk.
+func (s *chainState) verifyNext(blk *block.Header) error {
+	if !blk.Namespace.Equal(&s.namespace) {
+		return fmt.Errorf("%w: bad namespace (expected: %s got: %s)", ErrBadChain, s.namespace, blk.Namespace)
+	}
+	if s.lastBlock == nil {
+		// Without a parent, the first block must be a genesis block.
+		if !blk.PreviousHash.IsEmpty() {
+			return fmt.Errorf("%w: first block %d is not a genesis block", ErrBadChain, blk.Round)
+		}
+		return nil
+	}
+	if blk.Round != s.lastBlock.Round+1 {
+		return fmt.Errorf("%w: non-consecutive round (expected: %d got: %d)", ErrBadChain, s.lastBlock.Round+1, blk.Round)
+	}
+	lastBlockHash := s.lastBlock.EncodedHash()
+	if !blk.PreviousHash.Equal(&lastBlockHash) {
+		return fmt.Errorf("%w: block %d does not follow previous block", ErrBadChain, blk.Round)
+	}
+	return nil
+}
+
+// Writer is an archive writer.
+type Writer struct {
+	chainState
+
+	sw  *snappy.Writer
+	enc *cbor.Encoder
+}
+
+// WriteChunk appends a chunk with the given block header and write logs to the archive.
+func (w *Writer) WriteChunk(blk *block.Header, ioWriteLog, stateWriteLog writelog.WriteLog) error {
+	if err := w.verifyNext(blk); err != nil {
+		return err
+	}
+
+	chunk := Chunk{
+		PreviousHash:  w.lastHash,
+		Block:         *blk,
+		IOWriteLog:    ioWriteLog,
+		StateWriteLog: stateWriteLog,
+	}
+	if err := w.enc.Encode(&record{Chunk: &chunk}); err != nil {
+		return fmt.Errorf("archive: failed to encode chunk: %w", err)
+	}
+
+	w.lastHash = chunk.Hash()
+	w.lastBlock = &chunk.Block
+	w.chunks++
+	return nil
+}
+
+// LastBlock returns the block header of the last written chunk or the parent block header in
+// case no chunks have been written yet.
+func (w *Writer) LastBlock() *block.Header {
+	return w.lastBlock
+}
+
+// Close writes the archive trailer and flushes any buffered data to the underlying writer.
+// It must only be called once all chunks have been successfully written as otherwise the
+// archive will appear complete.
+//
+// It does not close the underlying writer.
+func (w *Writer) Close() error {
+	trailer := Trailer{
+		Chunks:   w.chunks,
+		LastHash: w.lastHash,
+	}
+	if err := w.enc.Encode(&record{Trailer: &trailer}); err != nil {
+		return fmt.Errorf("archive: failed to encode trailer: %w", err)
+	}
+	return w.sw.Close()
+}
+
+// NewWriter creates a new archive writer for the given runtime and writes the archive header.
+//
+// The parent is the header of the block preceding the first chunk that will be written and
+// should be nil in case the archive will start at the genesis block.
+func NewWriter(w io.Writer, namespace common.Namespace, parent *block.Header) (*Writer, error) {
+	if parent != nil && !parent.Namespace.Equal(&namespace) {
+		return nil, fmt.Errorf("%w: bad parent namespace (expected: %s got: %s)", ErrBadChain, namespace, parent.Namespace)
+	}
+
+	if _, err := w.Write(magic); err != nil {
+		return nil, fmt.Errorf("archive: failed to write magic: %w", err)
+	}
+
+	sw := snappy.NewBufferedWriter(w)
+	aw := &Writer{
+		chainState: chainState{
+			namespace: namespace,
+			lastBlock: parent,
+		},
+		sw:  sw,
+		enc: cbor.NewEncoder(sw),
+	}
+
+	hdr := Header{
+		Version:   FormatVersion,
+		Namespace: namespace,
+		Parent:    parent,
+	}
+	if err := aw.enc.Encode(&hdr); err != nil {
+		return nil, fmt.Errorf("archive: failed to encode header: %w", err)
+	}
+	aw.lastHash = hash.NewFrom(&hdr)
+
+	return aw, nil
+}
+
+// Reader is an archive reader.
+type Reader struct {
+	chainState
+
+	hdr  Header
+	dec  *cbor.Decoder
+	done bool
+}
+
+// Header returns the archive header.
+func (r *Reader) Header() *Header {
+	return &r.hdr
+}
+
+// Next reads and verifies the next chunk from the archive.
+//
+// It returns io.EOF when there are no more chunks and the archive trailer has been verified.
+func (r *Reader) Next() (*Chunk, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+
+	var rec record
+	if err := r.dec.Decode(&rec); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: truncated archive (missing trailer)", ErrCorrupted)
+		}
+		return nil, fmt.Errorf("%w: failed to decode chunk: %s", ErrCorrupted, err)
+	}
+	switch {
+	case rec.Chunk != nil && rec.Trailer == nil:
+	case rec.Trailer != nil && rec.Chunk == nil:
+		if err := r.verifyTrailer(rec.Trailer); err != nil {
+			return nil, err
+		}
+		r.done = true
+		return nil, io.EOF
+	default:
+		return nil, fmt.Errorf("%w: malformed record", ErrCorrupted)
+	}
+	chunk := rec.Chunk
+
+	if !chunk.PreviousHash.Equal(&r.lastHash) {
+		return nil, fmt.Errorf("%w: bad previous chunk hash (expected: %s got: %s)",
+			ErrCorrupted,
+			r.lastHash,
+			chunk.PreviousHash,
+		)
+	}
+	if err := r.verifyNext(&chunk.Block); err != nil {
+		return nil, err
+	}
+
+	r.lastHash = chunk.Hash()
+	r.lastBlock = &chunk.Block
+	r.chunks++
+	return chunk, nil
+}
+
+// verifyTrailer verifies the archive trailer against the chunks read so far and makes sure
+// that there is no data following the trailer.
+func (r *Reader) verifyTrailer(trailer *Trailer) error {
+	if trailer.Chunks != r.chunks {
+		return fmt.Errorf("%w: bad chunk count (expected: %d got: %d)", ErrCorrupted, trailer.Chunks, r.chunks)
+	}
+	if !trailer.LastHash.Equal(&r.lastHash) {
+		return fmt.Errorf("%w: bad last chunk hash (expected: %s got: %s)",
+			ErrCorrupted,
+			trailer.LastHash,
+			r.lastHash,
+		)
+	}
+
+	var rec record
+	if err := r.dec.Decode(&rec); err != io.EOF {
+		return fmt.Errorf("%w: data following the trailer", ErrCorrupted)
+	}
+	return nil
+}
+
+// NewReader creates a new archive reader and reads the archive header.
+func NewReader(r io.Reader) (*Reader, error) {
+	m := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, m); err != nil {
+		return nil, fmt.Errorf("%w: failed to read magic: %s", ErrCorrupted, err)
+	}
+	if !bytes.Equal(m, magic) {
+		return nil, fmt.Errorf("%w: bad magic", ErrCorrupted)
+	}
+
+	ar := &Reader{
+		dec: cbor.NewDecoder(snappy.NewReader(r)),
+	}
+	if err := ar.dec.Decode(&ar.hdr); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode header: %s", ErrCorrupted, err)
+	}
+	if ar.hdr.Version != FormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, ar.hdr.Version)
+	}
+
+	if ar.hdr.Parent != nil && !ar.hdr.Parent.Namespace.Equal(&ar.hdr.Namespace) {
+		return nil, fmt.Errorf("%w: bad parent namespace", ErrBadChain)
+	}
+
+	ar.namespace = ar.hdr.Namespace
+	ar.lastBlock = ar.hdr.Parent
+	ar.lastHash = hash.NewFrom(&ar.hdr)
+	return ar, nil
+}
@@ -0,0 +1,259 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/database"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/writelog"
+)
+
+const testRounds = 5
+
+var testNs = common.NewTestNamespaceFromSeed([]byte("storage archive test ns"), 0)
+
+func newTestBackend(require *require.Assertions) api.LocalBackend {
+	signer, err := memorySigner.NewSigner(rand.Reader)
+	require.NoError(err, "NewSigner()")
+
+	backend, err := database.New(&api.Config{
+		Backend:           database.BackendNameBadgerDB,
+		ApplyLockLRUSlots: 100,
+		Namespace:         testNs,
+		MaxCacheSize:      16 * 1024 * 1024,
+		NoFsync:           true,
+		MemoryOnly:        true,
+		Signer:            signer,
+	})
+	require.NoError(err, "database.New()")
+	return backend.(api.LocalBackend)
+}
+
+func commitTree(ctx context.Context, require *require.Assertions, ndb api.NodeDB, root api.Root, round uint64, wl writelog.WriteLog) hash.Hash {
+	tree := mkvs.NewWithRoot(nil, ndb, root)
+	defer tree.Close()
+
+	err := tree.ApplyWriteLog(ctx, writelog.NewStaticIterator(wl))
+	require.NoError(err, "ApplyWriteLog()")
+	_, rootHash, err := tree.Commit(ctx, testNs, round)
+	require.NoError(err, "Commit()")
+	return rootHash
+}
+
+// populate creates a chain of blocks together with the corresponding storage roots.
+func populate(ctx context.Context, require *require.Assertions, ndb api.NodeDB) []*block.Block {
+	var (
+		blocks    []*block.Block
+		stateRoot api.Root
+	)
+	stateRoot.Namespace = testNs
+	stateRoot.Hash.Empty()
+
+	for round := uint64(0); round < testRounds; round++ {
+		var blk *block.Block
+		switch round {
+		case 0:
+			blk = block.NewGenesisBlock(testNs, 0)
+		default:
+			blk = block.NewEmptyBlock(blocks[round-1], round, block.Normal)
+		}
+
+		emptyRoot := api.Root{Namespace: testNs, Version: round}
+		emptyRoot.Hash.Empty()
+
+		ioWriteLog := writelog.WriteLog{
+			{Key: []byte(fmt.Sprintf("input %d", round)), Value: []byte("input")},
+			{Key: []byte(fmt.Sprintf("output %d", round)), Value: []byte("output")},
+		}
+		stateWriteLog := writelog.WriteLog{
+			{Key: []byte(fmt.Sprintf("key %d", round)), Value: []byte(fmt.Sprintf("value %d", round))},
+			{Key: []byte("counter"), Value: []byte(fmt.Sprintf("%d", round))},
+		}
+
+		blk.Header.IORoot = commitTree(ctx, require, ndb, emptyRoot, round, ioWriteLog)
+		blk.Header.StateRoot = commitTree(ctx, require, ndb, stateRoot, round, stateWriteLog)
+		err := ndb.Finalize(ctx, round, []hash.Hash{blk.Header.IORoot, blk.Header.StateRoot})
+		require.NoError(err, "Finalize()")
+
+		stateRoot.Version = round
+		stateRoot.Hash = blk.Header.StateRoot
+		blocks = append(blocks, blk)
+	}
+	return blocks
+}
+
+func TestArchive(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	src := newTestBackend(require)
+	defer src.Cleanup()
+	blocks := populate(ctx, require, src.NodeDB())
+
+	getBlock := func(ctx context.Context, round uint64) (*block.Block, error) {
+		return blocks[round], nil
+	}
+
+	exportArchive := func(parent *block.Header, startRound, endRound uint64) []byte {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, testNs, parent)
+		require.NoError(err, "NewWriter()")
+		err = Export(ctx, w, src, getBlock, startRound, endRound)
+		require.NoError(err, "Export()")
+		err = w.Close()
+		require.NoError(err, "Close()")
+		return buf.Bytes()
+	}
+	importArchive := func(ndb api.NodeDB, archive []byte) (*block.Header, error) {
+		r, err := NewReader(bytes.NewReader(archive))
+		require.NoError(err, "NewReader()")
+		require.EqualValues(testNs, r.Header().Namespace, "archive namespace should be correct")
+		return Import(ctx, r, ndb)
+	}
+
+	// Export the chain into two consecutive archives.
+	genesisArchive := exportArchive(nil, 0, 2)
+	nextArchive := exportArchive(&blocks[2].Header, 3, testRounds-1)
+
+	// Exporting non-consecutive rounds should fail.
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testNs, &blocks[2].Header)
+	require.NoError(err, "NewWriter()")
+	err = Export(ctx, w, src, getBlock, 1, 1)
+	require.Error(err, "Export() should fail when rounds are not consecutive")
+	require.True(errors.Is(err, ErrBadChain), "Export() should fail with ErrBadChain")
+
+	// Import into an empty node database.
+	dst := newTestBackend(require)
+	defer dst.Cleanup()
+
+	_, err = importArchive(dst.NodeDB(), nextArchive)
+	require.Error(err, "Import() should fail without parent state")
+	require.True(errors.Is(err, ErrMissingParentState), "Import() should fail with ErrMissingParentState")
+
+	lastBlock, err := importArchive(dst.NodeDB(), genesisArchive)
+	require.NoError(err, "Import(genesis)")
+	require.EqualValues(2, lastBlock.Round, "last imported round should be correct")
+	lastBlock, err = importArchive(dst.NodeDB(), nextArchive)
+	require.NoError(err, "Import(next)")
+	require.EqualValues(testRounds-1, lastBlock.Round, "last imported round should be correct")
+
+	for round := uint64(0); round < testRounds; round++ {
+		for _, root := range blocks[round].Header.StorageRoots() {
+			require.True(dst.NodeDB().HasRoot(root), "imported root should exist (round %d)", round)
+		}
+	}
+	latest, err := dst.NodeDB().GetLatestVersion(ctx)
+	require.NoError(err, "GetLatestVersion()")
+	require.EqualValues(testRounds-1, latest, "all rounds should be finalized")
+
+	// Verify that the imported state matches the source state.
+	tree := mkvs.NewWithRoot(nil, dst.NodeDB(), blocks[testRounds-1].Header.StorageRoots()[1])
+	defer tree.Close()
+	for round := uint64(0); round < testRounds; round++ {
+		value, gerr := tree.Get(ctx, []byte(fmt.Sprintf("key %d", round)))
+		require.NoError(gerr, "Get()")
+		require.EqualValues(fmt.Sprintf("value %d", round), value, "imported state should be correct")
+	}
+
+	// Corrupted archives should be rejected.
+	corrupted := append([]byte{}, genesisArchive...)
+	corrupted[len(corrupted)/2] ^= 0xff
+	r, err := NewReader(bytes.NewReader(corrupted))
+	if err == nil {
+		for {
+			if _, err = r.Next(); err != nil {
+				break
+			}
+		}
+	}
+	require.Error(err, "reading a corrupted archive should fail")
+	require.NotEqual(io.EOF, err, "reading a corrupted archive should not succeed")
+}
+
+func TestArchiveTruncated(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	src := newTestBackend(require)
+	defer src.Cleanup()
+	blocks := populate(ctx, require, src.NodeDB())
+
+	getBlock := func(ctx context.Context, round uint64) (*block.Block, error) {
+		return blocks[round], nil
+	}
+
+	// Write an archive truncated at a chunk boundary (without a trailer).
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testNs, nil)
+	require.NoError(err, "NewWriter()")
+	err = Export(ctx, w, src, getBlock, 0, 2)
+	require.NoError(err, "Export()")
+	err = w.sw.Flush()
+	require.NoError(err, "Flush()")
+
+	dst := newTestBackend(require)
+	defer dst.Cleanup()
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(err, "NewReader()")
+	_, err = Import(ctx, r, dst.NodeDB())
+	require.Error(err, "Import() should fail on a truncated archive")
+	require.True(errors.Is(err, ErrCorrupted), "Import() should fail with ErrCorrupted")
+
+	// Trailers not matching the chunks should be rejected.
+	buf.Reset()
+	w, err = NewWriter(&buf, testNs, nil)
+	require.NoError(err, "NewWriter()")
+	err = Export(ctx, w, src, getBlock, 0, 2)
+	require.NoError(err, "Export()")
+	w.chunks--
+	err = w.Close()
+	require.NoError(err, "Close()")
+
+	r, err = NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(err, "NewReader()")
+	_, err = Import(ctx, r, dst.NodeDB())
+	require.Error(err, "Import() should fail on a bad trailer")
+	require.True(errors.Is(err, ErrCorrupted), "Import() should fail with ErrCorrupted")
+}
+
+func TestArchiveRootMismatch(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	src := newTestBackend(require)
+	defer src.Cleanup()
+	blocks := populate(ctx, require, src.NodeDB())
+
+	// Write a chunk with write logs that do not result in the block's roots.
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testNs, nil)
+	require.NoError(err, "NewWriter()")
+	err = w.WriteChunk(&blocks[0].Header, nil, writelog.WriteLog{{Key: []byte("key"), Value: []byte("value")}})
+	require.NoError(err, "WriteChunk()")
+	err = w.Close()
+	require.NoError(err, "Close()")
+
+	dst := newTestBackend(require)
+	defer dst.Cleanup()
+
+	r, err := NewReader(&buf)
+	require.NoError(err, "NewReader()")
+	_, err = Import(ctx, r, dst.NodeDB())
+	require.Error(err, "Import() should fail on root mismatch")
+	require.True(errors.Is(err, api.ErrExpectedRootMismatch), "Import() should fail with ErrExpectedRootMismatch")
+}
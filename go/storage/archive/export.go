@@ -0,0 +1,128 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/writelog"
+)
+
+// BlockGetter is a function that returns the runtime block for the given round.
+type BlockGetter func(ctx context.Context, round uint64) (*block.Block, error)
+
+// Export appends chunks for all rounds in the [startRound, endRound] range to the archive.
+//
+// State write logs are differences against the previous round's state root, except for the
+// genesis block whose state write log contains the complete genesis state.
+func Export(
+	ctx context.Context,
+	w *Writer,
+	backend api.Backend,
+	getBlock BlockGetter,
+	startRound uint64,
+	endRound uint64,
+) error {
+	if startRound > endRound {
+		return fmt.Errorf("archive: start round %d is after end round %d", startRound, endRound)
+	}
+	if lastBlock := w.LastBlock(); lastBlock != nil && lastBlock.Round+1 != startRound {
+		return fmt.Errorf("%w: non-consecutive round (expected: %d got: %d)", ErrBadChain, lastBlock.Round+1, startRound)
+	}
+
+	for round := startRound; round <= endRound; round++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		blk, err := getBlock(ctx, round)
+		if err != nil {
+			return fmt.Errorf("archive: failed to get block %d: %w", round, err)
+		}
+
+		var (
+			ioWriteLog    writelog.WriteLog
+			stateWriteLog writelog.WriteLog
+		)
+		roots := blk.Header.StorageRoots()
+		ioRoot, stateRoot := roots[0], roots[1]
+
+		emptyRoot := api.Root{
+			Namespace: blk.Header.Namespace,
+			Version:   round,
+		}
+		emptyRoot.Hash.Empty()
+
+		if ioWriteLog, err = getWriteLog(ctx, backend, emptyRoot, ioRoot, false); err != nil {
+			return fmt.Errorf("archive: failed to get I/O write log for round %d: %w", round, err)
+		}
+
+		switch lastBlock := w.LastBlock(); lastBlock {
+		case nil:
+			stateWriteLog, err = getWriteLog(ctx, backend, emptyRoot, stateRoot, true)
+		default:
+			prevStateRoot := lastBlock.StorageRoots()[1]
+			stateWriteLog, err = getWriteLog(ctx, backend, prevStateRoot, stateRoot, false)
+		}
+		if err != nil {
+			return fmt.Errorf("archive: failed to get state write log for round %d: %w", round, err)
+		}
+
+		if err = w.WriteChunk(&blk.Header, ioWriteLog, stateWriteLog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getWriteLog returns the write log that transforms the start root into the end root. In case
+// full is set, the start root must be an empty root and the write log is constructed by
+// iterating over the complete end root instead of requesting a stored write log.
+func getWriteLog(ctx context.Context, backend api.Backend, startRoot, endRoot api.Root, full bool) (writelog.WriteLog, error) {
+	// Roots that have not changed do not need a write log.
+	if startRoot.Hash.Equal(&endRoot.Hash) {
+		return nil, nil
+	}
+
+	if full {
+		tree := mkvs.NewWithRoot(backend, nil, endRoot)
+		defer tree.Close()
+
+		it := tree.NewIterator(ctx, mkvs.IteratorPrefetch(10_000))
+		defer it.Close()
+
+		var wl writelog.WriteLog
+		for it.Rewind(); it.Valid(); it.Next() {
+			wl = append(wl, writelog.LogEntry{Key: it.Key(), Value: it.Value()})
+		}
+		if it.Err() != nil {
+			return nil, it.Err()
+		}
+		return wl, nil
+	}
+
+	it, err := backend.GetDiff(ctx, &api.GetDiffRequest{StartRoot: startRoot, EndRoot: endRoot})
+	if err != nil {
+		return nil, err
+	}
+
+	var wl writelog.WriteLog
+	for {
+		more, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			break
+		}
+
+		entry, err := it.Value()
+		if err != nil {
+			return nil, err
+		}
+		wl = append(wl, entry)
+	}
+	return wl, nil
+}
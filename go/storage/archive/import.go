@@ -0,0 +1,119 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/storage/api"
+)
+
+// importApplyLockSlots is the number of apply lock slots used by the root cache during import.
+const importApplyLockSlots = 16
+
+// Import applies all chunks from the archive to the given node database, verifying that the
+// resulting I/O and state roots match the ones in the corresponding block headers, and finalizes
+// each imported round.
+//
+// In case the archive has a parent block, the parent's state root must already be present in
+// the node database.
+//
+// It returns the block header of the last imported chunk.
+func Import(ctx context.Context, r *Reader, ndb api.NodeDB) (*block.Header, error) {
+	lastBlock := r.Header().Parent
+	if lastBlock != nil && !ndb.HasRoot(lastBlock.StorageRoots()[1]) {
+		return nil, fmt.Errorf("%w: state root %s at round %d",
+			ErrMissingParentState,
+			lastBlock.StateRoot,
+			lastBlock.Round,
+		)
+	}
+
+	rootCache, err := api.NewRootCache(ndb, nil, importApplyLockSlots, false)
+	if err != nil {
+		return nil, fmt.Errorf("archive: failed to create root cache: %w", err)
+	}
+
+	var imported bool
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		chunk, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err = importChunk(ctx, rootCache, ndb, lastBlock, chunk); err != nil {
+			return nil, err
+		}
+		lastBlock = &chunk.Block
+		imported = true
+	}
+
+	if !imported {
+		return nil, fmt.Errorf("%w: archive does not contain any chunks", ErrCorrupted)
+	}
+	return lastBlock, nil
+}
+
+func importChunk(
+	ctx context.Context,
+	rootCache *api.RootCache,
+	ndb api.NodeDB,
+	lastBlock *block.Header,
+	chunk *Chunk,
+) error {
+	blk := &chunk.Block
+
+	var emptyHash hash.Hash
+	emptyHash.Empty()
+
+	// The I/O root is always derived from an empty root in the same round.
+	if _, err := rootCache.Apply(
+		ctx,
+		blk.Namespace,
+		blk.Round,
+		emptyHash,
+		blk.Round,
+		blk.IORoot,
+		chunk.IOWriteLog,
+	); err != nil {
+		return fmt.Errorf("archive: failed to apply I/O write log for round %d: %w", blk.Round, err)
+	}
+
+	// The state root is derived from the previous round's state root, except for the genesis
+	// block where it is derived from an empty root in the same round.
+	srcRound, srcRoot := blk.Round, emptyHash
+	if lastBlock != nil {
+		srcRound, srcRoot = lastBlock.Round, lastBlock.StateRoot
+	}
+	if _, err := rootCache.Apply(
+		ctx,
+		blk.Namespace,
+		srcRound,
+		srcRoot,
+		blk.Round,
+		blk.StateRoot,
+		chunk.StateWriteLog,
+	); err != nil {
+		return fmt.Errorf("archive: failed to apply state write log for round %d: %w", blk.Round, err)
+	}
+
+	err := ndb.Finalize(ctx, blk.Round, []hash.Hash{blk.IORoot, blk.StateRoot})
+	switch {
+	case err == nil:
+	case errors.Is(err, api.ErrAlreadyFinalized):
+		// The round has already been imported before.
+	default:
+		return fmt.Errorf("archive: failed to finalize round %d: %w", blk.Round, err)
+	}
+	return nil
+}
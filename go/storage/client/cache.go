@@ -0,0 +1,259 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/spf13/viper"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cache/lru"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
+)
+
+// maxTrackedPrefixes is the maximum number of distinct prefixes for which prefetch statistics
+// are tracked per runtime.
+const maxTrackedPrefixes = 1024
+
+var (
+	sharedCacheOnce sync.Once
+	sharedCache     *nodeCache
+)
+
+// getSharedNodeCache returns the node cache shared between all storage clients created by this
+// process or nil in case the cache is disabled.
+func getSharedNodeCache() *nodeCache {
+	sharedCacheOnce.Do(func() {
+		capacity := uint64(viper.GetSizeInBytes(CfgCacheMaxSize))
+		if capacity == 0 {
+			return
+		}
+
+		var err error
+		if sharedCache, err = newNodeCache(capacity); err != nil {
+			// This can only fail due to invalid options which are static.
+			panic(fmt.Errorf("storage/client: failed to create node cache: %w", err))
+		}
+	})
+	return sharedCache
+}
+
+// nodeCache is a read-through cache of verified MKVS nodes keyed by node hash.
+//
+// As nodes are content-addressed, the cache can be safely shared between storage clients for
+// different runtimes.
+type nodeCache struct {
+	sync.Mutex
+
+	nodes    *lru.Cache
+	verifier syncer.ProofVerifier
+
+	prefixStats map[common.Namespace]map[string]uint64
+}
+
+func (c *nodeCache) get(h hash.Hash) node.Node {
+	v, ok := c.nodes.Get(h)
+	if !ok {
+		return nil
+	}
+	return v.(node.Node)
+}
+
+// insert adds all nodes contained in the given verified subtree to the cache.
+func (c *nodeCache) insert(ptr *node.Pointer) {
+	if ptr == nil || ptr.Node == nil {
+		return
+	}
+
+	// Only store nodes with hash references to children so that the cached nodes do not keep
+	// complete subtrees alive. Leaf nodes of internal nodes are always kept with the internal
+	// node as they cannot be addressed separately.
+	if _, ok := c.nodes.Peek(ptr.Hash); !ok {
+		_ = c.nodes.Put(ptr.Hash, ptr.Node.ExtractUnchecked())
+	}
+
+	if n, ok := ptr.Node.(*node.InternalNode); ok {
+		c.insert(n.Left)
+		c.insert(n.Right)
+	}
+}
+
+// verifyAndInsert verifies the proof against the given tree and inserts all included nodes into
+// the cache.
+func (c *nodeCache) verifyAndInsert(ctx context.Context, tree *api.TreeID, proof *api.Proof) error {
+	// The proof can either be for the requested position or for the tree root.
+	var expectedRoot hash.Hash
+	switch {
+	case proof.UntrustedRoot.Equal(&tree.Position):
+		expectedRoot = tree.Position
+	case proof.UntrustedRoot.Equal(&tree.Root.Hash):
+		expectedRoot = tree.Root.Hash
+	default:
+		cacheBadProofs.With(runtimeLabels(tree.Root.Namespace)).Inc()
+		return fmt.Errorf("storage/client: got proof for unexpected root (%s)", proof.UntrustedRoot)
+	}
+
+	subtree, err := c.verifier.VerifyProof(ctx, expectedRoot, proof)
+	if err != nil {
+		cacheBadProofs.With(runtimeLabels(tree.Root.Namespace)).Inc()
+		return fmt.Errorf("storage/client: bad proof: %w", err)
+	}
+
+	c.insert(subtree)
+	cacheSize.Set(float64(c.nodes.Size()))
+	return nil
+}
+
+// syncGet attempts to serve the given request from cached nodes only. It returns nil in case
+// any of the nodes required to generate the proof is not cached.
+func (c *nodeCache) syncGet(ctx context.Context, request *api.GetRequest) (*api.ProofResponse, error) {
+	pb := syncer.NewProofBuilder(request.Tree.Root.Hash, request.Tree.Position)
+	found, err := c.lookup(ctx, pb, request.Tree.Root.Hash, 0, request.Key, request.IncludeSiblings, false)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		cacheMisses.With(runtimeLabels(request.Tree.Root.Namespace)).Inc()
+		return nil, nil
+	}
+	cacheHits.With(runtimeLabels(request.Tree.Root.Namespace)).Inc()
+
+	proof, err := pb.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &api.ProofResponse{Proof: *proof}, nil
+}
+
+// lookup traverses the cached nodes in the same way as a key lookup on a storage node would,
+// including all visited nodes in the proof builder.
+//
+// It returns false in case any of the required nodes is not cached.
+func (c *nodeCache) lookup(
+	ctx context.Context,
+	pb *syncer.ProofBuilder,
+	h hash.Hash,
+	bitDepth node.Depth,
+	key node.Key,
+	includeSiblings bool,
+	stop bool,
+) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if h.IsEmpty() {
+		// Reached a nil node, there is nothing here.
+		return true, nil
+	}
+
+	nd := c.get(h)
+	if nd == nil {
+		return false, nil
+	}
+	pb.Include(nd)
+
+	// Siblings are only included in the proof and not traversed further.
+	if stop {
+		return true, nil
+	}
+
+	n, ok := nd.(*node.InternalNode)
+	if !ok {
+		// Reached a leaf node.
+		return true, nil
+	}
+	bitLength := bitDepth + n.LabelBitLength
+
+	switch {
+	case key.BitLength() == bitLength:
+		// Lookup key ends here, the leaf node is always included with the internal node.
+		if !includeSiblings {
+			return true, nil
+		}
+		for _, child := range []*node.Pointer{n.Left, n.Right} {
+			if found, err := c.lookup(ctx, pb, child.GetHash(), bitLength, key, includeSiblings, true); !found || err != nil {
+				return found, err
+			}
+		}
+		return true, nil
+	case key.BitLength() < bitLength:
+		// Lookup key is too short for the current label. It's not stored.
+		return true, nil
+	}
+
+	next, sibling := n.Left, n.Right
+	if key.GetBit(bitLength) {
+		next, sibling = n.Right, n.Left
+	}
+	if found, err := c.lookup(ctx, pb, next.GetHash(), bitLength, key, includeSiblings, false); !found || err != nil {
+		return found, err
+	}
+	if includeSiblings {
+		return c.lookup(ctx, pb, sibling.GetHash(), bitLength, key, includeSiblings, true)
+	}
+	return true, nil
+}
+
+// recordPrefixes updates the prefetch statistics for the given runtime.
+func (c *nodeCache) recordPrefixes(ns common.Namespace, prefixes [][]byte) {
+	c.Lock()
+	defer c.Unlock()
+
+	stats := c.prefixStats[ns]
+	if stats == nil {
+		stats = make(map[string]uint64)
+		c.prefixStats[ns] = stats
+	}
+	for _, prefix := range prefixes {
+		if _, ok := stats[string(prefix)]; !ok && len(stats) >= maxTrackedPrefixes {
+			continue
+		}
+		stats[string(prefix)]++
+	}
+}
+
+// prefetchHints returns up to limit most frequently prefetched prefixes for the given runtime.
+func (c *nodeCache) prefetchHints(ns common.Namespace, limit int) [][]byte {
+	c.Lock()
+	defer c.Unlock()
+
+	stats := c.prefixStats[ns]
+	prefixes := make([]string, 0, len(stats))
+	for prefix := range stats {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		if stats[prefixes[i]] != stats[prefixes[j]] {
+			return stats[prefixes[i]] > stats[prefixes[j]]
+		}
+		return prefixes[i] < prefixes[j]
+	})
+	if len(prefixes) > limit {
+		prefixes = prefixes[:limit]
+	}
+
+	hints := make([][]byte, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		hints = append(hints, []byte(prefix))
+	}
+	return hints
+}
+
+func newNodeCache(capacity uint64) (*nodeCache, error) {
+	nodes, err := lru.New(lru.Capacity(capacity, true))
+	if err != nil {
+		return nil, err
+	}
+
+	initMetrics()
+
+	return &nodeCache{
+		nodes:       nodes,
+		prefixStats: make(map[common.Namespace]map[string]uint64),
+	}, nil
+}
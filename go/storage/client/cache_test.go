@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
+)
+
+var testNs = common.NewTestNamespaceFromSeed([]byte("storage client cache test ns"), 0)
+
+// cachingSyncer is a read syncer that serves requests from the node cache, falling back to the
+// remote read syncer in the same way as the storage client does.
+type cachingSyncer struct {
+	syncer.ReadSyncer
+
+	cache  *nodeCache
+	remote syncer.ReadSyncer
+
+	remoteGets int
+}
+
+func (s *cachingSyncer) SyncGet(ctx context.Context, request *api.GetRequest) (*api.ProofResponse, error) {
+	rsp, err := s.cache.syncGet(ctx, request)
+	if err != nil || rsp != nil {
+		return rsp, err
+	}
+
+	s.remoteGets++
+	if rsp, err = s.remote.SyncGet(ctx, request); err != nil {
+		return nil, err
+	}
+	if err = s.cache.verifyAndInsert(ctx, &request.Tree, &rsp.Proof); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+func TestNodeCache(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	tree := mkvs.New(nil, nil)
+	defer tree.Close()
+	for i := 0; i < 100; i++ {
+		err := tree.Insert(ctx, []byte(fmt.Sprintf("key %d", i)), []byte(fmt.Sprintf("value %d", i)))
+		require.NoError(err, "Insert")
+	}
+	_, rootHash, err := tree.Commit(ctx, testNs, 0)
+	require.NoError(err, "Commit")
+	root := api.Root{Namespace: testNs, Hash: rootHash}
+
+	cache, err := newNodeCache(16 * 1024 * 1024)
+	require.NoError(err, "newNodeCache")
+	rs := &cachingSyncer{cache: cache, remote: tree}
+
+	// Reads from the first tree should all be fetched from the remote.
+	readAll := func() {
+		remoteTree := mkvs.NewWithRoot(rs, nil, root)
+		defer remoteTree.Close()
+
+		for i := 0; i < 100; i++ {
+			value, gerr := remoteTree.Get(ctx, []byte(fmt.Sprintf("key %d", i)))
+			require.NoError(gerr, "Get")
+			require.EqualValues(fmt.Sprintf("value %d", i), value, "value should be correct")
+		}
+	}
+	readAll()
+	remoteGets := rs.remoteGets
+	require.NotZero(remoteGets, "reads should go to the remote")
+
+	// Reads from a fresh tree should be served from the shared cache and the generated proofs
+	// should verify.
+	readAll()
+	require.Equal(remoteGets, rs.remoteGets, "reads should be served from the cache")
+
+	// Proofs for unexpected roots should be rejected.
+	rsp, err := tree.SyncGet(ctx, &api.GetRequest{
+		Tree: api.TreeID{Root: root, Position: root.Hash},
+		Key:  []byte("key 1"),
+	})
+	require.NoError(err, "SyncGet")
+	otherRoot := root
+	otherRoot.Hash.FromBytes([]byte("other root"))
+	err = cache.verifyAndInsert(ctx, &api.TreeID{Root: otherRoot, Position: otherRoot.Hash}, &rsp.Proof)
+	require.Error(err, "verifyAndInsert should fail for proof with unexpected root")
+
+	// Tampered proofs should be rejected.
+	rsp.Proof.Entries[0] = append([]byte{}, rsp.Proof.Entries[0]...)
+	rsp.Proof.Entries[0][len(rsp.Proof.Entries[0])-1] ^= 0xff
+	err = cache.verifyAndInsert(ctx, &api.TreeID{Root: root, Position: root.Hash}, &rsp.Proof)
+	require.Error(err, "verifyAndInsert should fail for tampered proof")
+}
+
+func TestNodeCachePrefetchHints(t *testing.T) {
+	require := require.New(t)
+
+	cache, err := newNodeCache(1024)
+	require.NoError(err, "newNodeCache")
+
+	cache.recordPrefixes(testNs, [][]byte{[]byte("a"), []byte("b")})
+	cache.recordPrefixes(testNs, [][]byte{[]byte("b"), []byte("c")})
+	cache.recordPrefixes(testNs, [][]byte{[]byte("b"), []byte("c")})
+
+	require.EqualValues([][]byte{[]byte("b"), []byte("c")}, cache.prefetchHints(testNs, 2), "hints should be ordered by frequency")
+	require.Len(cache.prefetchHints(testNs, 10), 3, "all hints should be returned")
+
+	otherNs := common.NewTestNamespaceFromSeed([]byte("storage client cache test other ns"), 0)
+	require.Empty(cache.prefetchHints(otherNs, 10), "hints should be tracked per runtime")
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/mathrand"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
 	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
@@ -31,8 +32,13 @@ var (
 	_ api.ClientBackend = (*storageClientBackend)(nil)
 )
 
-// ErrStorageNotAvailable is the error returned when no storage node is available.
-var ErrStorageNotAvailable = errors.New("storage/client: storage not available")
+var (
+	// ErrStorageNotAvailable is the error returned when no storage node is available.
+	ErrStorageNotAvailable = errors.New("storage/client: storage not available")
+	// ErrReadQuorumNotReached is the error returned when not enough storage nodes returned
+	// matching responses for a read.
+	ErrReadQuorumNotReached = errors.New("storage/client: read quorum not reached")
+)
 
 const (
 	retryInterval = 1 * time.Second
@@ -48,6 +54,10 @@ type storageClientBackend struct {
 
 	nodesClient grpc.NodesClient
 	runtime     registry.RuntimeDescriptorProvider
+
+	cache       *nodeCache
+	divergences *divergenceDetector
+	readQuorum  int
 }
 
 // Implements api.StorageClient.
@@ -64,6 +74,45 @@ func (b *storageClientBackend) EnsureCommitteeVersion(ctx context.Context, versi
 	return b.nodesClient.EnsureVersion(ctx, version)
 }
 
+// Implements api.StorageClient.
+func (b *storageClientBackend) PrefetchHints(ns common.Namespace, limit int) [][]byte {
+	if b.cache == nil {
+		return nil
+	}
+	return b.cache.prefetchHints(ns, limit)
+}
+
+// Implements api.StorageClient.
+func (b *storageClientBackend) Prefetch(ctx context.Context, root api.Root, prefixes [][]byte, limit uint16) error {
+	if b.cache == nil {
+		return api.ErrUnsupported
+	}
+	if len(prefixes) == 0 {
+		return nil
+	}
+
+	_, err := b.readWithClient(
+		ctx,
+		root.Namespace,
+		func(ctx context.Context, c api.Backend) (interface{}, error) {
+			request := &api.GetPrefixesRequest{
+				Tree: api.TreeID{
+					Root:     root,
+					Position: root.Hash,
+				},
+				Prefixes: prefixes,
+				Limit:    limit,
+			}
+			rsp, err := c.SyncGetPrefixes(ctx, request)
+			if err != nil {
+				return nil, err
+			}
+			return nil, b.cache.verifyAndInsert(ctx, &request.Tree, &rsp.Proof)
+		},
+	)
+	return err
+}
+
 type grpcResponse struct {
 	resp interface{}
 	err  error
@@ -286,6 +335,21 @@ func (b *storageClientBackend) readWithClient(
 	ctx context.Context,
 	ns common.Namespace,
 	fn func(context.Context, api.Backend) (interface{}, error),
+) (interface{}, error) {
+	return b.readWithQuorum(ctx, ns, nil, 1, fn)
+}
+
+// readWithQuorum performs the given read operation against connected nodes until the given
+// number of nodes returned identical responses.
+//
+// In case a root is given, nodes that fail the read because they are missing the root or any
+// of its nodes while some other node serves it are reported as divergent.
+func (b *storageClientBackend) readWithQuorum(
+	ctx context.Context,
+	ns common.Namespace,
+	root *api.Root,
+	quorum int,
+	fn func(context.Context, api.Backend) (interface{}, error),
 ) (interface{}, error) {
 	var resp interface{}
 	op := func() error {
@@ -296,6 +360,14 @@ func (b *storageClientBackend) readWithClient(
 			)
 			return ErrStorageNotAvailable
 		}
+		if len(conns) < quorum {
+			b.logger.Error("readWithClient: not enough connected nodes for read quorum",
+				"runtime_id", ns,
+				"read_quorum", quorum,
+				"num_nodes", len(conns),
+			)
+			return ErrStorageNotAvailable
+		}
 
 		var nodes []*grpc.ConnWithNodeMeta
 		// If a storage node priority hint is set, prioritize overlapping nodes.
@@ -321,9 +393,14 @@ func (b *storageClientBackend) readWithClient(
 			ordinaryNodes[i], ordinaryNodes[j] = ordinaryNodes[j], ordinaryNodes[i]
 		})
 
-		var err error
+		var (
+			err     error
+			missing []signature.PublicKey
+		)
+		votes := make(map[hash.Hash]int)
 		for _, conn := range nodes {
-			resp, err = fn(ctx, api.NewStorageClient(conn.ClientConn))
+			var rsp interface{}
+			rsp, err = fn(ctx, api.NewStorageClient(conn.ClientConn))
 			if ctx.Err() != nil {
 				return backoff.Permanent(ctx.Err())
 			}
@@ -333,10 +410,29 @@ func (b *storageClientBackend) readWithClient(
 					"err", err,
 					"runtime_id", ns,
 				)
+				if errors.Is(err, api.ErrRootNotFound) || errors.Is(err, api.ErrNodeNotFound) {
+					missing = append(missing, conn.Node.ID)
+				}
 				continue
 			}
+
+			if quorum > 1 {
+				h := hash.NewFrom(rsp)
+				votes[h]++
+				if votes[h] < quorum {
+					continue
+				}
+			}
+
+			if root != nil {
+				b.divergences.reportMissing(ctx, *root, conn.Node.ID, missing)
+			}
+			resp = rsp
 			return nil
 		}
+		if err == nil {
+			err = ErrReadQuorumNotReached
+		}
 		return err
 	}
 
@@ -345,13 +441,19 @@ func (b *storageClientBackend) readWithClient(
 	return resp, err
 }
 
-func (b *storageClientBackend) SyncGet(ctx context.Context, request *api.GetRequest) (*api.ProofResponse, error) {
-	rsp, err := b.readWithClient(
+// readProofWithClient performs a read operation returning a proof for the given tree, requiring
+// the configured read quorum.
+func (b *storageClientBackend) readProofWithClient(
+	ctx context.Context,
+	tree *api.TreeID,
+	fn func(context.Context, api.Backend) (*api.ProofResponse, error),
+) (*api.ProofResponse, error) {
+	rsp, err := b.readWithQuorum(
 		ctx,
-		request.Tree.Root.Namespace,
-		func(ctx context.Context, c api.Backend) (interface{}, error) {
-			return c.SyncGet(ctx, request)
-		},
+		tree.Root.Namespace,
+		&tree.Root,
+		b.readQuorum,
+		b.verifiedRead(tree, fn),
 	)
 	if err != nil {
 		return nil, err
@@ -359,32 +461,70 @@ func (b *storageClientBackend) SyncGet(ctx context.Context, request *api.GetRequ
 	return rsp.(*api.ProofResponse), nil
 }
 
+// verifiedRead wraps a read operation returning a proof so that the proof is verified and the
+// included nodes are added to the read cache. In case the read cache is disabled, proofs are
+// passed through unverified as they are verified by the caller.
+func (b *storageClientBackend) verifiedRead(
+	tree *api.TreeID,
+	fn func(context.Context, api.Backend) (*api.ProofResponse, error),
+) func(context.Context, api.Backend) (interface{}, error) {
+	return func(ctx context.Context, c api.Backend) (interface{}, error) {
+		rsp, err := fn(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if b.cache != nil {
+			// Reject invalid proofs so that the read is retried with a different node.
+			if err = b.cache.verifyAndInsert(ctx, tree, &rsp.Proof); err != nil {
+				return nil, err
+			}
+		}
+		return rsp, nil
+	}
+}
+
+func (b *storageClientBackend) SyncGet(ctx context.Context, request *api.GetRequest) (*api.ProofResponse, error) {
+	if b.cache != nil {
+		rsp, err := b.cache.syncGet(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		if rsp != nil {
+			return rsp, nil
+		}
+	}
+
+	return b.readProofWithClient(
+		ctx,
+		&request.Tree,
+		func(ctx context.Context, c api.Backend) (*api.ProofResponse, error) {
+			return c.SyncGet(ctx, request)
+		},
+	)
+}
+
 func (b *storageClientBackend) SyncGetPrefixes(ctx context.Context, request *api.GetPrefixesRequest) (*api.ProofResponse, error) {
-	rsp, err := b.readWithClient(
+	if b.cache != nil {
+		b.cache.recordPrefixes(request.Tree.Root.Namespace, request.Prefixes)
+	}
+
+	return b.readProofWithClient(
 		ctx,
-		request.Tree.Root.Namespace,
-		func(ctx context.Context, c api.Backend) (interface{}, error) {
+		&request.Tree,
+		func(ctx context.Context, c api.Backend) (*api.ProofResponse, error) {
 			return c.SyncGetPrefixes(ctx, request)
 		},
 	)
-	if err != nil {
-		return nil, err
-	}
-	return rsp.(*api.ProofResponse), nil
 }
 
 func (b *storageClientBackend) SyncIterate(ctx context.Context, request *api.IterateRequest) (*api.ProofResponse, error) {
-	rsp, err := b.readWithClient(
+	return b.readProofWithClient(
 		ctx,
-		request.Tree.Root.Namespace,
-		func(ctx context.Context, c api.Backend) (interface{}, error) {
+		&request.Tree,
+		func(ctx context.Context, c api.Backend) (*api.ProofResponse, error) {
 			return c.SyncIterate(ctx, request)
 		},
 	)
-	if err != nil {
-		return nil, err
-	}
-	return rsp.(*api.ProofResponse), nil
 }
 
 func (b *storageClientBackend) GetDiff(ctx context.Context, request *api.GetDiffRequest) (api.WriteLogIterator, error) {
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/history"
+	"github.com/oasisprotocol/oasis-core/go/storage/api"
+)
+
+// blockHistoryProvider is implemented by runtime descriptor providers that also provide access
+// to the runtime block history.
+type blockHistoryProvider interface {
+	History() history.History
+}
+
+// divergenceDetector tracks storage committee members that are missing finalized roots.
+//
+// Members repair themselves when they fail to serve a finalized root (see the storage worker),
+// so the detector only needs to report them.
+type divergenceDetector struct {
+	logger *logging.Logger
+
+	// history is the runtime block history used to check whether roots have been finalized. In
+	// case it is not available, divergences are not reported.
+	history roothash.BlockHistory
+}
+
+// isFinalized checks whether the given root is a storage root of a finalized runtime block.
+func (d *divergenceDetector) isFinalized(ctx context.Context, root api.Root) bool {
+	if d.history == nil {
+		return false
+	}
+	blk, err := d.history.GetBlock(ctx, root.Version)
+	if err != nil {
+		return false
+	}
+	for _, finalizedRoot := range blk.Header.StorageRoots() {
+		if finalizedRoot.Equal(&root) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportMissing records that the given members are missing a root that the source member has.
+//
+// Members are only reported as divergent in case the root has been finalized as otherwise they
+// may not have received it yet.
+func (d *divergenceDetector) reportMissing(ctx context.Context, root api.Root, source signature.PublicKey, members []signature.PublicKey) {
+	if len(members) == 0 || !d.isFinalized(ctx, root) {
+		return
+	}
+
+	for _, member := range members {
+		divergences.With(memberLabels(root.Namespace, member)).Inc()
+		d.logger.Warn("storage committee member is missing finalized root",
+			"node", member,
+			"root", root,
+			"source", source,
+		)
+	}
+}
+
+func newDivergenceDetector(logger *logging.Logger, blockHistory roothash.BlockHistory) *divergenceDetector {
+	return &divergenceDetector{
+		logger:  logger,
+		history: blockHistory,
+	}
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/runtime/history"
+	"github.com/oasisprotocol/oasis-core/go/storage/api"
+)
+
+func TestDivergenceDetector(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	dataDir, err := ioutil.TempDir("", "oasis-storage-client-divergence-test_")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dataDir)
+
+	blockHistory, err := history.New(dataDir, testNs, history.NewDefaultConfig())
+	require.NoError(err, "history.New")
+	defer blockHistory.Close()
+
+	blk := block.NewGenesisBlock(testNs, 0)
+	blk.Header.StateRoot.FromBytes([]byte("finalized state root"))
+	err = blockHistory.Commit(&roothash.AnnotatedBlock{Height: 1, Block: blk}, nil)
+	require.NoError(err, "Commit")
+
+	finalized := blk.Header.StorageRoots()[1]
+	unfinalized := api.Root{Namespace: testNs, Version: 1}
+	unfinalized.Hash.FromBytes([]byte("unfinalized state root"))
+
+	source := signature.NewPublicKey("0000000000000000000000000000000000000000000000000000000000000001")
+	member := signature.NewPublicKey("0000000000000000000000000000000000000000000000000000000000000002")
+	divergent := func() float64 {
+		return testutil.ToFloat64(divergences.With(memberLabels(testNs, member)))
+	}
+
+	d := newDivergenceDetector(logging.GetLogger("storage/client/test"), blockHistory)
+	require.True(d.isFinalized(ctx, finalized), "root of a finalized block should be finalized")
+	require.False(d.isFinalized(ctx, unfinalized), "root without a finalized block should not be finalized")
+
+	before := divergent()
+	d.reportMissing(ctx, unfinalized, source, []signature.PublicKey{member})
+	require.Equal(before, divergent(), "members missing unfinalized roots should not be divergent")
+	d.reportMissing(ctx, finalized, source, []signature.PublicKey{member})
+	require.Equal(before+1, divergent(), "members missing finalized roots should be divergent")
+
+	// Without block history divergences cannot be verified.
+	d = newDivergenceDetector(logging.GetLogger("storage/client/test"), nil)
+	require.False(d.isFinalized(ctx, finalized), "roots should not be finalized without history")
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/identity"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
 	"github.com/oasisprotocol/oasis-core/go/runtime/committee"
 	"github.com/oasisprotocol/oasis-core/go/runtime/nodes"
 	"github.com/oasisprotocol/oasis-core/go/runtime/nodes/grpc"
@@ -16,8 +20,21 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/storage/api"
 )
 
-// BackendName is the name of this implementation.
-const BackendName = "client"
+const (
+	// BackendName is the name of this implementation.
+	BackendName = "client"
+
+	// CfgCacheMaxSize configures the maximum size of the node cache shared between all storage
+	// clients. Setting it to zero disables the cache.
+	CfgCacheMaxSize = "storage.client.cache.max_size"
+
+	// CfgReadQuorum configures the number of storage nodes that need to return matching proofs
+	// for a read to succeed.
+	CfgReadQuorum = "storage.client.read_quorum"
+)
+
+// Flags has the configuration flags.
+var Flags = flag.NewFlagSet("", flag.ContinueOnError)
 
 // NewForNodesClient creates a new storage client that connects to nodes watched
 // by the provided nodes gRPC client.
@@ -26,11 +43,27 @@ func NewForNodesClient(
 	client grpc.NodesClient,
 	runtime registry.RuntimeDescriptorProvider,
 ) (api.Backend, error) {
+	readQuorum := viper.GetInt(CfgReadQuorum)
+	if readQuorum < 1 {
+		return nil, fmt.Errorf("storage/client: invalid read quorum: %d", readQuorum)
+	}
+
+	initMetrics()
+
+	var blockHistory roothash.BlockHistory
+	if hp, ok := runtime.(blockHistoryProvider); ok {
+		blockHistory = hp.History()
+	}
+
+	logger := logging.GetLogger("storage/client")
 	b := &storageClientBackend{
 		ctx:         ctx,
-		logger:      logging.GetLogger("storage/client"),
+		logger:      logger,
 		nodesClient: client,
 		runtime:     runtime,
+		cache:       getSharedNodeCache(),
+		divergences: newDivergenceDetector(logger, blockHistory),
+		readQuorum:  readQuorum,
 	}
 	return api.NewMetricsWrapper(b), nil
 }
@@ -104,3 +137,9 @@ func NewStatic(
 
 	return client, nil
 }
+
+func init() {
+	Flags.String(CfgCacheMaxSize, "0", "Maximum size of the shared storage client node cache (0 disables)")
+	Flags.Int(CfgReadQuorum, 1, "Number of storage nodes that need to return matching proofs for a read")
+	_ = viper.BindPFlags(Flags)
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+)
+
+var (
+	cacheHits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_storage_client_cache_hits",
+			Help: "Number of storage client reads served from the node cache.",
+		},
+		[]string{"runtime"},
+	)
+	cacheMisses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_storage_client_cache_misses",
+			Help: "Number of storage client reads that could not be served from the node cache.",
+		},
+		[]string{"runtime"},
+	)
+	cacheBadProofs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_storage_client_cache_bad_proofs",
+			Help: "Number of storage node responses rejected due to invalid proofs.",
+		},
+		[]string{"runtime"},
+	)
+	cacheSize = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "oasis_storage_client_cache_size",
+			Help: "Size of the shared storage client node cache (bytes).",
+		},
+	)
+	divergences = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_storage_client_divergences",
+			Help: "Number of finalized roots detected as missing on a storage committee member.",
+		},
+		[]string{"runtime", "node"},
+	)
+
+	clientCollectors = []prometheus.Collector{
+		cacheHits,
+		cacheMisses,
+		cacheBadProofs,
+		cacheSize,
+		divergences,
+	}
+
+	metricsOnce sync.Once
+)
+
+func initMetrics() {
+	metricsOnce.Do(func() {
+		prometheus.MustRegister(clientCollectors...)
+	})
+}
+
+func runtimeLabels(ns common.Namespace) prometheus.Labels {
+	return prometheus.Labels{"runtime": ns.String()}
+}
+
+func memberLabels(ns common.Namespace, nodeID signature.PublicKey) prometheus.Labels {
+	return prometheus.Labels{"runtime": ns.String(), "node": nodeID.String()}
+}
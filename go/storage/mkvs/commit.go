@@ -21,19 +21,28 @@ func NoPersist() CommitOption {
 	}
 }
 
+// Repair returns a commit option that makes the Commit persist the root into an already finalized
+// version in order to repair a root that is missing from the database or has missing nodes.
+func Repair() CommitOption {
+	return func(o *commitOptions) {
+		o.repair = true
+	}
+}
+
 type commitOptions struct {
 	noPersist bool
+	repair    bool
 }
 
 // Implements Tree.
-func (t *tree) CommitKnown(ctx context.Context, root node.Root) (writelog.WriteLog, error) {
+func (t *tree) CommitKnown(ctx context.Context, root node.Root, options ...CommitOption) (writelog.WriteLog, error) {
 	writeLog, _, err := t.commitWithHooks(ctx, root.Namespace, root.Version, func(rootHash hash.Hash) error {
 		if !rootHash.Equal(&root.Hash) {
 			return ErrKnownRootMismatch
 		}
 
 		return nil
-	})
+	}, options...)
 	return writeLog, err
 }
 
@@ -69,13 +78,15 @@ func (t *tree) commitWithHooks(
 
 	var batch db.Batch
 	var err error
-	switch opts.noPersist {
-	case false:
-		batch, err = t.cache.db.NewBatch(oldRoot, version, false)
-	case true:
+	switch {
+	case opts.noPersist:
 		// Do not persist anything -- use a dummy batch.
 		nopDb, _ := db.NewNopNodeDB()
 		batch, err = nopDb.NewBatch(oldRoot, version, false)
+	case opts.repair:
+		batch, err = t.cache.db.NewRepairBatch(oldRoot, version)
+	default:
+		batch, err = t.cache.db.NewBatch(oldRoot, version, false)
 	}
 	if err != nil {
 		return nil, hash.Hash{}, err
@@ -3,6 +3,7 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
@@ -75,6 +76,9 @@ type Config struct { // nolint: maligned
 
 	// DiscardWriteLogs will cause all write logs to be discarded.
 	DiscardWriteLogs bool
+
+	// EncryptionKey is the optional key used to encrypt the database at rest.
+	EncryptionKey []byte
 }
 
 // NodeDB is the persistence layer used for persisting the in-memory tree.
@@ -114,6 +118,12 @@ type NodeDB interface {
 	// from being finalized.
 	NewBatch(oldRoot node.Root, version uint64, chunk bool) (Batch, error)
 
+	// NewRepairBatch starts a new batch used to repair a root of an already finalized version
+	// that is missing from the database or has missing nodes.
+	//
+	// Committing a repair batch stores all of its nodes even in case the root already exists.
+	NewRepairBatch(oldRoot node.Root, version uint64) (Batch, error)
+
 	// HasRoot checks whether the given root exists.
 	HasRoot(root node.Root) bool
 
@@ -127,6 +137,12 @@ type NodeDB interface {
 	// Only the earliest version can be pruned, passing any other version will result in an error.
 	Prune(ctx context.Context, version uint64) error
 
+	// CollectGarbage performs a mark-and-sweep pass over the database, removing any nodes
+	// that are not reachable from any of the stored roots.
+	//
+	// Only nodes written in finalized versions are considered for removal.
+	CollectGarbage(ctx context.Context, opts *GCOptions) (*GCResult, error)
+
 	// Size returns the size of the database in bytes.
 	Size() (int64, error)
 
@@ -138,6 +154,40 @@ type NodeDB interface {
 	Close()
 }
 
+// GCOptions are the options for a garbage collection pass.
+type GCOptions struct {
+	// DryRun only reports the unreachable nodes without removing them.
+	DryRun bool
+
+	// Compact requests that the backend also compacts the on-disk representation after
+	// unreachable nodes have been removed. This may be expensive and should only be used
+	// when the database is not being actively used.
+	Compact bool
+
+	// Pause is the amount of time to pause after processing each batch of nodes so that
+	// the garbage collector does not starve other database operations.
+	Pause time.Duration
+}
+
+// GCResult is the result of a garbage collection pass.
+type GCResult struct {
+	// Roots is the number of roots that have been traversed.
+	Roots uint64 `json:"roots"`
+
+	// ReachableNodes is the number of nodes reachable from any of the roots.
+	ReachableNodes uint64 `json:"reachable_nodes"`
+
+	// MissingNodes is the number of nodes that are referenced from a root, but are not
+	// present in the database.
+	MissingNodes uint64 `json:"missing_nodes"`
+
+	// UnreachableNodes is the number of nodes not reachable from any of the roots.
+	UnreachableNodes uint64 `json:"unreachable_nodes"`
+
+	// ReclaimedBytes is the estimated number of bytes taken up by unreachable nodes.
+	ReclaimedBytes uint64 `json:"reclaimed_bytes"`
+}
+
 // Subtree is a NodeDB-specific subtree implementation.
 type Subtree interface {
 	// PutNode persists a node in the NodeDB.
@@ -247,6 +297,10 @@ func (d *nopNodeDB) Prune(ctx context.Context, version uint64) error {
 	return nil
 }
 
+func (d *nopNodeDB) CollectGarbage(ctx context.Context, opts *GCOptions) (*GCResult, error) {
+	return &GCResult{}, nil
+}
+
 func (d *nopNodeDB) Size() (int64, error) {
 	return 0, nil
 }
@@ -267,6 +321,10 @@ func (d *nopNodeDB) NewBatch(oldRoot node.Root, version uint64, chunk bool) (Bat
 	return &nopBatch{}, nil
 }
 
+func (d *nopNodeDB) NewRepairBatch(oldRoot node.Root, version uint64) (Batch, error) {
+	return &nopBatch{}, nil
+}
+
 func (b *nopBatch) MaybeStartSubtree(subtree Subtree, depth node.Depth, subtreeRoot *node.Pointer) Subtree {
 	return &nopSubtree{}
 }
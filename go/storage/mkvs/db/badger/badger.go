@@ -68,6 +68,7 @@ func New(cfg *api.Config) (api.NodeDB, error) {
 		logger:           logging.GetLogger("mkvs/db/badger"),
 		namespace:        cfg.Namespace,
 		readOnly:         cfg.ReadOnly,
+		memoryOnly:       cfg.MemoryOnly,
 		discardWriteLogs: cfg.DiscardWriteLogs,
 	}
 
@@ -81,6 +82,7 @@ func New(cfg *api.Config) (api.NodeDB, error) {
 	opts = opts.WithBlockCacheSize(cfg.MaxCacheSize)
 	opts = opts.WithReadOnly(cfg.ReadOnly)
 	opts = opts.WithDetectConflicts(false)
+	opts = cmnBadger.WithEncryption(opts, cfg.EncryptionKey)
 
 	if cfg.MemoryOnly {
 		db.logger.Warn("using memory-only mode, data will not be persisted")
@@ -118,6 +120,7 @@ type badgerNodeDB struct { // nolint: maligned
 	namespace common.Namespace
 
 	readOnly         bool
+	memoryOnly       bool
 	discardWriteLogs bool
 
 	multipartVersion uint64
@@ -131,6 +134,10 @@ type badgerNodeDB struct { // nolint: maligned
 	metaUpdateLock sync.Mutex
 	meta           metadata
 
+	// gcLock serializes garbage collection and pruning. The garbage collector releases
+	// it between batches of processed nodes.
+	gcLock sync.Mutex
+
 	closeOnce sync.Once
 }
 
@@ -675,6 +682,9 @@ func (d *badgerNodeDB) Prune(ctx context.Context, version uint64) error {
 		return api.ErrReadOnly
 	}
 
+	d.gcLock.Lock()
+	defer d.gcLock.Unlock()
+
 	d.metaUpdateLock.Lock()
 	defer d.metaUpdateLock.Unlock()
 
@@ -859,6 +869,19 @@ func (d *badgerNodeDB) NewBatch(oldRoot node.Root, version uint64, chunk bool) (
 	}, nil
 }
 
+func (d *badgerNodeDB) NewRepairBatch(oldRoot node.Root, version uint64) (api.Batch, error) {
+	if d.readOnly {
+		return nil, api.ErrReadOnly
+	}
+
+	return &badgerBatch{
+		db:      d,
+		bat:     d.db.NewWriteBatchAt(versionToTs(version)),
+		oldRoot: oldRoot,
+		repair:  true,
+	}, nil
+}
+
 func (d *badgerNodeDB) Size() (int64, error) {
 	lsm, vlog := d.db.Size()
 	return lsm + vlog, nil
@@ -893,6 +916,7 @@ type badgerBatch struct {
 
 	oldRoot node.Root
 	chunk   bool
+	repair  bool
 
 	writeLog     writelog.WriteLog
 	annotations  writelog.Annotations
@@ -937,6 +961,10 @@ func (ba *badgerBatch) Commit(root node.Root) error {
 	ba.db.metaUpdateLock.Lock()
 	defer ba.db.metaUpdateLock.Unlock()
 
+	if ba.repair {
+		return ba.commitRepairLocked(root)
+	}
+
 	if ba.db.multipartVersion != multipartVersionNone && ba.db.multipartVersion != root.Version {
 		return api.ErrInvalidMultipartVersion
 	}
@@ -1049,6 +1077,97 @@ func (ba *badgerBatch) Commit(root node.Root) error {
 	return ba.BaseBatch.Commit(root)
 }
 
+// commitRepairLocked commits a repair batch for the given root of an already finalized version.
+//
+// As the derived roots of the repaired root are not known, the root is linked to all roots of the
+// next version (if any) so that pruning never removes nodes that may be shared with them. Any
+// nodes left over after pruning are removed by the garbage collector.
+//
+// Assumes metaUpdateLock is held when called.
+func (ba *badgerBatch) commitRepairLocked(root node.Root) error {
+	if ba.db.multipartVersion != multipartVersionNone {
+		return api.ErrMultipartInProgress
+	}
+	if err := ba.db.sanityCheckNamespace(root.Namespace); err != nil {
+		return err
+	}
+	if !root.Follows(&ba.oldRoot) {
+		return api.ErrRootMustFollowOld
+	}
+
+	// Make sure that the version that we try to repair has been finalized and not yet pruned.
+	lastFinalizedVersion, exists := ba.db.meta.getLastFinalizedVersion()
+	if !exists || lastFinalizedVersion < root.Version {
+		return api.ErrNotFinalized
+	}
+	if root.Version < ba.db.meta.getEarliestVersion() {
+		return api.ErrVersionNotFound
+	}
+
+	tx := ba.db.db.NewTransactionAt(versionToTs(root.Version), true)
+	defer tx.Discard()
+
+	rootsMeta, err := loadRootsMetadata(tx, root.Version)
+	if err != nil {
+		return err
+	}
+	if rootsMeta.Roots[root.Hash] == nil {
+		var derivedRoots []hash.Hash
+		if root.Version < lastFinalizedVersion {
+			var nextRootsMeta *rootsMetadata
+			if nextRootsMeta, err = loadRootsMetadata(tx, root.Version+1); err != nil {
+				return err
+			}
+			for rootHash := range nextRootsMeta.Roots {
+				derivedRoots = append(derivedRoots, rootHash)
+			}
+		}
+		rootsMeta.Roots[root.Hash] = append([]hash.Hash{}, derivedRoots...)
+		if err = rootsMeta.save(tx); err != nil {
+			return fmt.Errorf("mkvs/badger: failed to save roots metadata: %w", err)
+		}
+
+		// Update the root link for the old root.
+		if !ba.oldRoot.Hash.IsEmpty() && ba.oldRoot.Version >= ba.db.meta.getEarliestVersion() {
+			var oldRootsMeta *rootsMetadata
+			if oldRootsMeta, err = loadRootsMetadata(tx, ba.oldRoot.Version); err != nil {
+				return err
+			}
+			if _, ok := oldRootsMeta.Roots[ba.oldRoot.Hash]; ok {
+				oldRootsMeta.Roots[ba.oldRoot.Hash] = append(oldRootsMeta.Roots[ba.oldRoot.Hash], root.Hash)
+				if err = oldRootsMeta.save(tx); err != nil {
+					return fmt.Errorf("mkvs/badger: failed to save old roots metadata: %w", err)
+				}
+			}
+		}
+	}
+
+	// Store write log.
+	if ba.writeLog != nil && ba.annotations != nil {
+		log := api.MakeHashedDBWriteLog(ba.writeLog, ba.annotations)
+		key := writeLogKeyFmt.Encode(root.Version, &root.Hash, &ba.oldRoot.Hash)
+		if err = ba.bat.Set(key, cbor.Marshal(log)); err != nil {
+			return fmt.Errorf("mkvs/badger: set new write log returned error: %w", err)
+		}
+	}
+
+	// Flush node updates.
+	if err = ba.bat.Flush(); err != nil {
+		return fmt.Errorf("mkvs/badger: failed to flush batch: %w", err)
+	}
+
+	// Commit root metadata updates. This is done last, so in case we fail, we can still retry.
+	if err = tx.CommitAt(tsMetadata, nil); err != nil {
+		return err
+	}
+
+	ba.writeLog = nil
+	ba.annotations = nil
+	ba.updatedNodes = nil
+
+	return ba.BaseBatch.Commit(root)
+}
+
 func (ba *badgerBatch) Reset() {
 	ba.bat.Cancel()
 	if ba.multipartNodes != nil {
@@ -3,11 +3,13 @@ package badger
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/dgraph-io/badger/v2"
 	"github.com/stretchr/testify/require"
@@ -325,3 +327,231 @@ func TestReadOnlyBatch(t *testing.T) {
 	_, err = badgerdb.NewBatch(node.Root{}, 13, false)
 	require.Error(err, "NewBatch()")
 }
+
+func TestCollectGarbage(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	ndb, err := New(dbCfg)
+	require.NoError(err, "New()")
+	defer ndb.Close()
+	badgerdb := ndb.(*badgerNodeDB)
+
+	root := fillDB(ctx, require, testValues, 1, ndb)
+	err = ndb.Finalize(ctx, root.Version, []hash.Hash{root.Hash})
+	require.NoError(err, "Finalize()")
+
+	// Nothing should be collected in a consistent database.
+	res, err := ndb.CollectGarbage(ctx, &api.GCOptions{})
+	require.NoError(err, "CollectGarbage()")
+	require.EqualValues(1, res.Roots, "all roots should be traversed")
+	require.EqualValues(0, res.MissingNodes, "no nodes should be missing")
+	require.EqualValues(0, res.UnreachableNodes, "no nodes should be unreachable")
+
+	// Simulate an orphaned node left over from an interrupted commit.
+	orphan := &node.LeafNode{Version: root.Version, Key: []byte("orphan"), Value: []byte("orphan")}
+	orphan.UpdateHash()
+	data, err := orphan.MarshalBinary()
+	require.NoError(err, "MarshalBinary()")
+	batch := badgerdb.db.NewWriteBatchAt(versionToTs(root.Version))
+	err = batch.Set(nodeKeyFmt.Encode(&orphan.Hash), data)
+	require.NoError(err, "Set()")
+	err = batch.Flush()
+	require.NoError(err, "Flush()")
+
+	orphanPtr := &node.Pointer{Clean: true, Hash: orphan.Hash}
+	_, err = ndb.GetNode(root, orphanPtr)
+	require.NoError(err, "GetNode(orphan)")
+
+	// A dry run should only report the orphaned node.
+	res, err = ndb.CollectGarbage(ctx, &api.GCOptions{DryRun: true})
+	require.NoError(err, "CollectGarbage(DryRun)")
+	require.EqualValues(1, res.UnreachableNodes, "orphaned node should be unreachable")
+	require.NotZero(res.ReclaimedBytes, "reclaimed bytes should be reported")
+	_, err = ndb.GetNode(root, orphanPtr)
+	require.NoError(err, "GetNode(orphan) after dry run")
+
+	res, err = ndb.CollectGarbage(ctx, &api.GCOptions{Compact: true})
+	require.NoError(err, "CollectGarbage()")
+	require.EqualValues(1, res.UnreachableNodes, "orphaned node should be unreachable")
+	_, err = ndb.GetNode(root, orphanPtr)
+	require.Equal(api.ErrNodeNotFound, err, "GetNode(orphan) after collection")
+
+	// All reachable nodes must be retained.
+	tree := mkvs.NewWithRoot(nil, ndb, root)
+	defer tree.Close()
+	for i, val := range testValues {
+		var v []byte
+		v, err = tree.Get(ctx, []byte(strconv.Itoa(i)))
+		require.NoError(err, "Get()")
+		require.Equal(val, v, "value should be retained")
+	}
+
+	// Collecting again should find nothing.
+	res, err = ndb.CollectGarbage(ctx, &api.GCOptions{})
+	require.NoError(err, "CollectGarbage()")
+	require.EqualValues(0, res.UnreachableNodes, "no nodes should be unreachable")
+}
+
+func gcTestValue(i int, version uint64) []byte {
+	return []byte(fmt.Sprintf("value %d at version %d", i, version))
+}
+
+// fillGCTestDB creates two finalized versions with enough nodes so that the garbage collector
+// pauses during its pass.
+func fillGCTestDB(ctx context.Context, require *require.Assertions, ndb api.NodeDB) []node.Root {
+	var roots []node.Root
+	for version := uint64(0); version < 2; version++ {
+		root := node.Root{Namespace: testNs, Version: version}
+		root.Hash.Empty()
+		if version > 0 {
+			root = roots[version-1]
+		}
+		tree := mkvs.NewWithRoot(nil, ndb, root)
+		var wl writelog.WriteLog
+		for i := 0; i < 2*gcBatchSize; i++ {
+			wl = append(wl, writelog.LogEntry{Key: []byte(strconv.Itoa(i)), Value: gcTestValue(i, version)})
+		}
+		err := tree.ApplyWriteLog(ctx, writelog.NewStaticIterator(wl))
+		require.NoError(err, "ApplyWriteLog()")
+		_, root.Hash, err = tree.Commit(ctx, testNs, version)
+		require.NoError(err, "Commit()")
+		root.Version = version
+		tree.Close()
+
+		err = ndb.Finalize(ctx, version, []hash.Hash{root.Hash})
+		require.NoError(err, "Finalize()")
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+func TestCollectGarbageDoesNotBlockPrune(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	ndb, err := New(dbCfg)
+	require.NoError(err, "New()")
+	defer ndb.Close()
+
+	roots := fillGCTestDB(ctx, require, ndb)
+
+	gcCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	gcErrCh := make(chan error)
+	go func() {
+		_, gcErr := ndb.CollectGarbage(gcCtx, &api.GCOptions{Pause: time.Hour})
+		gcErrCh <- gcErr
+	}()
+
+	// Pruning should not be blocked while the garbage collector is paused.
+	pruneErrCh := make(chan error)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		pruneErrCh <- ndb.Prune(ctx, 0)
+	}()
+	select {
+	case err = <-pruneErrCh:
+		require.NoError(err, "Prune()")
+	case <-time.After(10 * time.Second):
+		t.Fatalf("pruning blocked by garbage collection")
+	}
+
+	cancel()
+	err = <-gcErrCh
+	require.True(errors.Is(err, context.Canceled), "CollectGarbage() should be canceled")
+
+	// The latest version must be intact.
+	tree := mkvs.NewWithRoot(nil, ndb, roots[1])
+	defer tree.Close()
+	v, err := tree.Get(ctx, []byte("0"))
+	require.NoError(err, "Get()")
+	require.Equal(gcTestValue(0, 1), v, "value should be retained")
+}
+
+func TestCollectGarbageAbortsOnMultipart(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	ndb, err := New(dbCfg)
+	require.NoError(err, "New()")
+	defer ndb.Close()
+
+	_ = fillGCTestDB(ctx, require, ndb)
+
+	gcErrCh := make(chan error)
+	go func() {
+		_, gcErr := ndb.CollectGarbage(ctx, &api.GCOptions{Pause: 500 * time.Millisecond})
+		gcErrCh <- gcErr
+	}()
+
+	// Start a multipart restore while the garbage collector is paused.
+	time.Sleep(100 * time.Millisecond)
+	err = ndb.StartMultipartInsert(42)
+	require.NoError(err, "StartMultipartInsert()")
+
+	select {
+	case err = <-gcErrCh:
+		require.True(errors.Is(err, api.ErrMultipartInProgress), "CollectGarbage() should be aborted")
+	case <-time.After(10 * time.Second):
+		t.Fatalf("garbage collection not aborted")
+	}
+
+	err = ndb.AbortMultipartInsert()
+	require.NoError(err, "AbortMultipartInsert()")
+}
+
+func TestRepairBatch(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	ndb, err := New(dbCfg)
+	require.NoError(err, "New()")
+	defer ndb.Close()
+	badgerdb := ndb.(*badgerNodeDB)
+
+	roots := fillGCTestDB(ctx, require, ndb)
+	var wl writelog.WriteLog
+	for i := 0; i < 2*gcBatchSize; i++ {
+		wl = append(wl, writelog.LogEntry{Key: []byte(strconv.Itoa(i)), Value: gcTestValue(i, 1)})
+	}
+	visit := func(root node.Root) error {
+		return api.Visit(ctx, ndb, root, func(context.Context, node.Node) bool { return true })
+	}
+	applyAndCommit := func(root node.Root, options ...mkvs.CommitOption) error {
+		tree := mkvs.NewWithRoot(nil, ndb, roots[0])
+		defer tree.Close()
+		err = tree.ApplyWriteLog(ctx, writelog.NewStaticIterator(wl))
+		require.NoError(err, "ApplyWriteLog()")
+		_, err = tree.CommitKnown(ctx, root, options...)
+		return err
+	}
+
+	// Corrupt the finalized root by removing its root node.
+	batch := badgerdb.db.NewWriteBatchAt(versionToTs(roots[1].Version))
+	err = batch.Delete(nodeKeyFmt.Encode(&roots[1].Hash))
+	require.NoError(err, "Delete()")
+	err = batch.Flush()
+	require.NoError(err, "Flush()")
+	require.True(ndb.HasRoot(roots[1]), "corrupt root should still be present")
+	err = visit(roots[1])
+	require.True(errors.Is(err, api.ErrNodeNotFound), "corrupt root should be missing nodes")
+
+	// Regular batches cannot be committed into finalized versions.
+	err = applyAndCommit(roots[1])
+	require.True(errors.Is(err, api.ErrAlreadyFinalized), "CommitKnown() should fail for finalized versions")
+
+	// Repair batches can.
+	err = applyAndCommit(roots[1], mkvs.Repair())
+	require.NoError(err, "CommitKnown(Repair)")
+	err = visit(roots[1])
+	require.NoError(err, "repaired root should be intact")
+
+	// Repair batches cannot be committed into versions that have not yet been finalized.
+	tree := mkvs.NewWithRoot(nil, ndb, roots[1])
+	defer tree.Close()
+	err = tree.Insert(ctx, []byte("0"), gcTestValue(0, 2))
+	require.NoError(err, "Insert()")
+	_, _, err = tree.Commit(ctx, testNs, roots[1].Version+1, mkvs.Repair())
+	require.True(errors.Is(err, api.ErrNotFinalized), "Commit(Repair) should fail for non-finalized versions")
+}
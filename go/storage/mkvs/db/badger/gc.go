@@ -0,0 +1,302 @@
+package badger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+
+	cmnBadger "github.com/oasisprotocol/oasis-core/go/common/badger"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+)
+
+// gcBatchSize is the number of nodes processed by the garbage collector between
+// (optional) pauses. The garbage collector releases the GC lock between batches so
+// that it does not block pruning for the whole pass.
+const gcBatchSize = 1000
+
+// gcState is the state of a single garbage collection pass.
+type gcState struct {
+	ctx  context.Context
+	opts *api.GCOptions
+	db   *badgerNodeDB
+
+	marked    map[hash.Hash]struct{}
+	processed uint64
+	result    api.GCResult
+}
+
+// maybePause yields the GC lock after every processed batch of nodes and pauses the
+// garbage collector in case a pause has been configured.
+//
+// Pruning may remove roots while the lock is released. Nodes of pruned roots that were
+// already marked are only kept until the next pass, while nodes that disappear during
+// marking are ignored (see gcMarkNode), so this is safe. A multipart restore may also be
+// started while the lock is released, in which case the pass is aborted.
+func (s *gcState) maybePause() error {
+	s.processed++
+	if s.processed%gcBatchSize != 0 {
+		return s.ctx.Err()
+	}
+
+	s.db.gcLock.Unlock()
+	err := s.pause()
+	s.db.gcLock.Lock()
+	if err != nil {
+		return err
+	}
+
+	return s.db.checkMultipart()
+}
+
+func (s *gcState) pause() error {
+	if s.opts.Pause == 0 {
+		return s.ctx.Err()
+	}
+	select {
+	case <-time.After(s.opts.Pause):
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+// checkMultipart returns an error in case a multipart restore is in progress.
+func (d *badgerNodeDB) checkMultipart() error {
+	d.metaUpdateLock.Lock()
+	defer d.metaUpdateLock.Unlock()
+	if d.multipartVersion != multipartVersionNone {
+		return api.ErrMultipartInProgress
+	}
+	return nil
+}
+
+func (d *badgerNodeDB) CollectGarbage(ctx context.Context, opts *api.GCOptions) (*api.GCResult, error) {
+	if opts == nil {
+		opts = &api.GCOptions{}
+	}
+	if d.readOnly && !opts.DryRun {
+		return nil, api.ErrReadOnly
+	}
+
+	// Serialize with pruning. The lock is released between batches of processed nodes
+	// (see gcState.maybePause), so pruning is never blocked for the whole pass.
+	d.gcLock.Lock()
+	defer d.gcLock.Unlock()
+
+	if err := d.checkMultipart(); err != nil {
+		return nil, err
+	}
+
+	// Only consider nodes written in finalized versions for removal. Anything later could
+	// still be referenced by roots that are in the process of being committed.
+	lastFinalizedVersion, exists := d.meta.getLastFinalizedVersion()
+	if !exists {
+		return &api.GCResult{}, nil
+	}
+	cutoffTs := versionToTs(lastFinalizedVersion)
+
+	s := &gcState{
+		ctx:    ctx,
+		opts:   opts,
+		db:     d,
+		marked: make(map[hash.Hash]struct{}),
+	}
+
+	d.logger.Info("starting garbage collection",
+		"last_finalized_version", lastFinalizedVersion,
+		"dry_run", opts.DryRun,
+	)
+
+	if err := d.gcMark(s); err != nil {
+		return nil, fmt.Errorf("mkvs/badger: failed to mark reachable nodes: %w", err)
+	}
+	if err := d.gcSweep(s, cutoffTs); err != nil {
+		return nil, fmt.Errorf("mkvs/badger: failed to sweep unreachable nodes: %w", err)
+	}
+
+	if opts.Compact && !opts.DryRun {
+		if err := d.compact(); err != nil {
+			return nil, fmt.Errorf("mkvs/badger: failed to compact database: %w", err)
+		}
+	}
+
+	d.logger.Info("garbage collection finished",
+		"roots", s.result.Roots,
+		"reachable_nodes", s.result.ReachableNodes,
+		"missing_nodes", s.result.MissingNodes,
+		"unreachable_nodes", s.result.UnreachableNodes,
+		"reclaimed_bytes", s.result.ReclaimedBytes,
+	)
+
+	return &s.result, nil
+}
+
+// gcMark traverses all roots of all versions present in the database and marks all nodes that
+// are reachable from them.
+func (d *badgerNodeDB) gcMark(s *gcState) error {
+	tx := d.db.NewTransactionAt(tsMetadata, false)
+	defer tx.Discard()
+
+	it := tx.NewIterator(badger.IteratorOptions{Prefix: rootsMetadataKeyFmt.Encode()})
+	defer it.Close()
+
+	for it.Rewind(); it.Valid(); it.Next() {
+		var version uint64
+		if !rootsMetadataKeyFmt.Decode(it.Item().Key(), &version) {
+			// This should not happen as the Badger iterator should take care of it.
+			panic("mkvs/badger: bad iterator")
+		}
+		// The earliest version may change while the GC lock is released.
+		if version < d.meta.getEarliestVersion() {
+			continue
+		}
+
+		var rootsMeta rootsMetadata
+		if err := it.Item().Value(func(val []byte) error {
+			return cbor.UnmarshalTrusted(val, &rootsMeta)
+		}); err != nil {
+			return fmt.Errorf("error reading roots metadata: %w", err)
+		}
+
+		for rootHash := range rootsMeta.Roots {
+			if rootHash.IsEmpty() {
+				continue
+			}
+
+			root := node.Root{Namespace: d.namespace, Version: version, Hash: rootHash}
+			if err := d.gcMarkNode(s, root, &node.Pointer{Clean: true, Hash: rootHash}); err != nil {
+				return err
+			}
+			s.result.Roots++
+		}
+	}
+	return nil
+}
+
+func (d *badgerNodeDB) gcMarkNode(s *gcState, root node.Root, ptr *node.Pointer) error {
+	// Subtrees are shared between roots, so there is no need to traverse them more than once.
+	if _, ok := s.marked[ptr.Hash]; ok {
+		return nil
+	}
+	if err := s.maybePause(); err != nil {
+		return err
+	}
+
+	nd, err := d.GetNode(root, ptr)
+	switch {
+	case err == nil:
+	case errors.Is(err, api.ErrNodeNotFound):
+		if root.Version < d.meta.getEarliestVersion() {
+			// The root has been pruned while the GC lock was released.
+			return nil
+		}
+		d.logger.Warn("node referenced by root is missing",
+			"root", root,
+			"node_hash", ptr.Hash,
+		)
+		s.result.MissingNodes++
+		return nil
+	default:
+		return err
+	}
+
+	s.marked[ptr.Hash] = struct{}{}
+	s.result.ReachableNodes++
+
+	if n, ok := nd.(*node.InternalNode); ok {
+		for _, child := range []*node.Pointer{n.LeafNode, n.Left, n.Right} {
+			if child == nil {
+				continue
+			}
+			if err = d.gcMarkNode(s, root, child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// gcSweep removes all nodes written at or before the cutoff timestamp which have not been
+// marked as reachable.
+func (d *badgerNodeDB) gcSweep(s *gcState, cutoffTs uint64) error {
+	tx := d.db.NewTransactionAt(math.MaxUint64, false)
+	defer tx.Discard()
+
+	it := tx.NewIterator(badger.IteratorOptions{
+		Prefix:      nodeKeyFmt.Encode(),
+		AllVersions: true,
+	})
+	defer it.Close()
+
+	batch := d.db.NewManagedWriteBatch()
+	defer batch.Cancel()
+
+	// Versions of the same key are iterated over from newest to oldest, so once a deletion
+	// marker is encountered, all older versions of the same key are already removed.
+	var deletedKey []byte
+	for it.Rewind(); it.Valid(); it.Next() {
+		item := it.Item()
+		if deletedKey != nil && bytes.Equal(item.Key(), deletedKey) {
+			continue
+		}
+		if item.IsDeletedOrExpired() {
+			deletedKey = item.KeyCopy(deletedKey[:0])
+			continue
+		}
+		if item.Version() > cutoffTs {
+			continue
+		}
+
+		var h hash.Hash
+		if !nodeKeyFmt.Decode(item.Key(), &h) {
+			// This should not happen as the Badger iterator should take care of it.
+			panic("mkvs/badger: bad iterator")
+		}
+		if _, ok := s.marked[h]; ok {
+			continue
+		}
+		if err := s.maybePause(); err != nil {
+			return err
+		}
+
+		s.result.UnreachableNodes++
+		s.result.ReclaimedBytes += uint64(item.EstimatedSize())
+
+		if s.opts.DryRun {
+			continue
+		}
+		// Remove the node at the same timestamp as it was written at so that any newer
+		// versions of the same node remain untouched.
+		if err := batch.DeleteAt(item.KeyCopy(nil), item.Version()); err != nil {
+			return err
+		}
+	}
+
+	// Make sure that no multipart restore has been started since the last check as restored
+	// nodes are not marked as reachable.
+	d.metaUpdateLock.Lock()
+	defer d.metaUpdateLock.Unlock()
+	if d.multipartVersion != multipartVersionNone {
+		return api.ErrMultipartInProgress
+	}
+	return batch.Flush()
+}
+
+// compact forces compaction of the LSM tree and runs value log garbage collection.
+func (d *badgerNodeDB) compact() error {
+	if err := d.db.Flatten(1); err != nil {
+		return err
+	}
+	if d.memoryOnly {
+		return nil
+	}
+	return cmnBadger.RunValueLogGC(d.db)
+}
@@ -0,0 +1,117 @@
+package committee
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cache/lru"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/syncer"
+)
+
+// verifiedRootCacheSize is the number of consensus state roots that are remembered as verified.
+const verifiedRootCacheSize = 128
+
+// consensusStateSyncer is a read syncer which serves requests against the consensus state.
+//
+// All requested roots are checked against the state root committed in the corresponding
+// consensus block header and all returned proofs are verified against the requested root before
+// being passed to the runtime. As the host is not trusted by the runtime, the runtime performs
+// its own verification of the root against the light block passed with each request.
+type consensusStateSyncer struct {
+	consensus consensus.Backend
+	verifier  syncer.ProofVerifier
+
+	verifiedRoots *lru.Cache
+}
+
+// verifyRoot checks that the given root is a consensus state root committed in the block header.
+func (s *consensusStateSyncer) verifyRoot(ctx context.Context, root storage.Root) error {
+	if _, ok := s.verifiedRoots.Get(root); ok {
+		return nil
+	}
+
+	// State root at version V is committed in the header of the block at height V+1.
+	blk, err := s.consensus.GetBlock(ctx, int64(root.Version)+1)
+	if err != nil {
+		return fmt.Errorf("committee: failed to get consensus block for state root: %w", err)
+	}
+	if blk.StateRoot.Version != root.Version || !blk.StateRoot.Hash.Equal(&root.Hash) {
+		return fmt.Errorf("committee: unexpected consensus state root (expected: %s got: %s)",
+			blk.StateRoot.Hash,
+			root.Hash,
+		)
+	}
+
+	_ = s.verifiedRoots.Put(root, true)
+	return nil
+}
+
+// verifyProof verifies the proof in the given response against the requested tree.
+func (s *consensusStateSyncer) verifyProof(ctx context.Context, tree *storage.TreeID, rsp *storage.ProofResponse) error {
+	// The proof can either be for the requested position or for the tree root.
+	var expectedRoot hash.Hash
+	switch {
+	case rsp.Proof.UntrustedRoot.Equal(&tree.Position):
+		expectedRoot = tree.Position
+	case rsp.Proof.UntrustedRoot.Equal(&tree.Root.Hash):
+		expectedRoot = tree.Root.Hash
+	default:
+		return fmt.Errorf("committee: got consensus state proof for unexpected root (%s)", rsp.Proof.UntrustedRoot)
+	}
+
+	if _, err := s.verifier.VerifyProof(ctx, expectedRoot, &rsp.Proof); err != nil {
+		return fmt.Errorf("committee: bad consensus state proof: %w", err)
+	}
+	return nil
+}
+
+func (s *consensusStateSyncer) sync(
+	ctx context.Context,
+	tree *storage.TreeID,
+	fn func(syncer.ReadSyncer) (*storage.ProofResponse, error),
+) (*storage.ProofResponse, error) {
+	if err := s.verifyRoot(ctx, tree.Root); err != nil {
+		return nil, err
+	}
+	rsp, err := fn(s.consensus.State())
+	if err != nil {
+		return nil, err
+	}
+	if err = s.verifyProof(ctx, tree, rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+// Implements syncer.ReadSyncer.
+func (s *consensusStateSyncer) SyncGet(ctx context.Context, request *storage.GetRequest) (*storage.ProofResponse, error) {
+	return s.sync(ctx, &request.Tree, func(rs syncer.ReadSyncer) (*storage.ProofResponse, error) {
+		return rs.SyncGet(ctx, request)
+	})
+}
+
+// Implements syncer.ReadSyncer.
+func (s *consensusStateSyncer) SyncGetPrefixes(ctx context.Context, request *storage.GetPrefixesRequest) (*storage.ProofResponse, error) {
+	return s.sync(ctx, &request.Tree, func(rs syncer.ReadSyncer) (*storage.ProofResponse, error) {
+		return rs.SyncGetPrefixes(ctx, request)
+	})
+}
+
+// Implements syncer.ReadSyncer.
+func (s *consensusStateSyncer) SyncIterate(ctx context.Context, request *storage.IterateRequest) (*storage.ProofResponse, error) {
+	return s.sync(ctx, &request.Tree, func(rs syncer.ReadSyncer) (*storage.ProofResponse, error) {
+		return rs.SyncIterate(ctx, request)
+	})
+}
+
+func newConsensusStateSyncer(consensus consensus.Backend) *consensusStateSyncer {
+	verifiedRoots, _ := lru.New(lru.Capacity(verifiedRootCacheSize, false))
+
+	return &consensusStateSyncer{
+		consensus:     consensus,
+		verifiedRoots: verifiedRoots,
+	}
+}
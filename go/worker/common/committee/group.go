@@ -165,6 +165,21 @@ func (e *EpochSnapshot) IsTransactionScheduler(round uint64) bool {
 	return scheduler.PublicKey.Equal(e.identity.NodeSigner.Public())
 }
 
+// GetProposerPriority returns the transaction scheduler priority of the current node at the
+// specific runtime round. Priority zero means that the node is the transaction scheduler while
+// higher priorities mean that the node is a backup proposer. If the node is neither, false is
+// returned.
+func (e *EpochSnapshot) GetProposerPriority(round uint64) (uint64, bool) {
+	if e.executorCommittee == nil || e.executorCommittee.Committee == nil {
+		return 0, false
+	}
+	priority, err := commitment.GetProposerPriority(e.runtime, e.executorCommittee.Committee, round, e.identity.NodeSigner.Public())
+	if err != nil {
+		return 0, false
+	}
+	return priority, true
+}
+
 // GetStorageCommittee returns the current storage committee.
 func (e *EpochSnapshot) GetStorageCommittee() *CommitteeInfo {
 	return e.storageCommittee
@@ -207,23 +222,50 @@ func (e *EpochSnapshot) VerifyCommitteeSignatures(kind scheduler.CommitteeKind,
 	return nil
 }
 
-// VerifyTxnSchedulerSignature verifies transaction scheduler signature.
+// VerifyTxnSchedulerSignature verifies transaction scheduler signature. It does not check whether
+// a backup proposer was already allowed to propose, use VerifyTxnSchedulerSignatureAtHeight for
+// that.
 //
 // Implements commitment.SignatureVerifier.
 func (e *EpochSnapshot) VerifyTxnSchedulerSignature(sig signature.Signature, round uint64) error {
-	if e.executorCommittee == nil || e.executorCommittee.Committee == nil {
-		return fmt.Errorf("epoch: no active transaction scheduler")
-	}
-	scheduler, err := commitment.GetTransactionScheduler(e.executorCommittee.Committee, round)
+	_, err := e.getTxnSchedulerPriority(sig, round)
+	return err
+}
+
+// VerifyTxnSchedulerSignatureAtHeight verifies transaction scheduler signature at the given
+// consensus height of a round that started at roundStartHeight. Same as in consensus, backup
+// proposers are only accepted once their timeout has elapsed.
+func (e *EpochSnapshot) VerifyTxnSchedulerSignatureAtHeight(
+	sig signature.Signature,
+	round uint64,
+	roundStartHeight int64,
+	height int64,
+) error {
+	priority, err := e.getTxnSchedulerPriority(sig, round)
 	if err != nil {
-		return fmt.Errorf("epoch: error getting transaction scheduler: %w", err)
+		return err
 	}
-	if !scheduler.PublicKey.Equal(sig.PublicKey) {
-		return fmt.Errorf("epoch: signature is not from the transaction scheduler at round: %d", round)
+	if priority > 0 && height < roundStartHeight+int64(priority)*e.runtime.TxnScheduler.BackupProposerTimeout {
+		return fmt.Errorf("epoch: signature is from a backup proposer before its timeout at round: %d", round)
 	}
 	return nil
 }
 
+func (e *EpochSnapshot) getTxnSchedulerPriority(sig signature.Signature, round uint64) (uint64, error) {
+	if e.executorCommittee == nil || e.executorCommittee.Committee == nil {
+		return 0, fmt.Errorf("epoch: no active transaction scheduler")
+	}
+	priority, err := commitment.GetProposerPriority(e.runtime, e.executorCommittee.Committee, round, sig.PublicKey)
+	switch err {
+	case nil:
+		return priority, nil
+	case commitment.ErrNotProposer:
+		return 0, fmt.Errorf("epoch: signature is not from a transaction scheduler at round: %d", round)
+	default:
+		return 0, fmt.Errorf("epoch: error getting transaction scheduler: %w", err)
+	}
+}
+
 // Group encapsulates communication with a group of nodes in the runtime committees.
 type Group struct {
 	sync.RWMutex
@@ -0,0 +1,73 @@
+package committee
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	memorySigner "github.com/oasisprotocol/oasis-core/go/common/crypto/signature/signers/memory"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+)
+
+func TestVerifyTxnSchedulerSignatureAtHeight(t *testing.T) {
+	// Generate keys for the workers, in round zero the first one is the transaction scheduler
+	// while the others are the backup proposers in order.
+	var sks []signature.Signer
+	for i := 0; i < 4; i++ {
+		sk, err := memorySigner.NewSigner(rand.Reader)
+		require.NoError(t, err, "NewSigner")
+		sks = append(sks, sk)
+	}
+
+	runtime := registry.Runtime{
+		TxnScheduler: registry.TxnSchedulerParameters{
+			BackupProposers:       2,
+			BackupProposerTimeout: 5,
+		},
+	}
+	executorCommittee := scheduler.Committee{
+		RuntimeID: runtime.ID,
+		Kind:      scheduler.KindComputeExecutor,
+	}
+	for _, sk := range sks {
+		executorCommittee.Members = append(executorCommittee.Members, &scheduler.CommitteeNode{
+			Role:      scheduler.RoleWorker,
+			PublicKey: sk.Public(),
+		})
+	}
+	epoch := &EpochSnapshot{
+		runtime:           &runtime,
+		executorCommittee: &CommitteeInfo{Committee: &executorCommittee},
+	}
+
+	const roundStartHeight = 10
+	for _, tc := range []struct {
+		name     string
+		proposer int
+		height   int64
+		valid    bool
+	}{
+		{"Scheduler", 0, roundStartHeight + 1, true},
+		{"FirstBackupEarly", 1, roundStartHeight + 4, false},
+		{"FirstBackup", 1, roundStartHeight + 5, true},
+		{"SecondBackupEarly", 2, roundStartHeight + 9, false},
+		{"SecondBackup", 2, roundStartHeight + 10, true},
+		{"NotProposer", 3, roundStartHeight + 100, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+
+			sig := signature.Signature{PublicKey: sks[tc.proposer].Public()}
+			err := epoch.VerifyTxnSchedulerSignatureAtHeight(sig, 0, roundStartHeight, tc.height)
+			switch tc.valid {
+			case true:
+				require.NoError(err, "VerifyTxnSchedulerSignatureAtHeight")
+			case false:
+				require.Error(err, "VerifyTxnSchedulerSignatureAtHeight")
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	"github.com/oasisprotocol/oasis-core/go/runtime/nodes"
 	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
+	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
 	"github.com/oasisprotocol/oasis-core/go/worker/common/api"
 	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p"
 	p2pError "github.com/oasisprotocol/oasis-core/go/worker/common/p2p/error"
@@ -110,7 +111,10 @@ type Node struct {
 	CurrentBlock          *block.Block
 	CurrentBlockHeight    int64
 	CurrentConsensusBlock *consensus.LightBlock
-	Height                int64
+	// CurrentConsensusStateRoot is the consensus state root committed in the header of the
+	// consensus block at CurrentBlockHeight.
+	CurrentConsensusStateRoot storage.Root
+	Height                    int64
 
 	logger *logging.Logger
 }
@@ -253,11 +257,22 @@ func (n *Node) handleNewBlockLocked(blk *block.Block, height int64) {
 		)
 		return
 	}
+	// Fetch the consensus state root committed in the same block.
+	consensusStateBlk, err := n.Consensus.GetBlock(n.ctx, height)
+	if err != nil {
+		n.logger.Error("failed to query consensus block",
+			"err", err,
+			"height", height,
+			"round", blk.Header.Round,
+		)
+		return
+	}
 
 	// Update the current block.
 	n.CurrentBlock = blk
 	n.CurrentBlockHeight = height
 	n.CurrentConsensusBlock = consensusBlk
+	n.CurrentConsensusStateRoot = consensusStateBlk.StateRoot
 
 	for _, hooks := range n.hooks {
 		hooks.HandleNewBlockEarlyLocked(blk)
@@ -35,6 +35,7 @@ type computeRuntimeHostHandler struct {
 	keyManagerClient *keymanagerClient.Client
 	localStorage     localstorage.LocalStorage
 	consensus        consensus.Backend
+	consensusState   *consensusStateSyncer
 }
 
 func (h *computeRuntimeHostHandler) Handle(ctx context.Context, body *protocol.Body) (*protocol.Body, error) {
@@ -77,8 +78,8 @@ func (h *computeRuntimeHostHandler) Handle(ctx context.Context, body *protocol.B
 				sctx = storage.WithNodePriorityHintFromSignatures(sctx, blk.Header.StorageSignatures)
 			}
 		case protocol.HostStorageEndpointConsensus:
-			// Consensus state storage.
-			rs = h.consensus.State()
+			// Consensus state storage, verified against the committed state roots.
+			rs = h.consensusState
 		default:
 			return nil, errEndpointNotSupported
 		}
@@ -115,6 +116,16 @@ func (h *computeRuntimeHostHandler) Handle(ctx context.Context, body *protocol.B
 		}
 		return &protocol.Body{HostLocalStorageSetResponse: &protocol.Empty{}}, nil
 	}
+	// Consensus light client.
+	if body.HostFetchConsensusBlockRequest != nil {
+		lb, err := h.consensus.GetLightBlock(ctx, int64(body.HostFetchConsensusBlockRequest.Height))
+		if err != nil {
+			return nil, err
+		}
+		return &protocol.Body{HostFetchConsensusBlockResponse: &protocol.HostFetchConsensusBlockResponse{
+			Block: *lb,
+		}}, nil
+	}
 
 	return nil, errMethodNotSupported
 }
@@ -235,5 +246,6 @@ func (n *Node) NewRuntimeHostHandler() protocol.Handler {
 		keyManagerClient: n.KeyManagerClient,
 		localStorage:     n.Runtime.LocalStorage(),
 		consensus:        n.Consensus,
+		consensusState:   newConsensusStateSyncer(n.Consensus),
 	}
 }
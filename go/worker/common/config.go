@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	flag "github.com/spf13/pflag"
@@ -46,6 +47,38 @@ var (
 	// The value should be a map of runtime IDs to corresponding resource
 	// paths.
 	CfgRuntimeSGXSignatures = "worker.runtime.sgx.signatures"
+	// CfgRuntimeBundleDir configures the directory containing runtime bundles used for runtime
+	// upgrades without a node restart. Bundles are stored as <runtime-ID>/<version> (with an
+	// optional <runtime-ID>/<version>.sig SGX signature).
+	CfgRuntimeBundleDir = "worker.runtime.bundle_dir"
+	// CfgRuntimeBundleURL configures the base URL from which runtime bundles that are missing from
+	// the bundle directory are downloaded.
+	CfgRuntimeBundleURL = "worker.runtime.bundle_url"
+
+	// CfgRuntimeLimitsMemory configures the per-runtime memory limits. The value should be a map
+	// of runtime IDs to corresponding memory sizes (e.g., 2gb).
+	CfgRuntimeLimitsMemory = "worker.runtime.limits.memory"
+	// CfgRuntimeLimitsCPUs configures the per-runtime CPU limits. The value should be a map of
+	// runtime IDs to corresponding (fractional) numbers of CPUs.
+	CfgRuntimeLimitsCPUs = "worker.runtime.limits.cpus"
+	// CfgRuntimeLimitsPids configures the per-runtime process limits. The value should be a map of
+	// runtime IDs to corresponding maximum numbers of processes.
+	CfgRuntimeLimitsPids = "worker.runtime.limits.pids"
+
+	// CfgRuntimeRestartInitialBackoff configures the delay before restarting a crashed runtime.
+	CfgRuntimeRestartInitialBackoff = "worker.runtime.restart.initial_backoff"
+	// CfgRuntimeRestartMaxBackoff configures the maximum delay before restarting a crashed runtime.
+	CfgRuntimeRestartMaxBackoff = "worker.runtime.restart.max_backoff"
+	// CfgRuntimeCrashLoopThreshold configures the number of crashes within the crash loop window
+	// after which a runtime is considered to be crash looping.
+	CfgRuntimeCrashLoopThreshold = "worker.runtime.restart.crash_loop_threshold"
+	// CfgRuntimeCrashLoopWindow configures the crash loop detection window.
+	CfgRuntimeCrashLoopWindow = "worker.runtime.restart.crash_loop_window"
+	// CfgRuntimeCrashLoopCooldown configures the delay before restarting a crash looping runtime.
+	CfgRuntimeCrashLoopCooldown = "worker.runtime.restart.crash_loop_cooldown"
+	// CfgRuntimeRestartMinUptime configures the time a runtime must stay up after being started
+	// before its crash history is reset.
+	CfgRuntimeRestartMinUptime = "worker.runtime.restart.min_uptime"
 
 	cfgSandboxBinary        = "worker.runtime.sandbox_binary"
 	cfgStorageCommitTimeout = "worker.storage_commit_timeout"
@@ -92,6 +125,13 @@ type RuntimeHostConfig struct {
 	// Runtimes contains per-runtime provisioning configuration. Some fields may be omitted as they
 	// are provided when the runtime is provisioned.
 	Runtimes map[common.Namespace]runtimeHost.Config
+
+	// BundleDir is the directory containing runtime bundles used for runtime upgrades. In case it
+	// is empty, runtime upgrades without a node restart are disabled.
+	BundleDir string
+
+	// BundleURL is the optional base URL from which missing runtime bundles are downloaded.
+	BundleURL string
 }
 
 // GetNodeAddresses returns worker node addresses.
@@ -186,11 +226,23 @@ func NewConfig(consensus consensus.Backend, ias ias.Endpoint) (*Config, error) {
 					return nil, fmt.Errorf("failed to stat sandbox binary: %w", err)
 				}
 			}
+			restartPolicy := &hostSandbox.RestartPolicy{
+				InitialBackoff:     viper.GetDuration(CfgRuntimeRestartInitialBackoff),
+				MaxBackoff:         viper.GetDuration(CfgRuntimeRestartMaxBackoff),
+				CrashLoopThreshold: viper.GetInt(CfgRuntimeCrashLoopThreshold),
+				CrashLoopWindow:    viper.GetDuration(CfgRuntimeCrashLoopWindow),
+				CrashLoopCooldown:  viper.GetDuration(CfgRuntimeCrashLoopCooldown),
+				MinUptime:          viper.GetDuration(CfgRuntimeRestartMinUptime),
+			}
+			if restartPolicy.CrashLoopThreshold < 1 {
+				return nil, fmt.Errorf("crash loop threshold must be at least 1")
+			}
 			// Sandboxed provisioner, can be used with no TEE or with Intel SGX.
 			rh.Provisioners[node.TEEHardwareInvalid], err = hostSandbox.New(hostSandbox.Config{
 				HostInfo:          hostInfo,
 				InsecureNoSandbox: insecureNoSandbox,
 				SandboxBinaryPath: sandboxBinary,
+				RestartPolicy:     restartPolicy,
 			})
 			if err != nil {
 				return nil, fmt.Errorf("failed to create runtime provisioner: %w", err)
@@ -202,6 +254,7 @@ func NewConfig(consensus consensus.Backend, ias ias.Endpoint) (*Config, error) {
 				IAS:               ias,
 				SandboxBinaryPath: sandboxBinary,
 				InsecureNoSandbox: insecureNoSandbox,
+				RestartPolicy:     restartPolicy,
 			})
 			if err != nil {
 				return nil, fmt.Errorf("failed to create SGX runtime provisioner: %w", err)
@@ -243,6 +296,16 @@ func NewConfig(consensus consensus.Backend, ias ias.Endpoint) (*Config, error) {
 		if len(rh.Runtimes) == 0 {
 			return nil, fmt.Errorf("no runtimes configured")
 		}
+		if err = configureRuntimeLimits(rh.Runtimes); err != nil {
+			return nil, err
+		}
+
+		// Configure runtime upgrades.
+		rh.BundleDir = viper.GetString(CfgRuntimeBundleDir)
+		rh.BundleURL = viper.GetString(CfgRuntimeBundleURL)
+		if rh.BundleURL != "" && rh.BundleDir == "" {
+			return nil, fmt.Errorf("runtime bundle URL requires a runtime bundle directory")
+		}
 
 		cfg.RuntimeHost = &rh
 	}
@@ -250,6 +313,52 @@ func NewConfig(consensus consensus.Backend, ias ias.Endpoint) (*Config, error) {
 	return &cfg, nil
 }
 
+func configureRuntimeLimits(runtimes map[common.Namespace]runtimeHost.Config) error {
+	getConfig := func(runtimeID string) (*runtimeHost.Config, error) {
+		var id common.Namespace
+		if err := id.UnmarshalHex(runtimeID); err != nil {
+			return nil, fmt.Errorf("bad runtime identifier '%s': %w", runtimeID, err)
+		}
+		cfg, ok := runtimes[id]
+		if !ok {
+			return nil, fmt.Errorf("resource limits configured for unknown runtime '%s'", runtimeID)
+		}
+		return &cfg, nil
+	}
+
+	for runtimeID, value := range viper.GetStringMapString(CfgRuntimeLimitsMemory) {
+		cfg, err := getConfig(runtimeID)
+		if err != nil {
+			return err
+		}
+		if cfg.Limits.MemoryBytes, err = configparser.ParseSizeInBytes(value); err != nil {
+			return fmt.Errorf("bad memory limit for runtime '%s': %w", runtimeID, err)
+		}
+		runtimes[cfg.RuntimeID] = *cfg
+	}
+	for runtimeID, value := range viper.GetStringMapString(CfgRuntimeLimitsCPUs) {
+		cfg, err := getConfig(runtimeID)
+		if err != nil {
+			return err
+		}
+		if cfg.Limits.CPUs, err = strconv.ParseFloat(value, 64); err != nil || cfg.Limits.CPUs < 0 {
+			return fmt.Errorf("bad CPU limit for runtime '%s': %s", runtimeID, value)
+		}
+		runtimes[cfg.RuntimeID] = *cfg
+	}
+	for runtimeID, value := range viper.GetStringMapString(CfgRuntimeLimitsPids) {
+		cfg, err := getConfig(runtimeID)
+		if err != nil {
+			return err
+		}
+		if cfg.Limits.Pids, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return fmt.Errorf("bad process limit for runtime '%s': %w", runtimeID, err)
+		}
+		runtimes[cfg.RuntimeID] = *cfg
+	}
+	return nil
+}
+
 func init() {
 	Flags.Uint16(CfgClientPort, 9100, "Port to use for incoming gRPC client connections")
 	Flags.StringSlice(cfgClientAddresses, []string{}, "Address/port(s) to use for client connections when registering this node (if not set, all non-loopback local interfaces will be used)")
@@ -260,6 +369,20 @@ func init() {
 	Flags.StringToString(CfgRuntimePaths, nil, "Paths to runtime resources (format: <rt1-ID>=<path>,<rt2-ID>=<path>)")
 	Flags.StringToString(CfgRuntimeSGXSignatures, nil, "(for SGX runtimes) Paths to signatures (format: <rt1-ID>=<path>,<rt2-ID>=<path>")
 
+	Flags.String(CfgRuntimeBundleDir, "", "Path to the directory containing runtime bundles for upgrades without a node restart")
+	Flags.String(CfgRuntimeBundleURL, "", "Base URL for downloading missing runtime bundles (format: <url>/<rt-ID>/<version>)")
+
+	Flags.StringToString(CfgRuntimeLimitsMemory, nil, "Per-runtime memory limits, requires cgroup v2 (format: <rt1-ID>=<size>,<rt2-ID>=<size>)")
+	Flags.StringToString(CfgRuntimeLimitsCPUs, nil, "Per-runtime CPU limits, requires cgroup v2 (format: <rt1-ID>=<cpus>,<rt2-ID>=<cpus>)")
+	Flags.StringToString(CfgRuntimeLimitsPids, nil, "Per-runtime process limits, requires cgroup v2 (format: <rt1-ID>=<num>,<rt2-ID>=<num>)")
+
+	Flags.Duration(CfgRuntimeRestartInitialBackoff, hostSandbox.DefaultRestartPolicy.InitialBackoff, "Delay before restarting a crashed runtime (doubled on each subsequent crash)")
+	Flags.Duration(CfgRuntimeRestartMaxBackoff, hostSandbox.DefaultRestartPolicy.MaxBackoff, "Maximum delay before restarting a crashed runtime")
+	Flags.Int(CfgRuntimeCrashLoopThreshold, hostSandbox.DefaultRestartPolicy.CrashLoopThreshold, "Number of crashes within the crash loop window after which a runtime is considered to be crash looping")
+	Flags.Duration(CfgRuntimeCrashLoopWindow, hostSandbox.DefaultRestartPolicy.CrashLoopWindow, "Crash loop detection window")
+	Flags.Duration(CfgRuntimeCrashLoopCooldown, hostSandbox.DefaultRestartPolicy.CrashLoopCooldown, "Delay before restarting a crash looping runtime")
+	Flags.Duration(CfgRuntimeRestartMinUptime, hostSandbox.DefaultRestartPolicy.MinUptime, "Time a runtime must stay up after being started before its crash history is reset")
+
 	Flags.String(cfgSandboxBinary, "/usr/bin/bwrap", "Path to the sandbox binary (bubblewrap)")
 
 	Flags.Duration(cfgStorageCommitTimeout, 5*time.Second, "Storage commit timeout")
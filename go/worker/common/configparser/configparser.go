@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
@@ -52,3 +53,33 @@ func GetRuntimes(runtimeIDsHex []string) ([]common.Namespace, error) {
 	}
 	return runtimes, nil
 }
+
+// ParseSizeInBytes parses a size with an optional unit suffix (e.g., 512mb or 2GB) into bytes.
+func ParseSizeInBytes(size string) (uint64, error) {
+	s := strings.ToLower(strings.TrimSpace(size))
+	multiplier := uint64(1)
+	for _, unit := range []struct {
+		suffix     string
+		multiplier uint64
+	}{
+		{"gb", 1 << 30},
+		{"mb", 1 << 20},
+		{"kb", 1 << 10},
+		{"g", 1 << 30},
+		{"m", 1 << 20},
+		{"k", 1 << 10},
+		{"b", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSuffix(s, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	value, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed size: %s", size)
+	}
+	return value * multiplier, nil
+}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/multi"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
 	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
 )
@@ -19,10 +22,21 @@ type RuntimeHostNode struct {
 	notifier protocol.Notifier
 
 	runtime host.Runtime
+
+	// Runtime upgrade state, only used in case runtime upgrades are enabled.
+	versions            *multi.Aggregate
+	versionNotifiers    map[version.Version]protocol.Notifier
+	versionConfigs      map[version.Version]host.Config
+	pendingVersion      *version.Version
+	roundBoundarySwitch bool
 }
 
 // ProvisionHostedRuntime provisions the configured runtime.
 //
+// In case runtime upgrades are enabled, the returned runtime aggregates all
+// provisioned versions of the runtime and new versions are provisioned as
+// the registry descriptor changes.
+//
 // This method may return before the runtime is fully provisioned. The returned runtime will not be
 // started automatically, you must call Start explicitly.
 func (n *RuntimeHostNode) ProvisionHostedRuntime(ctx context.Context) (host.Runtime, protocol.Notifier, error) {
@@ -30,16 +44,96 @@ func (n *RuntimeHostNode) ProvisionHostedRuntime(ctx context.Context) (host.Runt
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get runtime registry descriptor: %w", err)
 	}
+	cfg, err := n.getRuntimeConfig(rt)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	provisioner, ok := n.cfg.Provisioners[rt.TEEHardware]
-	if !ok {
-		return nil, nil, fmt.Errorf("no provisioner suitable for TEE hardware '%s'", rt.TEEHardware)
+	prt, notifier, err := n.provisionRuntime(ctx, rt, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if n.cfg.BundleDir != "" {
+		agg := multi.New(rt.ID)
+		if err = agg.AddVersion(rt.Version.Version, prt); err != nil {
+			return nil, nil, err
+		}
+
+		n.Lock()
+		n.versions = agg
+		n.versionNotifiers = map[version.Version]protocol.Notifier{
+			rt.Version.Version: notifier,
+		}
+		n.versionConfigs = map[version.Version]host.Config{
+			rt.Version.Version: cfg,
+		}
+		n.Unlock()
+
+		prt = agg
+		notifier = &versionsNotifier{node: n, initial: notifier}
+
+		go n.watchRuntimeVersions(ctx)
+	}
+
+	n.Lock()
+	n.runtime = prt
+	n.notifier = notifier
+	n.Unlock()
+
+	return prt, notifier, nil
+}
+
+// ProvisionHostedRuntimeInstance provisions an additional instance of the configured runtime.
+//
+// The instance is not returned by GetHostedRuntime and the caller is responsible for managing
+// its lifetime. The returned runtime will not be started automatically, you must call Start
+// explicitly.
+func (n *RuntimeHostNode) ProvisionHostedRuntimeInstance(ctx context.Context) (host.Runtime, protocol.Notifier, error) {
+	rt, err := n.factory.GetRuntime().RegistryDescriptor(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get runtime registry descriptor: %w", err)
+	}
+	cfg, err := n.getRuntimeConfig(rt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return n.provisionRuntime(ctx, rt, cfg)
+}
+
+// getRuntimeConfig returns a copy of the configuration template for the given runtime. In case
+// the runtime has been upgraded, the configuration of the active version is returned.
+func (n *RuntimeHostNode) getRuntimeConfig(rt *registry.Runtime) (host.Config, error) {
+	n.Lock()
+	versions := n.versions
+	n.Unlock()
+	if versions != nil {
+		if v, err := versions.Version(); err == nil {
+			n.Lock()
+			cfg, ok := n.versionConfigs[v]
+			n.Unlock()
+			if ok {
+				return cfg, nil
+			}
+		}
 	}
 
-	// Get a copy of the configuration template for the given runtime and apply updates.
 	cfg, ok := n.cfg.Runtimes[rt.ID]
 	if !ok {
-		return nil, nil, fmt.Errorf("missing runtime host configuration for runtime '%s'", rt.ID)
+		return host.Config{}, fmt.Errorf("missing runtime host configuration for runtime '%s'", rt.ID)
+	}
+	return cfg, nil
+}
+
+func (n *RuntimeHostNode) provisionRuntime(
+	ctx context.Context,
+	rt *registry.Runtime,
+	cfg host.Config,
+) (host.Runtime, protocol.Notifier, error) {
+	provisioner, ok := n.cfg.Provisioners[rt.TEEHardware]
+	if !ok {
+		return nil, nil, fmt.Errorf("no provisioner suitable for TEE hardware '%s'", rt.TEEHardware)
 	}
 	cfg.MessageHandler = n.factory.NewRuntimeHostHandler()
 
@@ -50,11 +144,6 @@ func (n *RuntimeHostNode) ProvisionHostedRuntime(ctx context.Context) (host.Runt
 	}
 	notifier := n.factory.NewNotifier(ctx, prt)
 
-	n.Lock()
-	n.runtime = prt
-	n.notifier = notifier
-	n.Unlock()
-
 	return prt, notifier, nil
 }
 
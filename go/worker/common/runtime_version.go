@@ -0,0 +1,420 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/sgx"
+	"github.com/oasisprotocol/oasis-core/go/common/sgx/sigstruct"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+	cmdFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	hostSgx "github.com/oasisprotocol/oasis-core/go/runtime/host/sgx"
+)
+
+const (
+	// runtimeUpgradeStartTimeout is the time to wait for a new runtime version to start.
+	runtimeUpgradeStartTimeout = 2 * time.Minute
+
+	// bundleSignatureSuffix is the suffix of the SGX signature file of a runtime bundle.
+	bundleSignatureSuffix = ".sig"
+)
+
+// EnableRoundBoundaryVersionSwitch makes runtime upgrades switch to the new runtime version only
+// when SwitchRuntimeVersion is called. By default the switch happens as soon as the new version
+// has been started.
+//
+// This method must be called before the hosted runtime is provisioned.
+func (n *RuntimeHostNode) EnableRoundBoundaryVersionSwitch() {
+	n.Lock()
+	defer n.Unlock()
+
+	n.roundBoundarySwitch = true
+}
+
+// SwitchRuntimeVersion switches the hosted runtime to a new runtime version that has been
+// started and is waiting to become active (if any). The previous version is stopped in the
+// background, so this is safe to call while holding other locks.
+//
+// Nodes that enabled round boundary version switches should call this at round boundaries.
+func (n *RuntimeHostNode) SwitchRuntimeVersion() {
+	n.Lock()
+	defer n.Unlock()
+
+	n.switchRuntimeVersionLocked()
+}
+
+func (n *RuntimeHostNode) switchRuntimeVersionLocked() {
+	if n.pendingVersion == nil {
+		return
+	}
+	pending := *n.pendingVersion
+	n.pendingVersion = nil
+
+	logger := n.versionLogger()
+	prev, err := n.versions.Version()
+	if err != nil {
+		logger.Error("failed to get active runtime version",
+			"err", err,
+		)
+		return
+	}
+	if err = n.versions.SetVersion(pending); err != nil {
+		logger.Error("failed to switch runtime version",
+			"err", err,
+			"version", pending,
+		)
+		return
+	}
+	n.removeRuntimeVersionLocked(prev)
+
+	logger.Info("runtime upgraded",
+		"previous_version", prev,
+		"version", pending,
+	)
+}
+
+// removeRuntimeVersionLocked removes the given runtime version. Stopping the runtime may take a
+// while, so the runtime and its notifier are stopped in the background.
+func (n *RuntimeHostNode) removeRuntimeVersionLocked(v version.Version) {
+	notifier := n.versionNotifiers[v]
+	delete(n.versionNotifiers, v)
+	delete(n.versionConfigs, v)
+
+	go func() {
+		if notifier != nil {
+			notifier.Stop()
+		}
+		_ = n.versions.RemoveVersion(v)
+	}()
+}
+
+func (n *RuntimeHostNode) versionLogger() *logging.Logger {
+	return logging.GetLogger("worker/common/runtime-version").With("runtime_id", n.factory.GetRuntime().ID())
+}
+
+// watchRuntimeVersions watches the runtime registry descriptor and upgrades the hosted runtime
+// whenever the runtime version changes.
+func (n *RuntimeHostNode) watchRuntimeVersions(ctx context.Context) {
+	logger := n.versionLogger()
+
+	ch, sub, err := n.factory.GetRuntime().WatchRegistryDescriptor()
+	if err != nil {
+		logger.Error("failed to watch runtime registry descriptor",
+			"err", err,
+		)
+		return
+	}
+	defer sub.Close()
+
+	// Versions that failed to be upgraded to are not retried.
+	failed := make(map[version.Version]bool)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case rt := <-ch:
+			v := rt.Version.Version
+			if n.versions.HasVersion(v) || failed[v] {
+				continue
+			}
+
+			logger.Info("runtime version changed, upgrading runtime",
+				"version", v,
+			)
+			if err = n.upgradeRuntime(ctx, rt); err != nil {
+				logger.Error("failed to upgrade runtime, keeping previous version",
+					"err", err,
+					"version", v,
+				)
+				failed[v] = true
+			}
+		}
+	}
+}
+
+// upgradeRuntime provisions and starts the runtime version from the given registry descriptor
+// alongside the active version.
+func (n *RuntimeHostNode) upgradeRuntime(ctx context.Context, rt *registry.Runtime) error {
+	v := rt.Version.Version
+	if rt.Version.BundleHash == nil {
+		return fmt.Errorf("registry descriptor does not contain a runtime bundle hash")
+	}
+
+	// Obtain and verify the runtime bundle.
+	bundlePath, sigPath, err := n.fetchRuntimeBundle(ctx, rt)
+	if err != nil {
+		return err
+	}
+	if err = verifyRuntimeBundle(rt, bundlePath, sigPath); err != nil {
+		return err
+	}
+
+	cfg, err := n.getRuntimeConfig(rt)
+	if err != nil {
+		return err
+	}
+	cfg.Path = bundlePath
+	if rt.TEEHardware == node.TEEHardwareIntelSGX {
+		// Never use the signature of the previous version.
+		cfg.Extra = &hostSgx.RuntimeExtra{
+			SignaturePath:                sigPath,
+			UnsafeDebugGenerateSigstruct: sigPath == "",
+		}
+	}
+
+	// Start the new version alongside the active one.
+	prt, notifier, err := n.provisionRuntime(ctx, rt, cfg)
+	if err != nil {
+		return err
+	}
+	if err = n.versions.AddVersion(v, prt); err != nil {
+		return err
+	}
+	if err = startRuntimeVersion(ctx, prt, v); err != nil {
+		// Roll back to the active version.
+		notifier.Stop()
+		_ = n.versions.RemoveVersion(v)
+		return err
+	}
+	if err = notifier.Start(); err != nil {
+		notifier.Stop()
+		_ = n.versions.RemoveVersion(v)
+		return fmt.Errorf("failed to start runtime notifier: %w", err)
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	// Replace any version that is still waiting to become active.
+	if n.pendingVersion != nil {
+		n.removeRuntimeVersionLocked(*n.pendingVersion)
+	}
+	n.pendingVersion = &v
+	n.versionNotifiers[v] = notifier
+	n.versionConfigs[v] = cfg
+
+	if !n.roundBoundarySwitch {
+		n.switchRuntimeVersionLocked()
+	}
+	return nil
+}
+
+// startRuntimeVersion starts the given runtime and waits for it to successfully respond to the
+// runtime info request with the expected version.
+func startRuntimeVersion(ctx context.Context, rt host.Runtime, v version.Version) error {
+	evCh, sub, err := rt.WatchEvents(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to watch runtime events: %w", err)
+	}
+	defer sub.Close()
+
+	if err = rt.Start(); err != nil {
+		return fmt.Errorf("failed to start runtime: %w", err)
+	}
+
+	startCtx, cancel := context.WithTimeout(ctx, runtimeUpgradeStartTimeout)
+	defer cancel()
+
+	for {
+		select {
+		case <-startCtx.Done():
+			return fmt.Errorf("timed out while waiting for runtime to start")
+		case ev := <-evCh:
+			switch {
+			case ev.Started != nil:
+				if ev.Started.Version != v {
+					return fmt.Errorf("runtime reported unexpected version (expected: %s got: %s)",
+						v,
+						ev.Started.Version,
+					)
+				}
+				return nil
+			case ev.FailedToStart != nil:
+				return fmt.Errorf("runtime failed to start: %w", ev.FailedToStart.Error)
+			}
+		}
+	}
+}
+
+// fetchRuntimeBundle returns the paths to the runtime bundle and its (optional) SGX signature,
+// downloading them in case they are not available locally.
+func (n *RuntimeHostNode) fetchRuntimeBundle(ctx context.Context, rt *registry.Runtime) (string, string, error) {
+	dir := filepath.Join(n.cfg.BundleDir, rt.ID.String())
+	if err := common.Mkdir(dir); err != nil {
+		return "", "", fmt.Errorf("failed to create runtime bundle directory: %w", err)
+	}
+
+	bundlePath := filepath.Join(dir, rt.Version.Version.String())
+	sigPath := bundlePath + bundleSignatureSuffix
+	isSGX := rt.TEEHardware == node.TEEHardwareIntelSGX
+
+	for _, fn := range []string{bundlePath, sigPath} {
+		if fn == sigPath && !isSGX {
+			break
+		}
+		if _, err := os.Stat(fn); err == nil {
+			continue
+		}
+		if n.cfg.BundleURL == "" {
+			if fn == sigPath && cmdFlags.DebugDontBlameOasis() {
+				// A debug signature is generated instead.
+				sigPath = ""
+				break
+			}
+			return "", "", fmt.Errorf("runtime bundle not available: %s", fn)
+		}
+		if err := downloadFile(ctx, n.cfg.BundleURL, rt.ID, filepath.Base(fn), fn); err != nil {
+			return "", "", err
+		}
+	}
+	if !isSGX {
+		sigPath = ""
+	}
+	return bundlePath, sigPath, nil
+}
+
+func downloadFile(ctx context.Context, baseURL string, runtimeID common.Namespace, name, dst string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("malformed runtime bundle URL: %w", err)
+	}
+	u.Path = filepath.ToSlash(filepath.Join(u.Path, runtimeID.String(), name))
+
+	rq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create runtime bundle request: %w", err)
+	}
+	rsp, err := http.DefaultClient.Do(rq)
+	if err != nil {
+		return fmt.Errorf("failed to download runtime bundle: %w", err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download runtime bundle: %s", rsp.Status)
+	}
+
+	// Download into a temporary file first so that partial downloads are never used.
+	f, err := ioutil.TempFile(filepath.Dir(dst), filepath.Base(dst)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create runtime bundle file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err = io.Copy(f, rsp.Body); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to download runtime bundle: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to write runtime bundle: %w", err)
+	}
+	if err = os.Chmod(f.Name(), 0o700); err != nil {
+		return fmt.Errorf("failed to write runtime bundle: %w", err)
+	}
+	return os.Rename(f.Name(), dst)
+}
+
+// verifyRuntimeBundle verifies the runtime bundle hash and SGX enclave identity against the
+// version information in the given registry descriptor.
+func verifyRuntimeBundle(rt *registry.Runtime, bundlePath, sigPath string) error {
+	bundle, err := ioutil.ReadFile(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to read runtime bundle: %w", err)
+	}
+	bundleHash := hash.NewFromBytes(bundle)
+	if !bundleHash.Equal(rt.Version.BundleHash) {
+		return fmt.Errorf("runtime bundle hash mismatch (expected: %s got: %s)",
+			rt.Version.BundleHash,
+			bundleHash,
+		)
+	}
+
+	if rt.TEEHardware != node.TEEHardwareIntelSGX {
+		return nil
+	}
+
+	var vi registry.VersionInfoIntelSGX
+	if err = cbor.Unmarshal(rt.Version.TEE, &vi); err != nil {
+		return fmt.Errorf("malformed SGX version information: %w", err)
+	}
+	var mrEnclave sgx.MrEnclave
+	if err = mrEnclave.FromSgxsBytes(bundle); err != nil {
+		return fmt.Errorf("failed to derive MRENCLAVE: %w", err)
+	}
+
+	// The signer must always be known, as otherwise any enclave with an allowed MRENCLAVE would
+	// be accepted regardless of who signed it.
+	var mrSigner sgx.MrSigner
+	if sigPath == "" {
+		if !cmdFlags.DebugDontBlameOasis() {
+			return fmt.Errorf("runtime bundle signature not available")
+		}
+		// The debug signature is generated with the dummy signing key.
+		mrSigner = sgx.FortanixDummyMrSigner
+	} else {
+		var sig []byte
+		if sig, err = ioutil.ReadFile(sigPath); err != nil {
+			return fmt.Errorf("failed to read runtime bundle signature: %w", err)
+		}
+		pk, parsed, verr := sigstruct.Verify(sig)
+		if verr != nil {
+			return fmt.Errorf("failed to verify runtime bundle signature: %w", verr)
+		}
+		if parsed.EnclaveHash != mrEnclave {
+			return fmt.Errorf("runtime bundle signature is for a different enclave")
+		}
+		if err = mrSigner.FromPublicKey(pk); err != nil {
+			return fmt.Errorf("failed to derive MRSIGNER: %w", err)
+		}
+	}
+
+	for _, id := range vi.Enclaves {
+		if id.MrEnclave != mrEnclave {
+			continue
+		}
+		if id.MrSigner != mrSigner {
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("runtime bundle enclave identity not allowed (mr_enclave: %s mr_signer: %s)",
+		mrEnclave,
+		mrSigner,
+	)
+}
+
+// versionsNotifier is the runtime host notifier used in case runtime upgrades are enabled. Each
+// runtime version has its own notifier.
+type versionsNotifier struct {
+	node    *RuntimeHostNode
+	initial protocol.Notifier
+}
+
+// Implements protocol.Notifier.
+func (vn *versionsNotifier) Start() error {
+	return vn.initial.Start()
+}
+
+// Implements protocol.Notifier.
+func (vn *versionsNotifier) Stop() {
+	vn.node.Lock()
+	defer vn.node.Unlock()
+
+	for v, notifier := range vn.node.versionNotifiers {
+		notifier.Stop()
+		delete(vn.node.versionNotifiers, v)
+	}
+}
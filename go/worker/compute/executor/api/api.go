@@ -1,6 +1,67 @@
 package api
 
+import (
+	"context"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
+)
+
+// ModuleName is the executor worker module name.
+const ModuleName = "worker/executor"
+
+var (
+	// ErrRuntimeNotFound is the error returned when the called references an unknown runtime.
+	ErrRuntimeNotFound = errors.New(ModuleName, 1, "worker/executor: runtime not found")
+
+	// ErrForensicsDisabled is the error returned when forensics bundles are requested but
+	// recording is not enabled for the runtime.
+	ErrForensicsDisabled = errors.New(ModuleName, 2, "worker/executor: forensics recording not enabled")
+
+	// ErrBundleNotFound is the error returned when no forensics bundle is available for the
+	// requested round.
+	ErrBundleNotFound = errors.New(ModuleName, 3, "worker/executor: forensics bundle not found")
+)
+
 // Tx is a runtime transaction being sent to the executor node.
 type Tx struct {
 	Data []byte `json:"data"`
 }
+
+// ExecutorWorker is the executor worker control API interface.
+type ExecutorWorker interface {
+	// GetForensicsBundle retrieves the forensics bundle recorded for the given round.
+	GetForensicsBundle(ctx context.Context, request *GetForensicsBundleRequest) (*ForensicsBundle, error)
+}
+
+// GetForensicsBundleRequest is a GetForensicsBundle request.
+type GetForensicsBundleRequest struct {
+	RuntimeID common.Namespace `json:"runtime_id"`
+	Round     uint64           `json:"round"`
+}
+
+// ForensicsBundle is everything an executor node used and produced when executing the batch of
+// a given round. It allows the execution to be compared against other committee members and
+// to be replayed in order to track down sources of non-determinism.
+type ForensicsBundle struct {
+	// RuntimeID is the runtime the batch has been executed for.
+	RuntimeID common.Namespace `json:"runtime_id"`
+	// Round is the round the batch has been executed in.
+	Round uint64 `json:"round"`
+	// NodeID is the identifier of the executor node that produced the bundle.
+	NodeID signature.PublicKey `json:"node_id"`
+
+	// Request is the batch execution request as it has been sent to the runtime. It contains
+	// the input batch together with the parent block and consensus state it was executed on.
+	Request protocol.RuntimeExecuteTxBatchRequest `json:"request"`
+	// Body is the compute results body of the executor commitment.
+	Body commitment.ComputeBody `json:"body"`
+	// IOWriteLog is the I/O write log produced by the runtime, containing the outputs and tags.
+	IOWriteLog storage.WriteLog `json:"io_write_log"`
+	// StateWriteLog is the state write log produced by the runtime.
+	StateWriteLog storage.WriteLog `json:"state_write_log"`
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	cmnGrpc "github.com/oasisprotocol/oasis-core/go/common/grpc"
+)
+
+var (
+	errInvalidRequestType = fmt.Errorf("invalid request type")
+
+	// ServiceName is the gRPC service name.
+	ServiceName = cmnGrpc.NewServiceName("ExecutorWorker")
+
+	// MethodGetForensicsBundle is the GetForensicsBundle method.
+	MethodGetForensicsBundle = ServiceName.NewMethod("GetForensicsBundle", &GetForensicsBundleRequest{}).
+					WithNamespaceExtractor(func(ctx context.Context, req interface{}) (common.Namespace, error) {
+			r, ok := req.(*GetForensicsBundleRequest)
+			if !ok {
+				return common.Namespace{}, errInvalidRequestType
+			}
+			return r.RuntimeID, nil
+		}).
+		WithAccessControl(func(ctx context.Context, req interface{}) (bool, error) {
+			return true, nil
+		})
+
+	// serviceDesc is the gRPC service descriptor.
+	serviceDesc = grpc.ServiceDesc{
+		ServiceName: string(ServiceName),
+		HandlerType: (*ExecutorWorker)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: MethodGetForensicsBundle.ShortName(),
+				Handler:    handlerGetForensicsBundle,
+			},
+		},
+		Streams: []grpc.StreamDesc{},
+	}
+)
+
+func handlerGetForensicsBundle( // nolint: golint
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	rq := new(GetForensicsBundleRequest)
+	if err := dec(rq); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExecutorWorker).GetForensicsBundle(ctx, rq)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MethodGetForensicsBundle.FullName(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExecutorWorker).GetForensicsBundle(ctx, req.(*GetForensicsBundleRequest))
+	}
+	return interceptor(ctx, rq, info, handler)
+}
+
+// RegisterService registers a new executor worker service with the given gRPC server.
+func RegisterService(server *grpc.Server, service ExecutorWorker) {
+	server.RegisterService(&serviceDesc, service)
+}
+
+type executorWorkerClient struct {
+	conn *grpc.ClientConn
+}
+
+func (c *executorWorkerClient) GetForensicsBundle(ctx context.Context, req *GetForensicsBundleRequest) (*ForensicsBundle, error) {
+	var rsp ForensicsBundle
+	if err := c.conn.Invoke(ctx, MethodGetForensicsBundle.FullName(), req, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// NewExecutorWorkerClient creates a new gRPC executor worker client service.
+func NewExecutorWorkerClient(c *grpc.ClientConn) ExecutorWorker {
+	return &executorWorkerClient{c}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
@@ -52,3 +53,17 @@ func (ub *unresolvedBatch) resolve(ctx context.Context, sb storage.Backend) (tra
 	}
 	return batch, nil
 }
+
+// commitInputs creates a new I/O tree under the given (empty) root containing only the inputs
+// of the given batch and returns its write log and root hash.
+func commitInputs(ctx context.Context, root storage.Root, batch transaction.RawBatch) (storage.WriteLog, hash.Hash, error) {
+	ioTree := transaction.NewTree(nil, root)
+	defer ioTree.Close()
+
+	for idx, tx := range batch {
+		if err := ioTree.AddTransaction(ctx, transaction.Transaction{Input: tx, BatchOrder: uint32(idx)}, nil); err != nil {
+			return nil, hash.Hash{}, err
+		}
+	}
+	return ioTree.Commit(ctx)
+}
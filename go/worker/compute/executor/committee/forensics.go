@@ -0,0 +1,102 @@
+package committee
+
+import (
+	"github.com/oasisprotocol/oasis-core/go/common/accessctl"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	"github.com/oasisprotocol/oasis-core/go/worker/common/committee"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/api"
+)
+
+// forensicsQueueSize is the maximum number of forensics bundles waiting to be persisted.
+const forensicsQueueSize = 16
+
+// forensicsAccessPolicy is the access policy for fetching forensics bundles via the external
+// forensics service.
+var forensicsAccessPolicy = &committee.AccessPolicy{
+	Actions: []accessctl.Action{
+		accessctl.Action(api.MethodGetForensicsBundle.FullName()),
+	},
+}
+
+// queueForensicsLocked queues the forensics bundle for the given processed batch and the compute
+// results proposed for it to be persisted by the forensics worker in case forensics recording is
+// enabled.
+//
+// Guarded by n.commonNode.CrossNode.
+func (n *Node) queueForensicsLocked(batch *processedBatch, body *commitment.ComputeBody) {
+	if n.forensicsStore == nil || batch.request == nil {
+		return
+	}
+
+	bundle := &api.ForensicsBundle{
+		RuntimeID:     n.commonNode.Runtime.ID(),
+		Round:         body.Header.Round,
+		NodeID:        n.commonNode.Identity.NodeSigner.Public(),
+		Request:       *batch.request,
+		Body:          *body,
+		IOWriteLog:    batch.computed.IOWriteLog,
+		StateWriteLog: batch.computed.StateWriteLog,
+	}
+	select {
+	case n.forensicsCh <- bundle:
+	default:
+		// Failing to record forensics should not affect the round.
+		n.logger.Error("forensics queue full, dropping forensics bundle",
+			"round", bundle.Round,
+		)
+	}
+}
+
+// forensicsWorker persists queued forensics bundles until the node's context is canceled.
+func (n *Node) forensicsWorker(quitCh chan<- struct{}) {
+	defer close(quitCh)
+
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case bundle := <-n.forensicsCh:
+			if err := n.forensicsStore.Put(bundle); err != nil {
+				n.logger.Error("failed to record forensics bundle",
+					"err", err,
+					"round", bundle.Round,
+				)
+			}
+		}
+	}
+}
+
+// updateForensicsPolicyLocked updates the access policy of the external forensics service for the
+// given epoch. Access is allowed to the members of the executor committee, the configured sentry
+// nodes and the configured forensics clients.
+//
+// Guarded by n.commonNode.CrossNode.
+func (n *Node) updateForensicsPolicyLocked(epoch *committee.EpochSnapshot) {
+	if n.forensicsPolicy == nil {
+		return
+	}
+
+	policy := accessctl.NewPolicy()
+	for _, addr := range n.commonCfg.SentryAddresses {
+		forensicsAccessPolicy.AddPublicKeyPolicy(&policy, addr.PubKey)
+	}
+	for _, pk := range n.forensicsClients {
+		forensicsAccessPolicy.AddPublicKeyPolicy(&policy, pk)
+	}
+	if executorCommittee := epoch.GetExecutorCommittee(); executorCommittee != nil {
+		forensicsAccessPolicy.AddRulesForCommittee(&policy, executorCommittee, epoch.Nodes())
+	}
+
+	n.forensicsPolicy.SetAccessPolicy(policy, n.commonNode.Runtime.ID())
+	n.logger.Debug("set new forensics gRPC access policy",
+		"policy", policy,
+	)
+}
+
+// GetForensicsBundle returns the forensics bundle recorded for the given round.
+func (n *Node) GetForensicsBundle(round uint64) (*api.ForensicsBundle, error) {
+	if n.forensicsStore == nil {
+		return nil, api.ErrForensicsDisabled
+	}
+	return n.forensicsStore.Get(round)
+}
@@ -6,10 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/eapache/channels"
 	"github.com/opentracing/opentracing-go"
+	opentracingExt "github.com/opentracing/opentracing-go/ext"
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/oasisprotocol/oasis-core/go/common/cache/lru"
@@ -17,20 +19,24 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/common/crash"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/grpc/policy"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
 	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
 	"github.com/oasisprotocol/oasis-core/go/common/tracing"
 	"github.com/oasisprotocol/oasis-core/go/common/version"
 	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/message"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
 	"github.com/oasisprotocol/oasis-core/go/runtime/nodes"
 	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling"
 	schedulingAPI "github.com/oasisprotocol/oasis-core/go/runtime/scheduling/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/persistent"
 	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
 	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
@@ -38,6 +44,8 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/worker/common/committee"
 	"github.com/oasisprotocol/oasis-core/go/worker/common/p2p"
 	p2pError "github.com/oasisprotocol/oasis-core/go/worker/common/p2p/error"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/api"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/forensics"
 	"github.com/oasisprotocol/oasis-core/go/worker/registration"
 )
 
@@ -76,6 +84,34 @@ var (
 		},
 		[]string{"runtime"},
 	)
+	parallelBatchCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_worker_parallel_batch_count",
+			Help: "Number of batches executed in parallel sub-batches.",
+		},
+		[]string{"runtime"},
+	)
+	parallelFallbackCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_worker_parallel_fallback_count",
+			Help: "Number of batches re-executed sequentially after parallel execution failed (e.g., due to conflicting sub-batches).",
+		},
+		[]string{"runtime"},
+	)
+	preExecutedBatchCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_worker_pre_executed_batch_count",
+			Help: "Number of batches whose speculatively pre-executed results have been used.",
+		},
+		[]string{"runtime"},
+	)
+	discardedPreExecutionCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_worker_discarded_pre_execution_count",
+			Help: "Number of discarded speculative batch pre-executions.",
+		},
+		[]string{"runtime"},
+	)
 	storageCommitLatency = prometheus.NewSummaryVec(
 		prometheus.SummaryOpts{
 			Name: "oasis_worker_storage_commit_latency",
@@ -111,6 +147,14 @@ var (
 		},
 		[]string{"runtime"},
 	)
+	stateDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "oasis_worker_executor_state_duration",
+			Help:    "Time spent by the executor node in each state (seconds).",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 14),
+		},
+		[]string{"runtime", "state"},
+	)
 	incomingQueueSize = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "oasis_worker_incoming_queue_size",
@@ -121,11 +165,16 @@ var (
 	nodeCollectors = []prometheus.Collector{
 		discrepancyDetectedCount,
 		abortedBatchCount,
+		parallelBatchCount,
+		parallelFallbackCount,
+		preExecutedBatchCount,
+		discardedPreExecutionCount,
 		storageCommitLatency,
 		batchReadTime,
 		batchProcessingTime,
 		batchRuntimeProcessingTime,
 		batchSize,
+		stateDuration,
 		incomingQueueSize,
 	}
 
@@ -136,24 +185,57 @@ var (
 type Node struct { // nolint: maligned
 	*commonWorker.RuntimeHostNode
 
-	runtimeVersion version.Version
+	// Version of the hosted runtime, set once the runtime has started.
+	runtimeVersion *version.Version
 
 	lastScheduledCache     *lru.Cache
 	scheduleCheckTxEnabled bool
 	scheduleMaxTxPoolSize  uint64
 	scheduleCh             *channels.RingChannel
 
-	// The scheduler mutex is here to protect the initialization
-	// of the scheduler variable. After initialization the variable
-	// will never change though -- so if the variable is non-nil
-	// (which must be checked while holding the read lock) it can
-	// safely be used without holding the lock.
+	// The scheduler mutex is here to protect the scheduler variable, which is
+	// replaced when the scheduling algorithm changes. It must be held for any
+	// use of the scheduler and must be acquired after .commonNode.CrossNode.
 	schedulerMutex sync.RWMutex
 	scheduler      schedulingAPI.Scheduler
 
+	// Number of runtime instances used for parallel execution and the
+	// additional runtime instances (besides the hosted runtime). The latter
+	// are only updated by the worker goroutine and are guarded by
+	// .commonNode.CrossNode.
+	parallelRuntimeInstances uint64
+	parallelRuntimes         []*runtimeInstance
+	// Declared read/write sets of queued transactions.
+	rwSetCache *lru.Cache
+	// TEE capability of the hosted runtime.
+	// Guarded by .commonNode.CrossNode.
+	capabilityTEE *node.CapabilityTEE
+
+	// Optional persistent store of queued transactions.
+	txStore persistent.Store
+	// Round of the latest block, accessed atomically as it is needed when
+	// persisting transactions without holding .commonNode.CrossNode.
+	latestRound uint64
+
+	// Whether the next batch is speculatively pre-executed while waiting for finalization.
+	preExecutionEnabled bool
+	// Guarded by .commonNode.CrossNode.
+	preExecution  *preExecutedBatch
+	preExecutions *pubsub.Broker
+
+	// Optional persistent store of forensics bundles. Bundles are queued for the forensics
+	// worker so that they are persisted without holding .commonNode.CrossNode.
+	forensicsStore forensics.Store
+	forensicsCh    chan *api.ForensicsBundle
+	// Access policy of the external forensics service and the TLS public keys of additional
+	// clients allowed to fetch bundles.
+	forensicsPolicy  *policy.DynamicRuntimePolicyChecker
+	forensicsClients []signature.PublicKey
+
 	// Guarded by .commonNode.CrossNode.
 	proposingTimeout bool
 	prevEpochWorker  bool
+	txsRestored      bool
 
 	commonNode   *committee.Node
 	commonCfg    commonWorker.Config
@@ -169,6 +251,9 @@ type Node struct { // nolint: maligned
 	// Mutable and shared with common node's worker.
 	// Guarded by .commonNode.CrossNode.
 	state NodeState
+	// Time when the current state has been entered.
+	// Guarded by .commonNode.CrossNode.
+	stateEnteredAt time.Time
 	// Context valid until the next round.
 	// Guarded by .commonNode.CrossNode.
 	roundCtx       context.Context
@@ -204,6 +289,12 @@ func (n *Node) Quit() <-chan struct{} {
 
 // Cleanup performs the service specific post-termination cleanup.
 func (n *Node) Cleanup() {
+	if n.txStore != nil {
+		n.txStore.Close()
+	}
+	if n.forensicsStore != nil {
+		n.forensicsStore.Close()
+	}
 }
 
 // Initialized returns a channel that will be closed when the node is
@@ -228,11 +319,21 @@ func (n *Node) getMetricLabels() prometheus.Labels {
 }
 
 // Assumes scheduler is initialized.
+// Guarded by n.commonNode.CrossNode and n.schedulerMutex.
 func (n *Node) clearQueuedTxs() {
 	n.scheduler.Clear()
 	if n.lastScheduledCache != nil {
 		n.lastScheduledCache.Clear()
 	}
+	// Only clear persisted transactions after they have been restored as the
+	// queue is always cleared on the first epoch transition after a restart.
+	if n.txStore != nil && n.txsRestored {
+		if err := n.txStore.Clear(); err != nil {
+			n.logger.Warn("failed to clear persistent transaction pool",
+				"err", err,
+			)
+		}
+	}
 	incomingQueueSize.With(n.getMetricLabels()).Set(0)
 }
 
@@ -254,15 +355,17 @@ func (n *Node) HandlePeerMessage(ctx context.Context, message *p2p.Message, isOw
 			return true, nil
 		}
 
+		var checkResult *transaction.TxnCheckResult
 		if n.scheduleCheckTxEnabled {
 			// Check transaction before queuing it.
-			if err := n.checkTx(ctx, tx); err != nil {
+			var err error
+			if checkResult, err = n.checkTx(ctx, tx); err != nil {
 				return true, err
 			}
 			n.logger.Debug("worker CheckTx successful, queuing transaction")
 		}
 
-		err := n.QueueTx(tx)
+		err := n.queueTx(tx, checkResult)
 		if err != nil {
 			n.logger.Error("unable to queue transaction",
 				"err", err,
@@ -283,11 +386,14 @@ func (n *Node) HandlePeerMessage(ctx context.Context, message *p2p.Message, isOw
 		epoch := n.commonNode.Group.GetEpochSnapshot()
 		n.commonNode.CrossNode.Lock()
 		round := n.commonNode.CurrentBlock.Header.Round
+		roundStartHeight := n.commonNode.CurrentBlockHeight
+		height := n.commonNode.Height + 1 // Commitments are included in the next consensus block.
 		n.commonNode.CrossNode.Unlock()
 
 		// Before opening the signed dispatch message, verify that it was
-		// actually signed by the current transaction scheduler.
-		if err := epoch.VerifyTxnSchedulerSignature(sbd.Signature, round); err != nil {
+		// actually signed by the current transaction scheduler. Batches from
+		// backup proposers received before their timeout are retried later.
+		if err := epoch.VerifyTxnSchedulerSignatureAtHeight(sbd.Signature, round, roundStartHeight, height); err != nil {
 			// Not signed by a current txn scheduler!
 			return false, errMsgFromNonTxnSched
 		}
@@ -433,6 +539,18 @@ func (n *Node) transitionLocked(state NodeState) {
 		panic(fmt.Sprintf("invalid state transition: %s -> %s", n.state, state))
 	}
 
+	// Record time spent in the previous state.
+	if n.state.Name() != state.Name() {
+		labels := n.getMetricLabels()
+		labels["state"] = string(n.state.Name())
+		stateDuration.With(labels).Observe(time.Since(n.stateEnteredAt).Seconds())
+		n.stateEnteredAt = time.Now()
+	}
+	// Once we are no longer waiting for the round to be finalized, finish the finalization span.
+	if s, ok := n.state.(StateWaitingForFinalize); ok && s.span != nil {
+		s.span.Finish()
+	}
+
 	n.state = state
 	n.stateTransitions.Broadcast(state)
 	// Restart our worker's select in case our state-specific channels have changed.
@@ -463,6 +581,8 @@ func (n *Node) HandleEpochTransitionLocked(epoch *committee.EpochSnapshot) {
 		n.transitionLocked(StateNotReady{})
 	}
 	n.prevEpochWorker = epoch.IsExecutorWorker()
+
+	n.updateForensicsPolicyLocked(epoch)
 }
 
 // HandleNewBlockEarlyLocked implements NodeHooks.
@@ -478,6 +598,7 @@ func (n *Node) HandleNewBlockEarlyLocked(blk *block.Block) {
 // Guarded by n.commonNode.CrossNode.
 func (n *Node) HandleNewBlockLocked(blk *block.Block) {
 	header := blk.Header
+	atomic.StoreUint64(&n.latestRound, header.Round)
 
 	// Cancel old round context, start a new one.
 	if n.roundCancelCtx != nil {
@@ -485,6 +606,12 @@ func (n *Node) HandleNewBlockLocked(blk *block.Block) {
 	}
 	n.roundCtx, n.roundCancelCtx = context.WithCancel(n.ctx)
 
+	// Switch to a new runtime version at the round boundary if one is ready.
+	n.SwitchRuntimeVersion()
+
+	// Keep or discard any batch pre-executed on top of the expected block.
+	n.resolvePreExecutionLocked(blk)
+
 	// Perform actions based on current state.
 	switch state := n.state.(type) {
 	case StateWaitingForBlock:
@@ -528,6 +655,9 @@ func (n *Node) HandleNewBlockLocked(blk *block.Block) {
 				"header_hash", header.EncodedHash(),
 				"header_type", header.HeaderType,
 			)
+			if state.span != nil {
+				state.span.SetTag("header_type", header.HeaderType)
+			}
 			if header.HeaderType != block.Normal {
 				return
 			}
@@ -561,6 +691,12 @@ func (n *Node) HandleNewBlockLocked(blk *block.Block) {
 	// Clear the potentially set "is proposing timeout" flag from the previous round.
 	n.proposingTimeout = false
 
+	// Restore persisted transactions once we are part of the executor committee.
+	if n.txStore != nil && !n.txsRestored && n.prevEpochWorker {
+		n.txsRestored = true
+		go n.restoreQueuedTxs(n.ctx, header.Round)
+	}
+
 	// Check if we are a proposer and if so try to immediately schedule a new batch.
 	if n.commonNode.Group.GetEpochSnapshot().IsTransactionScheduler(blk.Header.Round) {
 		n.logger.Info("we are a transaction scheduler",
@@ -572,27 +708,48 @@ func (n *Node) HandleNewBlockLocked(blk *block.Block) {
 }
 
 // checkTx requests the runtime to check the validity of the given transaction.
-func (n *Node) checkTx(ctx context.Context, tx []byte) error {
+//
+// On success the check result reported by the runtime is returned. It is nil in
+// case the runtime did not report a check result.
+func (n *Node) checkTx(ctx context.Context, tx []byte) (*transaction.TxnCheckResult, error) {
+	results, errs, err := n.checkTxBatch(ctx, transaction.RawBatch{tx})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], errs[0]
+}
+
+// checkTxBatch requests the runtime to check the validity of the given transactions.
+//
+// For each transaction either the check result reported by the runtime or an
+// error is returned. The check result is nil in case the runtime did not report
+// a check result.
+func (n *Node) checkTxBatch(
+	ctx context.Context,
+	batch transaction.RawBatch,
+) ([]*transaction.TxnCheckResult, []error, error) {
 	n.commonNode.CrossNode.Lock()
 	currentBlock := n.commonNode.CurrentBlock
 	currentConsensusBlock := n.commonNode.CurrentConsensusBlock
+	currentConsensusStateRoot := n.commonNode.CurrentConsensusStateRoot
 	n.commonNode.CrossNode.Unlock()
 
 	if currentBlock == nil || currentConsensusBlock == nil {
-		return errNotReady
+		return nil, nil, errNotReady
 	}
 
 	checkRq := &protocol.Body{
 		RuntimeCheckTxBatchRequest: &protocol.RuntimeCheckTxBatchRequest{
 			ConsensusBlock: *currentConsensusBlock,
-			Inputs:         transaction.RawBatch{tx},
+			ConsensusState: protocol.ConsensusState{ConsensusStateRoot: currentConsensusStateRoot},
+			Inputs:         batch,
 			Block:          *currentBlock,
 		},
 	}
 	rt := n.GetHostedRuntime()
 	if rt == nil {
 		n.logger.Error("CheckTx: hosted runtime not initialized")
-		return errNotReady
+		return nil, nil, errNotReady
 	}
 	resp, err := rt.Call(ctx, checkRq)
 	switch {
@@ -600,41 +757,59 @@ func (n *Node) checkTx(ctx context.Context, tx []byte) error {
 		n.logger.Error("CheckTx: runtime call error",
 			"err", err,
 		)
-		return p2pError.Permanent(err)
+		return nil, nil, p2pError.Permanent(err)
 	case resp.RuntimeCheckTxBatchResponse == nil:
 		n.logger.Error("CheckTx: runtime response is nil")
-		return errCheckTxFailed
+		return nil, nil, errCheckTxFailed
 	case resp.RuntimeCheckTxBatchResponse.Results == nil:
 		n.logger.Error("CheckTx: response contains no results")
-		return errCheckTxFailed
-	case len(resp.RuntimeCheckTxBatchResponse.Results) != 1:
-		n.logger.Error("CheckTx: runtime response doesn't contain exactly one result",
+		return nil, nil, errCheckTxFailed
+	case len(resp.RuntimeCheckTxBatchResponse.Results) != len(batch):
+		n.logger.Error("CheckTx: runtime response doesn't contain a result for each transaction",
 			"num_results", len(resp.RuntimeCheckTxBatchResponse.Results),
+			"num_txs", len(batch),
 		)
-		return errCheckTxFailed
+		return nil, nil, errCheckTxFailed
 	}
 
-	// Interpret CheckTx result.
-	resultRaw := resp.RuntimeCheckTxBatchResponse.Results[0]
-	var result transaction.TxnOutput
-	if err = cbor.Unmarshal(resultRaw, &result); err != nil {
-		n.logger.Error("CheckTx: runtime response failed to deserialize",
-			"err", err,
-		)
-		return errCheckTxFailed
-	}
-	if result.Error != nil {
-		n.logger.Error("CheckTx: runtime failed with error",
-			"err", result.Error,
-		)
-		return fmt.Errorf("%w: %s", errCheckTxFailed, *result.Error)
-	}
+	// Interpret CheckTx results.
+	results := make([]*transaction.TxnCheckResult, len(batch))
+	errs := make([]error, len(batch))
+	for i, resultRaw := range resp.RuntimeCheckTxBatchResponse.Results {
+		var result transaction.TxnOutput
+		if err = cbor.Unmarshal(resultRaw, &result); err != nil {
+			n.logger.Error("CheckTx: runtime response failed to deserialize",
+				"err", err,
+			)
+			errs[i] = errCheckTxFailed
+			continue
+		}
+		if result.Error != nil {
+			n.logger.Error("CheckTx: runtime failed with error",
+				"err", result.Error,
+			)
+			errs[i] = fmt.Errorf("%w: %s", errCheckTxFailed, *result.Error)
+			continue
+		}
 
-	return nil
+		// Runtimes are not required to report a check result, so ignore any other outputs.
+		var checkResult transaction.TxnCheckResult
+		if err = cbor.Unmarshal(result.Success, &checkResult); err != nil {
+			continue
+		}
+		results[i] = &checkResult
+	}
+	return results, errs, nil
 }
 
 // QueueTx queues a runtime transaction for scheduling.
 func (n *Node) QueueTx(tx []byte) error {
+	return n.queueTx(tx, nil)
+}
+
+// queueTx queues a runtime transaction together with its (optional) check
+// result for scheduling.
+func (n *Node) queueTx(tx []byte, checkResult *transaction.TxnCheckResult) error {
 	n.schedulerMutex.RLock()
 	defer n.schedulerMutex.RUnlock()
 
@@ -650,9 +825,11 @@ func (n *Node) QueueTx(tx []byte) error {
 		}
 	}
 
-	if err := n.scheduler.QueueTx(tx); err != nil {
+	if err := n.scheduler.QueueCheckedTx(tx, checkResult); err != nil {
 		return err
 	}
+	n.persistTx(tx)
+	n.rememberReadWriteSet(txHash, checkResult)
 
 	if n.lastScheduledCache != nil {
 		if err := n.lastScheduledCache.Put(txHash, true); err != nil {
@@ -673,17 +850,81 @@ func (n *Node) QueueTx(tx []byte) error {
 
 // removeTxBatch removes a batch from scheduling queue.
 func (n *Node) removeTxBatch(batch [][]byte) error {
-	// XXX: remove batch can only happen after a batch was already scheduled, meaning
-	// the scheduler already exists and there is no need for the scheduler mutex.
+	n.schedulerMutex.RLock()
+	defer n.schedulerMutex.RUnlock()
+
 	if err := n.scheduler.RemoveTxBatch(batch); err != nil {
 		return err
 	}
+	if n.txStore != nil {
+		if err := n.txStore.Remove(batch); err != nil {
+			n.logger.Warn("failed to remove batch from persistent transaction pool",
+				"err", err,
+			)
+		}
+	}
 
 	incomingQueueSize.With(n.getMetricLabels()).Set(float64(n.scheduler.UnscheduledSize()))
 
 	return nil
 }
 
+// updateScheduler updates the scheduling parameters, recreating the scheduler in case the
+// scheduling algorithm has changed. Queued transactions are moved to the new scheduler.
+func (n *Node) updateScheduler(params registry.TxnSchedulerParameters) error {
+	n.schedulerMutex.Lock()
+	defer n.schedulerMutex.Unlock()
+
+	if params.Algorithm == n.scheduler.Name() {
+		if err := n.scheduler.UpdateParameters(params); err != nil {
+			return fmt.Errorf("error updating scheduler parameters: %w", err)
+		}
+		return nil
+	}
+
+	newScheduler, err := scheduling.New(n.scheduleMaxTxPoolSize, params)
+	if err != nil {
+		return fmt.Errorf("failed to create new transaction scheduler algorithm: %w", err)
+	}
+	txs := n.scheduler.GetTransactions()
+	if err = newScheduler.AppendTxBatch(txs); err != nil {
+		n.logger.Warn("failed to move some queued transactions to the new scheduler",
+			"err", err,
+		)
+	}
+
+	// Forget transactions that did not fit into the new scheduler so that they can be
+	// resubmitted.
+	var dropped [][]byte
+	for _, tx := range txs {
+		txHash := hash.NewFromBytes(tx)
+		if newScheduler.IsQueued(txHash) {
+			continue
+		}
+		dropped = append(dropped, tx)
+		if n.lastScheduledCache != nil {
+			n.lastScheduledCache.Remove(txHash)
+		}
+	}
+	if n.txStore != nil && len(dropped) > 0 {
+		if err = n.txStore.Remove(dropped); err != nil {
+			n.logger.Warn("failed to remove dropped transactions from persistent transaction pool",
+				"err", err,
+			)
+		}
+	}
+
+	n.logger.Info("transaction scheduling algorithm changed, moved queued transactions",
+		"algorithm", params.Algorithm,
+		"moved", newScheduler.UnscheduledSize(),
+		"dropped", len(dropped),
+	)
+
+	n.scheduler = newScheduler
+	incomingQueueSize.With(n.getMetricLabels()).Set(float64(newScheduler.UnscheduledSize()))
+	return nil
+}
+
 func (n *Node) proposeTimeoutLocked() error {
 	// Do not propose a timeout if we are already proposing it.
 	// The flag will get cleared on the next round or if the propose timeout
@@ -754,26 +995,53 @@ func (n *Node) proposeTimeoutLocked() error {
 	return nil
 }
 
+// isProposerLocked checks if the current node may propose a batch in the given round. Backup
+// proposers may only propose once their timeout has elapsed.
+// Guarded by n.commonNode.CrossNode.
+func (n *Node) isProposerLocked(epoch *committee.EpochSnapshot, round uint64) bool {
+	priority, ok := epoch.GetProposerPriority(round)
+	switch {
+	case !ok:
+		return false
+	case priority == 0:
+		return true
+	default:
+		timeout := epoch.GetRuntime().TxnScheduler.BackupProposerTimeout
+		return n.commonNode.Height >= n.commonNode.CurrentBlockHeight+int64(priority)*timeout
+	}
+}
+
 func (n *Node) handleScheduleBatch(force bool) {
-	epoch, lastHeader, err := func() (*committee.EpochSnapshot, *block.Header, error) {
+	var preBatch transaction.RawBatch
+	isProposer, lastHeader, err := func() (bool, *block.Header, error) {
 		n.commonNode.CrossNode.Lock()
 		defer n.commonNode.CrossNode.Unlock()
 
 		// If we are not waiting for a batch, don't do anything.
-		if _, ok := n.state.(StateWaitingForBatch); !ok {
-			return nil, nil, errIncorrectState
+		switch state := n.state.(type) {
+		case StateWaitingForBatch:
+		case StateWaitingForFinalize:
+			// Try to pre-execute the next batch while waiting for the round to be finalized.
+			n.maybePreExecuteLocked(state)
+			return false, nil, errIncorrectState
+		default:
+			return false, nil, errIncorrectState
 		}
 		if n.commonNode.CurrentBlock == nil {
-			return nil, nil, errNoBlocks
+			return false, nil, errNoBlocks
 		}
 		header := n.commonNode.CurrentBlock.Header
 		epoch := n.commonNode.Group.GetEpochSnapshot()
 
 		// If we are not an executor worker in this epoch, we don't need to do anything.
 		if !epoch.IsExecutorWorker() {
-			return nil, nil, errNotTxnScheduler
+			return false, nil, errNotTxnScheduler
+		}
+		isProposer := n.isProposerLocked(epoch, header.Round)
+		if isProposer {
+			preBatch = n.preExecutedBatchLocked(header.Round + 1)
 		}
-		return epoch, &header, nil
+		return isProposer, &header, nil
 	}()
 	if err != nil {
 		n.logger.Debug("not scheduling a batch",
@@ -782,14 +1050,19 @@ func (n *Node) handleScheduleBatch(force bool) {
 		return
 	}
 
-	// Ask the scheduler to get us a scheduled batch.
-	batch := n.scheduler.GetBatch(force)
+	// Ask the scheduler to get us a scheduled batch unless we have already pre-executed one.
+	batch := preBatch
+	if batch == nil {
+		n.schedulerMutex.RLock()
+		batch = n.scheduler.GetBatch(force)
+		n.schedulerMutex.RUnlock()
+	}
 	if len(batch) == 0 {
 		return
 	}
 
 	// If we are an executor and not a scheduler try proposing a timeout.
-	if !epoch.IsTransactionScheduler(lastHeader.Round) {
+	if !isProposer {
 		n.logger.Debug("proposing a timeout",
 			"round", lastHeader.Round,
 		)
@@ -826,7 +1099,9 @@ func (n *Node) handleScheduleBatch(force bool) {
 	)
 
 	// Scheduler node opens a new parent span for batch processing.
-	batchSpan := opentracing.StartSpan("ScheduleBatch(batch)")
+	batchSpan := opentracing.StartSpan("ScheduleBatch(batch)",
+		tracing.RoundTags(lastHeader.Namespace, lastHeader.Round+1),
+	)
 	defer batchSpan.Finish()
 	batchSpanCtx := batchSpan.Context()
 
@@ -838,19 +1113,7 @@ func (n *Node) handleScheduleBatch(force bool) {
 	}
 	emptyRoot.Hash.Empty()
 
-	ioTree := transaction.NewTree(nil, emptyRoot)
-	defer ioTree.Close()
-
-	for idx, tx := range batch {
-		if err = ioTree.AddTransaction(n.ctx, transaction.Transaction{Input: tx, BatchOrder: uint32(idx)}, nil); err != nil {
-			n.logger.Error("failed to create I/O tree",
-				"err", err,
-			)
-			return
-		}
-	}
-
-	ioWriteLog, ioRoot, err := ioTree.Commit(n.ctx)
+	ioWriteLog, ioRoot, err := commitInputs(n.ctx, emptyRoot, batch)
 	if err != nil {
 		n.logger.Error("failed to create I/O tree",
 			"err", err,
@@ -860,6 +1123,7 @@ func (n *Node) handleScheduleBatch(force bool) {
 
 	// Commit I/O tree to storage and obtain receipts.
 	spanInsert, ctx := tracing.StartSpanWithContext(n.ctx, "Apply(ioWriteLog)",
+		tracing.RoundTags(lastHeader.Namespace, lastHeader.Round+1),
 		opentracing.ChildOf(batchSpanCtx),
 	)
 
@@ -884,6 +1148,7 @@ func (n *Node) handleScheduleBatch(force bool) {
 	spanPublish := opentracing.StartSpan("PublishScheduledBatch(batchHash, header)",
 		opentracing.Tag{Key: "ioRoot", Value: ioRoot},
 		opentracing.Tag{Key: "header", Value: lastHeader},
+		tracing.RoundTags(lastHeader.Namespace, lastHeader.Round+1),
 		opentracing.ChildOf(batchSpanCtx),
 	)
 	ioReceiptSignatures := []signature.Signature{}
@@ -1010,10 +1275,18 @@ func (n *Node) startProcessingBatchLocked(batch *unresolvedBatch) {
 	// goroutine so that the committee node can continue processing blocks.
 	blk := n.commonNode.CurrentBlock
 	consensusBlk := n.commonNode.CurrentConsensusBlock
+	consensusStateRoot := n.commonNode.CurrentConsensusStateRoot
 	height := n.commonNode.CurrentBlockHeight
+	preExecuted, kept := n.takePreExecutionLocked()
 	go func() {
 		defer close(done)
 
+		// Use the pre-executed results in case the batch has already been executed.
+		if processed := n.usePreExecuted(ctx, preExecuted, kept, blk, consensusBlk, consensusStateRoot, batch.ioRoot.Hash); processed != nil {
+			done <- processed
+			return
+		}
+
 		// Fetch message results emitted during the last normal round.
 		state, err := n.commonNode.Consensus.RootHash().GetRuntimeState(ctx, blk.Header.Namespace, height)
 		if err != nil {
@@ -1034,6 +1307,24 @@ func (n *Node) startProcessingBatchLocked(batch *unresolvedBatch) {
 			return
 		}
 
+		// Fetch incoming messages queued for the runtime.
+		var inMsgs []*message.IncomingMessage
+		if maxInMsgs := state.Runtime.Executor.MaxInMessages; maxInMsgs > 0 {
+			inMsgs, err = n.commonNode.Consensus.RootHash().GetIncomingMessageQueue(ctx, &roothash.InMessageQueueRequest{
+				RuntimeID: blk.Header.Namespace,
+				Height:    height,
+				Limit:     maxInMsgs,
+			})
+			if err != nil {
+				n.logger.Error("failed to query incoming messages",
+					"err", err,
+					"height", height,
+					"round", blk.Header.Round,
+				)
+				return
+			}
+		}
+
 		// Resolve the batch and dispatch it to the runtime.
 		readStartTime := time.Now()
 		resolvedBatch, err := batch.resolve(ctx, n.commonNode.Group.Storage())
@@ -1046,11 +1337,13 @@ func (n *Node) startProcessingBatchLocked(batch *unresolvedBatch) {
 		}
 		rq := &protocol.Body{
 			RuntimeExecuteTxBatchRequest: &protocol.RuntimeExecuteTxBatchRequest{
-				ConsensusBlock: *consensusBlk,
-				MessageResults: msgResults,
-				IORoot:         batch.ioRoot.Hash,
-				Inputs:         resolvedBatch,
-				Block:          *blk,
+				ConsensusBlock:   *consensusBlk,
+				ConsensusState:   protocol.ConsensusState{ConsensusStateRoot: consensusStateRoot},
+				MessageResults:   msgResults,
+				IncomingMessages: inMsgs,
+				IORoot:           batch.ioRoot.Hash,
+				Inputs:           resolvedBatch,
+				Block:            *blk,
 			},
 		}
 		batchReadTime.With(n.getMetricLabels()).Observe(time.Since(readStartTime).Seconds())
@@ -1058,6 +1351,7 @@ func (n *Node) startProcessingBatchLocked(batch *unresolvedBatch) {
 
 		span := opentracing.StartSpan("CallBatch(rq)",
 			opentracing.Tag{Key: "rq", Value: rq},
+			tracing.RoundTags(blk.Header.Namespace, blk.Header.Round+1),
 			opentracing.ChildOf(batch.spanCtx),
 		)
 		ctx = opentracing.ContextWithSpan(ctx, span)
@@ -1068,6 +1362,16 @@ func (n *Node) startProcessingBatchLocked(batch *unresolvedBatch) {
 			batchRuntimeProcessingTime.With(n.getMetricLabels()).Observe(time.Since(rtStartTime).Seconds())
 		}()
 
+		// Attempt to execute the batch in parallel sub-batches first.
+		if computed := n.maybeExecuteParallel(ctx, rt, rq.RuntimeExecuteTxBatchRequest); computed != nil {
+			done <- &processedBatch{
+				computed: computed,
+				raw:      resolvedBatch,
+				request:  rq.RuntimeExecuteTxBatchRequest,
+			}
+			return
+		}
+
 		rsp, err := rt.Call(ctx, rq)
 		switch {
 		case err == nil:
@@ -1101,6 +1405,7 @@ func (n *Node) startProcessingBatchLocked(batch *unresolvedBatch) {
 		done <- &processedBatch{
 			computed: &rsp.RuntimeExecuteTxBatchResponse.Batch,
 			raw:      resolvedBatch,
+			request:  rq.RuntimeExecuteTxBatchRequest,
 		}
 	}()
 }
@@ -1127,6 +1432,7 @@ func (n *Node) abortBatchLocked(reason error) {
 	// finalized.
 	n.transitionLocked(StateWaitingForFinalize{
 		batchStartTime: state.batchStartTime,
+		span:           n.startFinalizeSpan(state.batch),
 	})
 }
 
@@ -1153,8 +1459,8 @@ func (n *Node) proposeBatchLocked(processedBatch *processedBatch) {
 		InputRoot:        state.batch.ioRoot.Hash,
 		InputStorageSigs: state.batch.storageSignatures,
 	}
-	// If we are the transaction scheduler also include all the emitted messages.
-	if epoch.IsTransactionScheduler(n.commonNode.CurrentBlock.Header.Round) {
+	// If we proposed the batch also include all the emitted messages.
+	if state.batch.txnSchedSignature.PublicKey.Equal(n.commonNode.Identity.NodeSigner.Public()) {
 		proposedResults.Messages = batch.Messages
 	}
 
@@ -1162,6 +1468,7 @@ func (n *Node) proposeBatchLocked(processedBatch *processedBatch) {
 	start := time.Now()
 	storageErr := func() error {
 		span, ctx := tracing.StartSpanWithContext(n.ctx, "Apply(io, state)",
+			tracing.RoundTags(n.commonNode.Runtime.ID(), state.batch.ioRoot.Version),
 			opentracing.ChildOf(state.batch.spanCtx),
 		)
 		defer span.Finish()
@@ -1240,7 +1547,9 @@ func (n *Node) proposeBatchLocked(processedBatch *processedBatch) {
 		proposedResults.SetFailure(commitment.FailureStorageUnavailable)
 	}
 
-	if err := n.signAndSubmitCommitment(proposedResults); err != nil {
+	n.queueForensicsLocked(processedBatch, proposedResults)
+
+	if err := n.signAndSubmitCommitment(state.batch.spanCtx, proposedResults); err != nil {
 		n.logger.Error("failed to sign and submit the commitment",
 			"commit", proposedResults,
 			"err", err,
@@ -1255,7 +1564,14 @@ func (n *Node) proposeBatchLocked(processedBatch *processedBatch) {
 			batchStartTime: state.batchStartTime,
 			raw:            processedBatch.raw,
 			proposedIORoot: *proposedResults.Header.IORoot,
+			proposedHeader: &proposedResults.Header,
+			span:           n.startFinalizeSpan(state.batch),
 		})
+
+		if n.preExecutionEnabled {
+			// Attempt to pre-execute the next batch.
+			n.scheduleCh.In() <- struct{}{}
+		}
 	default:
 		n.abortBatchLocked(storageErr)
 	}
@@ -1263,7 +1579,16 @@ func (n *Node) proposeBatchLocked(processedBatch *processedBatch) {
 	crash.Here(crashPointBatchProposeAfter)
 }
 
-func (n *Node) signAndSubmitCommitment(body *commitment.ComputeBody) error {
+// startFinalizeSpan starts a span tracking the time from commitment submission until the round
+// in which the given batch has been proposed is finalized.
+func (n *Node) startFinalizeSpan(batch *unresolvedBatch) opentracing.Span {
+	return opentracing.StartSpan("WaitForFinalize(round)",
+		tracing.RoundTags(n.commonNode.Runtime.ID(), batch.ioRoot.Version),
+		opentracing.ChildOf(batch.spanCtx),
+	)
+}
+
+func (n *Node) signAndSubmitCommitment(batchSpanCtx opentracing.SpanContext, body *commitment.ComputeBody) error {
 	commit, err := commitment.SignExecutorCommitment(n.commonNode.Identity.NodeSigner, body)
 	if err != nil {
 		n.logger.Error("failed to sign commitment",
@@ -1274,12 +1599,21 @@ func (n *Node) signAndSubmitCommitment(body *commitment.ComputeBody) error {
 	}
 
 	tx := roothash.NewExecutorCommitTx(0, nil, n.commonNode.Runtime.ID(), []commitment.ExecutorCommitment{*commit})
+	roundCtx := n.roundCtx
 	go func() {
-		commitErr := consensus.SignAndSubmitTx(n.roundCtx, n.commonNode.Consensus, n.commonNode.Identity.NodeSigner, tx)
+		// The span covers both the submission and the inclusion of the commitment in a block.
+		span := opentracing.StartSpan("SubmitExecutorCommit(commit)",
+			tracing.RoundTags(n.commonNode.Runtime.ID(), body.Header.Round),
+			opentracing.ChildOf(batchSpanCtx),
+		)
+		defer span.Finish()
+
+		commitErr := consensus.SignAndSubmitTx(roundCtx, n.commonNode.Consensus, n.commonNode.Identity.NodeSigner, tx)
 		switch commitErr {
 		case nil:
 			n.logger.Info("executor commit finalized")
 		default:
+			opentracingExt.Error.Set(span, true)
 			n.logger.Error("failed to submit executor commit",
 				"commit", body,
 				"err", commitErr,
@@ -1379,25 +1713,54 @@ func (n *Node) handleRuntimeHostEvent(ev *host.Event) {
 	switch {
 	case ev.Started != nil:
 		// We are now able to service requests for this runtime.
-		n.runtimeVersion = ev.Started.Version
+		runtimeVersion := ev.Started.Version
+		if n.runtimeVersion != nil && *n.runtimeVersion != runtimeVersion {
+			// The runtime has been upgraded, replace any additional runtime instances as they
+			// are still running the previous version.
+			n.logger.Info("runtime version changed, re-provisioning parallel runtime instances",
+				"version", runtimeVersion,
+			)
+			n.reprovisionParallelRuntimes()
+		}
+		n.runtimeVersion = &runtimeVersion
+		n.commonNode.CrossNode.Lock()
+		n.capabilityTEE = ev.Started.CapabilityTEE
+		n.commonNode.CrossNode.Unlock()
 
 		n.roleProvider.SetAvailable(func(nd *node.Node) error {
 			rt := nd.AddOrUpdateRuntime(n.commonNode.Runtime.ID())
-			rt.Version = n.runtimeVersion
+			rt.Version = runtimeVersion
 			rt.Capabilities.TEE = ev.Started.CapabilityTEE
 			return nil
 		})
 	case ev.Updated != nil:
 		// Update runtime capabilities.
+		n.commonNode.CrossNode.Lock()
+		n.capabilityTEE = ev.Updated.CapabilityTEE
+		n.commonNode.CrossNode.Unlock()
+
+		if n.runtimeVersion == nil {
+			break
+		}
+		runtimeVersion := *n.runtimeVersion
+
 		n.roleProvider.SetAvailable(func(nd *node.Node) error {
 			rt := nd.AddOrUpdateRuntime(n.commonNode.Runtime.ID())
-			rt.Version = n.runtimeVersion
+			rt.Version = runtimeVersion
 			rt.Capabilities.TEE = ev.Updated.CapabilityTEE
 			return nil
 		})
 	case ev.FailedToStart != nil, ev.Stopped != nil:
 		// Runtime failed to start or was stopped -- we can no longer service requests.
 		n.roleProvider.SetUnavailable()
+	case ev.CrashLoop != nil:
+		// Runtime is crash looping -- deregister from the runtime so that other roles can still
+		// be registered while the runtime is not being restarted.
+		n.logger.Error("runtime is crash looping, deregistering from runtime",
+			"crashes", ev.CrashLoop.Crashes,
+			"last_exit_status", ev.CrashLoop.LastExitStatus,
+		)
+		n.roleProvider.SetDisabled()
 	default:
 		// Unknown event.
 		n.logger.Warn("unknown worker event",
@@ -1439,7 +1802,7 @@ func (n *Node) handleProcessedBatch(batch *processedBatch, processingCh chan *pr
 	}
 	commit.SetFailure(commitment.FailureUnknown)
 
-	if err := n.signAndSubmitCommitment(commit); err != nil {
+	if err := n.signAndSubmitCommitment(state.batch.spanCtx, commit); err != nil {
 		n.logger.Error("failed to sign and submit the commitment",
 			"commit", commit,
 			"err", err,
@@ -1463,6 +1826,15 @@ func (n *Node) worker() {
 
 	n.logger.Info("starting committee node")
 
+	if n.forensicsStore != nil {
+		forensicsQuitCh := make(chan struct{})
+		go n.forensicsWorker(forensicsQuitCh)
+		defer func() {
+			(n.cancelCtx)()
+			<-forensicsQuitCh
+		}()
+	}
+
 	// Provision the hosted runtime.
 	hrt, hrtNotifier, err := n.ProvisionHostedRuntime(n.ctx)
 	if err != nil {
@@ -1497,6 +1869,25 @@ func (n *Node) worker() {
 	}
 	defer hrtNotifier.Stop()
 
+	// Provision additional runtime instances used for parallel batch execution.
+	parallelRuntimes, err := n.provisionParallelRuntimes()
+	if err != nil {
+		n.logger.Error("failed to provision parallel runtime instances",
+			"err", err,
+		)
+		return
+	}
+	n.commonNode.CrossNode.Lock()
+	n.parallelRuntimes = parallelRuntimes
+	n.commonNode.CrossNode.Unlock()
+	defer func() {
+		n.commonNode.CrossNode.Lock()
+		rts := n.parallelRuntimes
+		n.parallelRuntimes = nil
+		n.commonNode.CrossNode.Unlock()
+		stopRuntimeInstances(rts)
+	}()
+
 	// Initialize transaction scheduling algorithm.
 	runtime, err := n.commonNode.Runtime.RegistryDescriptor(n.ctx)
 	if err != nil {
@@ -1560,14 +1951,8 @@ func (n *Node) worker() {
 			// Batch processing has finished.
 			n.handleProcessedBatch(batch, processingDoneCh)
 		case runtime := <-rtCh:
-			// XXX: Once there is more than one scheduling algorithm available
-			// this might need to recreate the scheduler and reinsert
-			// the transactions.
-			// At that point also update the schedulerMutex usage across the
-			// code, as it will be no longer be true that the scheduler
-			// variable never gets updated.
-			if err = n.scheduler.UpdateParameters(runtime.TxnScheduler); err != nil {
-				n.logger.Error("error updating scheduler parameters",
+			if err = n.updateScheduler(runtime.TxnScheduler); err != nil {
+				n.logger.Error("failed to update transaction scheduler",
 					"err", err,
 				)
 				return
@@ -1592,6 +1977,12 @@ func NewNode(
 	scheduleCheckTxEnabled bool,
 	scheduleMaxTxPoolSize uint64,
 	lastScheduledCacheSize uint64,
+	parallelRuntimeInstances uint64,
+	txStore persistent.Store,
+	preExecutionEnabled bool,
+	forensicsStore forensics.Store,
+	forensicsPolicy *policy.DynamicRuntimePolicyChecker,
+	forensicsClients []signature.PublicKey,
 ) (*Node, error) {
 	metricsOnce.Do(func() {
 		prometheus.MustRegister(nodeCollectors...)
@@ -1602,6 +1993,7 @@ func NewNode(
 	if err != nil {
 		return nil, err
 	}
+	rhn.EnableRoundBoundaryVersionSwitch()
 
 	var cache *lru.Cache
 	if lastScheduledCacheSize > 0 {
@@ -1611,26 +2003,46 @@ func NewNode(
 		}
 	}
 
+	var rwSetCache *lru.Cache
+	if parallelRuntimeInstances > 1 {
+		rwSetCache, err = lru.New(lru.Capacity(rwSetCacheSize, false))
+		if err != nil {
+			return nil, fmt.Errorf("error creating cache: %w", err)
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	n := &Node{
-		RuntimeHostNode:        rhn,
-		commonNode:             commonNode,
-		commonCfg:              commonCfg,
-		roleProvider:           roleProvider,
-		scheduleCheckTxEnabled: scheduleCheckTxEnabled,
-		scheduleMaxTxPoolSize:  scheduleMaxTxPoolSize,
-		lastScheduledCache:     cache,
-		scheduleCh:             channels.NewRingChannel(1),
-		ctx:                    ctx,
-		cancelCtx:              cancel,
-		stopCh:                 make(chan struct{}),
-		quitCh:                 make(chan struct{}),
-		initCh:                 make(chan struct{}),
-		state:                  StateNotReady{},
-		stateTransitions:       pubsub.NewBroker(false),
-		reselect:               make(chan struct{}, 1),
-		logger:                 logging.GetLogger("worker/executor/committee").With("runtime_id", commonNode.Runtime.ID()),
+		RuntimeHostNode:          rhn,
+		commonNode:               commonNode,
+		commonCfg:                commonCfg,
+		roleProvider:             roleProvider,
+		scheduleCheckTxEnabled:   scheduleCheckTxEnabled,
+		scheduleMaxTxPoolSize:    scheduleMaxTxPoolSize,
+		lastScheduledCache:       cache,
+		parallelRuntimeInstances: parallelRuntimeInstances,
+		rwSetCache:               rwSetCache,
+		txStore:                  txStore,
+		preExecutionEnabled:      preExecutionEnabled,
+		preExecutions:            pubsub.NewBroker(false),
+		forensicsStore:           forensicsStore,
+		forensicsPolicy:          forensicsPolicy,
+		forensicsClients:         forensicsClients,
+		scheduleCh:               channels.NewRingChannel(1),
+		ctx:                      ctx,
+		cancelCtx:                cancel,
+		stopCh:                   make(chan struct{}),
+		quitCh:                   make(chan struct{}),
+		initCh:                   make(chan struct{}),
+		state:                    StateNotReady{},
+		stateEnteredAt:           time.Now(),
+		stateTransitions:         pubsub.NewBroker(false),
+		reselect:                 make(chan struct{}, 1),
+		logger:                   logging.GetLogger("worker/executor/committee").With("runtime_id", commonNode.Runtime.ID()),
+	}
+	if forensicsStore != nil {
+		n.forensicsCh = make(chan *api.ForensicsBundle, forensicsQueueSize)
 	}
 
 	return n, nil
@@ -0,0 +1,85 @@
+package committee
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eapache/channels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling"
+	commonCommittee "github.com/oasisprotocol/oasis-core/go/worker/common/committee"
+)
+
+type fakeRegistryRuntime struct {
+	runtimeRegistry.Runtime
+}
+
+func (r *fakeRegistryRuntime) ID() common.Namespace {
+	return common.Namespace{}
+}
+
+func TestUpdateScheduler(t *testing.T) {
+	require := require.New(t)
+
+	params := registry.TxnSchedulerParameters{
+		Algorithm:         registry.TxnSchedulerSimple,
+		BatchFlushTimeout: time.Second,
+		MaxBatchSize:      10,
+		MaxBatchSizeBytes: 1024,
+	}
+	scheduler, err := scheduling.New(100, params)
+	require.NoError(err, "scheduling.New")
+
+	n := &Node{
+		commonNode:            &commonCommittee.Node{Runtime: &fakeRegistryRuntime{}},
+		logger:                logging.GetLogger("worker/executor/committee/test"),
+		scheduleCh:            channels.NewRingChannel(1),
+		scheduleMaxTxPoolSize: 100,
+		scheduler:             scheduler,
+	}
+
+	var txs [][]byte
+	for i := 0; i < 10; i++ {
+		tx := []byte(fmt.Sprintf("tx %d", i))
+		txs = append(txs, tx)
+		require.NoError(n.QueueTx(tx), "QueueTx")
+	}
+
+	// Updating the parameters should keep the scheduler.
+	params.MaxBatchSize = 5
+	require.NoError(n.updateScheduler(params), "updateScheduler")
+	require.Equal(scheduler, n.scheduler, "scheduler should be kept")
+	require.Len(n.scheduler.GetBatch(true), 5, "parameters should be updated")
+
+	// Changing the algorithm should move the queued transactions while transactions are being
+	// queued concurrently.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 10; i < 20; i++ {
+			_ = n.QueueTx([]byte(fmt.Sprintf("tx %d", i)))
+		}
+	}()
+	params.Algorithm = registry.TxnSchedulerPriority
+	require.NoError(n.updateScheduler(params), "updateScheduler")
+	wg.Wait()
+	for i := 10; i < 20; i++ {
+		txs = append(txs, []byte(fmt.Sprintf("tx %d", i)))
+	}
+
+	require.NotEqual(scheduler, n.scheduler, "scheduler should be recreated")
+	require.Equal(registry.TxnSchedulerPriority, n.scheduler.Name())
+	require.EqualValues(len(txs), n.scheduler.UnscheduledSize(), "queued transactions should be moved")
+	for _, tx := range txs {
+		require.True(n.scheduler.IsQueued(hash.NewFromBytes(tx)), "queued transactions should be moved")
+	}
+}
@@ -0,0 +1,398 @@
+package committee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	"github.com/oasisprotocol/oasis-core/go/common/sgx/ias"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/parallel"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+)
+
+// rwSetCacheSize is the number of declared read/write sets of queued transactions that are
+// remembered for partitioning batches.
+const rwSetCacheSize = 10_000
+
+var errParallelNotApplicable = errors.New("executor: parallel execution not applicable")
+
+// runtimeInstance is a runtime instance used for parallel batch execution.
+type runtimeInstance struct {
+	host.Runtime
+
+	notifier protocol.Notifier
+	sub      pubsub.ClosableSubscription
+
+	// Guarded by .commonNode.CrossNode.
+	capabilityTEE *node.CapabilityTEE
+}
+
+// stopRuntimeInstances stops the given additional runtime instances.
+func stopRuntimeInstances(rts []*runtimeInstance) {
+	for _, ri := range rts {
+		ri.notifier.Stop()
+		ri.Stop()
+		ri.sub.Close()
+	}
+}
+
+// provisionParallelRuntimes provisions and starts the additional runtime instances used for
+// parallel batch execution. The instances run the currently active version of the runtime.
+func (n *Node) provisionParallelRuntimes() ([]*runtimeInstance, error) {
+	var rts []*runtimeInstance
+	for i := uint64(1); i < n.parallelRuntimeInstances; i++ {
+		ri, err := n.provisionParallelRuntime()
+		if err != nil {
+			stopRuntimeInstances(rts)
+			return nil, err
+		}
+		rts = append(rts, ri)
+	}
+	return rts, nil
+}
+
+func (n *Node) provisionParallelRuntime() (*runtimeInstance, error) {
+	rt, notifier, err := n.ProvisionHostedRuntimeInstance(n.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision parallel runtime instance: %w", err)
+	}
+	ch, sub, err := rt.WatchEvents(n.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to parallel runtime instance events: %w", err)
+	}
+
+	ri := &runtimeInstance{Runtime: rt, notifier: notifier, sub: sub}
+	go n.watchRuntimeInstance(ri, ch)
+
+	if err = rt.Start(); err != nil {
+		sub.Close()
+		return nil, fmt.Errorf("failed to start parallel runtime instance: %w", err)
+	}
+	if err = notifier.Start(); err != nil {
+		rt.Stop()
+		sub.Close()
+		return nil, fmt.Errorf("failed to start parallel runtime instance notifier: %w", err)
+	}
+	return ri, nil
+}
+
+// reprovisionParallelRuntimes replaces the additional runtime instances used for parallel batch
+// execution with instances running the currently active version of the runtime.
+func (n *Node) reprovisionParallelRuntimes() {
+	if n.parallelRuntimeInstances <= 1 {
+		return
+	}
+
+	// Stop using the previous instances first so that no batches are executed by instances
+	// running the previous version.
+	n.commonNode.CrossNode.Lock()
+	prev := n.parallelRuntimes
+	n.parallelRuntimes = nil
+	n.commonNode.CrossNode.Unlock()
+	stopRuntimeInstances(prev)
+
+	rts, err := n.provisionParallelRuntimes()
+	if err != nil {
+		n.logger.Error("failed to re-provision parallel runtime instances, disabling parallel batch execution",
+			"err", err,
+		)
+		return
+	}
+
+	n.commonNode.CrossNode.Lock()
+	n.parallelRuntimes = rts
+	n.commonNode.CrossNode.Unlock()
+}
+
+// watchRuntimeInstance keeps track of the TEE capability of an additional runtime instance.
+func (n *Node) watchRuntimeInstance(ri *runtimeInstance, ch <-chan *host.Event) {
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			var capabilityTEE *node.CapabilityTEE
+			switch {
+			case ev.Started != nil:
+				capabilityTEE = ev.Started.CapabilityTEE
+			case ev.Updated != nil:
+				capabilityTEE = ev.Updated.CapabilityTEE
+			default:
+				continue
+			}
+
+			n.commonNode.CrossNode.Lock()
+			ri.capabilityTEE = capabilityTEE
+			n.commonNode.CrossNode.Unlock()
+		}
+	}
+}
+
+// rememberReadWriteSet remembers the read/write set declared for a queued transaction so that
+// the transaction does not need to be checked again when partitioning a batch.
+func (n *Node) rememberReadWriteSet(txHash hash.Hash, checkResult *transaction.TxnCheckResult) {
+	if n.rwSetCache == nil || checkResult == nil || !parallel.IsDeclared(&checkResult.PredictedReadWriteSet) {
+		return
+	}
+	if err := n.rwSetCache.Put(txHash, &checkResult.PredictedReadWriteSet); err != nil {
+		n.logger.Error("cache put error",
+			"err", err,
+		)
+	}
+}
+
+// maybeExecuteParallel attempts to execute the given batch in non-conflicting sub-batches on
+// multiple runtime instances.
+//
+// In case parallel execution is not applicable or fails (e.g., because the runtime rejected the
+// sub-batches as conflicting), nil is returned and the batch should be executed sequentially.
+func (n *Node) maybeExecuteParallel(
+	ctx context.Context,
+	rt host.Runtime,
+	rq *protocol.RuntimeExecuteTxBatchRequest,
+) *protocol.ComputedBatch {
+	n.commonNode.CrossNode.Lock()
+	if len(n.parallelRuntimes) == 0 {
+		n.commonNode.CrossNode.Unlock()
+		return nil
+	}
+	rts := append([]*runtimeInstance{{Runtime: rt, capabilityTEE: n.capabilityTEE}}, n.parallelRuntimes...)
+	// Capabilities may be updated concurrently, so use a snapshot.
+	for i, ri := range rts {
+		rts[i] = &runtimeInstance{Runtime: ri.Runtime, capabilityTEE: ri.capabilityTEE}
+	}
+	n.commonNode.CrossNode.Unlock()
+
+	rtDesc, err := n.commonNode.Runtime.RegistryDescriptor(ctx)
+	if err != nil {
+		n.logger.Error("failed to get runtime descriptor",
+			"err", err,
+		)
+		return nil
+	}
+
+	computed, err := n.executeParallel(ctx, rtDesc, rts, rq)
+	switch {
+	case err == nil:
+		parallelBatchCount.With(n.getMetricLabels()).Inc()
+		return computed
+	case errors.Is(err, errParallelNotApplicable):
+	default:
+		parallelFallbackCount.With(n.getMetricLabels()).Inc()
+		n.logger.Warn("parallel batch execution failed, re-executing batch sequentially",
+			"err", err,
+		)
+	}
+	return nil
+}
+
+func (n *Node) executeParallel(
+	ctx context.Context,
+	rtDesc *registry.Runtime,
+	rts []*runtimeInstance,
+	rq *protocol.RuntimeExecuteTxBatchRequest,
+) (*protocol.ComputedBatch, error) {
+	switch {
+	case rtDesc.TxnScheduler.Algorithm != registry.TxnSchedulerParallel:
+		return nil, errParallelNotApplicable
+	case len(rq.MessageResults) > 0:
+		// Message results must be processed exactly once.
+		return nil, errParallelNotApplicable
+	case len(rq.IncomingMessages) > 0:
+		// Incoming messages must be processed exactly once.
+		return nil, errParallelNotApplicable
+	}
+
+	rwSets, err := n.declaredReadWriteSets(ctx, rts[0], rq)
+	if err != nil {
+		return nil, err
+	}
+	// The runtime verifies that sub-batches do not conflict when merging them so the merged
+	// results do not depend on how the batch is partitioned.
+	subBatches := parallel.Partition(rwSets, len(rts))
+	if len(subBatches) < 2 {
+		return nil, errParallelNotApplicable
+	}
+
+	// Execute all sub-batches concurrently.
+	results := make([]*protocol.ExecutedSubBatch, len(subBatches))
+	errs := make([]error, len(subBatches))
+	var wg sync.WaitGroup
+	for i, indices := range subBatches {
+		wg.Add(1)
+		go func(i int, indices []int) {
+			defer wg.Done()
+			results[i], errs[i] = n.executeSubBatch(ctx, rts[i], rq, indices)
+		}(i, indices)
+	}
+	wg.Wait()
+
+	mergeRq := &protocol.RuntimeMergeTxBatchRequest{
+		IORoot: rq.IORoot,
+		Inputs: rq.Inputs,
+		Block:  rq.Block,
+	}
+	for i, indices := range subBatches {
+		if errs[i] != nil {
+			if errors.Is(errs[i], context.Canceled) {
+				// Abort the runtime instance, so it can process the next batch.
+				if err = rts[i].Abort(n.ctx, false); err != nil {
+					n.logger.Error("failed to abort the runtime",
+						"err", err,
+					)
+				}
+			}
+			return nil, fmt.Errorf("executor: failed to execute sub-batch %d: %w", i, errs[i])
+		}
+
+		sb := protocol.MergeSubBatch{
+			Batch: *results[i],
+		}
+		for _, idx := range indices {
+			sb.Indices = append(sb.Indices, uint32(idx))
+		}
+		if rtDesc.TEEHardware != node.TEEHardwareInvalid {
+			// Results of other instances are only accepted by the runtime in case they have been
+			// attested by the same enclave.
+			if sb.RAK, sb.AVR, err = instanceAttestation(rts[i]); err != nil {
+				return nil, fmt.Errorf("executor: sub-batch %d: %w", i, err)
+			}
+		}
+		mergeRq.SubBatches = append(mergeRq.SubBatches, sb)
+	}
+
+	// Merge the results on the primary instance which also verifies that the sub-batches do not
+	// conflict and produces the results as if the batch was executed sequentially.
+	rsp, err := rts[0].Call(ctx, &protocol.Body{RuntimeMergeTxBatchRequest: mergeRq})
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("executor: failed to merge sub-batches: %w", err)
+	case rsp.RuntimeMergeTxBatchResponse == nil:
+		return nil, fmt.Errorf("executor: malformed merge response from runtime")
+	}
+	return &rsp.RuntimeMergeTxBatchResponse.Batch, nil
+}
+
+// instanceAttestation returns the RAK of the given runtime instance together with its attestation.
+func instanceAttestation(ri *runtimeInstance) (*signature.PublicKey, *ias.AVRBundle, error) {
+	if ri.capabilityTEE == nil {
+		return nil, nil, fmt.Errorf("runtime instance has no TEE capability")
+	}
+	if ri.capabilityTEE.Hardware != node.TEEHardwareIntelSGX {
+		return nil, nil, fmt.Errorf("unsupported TEE hardware: %s", ri.capabilityTEE.Hardware)
+	}
+
+	var avr ias.AVRBundle
+	if err := cbor.Unmarshal(ri.capabilityTEE.Attestation, &avr); err != nil {
+		return nil, nil, fmt.Errorf("malformed attestation: %w", err)
+	}
+	rak := ri.capabilityTEE.RAK
+	return &rak, &avr, nil
+}
+
+// declaredReadWriteSets returns the read/write sets declared by the runtime for each transaction
+// in the batch. Transactions without a declared read/write set have a nil entry.
+//
+// Read/write sets remembered when the transactions were queued are used where available, so only
+// the remaining transactions need to be checked.
+func (n *Node) declaredReadWriteSets(
+	ctx context.Context,
+	rt host.Runtime,
+	rq *protocol.RuntimeExecuteTxBatchRequest,
+) ([]*transaction.ReadWriteSet, error) {
+	rwSets := make([]*transaction.ReadWriteSet, len(rq.Inputs))
+	var (
+		missing   []int
+		unchecked transaction.RawBatch
+	)
+	for i, tx := range rq.Inputs {
+		if n.rwSetCache != nil {
+			if rwSet, ok := n.rwSetCache.Get(hash.NewFromBytes(tx)); ok {
+				rwSets[i] = rwSet.(*transaction.ReadWriteSet)
+				continue
+			}
+		}
+		missing = append(missing, i)
+		unchecked = append(unchecked, tx)
+	}
+	if len(missing) == 0 {
+		return rwSets, nil
+	}
+
+	rsp, err := rt.Call(ctx, &protocol.Body{
+		RuntimeCheckTxBatchRequest: &protocol.RuntimeCheckTxBatchRequest{
+			ConsensusBlock: rq.ConsensusBlock,
+			ConsensusState: rq.ConsensusState,
+			Inputs:         unchecked,
+			Block:          rq.Block,
+		},
+	})
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("executor: failed to check batch: %w", err)
+	case rsp.RuntimeCheckTxBatchResponse == nil:
+		return nil, fmt.Errorf("executor: malformed check batch response")
+	case len(rsp.RuntimeCheckTxBatchResponse.Results) != len(unchecked):
+		return nil, fmt.Errorf("executor: check batch response has %d results, expected %d",
+			len(rsp.RuntimeCheckTxBatchResponse.Results),
+			len(unchecked),
+		)
+	}
+
+	for i, raw := range rsp.RuntimeCheckTxBatchResponse.Results {
+		var output transaction.TxnOutput
+		if err = cbor.Unmarshal(raw, &output); err != nil || output.Error != nil {
+			continue
+		}
+		var result transaction.TxnCheckResult
+		if err = cbor.Unmarshal(output.Success, &result); err != nil {
+			continue
+		}
+		if parallel.IsDeclared(&result.PredictedReadWriteSet) {
+			rwSets[missing[i]] = &result.PredictedReadWriteSet
+		}
+	}
+	return rwSets, nil
+}
+
+// executeSubBatch executes the transactions with the given indices as a separate sub-batch.
+func (n *Node) executeSubBatch(
+	ctx context.Context,
+	rt host.Runtime,
+	rq *protocol.RuntimeExecuteTxBatchRequest,
+	indices []int,
+) (*protocol.ExecutedSubBatch, error) {
+	inputs := make(transaction.RawBatch, 0, len(indices))
+	for _, idx := range indices {
+		inputs = append(inputs, rq.Inputs[idx])
+	}
+
+	rsp, err := rt.Call(ctx, &protocol.Body{
+		RuntimeExecuteTxSubBatchRequest: &protocol.RuntimeExecuteTxSubBatchRequest{
+			ConsensusBlock: rq.ConsensusBlock,
+			ConsensusState: rq.ConsensusState,
+			Inputs:         inputs,
+			Block:          rq.Block,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rsp.RuntimeExecuteTxSubBatchResponse == nil {
+		return nil, fmt.Errorf("malformed response from runtime")
+	}
+	return &rsp.RuntimeExecuteTxSubBatchResponse.Batch, nil
+}
@@ -0,0 +1,278 @@
+package committee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cache/lru"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	"github.com/oasisprotocol/oasis-core/go/common/sgx/ias"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+	commonCommittee "github.com/oasisprotocol/oasis-core/go/worker/common/committee"
+	"github.com/oasisprotocol/oasis-core/go/worker/registration"
+)
+
+// fakeRuntime is a runtime instance where each transaction reads and writes the key equal to the
+// transaction itself.
+type fakeRuntime struct {
+	host.Runtime
+
+	sync.Mutex
+	requests  []*protocol.Body
+	mergeErr  error
+	mergeRsp  protocol.ComputedBatch
+	declareRw bool
+	stopped   bool
+
+	finalizationIndependent bool
+}
+
+func (r *fakeRuntime) Stop() {
+	r.Lock()
+	defer r.Unlock()
+	r.stopped = true
+}
+
+func (r *fakeRuntime) Call(ctx context.Context, body *protocol.Body) (*protocol.Body, error) {
+	r.Lock()
+	r.requests = append(r.requests, body)
+	r.Unlock()
+
+	switch {
+	case body.RuntimeCheckTxBatchRequest != nil:
+		var results transaction.RawBatch
+		for _, tx := range body.RuntimeCheckTxBatchRequest.Inputs {
+			var result transaction.TxnCheckResult
+			if r.declareRw {
+				result.PredictedReadWriteSet = rwSetForTx(tx)
+			}
+			results = append(results, cbor.Marshal(transaction.TxnOutput{Success: cbor.Marshal(result)}))
+		}
+		return &protocol.Body{RuntimeCheckTxBatchResponse: &protocol.RuntimeCheckTxBatchResponse{
+			Results: results,
+		}}, nil
+	case body.RuntimeExecuteTxBatchRequest != nil:
+		rq := body.RuntimeExecuteTxBatchRequest
+		var computed protocol.ComputedBatch
+		computed.Header.Round = rq.Block.Header.Round + 1
+		computed.Header.PreviousHash = rq.Block.Header.EncodedHash()
+		computed.Header.IORoot = &rq.IORoot
+		return &protocol.Body{RuntimeExecuteTxBatchResponse: &protocol.RuntimeExecuteTxBatchResponse{
+			Batch:                   computed,
+			FinalizationIndependent: r.finalizationIndependent,
+		}}, nil
+	case body.RuntimeExecuteTxSubBatchRequest != nil:
+		return &protocol.Body{RuntimeExecuteTxSubBatchResponse: &protocol.RuntimeExecuteTxSubBatchResponse{
+			Batch: protocol.ExecutedSubBatch{
+				Header: protocol.SubBatchHeader{
+					InputsHash: hash.NewFrom(body.RuntimeExecuteTxSubBatchRequest.Inputs),
+				},
+			},
+		}}, nil
+	case body.RuntimeMergeTxBatchRequest != nil:
+		if r.mergeErr != nil {
+			return nil, r.mergeErr
+		}
+		return &protocol.Body{RuntimeMergeTxBatchResponse: &protocol.RuntimeMergeTxBatchResponse{
+			Batch: r.mergeRsp,
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported request: %s", body.Type())
+	}
+}
+
+func rwSetForTx(tx []byte) transaction.ReadWriteSet {
+	return transaction.ReadWriteSet{
+		Granularity: 1,
+		ReadSet:     transaction.CoarsenedSet{tx},
+		WriteSet:    transaction.CoarsenedSet{tx},
+	}
+}
+
+func newParallelTestNode(t *testing.T) *Node {
+	rwSetCache, err := lru.New(lru.Capacity(rwSetCacheSize, false))
+	require.NoError(t, err, "lru.New")
+
+	return &Node{
+		ctx:        context.Background(),
+		rwSetCache: rwSetCache,
+		logger:     logging.GetLogger("worker/executor/committee/test"),
+	}
+}
+
+func TestExecuteParallel(t *testing.T) {
+	require := require.New(t)
+
+	n := newParallelTestNode(t)
+	rtDesc := &registry.Runtime{
+		TxnScheduler: registry.TxnSchedulerParameters{Algorithm: registry.TxnSchedulerParallel},
+	}
+	primary := &fakeRuntime{declareRw: true}
+	primary.mergeRsp.Header = commitment.ComputeResultsHeader{Round: 1}
+	secondary := &fakeRuntime{declareRw: true}
+	rts := []*runtimeInstance{{Runtime: primary}, {Runtime: secondary}}
+
+	inputs := transaction.RawBatch{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+	rq := &protocol.RuntimeExecuteTxBatchRequest{Inputs: inputs}
+
+	// Read/write sets of checked transactions should be reused.
+	checkResult := transaction.TxnCheckResult{PredictedReadWriteSet: rwSetForTx(inputs[2])}
+	n.rememberReadWriteSet(hash.NewFromBytes(inputs[2]), &checkResult)
+
+	computed, err := n.executeParallel(context.Background(), rtDesc, rts, rq)
+	require.NoError(err, "executeParallel")
+	require.EqualValues(&primary.mergeRsp, computed, "merged batch should be returned")
+
+	// Only transactions without remembered read/write sets should be checked.
+	require.Len(primary.requests, 3, "primary instance should check, execute and merge")
+	require.EqualValues(transaction.RawBatch{inputs[0], inputs[1], inputs[3]}, primary.requests[0].RuntimeCheckTxBatchRequest.Inputs)
+
+	// Sub-batches should be executed on separate instances.
+	require.EqualValues(transaction.RawBatch{inputs[0], inputs[2]}, primary.requests[1].RuntimeExecuteTxSubBatchRequest.Inputs)
+	require.Len(secondary.requests, 1, "secondary instance should only execute")
+	require.EqualValues(transaction.RawBatch{inputs[1], inputs[3]}, secondary.requests[0].RuntimeExecuteTxSubBatchRequest.Inputs)
+
+	// Results should be merged by the primary instance.
+	mergeRq := primary.requests[2].RuntimeMergeTxBatchRequest
+	require.NotNil(mergeRq, "primary instance should merge")
+	require.EqualValues(inputs, mergeRq.Inputs)
+	require.Len(mergeRq.SubBatches, 2)
+	require.EqualValues([]uint32{0, 2}, mergeRq.SubBatches[0].Indices)
+	require.EqualValues([]uint32{1, 3}, mergeRq.SubBatches[1].Indices)
+	require.EqualValues(hash.NewFrom(transaction.RawBatch{inputs[1], inputs[3]}), mergeRq.SubBatches[1].Batch.Header.InputsHash)
+	require.Nil(mergeRq.SubBatches[1].RAK, "RAK should not be included without a TEE")
+
+	// Failure to merge should be reported so the batch is executed sequentially.
+	primary.mergeErr = fmt.Errorf("sub-batches 0 and 1 conflict")
+	_, err = n.executeParallel(context.Background(), rtDesc, rts, rq)
+	require.Error(err, "executeParallel should fail when merge fails")
+	require.False(errors.Is(err, errParallelNotApplicable))
+}
+
+func TestExecuteParallelTEE(t *testing.T) {
+	require := require.New(t)
+
+	n := newParallelTestNode(t)
+	rtDesc := &registry.Runtime{
+		TEEHardware:  node.TEEHardwareIntelSGX,
+		TxnScheduler: registry.TxnSchedulerParameters{Algorithm: registry.TxnSchedulerParallel},
+	}
+	avr := ias.AVRBundle{Body: []byte("body"), Signature: []byte("signature")}
+	var rak signature.PublicKey
+	rak[0] = 1
+	capabilityTEE := &node.CapabilityTEE{
+		Hardware:    node.TEEHardwareIntelSGX,
+		RAK:         rak,
+		Attestation: cbor.Marshal(avr),
+	}
+	primary := &fakeRuntime{declareRw: true}
+	secondary := &fakeRuntime{declareRw: true}
+	rts := []*runtimeInstance{{Runtime: primary, capabilityTEE: capabilityTEE}, {Runtime: secondary}}
+
+	rq := &protocol.RuntimeExecuteTxBatchRequest{
+		Inputs: transaction.RawBatch{[]byte("a"), []byte("b")},
+	}
+
+	// Sub-batches executed by instances without an attested RAK cannot be merged.
+	_, err := n.executeParallel(context.Background(), rtDesc, rts, rq)
+	require.Error(err, "executeParallel should fail without attestation")
+
+	rts[1].capabilityTEE = capabilityTEE
+	_, err = n.executeParallel(context.Background(), rtDesc, rts, rq)
+	require.NoError(err, "executeParallel")
+
+	mergeRq := primary.requests[len(primary.requests)-1].RuntimeMergeTxBatchRequest
+	require.NotNil(mergeRq, "primary instance should merge")
+	for _, sb := range mergeRq.SubBatches {
+		require.EqualValues(&rak, sb.RAK, "RAK should be included")
+		require.EqualValues(&avr, sb.AVR, "attestation should be included")
+	}
+}
+
+func TestExecuteParallelNotApplicable(t *testing.T) {
+	require := require.New(t)
+
+	n := newParallelTestNode(t)
+	rtDesc := &registry.Runtime{
+		TxnScheduler: registry.TxnSchedulerParameters{Algorithm: registry.TxnSchedulerParallel},
+	}
+	primary := &fakeRuntime{}
+	rts := []*runtimeInstance{{Runtime: primary}, {Runtime: &fakeRuntime{}}}
+	rq := &protocol.RuntimeExecuteTxBatchRequest{
+		Inputs: transaction.RawBatch{[]byte("a"), []byte("b")},
+	}
+
+	// Transactions without declared read/write sets cannot be executed in parallel.
+	_, err := n.executeParallel(context.Background(), rtDesc, rts, rq)
+	require.True(errors.Is(err, errParallelNotApplicable), "undeclared read/write sets")
+
+	// Messages must be processed exactly once.
+	primary.declareRw = true
+	rq.MessageResults = []*roothash.MessageEvent{{}}
+	_, err = n.executeParallel(context.Background(), rtDesc, rts, rq)
+	require.True(errors.Is(err, errParallelNotApplicable), "message results")
+	rq.MessageResults = nil
+
+	rtDesc.TxnScheduler.Algorithm = registry.TxnSchedulerSimple
+	_, err = n.executeParallel(context.Background(), rtDesc, rts, rq)
+	require.True(errors.Is(err, errParallelNotApplicable), "other scheduling algorithm")
+}
+
+type fakeRoleProvider struct {
+	registration.RoleProvider
+
+	hooks []registration.RegisterNodeHook
+}
+
+func (p *fakeRoleProvider) SetAvailable(hook registration.RegisterNodeHook) {
+	p.hooks = append(p.hooks, hook)
+}
+
+func TestParallelRuntimesRuntimeStarted(t *testing.T) {
+	require := require.New(t)
+
+	n := newParallelTestNode(t)
+	n.commonNode = &commonCommittee.Node{Runtime: &fakeRegistryRuntime{}}
+	roleProvider := &fakeRoleProvider{}
+	n.roleProvider = roleProvider
+	n.parallelRuntimeInstances = 2
+	secondary := &fakeRuntime{}
+	rts := []*runtimeInstance{{
+		Runtime:  secondary,
+		notifier: &protocol.NoOpNotifier{},
+		sub:      pubsub.NewBroker(false).Subscribe(),
+	}}
+	n.parallelRuntimes = rts
+
+	// The first started event should not be treated as an upgrade.
+	v := version.Version{Major: 1}
+	n.handleRuntimeHostEvent(&host.Event{Started: &host.StartedEvent{Version: v}})
+	require.Equal(rts, n.parallelRuntimes, "parallel runtime instances should be kept")
+
+	// Neither should a restart of the same version.
+	n.handleRuntimeHostEvent(&host.Event{Started: &host.StartedEvent{Version: v}})
+	require.Equal(rts, n.parallelRuntimes, "parallel runtime instances should be kept")
+	require.False(secondary.stopped, "parallel runtime instances should not be stopped")
+
+	require.Len(roleProvider.hooks, 2)
+	var nd node.Node
+	require.NoError(roleProvider.hooks[1](&nd))
+	require.Len(nd.Runtimes, 1)
+	require.Equal(v, nd.Runtimes[0].Version, "registered runtime version should be set")
+}
@@ -0,0 +1,175 @@
+package committee
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/persistent"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
+)
+
+const (
+	// restoreMaxScanRounds is the maximum number of finalized rounds that are scanned for
+	// persisted transactions that have already been included in a block.
+	restoreMaxScanRounds = 1000
+
+	// restoreCheckBatchSize is the maximum number of persisted transactions that are checked
+	// in a single runtime request.
+	restoreCheckBatchSize = 100
+)
+
+// persistTx persists the given queued transaction (if persistence is enabled).
+//
+// This may be called while holding the scheduler mutex, so it must not acquire
+// .commonNode.CrossNode. The store applies the update asynchronously.
+func (n *Node) persistTx(tx []byte) {
+	if n.txStore == nil {
+		return
+	}
+
+	round := atomic.LoadUint64(&n.latestRound)
+	if err := n.txStore.Add([][]byte{tx}, round); err != nil {
+		n.logger.Warn("failed to persist queued transaction",
+			"err", err,
+		)
+	}
+}
+
+// restoreQueuedTxs reloads the persisted queued transactions, drops the ones that have already
+// been included in finalized blocks and re-checks and queues the rest.
+func (n *Node) restoreQueuedTxs(ctx context.Context, latestRound uint64) {
+	qtxs, err := n.txStore.Load()
+	if err != nil {
+		n.logger.Error("failed to load persisted transactions",
+			"err", err,
+		)
+		return
+	}
+	if len(qtxs) == 0 {
+		return
+	}
+
+	n.logger.Info("restoring persisted transactions",
+		"num_txs", len(qtxs),
+	)
+
+	pending, included := n.filterIncludedTxs(ctx, qtxs, latestRound)
+	if len(included) > 0 {
+		n.logger.Info("dropping persisted transactions already included in a block",
+			"num_txs", len(included),
+		)
+		if err = n.txStore.Remove(included); err != nil {
+			n.logger.Warn("failed to remove included transactions from persistent transaction pool",
+				"err", err,
+			)
+		}
+	}
+
+	var restored int
+	for len(pending) > 0 {
+		batch := pending
+		if len(batch) > restoreCheckBatchSize {
+			batch = batch[:restoreCheckBatchSize]
+		}
+		pending = pending[len(batch):]
+
+		results, errs, err := n.checkTxBatch(ctx, batch)
+		if err != nil {
+			n.logger.Error("failed to check persisted transactions",
+				"err", err,
+			)
+			return
+		}
+
+		var invalid [][]byte
+		for i, tx := range batch {
+			if errs[i] == nil {
+				errs[i] = n.queueTx(tx, results[i])
+			}
+			if errs[i] != nil {
+				invalid = append(invalid, tx)
+				continue
+			}
+			restored++
+		}
+		if len(invalid) > 0 {
+			if err = n.txStore.Remove(invalid); err != nil {
+				n.logger.Warn("failed to remove invalid transactions from persistent transaction pool",
+					"err", err,
+				)
+			}
+		}
+	}
+
+	n.logger.Info("restored persisted transactions",
+		"num_txs", restored,
+	)
+}
+
+// filterIncludedTxs splits the given persisted transactions into ones that are still pending
+// and ones that have already been included in a finalized block.
+func (n *Node) filterIncludedTxs(
+	ctx context.Context,
+	qtxs []*persistent.QueuedTx,
+	latestRound uint64,
+) (pending transaction.RawBatch, included [][]byte) {
+	remaining := make(map[hash.Hash][]byte, len(qtxs))
+	startRound := latestRound
+	for _, qtx := range qtxs {
+		remaining[hash.NewFromBytes(qtx.Tx)] = qtx.Tx
+		// Transactions may only be included in rounds after they have been queued.
+		if qtx.Round+1 < startRound {
+			startRound = qtx.Round + 1
+		}
+	}
+	if latestRound > restoreMaxScanRounds && startRound < latestRound-restoreMaxScanRounds {
+		startRound = latestRound - restoreMaxScanRounds
+	}
+
+	history := n.commonNode.Runtime.History()
+	for round := startRound; round <= latestRound && len(remaining) > 0; round++ {
+		blk, err := history.GetBlock(ctx, round)
+		if err != nil {
+			// Block may have been pruned.
+			continue
+		}
+		if blk.Header.HeaderType != block.Normal || blk.Header.IORoot.IsEmpty() {
+			continue
+		}
+
+		txHashes := make([]hash.Hash, 0, len(remaining))
+		for txHash := range remaining {
+			txHashes = append(txHashes, txHash)
+		}
+
+		ioTree := transaction.NewTree(n.commonNode.Runtime.Storage(), storage.Root{
+			Namespace: blk.Header.Namespace,
+			Version:   blk.Header.Round,
+			Hash:      blk.Header.IORoot,
+		})
+		txs, err := ioTree.GetTransactionMultiple(ctx, txHashes)
+		ioTree.Close()
+		if err != nil {
+			n.logger.Warn("failed to query transactions included in block",
+				"err", err,
+				"round", round,
+			)
+			continue
+		}
+		for txHash := range txs {
+			included = append(included, remaining[txHash])
+			delete(remaining, txHash)
+		}
+	}
+
+	// Keep pending transactions in the order in which they were loaded.
+	for _, qtx := range qtxs {
+		if _, ok := remaining[hash.NewFromBytes(qtx.Tx)]; ok {
+			pending = append(pending, qtx.Tx)
+		}
+	}
+	return
+}
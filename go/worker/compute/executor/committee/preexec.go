@@ -0,0 +1,411 @@
+package committee
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/message"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
+)
+
+var emptyMessagesHash = message.MessagesHash(nil)
+
+// PreExecutionEvent is the outcome of a speculative pre-execution of a batch.
+type PreExecutionEvent struct {
+	// Round is the round the batch has been pre-executed for.
+	Round uint64
+	// Kept is true iff the expected parent block has been finalized so the pre-executed results
+	// can be used in the round.
+	Kept bool
+}
+
+// preExecutedBatch is a batch speculatively executed on top of a pending (not yet finalized)
+// block while waiting for the round to be finalized.
+type preExecutedBatch struct {
+	// parent is the expected parent block the batch has been executed on top of.
+	parent *block.Block
+	// consensusBlk is the consensus block the batch has been executed against.
+	consensusBlk *consensus.LightBlock
+	// consensusStateRoot is the consensus state root the batch has been executed against.
+	consensusStateRoot storage.Root
+	// raw is the pre-executed batch.
+	raw transaction.RawBatch
+	// pending are the queued transactions of the pending batch that have been removed from the
+	// scheduling queue and need to be requeued in case the pending batch is not finalized.
+	pending transaction.RawBatch
+
+	// Guarded by .commonNode.CrossNode.
+	resolved bool
+	kept     bool
+
+	// Set before done is closed.
+	done     chan struct{}
+	ioRoot   hash.Hash
+	request  *protocol.RuntimeExecuteTxBatchRequest
+	computed *protocol.ComputedBatch
+	// finalizationIndependent is true iff the runtime reported that the results do not depend on
+	// the block timestamp or the consensus block and state.
+	finalizationIndependent bool
+}
+
+// newPreExecutedBatch creates a new batch to be pre-executed on top of the block expected to be
+// finalized with the given proposed results.
+func newPreExecutedBatch(
+	current *block.Block,
+	proposed *commitment.ComputeResultsHeader,
+	consensusBlk *consensus.LightBlock,
+	consensusStateRoot storage.Root,
+	raw transaction.RawBatch,
+	pending transaction.RawBatch,
+) *preExecutedBatch {
+	// Construct the expected parent block from the proposed results. The timestamp is a guess as
+	// it depends on the consensus block finalizing the round.
+	parent := block.NewEmptyBlock(current, uint64(time.Now().Unix()), block.Normal)
+	parent.Header.IORoot = *proposed.IORoot
+	parent.Header.StateRoot = *proposed.StateRoot
+	parent.Header.MessagesHash = *proposed.MessagesHash
+
+	return &preExecutedBatch{
+		parent:             parent,
+		consensusBlk:       consensusBlk,
+		consensusStateRoot: consensusStateRoot,
+		raw:                raw,
+		pending:            pending,
+		done:               make(chan struct{}),
+	}
+}
+
+// round returns the round the batch has been pre-executed for.
+func (pb *preExecutedBatch) round() uint64 {
+	return pb.parent.Header.Round + 1
+}
+
+// isDone returns true iff the pre-execution has finished.
+func (pb *preExecutedBatch) isDone() bool {
+	select {
+	case <-pb.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// matchesParent returns true iff the given block is the expected parent block. Only the timestamp
+// and the storage receipts of the parent block may differ as they are only known at finalization
+// time.
+func (pb *preExecutedBatch) matchesParent(blk *block.Block) bool {
+	hdr := blk.Header
+	hdr.Timestamp = pb.parent.Header.Timestamp
+	return hdr.MostlyEqual(&pb.parent.Header)
+}
+
+// matches returns true iff the pre-executed results can be used on top of the given block and
+// consensus state. The given block must be the expected parent block.
+//
+// The next round is executed against the consensus block finalizing the parent block, which is not
+// known in advance. Unless the runtime reported that the results do not depend on it, the block
+// timestamp, the consensus block and the state root must be the ones the batch has been executed
+// against.
+//
+// Must only be called after the pre-execution has finished.
+func (pb *preExecutedBatch) matches(
+	blk *block.Block,
+	consensusBlk *consensus.LightBlock,
+	consensusStateRoot storage.Root,
+) bool {
+	if consensusBlk == nil || !pb.matchesParent(blk) {
+		return false
+	}
+	if pb.finalizationIndependent {
+		return true
+	}
+	return blk.Header.Timestamp == pb.parent.Header.Timestamp &&
+		consensusBlk.Height == pb.consensusBlk.Height &&
+		bytes.Equal(consensusBlk.Meta, pb.consensusBlk.Meta) &&
+		consensusStateRoot.Equal(&pb.consensusStateRoot)
+}
+
+// rebase returns the pre-executed results rebased on top of the given block and consensus state
+// which must match the expected ones.
+func (pb *preExecutedBatch) rebase(
+	blk *block.Block,
+	consensusBlk *consensus.LightBlock,
+	consensusStateRoot storage.Root,
+) *processedBatch {
+	// The timestamp and storage receipts of the finalized parent block are only known at
+	// finalization time.
+	computed := *pb.computed
+	computed.Header.PreviousHash = blk.Header.EncodedHash()
+	request := *pb.request
+	request.Block = *blk
+	request.ConsensusBlock = *consensusBlk
+	request.ConsensusState = protocol.ConsensusState{ConsensusStateRoot: consensusStateRoot}
+
+	return &processedBatch{
+		computed: &computed,
+		raw:      pb.raw,
+		request:  &request,
+	}
+}
+
+// maybePreExecuteLocked starts a speculative execution of the next batch on top of the results
+// proposed in the round being finalized in case pre-execution is enabled and the node is the
+// transaction scheduler of the next round.
+//
+// Guarded by n.commonNode.CrossNode.
+func (n *Node) maybePreExecuteLocked(state StateWaitingForFinalize) {
+	if !n.preExecutionEnabled || state.proposedHeader == nil {
+		return
+	}
+	if pb := n.preExecution; pb != nil {
+		// Only a single batch is pre-executed at a time. Resolved pre-executions which have not
+		// been used can be replaced once they are done.
+		if !pb.isDone() || !pb.resolved {
+			return
+		}
+	}
+
+	epoch := n.commonNode.Group.GetEpochSnapshot()
+	proposed := state.proposedHeader
+	switch {
+	case epoch.GetRuntime().TEEHardware != node.TEEHardwareInvalid:
+		// Pre-executed results cannot be attested by the runtime as the parent block is not
+		// known in advance.
+		return
+	case proposed.MessagesHash == nil || !proposed.MessagesHash.Equal(&emptyMessagesHash):
+		// Message results must be available before executing the next round.
+		return
+	case epoch.GetRuntime().Executor.MaxInMessages > 0:
+		// Incoming messages to be delivered in the next round are not known in advance.
+		return
+	case !epoch.IsTransactionScheduler(proposed.Round):
+		// Only the next transaction scheduler knows the next batch.
+		return
+	}
+
+	rt := n.GetHostedRuntime()
+	if rt == nil {
+		return
+	}
+
+	// Remove the pending batch from the scheduling queue so that the next batch can be obtained.
+	n.schedulerMutex.RLock()
+	defer n.schedulerMutex.RUnlock()
+
+	var pending transaction.RawBatch
+	for _, tx := range state.raw {
+		if n.scheduler.IsQueued(hash.NewFromBytes(tx)) {
+			pending = append(pending, tx)
+		}
+	}
+	if err := n.scheduler.RemoveTxBatch(pending); err != nil {
+		n.logger.Error("failed to remove pending batch from queue",
+			"err", err,
+		)
+		return
+	}
+	batch := n.scheduler.GetBatch(true)
+	if len(batch) == 0 {
+		if err := n.scheduler.AppendTxBatch(pending); err != nil {
+			n.logger.Error("failed to requeue pending batch",
+				"err", err,
+			)
+		}
+		return
+	}
+
+	pb := newPreExecutedBatch(
+		n.commonNode.CurrentBlock,
+		proposed,
+		n.commonNode.CurrentConsensusBlock,
+		n.commonNode.CurrentConsensusStateRoot,
+		batch,
+		pending,
+	)
+	n.preExecution = pb
+
+	n.logger.Debug("pre-executing next batch",
+		"round", pb.round(),
+		"batch_size", len(batch),
+	)
+
+	go n.preExecute(rt, pb)
+}
+
+// preExecute executes the given batch on top of the expected parent block.
+func (n *Node) preExecute(rt host.Runtime, pb *preExecutedBatch) {
+	defer close(pb.done)
+
+	emptyRoot := storage.Root{
+		Namespace: pb.parent.Header.Namespace,
+		Version:   pb.round(),
+	}
+	emptyRoot.Hash.Empty()
+
+	var err error
+	if _, pb.ioRoot, err = commitInputs(n.ctx, emptyRoot, pb.raw); err != nil {
+		n.logger.Error("failed to create I/O tree for pre-execution",
+			"err", err,
+		)
+		return
+	}
+
+	rq := &protocol.RuntimeExecuteTxBatchRequest{
+		ConsensusBlock: *pb.consensusBlk,
+		ConsensusState: protocol.ConsensusState{ConsensusStateRoot: pb.consensusStateRoot},
+		IORoot:         pb.ioRoot,
+		Inputs:         pb.raw,
+		Block:          *pb.parent,
+	}
+	rsp, err := rt.Call(n.ctx, &protocol.Body{RuntimeExecuteTxBatchRequest: rq})
+	if err != nil {
+		n.logger.Warn("failed to pre-execute batch",
+			"err", err,
+		)
+		return
+	}
+	if rsp.RuntimeExecuteTxBatchResponse == nil {
+		n.logger.Error("malformed response from runtime",
+			"response", rsp,
+		)
+		return
+	}
+	pb.request = rq
+	pb.computed = &rsp.RuntimeExecuteTxBatchResponse.Batch
+	pb.finalizationIndependent = rsp.RuntimeExecuteTxBatchResponse.FinalizationIndependent
+}
+
+// resolvePreExecutionLocked keeps or discards the pre-executed batch based on whether the given
+// block is the expected parent block and the pre-executed results can be used with the current
+// consensus block. In case the pre-execution has not yet finished, the batch is kept and the
+// results are checked once they are used.
+//
+// Guarded by n.commonNode.CrossNode.
+func (n *Node) resolvePreExecutionLocked(blk *block.Block) {
+	pb := n.preExecution
+	if pb == nil || pb.resolved {
+		return
+	}
+	pb.resolved = true
+	if pb.isDone() {
+		pb.kept = pb.matches(blk, n.commonNode.CurrentConsensusBlock, n.commonNode.CurrentConsensusStateRoot)
+	} else {
+		pb.kept = pb.matchesParent(blk)
+	}
+
+	if pb.kept {
+		n.logger.Debug("expected block finalized, keeping pre-executed batch",
+			"round", pb.round(),
+		)
+	} else {
+		n.logger.Info("unexpected block or consensus state, discarding pre-executed batch",
+			"round", pb.round(),
+			"header_hash", blk.Header.EncodedHash(),
+			"consensus_height", n.commonNode.CurrentConsensusBlock.Height,
+		)
+		discardedPreExecutionCount.With(n.getMetricLabels()).Inc()
+
+		// The pending batch has not been finalized, requeue it.
+		n.schedulerMutex.RLock()
+		if err := n.scheduler.AppendTxBatch(pb.pending); err != nil {
+			n.logger.Error("failed to requeue pending batch",
+				"err", err,
+			)
+		}
+		n.schedulerMutex.RUnlock()
+		if pb.isDone() {
+			n.preExecution = nil
+		}
+	}
+
+	n.preExecutions.Broadcast(&PreExecutionEvent{
+		Round: pb.round(),
+		Kept:  pb.kept,
+	})
+}
+
+// preExecutedBatchLocked returns the kept pre-executed batch for the given round in case all of
+// its transactions are still queued.
+//
+// Guarded by n.commonNode.CrossNode.
+func (n *Node) preExecutedBatchLocked(round uint64) transaction.RawBatch {
+	pb := n.preExecution
+	if pb == nil || !pb.kept || pb.round() != round {
+		return nil
+	}
+
+	n.schedulerMutex.RLock()
+	defer n.schedulerMutex.RUnlock()
+	for _, tx := range pb.raw {
+		if !n.scheduler.IsQueued(hash.NewFromBytes(tx)) {
+			return nil
+		}
+	}
+	return pb.raw
+}
+
+// takePreExecutionLocked removes the pre-executed batch (if any) so that it can be used when
+// processing the batch of the current round.
+//
+// Guarded by n.commonNode.CrossNode.
+func (n *Node) takePreExecutionLocked() (*preExecutedBatch, bool) {
+	pb := n.preExecution
+	if pb == nil {
+		return nil, false
+	}
+	n.preExecution = nil
+	return pb, pb.kept
+}
+
+// usePreExecuted waits for the given pre-execution to finish and returns its results in case they
+// can be used for processing the given batch on top of the given block and consensus state.
+func (n *Node) usePreExecuted(
+	ctx context.Context,
+	pb *preExecutedBatch,
+	kept bool,
+	blk *block.Block,
+	consensusBlk *consensus.LightBlock,
+	consensusStateRoot storage.Root,
+	ioRoot hash.Hash,
+) *processedBatch {
+	if pb == nil {
+		return nil
+	}
+
+	// Wait for the pre-execution to finish so the runtime is not used concurrently.
+	select {
+	case <-pb.done:
+	case <-ctx.Done():
+		return nil
+	}
+
+	if !kept || pb.computed == nil || !pb.ioRoot.Equal(&ioRoot) || !pb.matches(blk, consensusBlk, consensusStateRoot) {
+		return nil
+	}
+
+	preExecutedBatchCount.With(n.getMetricLabels()).Inc()
+	n.logger.Info("using pre-executed batch",
+		"round", pb.round(),
+	)
+
+	return pb.rebase(blk, consensusBlk, consensusStateRoot)
+}
+
+// WatchPreExecutions subscribes to the outcomes of speculative batch pre-executions.
+func (n *Node) WatchPreExecutions() (<-chan *PreExecutionEvent, *pubsub.Subscription) {
+	sub := n.preExecutions.Subscribe()
+	ch := make(chan *PreExecutionEvent)
+	sub.Unwrap(ch)
+
+	return ch, sub
+}
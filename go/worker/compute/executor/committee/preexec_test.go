@@ -0,0 +1,224 @@
+package committee
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eapache/channels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	registry "github.com/oasisprotocol/oasis-core/go/registry/api"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
+	commonCommittee "github.com/oasisprotocol/oasis-core/go/worker/common/committee"
+)
+
+func newPreExecutedTestBatch() *preExecutedBatch {
+	var id common.Namespace
+	parent := block.NewEmptyBlock(block.NewGenesisBlock(id, 0), 10, block.Normal)
+	parent.Header.IORoot = hash.NewFromBytes([]byte("io root"))
+	parent.Header.StateRoot = hash.NewFromBytes([]byte("state root"))
+
+	consensusBlk := &consensus.LightBlock{Height: 5, Meta: []byte("light block")}
+	consensusStateRoot := storage.Root{Version: 5, Hash: hash.NewFromBytes([]byte("consensus state root"))}
+
+	pb := &preExecutedBatch{
+		parent:             parent,
+		consensusBlk:       consensusBlk,
+		consensusStateRoot: consensusStateRoot,
+		raw:                transaction.RawBatch{[]byte("tx")},
+		done:               make(chan struct{}),
+		ioRoot:             hash.NewFromBytes([]byte("batch io root")),
+		request: &protocol.RuntimeExecuteTxBatchRequest{
+			ConsensusBlock: *consensusBlk,
+			ConsensusState: protocol.ConsensusState{ConsensusStateRoot: consensusStateRoot},
+			Block:          *parent,
+		},
+		computed: &protocol.ComputedBatch{},
+	}
+	pb.computed.Header.Round = parent.Header.Round + 1
+	pb.computed.Header.PreviousHash = parent.Header.EncodedHash()
+	close(pb.done)
+
+	return pb
+}
+
+func TestPreExecutedBatch(t *testing.T) {
+	t.Run("Matches", func(t *testing.T) {
+		require := require.New(t)
+
+		pb := newPreExecutedTestBatch()
+		consensusBlk := *pb.consensusBlk
+		consensusStateRoot := pb.consensusStateRoot
+
+		// Storage receipts of the parent block are only known at finalization time.
+		finalized := *pb.parent
+		finalized.Header.StorageSignatures = []signature.Signature{{}}
+		require.True(pb.matches(&finalized, &consensusBlk, consensusStateRoot), "expected block should match")
+
+		processed := pb.rebase(&finalized, &consensusBlk, consensusStateRoot)
+		require.EqualValues(finalized.Header.EncodedHash(), processed.computed.Header.PreviousHash, "results should be rebased")
+		require.EqualValues(finalized, processed.request.Block, "request should refer to the finalized block")
+		require.EqualValues(pb.parent.Header.EncodedHash(), pb.computed.Header.PreviousHash, "pre-executed results should not change")
+	})
+
+	t.Run("Mismatches", func(t *testing.T) {
+		pb := newPreExecutedTestBatch()
+
+		for _, tc := range []struct {
+			name string
+			// finalizationIndependent is true iff results independent of the finalization data
+			// should still match.
+			finalizationIndependent bool
+			mutate                  func(blk *block.Block, consensusBlk *consensus.LightBlock, consensusStateRoot *storage.Root)
+		}{
+			{"Timestamp", true, func(blk *block.Block, _ *consensus.LightBlock, _ *storage.Root) {
+				blk.Header.Timestamp++
+			}},
+			{"StateRoot", false, func(blk *block.Block, _ *consensus.LightBlock, _ *storage.Root) {
+				blk.Header.StateRoot = hash.NewFromBytes([]byte("other state root"))
+			}},
+			{"ConsensusHeight", true, func(_ *block.Block, consensusBlk *consensus.LightBlock, _ *storage.Root) {
+				consensusBlk.Height++
+			}},
+			{"ConsensusBlock", true, func(_ *block.Block, consensusBlk *consensus.LightBlock, _ *storage.Root) {
+				consensusBlk.Meta = []byte("other light block")
+			}},
+			{"ConsensusStateRoot", true, func(_ *block.Block, _ *consensus.LightBlock, consensusStateRoot *storage.Root) {
+				consensusStateRoot.Hash = hash.NewFromBytes([]byte("other consensus state root"))
+			}},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				require := require.New(t)
+
+				blk := *pb.parent
+				consensusBlk := *pb.consensusBlk
+				consensusStateRoot := pb.consensusStateRoot
+				tc.mutate(&blk, &consensusBlk, &consensusStateRoot)
+
+				require.False(pb.matches(&blk, &consensusBlk, consensusStateRoot), "pre-executed batch should not match")
+
+				independent := *pb
+				independent.finalizationIndependent = true
+				require.Equal(tc.finalizationIndependent, independent.matches(&blk, &consensusBlk, consensusStateRoot),
+					"only the finalization data should be ignored for independent results",
+				)
+
+				// Results should not be used even if the batch has been kept.
+				n := &Node{logger: logging.GetLogger("worker/executor/committee/test")}
+				processed := n.usePreExecuted(context.Background(), pb, true, &blk, &consensusBlk, consensusStateRoot, pb.ioRoot)
+				require.Nil(processed, "pre-executed results should be discarded")
+			})
+		}
+	})
+}
+
+func TestPreExecutionNewBlocks(t *testing.T) {
+	for _, tc := range []struct {
+		name                    string
+		finalizationIndependent bool
+		kept                    bool
+	}{
+		{"FinalizationIndependent", true, true},
+		{"FinalizationDependent", false, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+
+			scheduler, err := scheduling.New(100, registry.TxnSchedulerParameters{
+				Algorithm:         registry.TxnSchedulerSimple,
+				BatchFlushTimeout: time.Second,
+				MaxBatchSize:      10,
+				MaxBatchSizeBytes: 1024,
+			})
+			require.NoError(err, "scheduling.New")
+
+			n := newParallelTestNode(t)
+			n.commonNode = &commonCommittee.Node{Runtime: &fakeRegistryRuntime{}}
+			n.scheduler = scheduler
+			n.scheduleCh = channels.NewRingChannel(1)
+			n.scheduleMaxTxPoolSize = 100
+			n.preExecutions = pubsub.NewBroker(false)
+			evCh, sub := n.WatchPreExecutions()
+			defer sub.Close()
+
+			// The current round (1) has been finalized at consensus height 10.
+			var id common.Namespace
+			current := block.NewEmptyBlock(block.NewGenesisBlock(id, 0), 100, block.Normal)
+			consensusBlk := &consensus.LightBlock{Height: 10, Meta: []byte("light block 10")}
+			consensusStateRoot := storage.Root{Version: 9, Hash: hash.NewFromBytes([]byte("consensus state 9"))}
+			n.commonNode.CurrentBlock = current
+			n.commonNode.CurrentBlockHeight = 10
+			n.commonNode.CurrentConsensusBlock = consensusBlk
+			n.commonNode.CurrentConsensusStateRoot = consensusStateRoot
+
+			// The pending batch of round 2 has been proposed and the next batch is pre-executed
+			// while waiting for the round to be finalized.
+			pending := transaction.RawBatch{[]byte("pending")}
+			batch := transaction.RawBatch{[]byte("next")}
+			require.NoError(n.QueueTx(batch[0]), "QueueTx")
+
+			ioRoot := hash.NewFromBytes([]byte("io root"))
+			stateRoot := hash.NewFromBytes([]byte("state root"))
+			proposed := &commitment.ComputeResultsHeader{
+				Round:        current.Header.Round + 1,
+				PreviousHash: current.Header.EncodedHash(),
+				IORoot:       &ioRoot,
+				StateRoot:    &stateRoot,
+				MessagesHash: &emptyMessagesHash,
+			}
+			pb := newPreExecutedBatch(current, proposed, consensusBlk, consensusStateRoot, batch, pending)
+			n.preExecution = pb
+			n.preExecute(&fakeRuntime{finalizationIndependent: tc.finalizationIndependent}, pb)
+			require.NotNil(pb.computed, "batch should be pre-executed")
+
+			// Round 2 is finalized by a later consensus block at a different time.
+			finalized := block.NewEmptyBlock(current, pb.parent.Header.Timestamp+7, block.Normal)
+			finalized.Header.IORoot = ioRoot
+			finalized.Header.StateRoot = stateRoot
+			finalized.Header.MessagesHash = emptyMessagesHash
+			finalized.Header.StorageSignatures = []signature.Signature{{}}
+			finalizedConsensusBlk := &consensus.LightBlock{Height: 12, Meta: []byte("light block 12")}
+			finalizedConsensusStateRoot := storage.Root{Version: 11, Hash: hash.NewFromBytes([]byte("consensus state 11"))}
+			n.commonNode.CurrentBlock = finalized
+			n.commonNode.CurrentBlockHeight = 12
+			n.commonNode.CurrentConsensusBlock = finalizedConsensusBlk
+			n.commonNode.CurrentConsensusStateRoot = finalizedConsensusStateRoot
+
+			go n.resolvePreExecutionLocked(finalized)
+			select {
+			case ev := <-evCh:
+				require.EqualValues(finalized.Header.Round+1, ev.Round)
+				require.Equal(tc.kept, ev.Kept, "pre-execution outcome")
+			case <-time.After(time.Second):
+				t.Fatalf("failed to receive pre-execution event")
+			}
+
+			if !tc.kept {
+				require.Nil(n.preExecutedBatchLocked(finalized.Header.Round+1), "discarded batch should not be proposed")
+				require.True(n.scheduler.IsQueued(hash.NewFromBytes(pending[0])), "pending batch should be requeued")
+				return
+			}
+
+			// The pre-executed batch should be proposed and its results used in round 3.
+			require.EqualValues(batch, n.preExecutedBatchLocked(finalized.Header.Round+1), "kept batch should be proposed")
+			preExecuted, kept := n.takePreExecutionLocked()
+			processed := n.usePreExecuted(context.Background(), preExecuted, kept, finalized, finalizedConsensusBlk, finalizedConsensusStateRoot, pb.ioRoot)
+			require.NotNil(processed, "pre-executed results should be used")
+			require.EqualValues(finalized.Header.EncodedHash(), processed.computed.Header.PreviousHash, "results should be rebased")
+			require.EqualValues(*finalizedConsensusBlk, processed.request.ConsensusBlock, "request should refer to the finalizing consensus block")
+			require.EqualValues(finalizedConsensusStateRoot, processed.request.ConsensusStateRoot)
+		})
+	}
+}
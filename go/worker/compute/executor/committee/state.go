@@ -4,9 +4,12 @@ import (
 	"context"
 	"time"
 
+	"github.com/opentracing/opentracing-go"
+
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
 	"github.com/oasisprotocol/oasis-core/go/roothash/api/block"
+	"github.com/oasisprotocol/oasis-core/go/roothash/api/commitment"
 	"github.com/oasisprotocol/oasis-core/go/runtime/host/protocol"
 	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 )
@@ -175,6 +178,7 @@ type StateProcessingBatch struct {
 type processedBatch struct {
 	computed *protocol.ComputedBatch
 	raw      transaction.RawBatch
+	request  *protocol.RuntimeExecuteTxBatchRequest
 }
 
 // Name returns the name of the state.
@@ -199,6 +203,11 @@ type StateWaitingForFinalize struct {
 	batchStartTime time.Time
 	raw            transaction.RawBatch
 	proposedIORoot hash.Hash
+	// Header of the proposed results. Nil in case the batch has been aborted.
+	proposedHeader *commitment.ComputeResultsHeader
+
+	// Span tracking the time until the round is finalized.
+	span opentracing.Span
 }
 
 // Name returns the name of the state.
@@ -0,0 +1,161 @@
+package forensics
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/api"
+)
+
+const missing = "<missing>"
+
+// Difference is a single difference between two forensics bundles.
+type Difference struct {
+	// Field is the name of the differing field.
+	Field string `json:"field"`
+	// A is the value in the first bundle.
+	A string `json:"a"`
+	// B is the value in the second bundle.
+	B string `json:"b"`
+}
+
+// String returns a string representation of the difference.
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s != %s", d.Field, d.A, d.B)
+}
+
+type differ struct {
+	diffs []Difference
+}
+
+func (d *differ) add(field string, a, b interface{}) {
+	d.diffs = append(d.diffs, Difference{
+		Field: field,
+		A:     fmt.Sprintf("%v", a),
+		B:     fmt.Sprintf("%v", b),
+	})
+}
+
+func (d *differ) compare(field string, a, b interface{}) {
+	ha, hb := hash.NewFrom(a), hash.NewFrom(b)
+	if !ha.Equal(&hb) {
+		d.add(field, ha, hb)
+	}
+}
+
+func (d *differ) compareOptionalHash(field string, a, b *hash.Hash) {
+	switch {
+	case a == nil && b == nil:
+	case a == nil:
+		d.add(field, missing, b)
+	case b == nil:
+		d.add(field, a, missing)
+	case !a.Equal(b):
+		d.add(field, a, b)
+	}
+}
+
+func (d *differ) compareWriteLogs(field string, a, b storage.WriteLog) {
+	entries := func(wl storage.WriteLog) map[string][]byte {
+		m := make(map[string][]byte, len(wl))
+		for _, entry := range wl {
+			m[string(entry.Key)] = entry.Value
+		}
+		return m
+	}
+	ea, eb := entries(a), entries(b)
+
+	keys := make(map[string]bool)
+	for k := range ea {
+		keys[k] = true
+	}
+	for k := range eb {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	format := func(v []byte, ok bool) string {
+		switch {
+		case !ok:
+			return missing
+		case v == nil:
+			return "<deleted>"
+		default:
+			return hex.EncodeToString(v)
+		}
+	}
+	for _, k := range sorted {
+		va, okA := ea[k]
+		vb, okB := eb[k]
+		if okA == okB && (va == nil) == (vb == nil) && bytes.Equal(va, vb) {
+			continue
+		}
+		d.add(fmt.Sprintf("%s[%s]", field, hex.EncodeToString([]byte(k))), format(va, okA), format(vb, okB))
+	}
+}
+
+// Diff compares two forensics bundles for the same round and returns all differences in the
+// inputs used and the outputs produced by the executor nodes. Differences in inputs indicate
+// that the nodes executed different requests, while differences only in outputs indicate
+// non-deterministic execution.
+func Diff(a, b *api.ForensicsBundle) []Difference {
+	var d differ
+	if !a.RuntimeID.Equal(&b.RuntimeID) {
+		d.add("runtime_id", a.RuntimeID, b.RuntimeID)
+	}
+	if a.Round != b.Round {
+		d.add("round", a.Round, b.Round)
+	}
+
+	// Inputs.
+	ra, rb := &a.Request, &b.Request
+	if ha, hb := ra.Block.Header.EncodedHash(), rb.Block.Header.EncodedHash(); !ha.Equal(&hb) {
+		d.add("request.block", ha, hb)
+	}
+	if ra.ConsensusBlock.Height != rb.ConsensusBlock.Height {
+		d.add("request.consensus_block.height", ra.ConsensusBlock.Height, rb.ConsensusBlock.Height)
+	}
+	d.compare("request.consensus_state_root", ra.ConsensusStateRoot, rb.ConsensusStateRoot)
+	d.compare("request.message_results", ra.MessageResults, rb.MessageResults)
+	d.compare("request.incoming_messages", ra.IncomingMessages, rb.IncomingMessages)
+	if !ra.IORoot.Equal(&rb.IORoot) {
+		d.add("request.io_root", ra.IORoot, rb.IORoot)
+	}
+	if len(ra.Inputs) != len(rb.Inputs) {
+		d.add("request.inputs.len", len(ra.Inputs), len(rb.Inputs))
+	}
+	for i := 0; i < len(ra.Inputs) && i < len(rb.Inputs); i++ {
+		ha, hb := hash.NewFromBytes(ra.Inputs[i]), hash.NewFromBytes(rb.Inputs[i])
+		if !ha.Equal(&hb) {
+			d.add(fmt.Sprintf("request.inputs[%d]", i), ha, hb)
+		}
+	}
+
+	// Outputs.
+	ha, hb := &a.Body.Header, &b.Body.Header
+	if !ha.PreviousHash.Equal(&hb.PreviousHash) {
+		d.add("body.header.previous_hash", ha.PreviousHash, hb.PreviousHash)
+	}
+	d.compareOptionalHash("body.header.io_root", ha.IORoot, hb.IORoot)
+	d.compareOptionalHash("body.header.state_root", ha.StateRoot, hb.StateRoot)
+	d.compareOptionalHash("body.header.messages_hash", ha.MessagesHash, hb.MessagesHash)
+	d.compareOptionalHash("body.header.in_msgs_hash", ha.InMessagesHash, hb.InMessagesHash)
+	if ha.InMessagesCount != hb.InMessagesCount {
+		d.add("body.header.in_msgs_count", ha.InMessagesCount, hb.InMessagesCount)
+	}
+	if a.Body.Failure != b.Body.Failure {
+		d.add("body.failure", a.Body.Failure, b.Body.Failure)
+	}
+	d.compareWriteLogs("io_write_log", a.IOWriteLog, b.IOWriteLog)
+	d.compareWriteLogs("state_write_log", a.StateWriteLog, b.StateWriteLog)
+
+	return d.diffs
+}
@@ -0,0 +1,201 @@
+// Package forensics implements a persistent store of executor forensics bundles, used to
+// investigate execution discrepancies after the fact.
+package forensics
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/dgraph-io/badger/v2/options"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	cmnBadger "github.com/oasisprotocol/oasis-core/go/common/badger"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/keyformat"
+	"github.com/oasisprotocol/oasis-core/go/common/logging"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/api"
+)
+
+// DBFile is the filename of the forensics bundle database.
+const DBFile = "forensics.badger.db"
+
+const dbVersion = 1
+
+var (
+	// metadataKeyFmt is the metadata key format.
+	//
+	// Value is CBOR-serialized dbMetadata.
+	metadataKeyFmt = keyformat.New(0x01)
+	// bundleKeyFmt is the forensics bundle key format.
+	//
+	// Value is CBOR-serialized api.ForensicsBundle.
+	bundleKeyFmt = keyformat.New(0x02, uint64(0))
+
+	_ Store = (*store)(nil)
+)
+
+type dbMetadata struct {
+	// RuntimeID is the runtime ID this database is for.
+	RuntimeID common.Namespace `json:"runtime_id"`
+	// Version is the database schema version.
+	Version uint64 `json:"version"`
+}
+
+// Store is a persistent store of forensics bundles.
+type Store interface {
+	// Put persists the given bundle, replacing any bundle stored for the same round, and prunes
+	// bundles that fall out of the retention window.
+	Put(bundle *api.ForensicsBundle) error
+
+	// Get returns the bundle for the given round.
+	Get(round uint64) (*api.ForensicsBundle, error)
+
+	// Close closes the store.
+	Close()
+}
+
+type store struct {
+	logger *logging.Logger
+
+	db *badger.DB
+	gc *cmnBadger.GCWorker
+
+	maxRounds uint64
+}
+
+func (s *store) Put(bundle *api.ForensicsBundle) error {
+	err := s.db.Update(func(tx *badger.Txn) error {
+		if err := tx.Set(bundleKeyFmt.Encode(bundle.Round), cbor.Marshal(bundle)); err != nil {
+			return err
+		}
+
+		if s.maxRounds == 0 || bundle.Round < s.maxRounds {
+			return nil
+		}
+		minRound := bundle.Round - s.maxRounds + 1
+
+		// Prune all bundles for rounds before the retention window.
+		it := tx.NewIterator(badger.IteratorOptions{Prefix: bundleKeyFmt.Encode()})
+		defer it.Close()
+
+		var stale [][]byte
+		for it.Rewind(); it.Valid(); it.Next() {
+			var round uint64
+			if !bundleKeyFmt.Decode(it.Item().Key(), &round) || round >= minRound {
+				break
+			}
+			stale = append(stale, it.Item().KeyCopy(nil))
+		}
+		for _, key := range stale {
+			if err := tx.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("worker/executor/forensics: failed to put bundle: %w", err)
+	}
+	return nil
+}
+
+func (s *store) Get(round uint64) (*api.ForensicsBundle, error) {
+	var bundle api.ForensicsBundle
+	err := s.db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get(bundleKeyFmt.Encode(round))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return cbor.Unmarshal(val, &bundle)
+		})
+	})
+	switch err {
+	case nil:
+		return &bundle, nil
+	case badger.ErrKeyNotFound:
+		return nil, api.ErrBundleNotFound
+	default:
+		return nil, fmt.Errorf("worker/executor/forensics: failed to get bundle: %w", err)
+	}
+}
+
+func (s *store) Close() {
+	s.gc.Close()
+	if err := s.db.Close(); err != nil {
+		s.logger.Error("failed to close forensics database",
+			"err", err,
+		)
+	}
+}
+
+func (s *store) ensureMetadata(runtimeID common.Namespace) error {
+	return s.db.Update(func(tx *badger.Txn) error {
+		item, err := tx.Get(metadataKeyFmt.Encode())
+		switch err {
+		case nil:
+		case badger.ErrKeyNotFound:
+			// Create new metadata section.
+			meta := dbMetadata{
+				RuntimeID: runtimeID,
+				Version:   dbVersion,
+			}
+			return tx.Set(metadataKeyFmt.Encode(), cbor.Marshal(meta))
+		default:
+			return err
+		}
+
+		var meta dbMetadata
+		if err = item.Value(func(val []byte) error {
+			return cbor.Unmarshal(val, &meta)
+		}); err != nil {
+			return err
+		}
+
+		// Verify metadata section.
+		if meta.Version != dbVersion {
+			return fmt.Errorf("worker/executor/forensics: unsupported database version (expected: %d got: %d)",
+				dbVersion,
+				meta.Version,
+			)
+		}
+		if !meta.RuntimeID.Equal(&runtimeID) {
+			return fmt.Errorf("worker/executor/forensics: database for different runtime (expected: %s got: %s)",
+				runtimeID,
+				meta.RuntimeID,
+			)
+		}
+		return nil
+	})
+}
+
+// New creates a new forensics bundle store keeping bundles for at most maxRounds most recent
+// rounds. If maxRounds is zero, bundles are never pruned.
+func New(dataDir string, runtimeID common.Namespace, maxRounds uint64) (Store, error) {
+	s := &store{
+		logger:    logging.GetLogger("worker/executor/forensics").With("runtime_id", runtimeID),
+		maxRounds: maxRounds,
+	}
+
+	opts := badger.DefaultOptions(filepath.Join(dataDir, DBFile))
+	opts = opts.WithLogger(cmnBadger.NewLogAdapter(s.logger))
+	opts = opts.WithSyncWrites(true)
+	// Allow value log truncation if required (this is needed to recover the
+	// value log file which can get corrupted in crashes).
+	opts = opts.WithTruncate(true)
+	opts = opts.WithCompression(options.None)
+
+	var err error
+	if s.db, err = badger.Open(opts); err != nil {
+		return nil, fmt.Errorf("worker/executor/forensics: failed to open database: %w", err)
+	}
+	s.gc = cmnBadger.NewGCWorker(s.logger, s.db)
+
+	if err = s.ensureMetadata(runtimeID); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,130 @@
+package forensics
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
+	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/api"
+)
+
+func TestStore(t *testing.T) {
+	require := require.New(t)
+
+	// Create a new random temporary directory under /tmp.
+	dataDir, err := ioutil.TempDir("", "oasis-executor-forensics-test_")
+	require.NoError(err, "TempDir")
+	defer os.RemoveAll(dataDir)
+
+	runtimeID := common.NewTestNamespaceFromSeed([]byte("forensics test ns 1"), 0)
+	runtimeID2 := common.NewTestNamespaceFromSeed([]byte("forensics test ns 2"), 0)
+
+	store, err := New(dataDir, runtimeID, 3)
+	require.NoError(err, "New")
+
+	_, err = store.Get(1)
+	require.True(errors.Is(err, api.ErrBundleNotFound), "Get should fail for missing bundles")
+
+	newBundle := func(round uint64) *api.ForensicsBundle {
+		return &api.ForensicsBundle{
+			RuntimeID: runtimeID,
+			Round:     round,
+			IOWriteLog: storage.WriteLog{
+				{Key: []byte("key"), Value: []byte("output")},
+			},
+		}
+	}
+	for round := uint64(1); round <= 4; round++ {
+		bundle := newBundle(round)
+		bundle.Request.Inputs = transaction.RawBatch{[]byte("tx")}
+		err = store.Put(bundle)
+		require.NoError(err, "Put")
+	}
+
+	// Bundles should survive reopening the store.
+	store.Close()
+	store, err = New(dataDir, runtimeID, 3)
+	require.NoError(err, "New")
+
+	// Bundles outside of the retention window should be pruned.
+	_, err = store.Get(1)
+	require.True(errors.Is(err, api.ErrBundleNotFound), "Get should fail for pruned bundles")
+	for round := uint64(2); round <= 4; round++ {
+		bundle, gErr := store.Get(round)
+		require.NoError(gErr, "Get")
+		require.EqualValues(round, bundle.Round, "bundle should be for the requested round")
+		require.EqualValues(runtimeID, bundle.RuntimeID)
+		require.EqualValues(transaction.RawBatch{[]byte("tx")}, bundle.Request.Inputs)
+		require.EqualValues(newBundle(round).IOWriteLog, bundle.IOWriteLog)
+	}
+	store.Close()
+
+	// Opening the store for a different runtime should fail.
+	_, err = New(dataDir, runtimeID2, 3)
+	require.Error(err, "New should fail for a different runtime")
+}
+
+func TestDiff(t *testing.T) {
+	require := require.New(t)
+
+	runtimeID := common.NewTestNamespaceFromSeed([]byte("forensics test ns 1"), 0)
+	newBundle := func() *api.ForensicsBundle {
+		var ioRoot, stateRoot hash.Hash
+		ioRoot.FromBytes([]byte("io root"))
+		stateRoot.FromBytes([]byte("state root"))
+
+		bundle := &api.ForensicsBundle{
+			RuntimeID: runtimeID,
+			Round:     10,
+			IOWriteLog: storage.WriteLog{
+				{Key: []byte("output"), Value: []byte("value")},
+			},
+			StateWriteLog: storage.WriteLog{
+				{Key: []byte("counter"), Value: []byte{1}},
+				{Key: []byte("removed"), Value: nil},
+			},
+		}
+		bundle.Request.Inputs = transaction.RawBatch{[]byte("tx 1"), []byte("tx 2")}
+		bundle.Body.Header.Round = 10
+		bundle.Body.Header.IORoot = &ioRoot
+		bundle.Body.Header.StateRoot = &stateRoot
+		return bundle
+	}
+
+	a, b := newBundle(), newBundle()
+	require.Empty(Diff(a, b), "identical bundles should not differ")
+
+	// Non-deterministic execution.
+	var stateRoot hash.Hash
+	stateRoot.FromBytes([]byte("other state root"))
+	b.Body.Header.StateRoot = &stateRoot
+	b.StateWriteLog[0].Value = []byte{2}
+	b.StateWriteLog = append(b.StateWriteLog, storage.LogEntry{Key: []byte("extra"), Value: []byte{3}})
+
+	fields := func(diffs []Difference) []string {
+		var fields []string
+		for _, d := range diffs {
+			fields = append(fields, d.Field)
+		}
+		return fields
+	}
+	diffs := Diff(a, b)
+	require.EqualValues([]string{
+		"body.header.state_root",
+		"state_write_log[636f756e746572]",
+		"state_write_log[6578747261]",
+	}, fields(diffs))
+	require.Equal("<missing>", diffs[2].A, "missing entries should be reported")
+
+	// Different inputs.
+	b = newBundle()
+	b.Request.Inputs[1] = []byte("tx 3")
+	require.EqualValues([]string{"request.inputs[1]"}, fields(Diff(a, b)))
+}
@@ -1,9 +1,13 @@
 package executor
 
 import (
+	"fmt"
+
 	flag "github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/grpc"
 	workerCommon "github.com/oasisprotocol/oasis-core/go/worker/common"
 	"github.com/oasisprotocol/oasis-core/go/worker/compute"
 	"github.com/oasisprotocol/oasis-core/go/worker/registration"
@@ -14,8 +18,23 @@ const (
 	// scheduling it.
 	CfgScheduleCheckTxEnabled = "worker.executor.schedule_check_tx.enabled"
 
+	// CfgPreExecutionEnabled enables speculative pre-execution of the next
+	// batch while waiting for the current round to be finalized.
+	CfgPreExecutionEnabled = "worker.executor.pre_execution.enabled"
+
+	// CfgForensicsEnabled enables recording of per-round forensics bundles
+	// used to investigate execution discrepancies.
+	CfgForensicsEnabled = "worker.executor.forensics.enabled"
+
+	cfgForensicsMaxRounds      = "worker.executor.forensics.max_rounds"
+	cfgForensicsAllowedClients = "worker.executor.forensics.allowed_clients"
+
 	cfgMaxTxPoolSize       = "worker.executor.schedule_max_tx_pool_size"
 	cfgScheduleTxCacheSize = "worker.executor.schedule_tx_cache_size"
+
+	cfgSchedulePersistentTxPool = "worker.executor.schedule_persistent_tx_pool"
+
+	cfgParallelRuntimeInstances = "worker.executor.parallel_runtime_instances"
 )
 
 // Flags has the configuration flags.
@@ -23,11 +42,22 @@ var Flags = flag.NewFlagSet("", flag.ContinueOnError)
 
 // New creates a new executor worker.
 func New(
+	grpcInternal *grpc.Server,
 	dataDir string,
 	commonWorker *workerCommon.Worker,
 	registration *registration.Worker,
 ) (*Worker, error) {
+	var forensicsClients []signature.PublicKey
+	for _, v := range viper.GetStringSlice(cfgForensicsAllowedClients) {
+		var pk signature.PublicKey
+		if err := pk.UnmarshalText([]byte(v)); err != nil {
+			return nil, fmt.Errorf("worker/executor: malformed forensics client public key '%s': %w", v, err)
+		}
+		forensicsClients = append(forensicsClients, pk)
+	}
+
 	return newWorker(
+		grpcInternal,
 		dataDir,
 		compute.Enabled(),
 		commonWorker,
@@ -35,6 +65,12 @@ func New(
 		viper.GetBool(CfgScheduleCheckTxEnabled),
 		viper.GetUint64(cfgMaxTxPoolSize),
 		viper.GetUint64(cfgScheduleTxCacheSize),
+		viper.GetBool(cfgSchedulePersistentTxPool),
+		viper.GetUint64(cfgParallelRuntimeInstances),
+		viper.GetBool(CfgPreExecutionEnabled),
+		viper.GetBool(CfgForensicsEnabled),
+		viper.GetUint64(cfgForensicsMaxRounds),
+		forensicsClients,
 	)
 }
 
@@ -42,6 +78,12 @@ func init() {
 	Flags.Bool(CfgScheduleCheckTxEnabled, false, "Enable checking transactions before scheduling them")
 	Flags.Uint64(cfgMaxTxPoolSize, 10000, "Maximum size of the scheduling transaction pool")
 	Flags.Uint64(cfgScheduleTxCacheSize, 1000, "Cache size of recently scheduled transactions to prevent re-scheduling")
+	Flags.Bool(cfgSchedulePersistentTxPool, false, "Persist queued transactions across node restarts")
+	Flags.Bool(CfgPreExecutionEnabled, false, "Enable speculative pre-execution of the next batch while waiting for round finalization (non-TEE runtimes only)")
+	Flags.Bool(CfgForensicsEnabled, false, "Record per-round execution forensics bundles (inputs, outputs and write logs)")
+	Flags.Uint64(cfgForensicsMaxRounds, 1000, "Number of most recent rounds to keep forensics bundles for (0 keeps all)")
+	Flags.StringSlice(cfgForensicsAllowedClients, []string{}, "Base64 encoded TLS public key(s) of additional clients allowed to fetch forensics bundles")
+	Flags.Uint64(cfgParallelRuntimeInstances, 1, "Number of runtime instances used to execute sub-batches in parallel (parallel scheduling algorithm only)")
 
 	_ = viper.BindPFlags(Flags)
 }
@@ -0,0 +1,27 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/oasisprotocol/oasis-core/go/common/grpc/auth"
+	"github.com/oasisprotocol/oasis-core/go/common/grpc/policy"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/api"
+)
+
+var (
+	_ api.ExecutorWorker = (*forensicsService)(nil)
+	_ auth.ServerAuth    = (*forensicsService)(nil)
+)
+
+// forensicsService is the forensics service exposed to external clients via gRPC.
+type forensicsService struct {
+	w *Worker
+}
+
+func (s *forensicsService) AuthFunc(ctx context.Context, fullMethodName string, req interface{}) error {
+	return policy.GRPCAuthenticationFunction(s.w.grpcPolicy)(ctx, fullMethodName, req)
+}
+
+func (s *forensicsService) GetForensicsBundle(ctx context.Context, request *api.GetForensicsBundleRequest) (*api.ForensicsBundle, error) {
+	return s.w.GetForensicsBundle(ctx, request)
+}
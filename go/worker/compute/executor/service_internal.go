@@ -0,0 +1,18 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/api"
+)
+
+var _ api.ExecutorWorker = (*Worker)(nil)
+
+func (w *Worker) GetForensicsBundle(ctx context.Context, request *api.GetForensicsBundleRequest) (*api.ForensicsBundle, error) {
+	node := w.runtimes[request.RuntimeID]
+	if node == nil {
+		return nil, api.ErrRuntimeNotFound
+	}
+
+	return node.GetForensicsBundle(request.Round)
+}
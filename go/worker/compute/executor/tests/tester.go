@@ -3,6 +3,7 @@ package tests
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 
 	"github.com/oasisprotocol/oasis-core/go/common"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	consensus "github.com/oasisprotocol/oasis-core/go/consensus/api"
 	epochtime "github.com/oasisprotocol/oasis-core/go/epochtime/api"
 	epochtimeTests "github.com/oasisprotocol/oasis-core/go/epochtime/tests"
 	roothash "github.com/oasisprotocol/oasis-core/go/roothash/api"
@@ -17,7 +19,9 @@ import (
 	"github.com/oasisprotocol/oasis-core/go/runtime/transaction"
 	storage "github.com/oasisprotocol/oasis-core/go/storage/api"
 	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/api"
 	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/committee"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/forensics"
 )
 
 const recvTimeout = 5 * time.Second
@@ -152,3 +156,62 @@ blockLoop:
 	err = rtNode.QueueTx(testCall)
 	require.Error(t, err, "QueueCall duplicate transaction")
 }
+
+// ForensicsTests runs the executor forensics bundle tests.
+func ForensicsTests(
+	t *testing.T,
+	worker *executor.Worker,
+	runtimeID common.Namespace,
+	rtNode *committee.Node,
+	roothash roothash.Backend,
+) {
+	require := require.New(t)
+
+	// Subscribe to roothash blocks.
+	blocksCh, sub, err := roothash.WatchBlocks(runtimeID)
+	require.NoError(err, "WatchBlocks")
+	defer sub.Close()
+
+	latestBlk, err := roothash.GetLatestBlock(context.Background(), runtimeID, consensus.HeightLatest)
+	require.NoError(err, "GetLatestBlock")
+
+	testCall := []byte("forensics call at: " + time.Now().String())
+	err = rtNode.QueueTx(testCall)
+	require.NoError(err, "QueueTx")
+
+	// Wait for the block containing the call.
+	var blk *block.Block
+	for blk == nil {
+		select {
+		case annBlk := <-blocksCh:
+			if annBlk.Block.Header.HeaderType != block.Normal || annBlk.Block.Header.Round <= latestBlk.Header.Round {
+				continue
+			}
+			blk = annBlk.Block
+		case <-time.After(recvTimeout):
+			t.Fatalf("failed to receive block")
+		}
+	}
+
+	// The bundle should record the executed batch and the proposed results.
+	bundle, err := worker.GetForensicsBundle(context.Background(), &api.GetForensicsBundleRequest{
+		RuntimeID: runtimeID,
+		Round:     blk.Header.Round,
+	})
+	require.NoError(err, "GetForensicsBundle")
+	require.EqualValues(runtimeID, bundle.RuntimeID)
+	require.EqualValues(blk.Header.Round, bundle.Round)
+	require.EqualValues(transaction.RawBatch{testCall}, bundle.Request.Inputs)
+	require.EqualValues(blk.Header.Round-1, bundle.Request.Block.Header.Round, "bundle should record the parent block")
+	require.EqualValues(blk.Header.IORoot, *bundle.Body.Header.IORoot)
+	require.EqualValues(blk.Header.StateRoot, *bundle.Body.Header.StateRoot)
+	require.NotEmpty(bundle.IOWriteLog, "bundle should record the I/O write log")
+	require.Empty(forensics.Diff(bundle, bundle), "bundle should not differ from itself")
+
+	// Requesting an unknown round should fail.
+	_, err = worker.GetForensicsBundle(context.Background(), &api.GetForensicsBundleRequest{
+		RuntimeID: runtimeID,
+		Round:     blk.Header.Round + 1000,
+	})
+	require.True(errors.Is(err, api.ErrBundleNotFound), "GetForensicsBundle should fail for unknown rounds")
+}
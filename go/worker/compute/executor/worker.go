@@ -5,11 +5,19 @@ import (
 	"fmt"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/grpc"
+	"github.com/oasisprotocol/oasis-core/go/common/grpc/policy"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/runtime/host"
+	runtimeRegistry "github.com/oasisprotocol/oasis-core/go/runtime/registry"
+	"github.com/oasisprotocol/oasis-core/go/runtime/scheduling/persistent"
 	workerCommon "github.com/oasisprotocol/oasis-core/go/worker/common"
 	committeeCommon "github.com/oasisprotocol/oasis-core/go/worker/common/committee"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/api"
 	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/committee"
+	"github.com/oasisprotocol/oasis-core/go/worker/compute/executor/forensics"
 	"github.com/oasisprotocol/oasis-core/go/worker/registration"
 )
 
@@ -17,9 +25,22 @@ import (
 type Worker struct {
 	enabled bool
 
-	scheduleCheckTxEnabled bool
-	scheduleMaxTxPoolSize  uint64
-	scheduleTxCacheSize    uint64
+	dataDir string
+
+	scheduleCheckTxEnabled   bool
+	scheduleMaxTxPoolSize    uint64
+	scheduleTxCacheSize      uint64
+	schedulePersistentTxPool bool
+
+	parallelRuntimeInstances uint64
+
+	preExecutionEnabled bool
+
+	forensicsEnabled   bool
+	forensicsMaxRounds uint64
+	forensicsClients   []signature.PublicKey
+
+	grpcPolicy *policy.DynamicRuntimePolicyChecker
 
 	commonWorker *workerCommon.Worker
 	registration *registration.Worker
@@ -137,6 +158,19 @@ func (w *Worker) GetRuntime(id common.Namespace) *committee.Node {
 	return w.runtimes[id]
 }
 
+// GetHostedRuntime returns the provisioned hosted runtime for the given
+// runtime.
+//
+// In case the runtime with the specified id was not registered or is not
+// yet provisioned it returns nil.
+func (w *Worker) GetHostedRuntime(id common.Namespace) host.Runtime {
+	rt := w.runtimes[id]
+	if rt == nil {
+		return nil
+	}
+	return rt.GetHostedRuntime()
+}
+
 func (w *Worker) registerRuntime(commonNode *committeeCommon.Node) error {
 	id := commonNode.Runtime.ID()
 	w.logger.Info("registering new runtime",
@@ -148,9 +182,55 @@ func (w *Worker) registerRuntime(commonNode *committeeCommon.Node) error {
 		return fmt.Errorf("failed to create role provider: %w", err)
 	}
 
+	var txStore persistent.Store
+	if w.schedulePersistentTxPool {
+		path, err := runtimeRegistry.EnsureRuntimeStateDir(w.dataDir, id)
+		if err != nil {
+			return err
+		}
+		if txStore, err = persistent.New(path, id); err != nil {
+			return fmt.Errorf("failed to create persistent transaction pool: %w", err)
+		}
+		// Avoid waiting for a synced write for each queued transaction.
+		txStore = persistent.NewAsync(txStore)
+	}
+
+	var forensicsStore forensics.Store
+	if w.forensicsEnabled {
+		path, err := runtimeRegistry.EnsureRuntimeStateDir(w.dataDir, id)
+		if err == nil {
+			forensicsStore, err = forensics.New(path, id, w.forensicsMaxRounds)
+		}
+		if err != nil {
+			if txStore != nil {
+				txStore.Close()
+			}
+			return fmt.Errorf("failed to create forensics store: %w", err)
+		}
+	}
+
 	// Create committee node for the given runtime.
-	node, err := committee.NewNode(commonNode, w.commonWorker.GetConfig(), rp, w.scheduleCheckTxEnabled, w.scheduleMaxTxPoolSize, w.scheduleTxCacheSize)
+	node, err := committee.NewNode(
+		commonNode,
+		w.commonWorker.GetConfig(),
+		rp,
+		w.scheduleCheckTxEnabled,
+		w.scheduleMaxTxPoolSize,
+		w.scheduleTxCacheSize,
+		w.parallelRuntimeInstances,
+		txStore,
+		w.preExecutionEnabled,
+		forensicsStore,
+		w.grpcPolicy,
+		w.forensicsClients,
+	)
 	if err != nil {
+		if txStore != nil {
+			txStore.Close()
+		}
+		if forensicsStore != nil {
+			forensicsStore.Close()
+		}
 		return err
 	}
 
@@ -165,6 +245,7 @@ func (w *Worker) registerRuntime(commonNode *committeeCommon.Node) error {
 }
 
 func newWorker(
+	grpcInternal *grpc.Server,
 	dataDir string,
 	enabled bool,
 	commonWorker *workerCommon.Worker,
@@ -172,22 +253,35 @@ func newWorker(
 	scheduleCheckTxEnabled bool,
 	scheduleMaxTxPoolSize uint64,
 	scheduleTxCacheSize uint64,
+	schedulePersistentTxPool bool,
+	parallelRuntimeInstances uint64,
+	preExecutionEnabled bool,
+	forensicsEnabled bool,
+	forensicsMaxRounds uint64,
+	forensicsClients []signature.PublicKey,
 ) (*Worker, error) {
 	ctx, cancelCtx := context.WithCancel(context.Background())
 
 	w := &Worker{
-		enabled:                enabled,
-		commonWorker:           commonWorker,
-		scheduleCheckTxEnabled: scheduleCheckTxEnabled,
-		scheduleMaxTxPoolSize:  scheduleMaxTxPoolSize,
-		scheduleTxCacheSize:    scheduleTxCacheSize,
-		registration:           registration,
-		runtimes:               make(map[common.Namespace]*committee.Node),
-		ctx:                    ctx,
-		cancelCtx:              cancelCtx,
-		quitCh:                 make(chan struct{}),
-		initCh:                 make(chan struct{}),
-		logger:                 logging.GetLogger("worker/executor"),
+		enabled:                  enabled,
+		dataDir:                  dataDir,
+		commonWorker:             commonWorker,
+		scheduleCheckTxEnabled:   scheduleCheckTxEnabled,
+		scheduleMaxTxPoolSize:    scheduleMaxTxPoolSize,
+		scheduleTxCacheSize:      scheduleTxCacheSize,
+		schedulePersistentTxPool: schedulePersistentTxPool,
+		parallelRuntimeInstances: parallelRuntimeInstances,
+		preExecutionEnabled:      preExecutionEnabled,
+		forensicsEnabled:         forensicsEnabled,
+		forensicsMaxRounds:       forensicsMaxRounds,
+		forensicsClients:         forensicsClients,
+		registration:             registration,
+		runtimes:                 make(map[common.Namespace]*committee.Node),
+		ctx:                      ctx,
+		cancelCtx:                cancelCtx,
+		quitCh:                   make(chan struct{}),
+		initCh:                   make(chan struct{}),
+		logger:                   logging.GetLogger("worker/executor"),
 	}
 
 	if enabled {
@@ -195,12 +289,21 @@ func newWorker(
 			panic("common worker should have been enabled for executor worker")
 		}
 
+		// Attach the forensics interface to the external gRPC server.
+		if forensicsEnabled {
+			w.grpcPolicy = policy.NewDynamicRuntimePolicyChecker(api.ServiceName, commonWorker.GrpcPolicyWatcher)
+			api.RegisterService(commonWorker.Grpc.Server(), &forensicsService{w})
+		}
+
 		// Register all configured runtimes.
 		for _, rt := range commonWorker.GetRuntimes() {
 			if err := w.registerRuntime(rt); err != nil {
 				return nil, err
 			}
 		}
+
+		// Attach the executor worker's internal GRPC interface.
+		api.RegisterService(grpcInternal.Server(), w)
 	}
 
 	return w, nil
@@ -536,6 +536,15 @@ func (w *Worker) worker() { // nolint: gocyclo
 				// Worker failed to start or was stopped -- we can no longer service requests.
 				currentStartedEvent = nil
 				w.roleProvider.SetUnavailable()
+			case ev.CrashLoop != nil:
+				// Runtime is crash looping -- deregister from the runtime so that other roles can
+				// still be registered while the runtime is not being restarted.
+				w.logger.Error("key manager runtime is crash looping, deregistering from runtime",
+					"crashes", ev.CrashLoop.Crashes,
+					"last_exit_status", ev.CrashLoop.LastExitStatus,
+				)
+				currentStartedEvent = nil
+				w.roleProvider.SetDisabled()
 			default:
 				// Unknown event.
 				w.logger.Warn("unknown worker event",
@@ -104,6 +104,15 @@ type RoleProvider interface {
 	// SetUnavailable signals that the role provider is unavailable and that node registration
 	// should be blocked until the role provider becomes available.
 	SetUnavailable()
+
+	// SetDisabled signals that the role provider should be excluded from node registration (e.g.,
+	// because its runtime is crash looping). Unlike an unavailable role provider, a disabled role
+	// provider does not block registration of other roles, but the node is deregistered from the
+	// corresponding runtime on the next registration. In case all role providers are disabled, the
+	// node is not re-registered and its registration lapses.
+	//
+	// The role provider is enabled again by calling SetAvailable or SetAvailableWithCallback.
+	SetDisabled()
 }
 
 type roleProvider struct {
@@ -116,6 +125,7 @@ type roleProvider struct {
 	runtimeID *common.Namespace
 	hook      RegisterNodeHook
 	cb        RegisterNodeCallback
+	disabled  bool
 }
 
 func (rp *roleProvider) SetAvailable(hook RegisterNodeHook) {
@@ -127,6 +137,7 @@ func (rp *roleProvider) SetAvailableWithCallback(hook RegisterNodeHook, cb Regis
 	rp.version++
 	rp.hook = hook
 	rp.cb = cb
+	rp.disabled = false
 	rp.Unlock()
 
 	rp.w.registerCh <- struct{}{}
@@ -136,6 +147,17 @@ func (rp *roleProvider) SetUnavailable() {
 	rp.SetAvailable(nil)
 }
 
+func (rp *roleProvider) SetDisabled() {
+	rp.Lock()
+	rp.version++
+	rp.hook = nil
+	rp.cb = nil
+	rp.disabled = true
+	rp.Unlock()
+
+	rp.w.registerCh <- struct{}{}
+}
+
 // Worker is a service handling worker node registration.
 type Worker struct { // nolint: maligned
 	sync.RWMutex
@@ -325,7 +347,7 @@ Loop:
 
 		// If there are any role providers which are still not ready, we must wait for more
 		// notifications.
-		hooks, cbs, vers := func() (h []RegisterNodeHook, cbs []RegisterNodeCallback, vers []uint64) {
+		hooks, cbs, vers, ready := func() (h []RegisterNodeHook, cbs []RegisterNodeCallback, vers []uint64, ready bool) {
 			w.RLock()
 			defer w.RUnlock()
 
@@ -335,10 +357,17 @@ Loop:
 				hook := rp.hook
 				cb := rp.cb
 				ver := rp.version
+				disabled := rp.disabled
 				rp.Unlock()
 
+				// Disabled role providers are excluded from registration.
+				if disabled {
+					cbs = append(cbs, nil)
+					vers = append(vers, ver)
+					continue
+				}
 				if hook == nil {
-					return nil, nil, nil
+					return nil, nil, nil, false
 				}
 
 				h = append(h, func(n *node.Node) error {
@@ -348,9 +377,16 @@ Loop:
 				cbs = append(cbs, cb)
 				vers = append(vers, ver)
 			}
+			ready = true
 			return
 		}()
-		if hooks == nil {
+		if !ready {
+			continue Loop
+		}
+		if len(hooks) == 0 {
+			// All role providers are disabled so there are no roles to register with. Let the
+			// existing registration lapse so that the node is deregistered.
+			w.logger.Warn("not re-registering as all role providers are disabled")
 			continue Loop
 		}
 
@@ -0,0 +1,84 @@
+package committee
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	nodedb "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
+)
+
+// gcPause is the pause between batches of nodes processed by the online garbage collector
+// so that it runs at a low priority compared to regular storage operations.
+const gcPause = 10 * time.Millisecond
+
+var (
+	storageWorkerGCRuns = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_worker_storage_gc_runs",
+			Help: "Number of completed node database garbage collection passes.",
+		},
+		[]string{"runtime"},
+	)
+
+	storageWorkerGCUnreachableNodes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_worker_storage_gc_unreachable_nodes",
+			Help: "Number of unreachable nodes removed by the node database garbage collector.",
+		},
+		[]string{"runtime"},
+	)
+
+	storageWorkerGCReclaimedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_worker_storage_gc_reclaimed_bytes",
+			Help: "Estimated number of bytes reclaimed by the node database garbage collector.",
+		},
+		[]string{"runtime"},
+	)
+
+	storageWorkerGCMissingNodes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "oasis_worker_storage_gc_missing_nodes",
+			Help: "Number of nodes referenced by roots but missing from the node database in the last garbage collection pass.",
+		},
+		[]string{"runtime"},
+	)
+)
+
+// gcWorker periodically runs the node database garbage collector.
+func (n *Node) gcWorker() {
+	n.logger.Info("starting node database garbage collector",
+		"interval", n.gcInterval,
+	)
+
+	ticker := time.NewTicker(n.gcInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		res, err := n.localStorage.NodeDB().CollectGarbage(n.ctx, &nodedb.GCOptions{
+			Pause: gcPause,
+		})
+		if err != nil {
+			if n.ctx.Err() != nil {
+				return
+			}
+			n.logger.Error("failed to collect garbage",
+				"err", err,
+			)
+			continue
+		}
+
+		labels := n.getMetricLabels()
+		storageWorkerGCRuns.With(labels).Inc()
+		storageWorkerGCUnreachableNodes.With(labels).Add(float64(res.UnreachableNodes))
+		storageWorkerGCReclaimedBytes.With(labels).Add(float64(res.ReclaimedBytes))
+		storageWorkerGCMissingNodes.With(labels).Set(float64(res.MissingNodes))
+	}
+}
@@ -74,6 +74,12 @@ var (
 		storageWorkerLastFullRound,
 		storageWorkerLastSyncedRound,
 		storageWorkerLastPendingRound,
+		storageWorkerGCRuns,
+		storageWorkerGCUnreachableNodes,
+		storageWorkerGCReclaimedBytes,
+		storageWorkerGCMissingNodes,
+		storageWorkerRepairs,
+		storageWorkerRepairFailures,
 	}
 
 	prometheusOnce sync.Once
@@ -211,6 +217,10 @@ type Node struct {
 	checkpointSyncDisabled bool
 	checkpointSyncForced   bool
 
+	gcInterval time.Duration
+
+	repairQueue *repairQueue
+
 	syncedLock  sync.RWMutex
 	syncedState watcherState
 
@@ -238,6 +248,8 @@ func NewNode(
 	localStorage storageApi.LocalBackend,
 	checkpointerCfg *checkpoint.CheckpointerConfig,
 	checkpointSyncDisabled bool,
+	gcInterval time.Duration,
+	repairEnabled bool,
 ) (*Node, error) {
 	n := &Node{
 		commonNode: commonNode,
@@ -257,6 +269,8 @@ func NewNode(
 
 		checkpointSyncDisabled: checkpointSyncDisabled,
 
+		gcInterval: gcInterval,
+
 		blockCh:    channels.NewInfiniteChannel(),
 		diffCh:     make(chan *fetchedDiff),
 		finalizeCh: make(chan *blockSummary),
@@ -266,6 +280,9 @@ func NewNode(
 		workerQuitCh:    make(chan struct{}),
 		initCh:          make(chan struct{}),
 	}
+	if repairEnabled {
+		n.repairQueue = newRepairQueue()
+	}
 
 	n.syncedState.LastBlock.Round = defaultUndefinedRound
 	rtID := commonNode.Runtime.ID()
@@ -939,6 +956,25 @@ func (n *Node) worker() { // nolint: gocyclo
 	}
 	close(n.initCh)
 
+	// Start the node database garbage collector if enabled. Background workers are waited for
+	// before the worker terminates so that they never run against a closed node database.
+	var backgroundGroup sync.WaitGroup
+	if n.gcInterval > 0 {
+		backgroundGroup.Add(1)
+		go func() {
+			defer backgroundGroup.Done()
+			n.gcWorker()
+		}()
+	}
+	// Start the repair worker if enabled.
+	if n.repairQueue != nil {
+		backgroundGroup.Add(1)
+		go func() {
+			defer backgroundGroup.Done()
+			n.repairWorker()
+		}()
+	}
+
 	// Main processing loop. When a new block comes in, its state and io roots are inspected and their
 	// writelogs fetched from remote storage nodes in case we don't have them locally yet. Fetches are
 	// asynchronous and, once complete, trigger local Apply operations. These are serialized
@@ -1143,6 +1179,7 @@ mainLoop:
 	}
 
 	fetcherGroup.Wait()
+	backgroundGroup.Wait()
 	// blockCh will be garbage-collected without being closed. It can potentially still contain
 	// some new blocks, but only as many as were already in-flight at the point when the main
 	// context was canceled.
@@ -0,0 +1,294 @@
+package committee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	storageApi "github.com/oasisprotocol/oasis-core/go/storage/api"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs"
+	nodedb "github.com/oasisprotocol/oasis-core/go/storage/mkvs/db/api"
+	mkvsNode "github.com/oasisprotocol/oasis-core/go/storage/mkvs/node"
+	"github.com/oasisprotocol/oasis-core/go/storage/mkvs/writelog"
+)
+
+const (
+	// repairQueueSize is the maximum number of roots queued for repair.
+	repairQueueSize = 128
+	// maxRepairChainLength is the maximum number of rounds that are walked back in order to find
+	// a state root present in the local node database.
+	maxRepairChainLength = 16
+)
+
+var (
+	errRootNotFinalized = errors.New("root is not finalized")
+
+	storageWorkerRepairs = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_worker_storage_repairs",
+			Help: "Number of missing or corrupt finalized roots repaired by fetching write logs from other storage nodes.",
+		},
+		[]string{"runtime"},
+	)
+
+	storageWorkerRepairFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "oasis_worker_storage_repair_failures",
+			Help: "Number of failed attempts to repair a finalized root.",
+		},
+		[]string{"runtime"},
+	)
+)
+
+// repairQueue is a queue of roots pending repair.
+type repairQueue struct {
+	sync.Mutex
+
+	ch     chan storageApi.Root
+	queued map[storageApi.Root]struct{}
+}
+
+func newRepairQueue() *repairQueue {
+	return &repairQueue{
+		ch:     make(chan storageApi.Root, repairQueueSize),
+		queued: make(map[storageApi.Root]struct{}),
+	}
+}
+
+// RequestRepair requests repair of the given root in case it is a finalized root missing from
+// the local node database or having missing nodes. It is called when the node fails to serve a
+// root to a client.
+//
+// Requests are ignored in case repair is disabled or the repair queue is full.
+func (n *Node) RequestRepair(root storageApi.Root) {
+	q := n.repairQueue
+	if q == nil {
+		return
+	}
+
+	q.Lock()
+	defer q.Unlock()
+
+	if _, ok := q.queued[root]; ok {
+		return
+	}
+	select {
+	case q.ch <- root:
+		q.queued[root] = struct{}{}
+	default:
+	}
+}
+
+// repairWorker repairs roots requested via RequestRepair.
+func (n *Node) repairWorker() {
+	n.logger.Info("starting storage repair worker")
+
+	q := n.repairQueue
+	for {
+		var root storageApi.Root
+		select {
+		case <-n.ctx.Done():
+			return
+		case root = <-q.ch:
+		}
+
+		err := n.repairRoot(n.ctx, root)
+		switch {
+		case err == nil:
+		case errors.Is(err, errRootNotFinalized):
+			n.logger.Debug("not repairing root that has not been finalized",
+				"root", root,
+			)
+		default:
+			storageWorkerRepairFailures.With(n.getMetricLabels()).Inc()
+			n.logger.Error("failed to repair root",
+				"err", err,
+				"root", root,
+			)
+		}
+
+		q.Lock()
+		delete(q.queued, root)
+		q.Unlock()
+	}
+}
+
+// repairRoot fetches the write logs needed to reconstruct the given root in case it is a root of
+// a locally finalized round that is missing from the local node database or has missing nodes.
+//
+// Roots of rounds that have not yet been finalized locally are left to the sync loop.
+func (n *Node) repairRoot(ctx context.Context, root storageApi.Root) error {
+	ndb := n.localStorage.NodeDB()
+	latestVersion, err := ndb.GetLatestVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest version: %w", err)
+	}
+	if root.Version > latestVersion {
+		return fmt.Errorf("%w: round %d not finalized locally", errRootNotFinalized, root.Version)
+	}
+
+	intact, err := isRootIntact(ctx, ndb, root)
+	if err != nil {
+		return err
+	}
+	if intact {
+		return nil
+	}
+
+	chain, err := n.repairChain(ctx, root)
+	if err != nil {
+		return err
+	}
+
+	// Prioritize committee nodes.
+	if committee := n.commonNode.Group.GetEpochSnapshot().GetStorageCommittee(); committee != nil {
+		ctx = storageApi.WithNodePriorityHintFromMap(ctx, committee.PublicKeys)
+	}
+	for _, pair := range chain {
+		srcRoot, dstRoot := pair[0], pair[1]
+
+		var wl storageApi.WriteLog
+		if !srcRoot.Hash.Equal(&dstRoot.Hash) {
+			if wl, err = n.getDiff(ctx, srcRoot, dstRoot); err != nil {
+				return fmt.Errorf("failed to get write log for root %s: %w", dstRoot, err)
+			}
+		}
+
+		if err = repairFromWriteLog(ctx, ndb, srcRoot, dstRoot, wl); err != nil {
+			return fmt.Errorf("failed to apply write log for root %s: %w", dstRoot, err)
+		}
+	}
+
+	storageWorkerRepairs.With(n.getMetricLabels()).Inc()
+	n.logger.Info("repaired finalized root",
+		"root", root,
+		"rounds", len(chain),
+	)
+	return nil
+}
+
+// isRootIntact checks whether the given root and all of its nodes are present in the node database.
+func isRootIntact(ctx context.Context, ndb nodedb.NodeDB, root storageApi.Root) (bool, error) {
+	if !ndb.HasRoot(root) {
+		return false, nil
+	}
+	err := nodedb.Visit(ctx, ndb, root, func(context.Context, mkvsNode.Node) bool {
+		return true
+	})
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, nodedb.ErrNodeNotFound):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to verify root %s: %w", root, err)
+	}
+}
+
+// repairFromWriteLog reconstructs the destination root by applying the given write log to the
+// source root, storing all nodes of the destination root into its already finalized version.
+func repairFromWriteLog(ctx context.Context, ndb nodedb.NodeDB, srcRoot, dstRoot storageApi.Root, wl storageApi.WriteLog) error {
+	tree := mkvs.NewWithRoot(nil, ndb, srcRoot)
+	defer tree.Close()
+
+	if err := tree.ApplyWriteLog(ctx, writelog.NewStaticIterator(wl)); err != nil {
+		return err
+	}
+	_, err := tree.CommitKnown(ctx, dstRoot, mkvs.Repair())
+	return err
+}
+
+// repairChain returns the sequence of (source, destination) root pairs that need to be applied
+// in order to reconstruct the given finalized root, starting with the oldest pair.
+//
+// I/O roots are derived from an empty root in the same round, while state roots are derived
+// from the previous round's state root, so previous rounds are walked back using the runtime
+// block history until a state root present in the local node database is found.
+func (n *Node) repairChain(ctx context.Context, root storageApi.Root) ([][2]storageApi.Root, error) {
+	history := n.commonNode.Runtime.History()
+	blk, err := history.GetBlock(ctx, root.Version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errRootNotFinalized, err)
+	}
+
+	emptyRoot := storageApi.Root{
+		Namespace: root.Namespace,
+		Version:   root.Version,
+	}
+	emptyRoot.Hash.Empty()
+
+	roots := blk.Header.StorageRoots()
+	ioRoot, stateRoot := roots[0], roots[1]
+	switch {
+	case ioRoot.Equal(&root):
+		return [][2]storageApi.Root{{emptyRoot, root}}, nil
+	case stateRoot.Equal(&root):
+	default:
+		return nil, errRootNotFinalized
+	}
+
+	ndb := n.localStorage.NodeDB()
+	var chain [][2]storageApi.Root
+	for dst := root; ; {
+		if len(chain) >= maxRepairChainLength {
+			return nil, fmt.Errorf("no state root available within %d rounds before round %d", maxRepairChainLength, root.Version)
+		}
+
+		if blk.Header.PreviousHash.IsEmpty() {
+			// The genesis state root is derived from an empty root in the same round.
+			emptyRoot.Version = dst.Version
+			chain = append(chain, [2]storageApi.Root{emptyRoot, dst})
+			break
+		}
+
+		if blk, err = history.GetBlock(ctx, dst.Version-1); err != nil {
+			return nil, fmt.Errorf("failed to get block for round %d: %w", dst.Version-1, err)
+		}
+		src := blk.Header.StorageRoots()[1]
+		chain = append(chain, [2]storageApi.Root{src, dst})
+		var intact bool
+		if intact, err = isRootIntact(ctx, ndb, src); err != nil {
+			return nil, err
+		}
+		if intact {
+			break
+		}
+		dst = src
+	}
+
+	// Reverse the chain so that older roots are repaired first.
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// getDiff fetches the write log transforming the source root into the destination root from
+// other storage nodes.
+func (n *Node) getDiff(ctx context.Context, srcRoot, dstRoot storageApi.Root) (storageApi.WriteLog, error) {
+	it, err := n.storageClient.GetDiff(ctx, &storageApi.GetDiffRequest{StartRoot: srcRoot, EndRoot: dstRoot})
+	if err != nil {
+		return nil, err
+	}
+
+	var wl storageApi.WriteLog
+	for {
+		more, err := it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			break
+		}
+
+		entry, err := it.Value()
+		if err != nil {
+			return nil, err
+		}
+		wl = append(wl, entry)
+	}
+	return wl, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/oasisprotocol/oasis-core/go/common"
+	cmnBadger "github.com/oasisprotocol/oasis-core/go/common/badger"
 	"github.com/oasisprotocol/oasis-core/go/common/identity"
 
 	cmdFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
@@ -31,6 +32,12 @@ const (
 	// CfgCheckpointSyncDisabled disables syncing from checkpoints on worker startup.
 	CfgWorkerCheckpointSyncDisabled = "worker.storage.checkpoint_sync.disabled"
 
+	// CfgWorkerGCInterval configures the node database garbage collection interval.
+	CfgWorkerGCInterval = "worker.storage.gc.interval"
+	// CfgWorkerRepairEnabled enables repair of finalized roots that are missing from the local
+	// node database or have missing nodes when they are requested by clients.
+	CfgWorkerRepairEnabled = "worker.storage.repair.enabled"
+
 	// CfgWorkerDebugIgnoreApply is a debug option that makes the worker ignore
 	// all apply operations.
 	CfgWorkerDebugIgnoreApply = "worker.debug.storage.ignore_apply"
@@ -57,6 +64,15 @@ func NewLocalBackend(
 	namespace common.Namespace,
 	identity *identity.Identity,
 ) (api.LocalBackend, error) {
+	encryptionKey, err := cmnBadger.NewEncryptionKey(
+		cmdFlags.DBEncryptionKeySource(),
+		cmdFlags.DBEncryptionKeyFile(),
+		identity.NodeSigner,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to load database encryption key: %w", err)
+	}
+
 	cfg := &api.Config{
 		Backend:            strings.ToLower(viper.GetString(CfgBackend)),
 		DB:                 dataDir,
@@ -65,12 +81,10 @@ func NewLocalBackend(
 		InsecureSkipChecks: viper.GetBool(cfgInsecureSkipChecks) && cmdFlags.DebugDontBlameOasis(),
 		Namespace:          namespace,
 		MaxCacheSize:       int64(viper.GetSizeInBytes(CfgMaxCacheSize)),
+		EncryptionKey:      encryptionKey,
 	}
 
-	var (
-		err  error
-		impl api.Backend
-	)
+	var impl api.Backend
 	switch cfg.Backend {
 	case database.BackendNameBadgerDB:
 		cfg.DB = filepath.Join(cfg.DB, database.DefaultFileName(cfg.Backend))
@@ -96,6 +110,8 @@ func init() {
 	Flags.Bool(CfgWorkerCheckpointerDisabled, false, "Disable the storage checkpointer")
 	Flags.Duration(CfgWorkerCheckpointCheckInterval, 1*time.Minute, "Storage checkpointer check interval")
 	Flags.Bool(CfgWorkerCheckpointSyncDisabled, false, "Disable initial storage sync from checkpoints")
+	Flags.Duration(CfgWorkerGCInterval, 0, "Storage node database garbage collection interval (0 disables)")
+	Flags.Bool(CfgWorkerRepairEnabled, false, "Repair finalized roots missing from the local node database or having missing nodes")
 
 	Flags.Bool(CfgWorkerDebugIgnoreApply, false, "Ignore Apply operations (for debugging purposes)")
 	_ = Flags.MarkHidden(CfgWorkerDebugIgnoreApply)
@@ -55,25 +55,42 @@ func (s *storageService) getConfig(ctx context.Context, ns common.Namespace) (*r
 	return &rtDesc.Storage, nil
 }
 
+// maybeRequestRepair requests repair of the given root in case the error indicates that it is
+// missing from the local node database.
+func (s *storageService) maybeRequestRepair(root api.Root, err error) {
+	if !errors.Is(err, api.ErrRootNotFound) && !errors.Is(err, api.ErrNodeNotFound) {
+		return
+	}
+	if n := s.w.GetRuntime(root.Namespace); n != nil {
+		n.RequestRepair(root)
+	}
+}
+
 func (s *storageService) SyncGet(ctx context.Context, request *api.GetRequest) (*api.ProofResponse, error) {
 	if err := s.ensureInitialized(ctx); err != nil {
 		return nil, err
 	}
-	return s.storage.SyncGet(ctx, request)
+	rsp, err := s.storage.SyncGet(ctx, request)
+	s.maybeRequestRepair(request.Tree.Root, err)
+	return rsp, err
 }
 
 func (s *storageService) SyncGetPrefixes(ctx context.Context, request *api.GetPrefixesRequest) (*api.ProofResponse, error) {
 	if err := s.ensureInitialized(ctx); err != nil {
 		return nil, err
 	}
-	return s.storage.SyncGetPrefixes(ctx, request)
+	rsp, err := s.storage.SyncGetPrefixes(ctx, request)
+	s.maybeRequestRepair(request.Tree.Root, err)
+	return rsp, err
 }
 
 func (s *storageService) SyncIterate(ctx context.Context, request *api.IterateRequest) (*api.ProofResponse, error) {
 	if err := s.ensureInitialized(ctx); err != nil {
 		return nil, err
 	}
-	return s.storage.SyncIterate(ctx, request)
+	rsp, err := s.storage.SyncIterate(ctx, request)
+	s.maybeRequestRepair(request.Tree.Root, err)
+	return rsp, err
 }
 
 func (s *storageService) Apply(ctx context.Context, request *api.ApplyRequest) ([]*api.Receipt, error) {
@@ -126,7 +143,9 @@ func (s *storageService) GetDiff(ctx context.Context, request *api.GetDiffReques
 	if err := s.ensureInitialized(ctx); err != nil {
 		return nil, err
 	}
-	return s.storage.GetDiff(ctx, request)
+	it, err := s.storage.GetDiff(ctx, request)
+	s.maybeRequestRepair(request.EndRoot, err)
+	return it, err
 }
 
 func (s *storageService) GetCheckpoints(ctx context.Context, request *checkpoint.GetCheckpointsRequest) ([]*checkpoint.Metadata, error) {
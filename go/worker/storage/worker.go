@@ -139,6 +139,8 @@ func (s *Worker) registerRuntime(dataDir string, commonNode *committeeCommon.Nod
 		localStorage,
 		checkpointerCfg,
 		viper.GetBool(CfgWorkerCheckpointSyncDisabled),
+		viper.GetDuration(CfgWorkerGCInterval),
+		viper.GetBool(CfgWorkerRepairEnabled),
 	)
 	if err != nil {
 		return err
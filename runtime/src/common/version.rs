@@ -55,6 +55,6 @@ impl From<u64> for Version {
 // the worker host.
 pub const PROTOCOL_VERSION: Version = Version {
     major: 2,
-    minor: 0,
+    minor: 1,
     patch: 0,
 };
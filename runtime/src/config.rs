@@ -0,0 +1,12 @@
+//! Runtime configuration.
+use crate::{common::version::Version, consensus::tendermint::verifier::TrustRoot};
+
+/// Global runtime configuration.
+#[derive(Clone, Debug)]
+pub struct Config {
+    /// Semantic runtime version.
+    pub version: Version,
+    /// Trust root of the consensus light block verifier. Consensus layer state is only made
+    /// available to the runtime in case a trust root is configured.
+    pub consensus_trust_root: Option<TrustRoot>,
+}
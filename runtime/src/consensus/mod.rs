@@ -1,6 +1,19 @@
 //! Consensus service interfaces.
+use serde::{Deserialize, Serialize};
 
 pub mod address;
 pub mod registry;
 pub mod roothash;
 pub mod staking;
+pub mod state;
+pub mod tendermint;
+
+/// A consensus light block.
+#[derive(Clone, Debug, Default, PartialEq, Eq, Serialize, Deserialize)]
+pub struct LightBlock {
+    /// Block height.
+    pub height: i64,
+    /// Consensus backend specific light block.
+    #[serde(with = "serde_bytes")]
+    pub meta: Vec<u8>,
+}
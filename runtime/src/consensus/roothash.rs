@@ -12,6 +12,7 @@ use crate::{
         cbor,
         crypto::{hash::Hash, signature::SignatureBundle},
         namespace::Namespace,
+        quantity::Quantity,
     },
     consensus::staking,
 };
@@ -62,6 +63,19 @@ pub enum Message {
         #[serde(flatten)]
         msg: StakingMessage,
     },
+    #[serde(rename = "runtime")]
+    Runtime {
+        v: u16,
+        /// Destination runtime.
+        to: Namespace,
+        /// Fee paid to the destination runtime.
+        fee: Quantity,
+        /// Message payload.
+        #[serde(default)]
+        #[serde(with = "serde_bytes")]
+        #[serde(skip_serializing_if = "Vec::is_empty")]
+        data: Vec<u8>,
+    },
 }
 
 impl Message {
@@ -83,6 +97,41 @@ pub enum StakingMessage {
     Withdraw(staking::Withdraw),
 }
 
+/// A message sent to the runtime by another runtime.
+#[derive(Clone, Debug, Default, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub struct IncomingMessage {
+    /// Sequence number of the message in the incoming message queue.
+    #[serde(default)]
+    pub id: u64,
+    /// Source runtime.
+    pub from: Namespace,
+    /// Round of the source runtime in which the message was emitted.
+    #[serde(default)]
+    pub source_round: u64,
+    /// Index of the message among the messages emitted in the source round.
+    #[serde(default)]
+    pub source_index: u32,
+    /// Fee paid by the source runtime.
+    #[serde(default)]
+    pub fee: Quantity,
+    /// Message payload.
+    #[serde(default)]
+    #[serde(with = "serde_bytes")]
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub data: Vec<u8>,
+}
+
+impl IncomingMessage {
+    /// Returns a hash of provided incoming runtime messages.
+    pub fn in_messages_hash(msgs: &[IncomingMessage]) -> Hash {
+        if msgs.is_empty() {
+            // Special case if there are no messages.
+            return Hash::empty_hash();
+        }
+        Hash::digest_bytes(&cbor::to_vec(&msgs))
+    }
+}
+
 /// Result of a message being processed by the consensus layer.
 #[derive(Clone, Debug, Default, PartialEq, Eq, Hash, Serialize, Deserialize)]
 pub struct MessageEvent {
@@ -156,6 +205,13 @@ pub struct ComputeResultsHeader {
     /// Hash of messages sent from this batch.
     #[serde(skip_serializing_if = "Option::is_none")]
     pub messages_hash: Option<Hash>,
+    /// Hash of the incoming messages processed in this batch.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub in_msgs_hash: Option<Hash>,
+    /// Number of incoming messages processed in this batch.
+    #[serde(default)]
+    #[serde(skip_serializing_if = "num_traits::Zero::is_zero")]
+    pub in_msgs_count: u32,
 }
 
 impl ComputeResultsHeader {
@@ -211,6 +267,7 @@ mod tests {
             io_root: Some(Hash::empty_hash()),
             state_root: Some(Hash::empty_hash()),
             messages_hash: Some(Hash::empty_hash()),
+            ..Default::default()
         };
         assert_eq!(
             populated.encoded_hash(),
@@ -4,6 +4,8 @@
 //!
 //! This **MUST** be kept in sync with go/staking/api.
 //!
+use std::collections::HashMap;
+
 use serde::{Deserialize, Serialize};
 
 use crate::{common::quantity::Quantity, consensus::address::Address};
@@ -21,3 +23,42 @@ pub struct Withdraw {
     pub from: Address,
     pub amount: Quantity,
 }
+
+/// A stake share pool.
+#[derive(Clone, Debug, Default, PartialEq, Eq, Serialize, Deserialize)]
+pub struct SharePool {
+    #[serde(default)]
+    pub balance: Quantity,
+    #[serde(default)]
+    pub total_shares: Quantity,
+}
+
+/// A general (end-user) account.
+#[derive(Clone, Debug, Default, PartialEq, Eq, Serialize, Deserialize)]
+pub struct GeneralAccount {
+    #[serde(default)]
+    pub balance: Quantity,
+    #[serde(default)]
+    pub nonce: u64,
+    #[serde(default)]
+    pub allowances: HashMap<Address, Quantity>,
+}
+
+/// An escrow account the balance of which is subject to special delegation provisions and a
+/// debonding period.
+#[derive(Clone, Debug, Default, PartialEq, Eq, Serialize, Deserialize)]
+pub struct EscrowAccount {
+    #[serde(default)]
+    pub active: SharePool,
+    #[serde(default)]
+    pub debonding: SharePool,
+}
+
+/// An entry in the staking ledger.
+#[derive(Clone, Debug, Default, PartialEq, Eq, Serialize, Deserialize)]
+pub struct Account {
+    #[serde(default)]
+    pub general: GeneralAccount,
+    #[serde(default)]
+    pub escrow: EscrowAccount,
+}
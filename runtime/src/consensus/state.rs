@@ -0,0 +1,57 @@
+//! Consensus state access.
+use std::sync::Arc;
+
+use anyhow::{anyhow, Result};
+use io_context::Context;
+
+use crate::{
+    common::cbor,
+    consensus::{address::Address, staking::Account},
+    protocol::Protocol,
+    storage::mkvs::{sync::HostReadSyncer, Root, Tree},
+    types::HostStorageEndpoint,
+};
+
+/// Staking account key prefix (see go/consensus/tendermint/apps/staking/state).
+const STAKING_ACCOUNT_KEY_PREFIX: u8 = 0x50;
+
+/// Read-only view of the consensus state at a given root.
+///
+/// All reads are served by the host and the returned proofs are verified against the
+/// consensus state root, which the dispatcher verifies against the header of a verified
+/// consensus light block before creating the view.
+pub struct ConsensusState {
+    mkvs: Tree,
+}
+
+impl ConsensusState {
+    /// Create a new consensus state view at the given root.
+    pub fn new(protocol: Arc<Protocol>, root: Root) -> Self {
+        let read_syncer = HostReadSyncer::new(protocol, HostStorageEndpoint::Consensus);
+        Self {
+            mkvs: Tree::make()
+                .with_capacity(10_000, 1_000_000)
+                .with_root(root)
+                .new(Box::new(read_syncer)),
+        }
+    }
+
+    /// Fetch the raw value stored under the given key.
+    pub fn get(&self, ctx: Context, key: &[u8]) -> Result<Option<Vec<u8>>> {
+        self.mkvs.get(ctx, key)
+    }
+
+    /// Fetch the staking account for the given address.
+    ///
+    /// Accounts that do not exist in the consensus state are returned as empty accounts.
+    pub fn account(&self, ctx: Context, address: &Address) -> Result<Account> {
+        let mut key = vec![STAKING_ACCOUNT_KEY_PREFIX];
+        key.extend_from_slice(address.as_ref());
+
+        let raw = match self.get(ctx, &key)? {
+            Some(raw) => raw,
+            None => return Ok(Account::default()),
+        };
+        cbor::from_slice(&raw).map_err(|err| anyhow!("malformed staking account: {}", err))
+    }
+}
@@ -0,0 +1,425 @@
+//! Tendermint consensus backend light block handling.
+//!
+//! # Note
+//!
+//! This **MUST** be kept in sync with the protocol buffers definitions and hashing rules of the
+//! Tendermint version used by go/consensus/tendermint.
+//!
+use std::collections::HashSet;
+
+use anyhow::{anyhow, Result};
+use ed25519_dalek::{self, ed25519::signature::Signature as _};
+use sha2::{Digest, Sha256};
+
+use self::proto::{
+    put_bytes_field, put_fixed64_field, put_message_field, put_varint, put_varint_field, Reader,
+};
+
+mod proto;
+pub mod verifier;
+
+/// Size of an Ed25519 public key.
+const ED25519_PUBLIC_KEY_SIZE: usize = 32;
+
+/// Commit signature block identifier flag for validators that voted for the block.
+const BLOCK_ID_FLAG_COMMIT: u64 = 2;
+/// Signed message type of precommit votes.
+const SIGNED_MSG_TYPE_PRECOMMIT: u64 = 2;
+
+/// A block identifier.
+#[derive(Clone, Debug, Default, PartialEq, Eq)]
+pub struct BlockId {
+    pub hash: Vec<u8>,
+    pub part_set_total: u32,
+    pub part_set_hash: Vec<u8>,
+}
+
+impl BlockId {
+    fn decode(raw: &[u8]) -> Result<Self> {
+        let mut id = BlockId::default();
+        let mut r = Reader::new(raw);
+        while let Some((field, value)) = r.next()? {
+            match field {
+                1 => id.hash = value.bytes()?.to_vec(),
+                2 => {
+                    let mut psh = Reader::new(value.bytes()?);
+                    while let Some((field, value)) = psh.next()? {
+                        match field {
+                            1 => id.part_set_total = value.varint()? as u32,
+                            2 => id.part_set_hash = value.bytes()?.to_vec(),
+                            _ => {}
+                        }
+                    }
+                }
+                _ => {}
+            }
+        }
+        Ok(id)
+    }
+
+    /// Encode the block identifier as a canonical block identifier used in vote sign bytes.
+    fn encode_canonical(&self) -> Vec<u8> {
+        let mut psh = Vec::new();
+        put_varint_field(&mut psh, 1, self.part_set_total as u64);
+        put_bytes_field(&mut psh, 2, &self.part_set_hash);
+
+        let mut out = Vec::new();
+        put_bytes_field(&mut out, 1, &self.hash);
+        put_message_field(&mut out, 2, &psh);
+        out
+    }
+}
+
+/// A block header.
+#[derive(Clone, Debug, Default, PartialEq, Eq)]
+pub struct Header {
+    pub chain_id: String,
+    pub height: i64,
+    pub validators_hash: Vec<u8>,
+    pub next_validators_hash: Vec<u8>,
+    pub app_hash: Vec<u8>,
+
+    /// Field encodings in header hashing order.
+    hash_fields: Vec<Vec<u8>>,
+}
+
+impl Header {
+    fn decode(raw: &[u8]) -> Result<Self> {
+        // Embedded messages are hashed as encoded while all other fields are hashed encoded as
+        // the corresponding protocol buffers wrapper type (e.g., BytesValue).
+        let mut fields: Vec<Vec<u8>> = vec![Vec::new(); 14];
+        let mut hdr = Header::default();
+        let mut r = Reader::new(raw);
+        while let Some((field, value)) = r.next()? {
+            match field {
+                1 | 4 | 5 => fields[field as usize - 1] = value.bytes()?.to_vec(),
+                2 => {
+                    let chain_id = value.bytes()?;
+                    hdr.chain_id = String::from_utf8(chain_id.to_vec())
+                        .map_err(|_| anyhow!("tendermint: malformed chain id"))?;
+                    put_bytes_field(&mut fields[1], 1, chain_id);
+                }
+                3 => {
+                    let height = value.varint()?;
+                    hdr.height = height as i64;
+                    put_varint_field(&mut fields[2], 1, height);
+                }
+                6..=14 => {
+                    let v = value.bytes()?;
+                    match field {
+                        8 => hdr.validators_hash = v.to_vec(),
+                        9 => hdr.next_validators_hash = v.to_vec(),
+                        11 => hdr.app_hash = v.to_vec(),
+                        _ => {}
+                    }
+                    put_bytes_field(&mut fields[field as usize - 1], 1, v);
+                }
+                _ => {}
+            }
+        }
+        hdr.hash_fields = fields;
+        Ok(hdr)
+    }
+
+    /// Compute the header hash.
+    pub fn hash(&self) -> Vec<u8> {
+        merkle_hash(&self.hash_fields)
+    }
+}
+
+/// A commit signature.
+#[derive(Clone, Debug, Default, PartialEq, Eq)]
+struct CommitSig {
+    block_id_flag: u64,
+    validator_address: Vec<u8>,
+    timestamp: Vec<u8>,
+    signature: Vec<u8>,
+}
+
+impl CommitSig {
+    fn decode(raw: &[u8]) -> Result<Self> {
+        let mut sig = CommitSig::default();
+        let mut r = Reader::new(raw);
+        while let Some((field, value)) = r.next()? {
+            match field {
+                1 => sig.block_id_flag = value.varint()?,
+                2 => sig.validator_address = value.bytes()?.to_vec(),
+                3 => sig.timestamp = value.bytes()?.to_vec(),
+                4 => sig.signature = value.bytes()?.to_vec(),
+                _ => {}
+            }
+        }
+        Ok(sig)
+    }
+}
+
+/// A commit for a block.
+#[derive(Clone, Debug, Default, PartialEq, Eq)]
+struct Commit {
+    height: i64,
+    round: i32,
+    block_id: BlockId,
+    signatures: Vec<CommitSig>,
+}
+
+impl Commit {
+    fn decode(raw: &[u8]) -> Result<Self> {
+        let mut commit = Commit::default();
+        let mut r = Reader::new(raw);
+        while let Some((field, value)) = r.next()? {
+            match field {
+                1 => commit.height = value.varint()? as i64,
+                2 => commit.round = value.varint()? as i32,
+                3 => commit.block_id = BlockId::decode(value.bytes()?)?,
+                4 => commit.signatures.push(CommitSig::decode(value.bytes()?)?),
+                _ => {}
+            }
+        }
+        Ok(commit)
+    }
+
+    /// Compute the bytes signed by the given commit signature (a length-prefixed canonical
+    /// precommit vote).
+    fn vote_sign_bytes(&self, chain_id: &str, sig: &CommitSig) -> Vec<u8> {
+        let mut vote = Vec::new();
+        put_varint_field(&mut vote, 1, SIGNED_MSG_TYPE_PRECOMMIT);
+        put_fixed64_field(&mut vote, 2, self.height as u64);
+        put_fixed64_field(&mut vote, 3, self.round as i64 as u64);
+        put_message_field(&mut vote, 4, &self.block_id.encode_canonical());
+        put_message_field(&mut vote, 5, &sig.timestamp);
+        put_bytes_field(&mut vote, 6, chain_id.as_bytes());
+
+        let mut out = Vec::new();
+        put_varint(&mut out, vote.len() as u64);
+        out.extend_from_slice(&vote);
+        out
+    }
+}
+
+/// A validator.
+#[derive(Clone, Debug, Default, PartialEq, Eq)]
+pub struct Validator {
+    pub address: Vec<u8>,
+    pub public_key: Vec<u8>,
+    pub voting_power: i64,
+}
+
+impl Validator {
+    fn decode(raw: &[u8]) -> Result<Self> {
+        let mut val = Validator::default();
+        let mut r = Reader::new(raw);
+        while let Some((field, value)) = r.next()? {
+            match field {
+                1 => val.address = value.bytes()?.to_vec(),
+                2 => {
+                    let mut pk = Reader::new(value.bytes()?);
+                    while let Some((field, value)) = pk.next()? {
+                        match field {
+                            1 => val.public_key = value.bytes()?.to_vec(),
+                            _ => return Err(anyhow!("tendermint: unsupported public key type")),
+                        }
+                    }
+                }
+                3 => val.voting_power = value.varint()? as i64,
+                _ => {}
+            }
+        }
+        if val.public_key.len() != ED25519_PUBLIC_KEY_SIZE {
+            return Err(anyhow!("tendermint: malformed validator public key"));
+        }
+        if val.voting_power <= 0 {
+            return Err(anyhow!("tendermint: invalid validator voting power"));
+        }
+        let address = &Sha256::digest(&val.public_key)[..20];
+        if val.address != address {
+            return Err(anyhow!("tendermint: validator address mismatch"));
+        }
+        Ok(val)
+    }
+
+    /// Encode the validator as a simple validator used for validator set hashing.
+    fn encode_simple(&self) -> Vec<u8> {
+        let mut pk = Vec::new();
+        put_bytes_field(&mut pk, 1, &self.public_key);
+
+        let mut out = Vec::new();
+        put_message_field(&mut out, 1, &pk);
+        put_varint_field(&mut out, 2, self.voting_power as u64);
+        out
+    }
+
+    /// Verify a signature by the validator over the given message.
+    fn verify(&self, message: &[u8], signature: &[u8]) -> Result<()> {
+        let pk = ed25519_dalek::PublicKey::from_bytes(&self.public_key)?;
+        let sig = ed25519_dalek::Signature::from_bytes(signature)?;
+        Ok(pk.verify_strict(message, &sig)?)
+    }
+}
+
+/// A validator set.
+#[derive(Clone, Debug, Default, PartialEq, Eq)]
+pub struct ValidatorSet {
+    pub validators: Vec<Validator>,
+}
+
+impl ValidatorSet {
+    fn decode(raw: &[u8]) -> Result<Self> {
+        let mut vals = ValidatorSet::default();
+        let mut r = Reader::new(raw);
+        while let Some((field, value)) = r.next()? {
+            if field == 1 {
+                vals.validators.push(Validator::decode(value.bytes()?)?);
+            }
+        }
+        if vals.validators.is_empty() {
+            return Err(anyhow!("tendermint: empty validator set"));
+        }
+        Ok(vals)
+    }
+
+    /// Compute the validator set hash.
+    pub fn hash(&self) -> Vec<u8> {
+        let leaves: Vec<Vec<u8>> = self.validators.iter().map(|v| v.encode_simple()).collect();
+        merkle_hash(&leaves)
+    }
+
+    /// Total voting power of the validator set.
+    pub fn total_voting_power(&self) -> i64 {
+        self.validators.iter().map(|v| v.voting_power).sum()
+    }
+
+    fn get_by_address(&self, address: &[u8]) -> Option<&Validator> {
+        self.validators.iter().find(|v| v.address == address)
+    }
+}
+
+/// A Tendermint light block (signed header and the validator set of the header).
+#[derive(Clone, Debug, Default, PartialEq, Eq)]
+pub struct LightBlock {
+    pub header: Header,
+    commit: Commit,
+    pub validators: ValidatorSet,
+}
+
+impl LightBlock {
+    /// Decode a protocol buffers encoded light block.
+    pub fn decode(raw: &[u8]) -> Result<Self> {
+        let mut signed_header = None;
+        let mut validators = None;
+        let mut r = Reader::new(raw);
+        while let Some((field, value)) = r.next()? {
+            match field {
+                1 => signed_header = Some(value.bytes()?),
+                2 => validators = Some(ValidatorSet::decode(value.bytes()?)?),
+                _ => {}
+            }
+        }
+        let signed_header =
+            signed_header.ok_or_else(|| anyhow!("tendermint: missing signed header"))?;
+        let validators = validators.ok_or_else(|| anyhow!("tendermint: missing validator set"))?;
+
+        let mut header = None;
+        let mut commit = None;
+        let mut r = Reader::new(signed_header);
+        while let Some((field, value)) = r.next()? {
+            match field {
+                1 => header = Some(Header::decode(value.bytes()?)?),
+                2 => commit = Some(Commit::decode(value.bytes()?)?),
+                _ => {}
+            }
+        }
+
+        Ok(Self {
+            header: header.ok_or_else(|| anyhow!("tendermint: missing header"))?,
+            commit: commit.ok_or_else(|| anyhow!("tendermint: missing commit"))?,
+            validators,
+        })
+    }
+
+    /// Validate that the light block is internally consistent, i.e. that the validator set
+    /// matches the header and that the commit is for the header and is signed by more than
+    /// two thirds of the voting power of the validator set.
+    pub fn validate(&self) -> Result<()> {
+        if self.validators.hash() != self.header.validators_hash {
+            return Err(anyhow!("tendermint: validator set does not match header"));
+        }
+        if self.commit.height != self.header.height {
+            return Err(anyhow!("tendermint: commit is for a different height"));
+        }
+        if self.commit.block_id.hash != self.header.hash() {
+            return Err(anyhow!("tendermint: commit is for a different header"));
+        }
+        if self.commit.signatures.len() != self.validators.validators.len() {
+            return Err(anyhow!("tendermint: commit signature count mismatch"));
+        }
+
+        let mut tallied = 0i64;
+        for (val, sig) in self
+            .validators
+            .validators
+            .iter()
+            .zip(self.commit.signatures.iter())
+        {
+            if sig.block_id_flag != BLOCK_ID_FLAG_COMMIT {
+                continue;
+            }
+            if sig.validator_address != val.address {
+                return Err(anyhow!("tendermint: commit signature validator mismatch"));
+            }
+            let sign_bytes = self.commit.vote_sign_bytes(&self.header.chain_id, sig);
+            val.verify(&sign_bytes, &sig.signature)
+                .map_err(|_| anyhow!("tendermint: invalid commit signature"))?;
+            tallied += val.voting_power;
+        }
+        if tallied <= self.validators.total_voting_power() * 2 / 3 {
+            return Err(anyhow!("tendermint: insufficient commit voting power"));
+        }
+        Ok(())
+    }
+
+    /// Check whether validators from the given trusted validator set holding more than one third
+    /// of its voting power signed the commit of this light block.
+    pub fn is_trusted_by(&self, trusted: &ValidatorSet) -> Result<bool> {
+        let mut seen = HashSet::new();
+        let mut tallied = 0i64;
+        for sig in &self.commit.signatures {
+            if sig.block_id_flag != BLOCK_ID_FLAG_COMMIT {
+                continue;
+            }
+            let val = match trusted.get_by_address(&sig.validator_address) {
+                Some(val) => val,
+                None => continue,
+            };
+            if !seen.insert(val.address.clone()) {
+                return Err(anyhow!("tendermint: duplicate commit signature"));
+            }
+            let sign_bytes = self.commit.vote_sign_bytes(&self.header.chain_id, sig);
+            val.verify(&sign_bytes, &sig.signature)
+                .map_err(|_| anyhow!("tendermint: invalid commit signature"))?;
+            tallied += val.voting_power;
+        }
+        Ok(tallied > trusted.total_voting_power() / 3)
+    }
+}
+
+/// Compute the RFC 6962 Merkle tree root of the given leaves.
+fn merkle_hash(leaves: &[Vec<u8>]) -> Vec<u8> {
+    let mut h = Sha256::new();
+    match leaves.len() {
+        0 => {}
+        1 => {
+            h.update(&[0x00]);
+            h.update(&leaves[0]);
+        }
+        n => {
+            let mut split = 1;
+            while split * 2 < n {
+                split *= 2;
+            }
+            h.update(&[0x01]);
+            h.update(&merkle_hash(&leaves[..split]));
+            h.update(&merkle_hash(&leaves[split..]));
+        }
+    }
+    h.finalize().to_vec()
+}
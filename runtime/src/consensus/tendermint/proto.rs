@@ -0,0 +1,195 @@
+//! Minimal protocol buffers wire format support required for handling Tendermint light blocks.
+use anyhow::{anyhow, Result};
+
+/// Varint wire type.
+pub const WIRE_VARINT: u8 = 0;
+/// 64-bit fixed size wire type.
+pub const WIRE_FIXED64: u8 = 1;
+/// Length-delimited wire type.
+pub const WIRE_BYTES: u8 = 2;
+/// 32-bit fixed size wire type.
+pub const WIRE_FIXED32: u8 = 5;
+
+/// A decoded field value.
+pub enum Value<'a> {
+    Varint(u64),
+    Fixed64(u64),
+    Bytes(&'a [u8]),
+    Fixed32(u32),
+}
+
+impl<'a> Value<'a> {
+    /// Return the varint value of the field.
+    pub fn varint(&self) -> Result<u64> {
+        match self {
+            Value::Varint(v) => Ok(*v),
+            _ => Err(anyhow!("proto: expected varint field")),
+        }
+    }
+
+    /// Return the length-delimited value of the field.
+    pub fn bytes(&self) -> Result<&'a [u8]> {
+        match self {
+            Value::Bytes(v) => Ok(v),
+            _ => Err(anyhow!("proto: expected length-delimited field")),
+        }
+    }
+}
+
+/// A reader over the fields of an encoded message.
+pub struct Reader<'a> {
+    buf: &'a [u8],
+}
+
+impl<'a> Reader<'a> {
+    /// Create a new reader over the given encoded message.
+    pub fn new(buf: &'a [u8]) -> Self {
+        Self { buf }
+    }
+
+    /// Read the next field, returning its field number and value.
+    pub fn next(&mut self) -> Result<Option<(u64, Value<'a>)>> {
+        if self.buf.is_empty() {
+            return Ok(None);
+        }
+
+        let key = self.read_varint()?;
+        let field = key >> 3;
+        if field == 0 {
+            return Err(anyhow!("proto: invalid field number"));
+        }
+        let value = match (key & 0x7) as u8 {
+            WIRE_VARINT => Value::Varint(self.read_varint()?),
+            WIRE_FIXED64 => {
+                let raw = self.read_raw(8)?;
+                let mut v = [0u8; 8];
+                v.copy_from_slice(raw);
+                Value::Fixed64(u64::from_le_bytes(v))
+            }
+            WIRE_BYTES => {
+                let len = self.read_varint()?;
+                if len > self.buf.len() as u64 {
+                    return Err(anyhow!("proto: truncated field"));
+                }
+                Value::Bytes(self.read_raw(len as usize)?)
+            }
+            WIRE_FIXED32 => {
+                let raw = self.read_raw(4)?;
+                let mut v = [0u8; 4];
+                v.copy_from_slice(raw);
+                Value::Fixed32(u32::from_le_bytes(v))
+            }
+            wire => return Err(anyhow!("proto: unsupported wire type {}", wire)),
+        };
+        Ok(Some((field, value)))
+    }
+
+    fn read_varint(&mut self) -> Result<u64> {
+        let mut v: u64 = 0;
+        for i in 0..10 {
+            let b = *self
+                .buf
+                .get(i)
+                .ok_or_else(|| anyhow!("proto: truncated varint"))?;
+            v |= ((b & 0x7f) as u64) << (7 * i);
+            if b & 0x80 == 0 {
+                self.buf = &self.buf[i + 1..];
+                return Ok(v);
+            }
+        }
+        Err(anyhow!("proto: malformed varint"))
+    }
+
+    fn read_raw(&mut self, len: usize) -> Result<&'a [u8]> {
+        if len > self.buf.len() {
+            return Err(anyhow!("proto: truncated field"));
+        }
+        let (raw, rest) = self.buf.split_at(len);
+        self.buf = rest;
+        Ok(raw)
+    }
+}
+
+/// Append a varint to the given buffer.
+pub fn put_varint(out: &mut Vec<u8>, mut v: u64) {
+    while v >= 0x80 {
+        out.push((v as u8) | 0x80);
+        v >>= 7;
+    }
+    out.push(v as u8);
+}
+
+fn put_key(out: &mut Vec<u8>, field: u64, wire: u8) {
+    put_varint(out, (field << 3) | wire as u64);
+}
+
+/// Append a varint field to the given buffer, omitting it in case the value is zero.
+pub fn put_varint_field(out: &mut Vec<u8>, field: u64, v: u64) {
+    if v == 0 {
+        return;
+    }
+    put_key(out, field, WIRE_VARINT);
+    put_varint(out, v);
+}
+
+/// Append a 64-bit fixed size field to the given buffer, omitting it in case the value is zero.
+pub fn put_fixed64_field(out: &mut Vec<u8>, field: u64, v: u64) {
+    if v == 0 {
+        return;
+    }
+    put_key(out, field, WIRE_FIXED64);
+    out.extend_from_slice(&v.to_le_bytes());
+}
+
+/// Append a bytes (or string) field to the given buffer, omitting it in case it is empty.
+pub fn put_bytes_field(out: &mut Vec<u8>, field: u64, v: &[u8]) {
+    if v.is_empty() {
+        return;
+    }
+    put_message_field(out, field, v);
+}
+
+/// Append an embedded message field to the given buffer. The field is always emitted, matching
+/// the encoding of non-nullable embedded messages.
+pub fn put_message_field(out: &mut Vec<u8>, field: u64, v: &[u8]) {
+    put_key(out, field, WIRE_BYTES);
+    put_varint(out, v.len() as u64);
+    out.extend_from_slice(v);
+}
+
+#[cfg(test)]
+mod test {
+    use super::*;
+
+    #[test]
+    fn test_roundtrip() {
+        let mut buf = Vec::new();
+        put_varint_field(&mut buf, 1, 300);
+        put_fixed64_field(&mut buf, 2, 42);
+        put_bytes_field(&mut buf, 3, b"hello");
+        put_bytes_field(&mut buf, 4, b"");
+        put_message_field(&mut buf, 5, b"");
+
+        let mut r = Reader::new(&buf);
+        let (field, value) = r.next().unwrap().unwrap();
+        assert_eq!(field, 1);
+        assert_eq!(value.varint().unwrap(), 300);
+        let (field, value) = r.next().unwrap().unwrap();
+        assert_eq!(field, 2);
+        match value {
+            Value::Fixed64(v) => assert_eq!(v, 42),
+            _ => panic!("expected fixed64 field"),
+        }
+        let (field, value) = r.next().unwrap().unwrap();
+        assert_eq!(field, 3);
+        assert_eq!(value.bytes().unwrap(), b"hello");
+        let (field, value) = r.next().unwrap().unwrap();
+        assert_eq!(field, 5);
+        assert!(value.bytes().unwrap().is_empty());
+        assert!(r.next().unwrap().is_none());
+
+        // Truncated input should be rejected.
+        let mut r = Reader::new(&[0x1a, 0x05, b'h']);
+        assert!(r.next().is_err());
+    }
+}
@@ -0,0 +1,371 @@
+//! Tendermint light block verifier.
+use std::collections::BTreeMap;
+
+use anyhow::{anyhow, Result};
+
+use super::{Header, LightBlock, ValidatorSet};
+
+/// Maximum number of verified light blocks that are remembered in addition to the trust root.
+const MAX_TRUSTED_BLOCKS: usize = 128;
+
+/// Trust root of the light block verifier.
+///
+/// The trust root must be obtained from a trusted source (e.g., it can be embedded in the runtime
+/// binary at build time) as all light blocks are verified starting from it.
+#[derive(Clone, Debug, Default, PartialEq, Eq)]
+pub struct TrustRoot {
+    /// Height of the trusted consensus block.
+    pub height: u64,
+    /// Hash of the trusted consensus block header.
+    pub hash: Vec<u8>,
+}
+
+/// Source of protocol buffers encoded light blocks required to verify other light blocks.
+///
+/// Light blocks obtained from the source are untrusted and are verified before use.
+pub trait LightBlockFetcher {
+    /// Fetch the light block at the given height.
+    fn fetch_light_block(&self, height: u64) -> Result<Vec<u8>>;
+}
+
+/// A verified light block.
+struct TrustedState {
+    header_hash: Vec<u8>,
+    next_validators_hash: Vec<u8>,
+    validators: ValidatorSet,
+}
+
+/// Light block verifier.
+///
+/// The verifier starts from the configured trust root, fetching the light block at the trust
+/// root height and checking that its header hash matches the trust root. Other light blocks are
+/// verified starting from the closest verified light block at a lower height. A light block for
+/// the height immediately following a verified height must be signed by the validator set
+/// committed to by the verified header, while a light block for a later height must be signed by
+/// validators holding more than one third of the voting power of the verified validator set. In
+/// case the latter does not hold, the light block in the middle of the two heights is fetched
+/// and verified first (bisection).
+///
+/// Light blocks at heights below the trust root height are rejected. As the runtime has no
+/// trusted source of time, verified light blocks do not expire.
+pub struct Verifier {
+    trust_root: TrustRoot,
+    chain_id: String,
+    trusted: BTreeMap<i64, TrustedState>,
+}
+
+impl Verifier {
+    /// Create a new light block verifier with the given trust root.
+    pub fn new(trust_root: TrustRoot) -> Self {
+        Self {
+            trust_root,
+            chain_id: String::new(),
+            trusted: BTreeMap::new(),
+        }
+    }
+
+    /// Verify the given protocol buffers encoded light block and return its verified header.
+    ///
+    /// Any light blocks required for verification are fetched from the given fetcher.
+    pub fn verify(&mut self, raw: &[u8], fetcher: &dyn LightBlockFetcher) -> Result<Header> {
+        if self.trusted.is_empty() {
+            self.verify_trust_root(fetcher)?;
+        }
+
+        let lb = LightBlock::decode(raw)?;
+        lb.validate()?;
+        if lb.header.chain_id != self.chain_id {
+            return Err(anyhow!("tendermint: light block is for a different chain"));
+        }
+        if lb.header.height < self.trust_root.height as i64 {
+            return Err(anyhow!(
+                "tendermint: light block is older than the trust root"
+            ));
+        }
+        let header = lb.header.clone();
+
+        let mut pending = vec![lb];
+        while let Some(lb) = pending.last() {
+            let height = lb.header.height;
+            let (&anchor_height, anchor) = self
+                .trusted
+                .range(..=height)
+                .next_back()
+                .expect("trust root is always trusted");
+
+            if anchor_height == height {
+                if anchor.header_hash != lb.header.hash() {
+                    return Err(anyhow!(
+                        "tendermint: light block does not match verified header"
+                    ));
+                }
+                pending.pop();
+                continue;
+            }
+
+            let is_trusted = if height == anchor_height + 1 {
+                if lb.header.validators_hash != anchor.next_validators_hash {
+                    return Err(anyhow!("tendermint: unexpected validator set"));
+                }
+                true
+            } else {
+                lb.is_trusted_by(&anchor.validators)?
+            };
+            if is_trusted {
+                let lb = pending.pop().unwrap();
+                self.trust(lb);
+                continue;
+            }
+
+            // Not enough trusted validators signed the light block, verify the light block in
+            // the middle first.
+            let pivot = anchor_height + (height - anchor_height) / 2;
+            let lb = LightBlock::decode(&fetcher.fetch_light_block(pivot as u64)?)?;
+            lb.validate()?;
+            if lb.header.chain_id != self.chain_id || lb.header.height != pivot {
+                return Err(anyhow!("tendermint: fetched unexpected light block"));
+            }
+            pending.push(lb);
+        }
+
+        Ok(header)
+    }
+
+    fn verify_trust_root(&mut self, fetcher: &dyn LightBlockFetcher) -> Result<()> {
+        let lb = LightBlock::decode(&fetcher.fetch_light_block(self.trust_root.height)?)?;
+        lb.validate()?;
+        if lb.header.height != self.trust_root.height as i64
+            || lb.header.hash() != self.trust_root.hash
+        {
+            return Err(anyhow!("tendermint: light block does not match trust root"));
+        }
+
+        self.chain_id = lb.header.chain_id.clone();
+        self.trust(lb);
+        Ok(())
+    }
+
+    fn trust(&mut self, lb: LightBlock) {
+        self.trusted.insert(
+            lb.header.height,
+            TrustedState {
+                header_hash: lb.header.hash(),
+                next_validators_hash: lb.header.next_validators_hash,
+                validators: lb.validators,
+            },
+        );
+
+        // Forget the oldest light blocks, but always keep the trust root.
+        while self.trusted.len() > MAX_TRUSTED_BLOCKS + 1 {
+            let oldest = *self
+                .trusted
+                .keys()
+                .nth(1)
+                .expect("there are more than two trusted light blocks");
+            self.trusted.remove(&oldest);
+        }
+    }
+}
+
+#[cfg(test)]
+mod test {
+    use std::{cell::RefCell, collections::HashMap};
+
+    use rustc_hex::FromHex;
+
+    use super::{super::BLOCK_ID_FLAG_COMMIT, *};
+
+    /// Light blocks generated by Tendermint (height, header hash, encoded light block). Heights 1
+    /// to 5 are signed by the same validator set, height 5 switches to an entirely different
+    /// validator set which signs heights 6 and 7.
+    const LIGHT_BLOCKS: &str = include_str!("../../../testdata/tendermint_light_blocks.txt");
+    /// Light blocks generated by Tendermint for the same chain, signed by a validator set that
+    /// is not part of the chain above.
+    const FORGED_LIGHT_BLOCKS: &str =
+        include_str!("../../../testdata/tendermint_light_blocks_forged.txt");
+
+    fn light_blocks(data: &str) -> Vec<(i64, Vec<u8>, Vec<u8>)> {
+        data.lines()
+            .filter(|line| !line.is_empty())
+            .map(|line| {
+                let parts: Vec<&str> = line.split(' ').collect();
+                (
+                    parts[0].parse().unwrap(),
+                    parts[1].from_hex().unwrap(),
+                    parts[2].from_hex().unwrap(),
+                )
+            })
+            .collect()
+    }
+
+    /// Light block fetcher serving the given light blocks and recording the fetched heights.
+    struct TestFetcher {
+        blocks: HashMap<u64, Vec<u8>>,
+        fetched: RefCell<Vec<u64>>,
+    }
+
+    impl TestFetcher {
+        fn new(lbs: &[(i64, Vec<u8>, Vec<u8>)]) -> Self {
+            Self {
+                blocks: lbs
+                    .iter()
+                    .map(|(height, _, raw)| (*height as u64, raw.clone()))
+                    .collect(),
+                fetched: RefCell::new(Vec::new()),
+            }
+        }
+
+        fn take_fetched(&self) -> Vec<u64> {
+            self.fetched.replace(Vec::new())
+        }
+    }
+
+    impl LightBlockFetcher for TestFetcher {
+        fn fetch_light_block(&self, height: u64) -> Result<Vec<u8>> {
+            self.fetched.borrow_mut().push(height);
+            self.blocks
+                .get(&height)
+                .cloned()
+                .ok_or_else(|| anyhow!("light block not available"))
+        }
+    }
+
+    fn trust_root(lb: &(i64, Vec<u8>, Vec<u8>)) -> TrustRoot {
+        TrustRoot {
+            height: lb.0 as u64,
+            hash: lb.1.clone(),
+        }
+    }
+
+    #[test]
+    fn test_light_block() {
+        let mut lbs = light_blocks(LIGHT_BLOCKS);
+        lbs.extend(light_blocks(FORGED_LIGHT_BLOCKS));
+        for (height, header_hash, raw) in lbs {
+            let lb = LightBlock::decode(&raw).unwrap();
+            assert_eq!(lb.header.height, height);
+            assert_eq!(lb.header.chain_id, "test-chain");
+            assert_eq!(lb.header.hash(), header_hash, "header hash should match");
+            assert_eq!(lb.validators.validators.len(), 4);
+            assert_eq!(lb.validators.total_voting_power(), 10 + 11 + 12 + 13);
+            lb.validate().unwrap();
+
+            // Tampering with the header should be detected.
+            let mut tampered = raw.clone();
+            let mut app_hash = vec![0x5a, 0x20];
+            app_hash.extend_from_slice(&lb.header.app_hash);
+            let pos = tampered
+                .windows(app_hash.len())
+                .position(|w| w == &app_hash[..])
+                .unwrap();
+            tampered[pos + 2] ^= 0xff;
+            let lb_tampered = LightBlock::decode(&tampered).unwrap();
+            assert!(
+                lb_tampered.validate().is_err(),
+                "tampered header should be rejected"
+            );
+
+            // Tampering with signatures should be detected.
+            let mut lb_tampered = lb.clone();
+            lb_tampered.commit.signatures[0].signature[0] ^= 0xff;
+            assert!(
+                lb_tampered.validate().is_err(),
+                "bad signature should be rejected"
+            );
+
+            // Insufficient voting power should be detected.
+            let mut lb_tampered = lb.clone();
+            for sig in lb_tampered.commit.signatures.iter_mut().take(2) {
+                sig.block_id_flag = BLOCK_ID_FLAG_COMMIT - 1;
+            }
+            assert!(
+                lb_tampered.validate().is_err(),
+                "insufficient power should be rejected"
+            );
+
+            // Truncated light blocks should be rejected.
+            assert!(LightBlock::decode(&raw[..raw.len() - 1]).is_err());
+        }
+    }
+
+    #[test]
+    fn test_verifier() {
+        let lbs = light_blocks(LIGHT_BLOCKS);
+        let fetcher = TestFetcher::new(&lbs);
+
+        // Consecutive light blocks starting at the trust root.
+        let mut verifier = Verifier::new(trust_root(&lbs[0]));
+        for (height, header_hash, raw) in &lbs {
+            let hdr = verifier.verify(raw, &fetcher).unwrap();
+            assert_eq!(hdr.height, *height);
+            assert_eq!(&hdr.hash(), header_hash);
+        }
+        assert_eq!(fetcher.take_fetched(), vec![1]);
+        // Already verified older heights should be accepted without fetching.
+        verifier.verify(&lbs[1].2, &fetcher).unwrap();
+        assert!(fetcher.take_fetched().is_empty());
+
+        // Skipping verification should succeed while the validator set does not change, and
+        // older unverified heights should be verified starting from the trust root.
+        let mut verifier = Verifier::new(trust_root(&lbs[0]));
+        verifier.verify(&lbs[4].2, &fetcher).unwrap();
+        assert_eq!(fetcher.take_fetched(), vec![1]);
+        let hdr = verifier.verify(&lbs[2].2, &fetcher).unwrap();
+        assert_eq!(hdr.height, 3);
+        assert!(fetcher.take_fetched().is_empty());
+
+        // Height 7 is signed by an entirely different validator set, so it must be verified by
+        // bisection through the validator set change at height 5.
+        let mut verifier = Verifier::new(trust_root(&lbs[0]));
+        let hdr = verifier.verify(&lbs[6].2, &fetcher).unwrap();
+        assert_eq!(hdr.height, 7);
+        assert_eq!(fetcher.take_fetched(), vec![1, 4, 5, 6]);
+        // Heights below the latest verified height should be accepted afterwards.
+        verifier.verify(&lbs[1].2, &fetcher).unwrap();
+        verifier.verify(&lbs[5].2, &fetcher).unwrap();
+        assert!(fetcher.take_fetched().is_empty());
+
+        // Light blocks below the trust root should be rejected.
+        let mut verifier = Verifier::new(trust_root(&lbs[2]));
+        assert!(verifier.verify(&lbs[1].2, &fetcher).is_err());
+        verifier.verify(&lbs[3].2, &fetcher).unwrap();
+    }
+
+    #[test]
+    fn test_verifier_forged() {
+        let lbs = light_blocks(LIGHT_BLOCKS);
+        let forged = light_blocks(FORGED_LIGHT_BLOCKS);
+        let fetcher = TestFetcher::new(&lbs);
+        let forged_fetcher = TestFetcher::new(&forged);
+
+        // Without a matching trust root, nothing should be accepted.
+        let mut verifier = Verifier::new(trust_root(&lbs[0]));
+        assert!(verifier.verify(&forged[2].2, &forged_fetcher).is_err());
+        assert!(verifier.verify(&lbs[2].2, &forged_fetcher).is_err());
+        let mut verifier = Verifier::new(TrustRoot {
+            height: 1,
+            hash: vec![0; 32],
+        });
+        assert!(verifier.verify(&lbs[0].2, &fetcher).is_err());
+
+        // Forged light blocks should be rejected when verified against the real chain.
+        let mut verifier = Verifier::new(trust_root(&lbs[0]));
+        assert!(verifier.verify(&forged[1].2, &fetcher).is_err());
+        assert!(verifier.verify(&forged[2].2, &fetcher).is_err());
+        assert!(verifier.verify(&forged[0].2, &fetcher).is_err());
+
+        // Forged light blocks should be rejected at already verified heights.
+        verifier.verify(&lbs[2].2, &fetcher).unwrap();
+        assert!(verifier.verify(&forged[2].2, &fetcher).is_err());
+
+        // A host serving forged light blocks should not be able to get bisection to accept a
+        // light block signed by other validators.
+        let mut verifier = Verifier::new(trust_root(&lbs[0]));
+        verifier.verify(&lbs[0].2, &fetcher).unwrap();
+        let mut mixed = lbs.clone();
+        mixed[3] = forged[3].clone();
+        let mixed_fetcher = TestFetcher::new(&mixed);
+        assert!(verifier.verify(&lbs[6].2, &mixed_fetcher).is_err());
+        assert_eq!(mixed_fetcher.take_fetched(), vec![4, 2, 3]);
+    }
+}
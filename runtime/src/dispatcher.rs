@@ -22,8 +22,14 @@ use crate::{
             signature::{Signature, Signer},
         },
         logger::get_logger,
+        sgx::avr,
+    },
+    consensus::{
+        roothash::{self, Block, ComputeResultsHeader, COMPUTE_RESULTS_HEADER_CONTEXT},
+        state::ConsensusState,
+        tendermint::verifier::{TrustRoot, Verifier as ConsensusVerifier},
+        LightBlock,
     },
-    consensus::roothash::{self, Block, ComputeResultsHeader, COMPUTE_RESULTS_HEADER_CONTEXT},
     enclave_rpc::{
         demux::Demux as RpcDemux,
         dispatcher::Dispatcher as RpcDispatcher,
@@ -41,11 +47,15 @@ use crate::{
     },
     transaction::{
         dispatcher::{Dispatcher as TxnDispatcher, NoopDispatcher as TxnNoopDispatcher},
+        rwset::{overlaps, CoarsenedSet},
         tree::Tree as TxnTree,
-        types::TxnBatch,
+        types::{TxnBatch, TxnCall, TxnCheckResult, TxnOutput},
         Context as TxnContext,
     },
-    types::{Body, ComputedBatch, HostStorageEndpoint},
+    types::{
+        Body, ComputedBatch, ExecutedSubBatch, HostStorageEndpoint, MergeSubBatch, SubBatchHeader,
+        SUB_BATCH_HEADER_CONTEXT,
+    },
 };
 
 /// Maximum amount of requests that can be in the dispatcher queue.
@@ -112,11 +122,19 @@ pub struct Dispatcher {
     protocol_cond: Condvar,
     rak: Arc<RAK>,
     abort_batch: Arc<AtomicBool>,
+    consensus_verifier: Option<Mutex<ConsensusVerifier>>,
 }
 
 impl Dispatcher {
     /// Create a new runtime call dispatcher.
-    pub fn new(initializer: Box<dyn Initializer>, rak: Arc<RAK>) -> Arc<Self> {
+    ///
+    /// Consensus layer state is only made available to the runtime in case a consensus trust
+    /// root is given.
+    pub fn new(
+        initializer: Box<dyn Initializer>,
+        rak: Arc<RAK>,
+        consensus_trust_root: Option<TrustRoot>,
+    ) -> Arc<Self> {
         let (tx, rx) = channel::bounded(BACKLOG_SIZE);
         let (abort_tx, abort_rx) = channel::bounded(1);
 
@@ -129,6 +147,8 @@ impl Dispatcher {
             protocol_cond: Condvar::new(),
             rak,
             abort_batch: Arc::new(AtomicBool::new(false)),
+            consensus_verifier: consensus_trust_root
+                .map(|trust_root| Mutex::new(ConsensusVerifier::new(trust_root))),
         });
 
         let d = dispatcher.clone();
@@ -193,9 +213,10 @@ impl Dispatcher {
         txn_dispatcher.set_abort_batch_flag(self.abort_batch.clone());
 
         // Create common MKVS to use as a cache as long as the root stays the same. Use separate
-        // caches for executing and checking transactions.
+        // caches for executing and checking transactions and for queries.
         let mut cache = Cache::new(protocol.clone());
         let mut cache_check = Cache::new(protocol.clone());
+        let mut cache_query = Cache::new(protocol.clone());
 
         'dispatch: loop {
             // Check if abort was requested and if so, signal that the batch
@@ -227,7 +248,10 @@ impl Dispatcher {
                     ctx,
                     id,
                     Body::RuntimeExecuteTxBatchRequest {
+                        consensus_block,
+                        consensus_state_root,
                         message_results,
+                        incoming_messages,
                         io_root,
                         inputs,
                         block,
@@ -243,11 +267,23 @@ impl Dispatcher {
                         io_root,
                         inputs,
                         block,
+                        consensus_block,
+                        consensus_state_root,
                         message_results,
+                        incoming_messages,
                         false,
                     );
                 }
-                Ok((ctx, id, Body::RuntimeCheckTxBatchRequest { inputs, block })) => {
+                Ok((
+                    ctx,
+                    id,
+                    Body::RuntimeCheckTxBatchRequest {
+                        consensus_block,
+                        consensus_state_root,
+                        inputs,
+                        block,
+                    },
+                )) => {
                     // Transaction check.
                     self.dispatch_txn(
                         &mut cache_check,
@@ -258,10 +294,91 @@ impl Dispatcher {
                         Hash::default(),
                         inputs,
                         block,
+                        consensus_block,
+                        consensus_state_root,
+                        vec![],
                         vec![],
                         true,
                     );
                 }
+                Ok((
+                    ctx,
+                    id,
+                    Body::RuntimeExecuteTxSubBatchRequest {
+                        consensus_block,
+                        consensus_state_root,
+                        inputs,
+                        block,
+                    },
+                )) => {
+                    // Sub-batch execution.
+                    let result = self.execute_sub_batch(
+                        &mut cache,
+                        &mut txn_dispatcher,
+                        &protocol,
+                        ctx,
+                        inputs,
+                        block,
+                        consensus_block,
+                        consensus_state_root,
+                    );
+                    self.send_result(
+                        &protocol,
+                        id,
+                        result.map(|batch| Body::RuntimeExecuteTxSubBatchResponse { batch }),
+                    );
+                }
+                Ok((
+                    ctx,
+                    id,
+                    Body::RuntimeMergeTxBatchRequest {
+                        io_root,
+                        inputs,
+                        block,
+                        sub_batches,
+                    },
+                )) => {
+                    // Merging of executed sub-batches.
+                    let result = self.merge_sub_batches(
+                        &mut cache,
+                        &mut txn_dispatcher,
+                        ctx,
+                        io_root,
+                        inputs,
+                        block,
+                        sub_batches,
+                    );
+                    self.send_result(
+                        &protocol,
+                        id,
+                        result.map(|batch| Body::RuntimeMergeTxBatchResponse { batch }),
+                    );
+                }
+                Ok((
+                    ctx,
+                    id,
+                    Body::RuntimeQueryRequest {
+                        consensus_block,
+                        consensus_state_root,
+                        method,
+                        args,
+                        block,
+                    },
+                )) => {
+                    // Read-only query.
+                    self.dispatch_query(
+                        &mut cache_query,
+                        &mut txn_dispatcher,
+                        &protocol,
+                        ctx,
+                        id,
+                        method,
+                        args,
+                        block,
+                        consensus_block,
+                        consensus_state_root,
+                    );
+                }
                 Ok((ctx, id, Body::RuntimeKeyManagerPolicyUpdateRequest { signed_policy_raw })) => {
                     // KeyManager policy update local RPC call.
                     self.handle_km_policy_update(
@@ -303,13 +420,17 @@ impl Dispatcher {
         io_root: Hash,
         mut inputs: TxnBatch,
         block: Block,
+        consensus_block: LightBlock,
+        consensus_state_root: Root,
         message_results: Vec<roothash::MessageEvent>,
+        incoming_messages: Vec<roothash::IncomingMessage>,
         check_only: bool,
     ) {
         debug!(self.logger, "Received transaction batch request";
             "state_root" => ?block.header.state_root,
             "round" => block.header.round + 1,
             "message_results" => ?message_results,
+            "incoming_messages" => incoming_messages.len(),
             "check_only" => check_only,
         );
 
@@ -321,11 +442,33 @@ impl Dispatcher {
             hash: block.header.state_root,
         });
 
+        let consensus_state =
+            match self.consensus_state(protocol, &consensus_block, consensus_state_root) {
+                Ok(consensus_state) => consensus_state,
+                Err(error) => {
+                    warn!(self.logger, "Failed to verify consensus state"; "err" => %error);
+                    protocol
+                        .send_response(
+                            id,
+                            Body::Error {
+                                module: "".to_owned(), // XXX: Error codes.
+                                code: 0,               // XXX: Error codes.
+                                message: format!("{}", error),
+                            },
+                        )
+                        .unwrap();
+                    return;
+                }
+            };
+
         let untrusted_local = Arc::new(ProtocolUntrustedLocalStorage::new(
             Context::create_child(&ctx),
             protocol.clone(),
         ));
-        let txn_ctx = TxnContext::new(ctx.clone(), &block.header, &message_results, check_only);
+        let mut txn_ctx = TxnContext::new(ctx.clone(), &block.header, &message_results, check_only);
+        txn_ctx.consensus_state = consensus_state;
+        txn_ctx.incoming_messages = &incoming_messages;
+        let finalization_data_used = txn_ctx.finalization_data_used();
         let mut overlay = OverlayTree::new(&mut cache.mkvs);
         match StorageContext::enter(&mut overlay, untrusted_local.clone(), || {
             txn_dispatcher.dispatch_batch(&inputs, txn_ctx)
@@ -415,6 +558,15 @@ impl Dispatcher {
                         io_root: Some(io_root),
                         state_root: Some(new_state_root),
                         messages_hash: Some(roothash::Message::messages_hash(&messages)),
+                        // All incoming messages are processed in the round they are delivered.
+                        in_msgs_hash: if incoming_messages.is_empty() {
+                            None
+                        } else {
+                            Some(roothash::IncomingMessage::in_messages_hash(
+                                &incoming_messages,
+                            ))
+                        },
+                        in_msgs_count: incoming_messages.len() as u32,
                     };
 
                     debug!(self.logger, "Transaction batch execution complete";
@@ -422,6 +574,7 @@ impl Dispatcher {
                         "io_root" => ?header.io_root,
                         "state_root" => ?header.state_root,
                         "messages_hash" => ?header.messages_hash,
+                        "in_msgs_count" => header.in_msgs_count,
                     );
 
                     let rak_sig = if self.rak.public_key().is_some() {
@@ -442,13 +595,475 @@ impl Dispatcher {
 
                     // Send the result back.
                     protocol
-                        .send_response(id, Body::RuntimeExecuteTxBatchResponse { batch: result })
+                        .send_response(
+                            id,
+                            Body::RuntimeExecuteTxBatchResponse {
+                                batch: result,
+                                finalization_independent: !finalization_data_used.get(),
+                            },
+                        )
                         .unwrap();
                 }
             }
         }
     }
 
+    /// Execute a sub-batch of a larger batch without committing any state changes.
+    ///
+    /// All transactions in the sub-batch must declare their read/write sets and the sub-batch
+    /// must not modify any keys outside the declared write sets, so that the results of
+    /// sub-batches that do not conflict can later be merged.
+    fn execute_sub_batch(
+        &self,
+        cache: &mut Cache,
+        txn_dispatcher: &mut Box<dyn TxnDispatcher>,
+        protocol: &Arc<Protocol>,
+        ctx: Context,
+        inputs: TxnBatch,
+        block: Block,
+        consensus_block: LightBlock,
+        consensus_state_root: Root,
+    ) -> Result<ExecutedSubBatch> {
+        debug!(self.logger, "Received transaction sub-batch request";
+            "state_root" => ?block.header.state_root,
+            "round" => block.header.round + 1,
+            "inputs" => inputs.len(),
+        );
+
+        let ctx = ctx.freeze();
+        cache.maybe_replace(Root {
+            namespace: block.header.namespace,
+            version: block.header.round,
+            hash: block.header.state_root,
+        });
+        let untrusted_local = Arc::new(ProtocolUntrustedLocalStorage::new(
+            Context::create_child(&ctx),
+            protocol.clone(),
+        ));
+
+        // Check the transactions first to obtain their declared read/write sets.
+        let mut txn_ctx = TxnContext::new(ctx.clone(), &block.header, &[], true);
+        txn_ctx.consensus_state =
+            self.consensus_state(protocol, &consensus_block, consensus_state_root)?;
+        let (results, _) = {
+            let mut overlay = OverlayTree::new(&mut cache.mkvs);
+            StorageContext::enter(&mut overlay, untrusted_local.clone(), || {
+                txn_dispatcher.dispatch_sub_batch(&inputs, txn_ctx)
+            })?
+        };
+
+        let mut read_set = CoarsenedSet::new();
+        let mut write_set = CoarsenedSet::new();
+        for (idx, result) in results.iter().enumerate() {
+            let rw_set = match cbor::from_slice::<TxnOutput>(result)? {
+                TxnOutput::Success(value) => {
+                    cbor::from_value::<TxnCheckResult>(value)?.predicted_rw_set
+                }
+                TxnOutput::Error(error) => {
+                    return Err(anyhow!("transaction {} failed check: {}", idx, error));
+                }
+            };
+            if !rw_set.is_declared() {
+                return Err(anyhow!(
+                    "transaction {} has no declared read/write set",
+                    idx
+                ));
+            }
+            read_set.extend(rw_set.read_set);
+            write_set.extend(rw_set.write_set);
+        }
+
+        // Execute the transactions. State changes are only committed once the sub-batches are
+        // merged, so the overlay is discarded.
+        let mut txn_ctx = TxnContext::new(ctx.clone(), &block.header, &[], false);
+        txn_ctx.consensus_state =
+            self.consensus_state(protocol, &consensus_block, consensus_state_root)?;
+        let ((mut outputs, tags), state_write_log) = {
+            let mut overlay = OverlayTree::new(&mut cache.mkvs);
+            let result = StorageContext::enter(&mut overlay, untrusted_local.clone(), || {
+                txn_dispatcher.dispatch_sub_batch(&inputs, txn_ctx)
+            })?;
+            (result, overlay.pending_write_log())
+        };
+        if state_write_log
+            .iter()
+            .any(|entry| !write_set.iter().any(|key| key.covers(&entry.key)))
+        {
+            return Err(anyhow!(
+                "sub-batch modified keys outside the declared write set"
+            ));
+        }
+
+        // Generate the output artifacts. The inputs are already part of the I/O tree of the
+        // batch, so only outputs need to be included.
+        let mut txn_tree = TxnTree::new(
+            Box::new(NoopReadSyncer),
+            Root {
+                namespace: block.header.namespace,
+                version: block.header.round + 1,
+                hash: Hash::empty_hash(),
+            },
+        );
+        for ((input, output), tags) in inputs.iter().zip(outputs.drain(..)).zip(tags) {
+            txn_tree.add_output(
+                Context::create_child(&ctx),
+                Hash::digest_bytes(input),
+                output,
+                tags,
+            )?;
+        }
+        let (io_write_log, _) = txn_tree.commit(Context::create_child(&ctx))?;
+
+        let header = SubBatchHeader {
+            previous_hash: block.header.encoded_hash(),
+            inputs_hash: Hash::digest_bytes(&cbor::to_vec(&inputs)),
+            read_set,
+            write_set,
+            io_write_log_hash: Hash::digest_bytes(&cbor::to_vec(&io_write_log)),
+            state_write_log_hash: Hash::digest_bytes(&cbor::to_vec(&state_write_log)),
+        };
+
+        debug!(self.logger, "Transaction sub-batch execution complete";
+            "previous_hash" => ?header.previous_hash,
+            "inputs_hash" => ?header.inputs_hash,
+        );
+
+        let rak_sig = if self.rak.public_key().is_some() {
+            self.rak
+                .sign(&SUB_BATCH_HEADER_CONTEXT, &cbor::to_vec(&header))?
+        } else {
+            Signature::default()
+        };
+
+        Ok(ExecutedSubBatch {
+            header,
+            io_write_log,
+            state_write_log,
+            rak_sig,
+        })
+    }
+
+    /// Merge the results of executing sub-batches of the given batch.
+    ///
+    /// The sub-batches must cover the whole batch and must not conflict, in which case the
+    /// merged results are the same as the results of executing the batch sequentially.
+    fn merge_sub_batches(
+        &self,
+        cache: &mut Cache,
+        txn_dispatcher: &mut Box<dyn TxnDispatcher>,
+        ctx: Context,
+        io_root: Hash,
+        inputs: TxnBatch,
+        block: Block,
+        sub_batches: Vec<MergeSubBatch>,
+    ) -> Result<ComputedBatch> {
+        debug!(self.logger, "Received transaction batch merge request";
+            "state_root" => ?block.header.state_root,
+            "round" => block.header.round + 1,
+            "sub_batches" => sub_batches.len(),
+        );
+
+        let ctx = ctx.freeze();
+        let previous_hash = block.header.encoded_hash();
+
+        // Verify the sub-batches.
+        let mut merged = vec![false; inputs.len()];
+        for (i, sb) in sub_batches.iter().enumerate() {
+            let mut sub_inputs = Vec::with_capacity(sb.indices.len());
+            for (pos, &idx) in sb.indices.iter().enumerate() {
+                if pos > 0 && idx <= sb.indices[pos - 1] {
+                    return Err(anyhow!("sub-batch {} is not in batch order", i));
+                }
+                let seen = merged
+                    .get_mut(idx as usize)
+                    .ok_or_else(|| anyhow!("sub-batch {} input index out of range", i))?;
+                if *seen {
+                    return Err(anyhow!("sub-batch {} input included more than once", i));
+                }
+                *seen = true;
+                sub_inputs.push(inputs[idx as usize].clone());
+            }
+
+            let header = &sb.batch.header;
+            if header.previous_hash != previous_hash {
+                return Err(anyhow!("sub-batch {} executed on a different block", i));
+            }
+            if header.inputs_hash != Hash::digest_bytes(&cbor::to_vec(&TxnBatch::new(sub_inputs))) {
+                return Err(anyhow!("sub-batch {} inputs mismatch", i));
+            }
+            if header.io_write_log_hash != Hash::digest_bytes(&cbor::to_vec(&sb.batch.io_write_log))
+                || header.state_write_log_hash
+                    != Hash::digest_bytes(&cbor::to_vec(&sb.batch.state_write_log))
+            {
+                return Err(anyhow!("sub-batch {} write logs mismatch", i));
+            }
+            self.verify_sub_batch(sb)
+                .map_err(|err| anyhow!("sub-batch {} not verified: {}", i, err))?;
+        }
+        if merged.iter().any(|seen| !seen) {
+            return Err(anyhow!("sub-batches do not cover the whole batch"));
+        }
+
+        // Sub-batches were executed independently of each other, so the results can only be
+        // merged in case no sub-batch modified anything another sub-batch accessed.
+        for (i, a) in sub_batches.iter().enumerate() {
+            for (j, b) in sub_batches.iter().enumerate() {
+                if i != j
+                    && (overlaps(&a.batch.header.write_set, &b.batch.header.write_set)
+                        || overlaps(&a.batch.header.write_set, &b.batch.header.read_set))
+                {
+                    return Err(anyhow!("sub-batches {} and {} conflict", i, j));
+                }
+            }
+        }
+
+        // Generate the previous I/O tree from the inputs.
+        let mut txn_tree = TxnTree::new(
+            Box::new(NoopReadSyncer),
+            Root {
+                namespace: block.header.namespace,
+                version: block.header.round + 1,
+                hash: Hash::empty_hash(),
+            },
+        );
+        for (batch_order, input) in inputs.iter().enumerate() {
+            txn_tree.add_input(
+                Context::create_child(&ctx),
+                input.clone(),
+                batch_order.try_into()?,
+            )?;
+        }
+        let (_, old_io_root) = txn_tree.commit(Context::create_child(&ctx))?;
+        if old_io_root != io_root {
+            return Err(anyhow!(
+                "I/O root inconsistent with inputs (expected: {:?} got: {:?})",
+                io_root,
+                old_io_root,
+            ));
+        }
+
+        // Merge state.
+        cache.maybe_replace(Root {
+            namespace: block.header.namespace,
+            version: block.header.round,
+            hash: block.header.state_root,
+        });
+        let mut overlay = OverlayTree::new(&mut cache.mkvs);
+        for sb in &sub_batches {
+            for entry in &sb.batch.state_write_log {
+                match entry.value {
+                    Some(ref value) => {
+                        overlay.insert(Context::create_child(&ctx), &entry.key, value)?;
+                    }
+                    None => {
+                        overlay.remove(Context::create_child(&ctx), &entry.key)?;
+                    }
+                }
+            }
+        }
+        let (state_write_log, new_state_root) = overlay.commit_both(
+            Context::create_child(&ctx),
+            block.header.namespace,
+            block.header.round + 1,
+        )?;
+
+        txn_dispatcher.finalize(new_state_root);
+        cache.commit(block.header.round + 1, new_state_root);
+
+        // Merge outputs.
+        for sb in &sub_batches {
+            txn_tree.apply_write_log(Context::create_child(&ctx), &sb.batch.io_write_log)?;
+        }
+        let (io_write_log, io_root) = txn_tree.commit(Context::create_child(&ctx))?;
+
+        let header = ComputeResultsHeader {
+            round: block.header.round + 1,
+            previous_hash,
+            io_root: Some(io_root),
+            state_root: Some(new_state_root),
+            messages_hash: Some(roothash::Message::messages_hash(&[])),
+            in_msgs_hash: None,
+            in_msgs_count: 0,
+        };
+
+        debug!(self.logger, "Transaction batch merge complete";
+            "previous_hash" => ?header.previous_hash,
+            "io_root" => ?header.io_root,
+            "state_root" => ?header.state_root,
+        );
+
+        let rak_sig = if self.rak.public_key().is_some() {
+            self.rak
+                .sign(&COMPUTE_RESULTS_HEADER_CONTEXT, &cbor::to_vec(&header))?
+        } else {
+            Signature::default()
+        };
+
+        Ok(ComputedBatch {
+            header,
+            io_write_log,
+            state_write_log,
+            rak_sig,
+            messages: vec![],
+        })
+    }
+
+    /// Verify that the given sub-batch has been executed by an instance of this runtime.
+    fn verify_sub_batch(&self, sb: &MergeSubBatch) -> Result<()> {
+        if self.rak.public_key().is_none() {
+            // Results are not signed when not running in a TEE.
+            return Ok(());
+        }
+
+        let rak = sb.rak.as_ref().ok_or_else(|| anyhow!("missing RAK"))?;
+        let avr = sb
+            .avr
+            .as_ref()
+            .ok_or_else(|| anyhow!("missing RAK attestation"))?;
+        let avr = avr::verify(avr)?;
+        if Some(&avr.identity) != avr::EnclaveIdentity::current().as_ref() {
+            return Err(anyhow!("executed by a different enclave"));
+        }
+        RAK::verify_binding(&avr, rak)?;
+
+        sb.batch.rak_sig.verify(
+            rak,
+            &SUB_BATCH_HEADER_CONTEXT,
+            &cbor::to_vec(&sb.batch.header),
+        )
+    }
+
+    fn send_result(&self, protocol: &Arc<Protocol>, id: u64, result: Result<Body>) {
+        let body = match result {
+            Ok(body) => body,
+            Err(error) => {
+                warn!(self.logger, "Failed to process request"; "err" => %error);
+                Body::Error {
+                    module: "".to_owned(), // XXX: Error codes.
+                    code: 0,               // XXX: Error codes.
+                    message: format!("{}", error),
+                }
+            }
+        };
+        protocol.send_response(id, body).unwrap();
+    }
+
+    /// Construct a view of the consensus state at the given root, if one was provided and a
+    /// consensus trust root is configured.
+    ///
+    /// The root is only accepted in case it matches the state root committed in the header of
+    /// the given consensus light block, which must be verified by the consensus verifier.
+    fn consensus_state(
+        &self,
+        protocol: &Arc<Protocol>,
+        consensus_block: &LightBlock,
+        root: Root,
+    ) -> Result<Option<ConsensusState>> {
+        let consensus_verifier = match &self.consensus_verifier {
+            Some(consensus_verifier) => consensus_verifier,
+            None => return Ok(None),
+        };
+        if root == Root::default() {
+            // Older hosts do not provide the consensus state root.
+            return Ok(None);
+        }
+
+        let header = consensus_verifier
+            .lock()
+            .unwrap()
+            .verify(&consensus_block.meta, protocol.as_ref())?;
+
+        // The state root at version V is committed in the header of the block at height V+1.
+        if header.height < 1 || root.version != (header.height - 1) as u64 {
+            return Err(anyhow!(
+                "consensus state root version mismatch (expected: {} got: {})",
+                header.height - 1,
+                root.version,
+            ));
+        }
+        let state_root = match header.app_hash.len() {
+            0 => Hash::empty_hash(),
+            32 => Hash::from(&header.app_hash[..]),
+            _ => return Err(anyhow!("malformed consensus app hash")),
+        };
+        if root.namespace != Default::default() || root.hash != state_root {
+            return Err(anyhow!(
+                "consensus state root mismatch (expected: {:?} got: {:?})",
+                state_root,
+                root.hash,
+            ));
+        }
+
+        Ok(Some(ConsensusState::new(protocol.clone(), root)))
+    }
+
+    fn dispatch_query(
+        &self,
+        cache: &mut Cache,
+        txn_dispatcher: &mut Box<dyn TxnDispatcher>,
+        protocol: &Arc<Protocol>,
+        ctx: Context,
+        id: u64,
+        method: String,
+        args: Vec<u8>,
+        block: Block,
+        consensus_block: LightBlock,
+        consensus_state_root: Root,
+    ) {
+        debug!(self.logger, "Received query request";
+            "method" => &method,
+            "state_root" => ?block.header.state_root,
+            "round" => block.header.round,
+        );
+
+        // Create a new context and dispatch the query.
+        let ctx = ctx.freeze();
+        cache.maybe_replace(Root {
+            namespace: block.header.namespace,
+            version: block.header.round,
+            hash: block.header.state_root,
+        });
+
+        let untrusted_local = Arc::new(ProtocolUntrustedLocalStorage::new(
+            Context::create_child(&ctx),
+            protocol.clone(),
+        ));
+        let mut txn_ctx = TxnContext::new(ctx.clone(), &block.header, &[], false);
+        let result = self
+            .consensus_state(protocol, &consensus_block, consensus_state_root)
+            .and_then(|consensus_state| {
+                txn_ctx.consensus_state = consensus_state;
+                // Any state changes are discarded together with the overlay.
+                let mut overlay = OverlayTree::new(&mut cache.mkvs);
+                StorageContext::enter(&mut overlay, untrusted_local.clone(), || {
+                    let args = if args.is_empty() {
+                        cbor::Value::Null
+                    } else {
+                        cbor::from_slice(&args)
+                            .map_err(|err| anyhow!("unable to parse query arguments: {}", err))?
+                    };
+                    txn_dispatcher.dispatch_query(TxnCall { method, args }, txn_ctx)
+                })
+            });
+        let output = match result {
+            Ok(response) => TxnOutput::Success(response),
+            Err(error) => {
+                debug!(self.logger, "Query failed"; "err" => %error);
+                TxnOutput::Error(format!("{}", error))
+            }
+        };
+
+        // Send the result back.
+        protocol
+            .send_response(
+                id,
+                Body::RuntimeQueryResponse {
+                    data: cbor::to_vec(&output),
+                },
+            )
+            .unwrap();
+    }
+
     fn dispatch_rpc(
         &self,
         rpc_demux: &mut RpcDemux,
@@ -1,9 +1,7 @@
 //! Runtime initialization.
 use crate::{
-    common::{
-        logger::{get_logger, init_logger},
-        version::Version,
-    },
+    common::logger::{get_logger, init_logger},
+    config::Config,
     dispatcher::{Dispatcher, Initializer},
     protocol::{Protocol, Stream},
     rak::RAK,
@@ -13,7 +11,7 @@ use log;
 use std::{env, sync::Arc};
 
 /// Starts the runtime.
-pub fn start_runtime(initializer: Box<dyn Initializer>, version: Version) {
+pub fn start_runtime(initializer: Box<dyn Initializer>, config: Config) {
     // Output backtraces.
     env::set_var("RUST_BACKTRACE", "1");
 
@@ -26,7 +24,7 @@ pub fn start_runtime(initializer: Box<dyn Initializer>, version: Version) {
     let rak = Arc::new(RAK::new());
 
     // Initialize the dispatcher.
-    let dispatcher = Dispatcher::new(initializer, rak.clone());
+    let dispatcher = Dispatcher::new(initializer, rak.clone(), config.consensus_trust_root);
 
     info!(logger, "Establishing connection with the worker host");
 
@@ -54,7 +52,7 @@ pub fn start_runtime(initializer: Box<dyn Initializer>, version: Version) {
         stream,
         rak.clone(),
         dispatcher.clone(),
-        version,
+        config.version,
     ));
 
     protocol.start();
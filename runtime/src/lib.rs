@@ -49,6 +49,7 @@ use sgx_isa::{AttributesFlags, Report};
 
 #[macro_use]
 pub mod common;
+pub mod config;
 pub mod consensus;
 pub mod dispatcher;
 pub mod enclave_rpc;
@@ -121,6 +122,7 @@ pub struct BuildInfo {
 
 // Re-exports.
 pub use self::{
+    config::Config,
     enclave_rpc::{demux::Demux as RpcDemux, dispatcher::Dispatcher as RpcDispatcher},
     init::start_runtime,
     protocol::Protocol,
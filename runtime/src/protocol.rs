@@ -17,6 +17,7 @@ use thiserror::Error;
 
 use crate::{
     common::{cbor, logger::get_logger, runtime::RuntimeId, version::Version},
+    consensus::tendermint::verifier::LightBlockFetcher,
     dispatcher::Dispatcher,
     rak::RAK,
     storage::KeyValue,
@@ -339,6 +340,21 @@ impl Protocol {
                 self.dispatcher.queue_request(ctx, id, req)?;
                 Ok(None)
             }
+            req @ Body::RuntimeExecuteTxSubBatchRequest { .. } => {
+                self.can_handle_runtime_requests()?;
+                self.dispatcher.queue_request(ctx, id, req)?;
+                Ok(None)
+            }
+            req @ Body::RuntimeMergeTxBatchRequest { .. } => {
+                self.can_handle_runtime_requests()?;
+                self.dispatcher.queue_request(ctx, id, req)?;
+                Ok(None)
+            }
+            req @ Body::RuntimeQueryRequest { .. } => {
+                self.can_handle_runtime_requests()?;
+                self.dispatcher.queue_request(ctx, id, req)?;
+                Ok(None)
+            }
             req @ Body::RuntimeKeyManagerPolicyUpdateRequest { .. } => {
                 info!(self.logger, "Received key manager policy update request");
                 self.can_handle_runtime_requests()?;
@@ -416,3 +432,16 @@ impl KeyValue for ProtocolUntrustedLocalStorage {
         }
     }
 }
+
+impl LightBlockFetcher for Protocol {
+    fn fetch_light_block(&self, height: u64) -> Result<Vec<u8>> {
+        match self.make_request(
+            Context::background(),
+            Body::HostFetchConsensusBlockRequest { height },
+        ) {
+            Ok(Body::HostFetchConsensusBlockResponse { block }) => Ok(block.meta),
+            Ok(_) => Err(ProtocolError::InvalidResponse.into()),
+            Err(error) => Err(error),
+        }
+    }
+}
@@ -75,6 +75,34 @@ impl<T: mkvs::FallibleMKVS> OverlayTree<T> {
         OverlayTreeIterator::new(ctx, self)
     }
 
+    /// Return a write log of any modifications without committing them to the underlying tree.
+    pub fn pending_write_log(&self) -> mkvs::WriteLog {
+        let mut log: mkvs::WriteLog = self
+            .overlay
+            .iter()
+            .map(|(key, value)| mkvs::LogEntry {
+                key: key.clone(),
+                value: Some(value.clone()),
+            })
+            .collect();
+
+        // Any dirty items not present in the overlay must have been removed.
+        let mut removed: Vec<&Vec<u8>> = self
+            .dirty
+            .iter()
+            .filter(|key| !self.overlay.contains_key(*key))
+            .collect();
+        removed.sort();
+        for key in removed {
+            log.push(mkvs::LogEntry {
+                key: key.clone(),
+                value: None,
+            });
+        }
+
+        log
+    }
+
     /// Commit any modifications to the underlying tree.
     pub fn commit(&mut self, ctx: Context) -> Result<mkvs::WriteLog> {
         let ctx = ctx.freeze();
@@ -1,10 +1,13 @@
 //! Runtime call context.
-use std::{any::Any, sync::Arc};
+use std::{any::Any, cell::Cell, rc::Rc, sync::Arc};
 
 use io_context::Context as IoContext;
 
 use super::tags::{Tag, Tags};
-use crate::consensus::roothash::{Header, Message, MessageEvent};
+use crate::consensus::{
+    roothash::{Header, IncomingMessage, Message, MessageEvent},
+    state::ConsensusState,
+};
 
 struct NoRuntimeContext;
 
@@ -13,9 +16,13 @@ pub struct Context<'a> {
     /// I/O context.
     pub io_ctx: Arc<IoContext>,
     /// The block header accompanying this transaction.
-    pub header: &'a Header,
+    header: &'a Header,
     /// Results of message processing emitted in the previous round.
     pub message_results: &'a [MessageEvent],
+    /// Messages sent to the runtime by other runtimes, delivered in this round.
+    pub incoming_messages: &'a [IncomingMessage],
+    /// Consensus state at the consensus block accompanying this transaction (if available).
+    pub(crate) consensus_state: Option<ConsensusState>,
     /// Runtime-specific context.
     pub runtime: Box<dyn Any>,
 
@@ -28,6 +35,9 @@ pub struct Context<'a> {
 
     /// List of emitted messages.
     messages: Vec<Message>,
+
+    /// Flag indicating whether the block header or the consensus state have been accessed.
+    finalization_data_used: Rc<Cell<bool>>,
 }
 
 impl<'a> Context<'a> {
@@ -42,13 +52,45 @@ impl<'a> Context<'a> {
             io_ctx,
             header,
             message_results,
+            incoming_messages: &[],
+            consensus_state: None,
             runtime: Box::new(NoRuntimeContext),
             check_only,
             tags: Vec::new(),
             messages: Vec::new(),
+            finalization_data_used: Rc::new(Cell::new(false)),
         }
     }
 
+    /// The block header accompanying this transaction.
+    ///
+    /// The header includes the timestamp of the block, which is only known once the block has
+    /// been finalized, so results depending on it cannot be computed speculatively.
+    pub fn header(&self) -> &Header {
+        self.finalization_data_used.set(true);
+        self.header
+    }
+
+    /// Round of the block header accompanying this transaction.
+    pub fn round(&self) -> u64 {
+        self.header.round
+    }
+
+    /// Consensus state at the consensus block accompanying this transaction (if available).
+    ///
+    /// The consensus block used for executing a batch is only known once the previous block has
+    /// been finalized, so results depending on it cannot be computed speculatively.
+    pub fn consensus_state(&self) -> Option<&ConsensusState> {
+        self.finalization_data_used.set(true);
+        self.consensus_state.as_ref()
+    }
+
+    /// Returns a flag that is set once the block header or the consensus state are accessed
+    /// through this context.
+    pub(crate) fn finalization_data_used(&self) -> Rc<Cell<bool>> {
+        self.finalization_data_used.clone()
+    }
+
     /// Start a new transaction.
     pub fn start_transaction(&mut self) {
         self.tags.push(Tags::new());
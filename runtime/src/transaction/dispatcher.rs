@@ -186,6 +186,24 @@ pub trait Dispatcher {
     fn finalize(&self, new_storage_root: Hash);
     /// Configure abort batch flag.
     fn set_abort_batch_flag(&mut self, abort_batch: Arc<AtomicBool>);
+    /// Dispatches a read-only query.
+    ///
+    /// Any state changes made while processing the query are discarded.
+    fn dispatch_query(&self, _call: TxnCall, _ctx: Context) -> Result<cbor::Value> {
+        Err(anyhow!("queries not supported"))
+    }
+    /// Dispatches a sub-batch of a larger batch of runtime requests.
+    ///
+    /// Sub-batches are executed independently of each other and their results are merged, so
+    /// block-level handlers, which must run exactly once per batch, are not supported and no
+    /// messages may be emitted.
+    fn dispatch_sub_batch(
+        &self,
+        _batch: &TxnBatch,
+        _ctx: Context,
+    ) -> Result<(TxnBatch, Vec<Tags>)> {
+        Err(anyhow!("sub-batches not supported"))
+    }
 }
 
 /// No-op dispatcher.
@@ -292,6 +310,24 @@ impl MethodDispatcher {
         cbor::to_vec(&rsp)
     }
 
+    /// Dispatches all calls in the batch, each as a separate transaction.
+    fn dispatch_calls(&self, batch: &TxnBatch, ctx: &mut Context) -> Result<TxnBatch> {
+        let mut vec = Vec::new();
+        for call in batch.iter() {
+            if self
+                .abort_batch
+                .as_ref()
+                .map(|b| b.load(Ordering::SeqCst))
+                .unwrap_or(false)
+            {
+                return Err(anyhow!("batch aborted"));
+            }
+            ctx.start_transaction();
+            vec.push(self.dispatch(call, ctx));
+        }
+        Ok(TxnBatch::new(vec))
+    }
+
     fn dispatch_fallible(&self, call: &Vec<u8>, ctx: &mut Context) -> Result<cbor::Value> {
         let call: TxnCall = cbor::from_slice(call).context("unable to parse call")?;
 
@@ -321,20 +357,7 @@ impl Dispatcher for MethodDispatcher {
         }
 
         // Process batch.
-        let mut vec = Vec::new();
-        for call in batch.iter() {
-            if self
-                .abort_batch
-                .as_ref()
-                .map(|b| b.load(Ordering::SeqCst))
-                .unwrap_or(false)
-            {
-                return Err(anyhow!("batch aborted"));
-            }
-            ctx.start_transaction();
-            vec.push(self.dispatch(call, &mut ctx));
-        }
-        let outputs = TxnBatch::new(vec);
+        let outputs = self.dispatch_calls(batch, &mut ctx)?;
 
         // Invoke end batch handler.
         if let Some(ref handler) = self.batch_handler {
@@ -345,6 +368,28 @@ impl Dispatcher for MethodDispatcher {
         Ok((outputs, tags, roothash_messages))
     }
 
+    fn dispatch_sub_batch(
+        &self,
+        batch: &TxnBatch,
+        mut ctx: Context,
+    ) -> Result<(TxnBatch, Vec<Tags>)> {
+        if self.batch_handler.is_some() {
+            return Err(anyhow!("sub-batches not supported with a batch handler"));
+        }
+        if let Some(ref ctx_init) = self.ctx_initializer {
+            ctx_init.init(&mut ctx);
+        }
+
+        let outputs = self.dispatch_calls(batch, &mut ctx)?;
+
+        let (tags, roothash_messages) = ctx.close();
+        if !roothash_messages.is_empty() {
+            // Message indices are relative to the batch.
+            return Err(anyhow!("sub-batch emitted messages"));
+        }
+        Ok((outputs, tags))
+    }
+
     fn finalize(&self, new_storage_root: Hash) {
         if let Some(ref finalizer) = self.finalizer {
             finalizer.finalize(new_storage_root);
@@ -355,6 +400,21 @@ impl Dispatcher for MethodDispatcher {
     fn set_abort_batch_flag(&mut self, abort_batch: Arc<AtomicBool>) {
         self.abort_batch = Some(abort_batch);
     }
+
+    fn dispatch_query(&self, call: TxnCall, mut ctx: Context) -> Result<cbor::Value> {
+        if let Some(ref ctx_init) = self.ctx_initializer {
+            ctx_init.init(&mut ctx);
+        }
+
+        ctx.start_transaction();
+        match self.methods.get(&call.method) {
+            Some(dispatcher) => dispatcher.dispatch(call, &mut ctx),
+            None => Err(DispatchError::MethodNotFound {
+                method: call.method,
+            }
+            .into()),
+        }
+    }
 }
 
 #[cfg(test)]
@@ -382,7 +442,7 @@ mod tests {
                 name: "dummy".to_owned(),
             },
             |call: &Complex, ctx: &mut Context| -> Result<Complex> {
-                assert_eq!(ctx.header.timestamp, TEST_TIMESTAMP);
+                assert_eq!(ctx.header().timestamp, TEST_TIMESTAMP);
 
                 Ok(Complex {
                     text: call.text.clone(),
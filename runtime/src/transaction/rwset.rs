@@ -25,9 +25,26 @@ impl From<Vec<u8>> for CoarsenedKey {
     }
 }
 
+impl CoarsenedKey {
+    /// Check whether the given key is represented by this coarsened key.
+    pub fn covers(&self, key: &[u8]) -> bool {
+        key.starts_with(&self.0)
+    }
+
+    /// Check whether any key could be represented by both coarsened keys.
+    pub fn overlaps(&self, other: &CoarsenedKey) -> bool {
+        self.0.starts_with(&other.0) || other.0.starts_with(&self.0)
+    }
+}
+
 /// A set of coarsened keys.
 pub type CoarsenedSet = Vec<CoarsenedKey>;
 
+/// Check whether any key could be represented by both coarsened sets.
+pub fn overlaps(a: &[CoarsenedKey], b: &[CoarsenedKey]) -> bool {
+    a.iter().any(|ka| b.iter().any(|kb| ka.overlaps(kb)))
+}
+
 /// A read/write set.
 #[derive(Clone, Debug, Default, PartialEq, Serialize, Deserialize)]
 pub struct ReadWriteSet {
@@ -39,6 +56,13 @@ pub struct ReadWriteSet {
     pub write_set: CoarsenedSet,
 }
 
+impl ReadWriteSet {
+    /// Check whether the read/write set has been declared.
+    pub fn is_declared(&self) -> bool {
+        self.granularity > 0
+    }
+}
+
 #[cfg(test)]
 mod test {
     use crate::common::cbor;
@@ -58,4 +82,18 @@ mod test {
         let dec_rw_set: ReadWriteSet = cbor::from_slice(&enc).unwrap();
         assert_eq!(rw_set, dec_rw_set, "serialization should round-trip");
     }
+
+    #[test]
+    fn test_overlaps() {
+        let a: CoarsenedSet = vec![b"foo".to_vec().into(), b"bar".to_vec().into()];
+        let b: CoarsenedSet = vec![b"fo".to_vec().into()];
+        let c: CoarsenedSet = vec![b"moo".to_vec().into()];
+
+        assert!(overlaps(&a, &b), "prefixes should overlap");
+        assert!(overlaps(&b, &a), "prefixes should overlap");
+        assert!(!overlaps(&a, &c), "disjoint sets should not overlap");
+        assert!(!overlaps(&a, &[]), "empty set should not overlap");
+        assert!(b[0].covers(b"foobar"));
+        assert!(!c[0].covers(b"mo"));
+    }
 }
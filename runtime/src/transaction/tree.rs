@@ -218,6 +218,26 @@ impl Tree {
         Ok(())
     }
 
+    /// Apply the given write log, generated by adding output artifacts to another transaction
+    /// artifacts tree for the same round.
+    pub fn apply_write_log(&mut self, ctx: Context, write_log: &WriteLog) -> Result<()> {
+        let ctx = ctx.freeze();
+
+        for entry in write_log {
+            match entry.value {
+                Some(ref value) => {
+                    self.tree
+                        .insert(Context::create_child(&ctx), &entry.key, value)?;
+                }
+                None => {
+                    self.tree.remove(Context::create_child(&ctx), &entry.key)?;
+                }
+            }
+        }
+
+        Ok(())
+    }
+
     /// Commit updates to the underlying Merkle tree and return the write
     /// log and root hash.
     pub fn commit(&mut self, ctx: Context) -> Result<(WriteLog, Hash)> {
@@ -279,4 +299,55 @@ mod test {
             "c65f4e8bd5314c26f245337a859ad244f4b1544acf60ef334cf0d0eadb47363b",
         );
     }
+
+    #[test]
+    fn test_apply_write_log() {
+        let root = Root {
+            hash: Hash::empty_hash(),
+            ..Default::default()
+        };
+        let inputs: Vec<Vec<u8>> = (0..4)
+            .map(|i| format!("this goes in ({})", i).into_bytes())
+            .collect();
+        let add_output = |tree: &mut Tree, input: &Vec<u8>| {
+            tree.add_output(
+                Context::background(),
+                Hash::digest_bytes(input),
+                b"and this comes out".to_vec(),
+                vec![Tag::new(b"tag1".to_vec(), b"value1".to_vec())],
+            )
+            .unwrap();
+        };
+
+        // Generate the tree in one go.
+        let mut tree = Tree::new(Box::new(NoopReadSyncer), root.clone());
+        for (i, input) in inputs.iter().enumerate() {
+            tree.add_input(Context::background(), input.clone(), i as u32)
+                .unwrap();
+        }
+        tree.commit(Context::background()).unwrap();
+        for input in &inputs {
+            add_output(&mut tree, input);
+        }
+        let (_, expected_root) = tree.commit(Context::background()).unwrap();
+
+        // Generate the outputs in separate trees and apply their write logs.
+        let mut tree = Tree::new(Box::new(NoopReadSyncer), root.clone());
+        for (i, input) in inputs.iter().enumerate() {
+            tree.add_input(Context::background(), input.clone(), i as u32)
+                .unwrap();
+        }
+        tree.commit(Context::background()).unwrap();
+        for chunk in inputs.chunks(2) {
+            let mut sub_tree = Tree::new(Box::new(NoopReadSyncer), root.clone());
+            for input in chunk {
+                add_output(&mut sub_tree, input);
+            }
+            let (write_log, _) = sub_tree.commit(Context::background()).unwrap();
+            tree.apply_write_log(Context::background(), &write_log)
+                .unwrap();
+        }
+        let (_, root_hash) = tree.commit(Context::background()).unwrap();
+        assert_eq!(root_hash, expected_root, "merged outputs should match");
+    }
 }
@@ -32,6 +32,16 @@ pub enum TxnOutput {
 pub struct TxnCheckResult {
     /// Predicted read/write set.
     pub predicted_rw_set: ReadWriteSet,
+    /// Transaction priority. Transactions with higher priority are scheduled first by
+    /// schedulers that support priorities.
+    #[serde(default)]
+    pub priority: u64,
+    /// Opaque runtime-specific identifier of the transaction sender.
+    #[serde(default, with = "serde_bytes")]
+    pub sender: Vec<u8>,
+    /// Sender-specific sequence number of the transaction.
+    #[serde(default)]
+    pub sender_seq: u64,
 }
 
 /// Internal module to efficiently serialize batches.
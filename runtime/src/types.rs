@@ -12,11 +12,17 @@ use crate::{
         runtime::RuntimeId,
         sgx::avr::AVR,
     },
-    consensus::roothash::{self, Block, ComputeResultsHeader},
-    storage::mkvs::{sync, WriteLog},
-    transaction::types::TxnBatch,
+    consensus::{
+        roothash::{self, Block, ComputeResultsHeader},
+        LightBlock,
+    },
+    storage::mkvs::{sync, Root, WriteLog},
+    transaction::{rwset::CoarsenedSet, types::TxnBatch},
 };
 
+/// Sub-batch results header signature context.
+pub const SUB_BATCH_HEADER_CONTEXT: &'static [u8] = b"oasis-core/runtime: sub-batch header";
+
 /// Computed batch.
 #[derive(Debug, Serialize, Deserialize)]
 pub struct ComputedBatch {
@@ -33,6 +39,53 @@ pub struct ComputedBatch {
     pub messages: Vec<roothash::Message>,
 }
 
+/// Header of the results of executing a sub-batch.
+#[derive(Clone, Debug, Default, Serialize, Deserialize)]
+pub struct SubBatchHeader {
+    /// Hash of the block the sub-batch was executed on.
+    pub previous_hash: Hash,
+    /// Hash of the executed inputs.
+    pub inputs_hash: Hash,
+    /// Union of the read sets declared by the executed transactions.
+    pub read_set: CoarsenedSet,
+    /// Union of the write sets declared by the executed transactions.
+    pub write_set: CoarsenedSet,
+    /// Hash of the I/O write log.
+    pub io_write_log_hash: Hash,
+    /// Hash of the state write log.
+    pub state_write_log_hash: Hash,
+}
+
+/// Executed sub-batch.
+#[derive(Debug, Serialize, Deserialize)]
+pub struct ExecutedSubBatch {
+    /// Sub-batch results header.
+    pub header: SubBatchHeader,
+    /// Log of the output artifacts added to the I/O tree.
+    pub io_write_log: WriteLog,
+    /// Log of changes to the state tree.
+    pub state_write_log: WriteLog,
+    /// If this runtime uses a TEE, then this is the signature of the header with the RAK of the
+    /// runtime instance that executed the sub-batch.
+    pub rak_sig: Signature,
+}
+
+/// Executed sub-batch that should be merged.
+#[derive(Debug, Serialize, Deserialize)]
+pub struct MergeSubBatch {
+    /// Indices of the sub-batch inputs within the batch, in batch order.
+    pub indices: Vec<u32>,
+    /// Executed sub-batch.
+    pub batch: ExecutedSubBatch,
+    /// If this runtime uses a TEE, then this is the RAK of the runtime instance that executed
+    /// the sub-batch.
+    #[serde(default)]
+    pub rak: Option<PublicKey>,
+    /// If this runtime uses a TEE, then this is the attestation of the RAK.
+    #[serde(default)]
+    pub avr: Option<AVR>,
+}
+
 /// Storage sync request.
 #[derive(Debug, Serialize, Deserialize)]
 pub enum StorageSyncRequest {
@@ -118,6 +171,10 @@ pub enum Body {
         response: Vec<u8>,
     },
     RuntimeCheckTxBatchRequest {
+        #[serde(default)]
+        consensus_block: LightBlock,
+        #[serde(default)]
+        consensus_state_root: Root,
         inputs: TxnBatch,
         block: Block,
     },
@@ -125,14 +182,56 @@ pub enum Body {
         results: TxnBatch,
     },
     RuntimeExecuteTxBatchRequest {
+        #[serde(default)]
+        consensus_block: LightBlock,
+        #[serde(default)]
+        consensus_state_root: Root,
         #[serde(default)]
         message_results: Vec<roothash::MessageEvent>,
+        #[serde(default)]
+        incoming_messages: Vec<roothash::IncomingMessage>,
         io_root: Hash,
         inputs: TxnBatch,
         block: Block,
     },
     RuntimeExecuteTxBatchResponse {
         batch: ComputedBatch,
+        #[serde(default)]
+        finalization_independent: bool,
+    },
+    RuntimeExecuteTxSubBatchRequest {
+        #[serde(default)]
+        consensus_block: LightBlock,
+        #[serde(default)]
+        consensus_state_root: Root,
+        inputs: TxnBatch,
+        block: Block,
+    },
+    RuntimeExecuteTxSubBatchResponse {
+        batch: ExecutedSubBatch,
+    },
+    RuntimeMergeTxBatchRequest {
+        io_root: Hash,
+        inputs: TxnBatch,
+        block: Block,
+        sub_batches: Vec<MergeSubBatch>,
+    },
+    RuntimeMergeTxBatchResponse {
+        batch: ComputedBatch,
+    },
+    RuntimeQueryRequest {
+        #[serde(default)]
+        consensus_block: LightBlock,
+        #[serde(default)]
+        consensus_state_root: Root,
+        method: String,
+        #[serde(with = "serde_bytes")]
+        args: Vec<u8>,
+        block: Block,
+    },
+    RuntimeQueryResponse {
+        #[serde(with = "serde_bytes")]
+        data: Vec<u8>,
     },
     RuntimeKeyManagerPolicyUpdateRequest {
         #[serde(with = "serde_bytes")]
@@ -178,6 +277,12 @@ pub enum Body {
         value: Vec<u8>,
     },
     HostLocalStorageSetResponse {},
+    HostFetchConsensusBlockRequest {
+        height: u64,
+    },
+    HostFetchConsensusBlockResponse {
+        block: LightBlock,
+    },
 }
 
 #[derive(Clone, Copy, Debug)]
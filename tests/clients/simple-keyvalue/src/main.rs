@@ -18,7 +18,7 @@ use tokio::runtime::Runtime;
 
 use oasis_core_client::{
     create_txn_api_client,
-    transaction::{Query, QueryCondition},
+    transaction::{Query, QueryCondition, QueryOrder},
     Node, TxnClient,
 };
 use oasis_core_runtime::{
@@ -240,6 +240,9 @@ fn main() {
             values: vec![ByteBuf::from(b"insert".to_vec())],
         }],
         limit: 0,
+        filter: None,
+        order: QueryOrder::Ascending,
+        cursor: None,
     };
     let txns = rt
         .block_on(kv_client.txn_client().query_txs(query))
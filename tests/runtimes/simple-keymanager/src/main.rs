@@ -1,9 +1,15 @@
 use oasis_core_keymanager_lib::keymanager::*;
-use oasis_core_runtime::{common::version::Version, version_from_cargo};
+use oasis_core_runtime::{common::version::Version, version_from_cargo, Config};
 
 mod api;
 
 pub fn main() {
     let init = new_keymanager(api::trusted_policy_signers());
-    oasis_core_runtime::start_runtime(init, version_from_cargo!());
+    oasis_core_runtime::start_runtime(
+        init,
+        Config {
+            version: version_from_cargo!(),
+            consensus_trust_root: None,
+        },
+    );
 }
@@ -24,7 +24,8 @@ use oasis_core_runtime::{
         dispatcher::{BatchHandler, CheckOnlySuccess},
         Context as TxnContext,
     },
-    version_from_cargo, Protocol, RpcDemux, RpcDispatcher, TxnDispatcher, TxnMethDispatcher,
+    version_from_cargo, Config, Protocol, RpcDemux, RpcDispatcher, TxnDispatcher,
+    TxnMethDispatcher,
 };
 use simple_keymanager::trusted_policy_signers;
 use simple_keyvalue_api::{with_api, Key, KeyValue, Transfer, Withdraw};
@@ -431,5 +432,11 @@ pub fn main() {
     };
 
     // Start the runtime.
-    oasis_core_runtime::start_runtime(Box::new(init), version_from_cargo!());
+    oasis_core_runtime::start_runtime(
+        Box::new(init),
+        Config {
+            version: version_from_cargo!(),
+            consensus_trust_root: None,
+        },
+    );
 }